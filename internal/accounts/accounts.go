@@ -0,0 +1,208 @@
+// Package accounts resolves the set of AWS accounts a fleet command spans and
+// the credentials to reach each one: an explicit list of role ARNs, or every
+// ACTIVE account from AWS Organizations reached through a role-name template.
+// Region fan-out stays in the command layer; it nests under the per-account
+// aws.Config returned here.
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awscache"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// sessionName tags the assumed-role sessions so CloudTrail shows who called.
+const sessionName = "refresh-cli"
+
+// accountPlaceholder is substituted with the account ID in ARN templates.
+const accountPlaceholder = "{account}"
+
+// Inventory describes which accounts a fleet command spans. The zero value
+// means "just the loaded credentials' account".
+type Inventory struct {
+	// RoleARNs are assumed directly, one account per ARN.
+	RoleARNs []string
+	// OrgRole enables AWS Organizations discovery: every ACTIVE account from
+	// ListAccounts is reached through this role. Either a bare role name
+	// ("OrganizationAccountAccessRole") or an ARN template containing
+	// {account}.
+	OrgRole string
+}
+
+// Enabled reports whether the inventory names any accounts to assume into.
+func (inv Inventory) Enabled() bool {
+	return len(inv.RoleARNs) > 0 || strings.TrimSpace(inv.OrgRole) != ""
+}
+
+// Account is one fleet account and the config (assumed-role credentials,
+// base region) that reaches it. ID is empty for the single-account case.
+type Account struct {
+	ID      string
+	Name    string
+	RoleARN string
+	Config  aws.Config
+}
+
+// Label is how the account is shown in tables: the Organizations name when
+// known, else the ID.
+func (a Account) Label() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.ID
+}
+
+// OrganizationsAPI is the slice of Organizations used for discovery.
+type OrganizationsAPI interface {
+	ListAccounts(ctx context.Context, in *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+// Resolver turns an Inventory into per-account configs.
+type Resolver struct {
+	sts  stscreds.AssumeRoleAPIClient
+	orgs OrganizationsAPI
+}
+
+// NewResolver builds a resolver whose STS and Organizations calls use the base
+// (caller) credentials.
+func NewResolver(base aws.Config) *Resolver {
+	return &Resolver{
+		sts:  sts.NewFromConfig(base),
+		orgs: organizations.NewFromConfig(base),
+	}
+}
+
+// Resolve expands the inventory and assumes into every account. Accounts whose
+// role can't be assumed are returned as errors (one per account) rather than
+// failing the whole fleet; err is non-nil only when the inventory itself
+// can't be listed (e.g. Organizations ListAccounts is denied).
+func (r *Resolver) Resolve(ctx context.Context, base aws.Config, inv Inventory, maxConcurrency int) ([]Account, []error, error) {
+	if !inv.Enabled() {
+		return []Account{{Config: base}}, nil, nil
+	}
+	targets, err := r.targets(ctx, base, inv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type result struct {
+		acct Account
+		err  error
+	}
+	results := common.ForEachParallel(ctx, targets, maxConcurrency, func(fctx context.Context, t Account) result {
+		cfg := base.Copy()
+		// Tracked, so the --cache response cache keys this account's answers apart.
+		cfg.Credentials = awscache.TrackCredentials(aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(r.sts, t.RoleARN,
+			func(o *stscreds.AssumeRoleOptions) { o.RoleSessionName = sessionName })))
+		// Assume eagerly so a bad role is one account-level error, not one
+		// opaque credential error per region.
+		if _, err := cfg.Credentials.Retrieve(fctx); err != nil {
+			return result{err: fmt.Errorf("account %s: %w", t.Label(), awsinternal.FormatAWSError(err, "assuming "+t.RoleARN))}
+		}
+		t.Config = cfg
+		return result{acct: t}
+	})
+
+	var (
+		accts []Account
+		errs  []error
+	)
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		accts = append(accts, res.acct)
+	}
+	return accts, errs, nil
+}
+
+// targets lists the accounts to assume into, de-duplicated by role ARN.
+func (r *Resolver) targets(ctx context.Context, base aws.Config, inv Inventory) ([]Account, error) {
+	seen := map[string]bool{}
+	var out []Account
+	add := func(a Account) {
+		if seen[a.RoleARN] {
+			return
+		}
+		seen[a.RoleARN] = true
+		out = append(out, a)
+	}
+	for _, arn := range inv.RoleARNs {
+		arn = strings.TrimSpace(arn)
+		if arn == "" {
+			continue
+		}
+		id := AccountFromARN(arn)
+		if id == "" {
+			return nil, fmt.Errorf("invalid role ARN %q", arn)
+		}
+		add(Account{ID: id, RoleARN: arn})
+	}
+	if tmpl := strings.TrimSpace(inv.OrgRole); tmpl != "" {
+		orgAccounts, err := awsinternal.ListAllPages(ctx, "listing AWS Organizations accounts",
+			func(rc context.Context, token *string) (*organizations.ListAccountsOutput, error) {
+				return r.orgs.ListAccounts(rc, &organizations.ListAccountsInput{NextToken: token})
+			},
+			func(o *organizations.ListAccountsOutput) ([]orgtypes.Account, *string) {
+				return o.Accounts, o.NextToken
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		partition := Partition(base.Region)
+		for _, a := range orgAccounts {
+			if a.State != "" && a.State != orgtypes.AccountStateActive {
+				continue
+			}
+			id := aws.ToString(a.Id)
+			add(Account{ID: id, Name: aws.ToString(a.Name), RoleARN: RoleARN(tmpl, id, partition)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// RoleARN expands a role template for an account: an ARN containing
+// {account} has it substituted; a bare role name (optionally with a path)
+// becomes arn:<partition>:iam::<account>:role/<name>.
+func RoleARN(template, accountID, partition string) string {
+	template = strings.TrimSpace(template)
+	if strings.HasPrefix(template, "arn:") {
+		return strings.ReplaceAll(template, accountPlaceholder, accountID)
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, strings.TrimPrefix(template, "/"))
+}
+
+// AccountFromARN returns the account ID field of an ARN, or "" if malformed.
+func AccountFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[4]
+}
+
+// Partition maps a region to its AWS partition.
+func Partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	default:
+		return "aws"
+	}
+}
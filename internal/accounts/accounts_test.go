@@ -0,0 +1,130 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+type fakeSTS struct {
+	mu      sync.Mutex
+	assumed []string
+	deny    map[string]bool
+}
+
+func (f *fakeSTS) AssumeRole(_ context.Context, in *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	arn := aws.ToString(in.RoleArn)
+	f.mu.Lock()
+	f.assumed = append(f.assumed, arn)
+	f.mu.Unlock()
+	if f.deny[arn] {
+		return nil, errors.New("AccessDenied: not authorized to perform sts:AssumeRole")
+	}
+	return &sts.AssumeRoleOutput{Credentials: &ststypes.Credentials{
+		AccessKeyId:     aws.String("AKIA" + AccountFromARN(arn)),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}}, nil
+}
+
+type fakeOrgs struct {
+	accounts []orgtypes.Account
+	err      error
+}
+
+func (f *fakeOrgs) ListAccounts(_ context.Context, _ *organizations.ListAccountsInput, _ ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &organizations.ListAccountsOutput{Accounts: f.accounts}, nil
+}
+
+func TestResolve_NoInventoryIsCurrentAccount(t *testing.T) {
+	base := aws.Config{Region: "us-east-1"}
+	accts, errs, err := (&Resolver{}).Resolve(context.Background(), base, Inventory{}, 4)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v %v", err, errs)
+	}
+	if len(accts) != 1 || accts[0].ID != "" || accts[0].Config.Region != "us-east-1" {
+		t.Errorf("want the base config as the only account, got %+v", accts)
+	}
+}
+
+func TestResolve_RoleARNs(t *testing.T) {
+	stsAPI := &fakeSTS{deny: map[string]bool{"arn:aws:iam::222222222222:role/ro": true}}
+	r := &Resolver{sts: stsAPI}
+	accts, errs, err := r.Resolve(context.Background(), aws.Config{Region: "eu-west-1"}, Inventory{RoleARNs: []string{
+		"arn:aws:iam::111111111111:role/ro",
+		"arn:aws:iam::222222222222:role/ro",
+		"arn:aws:iam::111111111111:role/ro", // duplicate
+	}}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accts) != 1 || accts[0].ID != "111111111111" {
+		t.Fatalf("accounts = %+v", accts)
+	}
+	if accts[0].Config.Region != "eu-west-1" {
+		t.Errorf("assumed config should keep the base region, got %q", accts[0].Config.Region)
+	}
+	creds, _ := accts[0].Config.Credentials.Retrieve(context.Background())
+	if creds.AccessKeyID != "AKIA111111111111" {
+		t.Errorf("credentials not from the assumed role: %q", creds.AccessKeyID)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "account 222222222222") {
+		t.Errorf("want one per-account error for the denied role, got %v", errs)
+	}
+	if len(stsAPI.assumed) != 2 {
+		t.Errorf("duplicate ARN should be assumed once; AssumeRole calls = %v", stsAPI.assumed)
+	}
+}
+
+func TestResolve_Organizations(t *testing.T) {
+	r := &Resolver{sts: &fakeSTS{}, orgs: &fakeOrgs{accounts: []orgtypes.Account{
+		{Id: aws.String("333333333333"), Name: aws.String("prod"), State: orgtypes.AccountStateActive},
+		{Id: aws.String("444444444444"), Name: aws.String("closed"), State: orgtypes.AccountStateSuspended},
+		{Id: aws.String("111111111111"), Name: aws.String("dev"), State: orgtypes.AccountStateActive},
+	}}}
+	accts, errs, err := r.Resolve(context.Background(), aws.Config{Region: "us-gov-west-1"}, Inventory{OrgRole: "Audit"}, 4)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v %v", err, errs)
+	}
+	if len(accts) != 2 || accts[0].Label() != "dev" || accts[1].Label() != "prod" {
+		t.Fatalf("want dev, prod (active only, sorted by ID), got %+v", accts)
+	}
+	if accts[0].RoleARN != "arn:aws-us-gov:iam::111111111111:role/Audit" {
+		t.Errorf("RoleARN = %s", accts[0].RoleARN)
+	}
+
+	_, _, err = (&Resolver{orgs: &fakeOrgs{err: errors.New("AWSOrganizationsNotInUseException")}}).
+		Resolve(context.Background(), aws.Config{}, Inventory{OrgRole: "Audit"}, 4)
+	if err == nil {
+		t.Error("a failed ListAccounts should fail the inventory")
+	}
+}
+
+func TestRoleARN(t *testing.T) {
+	tests := []struct{ tmpl, want string }{
+		{"OrganizationAccountAccessRole", "arn:aws:iam::123456789012:role/OrganizationAccountAccessRole"},
+		{"/ops/refresh-ro", "arn:aws:iam::123456789012:role/ops/refresh-ro"},
+		{"arn:aws:iam::{account}:role/custom", "arn:aws:iam::123456789012:role/custom"},
+	}
+	for _, tt := range tests {
+		if got := RoleARN(tt.tmpl, "123456789012", "aws"); got != tt.want {
+			t.Errorf("RoleARN(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+	if AccountFromARN("not-an-arn") != "" {
+		t.Error("malformed ARN should yield no account")
+	}
+}
@@ -0,0 +1,302 @@
+// Package amichangelog turns the amazon-eks-ami GitHub release notes into the
+// delta between two EKS-optimized AMI releases: how many releases a nodegroup
+// is behind, the highlights of each, and the security content — CVE IDs and
+// kernel/containerd/runc bumps — pulled out into structured fields.
+//
+// The releases come from GitHub or a mirror (REFRESH_AMI_RELEASES_URL) through
+// an on-disk cache revalidated with its ETag, so repeat runs and networks
+// without access to the endpoint still have notes; a Changelog records which
+// source it read and how old the data is.
+//
+// Everything here is best-effort. A changelog that can't be fetched or parsed
+// degrades to the bare version delta; it never blocks an update.
+package amichangelog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// dateInRelease matches the 8-digit date stamp in an EKS AMI release
+	// version or tag (e.g. "1.31.0-20260601" → "20260601", "v20260601" →
+	// "20260601").
+	dateInRelease = regexp.MustCompile(`\d{8}`)
+	cveID         = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	// packageName matches a tracked package as a whole word, so
+	// "kernel-livepatch" or "runc-shim" don't count as the package itself.
+	packageName = regexp.MustCompile(`(?i)(?:^|[^\w-])(kernel|containerd|runc)(?:[^\w-]|$)`)
+	fromTo      = regexp.MustCompile(`(?i)\bfrom\s+v?(\d[\w.+~-]*)\s+to\s+v?(\d[\w.+~-]*)`)
+	versionWord = regexp.MustCompile(`\bv?(\d+\.\d+[\w.+~-]*)`)
+)
+
+// Release is one amazon-eks-ami GitHub release.
+type Release struct {
+	Tag  string `json:"tag_name"`
+	Body string `json:"body"`
+}
+
+// CVE is one CVE ID a release mentions, with the severity from the local
+// mapping when it has one.
+type CVE struct {
+	ID       string `json:"id" yaml:"id"`
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// PackageBump is a kernel, containerd or runc version a release ships. From is
+// set only when the notes spell out the previous version ("from X to Y").
+type PackageBump struct {
+	Package string `json:"package" yaml:"package"`
+	From    string `json:"from,omitempty" yaml:"from,omitempty"`
+	To      string `json:"to" yaml:"to"`
+}
+
+// Note is a summarized amazon-eks-ami release.
+type Note struct {
+	Tag        string        `json:"tag" yaml:"tag"`
+	Highlights []string      `json:"highlights,omitempty" yaml:"highlights,omitempty"`
+	CVEs       []CVE         `json:"cves,omitempty" yaml:"cves,omitempty"`
+	Packages   []PackageBump `json:"packages,omitempty" yaml:"packages,omitempty"`
+}
+
+// Security is the security content of every release in a changelog's range.
+type Security struct {
+	// ReleasesBehind counts the releases in range that fix at least one CVE.
+	ReleasesBehind int `json:"releasesBehind" yaml:"releasesBehind"`
+	// CVEs is every CVE fixed in range, most severe first.
+	CVEs     []CVE `json:"cves,omitempty" yaml:"cves,omitempty"`
+	Critical int   `json:"critical,omitempty" yaml:"critical,omitempty"`
+	High     int   `json:"high,omitempty" yaml:"high,omitempty"`
+	// Packages is the newest version of each tracked package in range — what
+	// a roll to the target lands on.
+	Packages []PackageBump `json:"packages,omitempty" yaml:"packages,omitempty"`
+}
+
+// Provenance says where a changelog's release notes came from and how old
+// they are.
+type Provenance struct {
+	// Origin is OriginLive, OriginCache or OriginImport.
+	Origin string `json:"origin" yaml:"origin"`
+	// URL is the releases endpoint (GitHub or a mirror), or the bundle file
+	// an imported cache came from.
+	URL       string    `json:"url,omitempty" yaml:"url,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt,omitzero" yaml:"fetchedAt,omitempty"`
+}
+
+// Label renders the provenance for display: "live", "cache, 3h old" or
+// "import, 12d old", with the endpoint when it isn't GitHub.
+func (p Provenance) Label(now time.Time) string {
+	txt := p.Origin
+	if p.Origin != OriginLive && !p.FetchedAt.IsZero() {
+		txt += ", " + HumanAge(now.Sub(p.FetchedAt)) + " old"
+	}
+	if p.URL != "" && p.URL != DefaultReleasesURL {
+		txt += " from " + p.URL
+	}
+	return txt
+}
+
+// Changelog is the current→target AMI release delta plus best-effort notes.
+type Changelog struct {
+	Current  string    `json:"current" yaml:"current"`
+	Target   string    `json:"target" yaml:"target"`
+	Behind   int       `json:"releasesBehind" yaml:"releasesBehind"`
+	Pinned   bool      `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	Notes    []Note    `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Security *Security `json:"security,omitempty" yaml:"security,omitempty"`
+	// Source is where the notes came from; nil when they weren't read.
+	Source   *Provenance `json:"source,omitempty" yaml:"source,omitempty"`
+	Degraded bool        `json:"degraded,omitempty" yaml:"degraded,omitempty"`
+	Reason   string      `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ReleaseDate returns the trailing 8-digit date stamp from a release version
+// or tag.
+func ReleaseDate(release string) (string, bool) {
+	m := dateInRelease.FindAllString(release, -1)
+	if len(m) == 0 {
+		return "", false
+	}
+	return m[len(m)-1], true
+}
+
+// Build computes the release delta and, best-effort, the summarized notes and
+// security content for the releases strictly after current up to target. An
+// empty target means up to the newest published release. Any failure degrades
+// to just the version delta.
+func Build(ctx context.Context, src Source, current, target string, sev Severities) Changelog {
+	cl := Changelog{Current: current, Target: target}
+	curDate, okC := ReleaseDate(current)
+	tgtDate, okT := ReleaseDate(target)
+	if !okC || (target != "" && !okT) {
+		cl.Degraded = true
+		cl.Reason = "could not parse release dates"
+		return cl
+	}
+	if target != "" && curDate >= tgtDate {
+		return cl // current is at or ahead of target — nothing to show
+	}
+
+	feed, err := src.Releases(ctx)
+	if err != nil {
+		cl.Degraded = true
+		cl.Reason = err.Error()
+		return cl
+	}
+	cl.Source = &feed.Provenance
+	sec := &Security{}
+	// The newest release mentioning a package decides its versions; a release
+	// can list several (one kernel per OS family, say).
+	latest := map[string][]PackageBump{}
+	latestDate := map[string]string{}
+	for _, r := range feed.Releases {
+		d, ok := ReleaseDate(r.Tag)
+		if !ok || d <= curDate || (target != "" && d > tgtDate) {
+			continue
+		}
+		cl.Behind++
+		note := summarize(r, sev)
+		if len(note.CVEs) > 0 {
+			sec.ReleasesBehind++
+			sec.CVEs = append(sec.CVEs, note.CVEs...)
+		}
+		for _, p := range note.Packages {
+			switch prev := latestDate[p.Package]; {
+			case d > prev:
+				latestDate[p.Package] = d
+				latest[p.Package] = []PackageBump{p}
+			case d == prev:
+				latest[p.Package] = append(latest[p.Package], p)
+			}
+		}
+		if len(note.Highlights) > 0 && len(cl.Notes) < 10 {
+			cl.Notes = append(cl.Notes, note)
+		}
+	}
+	sec.CVEs = dedupeCVEs(sec.CVEs)
+	for _, c := range sec.CVEs {
+		switch c.Severity {
+		case "critical":
+			sec.Critical++
+		case "high":
+			sec.High++
+		}
+	}
+	pkgs := make([]string, 0, len(latest))
+	for p := range latest {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	for _, p := range pkgs {
+		sec.Packages = append(sec.Packages, latest[p]...)
+	}
+	cl.Security = sec
+	return cl
+}
+
+// summarize pulls the lines that matter for a node patch out of a release
+// body, and the CVEs and package versions within them.
+func summarize(r Release, sev Severities) Note {
+	note := Note{Tag: r.Tag}
+	seen := map[string]struct{}{}
+	pkgSeen := map[PackageBump]struct{}{}
+	for _, line := range strings.Split(r.Body, "\n") {
+		l := strings.TrimLeft(strings.TrimSpace(line), "-*# ")
+		if l == "" {
+			continue
+		}
+		for _, id := range cveID.FindAllString(l, -1) {
+			note.CVEs = append(note.CVEs, sev.grade(id))
+		}
+		for _, p := range packageBumps(l) {
+			if _, dup := pkgSeen[p]; !dup {
+				pkgSeen[p] = struct{}{}
+				note.Packages = append(note.Packages, p)
+			}
+		}
+		low := strings.ToLower(l)
+		if strings.Contains(low, "kernel") || strings.Contains(low, "containerd") ||
+			strings.Contains(low, "runc") || strings.Contains(low, "cve-") {
+			if _, dup := seen[l]; dup {
+				continue
+			}
+			seen[l] = struct{}{}
+			if len(note.Highlights) < 6 {
+				note.Highlights = append(note.Highlights, l)
+			}
+		}
+	}
+	note.CVEs = dedupeCVEs(note.CVEs)
+	return note
+}
+
+// packageBumps reads the tracked package versions from one line of notes:
+// "from X to Y" when present, else the first version after the package name.
+// Release bodies also carry package tables ("| kernel | 6.1.134-… |"), which
+// the second form covers.
+func packageBumps(line string) []PackageBump {
+	var out []PackageBump
+	locs := packageName.FindAllStringSubmatchIndex(line, -1)
+	for i, loc := range locs {
+		end := len(line)
+		if i+1 < len(locs) {
+			end = locs[i+1][2]
+		}
+		rest := line[loc[3]:end]
+		pkg := strings.ToLower(line[loc[2]:loc[3]])
+		if m := fromTo.FindStringSubmatch(rest); m != nil {
+			out = append(out, PackageBump{Package: pkg, From: trimVersion(m[1]), To: trimVersion(m[2])})
+			continue
+		}
+		if m := versionWord.FindStringSubmatch(rest); m != nil {
+			out = append(out, PackageBump{Package: pkg, To: trimVersion(m[1])})
+		}
+	}
+	return out
+}
+
+func trimVersion(v string) string {
+	return strings.TrimRight(v, ".-~+")
+}
+
+// HumanAge renders a coarse age: minutes under an hour, hours under a day,
+// days beyond.
+func HumanAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// dedupeCVEs drops repeated IDs and orders the rest most severe first, then by
+// ID.
+func dedupeCVEs(cves []CVE) []CVE {
+	if len(cves) == 0 {
+		return nil
+	}
+	seen := map[string]struct{}{}
+	out := make([]CVE, 0, len(cves))
+	for _, c := range cves {
+		if _, dup := seen[c.ID]; dup {
+			continue
+		}
+		seen[c.ID] = struct{}{}
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := severityRank[out[i].Severity], severityRank[out[j].Severity]
+		if ri != rj {
+			return ri > rj
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
@@ -0,0 +1,144 @@
+package amichangelog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeSource struct {
+	releases []Release
+	err      error
+	calls    int
+}
+
+func (f *fakeSource) Releases(context.Context) (Feed, error) {
+	f.calls++
+	return Feed{Releases: f.releases, Provenance: Provenance{Origin: OriginLive}}, f.err
+}
+
+func TestReleaseDate(t *testing.T) {
+	cases := map[string]string{
+		"1.31.0-20260601": "20260601",
+		"v20260601":       "20260601",
+		"no-date-here":    "",
+	}
+	for in, want := range cases {
+		got, ok := ReleaseDate(in)
+		if want == "" {
+			if ok {
+				t.Errorf("ReleaseDate(%q) = %q, want no match", in, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Errorf("ReleaseDate(%q) = %q (ok=%v), want %q", in, got, ok, want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	body := "## Changes\n- Bump kernel to 5.10.99\n- update containerd from 1.7.0 to 1.7.2\n- Fix CVE-2026-1234 and cve-2026-5678\n- unrelated doc tweak\n| runc | 1.1.14-1.amzn2023 |\n- kernel-livepatch 1.0\n"
+	n := summarize(Release{Tag: "v1", Body: body}, Severities{"CVE-2026-1234": "high"})
+	if len(n.Highlights) != 5 {
+		t.Fatalf("got %d highlights, want 5: %v", len(n.Highlights), n.Highlights)
+	}
+	if len(n.CVEs) != 2 || n.CVEs[0] != (CVE{ID: "CVE-2026-1234", Severity: "high"}) || n.CVEs[1].ID != "CVE-2026-5678" {
+		t.Errorf("CVEs = %+v, want the graded one first", n.CVEs)
+	}
+	want := []PackageBump{
+		{Package: "kernel", To: "5.10.99"},
+		{Package: "containerd", From: "1.7.0", To: "1.7.2"},
+		{Package: "runc", To: "1.1.14-1.amzn2023"},
+	}
+	if len(n.Packages) != len(want) {
+		t.Fatalf("Packages = %+v, want %+v", n.Packages, want)
+	}
+	for i := range want {
+		if n.Packages[i] != want[i] {
+			t.Errorf("Packages[%d] = %+v, want %+v", i, n.Packages[i], want[i])
+		}
+	}
+}
+
+func TestBuild_SecurityAcrossRange(t *testing.T) {
+	src := &fakeSource{releases: []Release{
+		{Tag: "v20260601", Body: "- kernel 6.1.140\n- Fixes CVE-2026-1001\n"},
+		{Tag: "v20260401", Body: "- kernel 6.1.130\n- Fixes CVE-2026-1001, CVE-2026-1002\n"},
+		{Tag: "v20260301", Body: "- containerd 1.7.0\n"},
+		{Tag: "v20260101", Body: "- old, before current CVE-2025-9001\n"},
+	}}
+	sev := Severities{"CVE-2026-1002": "critical"}
+	cl := Build(context.Background(), src, "1.31.0-20260201", "1.31.0-20260601", sev)
+	if cl.Degraded {
+		t.Fatalf("unexpected degraded: %s", cl.Reason)
+	}
+	if cl.Behind != 3 || len(cl.Notes) != 3 {
+		t.Errorf("Behind = %d, Notes = %d, want 3 and 3", cl.Behind, len(cl.Notes))
+	}
+	sec := cl.Security
+	if sec == nil {
+		t.Fatal("Security = nil")
+	}
+	if sec.ReleasesBehind != 2 || len(sec.CVEs) != 2 || sec.Critical != 1 || sec.High != 0 {
+		t.Errorf("Security = %+v, want 2 releases, 2 CVEs, 1 critical", sec)
+	}
+	if sec.CVEs[0].ID != "CVE-2026-1002" {
+		t.Errorf("CVEs[0] = %s, want the critical one first", sec.CVEs[0].ID)
+	}
+	want := []PackageBump{{Package: "containerd", To: "1.7.0"}, {Package: "kernel", To: "6.1.140"}}
+	if len(sec.Packages) != 2 || sec.Packages[0] != want[0] || sec.Packages[1] != want[1] {
+		t.Errorf("Packages = %+v, want %+v", sec.Packages, want)
+	}
+}
+
+func TestBuild_EmptyTargetRunsToNewest(t *testing.T) {
+	src := &fakeSource{releases: []Release{
+		{Tag: "v20260601", Body: "- CVE-2026-1001\n"},
+		{Tag: "v20260301", Body: "- nothing\n"},
+	}}
+	cl := Build(context.Background(), src, "1.31.0-20260201", "", nil)
+	if cl.Behind != 2 || cl.Security == nil || cl.Security.ReleasesBehind != 1 {
+		t.Errorf("changelog = %+v, want 2 behind with 1 security release", cl)
+	}
+}
+
+func TestBuild_Degrades(t *testing.T) {
+	src := &fakeSource{err: errors.New("offline")}
+	if cl := Build(context.Background(), src, "custom-x", "also-custom", nil); !cl.Degraded || src.calls != 0 {
+		t.Errorf("unparseable dates: Degraded = %v, fetches = %d", cl.Degraded, src.calls)
+	}
+	cl := Build(context.Background(), src, "1.31.0-20260201", "1.31.0-20260601", nil)
+	if !cl.Degraded || cl.Reason != "offline" || cl.Security != nil {
+		t.Errorf("fetch error: %+v", cl)
+	}
+}
+
+func TestLoadSeverities(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yaml")
+	if err := os.WriteFile(good, []byte("cve-2026-1001: Critical\nCVE-2026-1002: low\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sev, err := LoadSeverities(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sev["CVE-2026-1001"] != "critical" || sev["CVE-2026-1002"] != "low" {
+		t.Errorf("severities = %v", sev)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("CVE-2026-1001: urgent\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSeverities(bad); err == nil || !strings.Contains(err.Error(), "urgent") {
+		t.Errorf("LoadSeverities(bad) err = %v, want the bad value named", err)
+	}
+	if sev, err := LoadSeverities(""); err != nil || sev != nil {
+		t.Errorf("LoadSeverities(\"\") = %v, %v", sev, err)
+	}
+}
@@ -0,0 +1,126 @@
+package amichangelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// cacheFileName is the cached release feed under the refresh config dir.
+const cacheFileName = "ami-releases.json"
+
+// Bundle is the cached release feed. `refresh changelog export` writes it to a
+// file and `refresh changelog import` loads one, which is how the release
+// notes reach a network that can't fetch them.
+type Bundle struct {
+	// URL is the endpoint the releases were fetched from.
+	URL  string `json:"url"`
+	ETag string `json:"etag,omitempty"`
+	// FetchedAt is when the endpoint last confirmed these releases.
+	FetchedAt time.Time `json:"fetchedAt"`
+	// CheckedAt is when the endpoint was last tried, successfully or not.
+	CheckedAt time.Time `json:"checkedAt,omitzero"`
+	// Imported is the file an imported cache came from. An imported cache is
+	// served without requests until the next sync.
+	Imported string    `json:"imported,omitempty"`
+	Releases []Release `json:"releases"`
+}
+
+func (b Bundle) feed(origin string) Feed {
+	p := Provenance{Origin: origin, URL: b.URL, FetchedAt: b.FetchedAt}
+	if origin == OriginImport {
+		p.URL = b.Imported
+	}
+	return Feed{Releases: b.Releases, Provenance: p}
+}
+
+// Export returns the bundle as it should leave this machine: without the
+// local bookkeeping of when it was last checked or where it was imported from.
+func (b Bundle) Export() Bundle {
+	b.CheckedAt, b.Imported = time.Time{}, ""
+	return b
+}
+
+// CachePath returns the cached feed location: ami-releases.json beside the
+// context file.
+func CachePath() (string, error) {
+	dir, err := cliconfig.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, cacheFileName), nil
+}
+
+// LoadBundle reads the bundle at path. A missing file is an empty bundle, not
+// an error.
+func LoadBundle(path string) (Bundle, error) {
+	var b Bundle
+	data, err := readFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// ImportBundle reads an exported bundle from src and writes it to the cache at
+// path, marked imported. A bundle without releases is rejected.
+func ImportBundle(src, path string) (Bundle, error) {
+	data, err := readFile(src)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("reading %s: %w", src, err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Bundle{}, fmt.Errorf("parsing %s: %w", src, err)
+	}
+	if len(b.Releases) == 0 {
+		return Bundle{}, fmt.Errorf("%s holds no amazon-eks-ami releases", src)
+	}
+	if abs, err := filepath.Abs(src); err == nil {
+		src = abs
+	}
+	b.Imported, b.CheckedAt = src, time.Time{}
+	return b, SaveBundle(path, b)
+}
+
+// SaveBundle writes the bundle atomically (temp + rename) so a crash or a
+// concurrent refresh process can't leave a torn file.
+func SaveBundle(path string, b Bundle) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ami-releases-*.json")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
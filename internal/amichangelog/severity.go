@@ -0,0 +1,61 @@
+package amichangelog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var readFile = os.ReadFile
+
+// severityRank orders severities for sorting; unknown severities rank lowest.
+var severityRank = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// Severities maps a CVE ID to its severity: critical, high, medium or low.
+// The release notes carry IDs only, so grading comes from a local file the
+// team maintains (exported from a scanner or the vendor advisories).
+type Severities map[string]string
+
+// LoadSeverities reads a YAML (or JSON) mapping of CVE ID to severity:
+//
+//	CVE-2026-1234: critical
+//	CVE-2026-2345: high
+//
+// An empty path returns no mapping, so every CVE stays ungraded.
+func LoadSeverities(path string) (Severities, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	b, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CVE severity file %s: %w", path, err)
+	}
+	var raw map[string]string
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("parsing CVE severity file %s: %w", path, err)
+	}
+	out := make(Severities, len(raw))
+	var bad []string
+	for id, s := range raw {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if _, ok := severityRank[s]; !ok {
+			bad = append(bad, fmt.Sprintf("%s: %q", id, s))
+			continue
+		}
+		out[strings.ToUpper(strings.TrimSpace(id))] = s
+	}
+	if len(bad) > 0 {
+		sort.Strings(bad)
+		return nil, fmt.Errorf("CVE severity file %s: want critical, high, medium or low (%s)", path, strings.Join(bad, ", "))
+	}
+	return out, nil
+}
+
+// grade returns the CVE for id with its mapped severity, if any.
+func (s Severities) grade(id string) CVE {
+	id = strings.ToUpper(id)
+	return CVE{ID: id, Severity: s[id]}
+}
@@ -0,0 +1,192 @@
+package amichangelog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReleasesURL is the GitHub releases endpoint of amazon-eks-ami.
+	DefaultReleasesURL = "https://api.github.com/repos/awslabs/amazon-eks-ami/releases?per_page=100"
+	// ReleasesURLEnv names a mirror of the releases endpoint, for networks
+	// that can't reach api.github.com.
+	ReleasesURLEnv = "REFRESH_AMI_RELEASES_URL"
+
+	// Origins of a Feed.
+	OriginLive   = "live"
+	OriginCache  = "cache"
+	OriginImport = "import"
+
+	httpLimit = 4 * time.Second
+	// revalidateAfter is how long the cache is served without asking the
+	// endpoint again. A failed request also waits this long before the next
+	// try, so a run without network access pays the timeout at most once per
+	// window.
+	revalidateAfter = 6 * time.Hour
+)
+
+// Feed is the release list a Source returns, with where it came from.
+type Feed struct {
+	Releases []Release
+	Provenance
+}
+
+// Source supplies amazon-eks-ami releases, newest first.
+type Source interface {
+	Releases(ctx context.Context) (Feed, error)
+}
+
+// ReleasesURL returns the releases endpoint: the mirror in
+// REFRESH_AMI_RELEASES_URL, else GitHub.
+func ReleasesURL() string {
+	if u := strings.TrimSpace(os.Getenv(ReleasesURLEnv)); u != "" {
+		return u
+	}
+	return DefaultReleasesURL
+}
+
+// Client is the Source backed by the releases endpoint and, when it has a
+// cache path, the on-disk cache. It resolves the feed once and serves every
+// later call from memory, so one run that builds changelogs for many
+// nodegroups costs at most one request.
+type Client struct {
+	http      *http.Client
+	url       string
+	cachePath string
+	now       func() time.Time
+
+	once sync.Once
+	feed Feed
+	err  error
+}
+
+// NewClient returns a Client for url. A nil httpClient gets a short timeout;
+// an empty cachePath disables the disk cache.
+func NewClient(httpClient *http.Client, url, cachePath string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: httpLimit}
+	}
+	return &Client{http: httpClient, url: url, cachePath: cachePath, now: time.Now}
+}
+
+// Default returns the Client the commands use: ReleasesURL with the cache at
+// CachePath (no cache when the config dir can't be resolved).
+func Default() *Client {
+	path, _ := CachePath()
+	return NewClient(nil, ReleasesURL(), path)
+}
+
+// URL is the endpoint the client fetches.
+func (c *Client) URL() string { return c.url }
+
+// Releases implements Source. An imported cache is served as-is. Otherwise a
+// cache checked within revalidateAfter is served without a request; an older
+// one is revalidated with its ETag. When the endpoint can't be reached, the
+// cache is served at its age. Only a failed request with nothing cached is an
+// error.
+func (c *Client) Releases(ctx context.Context) (Feed, error) {
+	c.once.Do(func() { c.feed, c.err = c.resolve(ctx) })
+	return c.feed, c.err
+}
+
+func (c *Client) resolve(ctx context.Context) (Feed, error) {
+	cached, _ := c.load()
+	have := len(cached.Releases) > 0
+	if have && cached.Imported != "" {
+		return cached.feed(OriginImport), nil
+	}
+	now := c.now()
+	if have && cached.URL == c.url && now.Sub(cached.CheckedAt) < revalidateAfter {
+		return cached.feed(OriginCache), nil
+	}
+	fresh, err := c.fetch(ctx, cached)
+	if err != nil {
+		if !have {
+			return Feed{}, err
+		}
+		cached.CheckedAt = now
+		_ = c.save(cached)
+		return cached.feed(OriginCache), nil
+	}
+	_ = c.save(fresh)
+	return fresh.feed(OriginLive), nil
+}
+
+// Sync revalidates the cache against the endpoint whatever its age, replacing
+// an imported cache, and returns the bundle now cached. changed is false when
+// the endpoint answered 304 Not Modified. Unlike Releases, a failed request is
+// an error.
+func (c *Client) Sync(ctx context.Context) (b Bundle, changed bool, err error) {
+	if c.cachePath == "" {
+		return Bundle{}, false, errors.New("cannot resolve the refresh config directory for the release cache")
+	}
+	cached, err := c.load()
+	if err != nil {
+		return Bundle{}, false, err
+	}
+	fresh, err := c.fetch(ctx, cached)
+	if err != nil {
+		return Bundle{}, false, err
+	}
+	changed = fresh.ETag == "" || fresh.ETag != cached.ETag || cached.Imported != ""
+	return fresh, changed, c.save(fresh)
+}
+
+// fetch GETs the releases, conditional on prev's ETag when prev came from the
+// same endpoint. A 304 returns prev, marked fetched now.
+func (c *Client) fetch(ctx context.Context, prev Bundle) (Bundle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return Bundle{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	revalidate := prev.URL == c.url && prev.ETag != "" && prev.Imported == "" && len(prev.Releases) > 0
+	if revalidate {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	now := c.now().UTC()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && revalidate:
+		prev.FetchedAt, prev.CheckedAt = now, now
+		return prev, nil
+	case resp.StatusCode != http.StatusOK:
+		return Bundle{}, fmt.Errorf("amazon-eks-ami releases API returned %s", resp.Status)
+	}
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return Bundle{}, fmt.Errorf("parsing amazon-eks-ami releases: %w", err)
+	}
+	return Bundle{
+		URL:       c.url,
+		ETag:      resp.Header.Get("ETag"),
+		FetchedAt: now,
+		CheckedAt: now,
+		Releases:  releases,
+	}, nil
+}
+
+func (c *Client) load() (Bundle, error) {
+	if c.cachePath == "" {
+		return Bundle{}, nil
+	}
+	return LoadBundle(c.cachePath)
+}
+
+func (c *Client) save(b Bundle) error {
+	if c.cachePath == "" {
+		return nil
+	}
+	return SaveBundle(c.cachePath, b)
+}
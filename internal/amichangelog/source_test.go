@@ -0,0 +1,146 @@
+package amichangelog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// releasesServer serves one release with ETag "v1", answering 304 to a
+// matching If-None-Match, and counts requests.
+func releasesServer(t *testing.T, hits *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"tag_name":"v20260601","body":"- kernel 5.10.99\n"}]`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(srv *httptest.Server, cachePath string, now time.Time) *Client {
+	c := NewClient(srv.Client(), srv.URL, cachePath)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestClient_FetchesOnceAndCaches(t *testing.T) {
+	hits := 0
+	srv := releasesServer(t, &hits)
+	path := filepath.Join(t.TempDir(), cacheFileName)
+	now := time.Date(2026, 6, 11, 12, 0, 0, 0, time.UTC)
+
+	c := newTestClient(srv, path, now)
+	for range 2 {
+		feed, err := c.Releases(context.Background())
+		if err != nil || len(feed.Releases) != 1 || feed.Origin != OriginLive {
+			t.Fatalf("Releases() = %+v, %v", feed, err)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("endpoint hit %d times, want 1", hits)
+	}
+
+	// A later run inside the window is served from disk without a request.
+	feed, err := newTestClient(srv, path, now.Add(time.Hour)).Releases(context.Background())
+	if err != nil || feed.Origin != OriginCache || hits != 1 {
+		t.Errorf("within window: origin %q, hits %d, err %v", feed.Origin, hits, err)
+	}
+}
+
+func TestClient_RevalidatesWithETag(t *testing.T) {
+	hits := 0
+	srv := releasesServer(t, &hits)
+	path := filepath.Join(t.TempDir(), cacheFileName)
+	now := time.Date(2026, 6, 11, 12, 0, 0, 0, time.UTC)
+	if _, err := newTestClient(srv, path, now).Releases(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	later := now.Add(revalidateAfter + time.Hour)
+	feed, err := newTestClient(srv, path, later).Releases(context.Background())
+	if err != nil || hits != 2 || len(feed.Releases) != 1 || !feed.FetchedAt.Equal(later) {
+		t.Errorf("revalidated feed = %+v, hits %d, err %v; want the cached releases confirmed now", feed, hits, err)
+	}
+}
+
+func TestClient_ServesStaleCacheWhenOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), cacheFileName)
+	fetched := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if err := SaveBundle(path, Bundle{URL: "http://offline.invalid", FetchedAt: fetched, CheckedAt: fetched,
+		Releases: []Release{{Tag: "v20260601"}}}); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(&http.Client{Timeout: 50 * time.Millisecond}, "http://offline.invalid", path)
+	now := fetched.Add(72 * time.Hour)
+	c.now = func() time.Time { return now }
+	feed, err := c.Releases(context.Background())
+	if err != nil || feed.Origin != OriginCache || !feed.FetchedAt.Equal(fetched) {
+		t.Fatalf("offline feed = %+v, %v", feed, err)
+	}
+	if got := feed.Label(now); !strings.HasPrefix(got, "cache, 3d old") {
+		t.Errorf("Label = %q", got)
+	}
+	// The failed attempt is recorded so the next run doesn't retry at once.
+	b, _ := LoadBundle(path)
+	if !b.CheckedAt.Equal(now) {
+		t.Errorf("CheckedAt = %v, want %v", b.CheckedAt, now)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	hits := 0
+	srv := releasesServer(t, &hits)
+	dir := t.TempDir()
+	connected := filepath.Join(dir, "connected", cacheFileName)
+	now := time.Date(2026, 6, 11, 12, 0, 0, 0, time.UTC)
+	b, changed, err := newTestClient(srv, connected, now).Sync(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Sync() = %v, %v", changed, err)
+	}
+
+	bundle := filepath.Join(dir, "bundle.json")
+	if err := SaveBundle(bundle, b.Export()); err != nil {
+		t.Fatal(err)
+	}
+	airgapped := filepath.Join(dir, "airgapped", cacheFileName)
+	if _, err := ImportBundle(bundle, airgapped); err != nil {
+		t.Fatal(err)
+	}
+
+	// The imported cache is served without touching the endpoint, whatever
+	// its age.
+	c := NewClient(srv.Client(), srv.URL, airgapped)
+	c.now = func() time.Time { return now.Add(30 * 24 * time.Hour) }
+	feed, err := c.Releases(context.Background())
+	if err != nil || feed.Origin != OriginImport || feed.URL != bundle || hits != 1 {
+		t.Errorf("imported feed = %+v, hits %d, err %v", feed.Provenance, hits, err)
+	}
+
+	if _, err := ImportBundle(filepath.Join(dir, "missing.json"), airgapped); err == nil {
+		t.Error("importing a missing file should fail")
+	}
+}
+
+func TestSync_NotModified(t *testing.T) {
+	hits := 0
+	srv := releasesServer(t, &hits)
+	path := filepath.Join(t.TempDir(), cacheFileName)
+	now := time.Date(2026, 6, 11, 12, 0, 0, 0, time.UTC)
+	if _, _, err := newTestClient(srv, path, now).Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, changed, err := newTestClient(srv, path, now.Add(time.Minute)).Sync(context.Background())
+	if err != nil || changed || hits != 2 {
+		t.Errorf("second Sync: changed %v, hits %d, err %v", changed, hits, err)
+	}
+}
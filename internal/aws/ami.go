@@ -4,6 +4,9 @@ package aws
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 )
 
 // CurrentAmiID resolves the current AMI ID for a nodegroup.
@@ -121,6 +125,122 @@ func LatestReleaseVersionForType(ctx context.Context, ssmClient *ssm.Client, k8s
 	return *out.Parameter.Value
 }
 
+// AMIRelease is one published EKS-optimized AMI release for an AMI type.
+type AMIRelease struct {
+	ReleaseVersion string // e.g. "1.31.0-20260601"
+	ImageID        string
+}
+
+// ReleasesForType lists the AMI releases published for an AMI type and
+// Kubernetes version, oldest first. It reads every release directory next to
+// the "recommended" parameter (…/<ami-name>/release_version and image_id), so
+// it covers the releases EKS accepts as an UpdateNodegroupVersion release
+// version. Custom and unrecognized AMI types return an error.
+func ReleasesForType(ctx context.Context, ssmClient *ssm.Client, k8sVersion string, amiType types.AMITypes) ([]AMIRelease, error) {
+	imgPath := buildSSMParameterPath(k8sVersion, amiType)
+	if imgPath == "" {
+		return nil, fmt.Errorf("no published AMI releases for AMI type %s", amiType)
+	}
+	dir := strings.TrimSuffix(imgPath, "recommended/image_id")
+	params, err := ListAllPages(ctx, fmt.Sprintf("listing %s AMI releases for Kubernetes %s", amiType, k8sVersion),
+		func(rc context.Context, token *string) (*ssm.GetParametersByPathOutput, error) {
+			return ssmClient.GetParametersByPath(rc, &ssm.GetParametersByPathInput{
+				Path:      aws.String(dir),
+				Recursive: aws.Bool(true),
+				NextToken: token,
+			})
+		},
+		func(out *ssm.GetParametersByPathOutput) ([]ssmtypes.Parameter, *string) {
+			return out.Parameters, out.NextToken
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return releasesFromParameters(params), nil
+}
+
+// releasesFromParameters pairs each release directory's release_version and
+// image_id parameters, skipping "recommended" (an alias of the newest
+// release) and directories missing either one, and sorts oldest first.
+func releasesFromParameters(params []ssmtypes.Parameter) []AMIRelease {
+	byDir := make(map[string]*AMIRelease)
+	for _, p := range params {
+		name, value := aws.ToString(p.Name), aws.ToString(p.Value)
+		dir, leaf, ok := cutLast(name, "/")
+		if !ok || strings.HasSuffix(dir, "/recommended") {
+			continue
+		}
+		r := byDir[dir]
+		if r == nil {
+			r = &AMIRelease{}
+			byDir[dir] = r
+		}
+		switch leaf {
+		case "release_version":
+			r.ReleaseVersion = value
+		case "image_id":
+			r.ImageID = value
+		}
+	}
+	out := make([]AMIRelease, 0, len(byDir))
+	for _, r := range byDir {
+		if r.ReleaseVersion != "" && r.ImageID != "" {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		di, dj := ReleaseStamp(out[i].ReleaseVersion), ReleaseStamp(out[j].ReleaseVersion)
+		if di != dj {
+			return di < dj
+		}
+		return out[i].ReleaseVersion < out[j].ReleaseVersion
+	})
+	return out
+}
+
+// FindRelease returns the release in releases with the given version. The
+// error names the newest published release, so a typo is easy to fix.
+func FindRelease(releases []AMIRelease, version string, amiType types.AMITypes, k8sVersion string) (AMIRelease, error) {
+	for _, r := range releases {
+		if r.ReleaseVersion == version {
+			return r, nil
+		}
+	}
+	if len(releases) == 0 {
+		return AMIRelease{}, fmt.Errorf("release version %s: no %s releases are published for Kubernetes %s", version, amiType, k8sVersion)
+	}
+	return AMIRelease{}, fmt.Errorf("release version %s is not published for %s on Kubernetes %s (newest: %s)",
+		version, amiType, k8sVersion, releases[len(releases)-1].ReleaseVersion)
+}
+
+// releaseStampPattern matches the 8-digit build date in a release version.
+var releaseStampPattern = regexp.MustCompile(`\d{8}`)
+
+// ReleaseStamp returns the last 8-digit date stamp in a release version, or
+// "" when there is none.
+func ReleaseStamp(release string) string {
+	m := releaseStampPattern.FindAllString(release, -1)
+	if len(m) == 0 {
+		return ""
+	}
+	return m[len(m)-1]
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// SSMImageParameter is the SSM parameter EKS publishes the recommended AMI ID
+// of an AMI type under, or "" for custom AMIs.
+func SSMImageParameter(k8sVersion string, amiType types.AMITypes) string {
+	return buildSSMParameterPath(k8sVersion, amiType)
+}
+
 // buildSSMParameterPath constructs the SSM parameter path for the given AMI type.
 // Reference: https://docs.aws.amazon.com/eks/latest/userguide/retrieve-ami-id.html
 func buildSSMParameterPath(k8sVersion string, amiType types.AMITypes) string {
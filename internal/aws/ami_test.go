@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 )
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -189,3 +190,35 @@ func TestInferSSMPath_AL2Arm64(t *testing.T) {
 		t.Errorf("unexpected path: %q", path)
 	}
 }
+
+// ──────────────────────────────────────────────────────────────────────────────
+// releasesFromParameters / FindRelease
+// ──────────────────────────────────────────────────────────────────────────────
+
+func TestReleasesFromParameters(t *testing.T) {
+	dir := "/aws/service/eks/optimized-ami/1.31/amazon-linux-2023/x86_64/standard/"
+	param := func(name, value string) ssmtypes.Parameter {
+		return ssmtypes.Parameter{Name: aws.String(dir + name), Value: aws.String(value)}
+	}
+	got := releasesFromParameters([]ssmtypes.Parameter{
+		param("recommended/image_id", "ami-newest"),
+		param("recommended/release_version", "1.31.1-20260701"),
+		param("amazon-eks-node-al2023-x86_64-standard-1.31-v20260701/image_id", "ami-newest"),
+		param("amazon-eks-node-al2023-x86_64-standard-1.31-v20260701/release_version", "1.31.1-20260701"),
+		param("amazon-eks-node-al2023-x86_64-standard-1.31-v20260601/release_version", "1.31.0-20260601"),
+		param("amazon-eks-node-al2023-x86_64-standard-1.31-v20260601/image_id", "ami-june"),
+		param("amazon-eks-node-al2023-x86_64-standard-1.31-v20260501/image_name", "no release_version"),
+	})
+	want := []AMIRelease{{ReleaseVersion: "1.31.0-20260601", ImageID: "ami-june"}, {ReleaseVersion: "1.31.1-20260701", ImageID: "ami-newest"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("releases = %+v, want %+v", got, want)
+	}
+
+	if r, err := FindRelease(got, "1.31.0-20260601", types.AMITypesAl2023X8664Standard, "1.31"); err != nil || r.ImageID != "ami-june" {
+		t.Errorf("FindRelease = %+v, %v", r, err)
+	}
+	_, err := FindRelease(got, "1.31.0-20260101", types.AMITypesAl2023X8664Standard, "1.31")
+	if err == nil || !strings.Contains(err.Error(), "not published for AL2023_x86_64_STANDARD on Kubernetes 1.31 (newest: 1.31.1-20260701)") {
+		t.Errorf("FindRelease error = %v", err)
+	}
+}
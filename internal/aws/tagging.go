@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	rgttypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+
+	"github.com/dantech2000/refresh/internal/selector"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// TaggingAPI is the Resource Groups Tagging API call the selector prefilter
+// makes. Satisfied by *resourcegroupstaggingapi.Client.
+type TaggingAPI interface {
+	GetResources(ctx context.Context, in *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error)
+}
+
+// GetResources' limits on one request's tag filters.
+const (
+	maxTagFilters     = 50
+	maxTagFilterValue = 20
+)
+
+// TaggedClusters narrows names to the EKS clusters whose tags satisfy sel's
+// =, in and existence terms, with one paged GetResources call for the
+// region's eks:cluster resources instead of a DescribeCluster per cluster.
+// It only rules clusters out: sel's !=, notin and !key terms are settled by
+// each kept cluster's DescribeCluster.
+//
+// The index lags tag changes by up to a few minutes. A cluster it still
+// lists under stale tags is kept and then dropped by its DescribeCluster,
+// but one tagged since the index last caught up is missed while any other
+// cluster matches. When none does, ok is false, so a selector naming only
+// freshly tagged clusters still finds them the slow way.
+//
+// ok is also false when the index can't be used — sel has no such terms, or
+// GetResources failed (AccessDenied for a role without tag:GetResources, say)
+// — and every name should then be checked with DescribeCluster as before.
+func TaggedClusters(ctx context.Context, api TaggingAPI, names []string, sel selector.Selector) (kept []string, ok bool) {
+	reqs := sel.Positive()
+	if api == nil || len(reqs) == 0 || len(reqs) > maxTagFilters {
+		return nil, false
+	}
+	filters := make([]rgttypes.TagFilter, 0, len(reqs))
+	for _, r := range reqs {
+		if len(r.Values) > maxTagFilterValue {
+			return nil, false
+		}
+		filters = append(filters, rgttypes.TagFilter{Key: aws.String(r.Key), Values: r.Values})
+	}
+	arns, err := common.Paginate(ctx, func(rc context.Context, token *string) ([]string, *string, error) {
+		out, err := common.WithRetry(rc, common.DefaultRetryConfig, func(rrc context.Context) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+			return api.GetResources(rrc, &resourcegroupstaggingapi.GetResourcesInput{
+				ResourceTypeFilters: []string{"eks:cluster"},
+				TagFilters:          filters,
+				PaginationToken:     token,
+			})
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		page := make([]string, 0, len(out.ResourceTagMappingList))
+		for _, m := range out.ResourceTagMappingList {
+			page = append(page, aws.ToString(m.ResourceARN))
+		}
+		// The last page carries an empty token rather than none.
+		next := out.PaginationToken
+		if aws.ToString(next) == "" {
+			next = nil
+		}
+		return page, next, nil
+	})
+	if err != nil {
+		return nil, false
+	}
+	tagged := make(map[string]bool, len(arns))
+	for _, arn := range arns {
+		// arn:aws:eks:us-east-1:111122223333:cluster/prod
+		if _, name, found := strings.Cut(arn, ":cluster/"); found {
+			tagged[name] = true
+		}
+	}
+	for _, n := range names {
+		if tagged[n] {
+			kept = append(kept, n)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, false
+	}
+	return kept, true
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	rgttypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/dantech2000/refresh/internal/selector"
+)
+
+// fakeTagging serves GetResources from clusters' tags, two per page, and
+// records the requests.
+type fakeTagging struct {
+	tags  map[string]map[string]string
+	err   error
+	calls []*resourcegroupstaggingapi.GetResourcesInput
+}
+
+func (f *fakeTagging) GetResources(_ context.Context, in *resourcegroupstaggingapi.GetResourcesInput, _ ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	f.calls = append(f.calls, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var arns []string
+	for _, name := range slices.Sorted(maps.Keys(f.tags)) {
+		if matchesFilters(f.tags[name], in.TagFilters) {
+			arns = append(arns, "arn:aws:eks:us-east-1:111122223333:cluster/"+name)
+		}
+	}
+	start := 0
+	if t := aws.ToString(in.PaginationToken); t != "" {
+		start = int(t[0] - '0')
+	}
+	end := min(start+2, len(arns))
+	out := &resourcegroupstaggingapi.GetResourcesOutput{PaginationToken: aws.String("")}
+	if end < len(arns) {
+		out.PaginationToken = aws.String(string(rune('0' + end)))
+	}
+	for _, arn := range arns[start:end] {
+		out.ResourceTagMappingList = append(out.ResourceTagMappingList, rgttypes.ResourceTagMapping{ResourceARN: aws.String(arn)})
+	}
+	return out, nil
+}
+
+func matchesFilters(tags map[string]string, filters []rgttypes.TagFilter) bool {
+	for _, f := range filters {
+		v, ok := tags[aws.ToString(f.Key)]
+		if !ok || (len(f.Values) > 0 && !slices.Contains(f.Values, v)) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTaggedClusters(t *testing.T) {
+	api := &fakeTagging{tags: map[string]map[string]string{
+		"prod-a":  {"env": "prod", "team": "web"},
+		"prod-b":  {"env": "prod", "team": "data"},
+		"prod-c":  {"env": "prod"},
+		"stage":   {"env": "staging", "team": "web"},
+		"deleted": {"env": "prod"}, // still tagged, no longer listed
+	}}
+	names := []string{"stage", "prod-c", "prod-b", "prod-a"}
+
+	sel, _ := selector.Parse("env=prod,team")
+	kept, ok := TaggedClusters(context.Background(), api, names, sel)
+	if !ok || !slices.Equal(kept, []string{"prod-b", "prod-a"}) {
+		t.Errorf("env=prod,team: kept %v, ok %v", kept, ok)
+	}
+	if in := api.calls[0]; !slices.Equal(in.ResourceTypeFilters, []string{"eks:cluster"}) || len(in.TagFilters) != 2 {
+		t.Errorf("request = %+v", in)
+	}
+
+	// Negative terms can't be asked of the index: the positive ones narrow
+	// the list and the caller checks the rest.
+	sel, _ = selector.Parse("env in (prod),team!=data")
+	kept, ok = TaggedClusters(context.Background(), api, names, sel)
+	if !ok || !slices.Equal(kept, []string{"prod-c", "prod-b", "prod-a"}) {
+		t.Errorf("env in (prod),team!=data: kept %v, ok %v", kept, ok)
+	}
+
+	// Nothing indexed matches: the tag may be newer than the index, so the
+	// caller describes every cluster rather than trusting an empty answer.
+	sel, _ = selector.Parse("team=payments")
+	if kept, ok := TaggedClusters(context.Background(), api, names, sel); ok || kept != nil {
+		t.Errorf("team=payments: kept %v, ok %v; want a fallback to DescribeCluster", kept, ok)
+	}
+
+	sel, _ = selector.Parse("!legacy,env!=dev")
+	calls := len(api.calls)
+	if _, ok := TaggedClusters(context.Background(), api, names, sel); ok || len(api.calls) != calls {
+		t.Error("a selector of only negative terms should fall back without calling GetResources")
+	}
+}
+
+func TestTaggedClusters_FallsBackOnAccessDenied(t *testing.T) {
+	api := &fakeTagging{err: &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform tag:GetResources"}}
+	sel, _ := selector.Parse("env=prod")
+	if kept, ok := TaggedClusters(context.Background(), api, []string{"prod"}, sel); ok || kept != nil {
+		t.Errorf("kept %v, ok %v; want a fallback to DescribeCluster", kept, ok)
+	}
+	if len(api.calls) != 1 {
+		t.Errorf("GetResources called %d times; AccessDenied isn't worth retrying", len(api.calls))
+	}
+}
@@ -0,0 +1,63 @@
+package awscache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// call is what the cache learns about one API call on its way down the
+// middleware stack.
+type call struct {
+	params any
+	// account is set when the call's credentials are retrieved, if they are
+	// tracked.
+	account string
+}
+
+type callKey struct{}
+
+// trackedCredentials tells the cache which account each call is made as.
+type trackedCredentials struct{ aws.CredentialsProvider }
+
+// TrackCredentials wraps p so calls made with its credentials can be cached.
+// Calls whose credentials aren't tracked always go to AWS: without knowing
+// the account, one account's answer could be served to another.
+func TrackCredentials(p aws.CredentialsProvider) aws.CredentialsProvider {
+	if p == nil {
+		return nil
+	}
+	if _, ok := p.(trackedCredentials); ok {
+		return p
+	}
+	return trackedCredentials{p}
+}
+
+// Retrieve implements aws.CredentialsProvider. The SDK retrieves credentials
+// for every call, with the call's context.
+func (t trackedCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := t.CredentialsProvider.Retrieve(ctx)
+	if err == nil {
+		if c, ok := middleware.GetStackValue(ctx, callKey{}).(*call); ok {
+			c.account = accountOf(creds)
+		}
+	}
+	return creds, err
+}
+
+// accountOf names the account credentials belong to: the one they name
+// (assumed roles, SSO), else a digest of the access key (plain IAM user
+// keys), which keeps accounts apart all the same.
+func accountOf(creds aws.Credentials) string {
+	if creds.AccountID != "" {
+		return creds.AccountID
+	}
+	if creds.AccessKeyID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(creds.AccessKeyID))
+	return "key-" + hex.EncodeToString(sum[:8])
+}
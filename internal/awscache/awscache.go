@@ -0,0 +1,460 @@
+// Package awscache is the opt-in (--cache) on-disk cache of AWS responses.
+// It sits in every SDK client's middleware stack, so services call AWS as
+// usual: reads it knows are answered from disk while fresh, and the
+// slow-changing catalogues (add-on versions, EKS AMI parameters, the support
+// calendar) are served stale while a background call revalidates them.
+// Responses are keyed by account, region, API operation and parameters.
+package awscache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// dirName is the cache directory under the refresh config dir.
+const dirName = "cache"
+
+// revalidateTimeout bounds a background revalidation, which outlives the
+// call that triggered it.
+const revalidateTimeout = 30 * time.Second
+
+// DefaultDir returns the cache directory: cache/ under cliconfig.CacheDir.
+func DefaultDir() (string, error) {
+	dir, err := cliconfig.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dirName), nil
+}
+
+// Cache is an on-disk response cache. It is safe for concurrent use, and by
+// several processes: entries are written atomically.
+type Cache struct {
+	dir string
+	now func() time.Time
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+	pending      sync.WaitGroup
+	hits         int
+	staleHits    int
+	misses       int
+}
+
+// New returns a cache stored in dir, which is created on first write.
+func New(dir string) *Cache {
+	return &Cache{dir: dir, now: time.Now, revalidating: map[string]bool{}}
+}
+
+// Dir is where the cache is stored.
+func (c *Cache) Dir() string { return c.dir }
+
+type bypassKey struct{}
+
+// Bypass returns a context whose AWS calls skip cached responses and go to
+// AWS; what they return is still cached. Commands that change state use it
+// so every decision they gate on is made on live data.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	v, _ := ctx.Value(bypassKey{}).(bool)
+	return v
+}
+
+// entry is one cached response on disk.
+type entry struct {
+	Account   string          `json:"account"`
+	Region    string          `json:"region"`
+	Service   string          `json:"service"`
+	Operation string          `json:"operation"`
+	StoredAt  time.Time       `json:"storedAt"`
+	Output    json.RawMessage `json:"output"`
+}
+
+// key locates a response.
+type key struct {
+	account, region, service, operation, digest string
+}
+
+// serviceDir is the directory of the key's service in its account and region.
+func (c *Cache) serviceDir(k key) string {
+	return filepath.Join(c.dir, pathSafe(k.account), pathSafe(k.region), pathSafe(k.service))
+}
+
+func (c *Cache) path(k key) string {
+	return filepath.Join(c.serviceDir(k), k.operation+"-"+k.digest+".json")
+}
+
+func pathSafe(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", " ", "-", "..", "_").Replace(s)
+}
+
+// digest identifies an operation's parameters.
+func digest(params any) (string, bool) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:12]), true
+}
+
+// AddToStack installs the cache on a client's middleware stack. It has the
+// shape of an aws.Config APIOptions entry. Lookups happen once the call's
+// credentials are resolved, which is when the account it is made as is
+// known, and before it is rate limited or signed.
+func (c *Cache) AddToStack(stack *middleware.Stack) error {
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RefreshCacheCall",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			return next.HandleInitialize(middleware.WithStackValue(ctx, callKey{}, &call{params: in.Parameters}), in)
+		}), middleware.Before)
+	if err != nil {
+		return err
+	}
+	mw := middleware.FinalizeMiddlewareFunc("RefreshCache", c.handleFinalize)
+	if _, ok := stack.Finalize.Get("GetIdentity"); ok {
+		return stack.Finalize.Insert(mw, "GetIdentity", middleware.After)
+	}
+	return stack.Finalize.Add(mw, middleware.Before)
+}
+
+func (c *Cache) handleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	cl, _ := middleware.GetStackValue(ctx, callKey{}).(*call)
+	if cl == nil || cl.account == "" {
+		return next.HandleFinalize(ctx, in)
+	}
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	p, cacheable := lookupPolicy(service, operation, cl.params)
+	mutation := !cacheable && isMutation(operation)
+	if !cacheable && !mutation {
+		return next.HandleFinalize(ctx, in)
+	}
+	k := key{account: cl.account, region: awsmiddleware.GetRegion(ctx), service: service, operation: operation}
+
+	if mutation {
+		out, md, err := next.HandleFinalize(ctx, in)
+		if err == nil {
+			c.invalidate(k)
+		}
+		return out, md, err
+	}
+
+	var ok bool
+	if k.digest, ok = digest(cl.params); !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+	if !bypassed(ctx) {
+		if result, storedAt, found := c.load(k, p); found {
+			switch age := c.now().Sub(storedAt); {
+			case age <= p.TTL:
+				c.count(&c.hits)
+				return middleware.FinalizeOutput{Result: result}, middleware.Metadata{}, nil
+			case age <= p.TTL+p.Stale:
+				c.count(&c.staleHits)
+				c.revalidate(ctx, in, next, k)
+				return middleware.FinalizeOutput{Result: result}, middleware.Metadata{}, nil
+			}
+		}
+	}
+	c.count(&c.misses)
+	out, md, err := next.HandleFinalize(ctx, in)
+	if err == nil {
+		c.store(k, out.Result)
+	}
+	return out, md, err
+}
+
+func (c *Cache) count(n *int) {
+	c.mu.Lock()
+	*n++
+	c.mu.Unlock()
+}
+
+// revalidate refreshes k in the background, once at a time per key.
+func (c *Cache) revalidate(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler, k key) {
+	path := c.path(k)
+	c.mu.Lock()
+	if c.revalidating[path] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[path] = true
+	c.mu.Unlock()
+
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, path)
+			c.mu.Unlock()
+		}()
+		// The caller has its answer and may be cancelled; this call is ours.
+		rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revalidateTimeout)
+		defer cancel()
+		if out, _, err := next.HandleFinalize(rctx, in); err == nil {
+			c.store(k, out.Result)
+		}
+	}()
+}
+
+// Wait waits up to timeout for background revalidations, so a process
+// doesn't exit with their answers unsaved.
+func (c *Cache) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// load returns the response cached for k and when it was stored.
+func (c *Cache) load(k key, p policy) (any, time.Time, bool) {
+	data, err := os.ReadFile(c.path(k))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil {
+		return nil, time.Time{}, false
+	}
+	out := p.newOutput()
+	if json.Unmarshal(e.Output, out) != nil {
+		return nil, time.Time{}, false
+	}
+	return out, e.StoredAt, true
+}
+
+// store writes result for k, atomically (temp + rename). Failures only cost
+// a later call.
+func (c *Cache) store(k key, result any) {
+	output, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(entry{
+		Account: k.account, Region: k.region, Service: k.service, Operation: k.operation,
+		StoredAt: c.now().UTC(), Output: output,
+	})
+	if err != nil {
+		return
+	}
+	path := c.path(k)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*.json")
+	if err != nil {
+		return
+	}
+	tmpName := tmp.Name()
+	_, werr := tmp.Write(data)
+	if cerr := tmp.Close(); werr != nil || cerr != nil || os.Rename(tmpName, path) != nil {
+		_ = os.Remove(tmpName)
+	}
+}
+
+// invalidate drops the short-lived entries of k's service in its account
+// and region: after a change, any of them may be out of date.
+func (c *Cache) invalidate(k key) {
+	dir := c.serviceDir(k)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		op, _, _ := strings.Cut(f.Name(), "-")
+		if p, ok := policies[k.service+"/"+op]; !ok || p.Stale == 0 {
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+		}
+	}
+}
+
+// OperationStats describes the cached responses of one operation.
+type OperationStats struct {
+	Service   string
+	Operation string
+	Entries   int
+	// Fresh entries are served as they are; Stale ones are served while
+	// being revalidated; Expired ones are no longer served.
+	Fresh   int
+	Stale   int
+	Expired int
+	Bytes   int64
+	// TTL and StaleFor are the operation's current policy.
+	TTL      time.Duration
+	StaleFor time.Duration
+}
+
+// Stats describes what the cache holds.
+type Stats struct {
+	Dir        string
+	Entries    int
+	Bytes      int64
+	Accounts   int
+	Operations []OperationStats
+}
+
+// Stats walks the cache. A cache that was never written is empty.
+func (c *Cache) Stats() (Stats, error) {
+	s := Stats{Dir: c.dir}
+	ops := map[string]*OperationStats{}
+	accountSet := map[string]bool{}
+	now := c.now()
+	err := c.walk(func(path string, size int64) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		var e entry
+		if json.Unmarshal(data, &e) != nil {
+			return
+		}
+		id := e.Service + "/" + e.Operation
+		o, ok := ops[id]
+		if !ok {
+			p := policies[id]
+			o = &OperationStats{Service: e.Service, Operation: e.Operation, TTL: p.TTL, StaleFor: p.Stale}
+			ops[id] = o
+		}
+		o.Entries++
+		o.Bytes += size
+		switch age := now.Sub(e.StoredAt); {
+		case o.TTL == 0:
+			o.Expired++
+		case age <= o.TTL:
+			o.Fresh++
+		case age <= o.TTL+o.StaleFor:
+			o.Stale++
+		default:
+			o.Expired++
+		}
+		s.Entries++
+		s.Bytes += size
+		accountSet[e.Account] = true
+	})
+	for _, o := range ops {
+		s.Operations = append(s.Operations, *o)
+	}
+	sort.Slice(s.Operations, func(i, j int) bool {
+		a, b := s.Operations[i], s.Operations[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Operation < b.Operation
+	})
+	s.Accounts = len(accountSet)
+	return s, err
+}
+
+// ClearOptions selects what Clear removes. The zero value removes every
+// cached response.
+type ClearOptions struct {
+	// Expired keeps responses that would still be served, fresh or stale.
+	Expired bool
+	// OlderThan, when set, keeps responses stored more recently than that.
+	OlderThan time.Duration
+	// DryRun counts what would be removed without removing it.
+	DryRun bool
+}
+
+// Clear removes the cached responses opts selects and returns how many it
+// removed, or with DryRun would remove. Unreadable entries are never served,
+// so they always go.
+func (c *Cache) Clear(opts ClearOptions) (int, error) {
+	now := c.now()
+	filtered := opts.Expired || opts.OlderThan > 0
+	removed := 0
+	err := c.walk(func(path string, _ int64) {
+		if filtered && c.keep(path, now, opts) {
+			return
+		}
+		if opts.DryRun || os.Remove(path) == nil {
+			removed++
+		}
+	})
+	if err == nil && !filtered && !opts.DryRun {
+		err = os.RemoveAll(c.dir)
+	}
+	return removed, err
+}
+
+// keep reports whether the entry at path survives opts.
+func (c *Cache) keep(path string, now time.Time, opts ClearOptions) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil {
+		return false
+	}
+	age := now.Sub(e.StoredAt)
+	if opts.OlderThan > 0 && age < opts.OlderThan {
+		return true
+	}
+	if opts.Expired {
+		p, known := policies[e.Service+"/"+e.Operation]
+		return known && age <= p.TTL+p.Stale
+	}
+	return false
+}
+
+// walk calls fn for every entry file.
+func (c *Cache) walk(fn func(path string, size int64)) error {
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fn(path, info.Size())
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// WriteStats writes this process's hits and misses, for --verbose.
+func (c *Cache) WriteStats(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hits+c.staleHits+c.misses == 0 {
+		return
+	}
+	fmt.Fprintf(w, "AWS response cache (%s): %d hits, %d served stale and revalidated, %d misses\n",
+		c.dir, c.hits, c.staleHits, c.misses)
+}
@@ -0,0 +1,292 @@
+package awscache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
+
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// eksTransport answers EKS calls, numbering its answers so a test can tell
+// a cached response from a fresh one.
+type eksTransport struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (t *eksTransport) Do(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if t.calls == nil {
+		t.calls = map[string]int{}
+	}
+	t.calls[r.URL.Path]++
+	n := t.calls[r.URL.Path]
+	t.mu.Unlock()
+
+	var body string
+	switch {
+	case r.URL.Path == "/clusters":
+		body = fmt.Sprintf(`{"clusters":["prod-%d"]}`, n)
+	case r.URL.Path == "/addons/supported-versions":
+		body = fmt.Sprintf(`{"addons":[{"addonName":"vpc-cni-%d"}]}`, n)
+	case strings.HasSuffix(r.URL.Path, "/update-config"):
+		body = `{"update":{"id":"u-1"}}`
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{"X-Amzn-Errortype": []string{"ResourceNotFoundException"}},
+			Body: io.NopCloser(strings.NewReader(`{"message":"not found"}`))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (t *eksTransport) count(path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls[path]
+}
+
+func testCache(t *testing.T) (*Cache, *time.Time) {
+	t.Helper()
+	c := New(t.TempDir())
+	clock := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return clock }
+	return c, &clock
+}
+
+func eksClient(c *Cache, transport *eksTransport, creds aws.CredentialsProvider) *eks.Client {
+	return eks.NewFromConfig(aws.Config{
+		Region:           "us-east-1",
+		Credentials:      creds,
+		HTTPClient:       transport,
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*smithymiddleware.Stack) error{c.AddToStack},
+	})
+}
+
+func account(id string) aws.CredentialsProvider {
+	return TrackCredentials(credentials.StaticCredentialsProvider{Value: aws.Credentials{
+		AccessKeyID: "AKID" + id, SecretAccessKey: "SECRET", AccountID: id,
+	}})
+}
+
+func firstCluster(t *testing.T, client *eks.Client, ctx context.Context) string {
+	t.Helper()
+	out, err := client.ListClusters(ctx, &eks.ListClustersInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Clusters[0]
+}
+
+func firstAddon(t *testing.T, client *eks.Client) string {
+	t.Helper()
+	out, err := client.DescribeAddonVersions(context.Background(), &eks.DescribeAddonVersionsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aws.ToString(out.Addons[0].AddonName)
+}
+
+func TestCacheFreshStaleAndExpired(t *testing.T) {
+	c, clock := testCache(t)
+	transport := &eksTransport{}
+	client := eksClient(c, transport, account("111111111111"))
+	ctx := context.Background()
+
+	if got := firstCluster(t, client, ctx); got != "prod-1" {
+		t.Fatalf("first call = %s", got)
+	}
+	if got := firstCluster(t, client, ctx); got != "prod-1" || transport.count("/clusters") != 1 {
+		t.Errorf("a call within the TTL should be served from disk: got %s after %d calls", got, transport.count("/clusters"))
+	}
+	// Lists aren't served stale: past the TTL they go to AWS.
+	*clock = clock.Add(6 * time.Minute)
+	if got := firstCluster(t, client, ctx); got != "prod-2" {
+		t.Errorf("past the TTL = %s, want a fresh answer", got)
+	}
+
+	if got := firstAddon(t, client); got != "vpc-cni-1" {
+		t.Fatalf("first catalogue call = %s", got)
+	}
+	*clock = clock.Add(7 * time.Hour)
+	// Stale: answered at once from disk while a background call revalidates.
+	if got := firstAddon(t, client); got != "vpc-cni-1" {
+		t.Errorf("stale call = %s, want the cached answer", got)
+	}
+	c.Wait(5 * time.Second)
+	if n := transport.count("/addons/supported-versions"); n != 2 {
+		t.Errorf("revalidation calls = %d, want 1", n-1)
+	}
+	if got := firstAddon(t, client); got != "vpc-cni-2" {
+		t.Errorf("after revalidation = %s, want the revalidated answer", got)
+	}
+
+	*clock = clock.Add(8 * 24 * time.Hour)
+	if got := firstAddon(t, client); got != "vpc-cni-3" {
+		t.Errorf("past the stale window = %s, want a live answer", got)
+	}
+	var out strings.Builder
+	c.WriteStats(&out)
+	if !strings.Contains(out.String(), "2 hits, 1 served stale and revalidated, 4 misses") {
+		t.Errorf("WriteStats = %q", out.String())
+	}
+}
+
+func TestCacheBypassAndMutations(t *testing.T) {
+	c, _ := testCache(t)
+	transport := &eksTransport{}
+	client := eksClient(c, transport, account("111111111111"))
+	ctx := context.Background()
+
+	firstCluster(t, client, ctx)
+	firstAddon(t, client)
+	// A bypassed call goes to AWS, and its answer replaces the cached one.
+	if got := firstCluster(t, client, Bypass(ctx)); got != "prod-2" {
+		t.Errorf("bypassed call = %s, want a live answer", got)
+	}
+	if got := firstCluster(t, client, ctx); got != "prod-2" {
+		t.Errorf("after a bypass = %s, want the bypassed answer cached", got)
+	}
+
+	_, err := client.UpdateNodegroupConfig(ctx, &eks.UpdateNodegroupConfigInput{
+		ClusterName: aws.String("prod"), NodegroupName: aws.String("workers"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := firstCluster(t, client, ctx); got != "prod-3" {
+		t.Errorf("after an update = %s, want the list invalidated", got)
+	}
+	// Catalogues don't change with the account's own resources.
+	if got := firstAddon(t, client); got != "vpc-cni-1" || transport.count("/addons/supported-versions") != 1 {
+		t.Errorf("after an update the catalogue = %s, want it still cached", got)
+	}
+}
+
+func TestCacheKeysAccountsApart(t *testing.T) {
+	c, _ := testCache(t)
+	transport := &eksTransport{}
+	ctx := context.Background()
+
+	firstCluster(t, eksClient(c, transport, account("111111111111")), ctx)
+	if got := firstCluster(t, eksClient(c, transport, account("222222222222")), ctx); got != "prod-2" {
+		t.Errorf("another account = %s, want its own answer", got)
+	}
+	// Without an account ID, the access key tells accounts apart.
+	keyOnly := TrackCredentials(credentials.NewStaticCredentialsProvider("AKIDUSER", "SECRET", ""))
+	if got := firstCluster(t, eksClient(c, transport, keyOnly), ctx); got != "prod-3" {
+		t.Errorf("access-key credentials = %s, want their own answer", got)
+	}
+	// Untracked credentials can't be told apart, so nothing is cached for them.
+	untracked := eksClient(c, transport, credentials.NewStaticCredentialsProvider("AKIDUSER", "SECRET", ""))
+	firstCluster(t, untracked, ctx)
+	firstCluster(t, untracked, ctx)
+	if n := transport.count("/clusters"); n != 5 {
+		t.Errorf("calls = %d, want 5", n)
+	}
+
+	s, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Entries != 3 || s.Accounts != 3 || len(s.Operations) != 1 || s.Operations[0].Fresh != 3 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestCachePolicies(t *testing.T) {
+	if _, ok := lookupPolicy("SSM", "GetParameter", &ssm.GetParameterInput{
+		Name: aws.String("/aws/service/eks/optimized-ami/1.32/amazon-linux-2023/x86_64/standard/recommended/image_id"),
+	}); !ok {
+		t.Error("EKS AMI parameters should be cached")
+	}
+	if _, ok := lookupPolicy("SSM", "GetParameter", &ssm.GetParameterInput{Name: aws.String("/team/golden-ami")}); ok {
+		t.Error("other SSM parameters must not be cached")
+	}
+	if _, ok := lookupPolicy("EC2", "DescribeInstances", nil); ok {
+		t.Error("operations without a policy must not be cached")
+	}
+	for op, want := range map[string]bool{"UpdateNodegroupVersion": true, "CreateAddon": true, "DescribeCluster": false, "ListClusters": false} {
+		if isMutation(op) != want {
+			t.Errorf("isMutation(%s) = %v", op, !want)
+		}
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	c, clock := testCache(t)
+	client := eksClient(c, &eksTransport{}, account("111111111111"))
+	firstCluster(t, client, context.Background())
+	*clock = clock.Add(time.Hour)
+	firstAddon(t, client)
+
+	n, err := c.Clear(ClearOptions{Expired: true})
+	if err != nil || n != 1 {
+		t.Fatalf("Clear(expired) = %d, %v; want the expired list only", n, err)
+	}
+	if s, _ := c.Stats(); s.Entries != 1 || s.Operations[0].Operation != "DescribeAddonVersions" {
+		t.Errorf("after clearing expired: %+v", s)
+	}
+	if n, err := c.Clear(ClearOptions{}); err != nil || n != 1 {
+		t.Errorf("Clear = %d, %v", n, err)
+	}
+	if s, err := c.Stats(); err != nil || s.Entries != 0 {
+		t.Errorf("after clearing: %+v, %v", s, err)
+	}
+}
+
+func TestCacheHitSkipsRateLimiter(t *testing.T) {
+	c, _ := testCache(t)
+	transport := &eksTransport{}
+	// One token, then one every 1000s: a hit that waited on the limiter
+	// would outlive the test's deadline.
+	slow := common.NewRateLimiter(common.LimiterConfig{Rate: 0.001, Burst: 1})
+	// The order awsconfig.Load installs them in.
+	client := eks.NewFromConfig(aws.Config{
+		Region:           "us-east-1",
+		Credentials:      account("111111111111"),
+		HTTPClient:       transport,
+		RetryMaxAttempts: 1,
+		APIOptions: []func(*smithymiddleware.Stack) error{
+			common.SharedRateLimiter.AddToStack, slow.AddToStack, c.AddToStack,
+		},
+	})
+	calls := func() int {
+		n := 0
+		for _, s := range common.SharedRateLimiter.Stats() {
+			if s.Service == "EKS" && s.Region == "us-east-1" {
+				n += s.Calls
+			}
+		}
+		return n
+	}
+
+	before := calls()
+	firstCluster(t, client, context.Background())
+	if got := calls() - before; got != 1 {
+		t.Fatalf("limited calls after a miss = %d, want 1", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for range 3 {
+		firstCluster(t, client, ctx)
+	}
+	if got := calls() - before; got != 1 || transport.count("/clusters") != 1 {
+		t.Errorf("limited calls after cache hits = %d (AWS calls %d), want the miss only", got, transport.count("/clusters"))
+	}
+	if s := slow.Stats(); len(s) != 1 || s[0].Calls != 1 || s[0].Waited != 0 {
+		t.Errorf("cache hits took limiter tokens: %+v", s)
+	}
+}
@@ -0,0 +1,84 @@
+package awscache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// policy is how one API operation is cached.
+type policy struct {
+	// TTL is how long a response is served as fresh.
+	TTL time.Duration
+	// Stale, when set, is how long past TTL a response is still served
+	// while a background call revalidates it. Only slow-changing catalogues
+	// get one; they also survive mutations.
+	Stale time.Duration
+	// newOutput returns the operation's output type to decode into.
+	newOutput func() any
+	// cacheable, when set, narrows the policy to the inputs it accepts.
+	cacheable func(input any) bool
+}
+
+// eksAMIParameters is where EKS publishes its optimized AMI IDs. Only these
+// SSM parameters are cached: others (a team's golden-AMI parameter) can
+// change at any time and mean something else.
+const eksAMIParameters = "/aws/service/eks/"
+
+// policies are the cached operations, by service ID and operation name.
+// Cluster, nodegroup and add-on state changes under refresh's own feet, so it
+// is only kept briefly; the catalogues of published versions change a few
+// times a month and are revalidated in the background.
+var policies = map[string]policy{
+	"EKS/ListClusters":      {TTL: 5 * time.Minute, newOutput: func() any { return &eks.ListClustersOutput{} }},
+	"EKS/DescribeCluster":   {TTL: time.Minute, newOutput: func() any { return &eks.DescribeClusterOutput{} }},
+	"EKS/ListNodegroups":    {TTL: 5 * time.Minute, newOutput: func() any { return &eks.ListNodegroupsOutput{} }},
+	"EKS/DescribeNodegroup": {TTL: time.Minute, newOutput: func() any { return &eks.DescribeNodegroupOutput{} }},
+	"EKS/ListAddons":        {TTL: 5 * time.Minute, newOutput: func() any { return &eks.ListAddonsOutput{} }},
+	"EKS/DescribeAddon":     {TTL: time.Minute, newOutput: func() any { return &eks.DescribeAddonOutput{} }},
+
+	"EKS/DescribeAddonVersions": {TTL: 6 * time.Hour, Stale: 7 * 24 * time.Hour,
+		newOutput: func() any { return &eks.DescribeAddonVersionsOutput{} }},
+	"EKS/DescribeClusterVersions": {TTL: 24 * time.Hour, Stale: 30 * 24 * time.Hour,
+		newOutput: func() any { return &eks.DescribeClusterVersionsOutput{} }},
+	"SSM/GetParameter": {TTL: 6 * time.Hour, Stale: 7 * 24 * time.Hour,
+		newOutput: func() any { return &ssm.GetParameterOutput{} },
+		cacheable: func(in any) bool {
+			p, ok := in.(*ssm.GetParameterInput)
+			return ok && p.Name != nil && strings.HasPrefix(*p.Name, eksAMIParameters)
+		}},
+	"SSM/GetParametersByPath": {TTL: 6 * time.Hour, Stale: 7 * 24 * time.Hour,
+		newOutput: func() any { return &ssm.GetParametersByPathOutput{} },
+		cacheable: func(in any) bool {
+			p, ok := in.(*ssm.GetParametersByPathInput)
+			return ok && p.Path != nil && strings.HasPrefix(*p.Path, eksAMIParameters)
+		}},
+}
+
+// lookupPolicy returns how service's operation with input is cached.
+func lookupPolicy(service, operation string, input any) (policy, bool) {
+	p, ok := policies[service+"/"+operation]
+	if !ok || (p.cacheable != nil && !p.cacheable(input)) {
+		return policy{}, false
+	}
+	return p, true
+}
+
+// mutatingPrefixes mark the operations that change state. Their success
+// invalidates the service's short-lived entries in that account and region.
+var mutatingPrefixes = []string{
+	"Associate", "Attach", "Cancel", "Complete", "Create", "Delete", "Deregister",
+	"Detach", "Disassociate", "Modify", "Put", "Reboot", "Register", "Set",
+	"Start", "Stop", "Tag", "Terminate", "Untag", "Update",
+}
+
+func isMutation(operation string) bool {
+	for _, p := range mutatingPrefixes {
+		if strings.HasPrefix(operation, p) {
+			return true
+		}
+	}
+	return false
+}
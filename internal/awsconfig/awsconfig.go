@@ -11,11 +11,32 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/awscache"
 	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/sandbox"
+	"github.com/dantech2000/refresh/internal/services/common"
 )
 
+// sim, when set by the global --sandbox flag, serves every AWS call Load's
+// configs make instead of AWS.
+var sim *sandbox.Sim
+
+// UseSandbox routes every config Load returns to the simulation; nil restores
+// real AWS.
+func UseSandbox(s *sandbox.Sim) { sim = s }
+
+// cache, when set by the global --cache flag, answers the reads it knows
+// from disk.
+var cache *awscache.Cache
+
+// UseCache installs the response cache on every config Load returns; nil
+// turns it off.
+func UseCache(c *awscache.Cache) { cache = c }
+
 // Load returns an aws.Config with profile/region resolved from (in order):
 //
 //  1. CLI flags --profile / --region (if cmd is non-nil and they are set)
@@ -24,23 +45,35 @@ import (
 //  4. AWS SDK defaults (~/.aws/config, IMDS, etc.)
 //
 // CLI-supplied values always win so the user can override the active context
-// for a single invocation.
+// for a single invocation. A context with a role assumes it on top of the
+// resolved credentials, unless --profile picked other credentials. Calls made
+// through the config are rate limited per service and region by
+// common.SharedRateLimiter, and answered from the response cache under
+// --cache.
+//
+// In sandbox mode only --region applies; the simulation's default region
+// stands in for the rest of the chain.
 func Load(ctx context.Context, cmd *cli.Command) (aws.Config, error) {
+	if sim != nil {
+		return sim.Config(flagOrEmpty(cmd, "region")), nil
+	}
 	var opts []func(*config.LoadOptions) error
 
 	profile := flagOrEmpty(cmd, "profile")
 	region := flagOrEmpty(cmd, "region")
 	profileFromFlag := profile != ""
 	regionFromFlag := region != ""
+	role := ""
 
-	if profile == "" || region == "" {
-		if active, ok := activeContext(); ok {
-			if profile == "" && active.Profile != "" {
-				profile = active.Profile
-			}
-			if region == "" && active.Region != "" {
-				region = active.Region
-			}
+	if active, ok := activeContext(); ok {
+		if profile == "" && active.Profile != "" {
+			profile = active.Profile
+		}
+		if region == "" && active.Region != "" {
+			region = active.Region
+		}
+		if !profileFromFlag {
+			role = active.Role
 		}
 	}
 
@@ -56,7 +89,58 @@ func Load(ctx context.Context, cmd *cli.Command) (aws.Config, error) {
 		opts = append(opts, config.WithRegion(region))
 	}
 
-	return config.LoadDefaultConfig(ctx, opts...)
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return cfg, err
+	}
+	// Every client shares the process-wide adaptive limiter, so concurrent
+	// fan-outs back off together when a service throttles.
+	cfg.APIOptions = append(cfg.APIOptions, common.SharedRateLimiter.AddToStack)
+	if role != "" {
+		cfg = AssumeRole(cfg, role)
+	}
+	if cache != nil {
+		cfg.APIOptions = append(cfg.APIOptions, cache.AddToStack)
+		cfg.Credentials = awscache.TrackCredentials(cfg.Credentials)
+	}
+	return cfg, nil
+}
+
+// Resolved returns the profile and region Load's configs use, in the same
+// precedence: flags, then AWS_PROFILE/AWS_REGION/AWS_DEFAULT_REGION, then the
+// active refresh context. Empty means the SDK's own defaults apply.
+func Resolved(cmd *cli.Command) (profile, region string) {
+	profile, region = flagOrEmpty(cmd, "profile"), flagOrEmpty(cmd, "region")
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if active, ok := activeContext(); ok {
+		if profile == "" {
+			profile = active.Profile
+		}
+		if region == "" {
+			region = active.Region
+		}
+	}
+	return profile, region
+}
+
+// roleSessionName tags assumed-role sessions so CloudTrail shows who called.
+const roleSessionName = "refresh-cli"
+
+// AssumeRole returns a copy of cfg whose credentials assume roleARN with
+// cfg's own credentials.
+func AssumeRole(cfg aws.Config, roleARN string) aws.Config {
+	out := cfg.Copy()
+	out.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN,
+		func(o *stscreds.AssumeRoleOptions) { o.RoleSessionName = roleSessionName }))
+	return out
 }
 
 func flagOrEmpty(cmd *cli.Command, name string) string {
@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+
 	"github.com/dantech2000/refresh/internal/cliconfig"
 	"github.com/urfave/cli/v3"
 )
@@ -187,3 +190,26 @@ func TestLoadUsesContextProfile(t *testing.T) {
 		t.Fatalf("cfg.Region = %q, want context profile region eu-central-1", cfg.Region)
 	}
 }
+
+func TestLoadAssumesContextRoleUnlessProfileFlagSet(t *testing.T) {
+	setupContext(t, "payments", cliconfig.Context{Cluster: "x", Region: "us-east-1", Role: "arn:aws:iam::222222222222:role/refresh"})
+
+	cfg, err := Load(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cache, ok := cfg.Credentials.(*aws.CredentialsCache)
+	if !ok || !cache.IsCredentialsProvider(&stscreds.AssumeRoleProvider{}) {
+		t.Fatalf("credentials = %T, want the context role assumed", cfg.Credentials)
+	}
+
+	setupAWSConfigFile(t)
+	cmd := newParsedCommand(t, []cli.Flag{&cli.StringFlag{Name: "profile"}}, "--profile", "flag-profile")
+	cfg, err = Load(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cache, ok := cfg.Credentials.(*aws.CredentialsCache); ok && cache.IsCredentialsProvider(&stscreds.AssumeRoleProvider{}) {
+		t.Error("--profile should replace the context's credentials, role included")
+	}
+}
@@ -0,0 +1,144 @@
+package cliconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict is an incoming context that wasn't saved because of one already in
+// the file: the same name pointing elsewhere, or (a duplicate) another name
+// pointing at the same cluster.
+type Conflict struct {
+	Name         string  `json:"name" yaml:"name"`
+	Incoming     Context `json:"incoming" yaml:"incoming"`
+	ExistingName string  `json:"existingName" yaml:"existingName"`
+	Existing     Context `json:"existing" yaml:"existing"`
+}
+
+// Reason describes the collision for display.
+func (c Conflict) Reason() string {
+	if c.ExistingName == c.Name {
+		return fmt.Sprintf("%q already points at %s", c.Name, describe(c.Existing))
+	}
+	return fmt.Sprintf("%s is already saved as %q", describe(c.Incoming), c.ExistingName)
+}
+
+func describe(c Context) string {
+	s := c.Cluster
+	if c.Region != "" {
+		s += " in " + c.Region
+	}
+	if c.Profile != "" {
+		s += " (profile " + c.Profile + ")"
+	}
+	if c.Role != "" {
+		s += " via " + c.Role
+	}
+	return s
+}
+
+// MergeReport is what a bulk merge did, name by name.
+type MergeReport struct {
+	Added     []string `json:"added,omitempty" yaml:"added,omitempty"`
+	Updated   []string `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Unchanged []string `json:"unchanged,omitempty" yaml:"unchanged,omitempty"`
+	Pruned    []string `json:"pruned,omitempty" yaml:"pruned,omitempty"`
+	// Duplicates reach a cluster another saved context already reaches;
+	// they're skipped.
+	Duplicates []Conflict `json:"duplicates,omitempty" yaml:"duplicates,omitempty"`
+	// Conflicts are names already saved with a different target; they're
+	// left as they are unless the merge overwrites.
+	Conflicts []Conflict `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+}
+
+// Changed reports whether the merge modified the file.
+func (r MergeReport) Changed() bool {
+	return len(r.Added)+len(r.Updated)+len(r.Pruned) > 0
+}
+
+// Merge adds incoming contexts. A name already saved with the same target is
+// left alone; one saved with a different target is a conflict, replaced only
+// when overwrite is set. A new name for a cluster another context already
+// reaches is a duplicate and skipped.
+func (f *File) Merge(incoming map[string]Context, overwrite bool) MergeReport {
+	if f.Contexts == nil {
+		f.Contexts = map[string]Context{}
+	}
+	var r MergeReport
+	names := make([]string, 0, len(incoming))
+	for n := range incoming {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		in := incoming[name]
+		if existing, ok := f.Contexts[name]; ok {
+			switch {
+			case existing.SameTarget(in):
+				r.Unchanged = append(r.Unchanged, name)
+			case overwrite:
+				f.Contexts[name] = in
+				r.Updated = append(r.Updated, name)
+			default:
+				r.Conflicts = append(r.Conflicts, Conflict{Name: name, Incoming: in, ExistingName: name, Existing: existing})
+			}
+			continue
+		}
+		if other, existing, ok := f.findTarget(in); ok {
+			r.Duplicates = append(r.Duplicates, Conflict{Name: name, Incoming: in, ExistingName: other, Existing: existing})
+			continue
+		}
+		f.Contexts[name] = in
+		r.Added = append(r.Added, name)
+	}
+	return r
+}
+
+// findTarget returns the saved context with the same target as c.
+func (f *File) findTarget(c Context) (string, Context, bool) {
+	for _, n := range f.Names() {
+		if f.Contexts[n].SameTarget(c) {
+			return n, f.Contexts[n], true
+		}
+	}
+	return "", Context{}, false
+}
+
+// Export returns the named contexts (all when names is empty) as a file to
+// share: no current/previous pointers and no sync bookkeeping.
+func (f *File) Export(names []string) (*File, error) {
+	if len(names) == 0 {
+		names = f.Names()
+	}
+	out := &File{Contexts: map[string]Context{}}
+	for _, n := range names {
+		c, ok := f.Contexts[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown context %q", n)
+		}
+		c.Managed = ""
+		out.Contexts[n] = c
+	}
+	return out, nil
+}
+
+// ParseContexts reads the contexts of an exported (or any) context file.
+// Every context needs a cluster.
+func ParseContexts(data []byte) (map[string]Context, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Contexts) == 0 {
+		return nil, errors.New("no contexts found")
+	}
+	for n, c := range f.Contexts {
+		if c.Cluster == "" {
+			return nil, fmt.Errorf("context %q: cluster is required", n)
+		}
+	}
+	return f.Contexts, nil
+}
@@ -0,0 +1,80 @@
+package cliconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMerge(t *testing.T) {
+	f := &File{Contexts: map[string]Context{
+		"prod": {Cluster: "prod-eks", Region: "us-east-1"},
+		"dev":  {Cluster: "dev-eks", Region: "us-west-2"},
+	}}
+	incoming := map[string]Context{
+		"prod":     {Cluster: "prod-eks", Region: "us-east-1"}, // same target
+		"dev":      {Cluster: "dev-eks", Region: "eu-west-1"},  // name taken
+		"dev-copy": {Cluster: "dev-eks", Region: "us-west-2"},  // target taken
+		"stage":    {Cluster: "stage-eks", Region: "us-east-1", Role: "arn:aws:iam::1:role/r"},
+	}
+
+	r := f.Merge(incoming, false)
+	if !reflect.DeepEqual(r.Added, []string{"stage"}) || !reflect.DeepEqual(r.Unchanged, []string{"prod"}) || len(r.Updated) != 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	if len(r.Conflicts) != 1 || r.Conflicts[0].Name != "dev" || !strings.Contains(r.Conflicts[0].Reason(), "already points at dev-eks in us-west-2") {
+		t.Errorf("conflicts = %+v", r.Conflicts)
+	}
+	if len(r.Duplicates) != 1 || r.Duplicates[0].ExistingName != "dev" || !strings.Contains(r.Duplicates[0].Reason(), `already saved as "dev"`) {
+		t.Errorf("duplicates = %+v", r.Duplicates)
+	}
+	if f.Contexts["dev"].Region != "us-west-2" {
+		t.Errorf("conflicting context was overwritten: %+v", f.Contexts["dev"])
+	}
+	if !r.Changed() {
+		t.Error("Changed() = false after an add")
+	}
+
+	r = f.Merge(map[string]Context{"dev": {Cluster: "dev-eks", Region: "eu-west-1"}}, true)
+	if !reflect.DeepEqual(r.Updated, []string{"dev"}) || f.Contexts["dev"].Region != "eu-west-1" {
+		t.Errorf("overwrite: report = %+v, dev = %+v", r, f.Contexts["dev"])
+	}
+}
+
+func TestExportAndParseContexts(t *testing.T) {
+	f := &File{Current: "prod", Previous: "dev", Contexts: map[string]Context{
+		"prod": {Cluster: "prod-eks", Region: "us-east-1", Managed: ManagedBySync},
+		"dev":  {Cluster: "dev-eks"},
+	}}
+	out, err := f.Export(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Current != "" || out.Previous != "" || out.Contexts["prod"].Managed != "" || len(out.Contexts) != 2 {
+		t.Errorf("export = %+v", out)
+	}
+	if _, err := f.Export([]string{"nope"}); err == nil {
+		t.Error("exporting an unknown context should fail")
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseContexts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, out.Contexts) {
+		t.Errorf("round trip = %+v, want %+v", parsed, out.Contexts)
+	}
+
+	if _, err := ParseContexts([]byte("contexts: {}\n")); err == nil || !strings.Contains(err.Error(), "no contexts") {
+		t.Errorf("empty file err = %v", err)
+	}
+	if _, err := ParseContexts([]byte("contexts:\n  x:\n    region: us-east-1\n")); err == nil || !strings.Contains(err.Error(), "cluster is required") {
+		t.Errorf("missing cluster err = %v", err)
+	}
+}
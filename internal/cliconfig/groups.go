@@ -0,0 +1,64 @@
+package cliconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dantech2000/refresh/internal/selector"
+)
+
+// GroupNames returns the sorted names of the saved context groups.
+func (f *File) GroupNames() []string {
+	names := make([]string, 0, len(f.Groups))
+	for n := range f.Groups {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetGroup saves a named tag selector, stored in its canonical form.
+func (f *File) SetGroup(name, expr string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("group name must not be empty")
+	}
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return err
+	}
+	if sel.Empty() {
+		return fmt.Errorf("group %q: selector must not be empty", name)
+	}
+	if f.Groups == nil {
+		f.Groups = map[string]string{}
+	}
+	f.Groups[name] = sel.String()
+	return nil
+}
+
+// RemoveGroup deletes a saved group.
+func (f *File) RemoveGroup(name string) error {
+	if _, ok := f.Groups[name]; !ok {
+		return fmt.Errorf("unknown group %q", name)
+	}
+	delete(f.Groups, name)
+	return nil
+}
+
+// Group returns the selector saved as name.
+func (f *File) Group(name string) (selector.Selector, error) {
+	expr, ok := f.Groups[name]
+	if !ok {
+		if len(f.Groups) == 0 {
+			return selector.Selector{}, fmt.Errorf("unknown group %q (none saved; see 'refresh context group add')", name)
+		}
+		return selector.Selector{}, fmt.Errorf("unknown group %q (saved: %s)", name, strings.Join(f.GroupNames(), ", "))
+	}
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return selector.Selector{}, fmt.Errorf("group %q: %w", name, err)
+	}
+	return sel, nil
+}
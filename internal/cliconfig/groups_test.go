@@ -0,0 +1,44 @@
+package cliconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	withTempHome(t)
+	f := &File{Contexts: map[string]Context{}}
+	if err := f.SetGroup("prod", "env=prod,team notin (ml, data)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetGroup("bad", "env in prod"); err == nil {
+		t.Error("a malformed selector should be rejected")
+	}
+	if err := f.SetGroup("empty", " "); err == nil {
+		t.Error("an empty selector should be rejected")
+	}
+	if err := Save(f); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Groups["prod"]; got != "env=prod,team notin (data,ml)" {
+		t.Errorf("saved selector = %q, want the canonical form", got)
+	}
+	sel, err := loaded.Group("prod")
+	if err != nil || !sel.Matches(map[string]string{"env": "prod", "team": "web"}) || sel.Matches(map[string]string{"env": "prod", "team": "ml"}) {
+		t.Errorf("Group(prod) = %s, %v", sel, err)
+	}
+	if _, err := loaded.Group("dev"); err == nil || !strings.Contains(err.Error(), "saved: prod") {
+		t.Errorf("unknown group err = %v", err)
+	}
+	if err := loaded.RemoveGroup("prod"); err != nil || len(loaded.GroupNames()) != 0 {
+		t.Errorf("RemoveGroup: %v, left %v", err, loaded.GroupNames())
+	}
+	if err := loaded.RemoveGroup("prod"); err == nil {
+		t.Error("removing an unknown group should fail")
+	}
+}
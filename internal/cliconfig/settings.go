@@ -0,0 +1,590 @@
+package cliconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SettingsFileName is the layered defaults file. A repo-local copy is found by
+// walking up from the working directory; the user copy lives beside
+// context.yaml.
+const SettingsFileName = "refresh.yaml"
+
+var getwd = os.Getwd
+
+// Settings is one refresh.yaml document. The top level is a Scope that
+// applies everywhere; tags.<key=glob>, clusters.<glob> and contexts.<name> are
+// narrower scopes layered on top of it.
+//
+//	defaults:                 # any command that has the flag
+//	  require-healthy: true
+//	commands:
+//	  nodegroup update:
+//	    poll-interval: 30s
+//	clusters:
+//	  prod-*:
+//	    commands:
+//	      addon update: {skip: ["@helm"]}
+//	contexts:
+//	  gpu:
+//	    health: {thresholds: {peakFailCPUPercent: 98}}
+//	tags:
+//	  env=prod:
+//	    sla: {maxAmiAgeDays: 30}
+//	skipLists:
+//	  helm: [aws-load-balancer-controller, external-dns]
+type Settings struct {
+	Scope    `yaml:",inline"`
+	Clusters map[string]Scope `yaml:"clusters,omitempty"`
+	Contexts map[string]Scope `yaml:"contexts,omitempty"`
+	// Tags scopes apply to clusters carrying a matching EKS tag; the key is
+	// "key=glob". Only commands that read cluster tags (status) resolve them.
+	Tags      map[string]Scope    `yaml:"tags,omitempty"`
+	SkipLists map[string][]string `yaml:"skipLists,omitempty"`
+}
+
+// Scope is a set of defaults: flag values for every command, flag values per
+// command path ("nodegroup update"), and the health, canary and sla blocks. Flag
+// values are keyed by long flag name and hold a scalar or, for repeatable
+// flags, a list.
+type Scope struct {
+	Defaults map[string]any            `yaml:"defaults,omitempty"`
+	Commands map[string]map[string]any `yaml:"commands,omitempty"`
+	Health   *HealthSettings           `yaml:"health,omitempty"`
+	Canary   *CanarySettings           `yaml:"canary,omitempty"`
+	SLA      *SLASettings              `yaml:"sla,omitempty"`
+}
+
+// HealthSettings tunes the pre-flight health checks.
+type HealthSettings struct {
+	// Thresholds overrides named check thresholds (e.g. peakFailCPUPercent).
+	Thresholds map[string]float64 `yaml:"thresholds,omitempty" json:"thresholds,omitempty"`
+	// Checks, when set, limits the run to these checks.
+	Checks []string `yaml:"checks,omitempty" json:"checks,omitempty"`
+	// SkipChecks drops these checks from the run.
+	SkipChecks []string `yaml:"skipChecks,omitempty" json:"skipChecks,omitempty"`
+	// Blocking overrides whether a check's failure blocks the update.
+	Blocking map[string]bool `yaml:"blocking,omitempty" json:"blocking,omitempty"`
+}
+
+// CanarySettings orders a fleet roll: clusters matching Clusters go first, and
+// the rest only start once they finish cleanly and Soak has elapsed.
+type CanarySettings struct {
+	Clusters []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+	Soak     string   `yaml:"soak,omitempty" json:"soak,omitempty"`
+}
+
+// SLASettings is the nodegroup AMI patch policy `refresh status` enforces. A
+// zero limit isn't enforced.
+type SLASettings struct {
+	// MaxAMIAgeDays is the oldest a stale nodegroup's AMI may get.
+	MaxAMIAgeDays int `yaml:"maxAmiAgeDays,omitempty" json:"maxAmiAgeDays,omitempty"`
+	// MaxReleasesBehind is how many amazon-eks-ami releases a nodegroup may
+	// trail the newest.
+	MaxReleasesBehind int `yaml:"maxReleasesBehind,omitempty" json:"maxReleasesBehind,omitempty"`
+	// WarnDays is how long before an age breach a nodegroup counts as
+	// approaching it.
+	WarnDays int `yaml:"warnDays,omitempty" json:"warnDays,omitempty"`
+}
+
+// Enforced reports whether any limit is set.
+func (s SLASettings) Enforced() bool {
+	return s.MaxAMIAgeDays > 0 || s.MaxReleasesBehind > 0
+}
+
+// SoakDuration parses Soak; an empty value is zero.
+func (c CanarySettings) SoakDuration() (time.Duration, error) {
+	if strings.TrimSpace(c.Soak) == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Soak)
+}
+
+// reservedSettingKeys are flags refresh.yaml may not default: the active
+// context already owns them, and defaulting them as flags would let a file
+// outrank AWS_PROFILE/AWS_REGION.
+var reservedSettingKeys = map[string]bool{"profile": true, "region": true}
+
+// gateFlags skip a confirmation prompt or a safety check. Skipping one is a
+// decision for a single run, so no refresh.yaml — least of all one that came
+// with a checked-out repo — may default it.
+var gateFlags = map[string]bool{
+	"yes":               true,
+	"force":             true,
+	"skip-health-check": true,
+	"skip-verify":       true,
+	"overwrite":         true,
+	"prune":             true,
+}
+
+// scopeFlags widen a mutating command from one target to all of them (every
+// matching cluster, every add-on). Like gateFlags they're for a single run.
+var scopeFlags = map[string]bool{
+	"all-clusters": true,
+	"all":          true,
+}
+
+// CheckGateFlag rejects the flag called name when it skips a confirmation or
+// safety check, or widens a change to every target: those must be passed on
+// the command line.
+func CheckGateFlag(name string) error {
+	switch {
+	case gateFlags[name]:
+		return fmt.Errorf("--%s skips a safety check and must be passed on the command line", name)
+	case scopeFlags[name]:
+		return fmt.Errorf("--%s widens a change to every target and must be passed on the command line", name)
+	}
+	return nil
+}
+
+// checkKey rejects a flag key refresh.yaml may not set.
+func checkKey(k string) error {
+	if reservedSettingKeys[k] {
+		return fmt.Errorf("%s is set by the active context, not refresh.yaml", k)
+	}
+	return CheckGateFlag(k)
+}
+
+// Layer is one refresh.yaml that was found on disk.
+type Layer struct {
+	// Name is "user" or "repo".
+	Name     string   `json:"name" yaml:"name"`
+	Path     string   `json:"path" yaml:"path"`
+	Settings Settings `json:"settings" yaml:"settings"`
+}
+
+// UserSettingsPath returns the user-level refresh.yaml path.
+func UserSettingsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SettingsFileName), nil
+}
+
+// FindRepoSettings walks up from dir looking for refresh.yaml (or a hidden
+// .refresh.yaml), stopping at the first directory that holds .git.
+func FindRepoSettings(dir string) (string, bool) {
+	for {
+		for _, name := range []string{SettingsFileName, "." + SettingsFileName} {
+			p := filepath.Join(dir, name)
+			if st, err := os.Stat(p); err == nil && !st.IsDir() {
+				return p, true
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// LoadLayers reads the refresh.yaml layers that exist, lowest precedence
+// first: the user file, then the repo-local one. Missing files are skipped;
+// a file that exists but doesn't parse is an error.
+func LoadLayers() ([]Layer, error) {
+	var layers []Layer
+	if p, err := UserSettingsPath(); err == nil {
+		l, ok, err := loadLayer("user", p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			layers = append(layers, l)
+		}
+	}
+	if wd, err := getwd(); err == nil {
+		if p, found := FindRepoSettings(wd); found {
+			l, ok, err := loadLayer("repo", p)
+			if err != nil {
+				return nil, err
+			}
+			if ok && !sameLayer(layers, l.Path) {
+				layers = append(layers, l)
+			}
+		}
+	}
+	return layers, nil
+}
+
+func sameLayer(layers []Layer, p string) bool {
+	for _, l := range layers {
+		if l.Path == p {
+			return true
+		}
+	}
+	return false
+}
+
+func loadLayer(name, p string) (Layer, bool, error) {
+	b, err := readFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return Layer{}, false, nil
+	}
+	if err != nil {
+		return Layer{}, false, fmt.Errorf("reading %s: %w", p, err)
+	}
+	var s Settings
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return Layer{}, false, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	if err := s.validate(); err != nil {
+		return Layer{}, false, fmt.Errorf("%s: %w", p, err)
+	}
+	return Layer{Name: name, Path: p, Settings: s}, true, nil
+}
+
+// validate rejects reserved keys and malformed durations up front, so a typo
+// surfaces when the file is read rather than mid-roll.
+func (s Settings) validate() error {
+	check := func(where string, sc Scope) error {
+		for _, k := range sortedKeys(sc.Defaults) {
+			if err := checkKey(k); err != nil {
+				return fmt.Errorf("%sdefaults.%s: %w", where, k, err)
+			}
+		}
+		for _, c := range sortedKeys(sc.Commands) {
+			for _, k := range sortedKeys(sc.Commands[c]) {
+				if err := checkKey(k); err != nil {
+					return fmt.Errorf("%scommands[%s].%s: %w", where, c, k, err)
+				}
+			}
+		}
+		if sc.Canary != nil {
+			if _, err := sc.Canary.SoakDuration(); err != nil {
+				return fmt.Errorf("%scanary.soak: %w", where, err)
+			}
+		}
+		if l := sc.SLA; l != nil && (l.MaxAMIAgeDays < 0 || l.MaxReleasesBehind < 0 || l.WarnDays < 0) {
+			return fmt.Errorf("%ssla: limits can't be negative", where)
+		}
+		return nil
+	}
+	if err := check("", s.Scope); err != nil {
+		return err
+	}
+	for _, p := range sortedKeys(s.Clusters) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("clusters[%s]: bad pattern: %w", p, err)
+		}
+		if err := check("clusters["+p+"].", s.Clusters[p]); err != nil {
+			return err
+		}
+	}
+	for _, n := range sortedKeys(s.Contexts) {
+		if err := check("contexts["+n+"].", s.Contexts[n]); err != nil {
+			return err
+		}
+	}
+	for _, sel := range sortedKeys(s.Tags) {
+		k, v, ok := strings.Cut(sel, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return fmt.Errorf("tags[%s]: want key=value", sel)
+		}
+		if _, err := path.Match(strings.TrimSpace(v), ""); err != nil {
+			return fmt.Errorf("tags[%s]: bad pattern: %w", sel, err)
+		}
+		if err := check("tags["+sel+"].", s.Tags[sel]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Target is what the effective settings are resolved for.
+type Target struct {
+	// Command is the command path below the root, e.g. "nodegroup update".
+	// Empty resolves every command's section (for display).
+	Command string
+	// Cluster selects the matching clusters.<glob> scopes.
+	Cluster string
+	// Context selects the contexts.<name> scope.
+	Context string
+	// Tags are the cluster's EKS tags; they select the matching
+	// tags.<key=glob> scopes.
+	Tags map[string]string
+}
+
+// Value is one effective setting and the layer/scope it came from.
+type Value struct {
+	Value  any    `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+	// Command is set when a flag value came from a commands.<path> section
+	// rather than defaults, i.e. it names a flag that command should have.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// Effective is the merged settings for a Target, keyed by a dotted path:
+// flag.<name> (or defaults.<name> / commands.<path>.<name> when no command
+// was targeted), skipLists.<name>, health.thresholds.<name>, health.checks,
+// health.skipChecks, health.blocking.<check>, canary.clusters, canary.soak,
+// sla.maxAmiAgeDays, sla.maxReleasesBehind, sla.warnDays.
+type Effective map[string]Value
+
+// Resolve merges layers for t. Precedence, lowest first: each file's top
+// level (user, then repo), then tags.<key=glob> scopes matching t.Tags, then
+// clusters.<glob> scopes matching t.Cluster,
+// then the contexts.<name> scope for t.Context. Within a scope a command's
+// section beats its defaults. Flags and env vars outrank all of this; that
+// is applied by the caller.
+func Resolve(layers []Layer, t Target) Effective {
+	eff := Effective{}
+	for _, l := range layers {
+		for _, name := range sortedKeys(l.Settings.SkipLists) {
+			eff["skipLists."+name] = Value{Value: l.Settings.SkipLists[name], Source: l.Path}
+		}
+		eff.apply(l.Settings.Scope, l.Path, t.Command)
+	}
+	if len(t.Tags) > 0 {
+		for _, l := range layers {
+			for _, sel := range sortedKeys(l.Settings.Tags) {
+				if tagMatches(sel, t.Tags) {
+					eff.apply(l.Settings.Tags[sel], fmt.Sprintf("%s tags[%s]", l.Path, sel), t.Command)
+				}
+			}
+		}
+	}
+	if t.Cluster != "" {
+		for _, l := range layers {
+			for _, p := range sortedKeys(l.Settings.Clusters) {
+				if ok, _ := path.Match(p, t.Cluster); ok {
+					eff.apply(l.Settings.Clusters[p], fmt.Sprintf("%s clusters[%s]", l.Path, p), t.Command)
+				}
+			}
+		}
+	}
+	if t.Context != "" {
+		for _, l := range layers {
+			if sc, ok := l.Settings.Contexts[t.Context]; ok {
+				eff.apply(sc, fmt.Sprintf("%s contexts[%s]", l.Path, t.Context), t.Command)
+			}
+		}
+	}
+	return eff
+}
+
+// tagMatches reports whether tags carry the key of a "key=glob" selector with
+// a value the glob matches.
+func tagMatches(sel string, tags map[string]string) bool {
+	k, pattern, _ := strings.Cut(sel, "=")
+	v, ok := tags[strings.TrimSpace(k)]
+	if !ok {
+		return false
+	}
+	matched, _ := path.Match(strings.TrimSpace(pattern), v)
+	return matched
+}
+
+func (e Effective) apply(sc Scope, src, command string) {
+	prefix := "flag."
+	if command == "" {
+		prefix = "defaults."
+	}
+	for _, k := range sortedKeys(sc.Defaults) {
+		e[prefix+k] = Value{Value: sc.Defaults[k], Source: src}
+	}
+	for _, c := range sortedKeys(sc.Commands) {
+		switch {
+		case command == "":
+			for _, k := range sortedKeys(sc.Commands[c]) {
+				e["commands."+c+"."+k] = Value{Value: sc.Commands[c][k], Source: src}
+			}
+		case strings.TrimSpace(c) == command:
+			for _, k := range sortedKeys(sc.Commands[c]) {
+				e["flag."+k] = Value{Value: sc.Commands[c][k], Source: src, Command: command}
+			}
+		}
+	}
+	if h := sc.Health; h != nil {
+		for _, k := range sortedKeys(h.Thresholds) {
+			e["health.thresholds."+k] = Value{Value: h.Thresholds[k], Source: src}
+		}
+		if len(h.Checks) > 0 {
+			e["health.checks"] = Value{Value: h.Checks, Source: src}
+		}
+		if len(h.SkipChecks) > 0 {
+			e["health.skipChecks"] = Value{Value: h.SkipChecks, Source: src}
+		}
+		for _, k := range sortedKeys(h.Blocking) {
+			e["health.blocking."+k] = Value{Value: h.Blocking[k], Source: src}
+		}
+	}
+	if c := sc.Canary; c != nil {
+		if len(c.Clusters) > 0 {
+			e["canary.clusters"] = Value{Value: c.Clusters, Source: src}
+		}
+		if c.Soak != "" {
+			e["canary.soak"] = Value{Value: c.Soak, Source: src}
+		}
+	}
+	if l := sc.SLA; l != nil {
+		if l.MaxAMIAgeDays > 0 {
+			e["sla.maxAmiAgeDays"] = Value{Value: l.MaxAMIAgeDays, Source: src}
+		}
+		if l.MaxReleasesBehind > 0 {
+			e["sla.maxReleasesBehind"] = Value{Value: l.MaxReleasesBehind, Source: src}
+		}
+		if l.WarnDays > 0 {
+			e["sla.warnDays"] = Value{Value: l.WarnDays, Source: src}
+		}
+	}
+}
+
+// Keys returns the effective keys in sorted order.
+func (e Effective) Keys() []string {
+	return sortedKeys(e)
+}
+
+// Flags returns the flag defaults (flag.<name>) keyed by flag name.
+func (e Effective) Flags() map[string]Value {
+	out := map[string]Value{}
+	for k, v := range e {
+		if name, ok := strings.CutPrefix(k, "flag."); ok {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+// SkipList returns the named skip list.
+func (e Effective) SkipList(name string) ([]string, bool) {
+	v, ok := e["skipLists."+name]
+	if !ok {
+		return nil, false
+	}
+	list, _ := v.Value.([]string)
+	return list, true
+}
+
+// Health reassembles the effective health block.
+func (e Effective) Health() HealthSettings {
+	var h HealthSettings
+	for k, v := range e {
+		switch {
+		case strings.HasPrefix(k, "health.thresholds."):
+			if f, ok := v.Value.(float64); ok {
+				if h.Thresholds == nil {
+					h.Thresholds = map[string]float64{}
+				}
+				h.Thresholds[strings.TrimPrefix(k, "health.thresholds.")] = f
+			}
+		case strings.HasPrefix(k, "health.blocking."):
+			if b, ok := v.Value.(bool); ok {
+				if h.Blocking == nil {
+					h.Blocking = map[string]bool{}
+				}
+				h.Blocking[strings.TrimPrefix(k, "health.blocking.")] = b
+			}
+		case k == "health.checks":
+			h.Checks, _ = v.Value.([]string)
+		case k == "health.skipChecks":
+			h.SkipChecks, _ = v.Value.([]string)
+		}
+	}
+	return h
+}
+
+// Canary reassembles the effective canary block.
+func (e Effective) Canary() CanarySettings {
+	var c CanarySettings
+	if v, ok := e["canary.clusters"]; ok {
+		c.Clusters, _ = v.Value.([]string)
+	}
+	if v, ok := e["canary.soak"]; ok {
+		c.Soak, _ = v.Value.(string)
+	}
+	return c
+}
+
+// SLA reassembles the effective sla block. sources lists the distinct scopes
+// its limits came from.
+func (e Effective) SLA() (sla SLASettings, sources []string) {
+	for _, f := range []struct {
+		key string
+		dst *int
+	}{
+		{"sla.maxAmiAgeDays", &sla.MaxAMIAgeDays},
+		{"sla.maxReleasesBehind", &sla.MaxReleasesBehind},
+		{"sla.warnDays", &sla.WarnDays},
+	} {
+		v, ok := e[f.key]
+		if !ok {
+			continue
+		}
+		*f.dst, _ = v.Value.(int)
+		if !slices.Contains(sources, v.Source) {
+			sources = append(sources, v.Source)
+		}
+	}
+	return sla, sources
+}
+
+// ExpandSkipLists replaces "@name" entries with the named skip list's members,
+// keeping order and dropping duplicates. An unknown list is an error so a typo
+// doesn't silently skip nothing.
+func (e Effective) ExpandSkipLists(values []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	add := func(v string) {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	for _, v := range values {
+		name, ok := strings.CutPrefix(strings.TrimSpace(v), "@")
+		if !ok {
+			add(strings.TrimSpace(v))
+			continue
+		}
+		list, found := e.SkipList(name)
+		if !found {
+			return nil, fmt.Errorf("unknown skip list %q (define it under skipLists in %s)", name, SettingsFileName)
+		}
+		for _, item := range list {
+			add(strings.TrimSpace(item))
+		}
+	}
+	return out, nil
+}
+
+// FlagStrings renders a settings value as the string(s) to hand to a flag:
+// one per list element, or a single formatted scalar.
+func FlagStrings(v any) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, fmt.Sprint(e))
+		}
+		return out
+	case []string:
+		return t
+	default:
+		return []string{fmt.Sprint(t)}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,267 @@
+package cliconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSettings(t *testing.T, dir, body string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, SettingsFileName)
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// withWorkdir points the repo-local lookup at dir for the test.
+func withWorkdir(t *testing.T, dir string) {
+	t.Helper()
+	old := getwd
+	getwd = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { getwd = old })
+}
+
+func TestFindRepoSettings_WalksUpToGitRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want := writeSettings(t, root, "defaults: {poll-interval: 20s}\n")
+	deep := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := FindRepoSettings(deep)
+	if !ok || got != want {
+		t.Fatalf("FindRepoSettings = %q, %v; want %q", got, ok, want)
+	}
+
+	// A file above the git root belongs to another project and is not used.
+	outer := t.TempDir()
+	writeSettings(t, outer, "defaults: {poll-interval: 20s}\n")
+	repo := filepath.Join(outer, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := FindRepoSettings(repo); ok {
+		t.Fatalf("lookup crossed the git root: %q", got)
+	}
+}
+
+func TestLoadLayers_UserThenRepo(t *testing.T) {
+	home := withTempHome(t)
+	repo := t.TempDir()
+	withWorkdir(t, repo)
+	user := writeSettings(t, home, "defaults: {poll-interval: 20s}\n")
+	local := writeSettings(t, repo, "defaults: {poll-interval: 30s}\n")
+
+	layers, err := LoadLayers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 2 || layers[0].Path != user || layers[1].Path != local {
+		t.Fatalf("layers = %+v", layers)
+	}
+	if layers[0].Name != "user" || layers[1].Name != "repo" {
+		t.Errorf("names = %q, %q", layers[0].Name, layers[1].Name)
+	}
+}
+
+func TestLoadLayers_RejectsReservedGatesAndBadSoak(t *testing.T) {
+	home := withTempHome(t)
+	withWorkdir(t, t.TempDir())
+
+	writeSettings(t, home, "commands:\n  status:\n    region: us-east-1\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "active context") {
+		t.Fatalf("reserved key: err = %v", err)
+	}
+
+	// A repo's refresh.yaml can't answer prompts or waive checks for whoever
+	// runs refresh inside it.
+	repo := t.TempDir()
+	withWorkdir(t, repo)
+	writeSettings(t, home, "defaults: {poll-interval: 20s}\n")
+	writeSettings(t, repo, "defaults: {yes: true}\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "defaults.yes") || !strings.Contains(err.Error(), "command line") {
+		t.Fatalf("repo yes: err = %v", err)
+	}
+	writeSettings(t, repo, "clusters:\n  prod-*:\n    commands:\n      nodegroup update: {skip-health-check: true}\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "skip-health-check") {
+		t.Fatalf("repo skip-health-check: err = %v", err)
+	}
+	writeSettings(t, repo, "defaults: {poll-interval: 30s}\n")
+
+	writeSettings(t, home, "contexts:\n  prod:\n    canary: {soak: soon}\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "contexts[prod].canary.soak") {
+		t.Fatalf("bad soak: err = %v", err)
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	user := Layer{Name: "user", Path: "user.yaml", Settings: Settings{
+		Scope: Scope{
+			Defaults: map[string]any{"poll-interval": "10s", "require-healthy": true},
+			Commands: map[string]map[string]any{"nodegroup update": {"poll-interval": "20s"}},
+		},
+		Contexts: map[string]Scope{"prod": {Defaults: map[string]any{"timeout": "1h"}}},
+	}}
+	repo := Layer{Name: "repo", Path: "repo.yaml", Settings: Settings{
+		Scope: Scope{Commands: map[string]map[string]any{"nodegroup update": {"poll-interval": "30s"}}},
+		Clusters: map[string]Scope{
+			"prod-*":  {Defaults: map[string]any{"timeout": "50m", "kubeconfig": "/k/prod"}},
+			"stage-*": {Defaults: map[string]any{"kubeconfig": "/k/stage"}},
+		},
+	}}
+	eff := Resolve([]Layer{user, repo}, Target{Command: "nodegroup update", Cluster: "prod-east", Context: "prod"})
+
+	cases := map[string]Value{
+		// Repo command section beats the user's command section and defaults.
+		"flag.poll-interval":   {Value: "30s", Source: "repo.yaml", Command: "nodegroup update"},
+		"flag.require-healthy": {Value: true, Source: "user.yaml"},
+		"flag.kubeconfig":      {Value: "/k/prod", Source: "repo.yaml clusters[prod-*]"},
+		// The context scope outranks the matching cluster scope.
+		"flag.timeout": {Value: "1h", Source: "user.yaml contexts[prod]"},
+	}
+	for k, want := range cases {
+		if got := eff[k]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", k, got, want)
+		}
+	}
+	if len(eff.Flags()) != 4 {
+		t.Errorf("flags = %v", eff.Flags())
+	}
+}
+
+func TestResolve_TagScopesAndSLA(t *testing.T) {
+	l := Layer{Path: "r.yaml", Settings: Settings{
+		Scope: Scope{SLA: &SLASettings{MaxAMIAgeDays: 60, WarnDays: 5}},
+		Tags: map[string]Scope{
+			"env=prod*": {SLA: &SLASettings{MaxAMIAgeDays: 30, MaxReleasesBehind: 2}},
+			"team=data": {SLA: &SLASettings{MaxAMIAgeDays: 14}},
+		},
+		Clusters: map[string]Scope{"prod-legacy": {SLA: &SLASettings{MaxAMIAgeDays: 90}}},
+	}}
+	cases := []struct {
+		target Target
+		want   SLASettings
+	}{
+		{Target{Cluster: "dev-1"}, SLASettings{MaxAMIAgeDays: 60, WarnDays: 5}},
+		{Target{Cluster: "prod-1", Tags: map[string]string{"env": "production"}}, SLASettings{MaxAMIAgeDays: 30, MaxReleasesBehind: 2, WarnDays: 5}},
+		// A cluster scope outranks a tag scope.
+		{Target{Cluster: "prod-legacy", Tags: map[string]string{"env": "prod"}}, SLASettings{MaxAMIAgeDays: 90, MaxReleasesBehind: 2, WarnDays: 5}},
+		{Target{Cluster: "x", Tags: map[string]string{"team": "web"}}, SLASettings{MaxAMIAgeDays: 60, WarnDays: 5}},
+	}
+	for _, c := range cases {
+		got, sources := Resolve([]Layer{l}, c.target).SLA()
+		if got != c.want {
+			t.Errorf("%s: SLA = %+v, want %+v", c.target.Cluster, got, c.want)
+		}
+		if len(sources) == 0 {
+			t.Errorf("%s: no sources", c.target.Cluster)
+		}
+	}
+	_, sources := Resolve([]Layer{l}, Target{Cluster: "prod-1", Tags: map[string]string{"env": "prod"}}).SLA()
+	if want := []string{"r.yaml tags[env=prod*]", "r.yaml"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+}
+
+func TestLoadLayers_RejectsBadTagSelectorAndSLA(t *testing.T) {
+	home := withTempHome(t)
+	withWorkdir(t, t.TempDir())
+
+	writeSettings(t, home, "tags:\n  prod:\n    sla: {maxAmiAgeDays: 30}\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "want key=value") {
+		t.Fatalf("bad selector: err = %v", err)
+	}
+	writeSettings(t, home, "sla: {maxAmiAgeDays: -1}\n")
+	if _, err := LoadLayers(); err == nil || !strings.Contains(err.Error(), "sla") {
+		t.Fatalf("negative limit: err = %v", err)
+	}
+}
+
+func TestResolve_WithoutCommandListsEverySection(t *testing.T) {
+	l := Layer{Path: "r.yaml", Settings: Settings{Scope: Scope{
+		Defaults: map[string]any{"yes": true},
+		Commands: map[string]map[string]any{"addon update": {"skip": []any{"@helm"}}},
+		Health:   &HealthSettings{Thresholds: map[string]float64{"peakFailCPUPercent": 98}, SkipChecks: []string{"balance"}},
+		Canary:   &CanarySettings{Clusters: []string{"stage-*"}, Soak: "10m"},
+	}}}
+	eff := Resolve([]Layer{l}, Target{})
+	want := []string{
+		"canary.clusters", "canary.soak", "commands.addon update.skip", "defaults.yes",
+		"health.skipChecks", "health.thresholds.peakFailCPUPercent",
+	}
+	if got := eff.Keys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+	h := eff.Health()
+	if h.Thresholds["peakFailCPUPercent"] != 98 || !reflect.DeepEqual(h.SkipChecks, []string{"balance"}) {
+		t.Errorf("health = %+v", h)
+	}
+	c := eff.Canary()
+	if d, _ := c.SoakDuration(); d.Minutes() != 10 || c.Clusters[0] != "stage-*" {
+		t.Errorf("canary = %+v", c)
+	}
+}
+
+func TestExpandSkipLists(t *testing.T) {
+	eff := Resolve([]Layer{{Path: "r.yaml", Settings: Settings{
+		SkipLists: map[string][]string{"helm": {"external-dns", "aws-load-balancer-controller"}},
+	}}}, Target{})
+
+	got, err := eff.ExpandSkipLists([]string{"coredns", "@helm", "external-dns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"coredns", "external-dns", "aws-load-balancer-controller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded = %v, want %v", got, want)
+	}
+	if _, err := eff.ExpandSkipLists([]string{"@gitops"}); err == nil {
+		t.Error("unknown skip list should be an error")
+	}
+}
+
+func TestFlagStrings(t *testing.T) {
+	if got := FlagStrings([]any{"a", 2}); !reflect.DeepEqual(got, []string{"a", "2"}) {
+		t.Errorf("list = %v", got)
+	}
+	if got := FlagStrings(true); !reflect.DeepEqual(got, []string{"true"}) {
+		t.Errorf("scalar = %v", got)
+	}
+	if got := FlagStrings(nil); got != nil {
+		t.Errorf("nil = %v", got)
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	for _, tc := range []struct {
+		key  string
+		want string
+	}{
+		{"poll-interval", ""},
+		{"region", "active context"},
+		{"yes", "skips a safety check"},
+		{"prune", "skips a safety check"},
+		{"all-clusters", "widens a change"},
+		{"all", "widens a change"},
+	} {
+		err := checkKey(tc.key)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.key, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.key, err, tc.want)
+		}
+	}
+}
@@ -38,9 +38,24 @@ var createTemp = func(dir, pattern string) (tempFile, error) {
 
 // Context is a named pointer at an EKS cluster within a region/profile.
 type Context struct {
-	Cluster string `yaml:"cluster"`
-	Region  string `yaml:"region,omitempty"`
-	Profile string `yaml:"profile,omitempty"`
+	Cluster string `yaml:"cluster" json:"cluster"`
+	Region  string `yaml:"region,omitempty" json:"region,omitempty"`
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	// Role is an IAM role assumed on top of the profile's credentials, for a
+	// cluster in another account.
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+	// Managed is set to ManagedBySync on contexts `refresh context sync`
+	// created, the only ones its --prune may remove.
+	Managed string `yaml:"managed,omitempty" json:"managed,omitempty"`
+}
+
+// ManagedBySync marks a context created by `refresh context sync`.
+const ManagedBySync = "sync"
+
+// SameTarget reports whether two contexts reach the same cluster the same
+// way, whoever manages them.
+func (c Context) SameTarget(o Context) bool {
+	return c.Cluster == o.Cluster && c.Region == o.Region && c.Profile == o.Profile && c.Role == o.Role
 }
 
 // File is the persisted YAML document.
@@ -48,22 +63,53 @@ type File struct {
 	Current  string             `yaml:"current,omitempty"`
 	Previous string             `yaml:"previous,omitempty"`
 	Contexts map[string]Context `yaml:"contexts,omitempty"`
+	// Groups are named cluster selectors (context groups), used with --group.
+	Groups map[string]string `yaml:"groups,omitempty"`
 }
 
-// Path returns the absolute path of the context file. The directory is
-// not created here; Save creates it on demand.
-func Path() (string, error) {
+// Dir returns the refresh configuration directory: $REFRESH_CONFIG_HOME, else
+// $XDG_CONFIG_HOME/refresh, else ~/.config/refresh. Sibling files (the context
+// file, price tables, …) all live here so one override relocates them together.
+func Dir() (string, error) {
 	if p := os.Getenv("REFRESH_CONFIG_HOME"); p != "" {
-		return filepath.Join(p, "context.yaml"), nil
+		return p, nil
 	}
 	if x := os.Getenv("XDG_CONFIG_HOME"); x != "" {
-		return filepath.Join(x, "refresh", "context.yaml"), nil
+		return filepath.Join(x, "refresh"), nil
 	}
 	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".config", "refresh", "context.yaml"), nil
+	return filepath.Join(home, ".config", "refresh"), nil
+}
+
+// cacheDir, when set, replaces Dir for the caches refresh fills on its own.
+var cacheDir string
+
+// UseCacheDir moves the caches CacheDir locates to dir; "" puts them back in
+// Dir. Sandbox runs point it at a throwaway directory, so nothing learned
+// from a fixture is left behind for real runs.
+func UseCacheDir(dir string) { cacheDir = dir }
+
+// CacheDir is where refresh keeps what it caches on its own (the support
+// calendar, add-on compatibility, AMI release notes, AWS responses): Dir,
+// unless UseCacheDir moved them.
+func CacheDir() (string, error) {
+	if cacheDir != "" {
+		return cacheDir, nil
+	}
+	return Dir()
+}
+
+// Path returns the absolute path of the context file. The directory is
+// not created here; Save creates it on demand.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "context.yaml"), nil
 }
 
 // Load reads the context file. A missing file returns an empty File and no error.
@@ -240,6 +240,12 @@ func runUpdateAll(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("--parallel and --dependency-order cannot be used together: parallel execution defeats dependency ordering")
 	}
 
+	// --skip accepts "@name" references to refresh.yaml skip lists.
+	skip, err := runner.SkipValues(cmd, "skip")
+	if err != nil {
+		return err
+	}
+
 	addonSvc := factory.NewAddonService(cfg, nil)
 
 	options := addons.UpdateAllOptions{
@@ -247,7 +253,7 @@ func runUpdateAll(ctx context.Context, cmd *cli.Command) error {
 		Parallel:        cmd.Bool("parallel"),
 		Wait:            cmd.Bool("wait"),
 		WaitTimeout:     cmd.Duration("wait-timeout"),
-		SkipAddons:      cmd.StringSlice("skip"),
+		SkipAddons:      skip,
 		DependencyOrder: cmd.Bool("dependency-order"),
 		HealthCheck:     cmd.Bool("health-check"),
 	}
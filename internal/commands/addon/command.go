@@ -14,14 +14,16 @@ import (
 func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "addon",
-		Usage: "EKS add-on operations (list, get, update)",
+		Usage: "EKS add-on operations (list, get, matrix, update)",
 		Description: `Inspect and update the managed EKS add-ons (vpc-cni, coredns, kube-proxy,
 and others) on a cluster. List shows installed versions and status, describe
-drills into one add-on, and update rolls a single add-on or every add-on
+drills into one add-on, matrix lays out compatible versions across Kubernetes
+versions for upgrade planning, and update rolls a single add-on or every add-on
 (--all) to a compatible version with optional health gating and waiting.`,
 		Commands: []*cli.Command{
 			listCommand(),
 			describeCommand(),
+			matrixCommand(),
 			updateCommand(),
 			updateAllHiddenCommand(),
 		},
@@ -122,7 +124,7 @@ before updating. -o json|yaml emits a machine-readable result/summary.`,
 			&cli.BoolFlag{Name: "wait", Usage: "Wait for each update to complete"},
 			&cli.DurationFlag{Name: "wait-timeout", Usage: "Per-addon wait timeout (with --wait)", Value: 5 * time.Minute},
 			&cli.BoolFlag{Name: "dependency-order", Usage: "(--all only) Update addons in dependency-safe order (vpc-cni -> coredns/kube-proxy -> others)"},
-			&cli.StringSliceFlag{Name: "skip", Aliases: []string{"s"}, Usage: "(--all only) Skip specific addons (repeatable)"},
+			&cli.StringSliceFlag{Name: "skip", Aliases: []string{"s"}, Usage: "(--all only) Skip specific addons (repeatable; @name expands a refresh.yaml skip list)"},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
@@ -0,0 +1,164 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	appconfig "github.com/dantech2000/refresh/internal/config"
+	"github.com/dantech2000/refresh/internal/services/addons"
+)
+
+// defaultMatrixSpan is how many minors before --to the matrix starts when
+// there's neither --from nor a cluster version to start from.
+const defaultMatrixSpan = 3
+
+func matrixCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "matrix",
+		Usage:     "Show add-on version compatibility across Kubernetes versions",
+		ArgsUsage: "[cluster]",
+		Description: `For each installed add-on (or the --addon set), show the latest and the
+default add-on version EKS publishes for every Kubernetes minor from --from to
+--to. Minors with no compatible version are gaps, and for installed add-ons
+the matrix marks each minor where the version carried in from the previous
+one stops being compatible, so it must change on that hop.
+
+--from defaults to the cluster's version and --to to the newest Kubernetes
+version any of the add-ons supports. Without a cluster, name the add-ons
+with --addon. Compatibility data is cached on disk for a day (--refresh
+fetches it again), so the matrix renders quickly while you plan.
+
+  refresh addon matrix -c prod --to 1.34
+  refresh addon matrix --addon vpc-cni --addon coredns --from 1.30 --to 1.34
+  refresh addon matrix prod -o json`,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
+			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or pattern (its installed add-ons and version)"},
+			&cli.StringSliceFlag{Name: "addon", Aliases: []string{"a"}, Usage: "Add-on(s) to show (repeatable); default every installed add-on"},
+			&cli.StringFlag{Name: "from", Usage: "First Kubernetes minor (default: the cluster's version)"},
+			&cli.StringFlag{Name: "to", Usage: "Last Kubernetes minor (default: the newest any add-on supports)"},
+			&cli.BoolFlag{Name: "refresh", Usage: "Ignore cached compatibility data and fetch it again"},
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runMatrix(ctx, cmd) },
+	}
+}
+
+func runMatrix(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	ctx, cancel, cfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	names := cmd.StringSlice("addon")
+	result := addons.AddonMatrix{}
+	installed := map[string]string{}
+	addonSvc := factory.NewAddonService(cfg, nil)
+
+	if len(names) == 0 || runner.RequestedCluster(cmd) != "" {
+		clusterName, listed, err := runner.ResolveClusterOrList(ctx, cfg, cmd)
+		if err != nil || listed {
+			return err
+		}
+		result.Cluster = clusterName
+		if err := runner.WithSpinner("addon", "Installed add-ons gathered!", func() error {
+			out, derr := addonSvc.EKS().DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String(clusterName)})
+			if derr != nil {
+				return awsinternal.FormatAWSError(derr, "describing cluster")
+			}
+			if out.Cluster != nil {
+				result.ClusterVersion = aws.ToString(out.Cluster.Version)
+			}
+			summaries, lerr := addonSvc.List(ctx, clusterName, addons.ListOptions{})
+			for _, s := range summaries {
+				installed[s.Name] = s.Version
+			}
+			return lerr
+		}); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			for name := range installed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("cluster %s has no managed add-ons; name some with --addon", clusterName)
+		}
+	}
+
+	path, err := addons.CompatCachePath()
+	if err != nil {
+		return err
+	}
+	cache := addons.LoadCompatCache(path)
+	var compat []*addons.CompatibilityMatrix
+	var errs []error
+	if err := runner.WithSpinner("addon", "Compatibility matrix built!", func() error {
+		compat, errs = addonSvc.CachedCompatibility(ctx, cache, cfg.Region, names, cmd.Bool("refresh"))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if serr := cache.Save(); serr != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: saving add-on compatibility cache: %v", serr))
+	}
+
+	var found []*addons.CompatibilityMatrix
+	var failed []addons.MatrixRow
+	for i, m := range compat {
+		if m == nil {
+			failed = append(failed, addons.MatrixRow{Addon: names[i], Installed: installed[names[i]], Error: errs[i].Error()})
+			continue
+		}
+		found = append(found, m)
+	}
+	if len(found) == 0 {
+		return errs[0]
+	}
+
+	from, to := matrixRange(cmd.String("from"), cmd.String("to"), result.ClusterVersion, found)
+	result.KubernetesVersions, err = addons.KubernetesRange(from, to)
+	if err != nil {
+		return err
+	}
+	result.Addons = append(addons.BuildAddonMatrix(found, installed, result.KubernetesVersions), failed...)
+
+	if handled, err := runner.EncodeStdout(cmd.String("format"), result); handled {
+		return err
+	}
+	return outputAddonMatrix(&result)
+}
+
+// matrixRange fills in the --from/--to defaults: from the cluster's version
+// (else defaultMatrixSpan minors before to), to the newest Kubernetes minor
+// any of the add-ons supports.
+func matrixRange(from, to, clusterVersion string, compat []*addons.CompatibilityMatrix) (string, string) {
+	from, to = strings.TrimPrefix(strings.TrimSpace(from), "v"), strings.TrimPrefix(strings.TrimSpace(to), "v")
+	if to == "" {
+		to = addons.NewestKubernetes(compat)
+	}
+	if from == "" {
+		from = addons.ShiftMinor(clusterVersion, 0)
+	}
+	if from == "" {
+		from = addons.ShiftMinor(to, -defaultMatrixSpan)
+	}
+	return from, to
+}
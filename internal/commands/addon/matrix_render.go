@@ -0,0 +1,132 @@
+package addon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/addons"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// outputAddonMatrix renders the compatibility matrix: the themed view on a
+// terminal, an uncolored table for `-o plain`.
+func outputAddonMatrix(m *addons.AddonMatrix) error {
+	if !ui.PlainOutput() {
+		th := render.Default(os.Stdout)
+		for _, line := range addonMatrixLines(th, m) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+	cols := []ui.Column{{Title: "ADDON"}, {Title: "INSTALLED"}, {Title: "ROW"}}
+	for _, k := range m.KubernetesVersions {
+		cols = append(cols, ui.Column{Title: k})
+	}
+	tbl := ui.NewPTable(cols, ui.CyanHeaders())
+	for _, r := range m.Addons {
+		latest := []string{r.Addon, valueOrDash(r.Installed), "latest"}
+		def := []string{r.Addon, valueOrDash(r.Installed), "default"}
+		for _, c := range r.Cells {
+			cell := valueOrDash(c.Latest)
+			if c.MustChange {
+				cell += " !"
+			}
+			latest = append(latest, cell)
+			def = append(def, valueOrDash(c.Default))
+		}
+		tbl.AddRow(latest...)
+		tbl.AddRow(def...)
+	}
+	tbl.Render()
+	return nil
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// addonMatrixLines builds the human `addon matrix` view (pure,
+// golden-testable): per add-on a latest and a default row across the
+// Kubernetes versions, then the hops where an installed version must change
+// and the gaps.
+func addonMatrixLines(th *render.Theme, m *addons.AddonMatrix) []string {
+	pal := th.Pal
+	head := th.Bold(pal.Mauve, "ADD-ON COMPATIBILITY")
+	if m.Cluster != "" {
+		head += "  " + th.Paint(pal.White, m.Cluster)
+		if m.ClusterVersion != "" {
+			head += th.Paint(pal.Dim, " · "+m.ClusterVersion)
+		}
+	}
+	if n := len(m.KubernetesVersions); n > 0 {
+		head += th.Paint(pal.Dim, fmt.Sprintf("  Kubernetes %s → %s", m.KubernetesVersions[0], m.KubernetesVersions[n-1]))
+	}
+	out := []string{head, ""}
+
+	cols := []ui.Column{{Title: "ADD-ON", Min: 8, Max: 28}, {Title: "INSTALLED", Min: 9}, {Title: "", Min: 7}}
+	for _, k := range m.KubernetesVersions {
+		cols = append(cols, ui.Column{Title: k, Min: 8})
+	}
+	tbl := th.NewTable(cols...)
+	var changes, gaps, failed []string
+	for _, r := range m.Addons {
+		installed := th.Paint(pal.Dim, "—")
+		if r.Installed != "" {
+			installed = th.Paint(pal.Text, r.Installed)
+		}
+		if r.Error != "" {
+			failed = append(failed, th.Token(render.Unknown, fmt.Sprintf("%s: %s", r.Addon, r.Error)))
+			continue
+		}
+		latest := []string{th.Paint(pal.White, r.Addon), installed, th.Paint(pal.Dim, "latest")}
+		def := []string{"", "", th.Paint(pal.Dim, "default")}
+		prev := r.Installed
+		for _, c := range r.Cells {
+			switch {
+			case c.Gap:
+				latest = append(latest, th.Token(render.Fail, "none"))
+				gaps = append(gaps, th.Token(render.Fail, fmt.Sprintf("%s has no version for Kubernetes %s", r.Addon, c.KubernetesVersion)))
+			case c.MustChange:
+				latest = append(latest, th.Token(render.Warn, c.Latest))
+				changes = append(changes, th.Token(render.Warn, fmt.Sprintf("%s %s → %s for Kubernetes %s", r.Addon, prev, c.Running, c.KubernetesVersion)))
+			default:
+				latest = append(latest, th.Paint(pal.Text, c.Latest))
+			}
+			if c.Running != "" {
+				prev = c.Running
+			}
+			if c.Default == "" {
+				def = append(def, th.Paint(pal.Dim, "—"))
+			} else {
+				def = append(def, th.Paint(pal.Dim, c.Default))
+			}
+		}
+		tbl.Row(latest...)
+		tbl.Row(def...)
+	}
+	out = append(out, tbl.Render()...)
+
+	if len(changes) > 0 {
+		out = append(out, "", th.Section("MUST CHANGE")+th.Paint(pal.Dim, "  installed version not published for the next minor"))
+		for _, l := range changes {
+			out = append(out, "  "+l)
+		}
+	}
+	if len(gaps) > 0 {
+		out = append(out, "", th.Section("GAPS"))
+		for _, l := range gaps {
+			out = append(out, "  "+l)
+		}
+	}
+	if len(failed) > 0 {
+		out = append(out, "", th.Section("UNAVAILABLE"))
+		for _, l := range failed {
+			out = append(out, "  "+l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package addon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/addons"
+)
+
+func TestAddonMatrixLines(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	m := &addons.AddonMatrix{
+		Cluster:            "prod",
+		ClusterVersion:     "1.31",
+		KubernetesVersions: []string{"1.31", "1.32", "1.33"},
+		Addons: []addons.MatrixRow{
+			{Addon: "coredns", Installed: "v1.10.0", Cells: []addons.MatrixCell{
+				{KubernetesVersion: "1.31", Latest: "v1.11.0", Default: "v1.11.0", Running: "v1.10.0"},
+				{KubernetesVersion: "1.32", Latest: "v1.12.0", Default: "v1.11.0", Running: "v1.12.0", MustChange: true},
+				{KubernetesVersion: "1.33", Gap: true, MustChange: true, Running: "v1.12.0"},
+			}},
+			{Addon: "vpc-cni", Error: "AccessDenied"},
+		},
+	}
+	joined := strings.Join(addonMatrixLines(th, m), "\n")
+
+	if strings.Contains(joined, "\x1b") {
+		t.Fatalf("ColorNone output contains ANSI escapes:\n%s", joined)
+	}
+	for _, want := range []string{
+		"ADD-ON COMPATIBILITY  prod · 1.31  Kubernetes 1.31 → 1.33",
+		"latest",
+		"default",
+		"none",
+		"MUST CHANGE",
+		"coredns v1.10.0 → v1.12.0 for Kubernetes 1.32",
+		"GAPS",
+		"coredns has no version for Kubernetes 1.33",
+		"UNAVAILABLE",
+		"vpc-cni: AccessDenied",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("addon matrix missing %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestMatrixRange(t *testing.T) {
+	cm := &addons.CompatibilityMatrix{AddonName: "coredns", Versions: map[string][]string{"v1.12.0": {"1.33", "1.34"}}}
+	compat := []*addons.CompatibilityMatrix{cm}
+	for _, tc := range []struct {
+		from, to, cluster string
+		wantFrom, wantTo  string
+	}{
+		{"", "", "1.31", "1.31", "1.34"},
+		{"", "", "", "1.31", "1.34"},
+		{"v1.30", "1.32", "1.31", "1.30", "1.32"},
+	} {
+		from, to := matrixRange(tc.from, tc.to, tc.cluster, compat)
+		if from != tc.wantFrom || to != tc.wantTo {
+			t.Errorf("matrixRange(%q, %q, %q) = %s..%s, want %s..%s", tc.from, tc.to, tc.cluster, from, to, tc.wantFrom, tc.wantTo)
+		}
+	}
+}
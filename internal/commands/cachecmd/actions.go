@@ -0,0 +1,179 @@
+package cachecmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/awscache"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// statsView is the `cache stats` payload.
+type statsView struct {
+	Dir        string          `json:"dir" yaml:"dir"`
+	Entries    int             `json:"entries" yaml:"entries"`
+	Bytes      int64           `json:"bytes" yaml:"bytes"`
+	Accounts   int             `json:"accounts" yaml:"accounts"`
+	Operations []operationView `json:"operations" yaml:"operations"`
+}
+
+type operationView struct {
+	Service   string `json:"service" yaml:"service"`
+	Operation string `json:"operation" yaml:"operation"`
+	Entries   int    `json:"entries" yaml:"entries"`
+	Fresh     int    `json:"fresh" yaml:"fresh"`
+	Stale     int    `json:"stale" yaml:"stale"`
+	Expired   int    `json:"expired" yaml:"expired"`
+	Bytes     int64  `json:"bytes" yaml:"bytes"`
+	TTL       string `json:"ttl" yaml:"ttl"`
+	StaleFor  string `json:"staleFor,omitempty" yaml:"staleFor,omitempty"`
+}
+
+func openCache() (*awscache.Cache, error) {
+	dir, err := awscache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return awscache.New(dir), nil
+}
+
+func runStats(_ context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	if err := runner.ValidateFormat(format, runner.FormatsStandard); err != nil {
+		return err
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	s, err := c.Stats()
+	if err != nil {
+		return fmt.Errorf("reading cache %s: %w", c.Dir(), err)
+	}
+	view := statsView{Dir: s.Dir, Entries: s.Entries, Bytes: s.Bytes, Accounts: s.Accounts, Operations: []operationView{}}
+	for _, o := range s.Operations {
+		view.Operations = append(view.Operations, operationView{
+			Service: o.Service, Operation: o.Operation,
+			Entries: o.Entries, Fresh: o.Fresh, Stale: o.Stale, Expired: o.Expired, Bytes: o.Bytes,
+			TTL: shortDuration(o.TTL), StaleFor: shortDuration(o.StaleFor),
+		})
+	}
+	return outputStats(format, view)
+}
+
+func outputStats(format string, view statsView) error {
+	if handled, err := runner.EncodeStdout(format, view); handled {
+		return err
+	}
+	if view.Entries == 0 {
+		fmt.Printf("The cache at %s is empty. Pass --cache (or set REFRESH_CACHE=true) to fill it.\n", view.Dir)
+		return nil
+	}
+	columns := []ui.Column{
+		{Title: "OPERATION", Min: 12},
+		{Title: "ENTRIES", Min: 7},
+		{Title: "FRESH", Min: 5},
+		{Title: "STALE", Min: 5},
+		{Title: "EXPIRED", Min: 7},
+		{Title: "SIZE", Min: 6},
+		{Title: "TTL", Min: 4},
+		{Title: "SERVED STALE FOR", Min: 8},
+	}
+	row := func(o operationView) []string {
+		staleFor := o.StaleFor
+		if staleFor == "" {
+			staleFor = "-"
+		}
+		return []string{o.Service + " " + o.Operation, strconv.Itoa(o.Entries), strconv.Itoa(o.Fresh),
+			strconv.Itoa(o.Stale), strconv.Itoa(o.Expired), humanBytes(o.Bytes), o.TTL, staleFor}
+	}
+	if ui.PlainOutput() {
+		table := ui.NewPTable(columns, ui.CyanHeaders())
+		for _, o := range view.Operations {
+			table.AddRow(row(o)...)
+		}
+		table.Render()
+	} else {
+		th := render.Default(os.Stdout)
+		tbl := th.NewTable(columns...)
+		for _, o := range view.Operations {
+			cells := row(o)
+			cells[0] = th.Paint(th.Pal.White, cells[0])
+			tbl.Row(cells...)
+		}
+		for _, line := range tbl.Render() {
+			fmt.Println(line)
+		}
+	}
+	fmt.Printf("\n%d responses, %s, %d account(s) in %s\n", view.Entries, humanBytes(view.Bytes), view.Accounts, view.Dir)
+	return nil
+}
+
+func runClear(_ context.Context, cmd *cli.Command) error {
+	opts := awscache.ClearOptions{
+		Expired:   cmd.Bool("expired"),
+		OlderThan: cmd.Duration("older-than"),
+		DryRun:    cmd.Bool("dry-run"),
+	}
+	if opts.OlderThan < 0 {
+		return fmt.Errorf("--older-than %s: must not be negative", opts.OlderThan)
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	n, err := c.Clear(opts)
+	if err != nil {
+		return fmt.Errorf("clearing cache %s: %w", c.Dir(), err)
+	}
+	fmt.Println(clearSummary(n, opts, c.Dir()))
+	return nil
+}
+
+// clearSummary is the line `cache clear` ends with.
+func clearSummary(n int, opts awscache.ClearOptions, dir string) string {
+	what := "cached responses"
+	if opts.Expired {
+		what = "expired responses"
+	}
+	if opts.OlderThan > 0 {
+		what += " stored " + shortDuration(opts.OlderThan) + " ago or more"
+	}
+	if opts.DryRun {
+		return fmt.Sprintf("Would remove %d %s from %s", n, what, dir)
+	}
+	return fmt.Sprintf("Removed %d %s from %s", n, what, dir)
+}
+
+// shortDuration renders policy durations the way they are documented: 5m,
+// 6h, 7d.
+func shortDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return ""
+	case d%(24*time.Hour) == 0:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d%time.Hour == 0:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	case d%time.Minute == 0:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	}
+	return d.String()
+}
+
+func humanBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,220 @@
+package cachecmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = original })
+
+	callErr := fn()
+	_ = w.Close()
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String(), callErr
+}
+
+// seedCache points cliconfig.CacheDir at a temp dir and writes entries the
+// way awscache stores them, plus one it can't read. It returns the cache
+// directory.
+func seedCache(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	cliconfig.UseCacheDir(root)
+	t.Cleanup(func() { cliconfig.UseCacheDir("") })
+	dir := filepath.Join(root, "cache")
+
+	now := time.Now()
+	for _, e := range []struct {
+		account, service, operation, name string
+		age                               time.Duration
+	}{
+		{"111111111111", "EKS", "ListClusters", "ListClusters-a", time.Hour},                         // expired (5m TTL)
+		{"111111111111", "EKS", "DescribeCluster", "DescribeCluster-a", 10 * time.Second},            // fresh
+		{"111111111111", "EKS", "DescribeAddonVersions", "DescribeAddonVersions-a", 2 * time.Hour},   // fresh
+		{"222222222222", "EKS", "DescribeAddonVersions", "DescribeAddonVersions-b", 72 * time.Hour},  // stale
+		{"222222222222", "EKS", "DescribeAddonVersions", "DescribeAddonVersions-c", 240 * time.Hour}, // expired
+	} {
+		data, err := json.Marshal(map[string]any{
+			"account": e.account, "region": "us-east-1", "service": e.service, "operation": e.operation,
+			"storedAt": now.Add(-e.age).UTC(), "output": map[string]any{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeEntry(t, filepath.Join(dir, e.account, "us-east-1", e.service, e.name+".json"), data)
+	}
+	writeEntry(t, filepath.Join(dir, "111111111111", "us-east-1", "EKS", "DescribeNodegroup-x.json"), []byte("{not json"))
+	return dir
+}
+
+func writeEntry(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// entryFiles lists the entry files left under dir, by base name.
+func entryFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*", "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(p), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCacheClear(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantLeft []string
+	}{
+		{
+			name:    "everything",
+			wantOut: "Removed 6 cached responses from ",
+		},
+		{
+			name:     "expired",
+			args:     []string{"--expired"},
+			wantOut:  "Removed 3 expired responses from ",
+			wantLeft: []string{"DescribeAddonVersions-a", "DescribeAddonVersions-b", "DescribeCluster-a"},
+		},
+		{
+			name:     "older than",
+			args:     []string{"--older-than", "90m"},
+			wantOut:  "Removed 4 cached responses stored 90m ago or more from ",
+			wantLeft: []string{"DescribeCluster-a", "ListClusters-a"},
+		},
+		{
+			name:     "expired and older than",
+			args:     []string{"--expired", "--older-than", "48h"},
+			wantOut:  "Removed 2 expired responses stored 2d ago or more from ",
+			wantLeft: []string{"DescribeAddonVersions-a", "DescribeAddonVersions-b", "DescribeCluster-a", "ListClusters-a"},
+		},
+		{
+			name:    "dry run",
+			args:    []string{"--dry-run"},
+			wantOut: "Would remove 6 cached responses from ",
+			wantLeft: []string{"DescribeAddonVersions-a", "DescribeAddonVersions-b", "DescribeAddonVersions-c",
+				"DescribeCluster-a", "DescribeNodegroup-x", "ListClusters-a"},
+		},
+		{
+			name:    "dry run older than",
+			args:    []string{"-d", "--older-than", "90m"},
+			wantOut: "Would remove 4 cached responses stored 90m ago or more from ",
+			wantLeft: []string{"DescribeAddonVersions-a", "DescribeAddonVersions-b", "DescribeAddonVersions-c",
+				"DescribeCluster-a", "DescribeNodegroup-x", "ListClusters-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := seedCache(t)
+			out, err := captureStdout(t, func() error {
+				return clearCommand().Run(context.Background(), append([]string{"clear"}, tt.args...))
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(out, tt.wantOut) || !strings.Contains(out, dir) {
+				t.Errorf("output = %q, want %q…%s", out, tt.wantOut, dir)
+			}
+			left := entryFiles(t, dir)
+			if strings.Join(left, ",") != strings.Join(tt.wantLeft, ",") {
+				t.Errorf("left = %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestCacheClear_NegativeOlderThan(t *testing.T) {
+	dir := seedCache(t)
+	err := clearCommand().Run(context.Background(), []string{"clear", "--older-than", "-1h"})
+	if err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Fatalf("err = %v", err)
+	}
+	if n := len(entryFiles(t, dir)); n != 6 {
+		t.Errorf("%d entries left, want all 6", n)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		dir := seedCache(t)
+		out, err := captureStdout(t, func() error {
+			return statsCommand().Run(context.Background(), []string{"stats", "-o", "json"})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var view statsView
+		if err := json.Unmarshal([]byte(out), &view); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		// The unreadable entry isn't counted.
+		if view.Dir != dir || view.Entries != 5 || view.Accounts != 2 || len(view.Operations) != 3 {
+			t.Fatalf("view = %+v", view)
+		}
+		want := map[string]operationView{
+			"DescribeAddonVersions": {Entries: 3, Fresh: 1, Stale: 1, Expired: 1, TTL: "6h", StaleFor: "7d"},
+			"DescribeCluster":       {Entries: 1, Fresh: 1, TTL: "1m"},
+			"ListClusters":          {Entries: 1, Expired: 1, TTL: "5m"},
+		}
+		for _, o := range view.Operations {
+			w := want[o.Operation]
+			if o.Service != "EKS" || o.Entries != w.Entries || o.Fresh != w.Fresh || o.Stale != w.Stale ||
+				o.Expired != w.Expired || o.TTL != w.TTL || o.StaleFor != w.StaleFor {
+				t.Errorf("%s = %+v, want %+v", o.Operation, o, w)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		cliconfig.UseCacheDir(t.TempDir())
+		t.Cleanup(func() { cliconfig.UseCacheDir("") })
+		out, err := captureStdout(t, func() error {
+			return statsCommand().Run(context.Background(), []string{"stats"})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "is empty. Pass --cache") {
+			t.Errorf("output = %q", out)
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		seedCache(t)
+		if err := statsCommand().Run(context.Background(), []string{"stats", "-o", "csv"}); err == nil {
+			t.Error("want an invalid format error")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+// Package cachecmd wires `refresh cache`: inspection and clearing of the
+// on-disk AWS response cache the global --cache flag turns on.
+package cachecmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Command returns the `refresh cache` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect or clear the on-disk AWS response cache (stats, clear)",
+		Description: `With the global --cache flag (or REFRESH_CACHE=true), AWS reads are cached on
+disk, keyed by account, region, API operation and parameters, so repeated
+runs skip calls whose answers can't have changed much:
+
+  cluster, nodegroup and add-on lists                 5 minutes
+  cluster, nodegroup and add-on descriptions          1 minute
+  published add-on versions, EKS AMI parameters       6 hours, then served stale for 7 days
+  the Kubernetes support calendar                     24 hours, then served stale for 30 days
+
+A stale answer is returned at once while a background call refreshes it.
+Commands that change something (updates, scaling, apply, upgrades) read
+live data for every decision they gate on, and a successful change drops
+the cached lists and descriptions of that service in its account and
+region.
+
+  refresh --cache status                  # cache this run's reads
+  refresh cache stats                     # what is cached, and how fresh
+  refresh cache clear --expired           # drop what would no longer be served
+  refresh cache clear --older-than 48h -d # count what is two days old or more`,
+		Commands: []*cli.Command{
+			statsCommand(),
+			clearCommand(),
+		},
+	}
+}
+
+func statsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "Show cached responses by operation, with how many are fresh, stale or expired",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runStats(ctx, cmd) },
+	}
+}
+
+func clearCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "clear",
+		Usage: "Remove cached responses",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "expired", Usage: "Only remove responses too old to be served, even stale"},
+			&cli.DurationFlag{Name: "older-than", Usage: "Only remove responses stored at least this long ago (e.g. 30m, 48h)"},
+			&cli.BoolFlag{Name: "dry-run", Aliases: []string{"d"}, Usage: "Show how many responses would be removed without removing them"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runClear(ctx, cmd) },
+	}
+}
@@ -0,0 +1,181 @@
+package calendarcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+const dateLayout = "2006-01-02"
+
+// calendarRow is one version's window as shown by `calendar show`.
+type calendarRow struct {
+	Version       string               `json:"version" yaml:"version"`
+	StandardUntil time.Time            `json:"standardUntil" yaml:"standardUntil"`
+	ExtendedUntil *time.Time           `json:"extendedUntil,omitempty" yaml:"extendedUntil,omitempty"`
+	Tier          status.SupportTier   `json:"tier" yaml:"tier"`
+	Source        status.SupportSource `json:"source" yaml:"source"`
+	FetchedAt     *time.Time           `json:"fetchedAt,omitempty" yaml:"fetchedAt,omitempty"`
+}
+
+// calendarView is the `calendar show` payload.
+type calendarView struct {
+	Path      string        `json:"path" yaml:"path"`
+	UpdatedAt *time.Time    `json:"updatedAt,omitempty" yaml:"updatedAt,omitempty"`
+	Versions  []calendarRow `json:"versions" yaml:"versions"`
+}
+
+func runUpdate(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	cache := status.DefaultCalendarCache()
+	if cache == nil {
+		return errors.New("cannot resolve the refresh config directory for the calendar")
+	}
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var cal status.Calendar
+	err = runner.WithSpinner("cluster", "Support calendar updated!", func() error {
+		var uerr error
+		cal, uerr = status.UpdateCalendar(ctx, eks.NewFromConfig(awsCfg), cache, time.Now())
+		return uerr
+	})
+	if err != nil {
+		return err
+	}
+	// Summary on stderr so -o json/yaml stays clean on stdout.
+	fmt.Fprintf(os.Stderr, "Cached %d version windows in %s\n", len(cal.Versions), cache.Path())
+	return output(cmd.String("format"), buildView(cache.Path(), cal, time.Now()))
+}
+
+func runShow(_ context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	cache := status.DefaultCalendarCache()
+	return output(cmd.String("format"), buildView(cache.Path(), cache.Snapshot(), time.Now()))
+}
+
+// buildView merges the cached calendar over the compiled-in one: cached rows
+// win, built-in rows fill versions the cache has never seen.
+func buildView(path string, cached status.Calendar, now time.Time) calendarView {
+	view := calendarView{Path: path}
+	if !cached.UpdatedAt.IsZero() {
+		u := cached.UpdatedAt
+		view.UpdatedAt = &u
+	}
+	merged := status.BuiltInCalendar()
+	merged.Merge(cached.Versions...)
+	for _, e := range merged.Versions {
+		row := calendarRow{
+			Version:       e.Version,
+			StandardUntil: e.StandardUntil,
+			ExtendedUntil: e.ExtendedUntil,
+			Tier:          e.Posture(now).Tier,
+			Source:        status.SourceBuiltIn,
+		}
+		if _, ok := cached.Lookup(e.Version); ok {
+			row.Source = status.SourceCache
+			if !e.FetchedAt.IsZero() {
+				f := e.FetchedAt
+				row.FetchedAt = &f
+			}
+		}
+		view.Versions = append(view.Versions, row)
+	}
+	return view
+}
+
+func output(format string, view calendarView) error {
+	if handled, err := runner.EncodeStdout(format, view); handled {
+		return err
+	}
+	now := time.Now()
+	th := render.Default(os.Stdout)
+	columns := []ui.Column{
+		{Title: "VERSION", Min: 7},
+		{Title: "STANDARD UNTIL", Min: 14},
+		{Title: "EXTENDED UNTIL", Min: 14},
+		{Title: "TIER NOW", Min: 11},
+		{Title: "SOURCE", Min: 8, Max: 24},
+	}
+	if ui.PlainOutput() {
+		table := ui.NewPTable(columns, ui.CyanHeaders())
+		for _, r := range view.Versions {
+			table.AddRow(r.Version, r.StandardUntil.Format(dateLayout), extendedCell(r), string(r.Tier), sourceCell(r, now))
+		}
+		table.Render()
+	} else {
+		tbl := th.NewTable(columns...)
+		for _, r := range view.Versions {
+			tbl.Row(th.Paint(th.Pal.White, r.Version), r.StandardUntil.Format(dateLayout), extendedCell(r),
+				tierPretty(th, r.Tier), th.Paint(th.Pal.Dim, sourceCell(r, now)))
+		}
+		for _, line := range tbl.Render() {
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+	switch {
+	case view.UpdatedAt != nil:
+		fmt.Printf("Calendar %s (updated %s ago)\n", view.Path, age(now.Sub(*view.UpdatedAt)))
+	default:
+		fmt.Printf("No cached calendar at %s — showing built-in windows. Run %s.\n",
+			view.Path, color.CyanString("refresh calendar update"))
+	}
+	return nil
+}
+
+func extendedCell(r calendarRow) string {
+	if r.ExtendedUntil == nil {
+		return "-"
+	}
+	return r.ExtendedUntil.Format(dateLayout)
+}
+
+func sourceCell(r calendarRow, now time.Time) string {
+	if r.Source == status.SourceCache && r.FetchedAt != nil {
+		return fmt.Sprintf("cache (%s old)", age(now.Sub(*r.FetchedAt)))
+	}
+	return string(r.Source)
+}
+
+func tierPretty(th *render.Theme, t status.SupportTier) string {
+	switch t {
+	case status.SupportStandard:
+		return th.Paint(th.Pal.Green, string(t))
+	case status.SupportExtended:
+		return th.Token(render.Warn, string(t))
+	case status.SupportUnsupported:
+		return th.Token(render.Fail, string(t))
+	default:
+		return th.Paint(th.Pal.Dim, string(t))
+	}
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
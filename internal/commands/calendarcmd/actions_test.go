@@ -0,0 +1,44 @@
+package calendarcmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+func TestBuildView_CacheOverridesBuiltIn(t *testing.T) {
+	now := time.Date(2026, 6, 11, 0, 0, 0, 0, time.UTC)
+	fetched := now.Add(-48 * time.Hour)
+	ext := time.Date(2028, 7, 1, 0, 0, 0, 0, time.UTC)
+	cached := status.Calendar{UpdatedAt: fetched, Versions: []status.CalendarEntry{
+		// Newer than the compiled-in table.
+		{Version: "1.34", StandardUntil: time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC), ExtendedUntil: &ext, FetchedAt: fetched},
+		// Overrides the compiled-in 1.33 row.
+		{Version: "1.33", StandardUntil: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), FetchedAt: fetched},
+	}}
+
+	view := buildView("/tmp/calendar.yaml", cached, now)
+	rows := map[string]calendarRow{}
+	for _, r := range view.Versions {
+		rows[r.Version] = r
+	}
+	if r := rows["1.34"]; r.Source != status.SourceCache || r.Tier != status.SupportStandard {
+		t.Errorf("1.34 = %+v, want cached/standard", r)
+	}
+	if r := rows["1.33"]; r.Source != status.SourceCache || !r.StandardUntil.Equal(time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("1.33 = %+v, want the cached window", r)
+	}
+	if r := rows["1.28"]; r.Source != status.SourceBuiltIn || r.Tier != status.SupportUnsupported {
+		t.Errorf("1.28 = %+v, want built-in/unsupported", r)
+	}
+	if got := sourceCell(rows["1.34"], now); got != "cache (2d old)" {
+		t.Errorf("sourceCell = %q", got)
+	}
+	if view.Versions[len(view.Versions)-1].Version != "1.34" {
+		t.Error("rows should be sorted by version")
+	}
+	if view.UpdatedAt == nil || !view.UpdatedAt.Equal(fetched) {
+		t.Errorf("UpdatedAt = %v", view.UpdatedAt)
+	}
+}
@@ -0,0 +1,59 @@
+// Package calendarcmd wires `refresh calendar`: the locally cached EKS
+// support calendar that keeps support tiers resolvable when
+// DescribeClusterVersions is denied or a version postdates the compiled-in
+// table.
+package calendarcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Command returns the `refresh calendar` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "calendar",
+		Usage: "Manage the cached EKS support calendar (update, show)",
+		Description: `refresh resolves each Kubernetes version's support window from
+DescribeClusterVersions and writes every answer to calendar.yaml in the
+refresh config directory. When the API is unavailable, the cached window is
+used (its age is shown), and only then the compiled-in calendar.
+
+  refresh calendar update   # fetch every version's window now
+  refresh calendar show     # print the known windows and where each came from`,
+		Commands: []*cli.Command{
+			updateCommand(),
+			showCommand(),
+		},
+	}
+}
+
+func updateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "update",
+		Usage: "Fetch all EKS support windows and rewrite the cached calendar",
+		Description: `Call DescribeClusterVersions for every version (standard and extended
+support) and merge the answer into the cached calendar. Run it from a
+principal with eks:DescribeClusterVersions so restricted roles can resolve
+support tiers from the cache later.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runUpdate(ctx, cmd) },
+	}
+}
+
+func showCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "show",
+		Usage: "Print the known EKS support windows and their source",
+		Description: `Print every version's standard/extended support window from the cached
+calendar, filled in from the compiled-in table for versions the cache has
+never seen. Offline: no AWS calls.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runShow(ctx, cmd) },
+	}
+}
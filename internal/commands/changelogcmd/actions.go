@@ -0,0 +1,146 @@
+package changelogcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+)
+
+// cacheView is the sync/import payload: what the cache now holds.
+type cacheView struct {
+	Path      string    `json:"path" yaml:"path"`
+	URL       string    `json:"url" yaml:"url"`
+	Imported  string    `json:"imported,omitempty" yaml:"imported,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt" yaml:"fetchedAt"`
+	Releases  int       `json:"releases" yaml:"releases"`
+	Newest    string    `json:"newest,omitempty" yaml:"newest,omitempty"`
+	// Changed is false when sync found the cache already current (304).
+	Changed *bool `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+func newView(path string, b amichangelog.Bundle) cacheView {
+	v := cacheView{Path: path, URL: b.URL, Imported: b.Imported, FetchedAt: b.FetchedAt, Releases: len(b.Releases)}
+	if len(b.Releases) > 0 {
+		v.Newest = b.Releases[0].Tag
+	}
+	return v
+}
+
+func runSync(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	path, err := cachePath()
+	if err != nil {
+		return err
+	}
+	timeout := cmd.Duration("timeout")
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	client := amichangelog.NewClient(&http.Client{Timeout: timeout}, strings.TrimSpace(cmd.String("url")), path)
+
+	var (
+		b       amichangelog.Bundle
+		changed bool
+	)
+	err = runner.WithSpinner("changelog", "Release notes synced!", func() error {
+		var serr error
+		b, changed, serr = client.Sync(ctx)
+		return serr
+	})
+	if err != nil {
+		return err
+	}
+	view := newView(path, b)
+	view.Changed = &changed
+	if handled, err := runner.EncodeStdout(cmd.String("format"), view); handled {
+		return err
+	}
+	state := "updated"
+	if !changed {
+		state = "already current"
+	}
+	fmt.Printf("Cached %d release(s) from %s in %s (%s)\n", view.Releases, view.URL, view.Path, state)
+	if view.Newest != "" {
+		fmt.Printf("Newest release: %s\n", view.Newest)
+	}
+	return nil
+}
+
+func runExport(_ context.Context, cmd *cli.Command) error {
+	dest := strings.TrimSpace(cmd.Args().First())
+	if dest == "" {
+		return errors.New("usage: refresh changelog export <file|->")
+	}
+	path, err := cachePath()
+	if err != nil {
+		return err
+	}
+	b, err := amichangelog.LoadBundle(path)
+	if err != nil {
+		return err
+	}
+	if len(b.Releases) == 0 {
+		return fmt.Errorf("no cached releases in %s; run 'refresh changelog sync' first", path)
+	}
+	b = b.Export()
+	if dest == "-" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	}
+	if err := amichangelog.SaveBundle(dest, b); err != nil {
+		return err
+	}
+	// Summary on stderr, matching sync/import's stdout staying machine-clean.
+	fmt.Fprintf(os.Stderr, "Exported %d release(s) fetched %s to %s\n",
+		len(b.Releases), b.FetchedAt.Format(time.RFC3339), dest)
+	return nil
+}
+
+func runImport(_ context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	src := strings.TrimSpace(cmd.Args().First())
+	if src == "" {
+		return errors.New("usage: refresh changelog import <file>")
+	}
+	path, err := cachePath()
+	if err != nil {
+		return err
+	}
+	b, err := amichangelog.ImportBundle(src, path)
+	if err != nil {
+		return err
+	}
+	view := newView(path, b)
+	if handled, err := runner.EncodeStdout(cmd.String("format"), view); handled {
+		return err
+	}
+	fmt.Printf("Imported %d release(s) into %s\n", view.Releases, view.Path)
+	fmt.Printf("Release notes are %s; %s replaces them.\n",
+		amichangelog.Provenance{Origin: amichangelog.OriginImport, FetchedAt: b.FetchedAt}.Label(time.Now()),
+		color.CyanString("refresh changelog sync"))
+	return nil
+}
+
+func cachePath() (string, error) {
+	path, err := amichangelog.CachePath()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve the refresh config directory for the release cache: %w", err)
+	}
+	return path, nil
+}
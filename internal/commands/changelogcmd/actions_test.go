@@ -0,0 +1,58 @@
+package changelogcmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+)
+
+func run(t *testing.T, args ...string) error {
+	t.Helper()
+	return Command().Run(context.Background(), append([]string{"changelog"}, args...))
+}
+
+func TestSyncExportImport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = fmt.Fprint(w, `[{"tag_name":"v20260601","body":"- kernel 6.1.140\n"}]`)
+	}))
+	defer srv.Close()
+
+	connected := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", connected)
+	if err := run(t, "sync", "--url", srv.URL, "-o", "json"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	bundle := filepath.Join(t.TempDir(), "bundle.json")
+	if err := run(t, "export", bundle); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	airgapped := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", airgapped)
+	if err := run(t, "export", bundle); err == nil {
+		t.Error("export with an empty cache should fail")
+	}
+	if err := run(t, "import", bundle, "-o", "json"); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	b, err := amichangelog.LoadBundle(filepath.Join(airgapped, "ami-releases.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Releases) != 1 || b.Imported != bundle || b.URL != srv.URL {
+		t.Errorf("imported cache = %+v", b)
+	}
+}
+
+func TestImportRequiresFile(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	if err := run(t, "import"); err == nil {
+		t.Error("import without a file should fail")
+	}
+}
@@ -0,0 +1,79 @@
+// Package changelogcmd wires `refresh changelog`: the on-disk cache of
+// amazon-eks-ami release notes behind every AMI changelog, and moving it into
+// networks that can't reach GitHub.
+package changelogcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+)
+
+// Command returns the `refresh changelog` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "changelog",
+		Usage: "Manage the cached amazon-eks-ami release notes (sync, export, import)",
+		Description: `AMI changelogs (nodegroup update --dry-run, the -o json run summary, the
+status SECURITY column) read the amazon-eks-ami release notes through a cache,
+ami-releases.json in the refresh config directory. The cache is revalidated
+with its ETag at most every 6 hours and served at its age when the endpoint
+can't be reached. REFRESH_AMI_RELEASES_URL points it at a mirror of the GitHub
+releases API.
+
+For an air-gapped network, sync and export where GitHub is reachable, carry
+the bundle across, and import it. An imported cache is used as-is, with no
+requests, until the next sync:
+  refresh changelog sync
+  refresh changelog export ami-releases.json
+  refresh changelog import ami-releases.json   # inside the air gap`,
+		Commands: []*cli.Command{
+			syncCommand(),
+			exportCommand(),
+			importCommand(),
+		},
+	}
+}
+
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Fetch the amazon-eks-ami releases now and update the cache",
+		Description: `Revalidate the cache against the releases endpoint whatever its age,
+replacing an imported cache. Fails when the endpoint can't be reached.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "url", Usage: "Releases endpoint (GitHub or a mirror of its releases API)", Value: amichangelog.DefaultReleasesURL, Sources: cli.EnvVars(amichangelog.ReleasesURLEnv)},
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runSync(ctx, cmd) },
+	}
+}
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Write the cached releases to a bundle file",
+		ArgsUsage: "<file|->",
+		Description: `Write the cache as a bundle for 'refresh changelog import' on another
+machine; "-" writes to stdout. Offline: run 'refresh changelog sync' first for
+the newest releases.`,
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runExport(ctx, cmd) },
+	}
+}
+
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Load a bundle into the cache",
+		ArgsUsage: "<file>",
+		Description: `Replace the cache with a bundle written by 'refresh changelog export'.
+The imported releases are used without requests, at the age they were
+fetched, until the next 'refresh changelog sync'.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runImport(ctx, cmd) },
+	}
+}
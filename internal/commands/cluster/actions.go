@@ -3,11 +3,13 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
 
 	"github.com/dantech2000/refresh/internal/commands/clusterview"
@@ -53,13 +55,28 @@ func runList(ctx context.Context, cmd *cli.Command) error {
 }
 
 func listClustersOnce(ctx context.Context, cmd *cli.Command) error {
+	sel, err := runner.ResolveSelector(cmd)
+	if err != nil {
+		return err
+	}
 	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
 	if err != nil {
 		return err
 	}
 	defer cancel()
 
-	clusterService := factory.NewClusterService(awsCfg, cmd.Bool("show-health"), nil)
+	accts, acctErrs, err := runner.ResolveAccounts(ctx, cmd, awsCfg)
+	if err != nil {
+		return err
+	}
+	// An account whose role can't be assumed is a warning, like an
+	// unreachable region; only a total failure is fatal.
+	for _, e := range acctErrs {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %v", e))
+	}
+	if len(accts) == 0 {
+		return fmt.Errorf("no account in the inventory could be assumed")
+	}
 
 	filters := runner.ParseFilters(cmd.StringSlice("filter"))
 	if pattern := strings.TrimSpace(cmd.Args().First()); pattern != "" {
@@ -73,21 +90,37 @@ func listClustersOnce(ctx context.Context, cmd *cli.Command) error {
 		Filters:        filters,
 		AllRegions:     allRegions,
 		MaxConcurrency: cmd.Int("max-concurrency"),
+		Selector:       sel,
+		HealthOptions: func(cluster string) (health.Options, error) {
+			return runner.HealthOptions(cmd, cluster)
+		},
 	}
 
 	startTime := time.Now()
 	var summaries []clustersvc.ClusterSummary
-	if allRegions || len(cmd.StringSlice("region")) > 0 {
-		summaries, err = runMultiRegionListWithProgress(ctx, clusterService, options)
-	} else {
-		err = runner.WithSpinner("cluster", "Cluster information gathered!", func() error {
-			var lerr error
-			summaries, lerr = clusterService.List(ctx, options)
-			return lerr
-		})
-	}
-	if err != nil {
-		return err
+	for _, acct := range accts {
+		clusterService := factory.NewClusterService(acct.Config, cmd.Bool("show-health"), nil)
+		var found []clustersvc.ClusterSummary
+		if allRegions || len(cmd.StringSlice("region")) > 0 {
+			found, err = runMultiRegionListWithProgress(ctx, clusterService, options)
+		} else {
+			err = runner.WithSpinner("cluster", "Cluster information gathered!", func() error {
+				var lerr error
+				found, lerr = clusterService.List(ctx, options)
+				return lerr
+			})
+		}
+		if err != nil {
+			if len(accts) == 1 {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, color.YellowString("warning: account %s: %v", acct.Label(), err))
+			continue
+		}
+		for i := range found {
+			found[i].Account = acct.Label()
+		}
+		summaries = append(summaries, found...)
 	}
 	elapsed := time.Since(startTime)
 
@@ -144,6 +177,9 @@ func runDescribe(ctx context.Context, cmd *cli.Command) error {
 		ShowSecurity:  cmd.Bool("show-security") || cmd.Bool("detailed"),
 		IncludeAddons: cmd.Bool("include-addons"),
 		Detailed:      cmd.Bool("detailed"),
+		HealthOptions: func(cluster string) (health.Options, error) {
+			return runner.HealthOptions(cmd, cluster)
+		},
 	}
 
 	var details *clustersvc.ClusterDetails
@@ -7,6 +7,7 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/commands/runner"
 	appconfig "github.com/dantech2000/refresh/internal/config"
 )
 
@@ -47,12 +48,23 @@ region/cluster hierarchy. Use --watch to redraw on the --watch-interval
 
   refresh cluster list -A --filter status=ACTIVE
   refresh cluster list -o tree
-  refresh cluster list --watch --watch-interval 5s`,
-		Flags: []cli.Flag{
+  refresh cluster list --watch --watch-interval 5s
+
+Select by EKS tags with -l/--selector (kubectl-style: env=prod,team!=data,
+tier in (web,api), owner, !legacy) or a saved --group.
+
+  refresh cluster list -A -l 'env=prod,tier in (web,api)'
+
+Add --account-role (repeatable) or --org-role to list across several AWS
+accounts; an ACCOUNT column appears and accounts whose role can't be assumed
+are reported as warnings.
+
+  refresh cluster list -A --org-role OrganizationAccountAccessRole`,
+		Flags: append([]cli.Flag{
 			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout (e.g. 60s, 2m)", Value: 60 * time.Second, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
 			&cli.IntFlag{Name: "max-concurrency", Aliases: []string{"C"}, Usage: "Max concurrent region requests", Value: appconfig.DefaultMaxConcurrency, Sources: cli.EnvVars("REFRESH_MAX_CONCURRENCY")},
 			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Query all EKS-supported regions"},
-			&cli.StringFlag{Name: "sort", Usage: "Sort by field: name,status,version,region", Value: "name"},
+			&cli.StringFlag{Name: "sort", Usage: "Sort by field: name,status,version,region,account", Value: "name"},
 			&cli.BoolFlag{Name: "desc", Usage: "Sort descending"},
 			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "Specific region(s) to query (can be used multiple times)"},
 			&cli.BoolFlag{Name: "show-health", Aliases: []string{"H"}, Usage: "Include health status for each cluster"},
@@ -61,7 +73,7 @@ region/cluster hierarchy. Use --watch to redraw on the --watch-interval
 			&cli.BoolFlag{Name: "tree", Aliases: []string{"T"}, Usage: "Display results as hierarchical tree (implies --all-regions)"},
 			&cli.BoolFlag{Name: "watch", Aliases: []string{"w"}, Usage: "Re-run and redraw every --watch-interval until interrupted"},
 			&cli.DurationFlag{Name: "watch-interval", Usage: "Refresh interval for --watch", Value: 10 * time.Second},
-		},
+		}, append(append(runner.HealthFlags(), runner.SelectorFlags()...), runner.AccountFlags()...)...),
 		Action: func(ctx context.Context, cmd *cli.Command) error { return runList(ctx, cmd) },
 	}
 }
@@ -75,7 +87,7 @@ func describeCommand() *cli.Command {
 		Description: `Get detailed information about an EKS cluster including networking,
 security configuration, add-ons, and health status. Direct EKS API calls
 provide fast, comprehensive results without CloudFormation dependency.`,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout (e.g. 60s, 2m)", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
 			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or pattern"},
 			&cli.BoolFlag{Name: "detailed", Aliases: []string{"d"}, Usage: "Show comprehensive information including networking and security"},
@@ -85,7 +97,7 @@ provide fast, comprehensive results without CloudFormation dependency.`,
 			&cli.BoolFlag{Name: "check-readiness", Aliases: []string{"R"}, Usage: "Measure real Kubernetes node readiness (Ready/desired) via the cluster API; without it NODES shows desired count only"},
 			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig for --check-readiness (defaults to $KUBECONFIG, then ~/.kube/config)"},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
-		},
+		}, runner.HealthFlags()...),
 		Action: func(ctx context.Context, cmd *cli.Command) error { return runDescribe(ctx, cmd) },
 	}
 }
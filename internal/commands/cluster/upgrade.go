@@ -8,12 +8,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
 
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
 	"github.com/dantech2000/refresh/internal/commands/factory"
 	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/customami"
 	"github.com/dantech2000/refresh/internal/rollview"
 	"github.com/dantech2000/refresh/internal/services/upgrade"
 	"github.com/dantech2000/refresh/internal/ui"
@@ -41,26 +46,39 @@ same command after a failure (or Ctrl+C) resumes where it left off, and
 rerunning after success is a no-op.
 
 Examples:
-   # Print the plan only (exits non-zero if anything blocks the upgrade)
+   # Print the plan and its cost estimate only (exits non-zero if anything
+   # blocks the upgrade)
    refresh cluster upgrade -c prod-east --to 1.33 --dry-run
 
    # Execute, confirming each mutating phase
    refresh cluster upgrade -c prod-east --to 1.33
 
    # Non-interactive (CI) run
-   refresh cluster upgrade -c prod-east --to 1.33 --yes`,
+   refresh cluster upgrade -c prod-east --to 1.33 --yes
+
+   # Land nodegroups on the AMI release validated in staging rather than the
+   # newest one (intermediate hops still roll to their latest release)
+   refresh cluster upgrade -c prod-east --to 1.33 --nodegroup-release-version 1.33.0-20260601
+
+   # Roll custom-AMI nodegroups too, once, on the final hop: refresh publishes a
+   # launch template version with the image built for the target version
+   refresh cluster upgrade -c prod-east --to 1.33 --custom-ami ssm:/platform/ami/{version}/golden`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or pattern"},
 			&cli.StringFlag{Name: "to", Usage: "Target Kubernetes version (e.g. 1.33)", Required: true},
 			&cli.BoolFlag{Name: "dry-run", Aliases: []string{"d"}, Usage: "Print the full ordered plan without mutating anything"},
 			&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Skip per-phase confirmation prompts"},
 			&cli.BoolFlag{Name: "force", Usage: "Force nodegroup rolls when pods can't be drained due to PDBs"},
-			&cli.StringSliceFlag{Name: "skip", Aliases: []string{"s"}, Usage: "Addon to skip (repeatable; for addons managed via Helm/GitOps)"},
-			&cli.StringSliceFlag{Name: "skip-nodegroup", Usage: "Nodegroup name pattern to skip (repeatable)"},
+			&cli.StringSliceFlag{Name: "skip", Aliases: []string{"s"}, Usage: "Addon to skip (repeatable; for addons managed via Helm/GitOps; @name expands a refresh.yaml skip list)"},
+			&cli.StringSliceFlag{Name: "skip-nodegroup", Usage: "Nodegroup name pattern to skip (repeatable; @name expands a refresh.yaml skip list)"},
+			&cli.StringFlag{Name: "nodegroup-release-version", Usage: "Roll nodegroups to this AMI release (e.g. 1.33.0-20260601) on the final hop instead of the latest; validated against the published releases"},
+			&cli.StringFlag{Name: "custom-ami", Usage: "Roll custom-AMI nodegroups on the final hop onto this image: ssm:<parameter>, name:<pattern> or an ami- ID ({version} = the target version)"},
+			&cli.StringSliceFlag{Name: "custom-ami-owner", Usage: "Account ID or alias owning name:<pattern> images (repeatable; default self)"},
 			&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Usage: "Suppress progress output"},
 			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Overall operation timeout", Value: upgradeDefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
 			&cli.DurationFlag{Name: "poll-interval", Aliases: []string{"p"}, Usage: "How often to poll in-flight updates", Value: 15 * time.Second},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Plan output format (table, json, yaml, plain)", Value: "table"},
+			&cli.StringFlag{Name: "prices", Usage: "Price table YAML for the --dry-run cost estimate (see `refresh cost`)", Sources: cli.EnvVars("REFRESH_PRICES")},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error { return runUpgrade(ctx, cmd) },
 	}
@@ -82,14 +100,38 @@ func runUpgrade(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	svc := upgrade.NewService(eks.NewFromConfig(awsCfg), factory.NewDefaultLogger(nil))
+	eksClient := eks.NewFromConfig(awsCfg)
+	svc := upgrade.NewService(eksClient, factory.NewDefaultLogger(nil))
 	if pi := cmd.Duration("poll-interval"); pi > 0 {
 		svc.PollInterval = pi
 	}
+	if spec := strings.TrimSpace(cmd.String("custom-ami")); spec != "" {
+		src, err := customami.ParseSource(spec, cmd.StringSlice("custom-ami-owner"))
+		if err != nil {
+			return err
+		}
+		svc.CustomAMI = customami.NewRollerFromConfig(src, awsCfg, eksClient)
+	}
 
+	// --skip/--skip-nodegroup accept "@name" references to refresh.yaml skip lists.
+	skipAddons, err := runner.SkipValues(cmd, "skip")
+	if err != nil {
+		return err
+	}
+	skipNodegroups, err := runner.SkipValues(cmd, "skip-nodegroup")
+	if err != nil {
+		return err
+	}
+	release := strings.TrimSpace(cmd.String("nodegroup-release-version"))
+	if release != "" {
+		if err := validateNodegroupRelease(ctx, awsCfg, eksClient, clusterName, cmd.String("to"), release, skipNodegroups); err != nil {
+			return err
+		}
+	}
 	planOpts := upgrade.PlanOptions{
-		SkipAddons:     cmd.StringSlice("skip"),
-		SkipNodegroups: cmd.StringSlice("skip-nodegroup"),
+		SkipAddons:              skipAddons,
+		SkipNodegroups:          skipNodegroups,
+		NodegroupReleaseVersion: release,
 	}
 
 	var plan *upgrade.Plan
@@ -115,6 +157,9 @@ func runUpgrade(ctx context.Context, cmd *cli.Command) error {
 		}
 	} else {
 		renderPlan(plan)
+		if cmd.Bool("dry-run") {
+			renderCostEstimate(ctx, eksClient, awsCfg.Region, cmd.String("prices"), plan)
+		}
 	}
 
 	// A plan with blockers prints and exits non-zero without mutating.
@@ -145,7 +190,7 @@ func runUpgrade(ctx context.Context, cmd *cli.Command) error {
 		if kube := resolveReadinessKubeClient(ctx, "", false); kube != nil {
 			timeout, poll := cmd.Duration("timeout"), cmd.Duration("poll-interval")
 			ngObserver = func(octx context.Context, ng string) {
-				rollview.LiveRollForUpdate(octx, kube, ng, timeout, poll)
+				rollview.LiveRollForUpdate(octx, kube, ng, timeout, poll, rollview.LiveRollOptions{})
 			}
 		}
 	}
@@ -154,10 +199,12 @@ func runUpgrade(ctx context.Context, cmd *cli.Command) error {
 		Yes:               cmd.Bool("yes"),
 		Confirm:           promptPhase,
 		Progress:          progress,
-		SkipAddons:        cmd.StringSlice("skip"),
-		SkipNodegroups:    cmd.StringSlice("skip-nodegroup"),
+		SkipAddons:        skipAddons,
+		SkipNodegroups:    skipNodegroups,
 		Force:             cmd.Bool("force"),
 		NodegroupObserver: ngObserver,
+
+		NodegroupReleaseVersion: release,
 	})
 
 	renderReport(report)
@@ -243,3 +290,42 @@ func renderReport(report *upgrade.Report) {
 		ui.Outf("%s %s\n", color.YellowString("remaining:"), r)
 	}
 }
+
+// validateNodegroupRelease checks that release is published for the AMI type
+// of every nodegroup the upgrade would roll, at the target version.
+// Custom-AMI and skipped nodegroups aren't rolled, so they aren't checked.
+func validateNodegroupRelease(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName, targetVersion, release string, skip []string) error {
+	names, err := awsinternal.ListAllPages(ctx, "listing nodegroups",
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return eksClient.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return err
+	}
+	ssmClient := ssm.NewFromConfig(awsCfg)
+	checked := make(map[ekstypes.AMITypes]bool)
+	for _, name := range names {
+		if upgrade.MatchesAny(name, skip) {
+			continue
+		}
+		out, err := eksClient.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)})
+		if err != nil {
+			return awsinternal.FormatAWSError(err, fmt.Sprintf("describing nodegroup %s", name))
+		}
+		ng := out.Nodegroup
+		if ng == nil || ng.AmiType == ekstypes.AMITypesCustom || checked[ng.AmiType] {
+			continue
+		}
+		releases, err := awsinternal.ReleasesForType(ctx, ssmClient, targetVersion, ng.AmiType)
+		if err != nil {
+			return fmt.Errorf("validating --nodegroup-release-version for nodegroup %s: %w", name, err)
+		}
+		if _, err := awsinternal.FindRelease(releases, release, ng.AmiType, targetVersion); err != nil {
+			return fmt.Errorf("nodegroup %s: %w", name, err)
+		}
+		checked[ng.AmiType] = true
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fatih/color"
+
+	"github.com/dantech2000/refresh/internal/services/cost"
+	"github.com/dantech2000/refresh/internal/services/upgrade"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// nodegroupRolls counts how many pending rolls each nodegroup has across the
+// plan's hops (a multi-hop upgrade rolls a nodegroup once per hop).
+func nodegroupRolls(plan *upgrade.Plan) map[string]int {
+	rolls := map[string]int{}
+	for _, hop := range plan.Hops {
+		for _, step := range hop.Steps {
+			if step.Type == upgrade.StepNodegroup && step.Status == upgrade.StatusPending {
+				rolls[step.Target]++
+			}
+		}
+	}
+	return rolls
+}
+
+// renderCostEstimate prints the dry-run cost section: the extended-support
+// premium the cluster is on course to pay and the EC2 surge the planned
+// nodegroup rolls launch. Best-effort — a pricing failure is a note, not an
+// error, since the plan itself is what the dry-run is for.
+func renderCostEstimate(ctx context.Context, api cost.EKSAPI, region, pricesPath string, plan *upgrade.Plan) {
+	prices, source, err := cost.LoadPrices(pricesPath)
+	if err != nil {
+		ui.Outln()
+		ui.Outf("%s %v\n", color.YellowString("Cost estimate unavailable:"), err)
+		return
+	}
+	rolls := nodegroupRolls(plan)
+	names := make([]string, 0, len(rolls))
+	for n := range rolls {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	svc := cost.NewService(api, region, prices)
+	cc := svc.EstimateCluster(ctx, plan.ClusterName, cost.EstimateOptions{Nodegroups: names, SkipSurge: len(names) == 0})
+
+	ui.Outln()
+	ui.Outf("%s %s\n", color.New(color.Bold).Sprint("Cost estimate"), color.HiBlackString("(prices: %s)", source))
+	s := cc.Support
+	switch {
+	case s.ProjectedPremiumUSD > 0:
+		ui.Outf("  Extended-support premium if %s stays on %s: %s until %s\n",
+			plan.ClusterName, plan.CurrentVersion, color.YellowString("$%.2f", s.ProjectedPremiumUSD), s.ExtendedUntil.Format("2006-01-02"))
+		ui.Outf("  Upgrading to %s now saves: %s\n", s.NextVersion, color.GreenString("$%.2f", s.SavingsUSD))
+	case s.ExtendedUntil != nil:
+		ui.Outf("  No extended-support premium ahead for %s\n", plan.CurrentVersion)
+	default:
+		ui.Outf("  Extended-support premium: unknown (no support dates for %s)\n", plan.CurrentVersion)
+	}
+
+	var total float64
+	for _, sc := range cc.Surge {
+		n := rolls[sc.Nodegroup]
+		if !sc.PriceKnown {
+			ui.Outf("  Roll surge %s: %d × %s for ~%dm × %d roll(s) — %s\n",
+				sc.Nodegroup, sc.SurgeNodes, sc.InstanceType, sc.DurationMinutes, n, color.HiBlackString("no price for %s", sc.InstanceType))
+			continue
+		}
+		usd := sc.EstimatedUSD * float64(n)
+		total += usd
+		ui.Outf("  Roll surge %s: %d × %s for ~%dm × %d roll(s) = $%.2f\n",
+			sc.Nodegroup, sc.SurgeNodes, sc.InstanceType, sc.DurationMinutes, n, usd)
+	}
+	if len(cc.Surge) > 0 {
+		ui.Outf("  Total roll surge: %s\n", color.CyanString("$%.2f", total))
+	}
+	for _, e := range cc.Errors {
+		ui.Outf("  %s %s\n", color.YellowString("▸ warning:"), e)
+	}
+}
@@ -0,0 +1,25 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/dantech2000/refresh/internal/services/upgrade"
+)
+
+func TestNodegroupRolls(t *testing.T) {
+	plan := &upgrade.Plan{Hops: []upgrade.Hop{
+		{From: "1.31", To: "1.32", Steps: []upgrade.Step{
+			{Type: upgrade.StepControlPlane, Status: upgrade.StatusPending},
+			{Type: upgrade.StepNodegroup, Target: "ng-a", Status: upgrade.StatusPending},
+			{Type: upgrade.StepNodegroup, Target: "ng-b", Status: upgrade.StatusCompleted},
+		}},
+		{From: "1.32", To: "1.33", Steps: []upgrade.Step{
+			{Type: upgrade.StepNodegroup, Target: "ng-a", Status: upgrade.StatusPending},
+			{Type: upgrade.StepNodegroup, Target: "ng-b", Status: upgrade.StatusPending},
+		}},
+	}}
+	got := nodegroupRolls(plan)
+	if got["ng-a"] != 2 || got["ng-b"] != 1 || len(got) != 2 {
+		t.Errorf("nodegroupRolls = %v, want ng-a:2 ng-b:1", got)
+	}
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
@@ -33,11 +34,22 @@ that catalog evolves — so list the live set for your cluster with --show-passi
 then drill into any with --id, which accepts the short ID shown in the table, the
 full ID, or a case-insensitive name substring (e.g. --id "deprecated").
 
+With -A/--all-regions it checks the whole fleet instead: every cluster in
+every EKS region (narrow with repeated --region, a name pattern, -l/--selector
+or --group; add --account-role/--org-role for several accounts) against --to,
+or each cluster's next minor version without it. The result is a matrix of
+cluster × blocking insights, nodegroups past the kubelet skew limit and addons
+with no version for the target, plus each insight grouped across the clusters
+it affects. It exits 3 if any cluster is blocked, 2 if any needs review or
+couldn't be checked, else 0.
+
 Examples:
    refresh cluster upgrade-check -c prod-east
    refresh cluster upgrade-check -c prod-east --show-passing -o json
-   refresh cluster upgrade-check -c prod-east --id "deprecated"   # detail view (by name)`,
-		Flags: []cli.Flag{
+   refresh cluster upgrade-check -c prod-east --id "deprecated"   # detail view (by name)
+   refresh cluster upgrade-check -A --to 1.33
+   refresh cluster upgrade-check -A --to 1.33 -l env=prod -o json`,
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or pattern"},
 			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout (e.g. 60s, 2m)", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
 			&cli.StringFlag{Name: "category", Usage: "Insight category (UPGRADE_READINESS, MISCONFIGURATION)", Value: "UPGRADE_READINESS"},
@@ -45,8 +57,17 @@ Examples:
 			&cli.BoolFlag{Name: "show-passing", Usage: "Include PASSING insights (hidden by default)"},
 			&cli.StringFlag{Name: "id", Usage: "Show the detail view for one insight — accepts its ID, a short ID prefix (as shown in the table), or a name substring"},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Check every cluster in every EKS region (the fleet matrix)"},
+			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "With -A, only these region(s) (repeatable); otherwise the cluster's region"},
+			&cli.StringFlag{Name: "to", Usage: "With -A, the Kubernetes version to check against (e.g. 1.33); default each cluster's next minor"},
+			&cli.IntFlag{Name: "max-concurrency", Aliases: []string{"C"}, Usage: "Max concurrent region requests", Value: appconfig.DefaultMaxConcurrency, Sources: cli.EnvVars("REFRESH_MAX_CONCURRENCY")},
+		}, append(runner.SelectorFlags(), runner.AccountFlags()...)...),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Bool("all-regions") {
+				return runFleetUpgradeCheck(ctx, cmd)
+			}
+			return runUpgradeCheck(ctx, cmd)
 		},
-		Action: func(ctx context.Context, cmd *cli.Command) error { return runUpgradeCheck(ctx, cmd) },
 	}
 }
 
@@ -54,6 +75,11 @@ func runUpgradeCheck(ctx context.Context, cmd *cli.Command) error {
 	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
 		return err
 	}
+	for _, f := range []string{"to", "selector", "group"} {
+		if cmd.IsSet(f) {
+			return fmt.Errorf("--%s needs -A/--all-regions (the fleet check)", f)
+		}
+	}
 	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
 	if err != nil {
 		return err
@@ -112,8 +138,16 @@ func runUpgradeCheck(ctx context.Context, cmd *cli.Command) error {
 
 	// Control-plane health gate from the free AWS/EKS CloudWatch metrics — etcd
 	// usage vs the 8 GiB read-only limit + API-server error rate (REF-140).
+	// The etcd/API-server limits honor refresh.yaml health.thresholds.
 	if report != nil {
+		hopts, herr := runner.HealthOptions(cmd, clusterName)
+		if herr != nil {
+			return herr
+		}
 		checker := health.NewChecker(eks.NewFromConfig(awsCfg), nil, cloudwatch.NewFromConfig(awsCfg), nil)
+		if herr := checker.SetOptions(hopts); herr != nil {
+			return herr
+		}
 		cp := checker.CheckControlPlaneMetrics(ctx, clusterName)
 		report.ControlPlane = &cp
 	}
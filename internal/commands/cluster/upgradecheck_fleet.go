@@ -0,0 +1,140 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/commands/clusterview"
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+// runFleetUpgradeCheck is `cluster upgrade-check -A`: the readiness matrix
+// across every account, region and matching cluster.
+func runFleetUpgradeCheck(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	if cmd.String("id") != "" {
+		return fmt.Errorf("--id needs a single cluster; drop -A")
+	}
+	target := strings.TrimPrefix(strings.TrimSpace(cmd.String("to")), "v")
+	if target != "" && !validMinorVersion(target) {
+		return fmt.Errorf("--to %q: want a Kubernetes minor version like 1.33", cmd.String("to"))
+	}
+	sel, err := runner.ResolveSelector(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	accts, acctErrs, err := runner.ResolveAccounts(ctx, cmd, awsCfg)
+	if err != nil {
+		return err
+	}
+	var warnings []string
+	for _, e := range acctErrs {
+		warnings = append(warnings, e.Error())
+	}
+	if len(accts) == 0 {
+		return fmt.Errorf("no account in the inventory could be assumed")
+	}
+
+	pattern := strings.TrimSpace(cmd.String("cluster"))
+	if pattern == "" {
+		pattern = strings.TrimSpace(cmd.Args().First())
+	}
+	opts := clustersvc.FleetReadinessOptions{
+		Target:      target,
+		NamePattern: pattern,
+		Selector:    sel,
+	}
+	regions := runner.FleetRegions(cmd, awsCfg)
+
+	// One bounded pool over every account × region, as status and cost use.
+	var rows []clustersvc.ClusterReadiness
+	var errs []error
+	if werr := runner.WithSpinner("cluster", "Fleet upgrade readiness computed!", func() error {
+		rows, errs = status.FanOut(ctx, accts, regions, cmd.Int("max-concurrency"),
+			func(ctx context.Context, a accounts.Account, cfg aws.Config) ([]clustersvc.ClusterReadiness, error) {
+				found, err := factory.NewClusterService(cfg, false, nil).RegionReadiness(ctx, opts)
+				for i := range found {
+					found[i].Account = a.Label()
+				}
+				return found, err
+			})
+		if len(errs) > 0 && len(errs) == len(accts)*len(regions) {
+			return fmt.Errorf("listing clusters failed in every account and region (e.g. %w)", errs[0])
+		}
+		return nil
+	}); werr != nil {
+		return werr
+	}
+	for _, e := range errs {
+		warnings = append(warnings, e.Error())
+	}
+	// A single account is the common case; its label would only add a
+	// redundant ACCOUNT column.
+	if len(accts) == 1 {
+		for i := range rows {
+			rows[i].Account = ""
+		}
+		for i, w := range warnings {
+			warnings[i] = strings.TrimPrefix(w, "account "+accts[0].Label()+" ")
+		}
+	}
+
+	report := clustersvc.NewFleetReadinessReport(target, rows, warnings)
+	if handled, encErr := runner.EncodeStdout(cmd.String("format"), report); handled {
+		if encErr != nil {
+			return encErr
+		}
+		return exitForReadiness(report)
+	}
+	for _, w := range report.Warnings {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %s", w))
+	}
+	if err := clusterview.OutputFleetReadiness(report); err != nil {
+		return err
+	}
+	return exitForReadiness(report)
+}
+
+// validMinorVersion accepts "1.N".
+func validMinorVersion(v string) bool {
+	major, minor, ok := strings.Cut(v, ".")
+	if !ok || major != "1" || minor == "" {
+		return false
+	}
+	for _, c := range minor {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// exitForReadiness maps the fleet verdict to the documented exit codes: 3 if
+// any cluster is blocked, 2 if any needs review or couldn't be checked.
+func exitForReadiness(r *clustersvc.FleetReadinessReport) error {
+	switch {
+	case r.Counts.Blocked > 0:
+		return cli.Exit("", 3)
+	case r.Counts.Warning > 0 || r.Counts.Error > 0 || len(r.Warnings) > 0:
+		return cli.Exit("", 2)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+)
+
+func TestExitForReadiness(t *testing.T) {
+	cases := []struct {
+		counts   clustersvc.ReadinessCounts
+		warnings []string
+		want     int
+	}{
+		{clustersvc.ReadinessCounts{Ready: 3, Current: 1}, nil, 0},
+		{clustersvc.ReadinessCounts{Ready: 3, Warning: 1}, nil, 2},
+		{clustersvc.ReadinessCounts{Ready: 3, Error: 1}, nil, 2},
+		{clustersvc.ReadinessCounts{Ready: 3}, []string{"region ap-east-1: AccessDenied"}, 2},
+		{clustersvc.ReadinessCounts{Blocked: 1, Warning: 2}, nil, 3},
+	}
+	for _, c := range cases {
+		err := exitForReadiness(&clustersvc.FleetReadinessReport{Counts: c.counts, Warnings: c.warnings})
+		got := 0
+		var ec cli.ExitCoder
+		if errors.As(err, &ec) {
+			got = ec.ExitCode()
+		}
+		if got != c.want {
+			t.Errorf("%+v: exit %d, want %d", c.counts, got, c.want)
+		}
+	}
+}
+
+func TestValidMinorVersion(t *testing.T) {
+	for v, want := range map[string]bool{"1.33": true, "1.3": true, "1.33.1": false, "2.0": false, "1.": false, "latest": false} {
+		if got := validMinorVersion(v); got != want {
+			t.Errorf("validMinorVersion(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package clusterview
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dantech2000/refresh/internal/render"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// maxGroupClusters caps how many cluster refs an insight group lists inline
+// before folding the rest into "+N more".
+const maxGroupClusters = 4
+
+// OutputFleetReadiness renders the `cluster upgrade-check -A` matrix.
+func OutputFleetReadiness(report *clustersvc.FleetReadinessReport) error {
+	if !ui.PlainOutput() {
+		th := render.Default(os.Stdout)
+		for _, line := range fleetReadinessLines(th, report) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+	withAccount := readinessHasAccounts(report.Clusters)
+	cols := []ui.Column{{Title: "CLUSTER"}}
+	if withAccount {
+		cols = append(cols, ui.Column{Title: "ACCOUNT"})
+	}
+	cols = append(cols,
+		ui.Column{Title: "REGION"}, ui.Column{Title: "VERSION"}, ui.Column{Title: "TARGET"}, ui.Column{Title: "STATE"},
+		ui.Column{Title: "BLOCKING"}, ui.Column{Title: "SKEW"}, ui.Column{Title: "ADDONS"}, ui.Column{Title: "WARNINGS"},
+	)
+	tbl := ui.NewPTable(cols)
+	for _, c := range report.Clusters {
+		row := []string{c.Cluster}
+		if withAccount {
+			row = append(row, c.Account)
+		}
+		row = append(row, c.Region, valueOrDash(c.Version), valueOrDash(c.Target), string(c.State),
+			strconv.Itoa(len(c.Blocking)), strconv.Itoa(len(c.SkewViolations)), strconv.Itoa(len(c.IncompatibleAddons)), strconv.Itoa(len(c.Warnings)))
+		tbl.AddRow(row...)
+	}
+	tbl.Render()
+	return nil
+}
+
+func readinessHasAccounts(rows []clustersvc.ClusterReadiness) bool {
+	for _, r := range rows {
+		if r.Account != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func readinessToken(th *render.Theme, s clustersvc.ReadinessState) string {
+	switch s {
+	case clustersvc.ReadinessBlocked:
+		return th.Tokenf(render.Fail, "BLOCKED")
+	case clustersvc.ReadinessWarning:
+		return th.Tokenf(render.Warn, "REVIEW")
+	case clustersvc.ReadinessReady:
+		return th.Tokenf(render.Healthy, "READY")
+	case clustersvc.ReadinessCurrent:
+		return th.Token(render.Neutral, "AT TARGET")
+	default:
+		return th.Tokenf(render.Unknown, "UNCHECKED")
+	}
+}
+
+// countCell renders a matrix count: red when blocking, dim dash for zero.
+func countCell(th *render.Theme, n int, st render.Status) string {
+	if n == 0 {
+		return th.Paint(th.Pal.Dim, "-")
+	}
+	return th.Token(st, strconv.Itoa(n))
+}
+
+// fleetReadinessLines builds the human fleet upgrade-readiness view (pure,
+// golden-testable): a cluster × blocker matrix, the insights grouped across
+// clusters, then per-cluster skew, addon and error details.
+func fleetReadinessLines(th *render.Theme, report *clustersvc.FleetReadinessReport) []string {
+	pal := th.Pal
+	head := th.Bold(pal.White, "FLEET UPGRADE READINESS")
+	if report.Target != "" {
+		head += th.Paint(pal.Dim, "  → "+report.Target)
+	} else {
+		head += th.Paint(pal.Dim, "  → next minor")
+	}
+	c := report.Counts
+	out := []string{
+		head,
+		th.Paint(pal.Dim, fmt.Sprintf("%d clusters  ", len(report.Clusters))) + strings.Join([]string{
+			th.Tokenf(render.Fail, fmt.Sprintf("%d blocked", c.Blocked)),
+			th.Tokenf(render.Warn, fmt.Sprintf("%d review", c.Warning)),
+			th.Tokenf(render.Healthy, fmt.Sprintf("%d ready", c.Ready)),
+			th.Token(render.Neutral, fmt.Sprintf("%d at target", c.Current)),
+			th.Token(render.Unknown, fmt.Sprintf("%d unchecked", c.Error)),
+		}, "  "),
+		"",
+	}
+	if len(report.Clusters) == 0 {
+		return append(out, "  "+th.Paint(pal.Dim, "no clusters matched"))
+	}
+
+	withAccount := readinessHasAccounts(report.Clusters)
+	cols := []ui.Column{{Title: "CLUSTER", Min: 14}}
+	if withAccount {
+		cols = append(cols, ui.Column{Title: "ACCOUNT", Min: 7, Max: 24})
+	}
+	cols = append(cols,
+		ui.Column{Title: "REGION", Min: 10},
+		ui.Column{Title: "VERSION", Min: 7},
+		ui.Column{Title: "HOPS", Min: 4, Align: ui.AlignRight},
+		ui.Column{Title: "STATE", Min: 10},
+		ui.Column{Title: "BLOCKING", Min: 8, Align: ui.AlignRight},
+		ui.Column{Title: "SKEW", Min: 4, Align: ui.AlignRight},
+		ui.Column{Title: "ADDONS", Min: 6, Align: ui.AlignRight},
+		ui.Column{Title: "WARNINGS", Min: 8, Align: ui.AlignRight},
+	)
+	tbl := th.NewTable(cols...)
+	for _, r := range report.Clusters {
+		row := []string{th.Paint(pal.White, r.Cluster)}
+		if withAccount {
+			row = append(row, th.Paint(pal.Teal, r.Account))
+		}
+		version := valueOrDash(r.Version)
+		if r.Target != "" && r.State != clustersvc.ReadinessCurrent && r.State != clustersvc.ReadinessError {
+			version += " → " + r.Target
+		}
+		row = append(row,
+			th.Paint(pal.Dim, r.Region),
+			th.Paint(pal.White, version),
+			th.Paint(pal.Text, strconv.Itoa(r.Hops)),
+			readinessToken(th, r.State),
+			countCell(th, len(r.Blocking), render.Fail),
+			countCell(th, len(r.SkewViolations), render.Fail),
+			countCell(th, len(r.IncompatibleAddons), render.Fail),
+			countCell(th, len(r.Warnings), render.Warn),
+		)
+		tbl.Row(row...)
+	}
+	out = append(out, tbl.Render()...)
+
+	if len(report.Insights) > 0 {
+		out = append(out, "", th.Section("INSIGHTS")+th.Paint(pal.Dim, "  grouped across clusters"))
+		it := th.NewTable(
+			ui.Column{Title: "STATUS", Min: 9},
+			ui.Column{Title: "INSIGHT", Min: 20, Max: 48},
+			ui.Column{Title: "CLUSTERS", Min: 8, Align: ui.AlignRight},
+			ui.Column{Title: "AFFECTED", Min: 12},
+		)
+		for _, g := range report.Insights {
+			it.Row(
+				insightToken(th, g.Status),
+				th.Paint(pal.White, g.Name),
+				th.Paint(pal.Text, strconv.Itoa(len(g.Clusters))),
+				th.Paint(pal.Dim, foldRefs(g.Clusters)),
+			)
+		}
+		for _, l := range it.Render() {
+			out = append(out, "  "+l)
+		}
+	}
+
+	var details []string
+	for _, r := range report.Clusters {
+		for _, ng := range r.SkewViolations {
+			details = append(details, th.Token(render.Fail, fmt.Sprintf("%s: nodegroup %s (%s) would trail %s by %d minors — roll it before the control plane gets there", r.Ref(), ng.Name, ng.Version, r.Target, ng.MinorsBehind)))
+		}
+		for _, a := range r.IncompatibleAddons {
+			details = append(details, th.Token(render.Fail, fmt.Sprintf("%s: addon %s %s has no version published for %s", r.Ref(), a.Name, a.Installed, r.Target)))
+		}
+		for _, n := range r.Notes {
+			details = append(details, th.Token(render.Warn, fmt.Sprintf("%s: %s", r.Ref(), n)))
+		}
+		if r.Error != "" {
+			details = append(details, th.Token(render.Unknown, fmt.Sprintf("%s: %s", r.Ref(), r.Error)))
+		}
+	}
+	for _, w := range report.Warnings {
+		details = append(details, th.Token(render.Warn, w))
+	}
+	if len(details) > 0 {
+		out = append(out, "", th.Section("DETAILS"))
+		for _, d := range details {
+			out = append(out, "  "+d)
+		}
+	}
+	out = append(out, "", th.Paint(pal.Dim, "drill into one: cluster upgrade-check -c <cluster> -r <region>"))
+	return out
+}
+
+// foldRefs lists the first few cluster refs and counts the rest.
+func foldRefs(refs []string) string {
+	if len(refs) <= maxGroupClusters {
+		return strings.Join(refs, ", ")
+	}
+	return fmt.Sprintf("%s, +%d more", strings.Join(refs[:maxGroupClusters], ", "), len(refs)-maxGroupClusters)
+}
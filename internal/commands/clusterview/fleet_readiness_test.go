@@ -0,0 +1,47 @@
+package clusterview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/refresh/internal/render"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+)
+
+func TestFleetReadinessLines(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	rows := []clustersvc.ClusterReadiness{
+		{
+			Cluster: "web", Region: "us-east-1", Version: "1.31", Target: "1.33", Hops: 2, State: clustersvc.ReadinessBlocked,
+			Blocking:           []clustersvc.InsightSummary{{Name: "Deprecated APIs", Status: clustersvc.InsightStatusError}},
+			SkewViolations:     []clustersvc.NodegroupSkew{{Name: "old", Version: "1.29", MinorsBehind: 4, Blocking: true}},
+			IncompatibleAddons: []clustersvc.AddonSkew{{Name: "vpc-cni", Installed: "v1.18.0"}},
+		},
+		{Cluster: "api", Region: "eu-west-1", Version: "1.33", Target: "1.33", State: clustersvc.ReadinessCurrent},
+	}
+	got := strings.Join(fleetReadinessLines(th, clustersvc.NewFleetReadinessReport("1.33", rows, []string{"region ap-east-1: AccessDenied"})), "\n")
+	for _, want := range []string{
+		"FLEET UPGRADE READINESS  → 1.33",
+		"1 blocked",
+		"1.31 → 1.33",
+		"BLOCKED",
+		"AT TARGET",
+		"Deprecated APIs",
+		"us-east-1/web: nodegroup old (1.29) would trail 1.33 by 4 minors",
+		"us-east-1/web: addon vpc-cni v1.18.0 has no version published for 1.33",
+		"region ap-east-1: AccessDenied",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ACCOUNT") {
+		t.Error("ACCOUNT column shown for a single-account fleet")
+	}
+}
+
+func TestFoldRefs(t *testing.T) {
+	if got := foldRefs([]string{"a", "b", "c", "d", "e", "f"}); got != "a, b, c, d, +2 more" {
+		t.Errorf("foldRefs = %q", got)
+	}
+}
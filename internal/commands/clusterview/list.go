@@ -47,7 +47,11 @@ func outputClustersPlain(summaries []clustersvc.ClusterSummary, elapsed time.Dur
 	}
 	ui.PrintElapsed(elapsed)
 
+	withAccount := hasAccounts(summaries)
 	cols := []ui.Column{{Title: "CLUSTER", Min: 14, Align: ui.AlignLeft}}
+	if withAccount {
+		cols = append(cols, ui.Column{Title: "ACCOUNT", Min: 7, Max: 24, Align: ui.AlignLeft})
+	}
 	if multiRegion {
 		cols = append(cols, ui.Column{Title: "REGION", Min: 10, Align: ui.AlignLeft})
 	}
@@ -63,6 +67,9 @@ func outputClustersPlain(summaries []clustersvc.ClusterSummary, elapsed time.Dur
 	tbl := ui.NewPTable(cols, ui.CyanHeaders())
 	for _, s := range summaries {
 		row := []string{s.Name}
+		if withAccount {
+			row = append(row, s.Account)
+		}
 		if multiRegion {
 			row = append(row, s.Region)
 		}
@@ -182,6 +189,17 @@ func OutputClustersTree(summaries []clustersvc.ClusterSummary, elapsed time.Dura
 	return nil
 }
 
+// hasAccounts reports whether the summaries came from a multi-account sweep,
+// which is when the ACCOUNT column is shown.
+func hasAccounts(summaries []clustersvc.ClusterSummary) bool {
+	for _, s := range summaries {
+		if s.Account != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // SortClusterSummaries sorts items in place by key and returns the slice.
 func SortClusterSummaries(items []clustersvc.ClusterSummary, key string, desc bool) []clustersvc.ClusterSummary {
 	var less func(i, j int) bool
@@ -192,6 +210,8 @@ func SortClusterSummaries(items []clustersvc.ClusterSummary, key string, desc bo
 		less = func(i, j int) bool { return items[i].Version < items[j].Version }
 	case "region":
 		less = func(i, j int) bool { return items[i].Region < items[j].Region }
+	case "account":
+		less = func(i, j int) bool { return items[i].Account < items[j].Account }
 	default:
 		less = func(i, j int) bool { return items[i].Name < items[j].Name }
 	}
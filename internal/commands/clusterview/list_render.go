@@ -27,7 +27,11 @@ func clusterListLines(th *render.Theme, summaries []clustersvc.ClusterSummary, m
 	}
 	out := []string{head, ""}
 
+	withAccount := hasAccounts(summaries)
 	cols := []ui.Column{{Title: "CLUSTER", Min: 14}}
+	if withAccount {
+		cols = append(cols, ui.Column{Title: "ACCOUNT", Min: 7, Max: 24})
+	}
 	if multiRegion {
 		cols = append(cols, ui.Column{Title: "REGION", Min: 10})
 	}
@@ -43,6 +47,9 @@ func clusterListLines(th *render.Theme, summaries []clustersvc.ClusterSummary, m
 	tbl := th.NewTable(cols...)
 	for _, s := range summaries {
 		row := []string{th.Paint(pal.White, s.Name)}
+		if withAccount {
+			row = append(row, th.Paint(pal.Teal, s.Account))
+		}
 		if multiRegion {
 			row = append(row, th.Paint(pal.Dim, s.Region))
 		}
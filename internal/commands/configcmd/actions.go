@@ -0,0 +1,277 @@
+package configcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// Sources shown for values that don't come from refresh.yaml.
+const (
+	sourceDefault = "default"
+	sourceUnset   = "-"
+)
+
+// settingRow is one effective value in `config view --effective`.
+type settingRow struct {
+	Key    string `json:"key" yaml:"key"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+}
+
+// effectiveView is the `config view --effective` payload.
+type effectiveView struct {
+	Command  string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Cluster  string            `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Context  string            `json:"context,omitempty" yaml:"context,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Layers   []string          `json:"layers" yaml:"layers"`
+	Settings []settingRow      `json:"settings" yaml:"settings"`
+}
+
+func runView(_ context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	if err := runner.ValidateFormat(format, runner.FormatsStandard); err != nil {
+		return err
+	}
+	layers, err := cliconfig.LoadLayers()
+	if err != nil {
+		return err
+	}
+	if !cmd.Bool("effective") {
+		return outputLayers(format, layers)
+	}
+
+	target, err := viewTarget(cmd)
+	if err != nil {
+		return err
+	}
+	view := effectiveView{Command: target.Command, Cluster: target.Cluster, Context: target.Context, Tags: target.Tags, Layers: layerPaths(layers)}
+	eff := cliconfig.Resolve(layers, target)
+	if target.Command != "" {
+		sub, err := findCommand(cmd.Root(), target.Command)
+		if err != nil {
+			return err
+		}
+		view.Settings = flagRows(sub, eff)
+	}
+	view.Settings = append(view.Settings, settingRows(eff, target.Command != "")...)
+	return outputEffective(format, view)
+}
+
+// viewTarget builds the resolution target from --command/-c/--context/--tag,
+// falling back to the active context (and its cluster).
+func viewTarget(cmd *cli.Command) (cliconfig.Target, error) {
+	t := cliconfig.Target{
+		Command: strings.Join(strings.Fields(cmd.String("command")), " "),
+		Cluster: strings.TrimSpace(cmd.String("cluster")),
+		Context: strings.TrimSpace(cmd.String("context")),
+	}
+	for _, kv := range cmd.StringSlice("tag") {
+		k, v, ok := strings.Cut(kv, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			return t, fmt.Errorf("--tag %q: want key=value", kv)
+		}
+		if t.Tags == nil {
+			t.Tags = map[string]string{}
+		}
+		t.Tags[k] = strings.TrimSpace(v)
+	}
+	f, err := cliconfig.Load()
+	if err != nil {
+		return t, err
+	}
+	if t.Context == "" {
+		if name, _, ok := f.Active(); ok {
+			t.Context = name
+		}
+	}
+	if t.Cluster == "" {
+		if c, ok := f.Contexts[t.Context]; ok {
+			t.Cluster = c.Cluster
+		}
+	}
+	return t, nil
+}
+
+// findCommand walks the command tree by path ("nodegroup update"), accepting
+// aliases at every level.
+func findCommand(root *cli.Command, path string) (*cli.Command, error) {
+	cur := root
+	for _, name := range strings.Fields(path) {
+		next := cur.Command(name)
+		if next == nil {
+			return nil, fmt.Errorf("unknown command %q", path)
+		}
+		cur = next
+	}
+	if cur == root {
+		return nil, fmt.Errorf("--command needs a command path, e.g. \"nodegroup update\"")
+	}
+	return cur, nil
+}
+
+// flagRows lists every flag sub accepts (its own, then inherited globals) with
+// the value it would get without a command-line flag: env var, refresh.yaml,
+// or the built-in default, in that order.
+func flagRows(sub *cli.Command, eff cliconfig.Effective) []settingRow {
+	settings := eff.Flags()
+	seen := map[string]bool{}
+	var rows []settingRow
+	for _, c := range sub.Lineage() {
+		for _, f := range c.Flags {
+			name := f.Names()[0]
+			if seen[name] || name == "help" || name == "version" {
+				continue
+			}
+			seen[name] = true
+			doc, ok := f.(cli.DocGenerationFlag)
+			if !ok {
+				continue
+			}
+			if v, ok := f.(cli.VisibleFlag); ok && !v.IsVisible() {
+				continue
+			}
+			def := doc.GetDefaultText()
+			if def == "" {
+				def = strings.Trim(doc.GetValue(), `"`)
+			}
+			row := settingRow{Key: "--" + name, Value: def, Source: sourceDefault}
+			if row.Value == "" {
+				row.Source = sourceUnset
+			}
+			if v, ok := settings[name]; ok {
+				row.Value, row.Source = strings.Join(cliconfig.FlagStrings(v.Value), ","), v.Source
+			}
+			for _, env := range doc.GetEnvVars() {
+				if val, set := os.LookupEnv(env); set {
+					row.Value, row.Source = val, "env "+env
+					break
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// settingRows flattens the effective settings. With a command targeted its
+// flag values were already listed by flagRows, so only the non-flag blocks
+// (skip lists, health, canary) are added here.
+func settingRows(eff cliconfig.Effective, skipFlags bool) []settingRow {
+	var rows []settingRow
+	for _, k := range eff.Keys() {
+		if skipFlags && strings.HasPrefix(k, "flag.") {
+			continue
+		}
+		v := eff[k]
+		rows = append(rows, settingRow{Key: k, Value: strings.Join(cliconfig.FlagStrings(v.Value), ","), Source: v.Source})
+	}
+	return rows
+}
+
+func layerPaths(layers []cliconfig.Layer) []string {
+	paths := make([]string, 0, len(layers))
+	for _, l := range layers {
+		paths = append(paths, l.Path)
+	}
+	return paths
+}
+
+func outputEffective(format string, view effectiveView) error {
+	if handled, err := runner.EncodeStdout(format, view); handled {
+		return err
+	}
+	if len(view.Layers) == 0 {
+		fmt.Printf("No %s found — built-in defaults only.\n", cliconfig.SettingsFileName)
+		if len(view.Settings) == 0 {
+			return nil
+		}
+		fmt.Println()
+	}
+	columns := []ui.Column{
+		{Title: "KEY", Min: 10},
+		{Title: "VALUE", Min: 8, Max: 48},
+		{Title: "SOURCE", Min: 8, Max: 72},
+	}
+	if ui.PlainOutput() {
+		table := ui.NewPTable(columns, ui.CyanHeaders())
+		for _, r := range view.Settings {
+			table.AddRow(r.Key, r.Value, r.Source)
+		}
+		table.Render()
+		return nil
+	}
+	th := render.Default(os.Stdout)
+	tbl := th.NewTable(columns...)
+	for _, r := range view.Settings {
+		src := th.Paint(th.Pal.Dim, r.Source)
+		if r.Source != sourceDefault && r.Source != sourceUnset {
+			src = th.Paint(th.Pal.Teal, r.Source)
+		}
+		tbl.Row(th.Paint(th.Pal.White, r.Key), r.Value, src)
+	}
+	for _, line := range tbl.Render() {
+		fmt.Println(line)
+	}
+	var scope []string
+	if view.Command != "" {
+		scope = append(scope, "command "+view.Command)
+	}
+	if view.Cluster != "" {
+		scope = append(scope, "cluster "+view.Cluster)
+	}
+	if view.Context != "" {
+		scope = append(scope, "context "+view.Context)
+	}
+	for _, k := range sortedTagKeys(view.Tags) {
+		scope = append(scope, "tag "+k+"="+view.Tags[k])
+	}
+	if len(scope) > 0 {
+		fmt.Printf("\nResolved for %s\n", strings.Join(scope, ", "))
+	}
+	return nil
+}
+
+// outputLayers prints each refresh.yaml found, highest precedence last.
+func outputLayers(format string, layers []cliconfig.Layer) error {
+	if handled, err := runner.EncodeStdout(format, map[string]any{"layers": layers}); handled {
+		return err
+	}
+	if len(layers) == 0 {
+		user, _ := cliconfig.UserSettingsPath()
+		fmt.Printf("No %s found. Create one in the repo or at %s.\n", cliconfig.SettingsFileName, user)
+		return nil
+	}
+	for i, l := range layers {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s (%s)\n", l.Path, l.Name)
+		b, err := yaml.Marshal(l.Settings)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	}
+	return nil
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
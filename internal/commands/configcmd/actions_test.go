@@ -0,0 +1,77 @@
+package configcmd
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+func testTree() *cli.Command {
+	return &cli.Command{
+		Name:  "refresh",
+		Flags: []cli.Flag{&cli.StringFlag{Name: "log-level", Value: "warn"}},
+		Commands: []*cli.Command{{
+			Name: "nodegroup",
+			Commands: []*cli.Command{{
+				Name:    "update",
+				Aliases: []string{"update-ami"},
+				Flags: []cli.Flag{
+					&cli.DurationFlag{Name: "poll-interval", Value: 15 * time.Second},
+					&cli.DurationFlag{Name: "timeout", Value: 40 * time.Minute, Sources: cli.EnvVars("TEST_REFRESH_TIMEOUT")},
+					&cli.StringFlag{Name: "kubeconfig"},
+					&cli.BoolFlag{Name: "simulate", Hidden: true},
+				},
+			}},
+		}},
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	root := testTree()
+	sub, err := findCommand(root, "nodegroup  update-ami")
+	if err != nil || sub.Name != "update" {
+		t.Fatalf("findCommand = %v, %v", sub, err)
+	}
+	if _, err := findCommand(root, "nodegroup upgrade"); err == nil {
+		t.Error("unknown path should error")
+	}
+	if _, err := findCommand(root, ""); err == nil {
+		t.Error("empty path should error")
+	}
+}
+
+func TestFlagRows_Provenance(t *testing.T) {
+	t.Setenv("TEST_REFRESH_TIMEOUT", "5m")
+	root := testTree()
+	// Wire parents the way a real run does, so Lineage reaches the root flags.
+	sub, _ := findCommand(root, "nodegroup update")
+	root.Writer = io.Discard
+	_ = root.Run(t.Context(), []string{"refresh", "nodegroup", "update", "--help"})
+
+	eff := cliconfig.Effective{
+		"flag.poll-interval": {Value: "30s", Source: "repo/refresh.yaml"},
+		// The env var outranks refresh.yaml.
+		"flag.timeout": {Value: "1h", Source: "repo/refresh.yaml"},
+	}
+	rows := map[string]settingRow{}
+	for _, r := range flagRows(sub, eff) {
+		rows[r.Key] = r
+	}
+	check := func(key, value, source string) {
+		t.Helper()
+		if r := rows[key]; r.Value != value || r.Source != source {
+			t.Errorf("%s = %+v, want %s from %s", key, r, value, source)
+		}
+	}
+	check("--poll-interval", "30s", "repo/refresh.yaml")
+	check("--timeout", "5m", "env TEST_REFRESH_TIMEOUT")
+	check("--kubeconfig", "", sourceUnset)
+	check("--log-level", "warn", sourceDefault)
+	if _, ok := rows["--simulate"]; ok {
+		t.Error("hidden flags should not be listed")
+	}
+}
@@ -0,0 +1,57 @@
+// Package configcmd wires `refresh config`: inspection of the layered
+// refresh.yaml defaults (repo-local, user, per-cluster and per-context
+// scopes) that fill flags the user didn't set.
+package configcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Command returns the `refresh config` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Inspect layered refresh.yaml defaults (view)",
+		Description: `refresh.yaml supplies defaults for any command flag, named skip lists, health
+thresholds, canary settings and the status SLA policy. It is read from two
+files — the user copy in the refresh config directory and a repo-local
+refresh.yaml found by walking up from the working directory — and each file
+can scope values to clusters (clusters.<glob>), contexts (contexts.<name>)
+and, for status, EKS cluster tags (tags.<key=glob>).
+
+Precedence, highest first: flags > env vars > contexts.<name> >
+clusters.<glob> > tags.<key=glob> > repo-local file > user file > built-in
+defaults.
+
+  refresh config view                                   # the files that were found
+  refresh config view --effective                       # merged values and their source
+  refresh config view --effective --command "nodegroup update" -c prod-east`,
+		Commands: []*cli.Command{
+			viewCommand(),
+		},
+	}
+}
+
+func viewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "view",
+		Usage: "Show the refresh.yaml layers, or with --effective the merged result and where each value came from",
+		Description: `Without --effective, print each refresh.yaml that was found and its contents.
+
+With --effective, merge the layers for the active context (or --context) and
+cluster (or -c) and print every value with its source. Adding --command
+narrows the view to one command and lists all of its flags, so values still
+coming from an env var or the built-in default are shown too.`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "effective", Aliases: []string{"e"}, Usage: "Show the merged settings and the layer each value came from"},
+			&cli.StringFlag{Name: "command", Usage: "Resolve for one command path (e.g. \"nodegroup update\") and list all of its flags"},
+			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "Cluster to resolve clusters.<glob> scopes for (default: the active context's cluster)"},
+			&cli.StringFlag{Name: "context", Usage: "Context to resolve contexts.<name> scopes for (default: the active context)"},
+			&cli.StringSliceFlag{Name: "tag", Usage: "Cluster tag key=value to resolve tags.<key=glob> scopes for (repeatable)"},
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runView(ctx, cmd) },
+	}
+}
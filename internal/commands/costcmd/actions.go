@@ -0,0 +1,85 @@
+package costcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/services/cost"
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+func runCost(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	prices, source, err := cost.LoadPrices(cmd.String("prices"))
+	if err != nil {
+		return err
+	}
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	regions := runner.FleetRegions(cmd, awsCfg)
+	batch := cmd.Duration("batch-duration")
+	if batch <= 0 {
+		batch = cost.DefaultBatchDuration
+	}
+
+	var (
+		clusters   []cost.ClusterCost
+		regionErrs []error
+	)
+	gather := func() error {
+		clusters, regionErrs = gatherFleet(ctx, awsCfg, regions, prices, batch,
+			strings.TrimSpace(cmd.Args().First()), cmd.Int("max-concurrency"))
+		if len(clusters) == 0 && len(regionErrs) > 0 {
+			return regionErrs[0]
+		}
+		return nil
+	}
+	if err := runner.WithSpinner("cluster", "Cost estimate ready!", gather); err != nil {
+		return err
+	}
+	for _, e := range regionErrs {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %v", e))
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Name != clusters[j].Name {
+			return clusters[i].Name < clusters[j].Name
+		}
+		return clusters[i].Region < clusters[j].Region
+	})
+	report := cost.NewReport(clusters, source, batch)
+
+	if handled, err := runner.EncodeStdout(cmd.String("format"), report); handled {
+		return err
+	}
+	outputReport(report)
+	return nil
+}
+
+// gatherFleet estimates every matching cluster across regions through the
+// status fan-out, returning the merged rows and any per-region errors.
+func gatherFleet(ctx context.Context, baseCfg aws.Config, regions []string, prices cost.PriceTable, batch time.Duration, pattern string, maxConc int) ([]cost.ClusterCost, []error) {
+	return status.FanOut(ctx, []accounts.Account{{Config: baseCfg}}, regions, maxConc,
+		func(ctx context.Context, _ accounts.Account, cfg aws.Config) ([]cost.ClusterCost, error) {
+			svc := cost.NewService(eks.NewFromConfig(cfg), cfg.Region, prices)
+			svc.BatchDuration = batch
+			return svc.ListClusterCosts(ctx, pattern, maxConc, cost.EstimateOptions{})
+		})
+}
@@ -0,0 +1,49 @@
+// Package costcmd wires the top-level `refresh cost` command: what lagging
+// patch posture costs in extended-support premium, and what a nodegroup roll
+// costs in temporary EC2 surge.
+package costcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/services/cost"
+)
+
+// Command returns the `refresh cost` top-level command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "cost",
+		Usage:     "Estimate extended-support premium and nodegroup roll surge cost",
+		ArgsUsage: "[name-pattern]",
+		Description: `Project what each cluster's patch posture costs:
+
+  - Extended-support premium accrued from now until the version's end of
+    extended support if the cluster is never upgraded, and how much of it
+    upgrading to the next minor now would save.
+  - Temporary EC2 cost of rolling each managed nodegroup: EKS launches the
+    larger of 2×AZ count and maxUnavailable extra nodes and keeps them up
+    for ceil(nodes / maxUnavailable) batches.
+
+Prices come from a local table — built-in us-east-1 list prices overlaid by
+prices.yaml in the refresh config directory (or --prices). Estimates are
+list-price upper bounds; spot nodegroups are priced on-demand.
+
+Example prices.yaml:
+  eks:
+    extendedPremiumPerHour: 0.50
+  ec2:
+    m6i.large: 0.085`,
+		Flags: []cli.Flag{
+			// --region is a repeatable slice like `refresh status`, shadowing the
+			// global single-region override. (REF-47)
+			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Query all EKS-supported regions"},
+			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "Specific region(s) to query (repeatable)"},
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+			&cli.StringFlag{Name: "prices", Usage: "Price table YAML overriding the built-in prices (default: prices.yaml in the config dir)", Sources: cli.EnvVars("REFRESH_PRICES")},
+			&cli.DurationFlag{Name: "batch-duration", Usage: "Assumed time to replace one batch of nodes during a roll", Value: cost.DefaultBatchDuration},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runCost(ctx, cmd) },
+	}
+}
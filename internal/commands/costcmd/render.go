@@ -0,0 +1,178 @@
+package costcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/cost"
+	"github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+const dateLayout = "2006-01-02"
+
+// outputReport renders the human cost table, or the uncolored PTable under
+// `-o plain`.
+func outputReport(r cost.Report) {
+	if ui.PlainOutput() {
+		outputPlain(r)
+		return
+	}
+	th := render.Default(os.Stdout)
+	for _, line := range reportLines(th, r) {
+		fmt.Println(line)
+	}
+}
+
+// reportLines builds the human cost table as lines (pure, so it is
+// golden-testable).
+func reportLines(th *render.Theme, r cost.Report) []string {
+	pal := th.Pal
+	out := []string{
+		th.Bold(pal.Mauve, "COST") + "  " +
+			th.Paint(pal.White, fmt.Sprintf("%d clusters", len(r.Clusters))) +
+			th.Paint(pal.Dim, " · prices: "+r.PriceSource),
+		"",
+	}
+	tbl := th.NewTable(
+		ui.Column{Title: "", Min: 1},
+		ui.Column{Title: "CLUSTER", Min: 8},
+		ui.Column{Title: "REGION", Min: 6},
+		ui.Column{Title: "VERSION", Min: 7},
+		ui.Column{Title: "SUPPORT", Min: 10, Max: 40},
+		ui.Column{Title: "PREMIUM TO EOL", Min: 10},
+		ui.Column{Title: "SAVE IF UPGRADED NOW", Min: 10},
+		ui.Column{Title: "ROLL SURGE", Min: 10},
+	)
+	unpriced := false
+	for _, c := range r.Clusters {
+		surge := usd(c.SurgeTotalUSD)
+		if hasUnpriced(c) {
+			surge += "*"
+			unpriced = true
+		}
+		tbl.Row(
+			th.Glyph(rowStatus(c)),
+			th.Paint(pal.White, c.Name),
+			th.Paint(pal.Dim, c.Region),
+			th.Paint(pal.White, orUnknown(c.Version)),
+			supportPretty(th, c.Support),
+			premiumPretty(th, c.Support.ProjectedPremiumUSD),
+			premiumPretty(th, c.Support.SavingsUSD),
+			th.Paint(pal.Text, surge),
+		)
+	}
+	out = append(out, tbl.Render()...)
+	out = append(out, "", th.Paint(pal.Dim, footer(r)))
+	if unpriced {
+		out = append(out, th.Paint(pal.Dim, "* some instance types are not in the price table; add them to prices.yaml"))
+	}
+	return out
+}
+
+func outputPlain(r cost.Report) {
+	table := ui.NewPTable([]ui.Column{
+		{Title: "CLUSTER", Min: 8},
+		{Title: "REGION", Min: 9},
+		{Title: "VERSION", Min: 7},
+		{Title: "SUPPORT", Min: 10, Max: 40},
+		{Title: "PREMIUM TO EOL", Min: 14},
+		{Title: "SAVE IF UPGRADED NOW", Min: 20},
+		{Title: "ROLL SURGE", Min: 10},
+	}, ui.CyanHeaders())
+	for _, c := range r.Clusters {
+		surge := usd(c.SurgeTotalUSD)
+		if hasUnpriced(c) {
+			surge += "*"
+		}
+		table.AddRow(c.Name, c.Region, orUnknown(c.Version), supportText(c.Support),
+			usd(c.Support.ProjectedPremiumUSD), usd(c.Support.SavingsUSD), surge)
+	}
+	table.Render()
+	fmt.Println()
+	fmt.Println(footer(r))
+}
+
+func footer(r cost.Report) string {
+	return fmt.Sprintf("total premium to end of extended support %s · saved by upgrading now %s · roll surge %s (%dm/batch, list prices)",
+		usd(r.ProjectedPremiumUSD), usd(r.SavingsUSD), usd(r.SurgeUSD), r.BatchMinutes)
+}
+
+// rowStatus flags clusters already paying the premium (or past it) as a
+// failure, clusters that will pay it as a warning.
+func rowStatus(c cost.ClusterCost) render.Status {
+	switch {
+	case len(c.Errors) > 0 || c.Support.Tier == status.SupportUnsupported:
+		return render.Fail
+	case c.Support.PremiumUSDPerHour > 0:
+		return render.Warn
+	default:
+		return render.Healthy
+	}
+}
+
+func supportPretty(th *render.Theme, s cost.SupportCost) string {
+	txt := supportText(s)
+	switch s.Tier {
+	case status.SupportStandard:
+		return th.Paint(th.Pal.Green, txt)
+	case status.SupportExtended:
+		return th.Token(render.Warn, txt)
+	case status.SupportUnsupported:
+		return th.Token(render.Fail, txt)
+	default:
+		return th.Paint(th.Pal.Dim, txt)
+	}
+}
+
+func supportText(s cost.SupportCost) string {
+	star := ""
+	if s.Fallback {
+		star = "*"
+	}
+	switch s.Tier {
+	case status.SupportStandard:
+		if s.StandardUntil != nil {
+			return "standard until " + s.StandardUntil.Format(dateLayout) + star
+		}
+		return "standard" + star
+	case status.SupportExtended:
+		txt := fmt.Sprintf("extended +$%.2f/hr", s.PremiumUSDPerHour)
+		if s.ExtendedUntil != nil {
+			txt += " until " + s.ExtendedUntil.Format(dateLayout)
+		}
+		return txt + star
+	case status.SupportUnsupported:
+		return "unsupported"
+	default:
+		return "unknown"
+	}
+}
+
+func premiumPretty(th *render.Theme, v float64) string {
+	if v == 0 {
+		return th.Paint(th.Pal.Dim, usd(v))
+	}
+	return th.Paint(th.Pal.Peach, usd(v))
+}
+
+func hasUnpriced(c cost.ClusterCost) bool {
+	for _, s := range c.Surge {
+		if !s.PriceKnown && s.Nodes > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func usd(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
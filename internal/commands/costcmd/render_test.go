@@ -0,0 +1,60 @@
+package costcmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/cost"
+	"github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+func TestReportLines(t *testing.T) {
+	until := time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC)
+	r := cost.NewReport([]cost.ClusterCost{
+		{
+			Name: "legacy", Region: "us-east-1", Version: "1.29",
+			Support: cost.SupportCost{
+				Tier: status.SupportExtended, ExtendedUntil: &until, PremiumUSDPerHour: 0.5,
+				ProjectedPremiumUSD: 480, SavingsUSD: 480,
+			},
+			Surge:         []cost.SurgeCost{{Nodegroup: "ng", Nodes: 3, PriceKnown: false}},
+			SurgeTotalUSD: 0,
+		},
+		{
+			Name: "prod", Region: "us-west-2", Version: "1.33",
+			Support:       cost.SupportCost{Tier: status.SupportStandard},
+			SurgeTotalUSD: 1.25,
+		},
+	}, "built-in", cost.DefaultBatchDuration)
+
+	got := ui.StripANSI(strings.Join(reportLines(render.New(render.ColorNone, false), r), "\n"))
+	for _, want := range []string{
+		"2 clusters · prices: built-in",
+		"extended +$0.50/hr until 2026-11-26",
+		"$480.00",
+		"$1.25",
+		"$0.00*",
+		"total premium to end of extended support $480.00",
+		"roll surge $1.25 (10m/batch",
+		"not in the price table",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRowStatus(t *testing.T) {
+	if rowStatus(cost.ClusterCost{Support: cost.SupportCost{Tier: status.SupportStandard}}) != render.Healthy {
+		t.Error("standard cluster should be healthy")
+	}
+	if rowStatus(cost.ClusterCost{Support: cost.SupportCost{Tier: status.SupportExtended, PremiumUSDPerHour: 0.5}}) != render.Warn {
+		t.Error("extended cluster should warn")
+	}
+	if rowStatus(cost.ClusterCost{Errors: []string{"boom"}}) != render.Fail {
+		t.Error("errored row should fail")
+	}
+}
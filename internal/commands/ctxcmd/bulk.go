@@ -0,0 +1,452 @@
+package ctxcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// defaultNameTemplate names imported and synced contexts after their cluster.
+const defaultNameTemplate = "{cluster}"
+
+// nameTemplateUsage lists the placeholders a --name-template may use.
+const nameTemplateUsage = "Context name template: {cluster}, {region}, {account}, {account_name}, {profile}, and {context} (the kubeconfig context, for --from-kubeconfig)"
+
+// candidate is a context to merge, with the values its name is built from.
+type candidate struct {
+	vars map[string]string
+	ctx  cliconfig.Context
+}
+
+// expandName fills a name template and trims separators left dangling by
+// empty placeholders.
+func expandName(tmpl string, vars map[string]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	name := strings.NewReplacer(pairs...).Replace(tmpl)
+	return strings.Trim(name, "-_.:/ ")
+}
+
+// nameCandidates names each candidate with tmpl. Two different clusters
+// landing on one name is an error, since saving either would hide the other;
+// the same cluster found twice is kept once.
+func nameCandidates(tmpl string, cands []candidate) (map[string]cliconfig.Context, error) {
+	out := map[string]cliconfig.Context{}
+	var clashes []string
+	for _, c := range cands {
+		name := expandName(tmpl, c.vars)
+		if name == "" {
+			return nil, fmt.Errorf("--name-template %q gives an empty name for cluster %s", tmpl, c.ctx.Cluster)
+		}
+		if prev, ok := out[name]; ok {
+			if !prev.SameTarget(c.ctx) {
+				clashes = append(clashes, fmt.Sprintf("%s (%s in %s and %s in %s)", name, prev.Cluster, orDash(prev.Region), c.ctx.Cluster, orDash(c.ctx.Region)))
+			}
+			continue
+		}
+		out[name] = c.ctx
+	}
+	if len(clashes) > 0 {
+		return nil, fmt.Errorf("--name-template %q gives several clusters the same name: %s; add {region} or {account}",
+			tmpl, strings.Join(clashes, ", "))
+	}
+	return out, nil
+}
+
+// applyMerge merges incoming into the saved contexts, saves unless dryRun,
+// and reports. Conflicts make it fail after the rest was saved.
+func applyMerge(cmd *cli.Command, incoming map[string]cliconfig.Context, prune func(*cliconfig.File) []string) error {
+	f, err := cliconfig.Load()
+	if err != nil {
+		return err
+	}
+	report := f.Merge(incoming, cmd.Bool("overwrite"))
+	if prune != nil {
+		report.Pruned = prune(f)
+	}
+	dryRun := cmd.Bool("dry-run")
+	if report.Changed() && !dryRun {
+		if err := cliconfig.Save(f); err != nil {
+			return err
+		}
+	}
+	if handled, err := runner.EncodeStdout(cmd.String("format"), report); handled {
+		if err != nil {
+			return err
+		}
+	} else {
+		printReport(os.Stdout, report, dryRun)
+	}
+	if n := len(report.Conflicts); n > 0 {
+		return fmt.Errorf("%d context(s) not saved: the name is already taken (re-run with --overwrite to replace)", n)
+	}
+	return nil
+}
+
+// printReport lists what the merge did, name by name.
+func printReport(w io.Writer, r cliconfig.MergeReport, dryRun bool) {
+	verb := func(done, planned string) string {
+		if dryRun {
+			return planned
+		}
+		return done
+	}
+	for _, n := range r.Added {
+		_, _ = fmt.Fprintln(w, color.GreenString("+ %s", n), verb("added", "would add"))
+	}
+	for _, n := range r.Updated {
+		_, _ = fmt.Fprintln(w, color.YellowString("~ %s", n), verb("overwritten", "would overwrite"))
+	}
+	for _, n := range r.Pruned {
+		_, _ = fmt.Fprintln(w, color.RedString("- %s", n), verb("pruned", "would prune"))
+	}
+	for _, c := range r.Duplicates {
+		_, _ = fmt.Fprintf(w, "= %s skipped: %s\n", c.Name, c.Reason())
+	}
+	for _, c := range r.Conflicts {
+		_, _ = fmt.Fprintln(w, color.RedString("! %s conflict: %s, not %s", c.Name, c.Reason(), describeTarget(c.Incoming)))
+	}
+	summary := fmt.Sprintf("%d added, %d overwritten, %d pruned, %d unchanged, %d duplicate, %d conflicting",
+		len(r.Added), len(r.Updated), len(r.Pruned), len(r.Unchanged), len(r.Duplicates), len(r.Conflicts))
+	if dryRun {
+		summary += " (dry run, nothing saved)"
+	}
+	_, _ = fmt.Fprintln(w, summary)
+}
+
+func describeTarget(c cliconfig.Context) string {
+	s := c.Cluster + " in " + orDash(c.Region)
+	if c.Role != "" {
+		s += " via " + c.Role
+	}
+	return s
+}
+
+// mergeFlags are shared by import and sync.
+func mergeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "overwrite", Usage: "Replace saved contexts whose name is taken by a different cluster (reported as conflicts otherwise)"},
+		&cli.BoolFlag{Name: "dry-run", Usage: "Report what would change without saving"},
+		&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+	}
+}
+
+func contextImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Add contexts from an exported file or from kubeconfig",
+		ArgsUsage: "[file|-]",
+		Description: `Add contexts in bulk, either from a file written by 'refresh context export'
+(a team's canonical set) or, with --from-kubeconfig, from the EKS clusters in
+a kubeconfig.
+
+Kubeconfig contexts are read for EKS cluster ARNs (aws eks update-kubeconfig)
+and eksctl names, and their users' exec args (--cluster-name, --region,
+--profile, --role-arn) and AWS_PROFILE/AWS_REGION env. --name-template names
+them, {cluster} by default.
+
+A name already saved with a different cluster is a conflict: it is reported
+and left alone unless --overwrite is given, and the command exits non-zero.
+A cluster already saved under another name is skipped.
+
+  refresh context import team-contexts.yaml
+  refresh context import --from-kubeconfig --name-template '{cluster}-{region}'
+  refresh context import --from-kubeconfig --dry-run`,
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{Name: "from-kubeconfig", Usage: "Import the EKS clusters in kubeconfig instead of a file"},
+			&cli.StringFlag{Name: "kubeconfig", Usage: "Kubeconfig to read (defaults to $KUBECONFIG, then ~/.kube/config)"},
+			&cli.StringFlag{Name: "name-template", Usage: nameTemplateUsage, Value: defaultNameTemplate},
+		}, mergeFlags()...),
+		Action: runImport,
+	}
+}
+
+func runImport(_ context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	if !cmd.Bool("from-kubeconfig") {
+		src := strings.TrimSpace(cmd.Args().First())
+		if src == "" {
+			return errors.New("give a file to import (or - for stdin), or --from-kubeconfig")
+		}
+		data, err := readSource(src)
+		if err != nil {
+			return err
+		}
+		incoming, err := cliconfig.ParseContexts(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src, err)
+		}
+		return applyMerge(cmd, incoming, nil)
+	}
+
+	path := kubeconfigPath(cmd.String("kubeconfig"))
+	raw, err := loadKubeconfig(path)
+	if err != nil {
+		return err
+	}
+	entries, skipped := kubeEntries(raw)
+	for _, s := range skipped {
+		_, _ = fmt.Fprintln(os.Stderr, color.YellowString("skipping kubeconfig context %s", s))
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no EKS clusters found in %s", path)
+	}
+	cands := make([]candidate, 0, len(entries))
+	for _, e := range entries {
+		cands = append(cands, candidate{ctx: e.Context, vars: map[string]string{
+			"cluster": e.Cluster, "region": e.Region, "account": e.Account, "account_name": e.Account,
+			"profile": e.Profile, "context": e.KubeContext,
+		}})
+	}
+	incoming, err := nameCandidates(cmd.String("name-template"), cands)
+	if err != nil {
+		return err
+	}
+	return applyMerge(cmd, incoming, nil)
+}
+
+func readSource(src string) ([]byte, error) {
+	if src == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", src, err)
+	}
+	return data, nil
+}
+
+func contextExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Write saved contexts as YAML to share",
+		ArgsUsage: "[name...]",
+		Description: `Write the named contexts (all by default) as YAML that 'refresh context
+import' reads, without the active/previous pointers. Commit it to share a
+canonical context set with a team.
+
+  refresh context export > team-contexts.yaml
+  refresh context export prod stage --out team-contexts.yaml`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "out", Aliases: []string{"f"}, Usage: "Write to this file instead of stdout"},
+		},
+		ShellComplete: completeContextNames,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			f, err := cliconfig.Load()
+			if err != nil {
+				return err
+			}
+			out, err := f.Export(cmd.Args().Slice())
+			if err != nil {
+				return err
+			}
+			data, err := yaml.Marshal(out)
+			if err != nil {
+				return err
+			}
+			dst := strings.TrimSpace(cmd.String("out"))
+			if dst == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(dst, data, 0o644); err != nil {
+				return err
+			}
+			color.Green("Exported %d context(s) to %s", len(out.Contexts), dst)
+			return nil
+		},
+	}
+}
+
+func contextSyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Create contexts for the EKS clusters discovered across regions and accounts",
+		Description: `Discover EKS clusters (in the current region, --region, or every region with
+-A, and in every account of --account-role/--org-role) and add a context for
+each, named by --name-template ({cluster} by default). Contexts for other
+accounts carry the role, which is assumed whenever they're active.
+
+Contexts sync creates are marked as managed. With --prune, managed contexts
+for clusters that are gone are removed; only regions and accounts that were
+swept successfully are pruned, and contexts you added yourself never are.
+Names already saved with a different cluster are reported as conflicts.
+
+  refresh context sync -A
+  refresh context sync -A --org-role refresh-read --name-template '{account_name}-{cluster}'
+  refresh context sync -A --prune --dry-run`,
+		Flags: append(append([]cli.Flag{
+			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Discover in all EKS-supported regions"},
+			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "Specific region(s) to discover in (repeatable)"},
+			&cli.StringFlag{Name: "name-template", Usage: nameTemplateUsage, Value: defaultNameTemplate},
+			&cli.BoolFlag{Name: "prune", Usage: "Remove managed contexts whose cluster no longer exists"},
+		}, mergeFlags()...), runner.AccountFlags()...),
+		Action: runSync,
+	}
+}
+
+// sweep is one account × region to list clusters in.
+type sweep struct {
+	acct   accounts.Account
+	region string
+}
+
+type sweepResult struct {
+	sweep
+	clusters []string
+	err      error
+}
+
+// listClusters lists the EKS clusters cfg's credentials see in its region.
+// A variable so tests can stub discovery.
+var listClusters = func(ctx context.Context, cfg aws.Config) ([]string, error) {
+	client := eks.NewFromConfig(cfg)
+	return awsinternal.ListAllPages(ctx, "listing EKS clusters in "+cfg.Region,
+		func(rc context.Context, token *string) (*eks.ListClustersOutput, error) {
+			return common.WithRetry(rc, common.DefaultRetryConfig, func(c context.Context) (*eks.ListClustersOutput, error) {
+				return client.ListClusters(c, &eks.ListClustersInput{NextToken: token})
+			})
+		},
+		func(o *eks.ListClustersOutput) ([]string, *string) { return o.Clusters, o.NextToken },
+	)
+}
+
+// setupSync loads the base AWS config and account inventory. A variable so
+// tests can run sync without credentials.
+var setupSync = func(ctx context.Context, cmd *cli.Command) (context.Context, context.CancelFunc, aws.Config, []accounts.Account, []error, error) {
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return nil, nil, aws.Config{}, nil, nil, err
+	}
+	accts, failed, err := runner.ResolveAccounts(ctx, cmd, awsCfg)
+	if err != nil {
+		cancel()
+		return nil, nil, aws.Config{}, nil, nil, err
+	}
+	return ctx, cancel, awsCfg, accts, failed, nil
+}
+
+func runSync(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	ctx, cancel, awsCfg, accts, failed, err := setupSync(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	regions := runner.FleetRegions(cmd, awsCfg)
+	var sweeps []sweep
+	for _, a := range accts {
+		for _, r := range regions {
+			sweeps = append(sweeps, sweep{acct: a, region: r})
+		}
+	}
+	results := common.ForEachParallel(ctx, sweeps, cmd.Int("max-concurrency"), func(fctx context.Context, s sweep) sweepResult {
+		cfg := s.acct.Config.Copy()
+		cfg.Region = s.region
+		clusters, err := listClusters(fctx, cfg)
+		return sweepResult{sweep: s, clusters: clusters, err: err}
+	})
+
+	profile := baseProfile(cmd)
+	var cands []candidate
+	swept := map[string]bool{} // role|region pairs listed successfully
+	errs := failed
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sweepLabel(res.sweep), res.err))
+			continue
+		}
+		swept[res.acct.RoleARN+"|"+res.region] = true
+		for _, name := range res.clusters {
+			cands = append(cands, candidate{
+				ctx: cliconfig.Context{Cluster: name, Region: res.region, Profile: profile, Role: res.acct.RoleARN, Managed: cliconfig.ManagedBySync},
+				vars: map[string]string{
+					"cluster": name, "region": res.region, "account": res.acct.ID, "account_name": res.acct.Label(),
+					"profile": profile, "context": name,
+				},
+			})
+		}
+	}
+	for _, e := range errs {
+		_, _ = fmt.Fprintln(os.Stderr, color.YellowString("warning: %v", e))
+	}
+	if len(swept) == 0 && len(errs) > 0 {
+		return errs[0]
+	}
+
+	incoming, err := nameCandidates(cmd.String("name-template"), cands)
+	if err != nil {
+		return err
+	}
+	var prune func(*cliconfig.File) []string
+	if cmd.Bool("prune") {
+		prune = func(f *cliconfig.File) []string { return pruneGone(f, cands, swept) }
+	}
+	return applyMerge(cmd, incoming, prune)
+}
+
+// pruneGone removes the managed contexts in a swept account and region whose
+// cluster wasn't discovered there, and returns their names.
+func pruneGone(f *cliconfig.File, found []candidate, swept map[string]bool) []string {
+	live := map[string]bool{}
+	for _, c := range found {
+		live[c.ctx.Role+"|"+c.ctx.Region+"|"+c.ctx.Cluster] = true
+	}
+	var pruned []string
+	for _, name := range f.Names() {
+		c := f.Contexts[name]
+		if c.Managed != cliconfig.ManagedBySync || !swept[c.Role+"|"+c.Region] || live[c.Role+"|"+c.Region+"|"+c.Cluster] {
+			continue
+		}
+		if err := f.Remove(name); err == nil {
+			pruned = append(pruned, name)
+		}
+	}
+	return pruned
+}
+
+func sweepLabel(s sweep) string {
+	if s.acct.ID == "" {
+		return s.region
+	}
+	return "account " + s.acct.Label() + " " + s.region
+}
+
+// baseProfile is the profile the base credentials came from, recorded on
+// synced contexts: --profile, AWS_PROFILE, else the active context's.
+func baseProfile(cmd *cli.Command) string {
+	if p := strings.TrimSpace(cmd.String("profile")); p != "" {
+		return p
+	}
+	if p := os.Getenv("AWS_PROFILE"); p != "" {
+		return p
+	}
+	if f, err := cliconfig.Load(); err == nil {
+		if _, active, ok := f.Active(); ok {
+			return active.Profile
+		}
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+package ctxcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: arn:aws:eks:us-east-1:111122223333:cluster/prod
+  cluster: {server: https://ABC.gr7.us-east-1.eks.amazonaws.com}
+- name: stage.eu-west-1.eksctl.io
+  cluster: {server: https://DEF.yl4.eu-west-1.eks.amazonaws.com}
+- name: custom
+  cluster: {server: https://GHI.sk1.us-west-2.eks.amazonaws.com}
+- name: kind-local
+  cluster: {server: https://127.0.0.1:6443}
+users:
+- name: arn:aws:eks:us-east-1:111122223333:cluster/prod
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: [--region, us-east-1, eks, get-token, --cluster-name, prod, --output, json]
+      env: [{name: AWS_PROFILE, value: prod-admin}]
+- name: me@stage.eu-west-1.eksctl.io
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: [eks, get-token, --cluster-name, stage, --profile=stage]
+- name: custom-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws-iam-authenticator
+      args: [token, -i, data, -r, "arn:aws:iam::444455556666:role/eks-admin"]
+- name: kind-local
+  user: {}
+contexts:
+- name: arn:aws:eks:us-east-1:111122223333:cluster/prod
+  context: {cluster: "arn:aws:eks:us-east-1:111122223333:cluster/prod", user: "arn:aws:eks:us-east-1:111122223333:cluster/prod"}
+- name: me@stage.eu-west-1.eksctl.io
+  context: {cluster: stage.eu-west-1.eksctl.io, user: me@stage.eu-west-1.eksctl.io}
+- name: data-ctx
+  context: {cluster: custom, user: custom-user}
+- name: kind-local
+  context: {cluster: kind-local, user: kind-local}
+`
+
+func writeKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKubeEntries(t *testing.T) {
+	raw, err := loadKubeconfig(writeKubeconfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, skipped := kubeEntries(raw)
+	got := map[string]kubeEntry{}
+	for _, e := range entries {
+		got[e.KubeContext] = e
+	}
+	want := map[string]cliconfig.Context{
+		"arn:aws:eks:us-east-1:111122223333:cluster/prod": {Cluster: "prod", Region: "us-east-1", Profile: "prod-admin"},
+		"me@stage.eu-west-1.eksctl.io":                    {Cluster: "stage", Region: "eu-west-1", Profile: "stage"},
+		"data-ctx":                                        {Cluster: "data", Region: "us-west-2", Role: "arn:aws:iam::444455556666:role/eks-admin"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for name, w := range want {
+		if got[name].Context != w {
+			t.Errorf("%s = %+v, want %+v", name, got[name].Context, w)
+		}
+	}
+	if got["arn:aws:eks:us-east-1:111122223333:cluster/prod"].Account != "111122223333" {
+		t.Errorf("prod account = %q", got["arn:aws:eks:us-east-1:111122223333:cluster/prod"].Account)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "kind-local") {
+		t.Errorf("skipped = %v", skipped)
+	}
+}
+
+func TestExpandAndNameCandidates(t *testing.T) {
+	vars := map[string]string{"cluster": "prod", "region": "us-east-1", "account_name": ""}
+	if got := expandName("{account_name}-{cluster}", vars); got != "prod" {
+		t.Errorf("expandName = %q, want dangling separator trimmed", got)
+	}
+	cands := []candidate{
+		{vars: map[string]string{"cluster": "web", "region": "us-east-1"}, ctx: cliconfig.Context{Cluster: "web", Region: "us-east-1"}},
+		{vars: map[string]string{"cluster": "web", "region": "eu-west-1"}, ctx: cliconfig.Context{Cluster: "web", Region: "eu-west-1"}},
+	}
+	if _, err := nameCandidates("{cluster}", cands); err == nil || !strings.Contains(err.Error(), "add {region}") {
+		t.Errorf("clashing template err = %v", err)
+	}
+	named, err := nameCandidates("{cluster}-{region}", cands)
+	if err != nil || len(named) != 2 || named["web-eu-west-1"].Region != "eu-west-1" {
+		t.Errorf("named = %+v, err = %v", named, err)
+	}
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	kube := writeKubeconfig(t)
+
+	out, err := captureStdout(t, func() error {
+		return runCommand(contextImportCommand(), "--from-kubeconfig", "--kubeconfig", kube, "--name-template", "{cluster}-{region}")
+	})
+	if err != nil {
+		t.Fatalf("import: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "3 added") {
+		t.Errorf("import output = %q", out)
+	}
+	f, err := cliconfig.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := f.Contexts["data-us-west-2"]; c.Role != "arn:aws:iam::444455556666:role/eks-admin" {
+		t.Errorf("data context = %+v", c)
+	}
+
+	exported := filepath.Join(t.TempDir(), "team.yaml")
+	if _, err := captureStdout(t, func() error {
+		return runCommand(contextExportCommand(), "--out", exported, "prod-us-east-1")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-point prod locally: importing the team file now conflicts.
+	f.Contexts["prod-us-east-1"] = cliconfig.Context{Cluster: "prod-v2", Region: "us-east-1"}
+	if err := cliconfig.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	out, err = captureStdout(t, func() error { return runCommand(contextImportCommand(), exported) })
+	if err == nil || !strings.Contains(err.Error(), "--overwrite") || !strings.Contains(out, "1 conflicting") {
+		t.Fatalf("conflicting import: err = %v, out = %q", err, out)
+	}
+	if f, _ = cliconfig.Load(); f.Contexts["prod-us-east-1"].Cluster != "prod-v2" {
+		t.Errorf("conflict was overwritten: %+v", f.Contexts["prod-us-east-1"])
+	}
+	if _, err := captureStdout(t, func() error { return runCommand(contextImportCommand(), "--overwrite", exported) }); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ = cliconfig.Load(); f.Contexts["prod-us-east-1"].Cluster != "prod" {
+		t.Errorf("--overwrite didn't replace prod: %+v", f.Contexts["prod-us-east-1"])
+	}
+}
+
+func stubSync(t *testing.T, accts []accounts.Account, clusters map[string][]string) {
+	t.Helper()
+	oldSetup, oldList := setupSync, listClusters
+	t.Cleanup(func() { setupSync, listClusters = oldSetup, oldList })
+	setupSync = func(ctx context.Context, _ *cli.Command) (context.Context, context.CancelFunc, aws.Config, []accounts.Account, []error, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, aws.Config{Region: "us-east-1"}, accts, nil, nil
+	}
+	listClusters = func(_ context.Context, cfg aws.Config) ([]string, error) {
+		key := cfg.Region
+		if cfg.AppID != "" {
+			key = cfg.AppID + "/" + cfg.Region
+		}
+		names, ok := clusters[key]
+		if !ok {
+			return nil, errors.New("AccessDenied")
+		}
+		return names, nil
+	}
+}
+
+func runSyncCmd(args ...string) error {
+	cmd := contextSyncCommand()
+	cmd.Flags = append(cmd.Flags, &cli.IntFlag{Name: "max-concurrency", Value: 4}, &cli.StringFlag{Name: "profile"})
+	return runCommand(cmd, args...)
+}
+
+func TestSync(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	t.Setenv("AWS_PROFILE", "ops")
+
+	// AppID tags each account's config so the stub can tell them apart.
+	accts := []accounts.Account{
+		{ID: "111111111111", Name: "prod", RoleARN: "arn:aws:iam::111111111111:role/read", Config: aws.Config{AppID: "prod"}},
+		{ID: "222222222222", Name: "dev", RoleARN: "arn:aws:iam::222222222222:role/read", Config: aws.Config{AppID: "dev"}},
+	}
+	stubSync(t, accts, map[string][]string{
+		"prod/us-east-1": {"web", "api"},
+		"dev/us-east-1":  {"web"},
+		"prod/eu-west-1": {},
+		// dev/eu-west-1 fails
+	})
+
+	out, err := captureStdout(t, func() error {
+		return runSyncCmd("--region", "us-east-1", "--region", "eu-west-1", "--name-template", "{account_name}-{cluster}")
+	})
+	if err != nil {
+		t.Fatalf("sync: %v\n%s", err, out)
+	}
+	f, _ := cliconfig.Load()
+	if len(f.Contexts) != 3 {
+		t.Fatalf("contexts = %+v", f.Contexts)
+	}
+	web := f.Contexts["dev-web"]
+	if web.Cluster != "web" || web.Region != "us-east-1" || web.Role != accts[1].RoleARN || web.Profile != "ops" || web.Managed != cliconfig.ManagedBySync {
+		t.Errorf("dev-web = %+v", web)
+	}
+
+	// Templates that fold accounts together are refused.
+	if _, err := captureStdout(t, func() error { return runSyncCmd("--region", "us-east-1") }); err == nil || !strings.Contains(err.Error(), "same name") {
+		t.Errorf("clashing sync err = %v", err)
+	}
+
+	// api is gone; a hand-made context in the same account survives the prune.
+	f.Contexts["mine"] = cliconfig.Context{Cluster: "old", Region: "us-east-1", Role: accts[0].RoleARN}
+	if err := cliconfig.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	stubSync(t, accts, map[string][]string{"prod/us-east-1": {"web"}, "dev/us-east-1": {"web"}})
+	out, err = captureStdout(t, func() error {
+		return runSyncCmd("--region", "us-east-1", "--name-template", "{account_name}-{cluster}", "--prune", "--dry-run")
+	})
+	if err != nil || !strings.Contains(out, "prod-api") || !strings.Contains(out, "dry run") {
+		t.Fatalf("dry-run prune: err = %v, out = %q", err, out)
+	}
+	if f, _ = cliconfig.Load(); len(f.Contexts) != 4 {
+		t.Errorf("dry run saved changes: %+v", f.Contexts)
+	}
+	if _, err := captureStdout(t, func() error {
+		return runSyncCmd("--region", "us-east-1", "--name-template", "{account_name}-{cluster}", "--prune")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = cliconfig.Load()
+	if _, ok := f.Contexts["prod-api"]; ok {
+		t.Error("prod-api wasn't pruned")
+	}
+	if _, ok := f.Contexts["mine"]; !ok {
+		t.Error("unmanaged context was pruned")
+	}
+}
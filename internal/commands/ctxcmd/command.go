@@ -69,7 +69,7 @@ func ContextCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "context",
 		Aliases: []string{"ctx"},
-		Usage:   "Manage saved refresh contexts (list, add, remove)",
+		Usage:   "Manage saved refresh contexts (list, add, remove, import, export, sync, group)",
 		Description: `Manage the named contexts that 'refresh use' switches between. Each context
 binds a cluster to an optional region and AWS profile, so you can name your
 environments once and select them by name (the kubectx model for EKS).
@@ -80,11 +80,26 @@ Contexts are stored as YAML under $XDG_CONFIG_HOME/refresh/context.yaml
   refresh context add prod --cluster prod-eks --region us-east-1 --profile prod
   refresh context list             # show all saved contexts (* marks active)
   refresh context add prod --cluster prod-eks --use   # add and switch in one step
-  refresh context remove prod      # delete a saved context`,
+  refresh context remove prod      # delete a saved context
+
+For a fleet, add them in bulk instead:
+
+  refresh context import --from-kubeconfig   # every EKS cluster in kubeconfig
+  refresh context sync -A                    # every cluster discovered in AWS
+  refresh context export > team.yaml         # share a canonical set
+  refresh context import team.yaml
+
+Name tag selectors as groups for the fleet commands' --group:
+
+  refresh context group add prod-web 'env=prod,tier=web'`,
 		Commands: []*cli.Command{
 			contextListCommand(),
 			contextAddCommand(),
 			contextRemoveCommand(),
+			contextImportCommand(),
+			contextExportCommand(),
+			contextSyncCommand(),
+			contextGroupCommand(),
 		},
 	}
 }
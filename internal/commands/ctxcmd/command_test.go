@@ -70,7 +70,7 @@ func TestContextCommandStructure(t *testing.T) {
 		t.Error("context: missing 'ctx' alias")
 	}
 
-	for _, want := range []string{"list", "add", "remove"} {
+	for _, want := range []string{"list", "add", "remove", "import", "export", "sync", "group"} {
 		if findSub(cmd, want) == nil {
 			t.Errorf("context: missing subcommand %q", want)
 		}
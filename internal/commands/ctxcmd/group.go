@@ -0,0 +1,119 @@
+package ctxcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// completeGroupNames prints saved group names for shell completion.
+func completeGroupNames(_ context.Context, cmd *cli.Command) {
+	if cmd.NArg() > 0 {
+		return
+	}
+	f, err := cliconfig.Load()
+	if err != nil {
+		return
+	}
+	for _, n := range f.GroupNames() {
+		_, _ = fmt.Fprintln(cmd.Root().Writer, n)
+	}
+}
+
+func contextGroupCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "group",
+		Aliases: []string{"groups"},
+		Usage:   "Manage named cluster selectors (context groups) for --group",
+		Description: `A context group names an EKS tag selector, so fleet commands (status,
+cluster list, nodegroup update --all-clusters) can target it with --group
+instead of repeating --selector. Groups are saved next to the contexts.
+
+  refresh context group add prod-web 'env=prod,tier in (web,api)'
+  refresh context group list
+  refresh status -A --group prod-web
+  refresh context group remove prod-web`,
+		Commands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List saved context groups",
+				Action: func(_ context.Context, _ *cli.Command) error {
+					f, err := cliconfig.Load()
+					if err != nil {
+						return err
+					}
+					if len(f.Groups) == 0 {
+						color.Yellow("No saved groups. Add one with: refresh context group add <name> <selector>")
+						return nil
+					}
+					for _, n := range f.GroupNames() {
+						fmt.Printf("%-20s %s\n", n, f.Groups[n])
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "add",
+				Usage:     "Add or update a context group",
+				ArgsUsage: "<name> <selector>",
+				Description: `Save a tag selector under a name. Re-running with the same name replaces it.
+Selectors are kubectl-style and comma-separated requirements all must hold:
+
+  key=value  key!=value  key in (a,b)  key notin (a,b)  key  !key
+
+  refresh context group add prod 'env=prod'
+  refresh context group add shared-prod 'env=prod,team notin (data,ml),!legacy'`,
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					name := strings.TrimSpace(cmd.Args().Get(0))
+					expr := strings.TrimSpace(strings.Join(cmd.Args().Tail(), " "))
+					if name == "" || expr == "" {
+						return fmt.Errorf("usage: refresh context group add <name> <selector>")
+					}
+					f, err := cliconfig.Load()
+					if err != nil {
+						return err
+					}
+					if err := f.SetGroup(name, expr); err != nil {
+						return err
+					}
+					if err := cliconfig.Save(f); err != nil {
+						return err
+					}
+					color.Green("Saved group %q: %s", name, f.Groups[name])
+					return nil
+				},
+			},
+			{
+				Name:          "remove",
+				Aliases:       []string{"rm", "delete"},
+				Usage:         "Remove a context group",
+				ArgsUsage:     "<name>",
+				ShellComplete: completeGroupNames,
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					name := strings.TrimSpace(cmd.Args().First())
+					if name == "" {
+						return fmt.Errorf("group name is required")
+					}
+					f, err := cliconfig.Load()
+					if err != nil {
+						return err
+					}
+					if err := f.RemoveGroup(name); err != nil {
+						return err
+					}
+					if err := cliconfig.Save(f); err != nil {
+						return err
+					}
+					color.Green("Removed group %q", name)
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package ctxcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+func TestContextGroupCommands(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	group := contextGroupCommand()
+
+	if _, err := captureStdout(t, func() error {
+		return runCommand(group, "add", "prod-web", "env=prod,", "tier in (web, api)")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := cliconfig.Load()
+	if got := f.Groups["prod-web"]; got != "env=prod,tier in (api,web)" {
+		t.Errorf("saved group = %q", got)
+	}
+
+	out, err := captureStdout(t, func() error { return runCommand(group, "list") })
+	if err != nil || !strings.Contains(out, "prod-web") || !strings.Contains(out, "tier in (api,web)") {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	if err := runCommand(group, "add", "broken", "env in prod"); err == nil {
+		t.Error("a malformed selector should fail")
+	}
+	if _, err := captureStdout(t, func() error { return runCommand(group, "remove", "prod-web") }); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ = cliconfig.Load(); len(f.Groups) != 0 {
+		t.Errorf("groups after remove = %v", f.Groups)
+	}
+}
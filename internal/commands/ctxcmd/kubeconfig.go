@@ -0,0 +1,152 @@
+package ctxcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+var (
+	// eksARN matches an EKS cluster ARN, as `aws eks update-kubeconfig`
+	// names clusters, users and contexts.
+	eksARN = regexp.MustCompile(`^arn:aws[a-z-]*:eks:([a-z0-9-]+):(\d{12}):cluster/(.+)$`)
+	// eksctlName matches eksctl's <cluster>.<region>.eksctl.io names.
+	eksctlName = regexp.MustCompile(`^(?:[^@]*@)?([A-Za-z0-9][A-Za-z0-9_-]*)\.([a-z]{2}(?:-[a-z]+)+-\d)\.eksctl\.io$`)
+	// eksEndpointRegion pulls the region out of an EKS API server URL.
+	eksEndpointRegion = regexp.MustCompile(`\.([a-z]{2}(?:-[a-z]+)+-\d)\.eks\.amazonaws\.com`)
+)
+
+// kubeEntry is an EKS cluster found behind a kubeconfig context.
+type kubeEntry struct {
+	// KubeContext is the kubeconfig context it came from.
+	KubeContext string
+	Account     string
+	cliconfig.Context
+}
+
+// kubeconfigPath picks the file to import: --kubeconfig, else the first
+// $KUBECONFIG entry, else ~/.kube/config.
+func kubeconfigPath(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".kube", "config")
+}
+
+// loadKubeconfig reads the kubeconfig at path.
+func loadKubeconfig(path string) (*clientcmdapi.Config, error) {
+	raw, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// kubeEntries resolves each kubeconfig context to an EKS cluster, region and
+// AWS profile: from an EKS ARN or eksctl name on the context, cluster or
+// user, then from the user's exec plugin args and env (aws eks get-token,
+// aws-iam-authenticator), then from the API server URL. Contexts that don't
+// lead to an EKS cluster come back in skipped with the reason.
+func kubeEntries(raw *clientcmdapi.Config) (entries []kubeEntry, skipped []string) {
+	names := make([]string, 0, len(raw.Contexts))
+	for n := range raw.Contexts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kc := raw.Contexts[name]
+		e := kubeEntry{KubeContext: name}
+		for _, ref := range []string{kc.Cluster, name, kc.AuthInfo} {
+			e.fromName(ref)
+		}
+		if user := raw.AuthInfos[kc.AuthInfo]; user != nil && user.Exec != nil {
+			e.fromExec(user.Exec)
+		}
+		if cl := raw.Clusters[kc.Cluster]; cl != nil && e.Region == "" {
+			if m := eksEndpointRegion.FindStringSubmatch(cl.Server); m != nil {
+				e.Region = m[1]
+			}
+		}
+		if e.Cluster == "" {
+			skipped = append(skipped, fmt.Sprintf("%s: no EKS cluster ARN or get-token --cluster-name", name))
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, skipped
+}
+
+// fromName fills the cluster, region and account from an ARN or eksctl name,
+// without replacing what an earlier reference already set.
+func (e *kubeEntry) fromName(ref string) {
+	if m := eksARN.FindStringSubmatch(ref); m != nil {
+		e.fill(&e.Region, m[1])
+		e.fill(&e.Account, m[2])
+		e.fill(&e.Cluster, m[3])
+		return
+	}
+	if m := eksctlName.FindStringSubmatch(ref); m != nil {
+		e.fill(&e.Cluster, m[1])
+		e.fill(&e.Region, m[2])
+	}
+}
+
+// fromExec reads the exec credential plugin: `aws eks get-token
+// --cluster-name X --region R [--role-arn A] [--profile P]` or
+// `aws-iam-authenticator token -i X [-r A]`, plus AWS_PROFILE/AWS_REGION in
+// its env.
+func (e *kubeEntry) fromExec(ex *clientcmdapi.ExecConfig) {
+	args := ex.Args
+	for i := 0; i < len(args); i++ {
+		flag, value, inline := strings.Cut(args[i], "=")
+		if !inline {
+			if i+1 >= len(args) {
+				break
+			}
+			value = args[i+1]
+		}
+		var dst *string
+		switch flag {
+		case "--cluster-name", "--cluster-id", "-i":
+			dst = &e.Cluster
+		case "--region":
+			dst = &e.Region
+		case "--profile":
+			dst = &e.Profile
+		case "--role-arn", "--role", "-r":
+			dst = &e.Role
+		default:
+			continue
+		}
+		e.fill(dst, value)
+		if !inline {
+			i++
+		}
+	}
+	for _, env := range ex.Env {
+		switch env.Name {
+		case "AWS_PROFILE":
+			e.fill(&e.Profile, env.Value)
+		case "AWS_REGION", "AWS_DEFAULT_REGION":
+			e.fill(&e.Region, env.Value)
+		}
+	}
+}
+
+func (e *kubeEntry) fill(dst *string, v string) {
+	if *dst == "" {
+		*dst = strings.TrimSpace(v)
+	}
+}
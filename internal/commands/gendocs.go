@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/plugins"
 )
 
 // GenDocsCommand returns a hidden command that generates the Markdown command
@@ -202,8 +204,9 @@ func flagNames(f cli.Flag, df cli.DocGenerationFlag) string {
 func visibleSubcommands(c *cli.Command) []*cli.Command {
 	var out []*cli.Command
 	for _, sc := range c.Commands {
-		// Skip hidden commands and the framework-added `help` command.
-		if sc.Hidden || sc.Name == "help" {
+		// Skip hidden commands, the framework-added `help` command, and the
+		// plugins found on this machine's PATH.
+		if sc.Hidden || sc.Name == "help" || sc.Category == plugins.Category {
 			continue
 		}
 		out = append(out, sc)
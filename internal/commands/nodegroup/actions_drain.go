@@ -0,0 +1,155 @@
+package nodegroup
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// drainCheckOutput is the -o json|yaml payload of `nodegroup drain-check`.
+type drainCheckOutput struct {
+	Cluster string `json:"cluster" yaml:"cluster"`
+	health.DrainReport
+}
+
+func runDrainCheck(ctx context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	if err := runner.ValidateFormat(format, []string{"table", "json", "yaml"}); err != nil {
+		return err
+	}
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	clusterName, err := awsinternal.ClusterName(ctx, awsCfg, runner.RequestedCluster(cmd))
+	if err != nil {
+		return err
+	}
+
+	// Unlike the pre-flight checks, this command is nothing without the
+	// cluster API, so an unreachable one is an error rather than a skip.
+	client, diag, err := health.BuildKubeClient(cmd.String("kubeconfig"))
+	if err != nil {
+		return fmt.Errorf("drain-check needs the cluster API: %w (%s)", err, diag)
+	}
+	if err := health.ProbeConnection(ctx, client); err != nil {
+		return fmt.Errorf("drain-check needs the cluster API, unreachable via %s: %w", diag, err)
+	}
+
+	nodegroup := cmd.String("nodegroup")
+	var report health.DrainReport
+	err = runner.WithSpinner("nodegroup", "Analyzed drain blockers", func() error {
+		var aerr error
+		report, aerr = health.NewChecker(nil, client, nil, nil).AnalyzeDrain(ctx, nodegroup)
+		return aerr
+	})
+	if err != nil {
+		return err
+	}
+	if len(report.Nodes) == 0 {
+		return fmt.Errorf("no nodes labeled eks.amazonaws.com/nodegroup=%s in cluster %s (is --kubeconfig pointing at it?)", nodegroup, clusterName)
+	}
+
+	if handled, err := runner.EncodeStdout(format, drainCheckOutput{Cluster: clusterName, DrainReport: report}); handled {
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, line := range drainCheckLines(render.Default(os.Stdout), clusterName, report) {
+			fmt.Println(line)
+		}
+	}
+	return drainCheckExit(report)
+}
+
+// drainCheckExit maps the report onto the health-gate exit codes: 3 when
+// something would stall the drain, 2 for warnings only.
+func drainCheckExit(r health.DrainReport) error {
+	switch {
+	case r.Blocking() > 0:
+		return cli.Exit(fmt.Sprintf("%d pod(s) would stall draining %s", r.Blocking(), r.Nodegroup), 3)
+	case r.Warnings() > 0:
+		return cli.Exit("", 2)
+	}
+	return nil
+}
+
+// drainCheckLines renders the human drain-check report (pure, golden-testable).
+func drainCheckLines(th *render.Theme, cluster string, r health.DrainReport) []string {
+	pal := th.Pal
+	out := []string{
+		th.Bold(pal.Mauve, "DRAIN CHECK") + "  " + th.Paint(pal.White, cluster+"/"+r.Nodegroup) +
+			th.Paint(pal.Dim, fmt.Sprintf(" · %d node(s) · %d pod(s) to evict", len(r.Nodes), r.Pods)),
+		"",
+	}
+	if len(r.Blockers) == 0 {
+		return append(out, th.Token(render.Healthy, "Nothing would stall or be hurt by draining this nodegroup"))
+	}
+	tbl := th.NewTable(
+		ui.Column{Title: "SEVERITY", Min: 8},
+		ui.Column{Title: "POD", Min: 4, Max: 48},
+		ui.Column{Title: "NODE", Min: 4, Max: 32},
+		ui.Column{Title: "REASON", Min: 6},
+		ui.Column{Title: "DETAIL", Min: 6},
+	)
+	for _, b := range r.Blockers {
+		sev := th.Token(render.Warn, b.Severity)
+		switch b.Severity {
+		case health.DrainSeverityBlock:
+			sev = th.Token(render.Fail, b.Severity)
+		case health.DrainSeverityInfo:
+			sev = th.Token(render.Neutral, b.Severity)
+		}
+		tbl.Row(
+			sev,
+			th.Paint(pal.White, b.Namespace+"/"+b.Pod),
+			th.Paint(pal.Text, b.Node),
+			th.Paint(pal.Text, b.Reason),
+			th.Paint(pal.Subtext, b.Detail),
+		)
+	}
+	out = append(out, tbl.Render()...)
+
+	summary := fmt.Sprintf("%d blocking, %d warning(s)", r.Blocking(), r.Warnings())
+	switch {
+	case r.Blocking() > 0:
+		out = append(out, "", th.Token(render.Fail, summary+" — the roll will stall until the blocking pods can be evicted"))
+	case r.Warnings() > 0:
+		out = append(out, "", th.Token(render.Warn, summary+" — the drain proceeds, at the cost shown"))
+	default:
+		out = append(out, "", th.Token(render.Healthy, summary+" — nothing would stall or be hurt; the notes above are for reference"))
+	}
+	if hints := drainHints(r); len(hints) > 0 {
+		out = append(out, "", th.Paint(pal.Dim, "To unblock:"))
+		for _, h := range hints {
+			out = append(out, th.Paint(pal.Dim, "  "+h))
+		}
+	}
+	return out
+}
+
+// drainHints suggests a fix per blocking reason present in the report.
+func drainHints(r health.DrainReport) []string {
+	seen := map[string]bool{}
+	for _, b := range r.Blockers {
+		seen[b.Reason] = true
+	}
+	var hints []string
+	if seen[health.DrainReasonPDB] {
+		hints = append(hints, "pdb: scale the workload up or relax minAvailable/maxUnavailable so the PDB allows a disruption")
+	}
+	if seen[health.DrainReasonMultiplePDBs] {
+		hints = append(hints, "multiple-pdbs: narrow the PDB selectors so each pod matches exactly one")
+	}
+	return hints
+}
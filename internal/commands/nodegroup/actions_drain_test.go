@@ -0,0 +1,65 @@
+package nodegroup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+func sampleDrainReport() health.DrainReport {
+	return health.DrainReport{
+		Nodegroup: "workers",
+		Nodes:     []string{"ip-10-0-1-5", "ip-10-0-2-7"},
+		Pods:      14,
+		Blockers: []health.DrainBlocker{
+			{Severity: health.DrainSeverityBlock, Reason: health.DrainReasonPDB, Namespace: "payments", Pod: "api-5c7d-x2", Node: "ip-10-0-1-5", Detail: "PDB api allows 0 disruptions (healthy 2/2 desired)"},
+			{Severity: health.DrainSeverityWarn, Reason: health.DrainReasonSingleReplica, Namespace: "tools", Pod: "grafana-77f-q", Node: "ip-10-0-2-7", Detail: "single-replica Deployment: unavailable until rescheduled"},
+		},
+	}
+}
+
+func TestDrainCheckLines(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	joined := strings.Join(drainCheckLines(th, "prod", sampleDrainReport()), "\n")
+	for _, want := range []string{
+		"DRAIN CHECK  prod/workers · 2 node(s) · 14 pod(s) to evict",
+		"✗ block",
+		"▲ warn",
+		"payments/api-5c7d-x2",
+		"1 blocking, 1 warning(s) — the roll will stall",
+		"pdb: scale the workload up",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("drain-check output missing %q in:\n%s", want, joined)
+		}
+	}
+
+	clean := health.DrainReport{Nodegroup: "workers", Nodes: []string{"a"}}
+	if joined := strings.Join(drainCheckLines(th, "prod", clean), "\n"); !strings.Contains(joined, "● Nothing would stall") {
+		t.Errorf("clean report:\n%s", joined)
+	}
+}
+
+func TestDrainCheckExit(t *testing.T) {
+	r := sampleDrainReport()
+	var exit cli.ExitCoder
+	if err := drainCheckExit(r); !errors.As(err, &exit) || exit.ExitCode() != 3 {
+		t.Errorf("blocking: err = %v, want exit 3", err)
+	}
+	r.Blockers = r.Blockers[1:]
+	if err := drainCheckExit(r); !errors.As(err, &exit) || exit.ExitCode() != 2 {
+		t.Errorf("warnings only: err = %v, want exit 2", err)
+	}
+	if err := drainCheckExit(health.DrainReport{}); err != nil {
+		t.Errorf("clean: err = %v", err)
+	}
+	r.Blockers = []health.DrainBlocker{{Severity: health.DrainSeverityInfo, Reason: health.DrainReasonLocalStorage}}
+	if err := drainCheckExit(r); err != nil {
+		t.Errorf("notes only: err = %v", err)
+	}
+}
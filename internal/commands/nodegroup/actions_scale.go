@@ -11,6 +11,7 @@ import (
 	"github.com/urfave/cli/v3"
 
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awscache"
 	"github.com/dantech2000/refresh/internal/commands/factory"
 	"github.com/dantech2000/refresh/internal/commands/runner"
 	"github.com/dantech2000/refresh/internal/health"
@@ -19,7 +20,8 @@ import (
 )
 
 func runScale(ctx context.Context, cmd *cli.Command) error {
-	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	// Scaling checks the nodegroup's current size and limits first; read them live.
+	ctx, cancel, awsCfg, err := runner.SetupAWS(awscache.Bypass(ctx), cmd)
 	if err != nil {
 		return err
 	}
@@ -48,6 +50,9 @@ func runScale(ctx context.Context, cmd *cli.Command) error {
 		Wait:        cmd.Bool("wait"),
 		Timeout:     cmd.Duration("op-timeout"),
 		DryRun:      cmd.Bool("dry-run"),
+		HealthOptions: func(cluster string) (health.Options, error) {
+			return runner.HealthOptions(cmd, cluster)
+		},
 	}
 
 	desired, err := int32PtrIfSet(cmd, "desired")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -19,12 +20,16 @@ import (
 	"github.com/urfave/cli/v3"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/dantech2000/refresh/internal/amichangelog"
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awscache"
 	"github.com/dantech2000/refresh/internal/commands/factory"
 	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/customami"
 	"github.com/dantech2000/refresh/internal/dryrun"
 	"github.com/dantech2000/refresh/internal/health"
 	"github.com/dantech2000/refresh/internal/monitoring"
+	"github.com/dantech2000/refresh/internal/noderoll"
 	"github.com/dantech2000/refresh/internal/rollview"
 	"github.com/dantech2000/refresh/internal/services/common"
 	refreshTypes "github.com/dantech2000/refresh/internal/types"
@@ -35,15 +40,38 @@ import (
 type updateAMIFlags struct {
 	force, dryRun, noWait, quiet, skipHealthCheck, healthOnly bool
 	yes, requireHealthy, skipVerify, changelog, live          bool
+	promptStuck                                               bool
 	timeout, pollInterval                                     time.Duration
 	format                                                    string
 	kubeconfig                                                string
+	record                                                    string
+	// recorder captures the roll when --record is set (nil otherwise).
+	recorder *noderoll.Recorder
+	// pin is --release-version / --match-cluster; pins is its validated
+	// release per selected nodegroup, resolved per cluster (nil when unpinned).
+	pin  releasePin
+	pins map[string]awsinternal.AMIRelease
+	// customAMI resolves --custom-ami / --custom-ami-owner for a cluster;
+	// custom is its result for the cluster being rolled (nil leaves
+	// custom-AMI nodegroups alone).
+	customAMI func(cluster string) (*customami.Source, error)
+	custom    *customami.Source
+	// releaseNotes serves the amazon-eks-ami release notes behind changelogs,
+	// fetched once per run; severities grades their CVEs (--cve-severity,
+	// validated before flags are read).
+	releaseNotes amichangelog.Source
+	severities   amichangelog.Severities
+	// healthOptions resolves the health thresholds, check selection and
+	// blocking overrides for one cluster (refresh.yaml scopes + flags).
+	healthOptions func(cluster string) (health.Options, error)
 }
 
 func readUpdateAMIFlags(cmd *cli.Command) updateAMIFlags {
+	severities, _ := amichangelog.LoadSeverities(cmd.String("cve-severity"))
 	// Flags placed after positional args (e.g. `update-ami my-cluster
 	// --health-only`) are parsed natively by urfave/cli v3.
 	return updateAMIFlags{
+		pin:             releasePin{version: strings.TrimSpace(cmd.String("release-version")), cluster: strings.TrimSpace(cmd.String("match-cluster"))},
 		force:           cmd.Bool("force"),
 		dryRun:          cmd.Bool("dry-run"),
 		noWait:          cmd.Bool("no-wait"),
@@ -55,10 +83,30 @@ func readUpdateAMIFlags(cmd *cli.Command) updateAMIFlags {
 		skipVerify:      cmd.Bool("skip-verify"),
 		changelog:       cmd.Bool("changelog"),
 		live:            cmd.Bool("live"),
+		promptStuck:     cmd.Bool("prompt-stuck-pods"),
 		timeout:         cmd.Duration("timeout"),
 		pollInterval:    cmd.Duration("poll-interval"),
 		format:          strings.ToLower(cmd.String("format")),
 		kubeconfig:      cmd.String("kubeconfig"),
+		record:          cmd.String("record"),
+		releaseNotes:    amichangelog.Default(),
+		severities:      severities,
+		healthOptions: func(cluster string) (health.Options, error) {
+			return runner.HealthOptions(cmd, cluster)
+		},
+		customAMI: func(cluster string) (*customami.Source, error) {
+			return customAMISource(cmd, cluster)
+		},
+	}
+}
+
+// dryRunOptions carries the pins and custom-AMI source into the preview.
+func (f updateAMIFlags) dryRunOptions(awsCfg aws.Config, eksClient *eks.Client) dryrun.Options {
+	return dryrun.Options{
+		Force:     f.force,
+		Quiet:     f.quiet,
+		Pins:      f.pins,
+		CustomAMI: customDryRun(newCustomRoller(awsCfg, eksClient, f.custom)),
 	}
 }
 
@@ -85,11 +133,27 @@ func runUpdateAMI(ctx context.Context, cmd *cli.Command) error {
 	if cmd.Bool("simulate") {
 		return rollview.SimulatedRoll(ctx, cmd.String("nodegroup"))
 	}
+	if err := readUpdateAMIFlags(cmd).pin.validate(); err != nil {
+		return err
+	}
+	if _, err := customAMISource(cmd, ""); err != nil {
+		return err
+	}
+	if _, err := amichangelog.LoadSeverities(cmd.String("cve-severity")); err != nil {
+		return err
+	}
 	if cmd.Bool("all-clusters") {
+		if cmd.String("record") != "" {
+			return fmt.Errorf("--record captures a single cluster's roll; it can't be combined with --all-clusters")
+		}
 		return runFleetUpdate(ctx, cmd)
 	}
+	if cmd.String("selector") != "" || cmd.String("group") != "" {
+		return fmt.Errorf("--selector and --group pick clusters for --all-clusters; use -c for a single cluster")
+	}
 
-	ctx, cancel, awsCfg, err := runner.SetupAWSWithTimeout(ctx, cmd, 60*time.Second)
+	// Every gate of a roll (status, versions, health) is read live.
+	ctx, cancel, awsCfg, err := runner.SetupAWSWithTimeout(awscache.Bypass(ctx), cmd, 60*time.Second)
 	if err != nil {
 		return err
 	}
@@ -103,8 +167,11 @@ func runUpdateAMI(ctx context.Context, cmd *cli.Command) error {
 	}
 	eksClient := eks.NewFromConfig(awsCfg)
 	flags := readUpdateAMIFlags(cmd)
+	if flags.custom, err = flags.customAMI(clusterName); err != nil {
+		return err
+	}
 
-	done, err := preflightHealthCheck(ctx, awsCfg, eksClient, clusterName, flags)
+	done, err := preflightHealthCheck(ctx, awsCfg, eksClient, clusterName, nodegroupPattern, flags)
 	if err != nil || done {
 		return err
 	}
@@ -123,12 +190,17 @@ func runUpdateAMI(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
+	// A pinned release is validated before anything is previewed or started.
+	if flags.pins, err = resolvePins(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags.pin); err != nil {
+		return err
+	}
+
 	if flags.dryRun {
-		if derr := dryrun.PerformDryRun(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags.force, flags.quiet); derr != nil {
+		if derr := dryrun.PerformDryRunWith(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags.dryRunOptions(awsCfg, eksClient)); derr != nil {
 			return derr
 		}
 		if !flags.quiet {
-			printChangelogsForNodegroups(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags.changelog)
+			printChangelogsForNodegroups(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags)
 		}
 		return nil
 	}
@@ -136,6 +208,16 @@ func runUpdateAMI(ctx context.Context, cmd *cli.Command) error {
 	jsonOut := flags.format == "json" && !flags.healthOnly
 	quiet := flags.quiet || jsonOut
 
+	if flags.record != "" {
+		rec, err := noderoll.CreateRecorder(flags.record)
+		if err != nil {
+			return err
+		}
+		rec.Meta(clusterName, selectedNodegroups)
+		flags.recorder = rec
+		defer closeRecording(rec, flags.record, quiet)
+	}
+
 	outcomes, verifyFailed, monErr := executeUpdates(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, flags)
 
 	if jsonOut {
@@ -197,20 +279,48 @@ func executeUpdates(ctx context.Context, awsCfg aws.Config, eksClient *eks.Clien
 		NoWait:          flags.noWait,
 		Timeout:         flags.timeout,
 	}
+	if rec := flags.recorder; rec != nil {
+		config.OnPoll = func(u refreshTypes.UpdateProgress) {
+			rec.Status(u.NodegroupName, noderoll.StatusPoll{
+				UpdateID: u.UpdateID, Status: string(u.Status), Error: u.ErrorMessage, PollError: u.LastCheckError,
+			})
+		}
+	}
 	// Live per-node roll view — now the DEFAULT for an interactive single-nodegroup
 	// roll (nodes draining/joining/terminating, pod eviction, warnings). Purely
 	// visual: EKS DescribeUpdate (below) stays authoritative for the result, and a
 	// missing/unreachable cluster API degrades silently to the standard monitor.
 	// The kube client is resolved quietly by default; --live makes the fallback
 	// reason explicit when the cluster can't be reached. (REF-126)
+	livePanel := false
 	if len(updates) == 1 && !quiet {
 		if kube := resolveHealthKubeClient(ctx, flags.kubeconfig, flags.live); kube != nil {
-			rollview.LiveRollForUpdate(ctx, kube, updates[0].NodegroupName, flags.timeout, flags.pollInterval)
+			rollview.LiveRollForUpdate(ctx, kube, updates[0].NodegroupName, flags.timeout, flags.pollInterval,
+				rollview.LiveRollOptions{PromptStuck: flags.promptStuck && isInteractive(), Record: flags.recorder})
 			monitor.Quiet, config.Quiet = true, true
+			livePanel = true
+		}
+	}
+	// Without a panel, watch the rolls headlessly: with -o json to report the
+	// pods that held drains up in the run summary, with --record to capture
+	// the nodes.
+	jsonOut := flags.format == "json" && !flags.healthOnly
+	var stalls *rollview.StallLog
+	stopWatch := func() {}
+	if jsonOut || (flags.recorder != nil && !livePanel) {
+		if kube := resolveHealthKubeClient(ctx, flags.kubeconfig, false); kube != nil {
+			if jsonOut {
+				stalls = &rollview.StallLog{}
+			}
+			stopWatch = watchRolls(ctx, kube, updates, flags.pollInterval, stalls, flags.recorder)
 		}
 	}
 
 	monErr := monitoring.MonitorUpdates(ctx, eksClient, monitor, config)
+	stopWatch()
+	if stalls != nil {
+		outcomes.DrainStalls = stalls.Stalls()
+	}
 
 	verifyFailed := false
 	if verify && monErr == nil && len(outcomes.Started) > 0 {
@@ -221,6 +331,36 @@ func executeUpdates(ctx context.Context, awsCfg aws.Config, eksClient *eks.Clien
 	return outcomes, verifyFailed, monErr
 }
 
+// watchRolls runs a headless roll watcher per update until the returned stop
+// func is called (which waits for them to exit).
+func watchRolls(ctx context.Context, kube kubernetes.Interface, updates []refreshTypes.UpdateProgress, interval time.Duration, log *rollview.StallLog, rec *noderoll.Recorder) (stop func()) {
+	wctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, u := range updates {
+		wg.Add(1)
+		go func(ng string) {
+			defer wg.Done()
+			rollview.WatchRoll(wctx, kube, ng, interval, log, rec)
+		}(u.NodegroupName)
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// closeRecording finishes a --record file, warning (never failing the run)
+// when it couldn't be written in full.
+func closeRecording(rec *noderoll.Recorder, path string, quiet bool) {
+	if err := rec.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: roll recording %s is incomplete: %v\n", path, err)
+		return
+	}
+	if !quiet {
+		fmt.Printf("Roll recorded to %s (replay: refresh roll replay %s)\n", path, path)
+	}
+}
+
 // printVerification renders the post-roll verification block.
 func printVerification(v PostRollVerification) {
 	if v.OK() {
@@ -255,9 +395,11 @@ func updateExit(o updateOutcomes, monErr error, verifyFailed bool) error {
 	return nil
 }
 
-// preflightHealthCheck runs the pre-update health checks. Returns done=true if
-// the caller should stop here (block decision, user cancelled, or --health-only).
-func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName string, flags updateAMIFlags) (done bool, err error) {
+// preflightHealthCheck runs the pre-update health checks, including a
+// scheduling simulation of rolling the nodegroups nodegroupPattern matches.
+// Returns done=true if the caller should stop here (block decision, user
+// cancelled, or --health-only).
+func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName, nodegroupPattern string, flags updateAMIFlags) (done bool, err error) {
 	if flags.skipHealthCheck || flags.dryRun || flags.force {
 		if flags.healthOnly {
 			color.Yellow("Health check skipped due to --skip-health-check, --dry-run, or --force flags")
@@ -265,6 +407,12 @@ func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks
 		}
 		return false, nil
 	}
+	opts := health.DefaultOptions()
+	if flags.healthOptions != nil {
+		if opts, err = flags.healthOptions(clusterName); err != nil {
+			return true, err
+		}
+	}
 
 	// Machine-readable verdicts suppress all human chrome so stdout is pure
 	// data; the exit code still encodes the decision (0/2/3).
@@ -277,6 +425,9 @@ func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks
 	asgClient := autoscaling.NewFromConfig(awsCfg)
 	k8sClient := resolveHealthKubeClient(ctx, flags.kubeconfig, humanOutput)
 	checker := health.NewChecker(eksClient, k8sClient, cwClient, asgClient)
+	if err := checker.SetOptions(opts); err != nil {
+		return true, err
+	}
 	// Attach metrics-server (best-effort) for live CPU+memory drain headroom; the
 	// utilization check skips cleanly if it isn't installed. (REF-142)
 	if k8sClient != nil {
@@ -287,6 +438,11 @@ func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks
 	// EC2 vCPU quota headroom — a roll surges new nodes against the account
 	// quota; the check skips cleanly if it can't read the limit/usage. (REF-144)
 	checker.SetServiceQuotas(servicequotas.NewFromConfig(awsCfg))
+	// Will the drained pods fit? Simulate the roll against live pod requests
+	// and placement constraints (needs the cluster API).
+	if k8sClient != nil {
+		checker.SetDrainPlans(rollDrainPlans(ctx, eksClient, clusterName, nodegroupPattern, flags.custom != nil))
+	}
 
 	spinner := ui.NewFunSpinnerForCategory("health")
 	if humanOutput {
@@ -311,6 +467,38 @@ func preflightHealthCheck(ctx context.Context, awsCfg aws.Config, eksClient *eks
 	return applyHealthDecision(summary, flags)
 }
 
+// rollDrainPlans describes the roll of every nodegroup matching pattern for
+// the scheduling simulation. It covers all matches, since an ambiguous pattern
+// is only narrowed by the prompt after the health gate. Custom-AMI nodegroups
+// are included only when --custom-ami rolls them. Best-effort: a nodegroup
+// that can't be described is left out.
+func rollDrainPlans(ctx context.Context, eksClient *eks.Client, clusterName, pattern string, includeCustom bool) []health.DrainPlan {
+	names, err := awsinternal.ListAllPages(ctx, "listing nodegroups",
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return eksClient.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return nil
+	}
+	described := common.ForEachParallel(ctx, awsinternal.MatchingNodegroups(names, pattern), common.DefaultItemConcurrency,
+		func(fctx context.Context, ng string) *ekstypes.Nodegroup {
+			out, err := eksClient.DescribeNodegroup(fctx, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(ng)})
+			if err != nil || out.Nodegroup == nil {
+				return nil
+			}
+			return out.Nodegroup
+		})
+	var plans []health.DrainPlan
+	for _, ng := range described {
+		if ng != nil && (includeCustom || ng.AmiType != ekstypes.AMITypesCustom) {
+			plans = append(plans, health.RollDrainPlan(ng))
+		}
+	}
+	return plans
+}
+
 // healthExitError maps a health decision to the --health-only exit-code
 // contract: 0 = pass, 2 = warnings, 3 = blocked. Messages go to stderr via
 // urfave/cli, keeping stdout pure data for JSON/YAML output.
@@ -413,7 +601,11 @@ func selectNodegroupsForUpdate(ctx context.Context, eksClient *eks.Client, clust
 
 // startNodegroupUpdates issues UpdateNodegroupVersion for each selected
 // nodegroup that isn't already updating or already on the latest AMI,
-// returning successful update progress entries. Per-nodegroup failures are
+// returning successful update progress entries. A nodegroup pinned to a release
+// (--release-version / --match-cluster) rolls to that release instead, and is
+// skipped when it already runs it. Custom-AMI nodegroups roll onto the
+// --custom-ami image through a new launch template version, or are skipped
+// with guidance when no source is set. Per-nodegroup failures are
 // logged and skipped, matching the original best-effort behavior.
 //
 // The already-on-latest skip mirrors the dry-run preview (ActionSkipLatest) so
@@ -424,14 +616,25 @@ type updateOutcomes struct {
 	Cluster      string                `json:"cluster"`
 	Started      []string              `json:"started"`
 	Skipped      []string              `json:"skipped"`         // already on latest, or already updating
-	Custom       []string              `json:"customUnmanaged"` // custom-AMI nodegroups (managed via LT)
+	Custom       []string              `json:"customUnmanaged"` // custom-AMI nodegroups left alone (no --custom-ami)
 	Failed       []string              `json:"failed"`          // describe or UpdateNodegroupVersion failed
 	Verification *PostRollVerification `json:"verification,omitempty"`
+	// DrainStalls are the pods that held up a node drain during the roll and
+	// why (-o json only; the live panel shows them as they happen).
+	DrainStalls []noderoll.StuckPod `json:"drainStalls,omitempty"`
+	// Changelogs is the AMI changelog of each started nodegroup, keyed by
+	// name, with the CVEs and package bumps the roll picks up (-o json only).
+	Changelogs map[string]amichangelog.Changelog `json:"changelogs,omitempty"`
 }
 
 func startNodegroupUpdates(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName string, nodegroups []string, flags updateAMIFlags) ([]refreshTypes.UpdateProgress, updateOutcomes) {
 	skipLatest := newLatestAMISkipChecker(ctx, awsCfg, eksClient, clusterName, flags)
+	roller := newCustomRoller(awsCfg, eksClient, flags.custom)
 	human := !flags.quiet && flags.format != "json"
+	var notes *changelogs
+	if flags.format == "json" {
+		notes = newChangelogs(ctx, awsCfg, eksClient, clusterName, flags)
+	}
 
 	outcomes := updateOutcomes{Cluster: clusterName}
 	updates := make([]refreshTypes.UpdateProgress, 0, len(nodegroups))
@@ -452,10 +655,11 @@ func startNodegroupUpdates(ctx context.Context, awsCfg aws.Config, eksClient *ek
 		}
 		// Custom-AMI nodegroups: EKS doesn't manage the AMI (it lives in the
 		// user's launch template), so UpdateNodegroupVersion can't pick a
-		// recommended AMI. Skip with clear guidance instead of mis-rolling.
-		if desc.Nodegroup.AmiType == ekstypes.AMITypesCustom {
+		// recommended AMI. Without --custom-ami, skip with clear guidance
+		// instead of mis-rolling.
+		if desc.Nodegroup.AmiType == ekstypes.AMITypesCustom && roller == nil {
 			color.Yellow("Nodegroup %s uses a custom AMI (AmiType=CUSTOM); refresh can't select a recommended AMI.", ng)
-			color.Yellow("  Publish a new launch template version with your AMI and roll it (e.g. update the LT, then `nodegroup update --force`).")
+			color.Yellow("  Roll it onto your image with --custom-ami (ssm:<parameter>, name:<pattern> or an ami- ID).")
 			outcomes.Custom = append(outcomes.Custom, ng)
 			continue
 		}
@@ -464,23 +668,43 @@ func startNodegroupUpdates(ctx context.Context, awsCfg aws.Config, eksClient *ek
 			outcomes.Skipped = append(outcomes.Skipped, ng)
 			continue
 		}
-		if skipLatest(desc.Nodegroup) {
+		if desc.Nodegroup.AmiType == ekstypes.AMITypesCustom {
+			if u, ok := startCustomRoll(ctx, roller, clusterName, desc.Nodegroup, flags, human, &outcomes); ok {
+				updates = append(updates, u)
+			}
+			continue
+		}
+		pin, pinned := flags.pins[ng]
+		switch {
+		case pinned && !flags.force && aws.ToString(desc.Nodegroup.ReleaseVersion) == pin.ReleaseVersion:
+			color.Green("Nodegroup %s is already on pinned release %s. Skipping (use --force to update anyway).", ng, pin.ReleaseVersion)
+			outcomes.Skipped = append(outcomes.Skipped, ng)
+			continue
+		case !pinned && skipLatest(desc.Nodegroup):
 			color.Green("Nodegroup %s is already on the latest AMI. Skipping (use --force to update anyway).", ng)
 			outcomes.Skipped = append(outcomes.Skipped, ng)
 			continue
 		}
 		if human {
-			color.Cyan("Starting update for nodegroup %s...", ng)
+			if pinned {
+				color.Cyan("Starting update for nodegroup %s to release %s...", ng, pin.ReleaseVersion)
+			} else {
+				color.Cyan("Starting update for nodegroup %s...", ng)
+			}
 		}
 
 		// ClientRequestToken makes the mutating call idempotent: a retry (or a
 		// fleet run that revisits a cluster) won't trigger a second AMI rollout.
-		resp, err := eksClient.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{
+		input := &eks.UpdateNodegroupVersionInput{
 			ClusterName:        aws.String(clusterName),
 			NodegroupName:      aws.String(ng),
 			Force:              flags.force,
 			ClientRequestToken: aws.String(common.IdempotencyToken()),
-		})
+		}
+		if pinned {
+			input.ReleaseVersion = aws.String(pin.ReleaseVersion)
+		}
+		resp, err := eksClient.UpdateNodegroupVersion(ctx, input)
 		if err != nil {
 			color.Red("Failed to update nodegroup %s: %v", ng, err)
 			outcomes.Failed = append(outcomes.Failed, ng)
@@ -502,6 +726,12 @@ func startNodegroupUpdates(ctx context.Context, awsCfg aws.Config, eksClient *ek
 			LastChecked:   now,
 		})
 		outcomes.Started = append(outcomes.Started, ng)
+		if cl, ok := notes.forNodegroup(ctx, desc.Nodegroup, flags.pins); ok {
+			if outcomes.Changelogs == nil {
+				outcomes.Changelogs = map[string]amichangelog.Changelog{}
+			}
+			outcomes.Changelogs[ng] = cl
+		}
 		if human {
 			color.Green("Update started for nodegroup %s (ID: %s)", ng, *resp.Update.Id)
 		}
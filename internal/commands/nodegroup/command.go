@@ -6,24 +6,27 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/commands/runner"
 	appconfig "github.com/dantech2000/refresh/internal/config"
 )
 
-// Command returns the nodegroup command group with list, describe, scale, and
-// update subcommands.
+// Command returns the nodegroup command group with list, describe, scale,
+// drain-check, and update subcommands.
 func Command() *cli.Command {
 	return &cli.Command{
 		Name:    "nodegroup",
 		Aliases: []string{"ng"},
-		Usage:   "Nodegroup operations (list, get, scale, update)",
+		Usage:   "Nodegroup operations (list, get, scale, drain-check, update)",
 		Description: `Inspect and operate on a cluster's managed nodegroups: list them with AMI
 freshness, describe one in depth, scale desired/min/max size (with optional PDB
-and health gating), and update (roll) nodegroups to the latest recommended AMI
-with pre-flight health checks and live monitoring.`,
+and health gating), check what would stall draining one, and update (roll)
+nodegroups to the latest recommended AMI with pre-flight health checks and live
+monitoring.`,
 		Commands: []*cli.Command{
 			listCommand(),
 			describeCommand(),
 			scaleCommand(),
+			drainCheckCommand(),
 			updateAMICommand(),
 		},
 	}
@@ -95,13 +98,15 @@ func scaleCommand() *cli.Command {
 --desired/--min/--max may be set; unspecified bounds are left unchanged.
 
 --check-pdbs validates Pod Disruption Budgets before scaling down so you don't
-strand workloads; --health-check validates cluster health before and after;
+strand workloads; --health-check validates cluster health before and after,
+and on a scale-down simulates whether the removed nodes' pods fit on the rest
+and which would stall the drain;
 --dry-run previews the impact without executing; --wait blocks until the
 operation settles.
 
   refresh nodegroup scale my-cluster -n ng-default --desired 5
   refresh nodegroup scale my-cluster -n ng-default --desired 2 --check-pdbs --wait`,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout (e.g. 60s, 2m)", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
 			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name"},
 			&cli.StringFlag{Name: "nodegroup", Aliases: []string{"n"}, Usage: "Nodegroup name", Required: true},
@@ -114,11 +119,47 @@ operation settles.
 			&cli.DurationFlag{Name: "op-timeout", Usage: "Scaling operation timeout", Value: 5 * time.Minute},
 			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig for workload/PDB health checks (defaults to $KUBECONFIG, then ~/.kube/config)"},
 			&cli.BoolFlag{Name: "dry-run", Usage: "Preview scaling impact without executing"},
-		},
+		}, runner.HealthFlags()...),
 		Action: runScale,
 	}
 }
 
+func drainCheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "drain-check",
+		Usage:     "Report the pods that would stall or be hurt by draining a nodegroup",
+		ArgsUsage: "[cluster]",
+		Description: `Map every pod on the nodegroup's nodes to the PodDisruptionBudgets whose
+selectors match it and report exactly what would stall a roll or scale-down
+and why. Blocking findings stall the drain:
+
+  pdb             the only PDB covering the pod allows 0 disruptions
+  multiple-pdbs   the pod matches more than one PDB (the eviction API refuses it)
+
+Warnings drain, at a cost:
+
+  unmanaged       no controller, so nothing recreates the pod
+  safe-to-evict   annotated cluster-autoscaler.kubernetes.io/safe-to-evict=false
+  local-storage   emptyDir/hostPath data is lost on eviction
+  single-replica  the Deployment has one replica, so it is down until rescheduled
+
+DaemonSet and mirror pods are skipped. Exit code: 0 clean, 2 warnings only,
+3 blocking findings. The same analysis runs as the "drain" pre-flight check of
+nodegroup update and a nodegroup scale-down with --health-check.
+
+  refresh nodegroup drain-check my-cluster -n ng-default
+  refresh nodegroup drain-check -c my-cluster -n ng-default -o json`,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout (e.g. 60s, 2m)", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
+			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name"},
+			&cli.StringFlag{Name: "nodegroup", Aliases: []string{"n"}, Usage: "Nodegroup name", Required: true},
+			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig for the cluster API (defaults to $KUBECONFIG, then ~/.kube/config)"},
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml)", Value: "table"},
+		},
+		Action: runDrainCheck,
+	}
+}
+
 func updateAMICommand() *cli.Command {
 	return &cli.Command{
 		Name:      "update",
@@ -128,8 +169,28 @@ func updateAMICommand() *cli.Command {
 		Description: `Roll managed nodegroups to the latest recommended AMI, with pre-flight
 health gates and live monitoring.
 
-Custom-AMI nodegroups (AmiType=CUSTOM) are skipped with guidance: their AMI is
-managed via the launch template, so publish a new LT version to roll them.
+Custom-AMI nodegroups (AmiType=CUSTOM) keep their AMI in the launch template,
+so they are skipped with guidance unless --custom-ami names the image to roll
+onto: an SSM parameter, an image name pattern (newest available image owned by
+--custom-ami-owner, default self) or an AMI ID. {version} in a parameter or
+pattern becomes the nodegroup's Kubernetes version. refresh publishes a launch
+template version that changes only the ImageId and rolls the nodegroup onto it
+behind the same health gates, monitoring and verification:
+   refresh nodegroup update -c prod --custom-ami ssm:/platform/ami/{version}/golden
+   refresh nodegroup update -c prod --custom-ami 'name:golden-eks-{version}-*' --custom-ami-owner 123456789012
+
+--release-version pins the roll to a published AMI release instead of the
+latest recommended one, and --match-cluster promotes whatever release a
+reference cluster runs (one per AMI type). Nodegroups already on the pinned
+release are skipped; the dry-run and changelog show the pinned target:
+   refresh nodegroup update -c prod --release-version 1.31.0-20260601 --dry-run
+   refresh nodegroup update -c prod --match-cluster staging --yes
+
+The changelog pulls the CVE IDs and kernel, containerd and runc versions out of
+every amazon-eks-ami release the roll picks up; -o json adds them to the run
+summary. --cve-severity grades the CVEs from a local file (CVE ID → critical,
+high, medium or low):
+   refresh nodegroup update -c prod --dry-run --cve-severity ~/.refresh/cves.yaml
 
 Fleet mode (--all-clusters) discovers clusters across regions (scope with -r)
 and rolls them serially with one batch confirmation, an aggregate summary, and a
@@ -137,6 +198,32 @@ worst-outcome exit code:
    refresh nodegroup update --all-clusters --dry-run        # fleet-wide plan
    refresh nodegroup update --all-clusters -r us-east-1 --yes
 
+Narrow the fleet by EKS tags with -l/--selector (kubectl-style) or a saved
+--group; each discovered cluster is described to read its tags:
+   refresh nodegroup update --all-clusters -l 'env=prod,team!=data' --dry-run
+
+Add --account-role (repeatable) or --org-role to sweep several AWS accounts;
+each role is assumed once and region discovery runs inside it. Accounts whose
+role can't be assumed are reported and left out of the run.
+
+The gate includes a scheduling simulation: the nodes of each matching nodegroup
+are drained maxUnavailable at a time onto the remaining and replacement nodes,
+and any pod that fits nowhere (requests, selectors, taints, affinity, topology
+spread) blocks the roll, as does a pod a PDB would keep from being evicted (see
+nodegroup drain-check). Both need the cluster API (--kubeconfig).
+
+During the roll the live panel lists the pods holding up each draining node —
+the PDB at 0 disruptions, finalizer, hung termination or volume detach behind
+each — with a suggested fix; --prompt-stuck-pods offers to delete one that has
+been stuck for 5 minutes. With -o json the same diagnosis lands in the run
+summary as drainStalls. --record roll.ndjson captures every node snapshot,
+lifecycle event and EKS status poll for 'refresh roll replay'.
+
+Health checks can be narrowed and tuned per run or per cluster (refresh.yaml
+clusters.<glob>.health); the effective thresholds are listed in each result:
+   refresh nodegroup update -c gpu-prod --threshold peakFailCPUPercent=98 --blocking capacity=false
+   refresh nodegroup update -c prod --skip-checks balance,quotas --health-only
+
 Unattended / CI use:
    --yes              skip confirmation prompts (multi-match selection, warnings)
    --require-healthy  treat warn-level health findings as a hard stop
@@ -148,7 +235,7 @@ Exit codes:
    3  health blocked     4  one or more nodegroup updates failed to start
 
 Example (cron): refresh nodegroup update -c prod --yes --require-healthy -o json`,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or partial name pattern (overrides kubeconfig)", Sources: cli.EnvVars("EKS_CLUSTER_NAME")},
 			&cli.StringFlag{Name: "nodegroup", Aliases: []string{"n"}, Usage: "Nodegroup name or partial name pattern (if not set, update all)"},
 			&cli.BoolFlag{Name: "all-clusters", Usage: "Fleet mode: roll matching nodegroups across all discovered clusters (serial). Scope with -r."},
@@ -164,7 +251,12 @@ Example (cron): refresh nodegroup update -c prod --yes --require-healthy -o json
 			&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Assume yes: skip confirmation prompts (multi-match selection, warn-level health) for unattended/CI use"},
 			&cli.BoolFlag{Name: "require-healthy", Usage: "Treat warn-level health findings as a hard stop (exit 2) instead of prompting"},
 			&cli.BoolFlag{Name: "skip-verify", Usage: "Skip post-roll verification (nodes ACTIVE, no new stuck pods)"},
+			&cli.StringFlag{Name: "release-version", Usage: "Roll to this AMI release (e.g. 1.31.0-20260601) instead of the latest recommended one; validated against the releases published for each nodegroup's AMI type"},
+			&cli.StringFlag{Name: "match-cluster", Usage: "Roll to the AMI release(s) this reference cluster's nodegroups run, per AMI type (same account and region)"},
+			&cli.StringFlag{Name: "custom-ami", Usage: "Roll custom-AMI nodegroups onto this image: ssm:<parameter>, name:<pattern> or an ami- ID ({version} = the nodegroup's Kubernetes version)"},
+			&cli.StringSliceFlag{Name: "custom-ami-owner", Usage: "Account ID or alias owning name:<pattern> images (repeatable; default self)"},
 			&cli.BoolFlag{Name: "changelog", Usage: "In dry-run, print full amazon-eks-ami release notes between the current and target AMI"},
+			&cli.StringFlag{Name: "cve-severity", Usage: "YAML/JSON file mapping CVE IDs to critical, high, medium or low, used to grade the CVEs in AMI changelogs", Sources: cli.EnvVars("REFRESH_CVE_SEVERITY")},
 			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig for workload/PDB health checks (defaults to $KUBECONFIG, then ~/.kube/config)"},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format: health results with --health-only; a JSON run summary with -o json", Value: "table"},
 			// The real-time per-node roll panel (driven from live Kubernetes
@@ -173,11 +265,13 @@ Example (cron): refresh nodegroup update -c prod --yes --require-healthy -o json
 			// reachable. --live forces it and reports the fallback reason. EKS
 			// DescribeUpdate stays authoritative for the result. (REF-126)
 			&cli.BoolFlag{Name: "live", Usage: "Force the live per-node roll view and report why if the cluster API can't be reached (the panel is already the default for an interactive single-nodegroup roll)"},
+			&cli.StringFlag{Name: "record", Usage: "Record every observed node snapshot, lifecycle event and EKS status poll to this NDJSON file (replay with 'refresh roll replay')"},
+			&cli.BoolFlag{Name: "prompt-stuck-pods", Usage: "In the live roll view, offer to delete a pod that has held a drain for 5m (PDB-blocked or hung terminating), once per pod"},
 			// --simulate drives the live node-roll panel from a scripted observer
 			// (no AWS, no cluster) — for demos, asciinema, and manual QA of the
 			// live view. Hidden: it's a dev/demo aid, not a real operation.
 			&cli.BoolFlag{Name: "simulate", Hidden: true, Usage: "Demo the live node-roll panel with simulated data (no AWS)"},
-		},
+		}, append(append(runner.HealthFlags(), runner.SelectorFlags()...), runner.AccountFlags()...)...),
 		Action: runUpdateAMI,
 	}
 }
@@ -0,0 +1,120 @@
+package nodegroup
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/customami"
+	"github.com/dantech2000/refresh/internal/dryrun"
+	refreshTypes "github.com/dantech2000/refresh/internal/types"
+)
+
+// customAMISource reads --custom-ami and --custom-ami-owner for one cluster.
+// An empty cluster reads the flags as parsed; otherwise refresh.yaml's
+// clusters.<glob> scopes are resolved for it, so a fleet run can roll each
+// cluster onto its own image. nil means no source: custom-AMI nodegroups are
+// skipped.
+func customAMISource(cmd *cli.Command, cluster string) (*customami.Source, error) {
+	spec := []string{cmd.String("custom-ami")}
+	owners := cmd.StringSlice("custom-ami-owner")
+	if cluster != "" {
+		var err error
+		if spec, err = runner.ClusterFlag(cmd, cluster, "custom-ami", spec); err != nil {
+			return nil, err
+		}
+		if owners, err = runner.ClusterFlag(cmd, cluster, "custom-ami-owner", owners); err != nil {
+			return nil, err
+		}
+	}
+	if len(spec) == 0 || strings.TrimSpace(spec[0]) == "" {
+		return nil, nil
+	}
+	src, err := customami.ParseSource(spec[0], owners)
+	if err != nil {
+		return nil, err
+	}
+	return &src, nil
+}
+
+// newCustomRoller returns a roller for src, or nil when there is none.
+func newCustomRoller(awsCfg aws.Config, eksClient *eks.Client, src *customami.Source) *customami.Roller {
+	if src == nil {
+		return nil
+	}
+	return customami.NewRollerFromConfig(*src, awsCfg, eksClient)
+}
+
+// customDryRun previews custom-AMI nodegroups with roller (nil when unset).
+// The target is resolved for the nodegroup's own Kubernetes version, the
+// same as the real run.
+func customDryRun(roller *customami.Roller) dryrun.CustomAMIFunc {
+	if roller == nil {
+		return nil
+	}
+	return func(ctx context.Context, ng *ekstypes.Nodegroup) (string, string, error) {
+		target, err := roller.TargetImage(ctx, aws.ToString(ng.Version))
+		if err != nil {
+			return "", "", err
+		}
+		current, err := roller.CurrentImage(ctx, ng)
+		if err != nil {
+			return "", "", err
+		}
+		return current, target, nil
+	}
+}
+
+// startCustomRoll rolls one custom-AMI nodegroup onto the --custom-ami image,
+// recording its outcome. It is skipped when the launch template already runs
+// the target (unless --force), like a managed nodegroup on the latest AMI.
+func startCustomRoll(ctx context.Context, roller *customami.Roller, clusterName string, ng *ekstypes.Nodegroup, flags updateAMIFlags, human bool, outcomes *updateOutcomes) (refreshTypes.UpdateProgress, bool) {
+	name := aws.ToString(ng.NodegroupName)
+	target, err := roller.TargetImage(ctx, aws.ToString(ng.Version))
+	if err != nil {
+		color.Red("Nodegroup %s: %v", name, err)
+		outcomes.Failed = append(outcomes.Failed, name)
+		return refreshTypes.UpdateProgress{}, false
+	}
+	if !flags.force {
+		if current, err := roller.CurrentImage(ctx, ng); err == nil && current == target {
+			color.Green("Nodegroup %s is already on custom AMI %s. Skipping (use --force to update anyway).", name, target)
+			outcomes.Skipped = append(outcomes.Skipped, name)
+			return refreshTypes.UpdateProgress{}, false
+		}
+	}
+	if human {
+		color.Cyan("Starting update for nodegroup %s to custom AMI %s...", name, target)
+	}
+	rolled, err := roller.Roll(ctx, clusterName, ng, target, flags.force)
+	if err != nil {
+		color.Red("Failed to update nodegroup %s: %v", name, err)
+		outcomes.Failed = append(outcomes.Failed, name)
+		return refreshTypes.UpdateProgress{}, false
+	}
+	if rolled.Update == nil || rolled.Update.Id == nil {
+		color.Red("Update for nodegroup %s returned no update ID", name)
+		outcomes.Failed = append(outcomes.Failed, name)
+		return refreshTypes.UpdateProgress{}, false
+	}
+	outcomes.Started = append(outcomes.Started, name)
+	if human {
+		color.Green("Update started for nodegroup %s (ID: %s, launch template %s version %s)", name, *rolled.Update.Id, rolled.LaunchTemplate, rolled.Version)
+	}
+	now := time.Now()
+	return refreshTypes.UpdateProgress{
+		NodegroupName: name,
+		UpdateID:      *rolled.Update.Id,
+		ClusterName:   clusterName,
+		Status:        rolled.Update.Status,
+		StartTime:     now,
+		LastChecked:   now,
+	}, true
+}
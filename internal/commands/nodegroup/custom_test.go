@@ -0,0 +1,48 @@
+package nodegroup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func parseCustomAMICommand(t *testing.T, args ...string) *cli.Command {
+	t.Helper()
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	var captured *cli.Command
+	cmd := &cli.Command{
+		Name: "test",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "custom-ami"},
+			&cli.StringSliceFlag{Name: "custom-ami-owner"},
+		},
+		Action: func(_ context.Context, c *cli.Command) error {
+			captured = c
+			return nil
+		},
+	}
+	if err := cmd.Run(context.Background(), append([]string{"test"}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	return captured
+}
+
+func TestCustomAMISource(t *testing.T) {
+	if src, err := customAMISource(parseCustomAMICommand(t), ""); err != nil || src != nil {
+		t.Errorf("no flag: source = %v, %v; want none", src, err)
+	}
+	if _, err := customAMISource(parseCustomAMICommand(t, "--custom-ami", "golden"), ""); err == nil {
+		t.Error("expected an error for a source without a scheme")
+	}
+
+	cmd := parseCustomAMICommand(t, "--custom-ami", "name:golden-{version}-*", "--custom-ami-owner", "111111111111, 222222222222", "--custom-ami-owner", "self")
+	src, err := customAMISource(cmd, "prod")
+	if err != nil || src == nil {
+		t.Fatalf("source = %v, %v", src, err)
+	}
+	if src.NamePattern != "golden-{version}-*" || !reflect.DeepEqual(src.Owners, []string{"111111111111", "222222222222", "self"}) {
+		t.Errorf("source = %+v", src)
+	}
+}
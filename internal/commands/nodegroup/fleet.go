@@ -5,32 +5,51 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/accounts"
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awscache"
 	"github.com/dantech2000/refresh/internal/commands/runner"
 	appconfig "github.com/dantech2000/refresh/internal/config"
 	"github.com/dantech2000/refresh/internal/dryrun"
+	"github.com/dantech2000/refresh/internal/selector"
 	"github.com/dantech2000/refresh/internal/services/common"
 )
 
-// clusterTarget is a cluster to update plus the region-scoped AWS config to
-// reach it.
+// clusterTarget is a cluster to update plus the account- and region-scoped
+// AWS config to reach it. account is empty outside multi-account sweeps.
 type clusterTarget struct {
 	cluster string
+	account string
 	region  string
 	awsCfg  aws.Config
 }
 
+// where is the "(account/region)" label used in fleet headers and summaries.
+func (t clusterTarget) where() string {
+	return fleetLocation(t.account, t.region)
+}
+
+func fleetLocation(account, region string) string {
+	if account == "" {
+		return region
+	}
+	return account + "/" + region
+}
+
 // clusterUpdateResult is one cluster's outcome within a fleet run.
 type clusterUpdateResult struct {
 	Cluster       string         `json:"cluster" yaml:"cluster"`
+	Account       string         `json:"account,omitempty" yaml:"account,omitempty"`
 	Region        string         `json:"region" yaml:"region"`
 	Outcomes      updateOutcomes `json:"outcomes" yaml:"outcomes"`
 	HealthBlocked bool           `json:"healthBlocked" yaml:"healthBlocked"`
@@ -42,7 +61,12 @@ type clusterUpdateResult struct {
 // matching nodegroups serially (blast-radius control), with one batch
 // confirmation, an aggregate summary, and a worst-outcome exit code.
 func runFleetUpdate(ctx context.Context, cmd *cli.Command) error {
-	ctx, cancel, awsCfg, err := runner.SetupAWSWithTimeout(ctx, cmd, 60*time.Second)
+	sel, err := runner.ResolveSelector(cmd)
+	if err != nil {
+		return err
+	}
+	// Every gate of a roll (status, versions, health) is read live.
+	ctx, cancel, awsCfg, err := runner.SetupAWSWithTimeout(awscache.Bypass(ctx), cmd, 60*time.Second)
 	if err != nil {
 		return err
 	}
@@ -51,18 +75,58 @@ func runFleetUpdate(ctx context.Context, cmd *cli.Command) error {
 	flags := readUpdateAMIFlags(cmd)
 	nodegroupPattern := cmd.String("nodegroup")
 	jsonOut := flags.format == "json" && !flags.healthOnly
+	// Catch a bad --checks/--threshold before discovery rather than once per
+	// cluster; per-cluster refresh.yaml scopes are resolved again at each gate.
+	if _, err := flags.healthOptions(""); err != nil {
+		return err
+	}
+
+	// --match-cluster names a cluster in the base account and region; resolve
+	// it once, then validate the pin per cluster.
+	if err := flags.pin.load(ctx, eks.NewFromConfig(awsCfg)); err != nil {
+		return err
+	}
 
 	regions := resolveUpdateRegions(cmd, awsCfg)
-	targets, err := discoverFleetTargets(ctx, awsCfg, regions)
+	accts, acctErrs, err := runner.ResolveAccounts(ctx, cmd, awsCfg)
+	if err != nil {
+		return err
+	}
+	// An account whose role can't be assumed is left out of the run, not
+	// fatal — the same way an unreachable region drops out of discovery.
+	for _, e := range acctErrs {
+		color.Yellow("Warning: %v", e)
+	}
+	if len(accts) == 0 {
+		return fmt.Errorf("no account in the inventory could be assumed")
+	}
+	targets, err := discoverFleetTargets(ctx, accts, regions, sel)
 	if err != nil {
 		return err
 	}
 	if len(targets) == 0 {
+		if !sel.Empty() {
+			color.Yellow("No clusters matching %s found across %d region(s)", sel, len(regions))
+			return nil
+		}
 		color.Yellow("No clusters found across %d region(s)", len(regions))
 		return nil
 	}
 
+	// refresh.yaml canary settings: matching clusters roll first, and the rest
+	// wait for them to finish cleanly plus the soak period.
+	eff, err := runner.EffectiveSettings(cmd)
+	if err != nil {
+		return err
+	}
+	canary := eff.Canary()
+	soak, _ := canary.SoakDuration() // validated when refresh.yaml was loaded
+	targets, canaries := orderCanaryFirst(targets, canary.Clusters)
+
 	if flags.dryRun {
+		if canaries > 0 && !flags.quiet {
+			color.Cyan("Canary: %d cluster(s) roll first, then a %s soak", canaries, soak)
+		}
 		return fleetDryRun(ctx, targets, nodegroupPattern, flags)
 	}
 
@@ -83,13 +147,23 @@ func runFleetUpdate(ctx context.Context, cmd *cli.Command) error {
 	cflags.yes = true
 
 	results := make([]clusterUpdateResult, 0, len(targets))
-	for _, tgt := range targets {
+	for i, tgt := range targets {
+		if canaries > 0 && i == canaries {
+			if err := fleetExit(results); err != nil {
+				color.Yellow("Canary cluster(s) finished with issues: %d cluster(s) not started", len(targets)-len(results))
+				break
+			}
+			if !soakCanary(ctx, soak, flags.quiet || jsonOut) {
+				color.Yellow("Interrupted during canary soak: %d cluster(s) not started", len(targets)-len(results))
+				break
+			}
+		}
 		if ctx.Err() != nil {
 			color.Yellow("Interrupted: %d of %d cluster(s) not started", len(targets)-len(results), len(targets))
 			break
 		}
 		if !flags.quiet && !jsonOut {
-			color.Cyan("\n=== %s (%s) ===", tgt.cluster, tgt.region)
+			color.Cyan("\n=== %s (%s) ===", tgt.cluster, tgt.where())
 		}
 		results = append(results, updateOneClusterInFleet(ctx, tgt, nodegroupPattern, cflags))
 	}
@@ -108,10 +182,16 @@ func runFleetUpdate(ctx context.Context, cmd *cli.Command) error {
 // roll → verify) and captures the outcome instead of exiting, so the fleet loop
 // can aggregate.
 func updateOneClusterInFleet(ctx context.Context, tgt clusterTarget, nodegroupPattern string, flags updateAMIFlags) clusterUpdateResult {
-	res := clusterUpdateResult{Cluster: tgt.cluster, Region: tgt.region}
+	res := clusterUpdateResult{Cluster: tgt.cluster, Account: tgt.account, Region: tgt.region}
 	eksClient := eks.NewFromConfig(tgt.awsCfg)
 
-	done, err := preflightHealthCheck(ctx, tgt.awsCfg, eksClient, tgt.cluster, flags)
+	var err error
+	if flags.custom, err = flags.customAMI(tgt.cluster); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	done, err := preflightHealthCheck(ctx, tgt.awsCfg, eksClient, tgt.cluster, nodegroupPattern, flags)
 	if err != nil {
 		// Block (or, in unattended mode, a warn-level hard stop).
 		res.HealthBlocked = true
@@ -127,6 +207,10 @@ func updateOneClusterInFleet(ctx context.Context, tgt clusterTarget, nodegroupPa
 		res.Error = err.Error()
 		return res
 	}
+	if flags.pins, err = resolvePins(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, flags.pin); err != nil {
+		res.Error = err.Error()
+		return res
+	}
 
 	outcomes, verifyFailed, monErr := executeUpdates(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, flags)
 	res.Outcomes = outcomes
@@ -149,12 +233,29 @@ func resolveUpdateRegions(cmd *cli.Command, awsCfg aws.Config) []string {
 	return appconfig.GetRegionsForPartition(awsCfg.Region)
 }
 
-// discoverFleetTargets lists clusters in each region (bounded concurrency) and
-// returns one target per cluster with a region-scoped config.
-func discoverFleetTargets(ctx context.Context, baseCfg aws.Config, regions []string) ([]clusterTarget, error) {
-	perRegion := common.ForEachParallel(ctx, regions, common.DefaultItemConcurrency,
-		func(fctx context.Context, region string) []clusterTarget {
-			cfg := baseCfg.Copy()
+// discoverFleetTargets lists clusters in each account × region (bounded
+// concurrency) and returns one target per cluster with a scoped config.
+// The account label is empty outside multi-account sweeps. With a selector,
+// only matching clusters are returned: the Resource Groups Tagging API
+// narrows them when it can, and each cluster it can't settle has its tags
+// read with DescribeCluster (ListClusters can't filter by tag). A cluster
+// whose tags can't be read is left out with a warning rather than rolled
+// blind.
+func discoverFleetTargets(ctx context.Context, accts []accounts.Account, regions []string, sel selector.Selector) ([]clusterTarget, error) {
+	type scope struct {
+		acct   accounts.Account
+		region string
+	}
+	scopes := make([]scope, 0, len(accts)*len(regions))
+	for _, a := range accts {
+		for _, r := range regions {
+			scopes = append(scopes, scope{acct: a, region: r})
+		}
+	}
+	perRegion := common.ForEachParallel(ctx, scopes, common.DefaultItemConcurrency,
+		func(fctx context.Context, sc scope) []clusterTarget {
+			region := sc.region
+			cfg := sc.acct.Config.Copy()
 			cfg.Region = region
 			eksClient := eks.NewFromConfig(cfg)
 			names, err := awsinternal.ListAllPages(fctx, fmt.Sprintf("listing clusters in %s", region),
@@ -166,9 +267,12 @@ func discoverFleetTargets(ctx context.Context, baseCfg aws.Config, regions []str
 			if err != nil {
 				return nil
 			}
+			if !sel.Empty() {
+				names = selectByTags(fctx, eksClient, resourcegroupstaggingapi.NewFromConfig(cfg), names, sel, fleetLocation(sc.acct.Label(), region))
+			}
 			targets := make([]clusterTarget, 0, len(names))
 			for _, n := range names {
-				targets = append(targets, clusterTarget{cluster: n, region: region, awsCfg: cfg})
+				targets = append(targets, clusterTarget{cluster: n, account: sc.acct.Label(), region: region, awsCfg: cfg})
 			}
 			return targets
 		})
@@ -180,22 +284,105 @@ func discoverFleetTargets(ctx context.Context, baseCfg aws.Config, regions []str
 	return all, nil
 }
 
+// selectByTags keeps the clusters whose EKS tags match sel. The tag index
+// only rules clusters out: it lags tag changes a little, and a roll must not
+// trust it, so every kept cluster is still described. A cluster tagged since
+// the index caught up is missed unless nothing else matches (see
+// awsinternal.TaggedClusters).
+func selectByTags(ctx context.Context, eksClient *eks.Client, tagging awsinternal.TaggingAPI, names []string, sel selector.Selector, where string) []string {
+	if kept, ok := awsinternal.TaggedClusters(ctx, tagging, names, sel); ok {
+		names = kept
+	}
+	matched := common.ForEachParallel(ctx, names, common.DefaultItemConcurrency, func(fctx context.Context, name string) bool {
+		out, err := common.WithRetry(fctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
+			return eksClient.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(name)})
+		})
+		if err != nil || out.Cluster == nil {
+			color.Yellow("Warning: skipping %s (%s): tags unavailable for --selector: %v", name, where, err)
+			return false
+		}
+		return sel.Matches(out.Cluster.Tags)
+	})
+	var kept []string
+	for i, ok := range matched {
+		if ok {
+			kept = append(kept, names[i])
+		}
+	}
+	return kept
+}
+
+// orderCanaryFirst moves targets whose cluster matches a canary glob to the
+// front (keeping discovery order within each group) and reports how many
+// there are.
+func orderCanaryFirst(targets []clusterTarget, patterns []string) ([]clusterTarget, int) {
+	if len(patterns) == 0 {
+		return targets, 0
+	}
+	var canary, rest []clusterTarget
+	for _, t := range targets {
+		if matchesGlob(t.cluster, patterns) {
+			canary = append(canary, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	return append(canary, rest...), len(canary)
+}
+
+// matchesGlob reports whether name matches any of the path.Match patterns.
+func matchesGlob(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// soakCanary waits out the canary soak period; false means ctx was cancelled.
+func soakCanary(ctx context.Context, soak time.Duration, quiet bool) bool {
+	if soak <= 0 {
+		return ctx.Err() == nil
+	}
+	if !quiet {
+		color.Cyan("\nCanary clusters done; soaking %s before the rest of the fleet", soak)
+	}
+	timer := time.NewTimer(soak)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // fleetDryRun prints the per-cluster plan without mutating anything.
 func fleetDryRun(ctx context.Context, targets []clusterTarget, nodegroupPattern string, flags updateAMIFlags) error {
 	color.Cyan("Fleet dry-run: %d cluster(s)", len(targets))
 	for _, tgt := range targets {
-		color.Cyan("\n=== %s (%s) ===", tgt.cluster, tgt.region)
+		color.Cyan("\n=== %s (%s) ===", tgt.cluster, tgt.where())
 		eksClient := eks.NewFromConfig(tgt.awsCfg)
 		selected, err := selectNodegroupsForUpdate(ctx, eksClient, tgt.cluster, nodegroupPattern, true)
 		if err != nil {
 			color.Red("  %v", err)
 			continue
 		}
-		if err := dryrun.PerformDryRun(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, flags.force, flags.quiet); err != nil {
+		cflags := flags
+		if cflags.pins, err = resolvePins(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, flags.pin); err != nil {
+			color.Red("  %v", err)
+			continue
+		}
+		if cflags.custom, err = flags.customAMI(tgt.cluster); err != nil {
+			color.Red("  %v", err)
+			continue
+		}
+		if err := dryrun.PerformDryRunWith(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, cflags.dryRunOptions(tgt.awsCfg, eksClient)); err != nil {
 			color.Red("  %v", err)
 		}
 		if !flags.quiet {
-			printChangelogsForNodegroups(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, flags.changelog)
+			printChangelogsForNodegroups(ctx, tgt.awsCfg, eksClient, tgt.cluster, selected, cflags)
 		}
 	}
 	return nil
@@ -206,7 +393,7 @@ func printFleetSummary(results []clusterUpdateResult) {
 	color.Cyan("\nFleet summary (%d cluster(s)):", len(results))
 	for _, r := range results {
 		status := summarizeClusterResult(r)
-		fmt.Printf("  %-28s %s\n", r.Cluster+" ("+r.Region+")", status)
+		fmt.Printf("  %-28s %s\n", r.Cluster+" ("+fleetLocation(r.Account, r.Region)+")", status)
 	}
 }
 
@@ -34,3 +34,31 @@ func TestFleetExit_WorstOutcome(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderCanaryFirst(t *testing.T) {
+	targets := []clusterTarget{{cluster: "prod-a"}, {cluster: "stage-a"}, {cluster: "prod-b"}, {cluster: "stage-b"}}
+
+	got, n := orderCanaryFirst(targets, []string{"stage-*"})
+	if n != 2 {
+		t.Fatalf("canaries = %d, want 2", n)
+	}
+	want := []string{"stage-a", "stage-b", "prod-a", "prod-b"}
+	for i, w := range want {
+		if got[i].cluster != w {
+			t.Errorf("order[%d] = %s, want %s", i, got[i].cluster, w)
+		}
+	}
+
+	if _, n := orderCanaryFirst(targets, nil); n != 0 {
+		t.Errorf("no patterns: canaries = %d, want 0", n)
+	}
+}
+
+func TestFleetLocation(t *testing.T) {
+	if got := fleetLocation("", "us-east-1"); got != "us-east-1" {
+		t.Errorf("single account = %q", got)
+	}
+	if got := fleetLocation("payments", "us-east-1"); got != "payments/us-east-1" {
+		t.Errorf("multi account = %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/amichangelog"
 	"github.com/dantech2000/refresh/internal/health"
 	nodegroupsvc "github.com/dantech2000/refresh/internal/services/nodegroup"
 	"github.com/dantech2000/refresh/internal/types"
@@ -179,7 +180,7 @@ func TestOrDash(t *testing.T) {
 }
 
 func TestPrintChangelog_Degraded(t *testing.T) {
-	cl := amiChangelog{Current: "ami-1", Target: "ami-2", Degraded: true, Reason: "could not parse release dates"}
+	cl := amichangelog.Changelog{Current: "ami-1", Target: "ami-2", Degraded: true, Reason: "could not parse release dates"}
 	out := captureStdout(t, func() { printChangelog(cl, false) })
 	if !strings.Contains(out, "release notes unavailable") {
 		t.Errorf("degraded changelog should say notes unavailable, got: %q", out)
@@ -187,11 +188,11 @@ func TestPrintChangelog_Degraded(t *testing.T) {
 }
 
 func TestPrintChangelog_TruncatesWithoutFull(t *testing.T) {
-	cl := amiChangelog{
+	cl := amichangelog.Changelog{
 		Current: "ami-1",
 		Target:  "ami-9",
 		Behind:  4,
-		Notes: []releaseNote{
+		Notes: []amichangelog.Note{
 			{Tag: "v1", Highlights: []string{"a"}},
 			{Tag: "v2"},
 			{Tag: "v3"},
@@ -207,11 +208,28 @@ func TestPrintChangelog_TruncatesWithoutFull(t *testing.T) {
 	}
 }
 
+func TestPrintChangelog_Security(t *testing.T) {
+	cl := amichangelog.Changelog{
+		Current:  "1.31.0-20260201",
+		Target:   "1.31.0-20260601",
+		Behind:   2,
+		Security: &amichangelog.Security{ReleasesBehind: 1, CVEs: []amichangelog.CVE{{ID: "CVE-2026-1001"}}},
+		Source:   &amichangelog.Provenance{Origin: amichangelog.OriginCache, FetchedAt: time.Now().Add(-3 * time.Hour)},
+	}
+	out := captureStdout(t, func() { printChangelog(cl, false) })
+	if !strings.Contains(out, "security: 1 CVE(s)") {
+		t.Errorf("expected a security line, got: %q", out)
+	}
+	if !strings.Contains(out, "release notes: cache, 3h old") {
+		t.Errorf("expected the notes' source and age, got: %q", out)
+	}
+}
+
 func TestPrintChangelog_FullShowsAll(t *testing.T) {
-	cl := amiChangelog{
+	cl := amichangelog.Changelog{
 		Current: "ami-1",
 		Target:  "ami-9",
-		Notes: []releaseNote{
+		Notes: []amichangelog.Note{
 			{Tag: "v1"}, {Tag: "v2"}, {Tag: "v3"}, {Tag: "v4"},
 		},
 	}
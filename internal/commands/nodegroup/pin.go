@@ -0,0 +1,178 @@
+package nodegroup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// releasePin is the AMI release a roll targets instead of the latest
+// recommended one: a single --release-version, or with --match-cluster the
+// release a reference cluster's nodegroups run, per AMI type.
+type releasePin struct {
+	version string // --release-version
+	cluster string // --match-cluster
+	byType  map[ekstypes.AMITypes]string
+}
+
+func (p releasePin) set() bool { return p.version != "" || p.cluster != "" }
+
+// forType returns the pinned release for an AMI type ("" when the reference
+// cluster runs none of that type).
+func (p releasePin) forType(t ekstypes.AMITypes) string {
+	if p.version != "" {
+		return p.version
+	}
+	return p.byType[t]
+}
+
+// validate rejects --release-version together with --match-cluster.
+func (p releasePin) validate() error {
+	if p.version != "" && p.cluster != "" {
+		return fmt.Errorf("--release-version and --match-cluster are mutually exclusive")
+	}
+	return nil
+}
+
+// load resolves --match-cluster against the reference cluster (a no-op
+// otherwise). The reference is looked up with eksClient, so it must live in
+// the account and region of the run.
+func (p *releasePin) load(ctx context.Context, eksClient nodegroupReader) error {
+	if p.cluster == "" || p.byType != nil {
+		return nil
+	}
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing nodegroups of reference cluster %s", p.cluster),
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return eksClient.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(p.cluster), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return err
+	}
+	// Every reference nodegroup must be read: one left out could hide a
+	// second release of its AMI type, and the roll would promote the wrong one.
+	type result struct {
+		ng  *ekstypes.Nodegroup
+		err error
+	}
+	results := common.ForEachParallel(ctx, names, common.DefaultItemConcurrency,
+		func(fctx context.Context, ng string) result {
+			out, err := common.WithRetry(fctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
+				return eksClient.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{ClusterName: aws.String(p.cluster), NodegroupName: aws.String(ng)})
+			})
+			if err != nil {
+				return result{err: err}
+			}
+			if out.Nodegroup == nil {
+				return result{err: fmt.Errorf("empty DescribeNodegroup response")}
+			}
+			return result{ng: out.Nodegroup}
+		})
+	described := make([]*ekstypes.Nodegroup, 0, len(results))
+	for i, r := range results {
+		if r.err != nil {
+			return awsinternal.FormatAWSError(r.err, fmt.Sprintf("describing nodegroup %s of reference cluster %s", names[i], p.cluster))
+		}
+		described = append(described, r.ng)
+	}
+	byType, err := referenceReleases(p.cluster, described)
+	if err != nil {
+		return err
+	}
+	p.byType = byType
+	return nil
+}
+
+// nodegroupReader is the EKS subset --match-cluster reads the reference
+// cluster with.
+type nodegroupReader interface {
+	nodegroupDescriber
+	ListNodegroups(ctx context.Context, in *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error)
+}
+
+// referenceReleases maps each AMI type in the reference cluster to the one
+// release its nodegroups run. Nodegroups of one type on different releases
+// (say, mid-roll) are an error: there is no single release to promote.
+func referenceReleases(cluster string, nodegroups []*ekstypes.Nodegroup) (map[ekstypes.AMITypes]string, error) {
+	byType := make(map[ekstypes.AMITypes]string)
+	seen := make(map[ekstypes.AMITypes][]string) // "release (nodegroup)" per type
+	for _, ng := range nodegroups {
+		if ng == nil || ng.AmiType == ekstypes.AMITypesCustom || aws.ToString(ng.ReleaseVersion) == "" {
+			continue
+		}
+		rel := aws.ToString(ng.ReleaseVersion)
+		seen[ng.AmiType] = append(seen[ng.AmiType], fmt.Sprintf("%s (%s)", rel, aws.ToString(ng.NodegroupName)))
+		if prev, ok := byType[ng.AmiType]; ok && prev != rel {
+			sort.Strings(seen[ng.AmiType])
+			return nil, fmt.Errorf("reference cluster %s runs %s nodegroups on different releases: %s; pin one with --release-version",
+				cluster, ng.AmiType, strings.Join(seen[ng.AmiType], ", "))
+		}
+		byType[ng.AmiType] = rel
+	}
+	if len(byType) == 0 {
+		return nil, fmt.Errorf("reference cluster %s has no managed-AMI nodegroups to match", cluster)
+	}
+	return byType, nil
+}
+
+// resolvePins validates the pin for each selected nodegroup against the
+// releases published for its AMI type and Kubernetes version, returning the
+// pinned release (with its AMI) by nodegroup name. Custom-AMI nodegroups are
+// left out: the roll skips them anyway. Any nodegroup the pin can't apply to
+// fails the whole run before anything is started.
+func resolvePins(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName string, nodegroups []string, pin releasePin) (map[string]awsinternal.AMIRelease, error) {
+	if !pin.set() {
+		return nil, nil
+	}
+	if err := pin.load(ctx, eksClient); err != nil {
+		return nil, err
+	}
+	ssmClient := ssm.NewFromConfig(awsCfg)
+	type key struct {
+		version string
+		amiType ekstypes.AMITypes
+	}
+	published := make(map[key][]awsinternal.AMIRelease)
+
+	pins := make(map[string]awsinternal.AMIRelease, len(nodegroups))
+	for _, name := range nodegroups {
+		out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
+			return eksClient.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)})
+		})
+		if err != nil {
+			return nil, awsinternal.FormatAWSError(err, fmt.Sprintf("describing nodegroup %s", name))
+		}
+		ng := out.Nodegroup
+		if ng == nil || ng.AmiType == ekstypes.AMITypesCustom {
+			continue
+		}
+		want := pin.forType(ng.AmiType)
+		if want == "" {
+			return nil, fmt.Errorf("nodegroup %s is %s, but reference cluster %s runs no %s nodegroups", name, ng.AmiType, pin.cluster, ng.AmiType)
+		}
+		k := key{version: aws.ToString(ng.Version), amiType: ng.AmiType}
+		releases, ok := published[k]
+		if !ok {
+			if releases, err = awsinternal.ReleasesForType(ctx, ssmClient, k.version, k.amiType); err != nil {
+				return nil, fmt.Errorf("validating release %s for nodegroup %s: %w", want, name, err)
+			}
+			published[k] = releases
+		}
+		rel, err := awsinternal.FindRelease(releases, want, k.amiType, k.version)
+		if err != nil {
+			return nil, fmt.Errorf("nodegroup %s: %w", name, err)
+		}
+		pins[name] = rel
+	}
+	return pins, nil
+}
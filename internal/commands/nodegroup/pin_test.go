@@ -0,0 +1,94 @@
+package nodegroup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/dantech2000/refresh/internal/mocks"
+)
+
+func TestReferenceReleases(t *testing.T) {
+	ng := func(name string, t ekstypes.AMITypes, release string) *ekstypes.Nodegroup {
+		return &ekstypes.Nodegroup{NodegroupName: aws.String(name), AmiType: t, ReleaseVersion: aws.String(release)}
+	}
+	byType, err := referenceReleases("staging", []*ekstypes.Nodegroup{
+		ng("workers", ekstypes.AMITypesAl2023X8664Standard, "1.31.0-20260601"),
+		ng("arm", ekstypes.AMITypesAl2023Arm64Standard, "1.31.0-20260601"),
+		ng("batch", ekstypes.AMITypesAl2023X8664Standard, "1.31.0-20260601"),
+		ng("byo", ekstypes.AMITypesCustom, ""),
+		nil, // couldn't be described
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pin := releasePin{cluster: "staging", byType: byType}
+	if got := pin.forType(ekstypes.AMITypesAl2023Arm64Standard); got != "1.31.0-20260601" {
+		t.Errorf("arm64 release = %q", got)
+	}
+	if got := pin.forType(ekstypes.AMITypesBottlerocketX8664); got != "" {
+		t.Errorf("a type staging doesn't run should have no pin, got %q", got)
+	}
+
+	// Mid-roll: two releases of one type leave nothing to promote.
+	_, err = referenceReleases("staging", []*ekstypes.Nodegroup{
+		ng("workers", ekstypes.AMITypesAl2023X8664Standard, "1.31.0-20260601"),
+		ng("batch", ekstypes.AMITypesAl2023X8664Standard, "1.31.0-20260515"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "1.31.0-20260515 (batch), 1.31.0-20260601 (workers)") {
+		t.Errorf("mixed releases error = %v", err)
+	}
+	if _, err := referenceReleases("staging", []*ekstypes.Nodegroup{ng("byo", ekstypes.AMITypesCustom, "")}); err == nil {
+		t.Error("a reference cluster with only custom AMIs should be an error")
+	}
+
+	if err := (releasePin{version: "1.31.0-20260601", cluster: "staging"}).validate(); err == nil {
+		t.Error("--release-version with --match-cluster should be rejected")
+	}
+}
+
+func TestReleasePinLoad(t *testing.T) {
+	api := mocks.NewEKSAPI().
+		WithNodegroup("workers", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		WithNodegroup("batch", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		Build()
+	describe := api.DescribeNodegroupFn
+	throttled := false
+	api.DescribeNodegroupFn = func(ctx context.Context, in *eks.DescribeNodegroupInput, opts ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+		if aws.ToString(in.NodegroupName) == "batch" && !throttled {
+			throttled = true
+			return nil, &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+		}
+		out, err := describe(ctx, in, opts...)
+		if err == nil {
+			out.Nodegroup.ReleaseVersion = aws.String("1.31.0-20260601")
+		}
+		return out, err
+	}
+	pin := releasePin{cluster: "reference"}
+	if err := pin.load(context.Background(), api); err != nil {
+		t.Fatalf("a throttled describe should be retried: %v", err)
+	}
+	if got := pin.forType(ekstypes.AMITypesAl2023X8664Standard); got != "1.31.0-20260601" {
+		t.Errorf("pinned %q", got)
+	}
+
+	// A nodegroup that can't be read might run a second release of its
+	// type: refuse rather than promote from the rest.
+	api.DescribeNodegroupFn = func(ctx context.Context, in *eks.DescribeNodegroupInput, opts ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+		if aws.ToString(in.NodegroupName) == "batch" {
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+		}
+		return describe(ctx, in, opts...)
+	}
+	pin = releasePin{cluster: "reference"}
+	err := pin.load(context.Background(), api)
+	if err == nil || !strings.Contains(err.Error(), "batch") {
+		t.Fatalf("err = %v, want the unreadable reference nodegroup named", err)
+	}
+}
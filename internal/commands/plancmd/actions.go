@@ -0,0 +1,275 @@
+package plancmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/services/common"
+	"github.com/dantech2000/refresh/internal/services/desired"
+	"github.com/dantech2000/refresh/internal/services/upgrade"
+)
+
+func runPlan(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	spec, err := desired.LoadSpec(cmd.String("file"))
+	if err != nil {
+		return err
+	}
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	plan, _, err := planSpec(ctx, cmd, awsCfg, spec)
+	if err != nil {
+		return err
+	}
+	if handled, encErr := runner.EncodeStdout(cmd.String("format"), plan); handled {
+		if encErr != nil {
+			return encErr
+		}
+		return exitForPlan(plan)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %s", w))
+	}
+	outputPlan(plan, cmd.String("file"))
+	return exitForPlan(plan)
+}
+
+func runApply(ctx context.Context, cmd *cli.Command) error {
+	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
+		return err
+	}
+	spec, err := desired.LoadSpec(cmd.String("file"))
+	if err != nil {
+		return err
+	}
+	// Strict credential validation: apply mutates control planes.
+	ctx, cancel, awsCfg, err := runner.SetupAWSStrict(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	plan, services, err := planSpec(ctx, cmd, awsCfg, spec)
+	if err != nil {
+		return err
+	}
+	for _, w := range plan.Warnings {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %s", w))
+	}
+	// With -o json|yaml, stdout holds only the plan; progress, prompts and
+	// the summary go to stderr.
+	out := io.Writer(os.Stdout)
+	if handled, encErr := runner.EncodeStdout(cmd.String("format"), plan); encErr != nil {
+		return encErr
+	} else if handled {
+		out = cmd.Root().ErrWriter
+	} else {
+		outputPlan(plan, cmd.String("file"))
+	}
+
+	_, blocked, _, errored := plan.Totals()
+	if blocked > 0 || errored > 0 {
+		return cli.Exit(color.RedString("Apply refused: resolve the blocked or unplanned clusters above, or narrow the run with --cluster."), 3)
+	}
+
+	progress := func(format string, args ...any) {
+		if !cmd.Bool("quiet") {
+			_, _ = fmt.Fprintf(out, "  "+format+"\n", args...)
+		}
+	}
+	applied := 0
+	for i := range plan.Clusters {
+		cp := &plan.Clusters[i]
+		if cp.Count(desired.ChangePending) == 0 {
+			continue
+		}
+		svc := services[cp.Region]
+		svc.Upgrader.PollInterval = cmd.Duration("poll-interval")
+		_, _ = fmt.Fprintln(out)
+		_, _ = fmt.Fprintf(out, "%s\n", color.New(color.Bold).Sprintf("Applying %s (%s)", cp.Cluster, cp.Region))
+		report, err := svc.Apply(ctx, cp, desired.ApplyOptions{
+			Yes:      cmd.Bool("yes"),
+			Confirm:  func(label string) bool { return promptPhase(out, label) },
+			Progress: progress,
+			Force:    cmd.Bool("force"),
+		})
+		renderReport(out, report)
+		if err != nil {
+			_, _ = fmt.Fprintln(out)
+			_, _ = fmt.Fprintf(out, "Resume with: %s\n", color.CyanString(resumeCommand(cmd)))
+			if errors.Is(err, upgrade.ErrAborted) {
+				return err
+			}
+			return fmt.Errorf("%s: %w", cp.Cluster, err)
+		}
+		applied++
+	}
+
+	_, _ = fmt.Fprintln(out)
+	if applied == 0 {
+		_, _ = fmt.Fprintf(out, "Nothing to apply: every cluster matches %s.\n", cmd.String("file"))
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "%s\n", color.GreenString("Apply complete: %d cluster(s) reconciled with %s.", applied, cmd.String("file")))
+	return nil
+}
+
+// planSpec expands the spec's entries into clusters, region by region, and
+// plans each one with bounded concurrency. A cluster matched by several
+// entries is planned against the first. The returned services, keyed by
+// region, apply the plans.
+func planSpec(ctx context.Context, cmd *cli.Command, awsCfg aws.Config, spec *desired.Spec) (*desired.Plan, map[string]*desired.Service, error) {
+	only := cmd.StringSlice("cluster")
+	services := map[string]*desired.Service{}
+	serviceFor := func(region string) *desired.Service {
+		if svc, ok := services[region]; ok {
+			return svc
+		}
+		cfg := awsCfg.Copy()
+		cfg.Region = region
+		svc := desired.NewService(eks.NewFromConfig(cfg), desired.SSMReleases{Client: ssm.NewFromConfig(cfg)}, factory.NewDefaultLogger(nil))
+		services[region] = svc
+		return svc
+	}
+
+	type target struct {
+		region, cluster string
+		spec            desired.ClusterSpec
+	}
+	plan := &desired.Plan{}
+	var targets []target
+	seen := map[string]string{}
+	err := runner.WithSpinner("cluster", "Desired state planned!", func() error {
+		for i := range spec.Clusters {
+			entry := spec.Resolve(i)
+			region := entry.Region
+			if region == "" {
+				region = awsCfg.Region
+			}
+			names, err := serviceFor(region).Match(ctx, entry)
+			if err != nil {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("clusters[%d] (%s): %v", i, entry.Name, err))
+				continue
+			}
+			if len(names) == 0 {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("clusters[%d] (%s) matched no clusters in %s", i, entry.Name, region))
+			}
+			for _, name := range names {
+				if len(only) > 0 && !slices.Contains(only, name) {
+					continue
+				}
+				key := region + "/" + name
+				if first, dup := seen[key]; dup {
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s is matched by %s and clusters[%d]; planned against the first", name, first, i))
+					continue
+				}
+				seen[key] = fmt.Sprintf("clusters[%d]", i)
+				targets = append(targets, target{region: region, cluster: name, spec: entry})
+			}
+		}
+		conc := cmd.Int("max-concurrency")
+		if conc <= 0 {
+			conc = common.DefaultItemConcurrency
+		}
+		plan.Clusters = common.ForEachParallel(ctx, targets, conc, func(pctx context.Context, t target) desired.ClusterPlan {
+			cp := services[t.region].PlanCluster(pctx, t.cluster, t.spec)
+			cp.Region = t.region
+			return cp
+		})
+		for i, t := range targets {
+			if plan.Clusters[i].Cluster == "" {
+				// ForEachParallel leaves items unstarted once ctx is cancelled.
+				plan.Clusters[i] = desired.ClusterPlan{Cluster: t.cluster, Region: t.region, Error: fmt.Sprintf("not planned: %v", ctx.Err())}
+			}
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(only) > 0 && len(targets) == 0 {
+		return nil, nil, fmt.Errorf("no cluster in %s matches --cluster %s", cmd.String("file"), strings.Join(only, ", "))
+	}
+	sort.SliceStable(plan.Clusters, func(i, j int) bool {
+		if plan.Clusters[i].Region != plan.Clusters[j].Region {
+			return plan.Clusters[i].Region < plan.Clusters[j].Region
+		}
+		return plan.Clusters[i].Cluster < plan.Clusters[j].Cluster
+	})
+	return plan, services, nil
+}
+
+// exitForPlan maps the plan to the documented exit codes: 3 when a change is
+// blocked, 2 when anything drifts or a cluster couldn't be planned.
+func exitForPlan(p *desired.Plan) error {
+	pending, blocked, manual, errored := p.Totals()
+	switch {
+	case blocked > 0:
+		return cli.Exit("", 3)
+	case pending > 0 || manual > 0 || errored > 0:
+		return cli.Exit("", 2)
+	}
+	return nil
+}
+
+// resumeCommand is the apply to rerun after a failure: the same spec and
+// cluster narrowing.
+func resumeCommand(cmd *cli.Command) string {
+	parts := []string{"refresh apply"}
+	if f := cmd.String("file"); f != desired.DefaultSpecFile {
+		parts = append(parts, "-f "+f)
+	}
+	for _, c := range cmd.StringSlice("cluster") {
+		parts = append(parts, "-c "+c)
+	}
+	return strings.Join(parts, " ")
+}
+
+// promptPhase asks for confirmation before a mutating phase. Bare Enter or a
+// read error declines (safe default).
+func promptPhase(out io.Writer, label string) bool {
+	_, _ = fmt.Fprintf(out, "\nProceed with %s? (y/N): ", label)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// renderReport prints the completed / failed-at / remaining summary to out.
+func renderReport(out io.Writer, report *upgrade.Report) {
+	if report == nil {
+		return
+	}
+	for _, c := range report.Completed {
+		_, _ = fmt.Fprintf(out, "%s %s\n", color.GreenString("completed:"), c)
+	}
+	if report.FailedAt != "" {
+		_, _ = fmt.Fprintf(out, "%s %s\n", color.RedString("failed at:"), report.FailedAt)
+	}
+	for _, r := range report.Remaining {
+		_, _ = fmt.Fprintf(out, "%s %s\n", color.YellowString("remaining:"), r)
+	}
+}
@@ -0,0 +1,96 @@
+package plancmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/awsconfig"
+	"github.com/dantech2000/refresh/internal/sandbox"
+	"github.com/dantech2000/refresh/internal/services/desired"
+)
+
+const applyFixture = `region: us-east-1
+timings: {addon: 0s}
+addonVersions:
+  coredns:
+    - {version: v1.11.3-eksbuild.1, kubernetes: ["1.31"], default: ["1.31"]}
+    - {version: v1.11.4-eksbuild.2, kubernetes: ["1.31"]}
+clusters:
+  - name: prod
+    version: "1.31"
+    addons:
+      - {name: coredns, version: v1.11.3-eksbuild.1}
+`
+
+const applySpec = `clusters:
+  - name: prod
+    addons:
+      coredns: latest
+`
+
+// TestApplyStructuredStdout checks that `apply -o json` leaves stdout to the
+// plan alone, so it decodes as one document while the run's progress and
+// summary go to stderr.
+func TestApplyStructuredStdout(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", dir)
+	t.Setenv("REFRESH_CONTEXT", "")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	fixture, spec := filepath.Join(dir, "fleet.yaml"), filepath.Join(dir, "spec.yaml")
+	for path, body := range map[string]string{fixture: applyFixture, spec: applySpec} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sim, err := sandbox.Load(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	awsconfig.UseSandbox(sim)
+	t.Cleanup(func() { awsconfig.UseSandbox(nil) })
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = original })
+
+	var errOut bytes.Buffer
+	app := &cli.Command{
+		Name:      "refresh",
+		Flags:     []cli.Flag{&cli.StringFlag{Name: "region"}, &cli.StringFlag{Name: "profile"}, &cli.DurationFlag{Name: "timeout"}},
+		ErrWriter: &errOut,
+		Commands:  []*cli.Command{ApplyCommand()},
+	}
+	runErr := app.Run(context.Background(), []string{"refresh", "--region", "us-east-1", "apply", "-f", spec, "-o", "json", "--yes", "-p", "1ms"})
+	_ = w.Close()
+	stdout, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("apply: %v\nstderr:\n%s", runErr, errOut.String())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(stdout))
+	var plan desired.Plan
+	if err := dec.Decode(&plan); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Fatalf("stdout holds more than the plan:\n%s", stdout)
+	}
+	if len(plan.Clusters) != 1 || plan.Clusters[0].Count(desired.ChangePending) != 1 {
+		t.Errorf("plan = %+v, want one pending change on prod", plan)
+	}
+	if !strings.Contains(errOut.String(), "Apply complete") {
+		t.Errorf("stderr = %q, want the apply summary", errOut.String())
+	}
+}
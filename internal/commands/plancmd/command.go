@@ -0,0 +1,109 @@
+// Package plancmd wires the top-level `refresh plan` and `refresh apply`
+// commands: diff live clusters against a declarative desired-state spec, and
+// reconcile the drift through the upgrade, add-on and nodegroup machinery.
+package plancmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	appconfig "github.com/dantech2000/refresh/internal/config"
+	"github.com/dantech2000/refresh/internal/services/desired"
+)
+
+// applyDefaultTimeout bounds a full apply, which may run multi-hop upgrades
+// and nodegroup rolls across several clusters.
+const applyDefaultTimeout = 4 * time.Hour
+
+const specDescription = `The spec file (default ` + desired.DefaultSpecFile + `, or REFRESH_SPEC) lists
+clusters by name or glob, optionally narrowed by region and EKS tag selector,
+each layered over a defaults block:
+
+  defaults:
+    kubernetesVersion: "1.33"
+    addons: {"*": latest}          # latest, default, or an exact version
+    nodegroups: {ami: latest, maxAgeDays: 30}
+  clusters:
+    - name: prod-*
+      selector: env=prod
+      addons:
+        coredns: default
+        kube-proxy: v1.33.0-eksbuild.1
+      nodegroups: {ami: 1.33.0-20260601}   # pin an AMI release
+      skip:
+        addons: [aws-load-balancer-controller]
+        nodegroups: [gpu-]`
+
+func specFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "Desired-state spec file", Value: desired.DefaultSpecFile, Sources: cli.EnvVars("REFRESH_SPEC")},
+		&cli.StringSliceFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "Only these clusters of the spec (repeatable)"},
+		&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+	}
+}
+
+// PlanCommand returns the `refresh plan` top-level command.
+func PlanCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "plan",
+		Usage: "Diff live clusters against a desired-state spec",
+		Description: `Compare every cluster a desired-state spec matches with what it runs: the
+Kubernetes version of the control plane and nodegroups, each add-on against its
+version policy, and each nodegroup's AMI release against the AMI policy. Each
+difference is pending (apply reconciles it), blocked (apply can't until it's
+resolved, e.g. a pinned add-on version that doesn't support the spec's
+Kubernetes version) or manual (outside what apply does, e.g. an add-on
+downgrade). Nothing is changed.
+
+` + specDescription + `
+
+Exit codes: 0 when every cluster matches the spec, 2 when there is drift or a
+cluster couldn't be planned, 3 when a change is blocked.
+
+  refresh plan
+  refresh plan -f fleet.yaml -c prod-east -o json`,
+		Flags: append(specFlags(),
+			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runPlan(ctx, cmd) },
+	}
+}
+
+// ApplyCommand returns the `refresh apply` top-level command.
+func ApplyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "apply",
+		Usage: "Reconcile clusters with a desired-state spec",
+		Description: `Plan as ` + "`refresh plan`" + ` does, then reconcile each drifting cluster in turn:
+
+  1. Kubernetes version drift runs the cluster upgrade orchestrator (readiness,
+     control plane, add-ons, nodegroup rolls, a gate after every phase).
+     Add-ons with a default or pinned policy are held out of its per-hop
+     updates, and a pinned AMI release is what nodegroups land on.
+  2. Add-ons move to the version their policy names, in dependency order,
+     behind the add-on health gate.
+  3. Nodegroups whose AMI breaks the policy roll onto the desired release,
+     behind the pre-roll nodegroup gate.
+
+Every mutating phase is confirmed unless --yes. Manual changes are reported and
+left alone, and apply refuses to start while any cluster in the plan is
+blocked or couldn't be planned (narrow the run with --cluster). State is
+re-derived from the clusters on every run, so rerunning after a failure or
+Ctrl+C resumes where it stopped.
+
+` + specDescription + `
+
+  refresh apply --yes
+  refresh apply -f fleet.yaml -c staging-east`,
+		Flags: append(specFlags(),
+			&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Skip per-phase confirmation prompts"},
+			&cli.BoolFlag{Name: "force", Usage: "Force nodegroup rolls when pods can't be drained due to PDBs"},
+			&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Usage: "Suppress progress output"},
+			&cli.DurationFlag{Name: "poll-interval", Aliases: []string{"p"}, Usage: "How often to poll in-flight updates", Value: 15 * time.Second},
+			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Overall operation timeout", Value: applyDefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runApply(ctx, cmd) },
+	}
+}
@@ -0,0 +1,157 @@
+package plancmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/desired"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// outputPlan renders the plan: the themed view on a terminal, an uncolored
+// table for `-o plain`.
+func outputPlan(p *desired.Plan, file string) {
+	if !ui.PlainOutput() {
+		th := render.Default(os.Stdout)
+		for _, line := range planLines(th, p, file) {
+			fmt.Println(line)
+		}
+		return
+	}
+	tbl := ui.NewPTable([]ui.Column{
+		{Title: "CLUSTER"}, {Title: "REGION"}, {Title: "KIND"}, {Title: "TARGET"},
+		{Title: "CURRENT"}, {Title: "DESIRED"}, {Title: "STATUS"}, {Title: "REASON"},
+	}, ui.CyanHeaders())
+	for _, c := range p.Clusters {
+		switch {
+		case c.Error != "":
+			tbl.AddRow(c.Cluster, c.Region, "-", "-", "-", "-", "error", c.Error)
+		case len(c.Changes) == 0:
+			tbl.AddRow(c.Cluster, c.Region, "-", "-", dash(c.KubernetesVersion), "-", "in-sync", "")
+		}
+		for _, ch := range c.Changes {
+			tbl.AddRow(c.Cluster, c.Region, string(ch.Kind), dash(ch.Target), dash(ch.Current), dash(ch.Desired), string(ch.Status), changeNote(ch))
+		}
+	}
+	tbl.Render()
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// kindLabel names a change kind for the human view.
+func kindLabel(k desired.ChangeKind) string {
+	switch k {
+	case desired.ChangeKubernetes:
+		return "control plane"
+	case desired.ChangeNodegroupVersion:
+		return "nodegroup"
+	case desired.ChangeNodegroupAMI:
+		return "nodegroup AMI"
+	}
+	return "add-on"
+}
+
+// changeNote joins a change's policy and reason.
+func changeNote(ch desired.Change) string {
+	var parts []string
+	if ch.Policy != "" && ch.Policy != ch.Desired {
+		parts = append(parts, ch.Policy)
+	}
+	if ch.Reason != "" && ch.Reason != "pinned" {
+		parts = append(parts, ch.Reason)
+	}
+	return strings.Join(parts, ": ")
+}
+
+func statusToken(th *render.Theme, s desired.ChangeStatus) string {
+	switch s {
+	case desired.ChangeBlocked:
+		return th.Tokenf(render.Fail, "BLOCKED")
+	case desired.ChangeManual:
+		return th.Tokenf(render.Unknown, "MANUAL")
+	}
+	return th.Tokenf(render.Warn, "CHANGE")
+}
+
+// planLines builds the human `refresh plan` view (pure, golden-testable): a
+// summary, then per cluster its drift or that it's in sync.
+func planLines(th *render.Theme, p *desired.Plan, file string) []string {
+	pal := th.Pal
+	pending, blocked, manual, errored := p.Totals()
+	inSync := 0
+	for _, c := range p.Clusters {
+		if c.Error == "" && len(c.Changes) == 0 {
+			inSync++
+		}
+	}
+	out := []string{
+		th.Bold(pal.White, "PLAN") + th.Paint(pal.Dim, fmt.Sprintf("  %s · %d clusters", file, len(p.Clusters))),
+		strings.Join([]string{
+			th.Tokenf(render.Warn, fmt.Sprintf("%d to change", pending)),
+			th.Tokenf(render.Fail, fmt.Sprintf("%d blocked", blocked)),
+			th.Token(render.Unknown, fmt.Sprintf("%d manual", manual)),
+			th.Tokenf(render.Healthy, fmt.Sprintf("%d in sync", inSync)),
+		}, "  "),
+	}
+	if errored > 0 {
+		out[1] += "  " + th.Token(render.Unknown, fmt.Sprintf("%d unplanned", errored))
+	}
+	if len(p.Clusters) == 0 {
+		return append(out, "", "  "+th.Paint(pal.Dim, "no clusters matched"))
+	}
+
+	for _, c := range p.Clusters {
+		version := c.KubernetesVersion
+		if c.DesiredVersion != "" && c.DesiredVersion != c.KubernetesVersion {
+			version += " → " + c.DesiredVersion
+		}
+		head := th.Bold(pal.White, c.Cluster) + th.Paint(pal.Dim, "  "+c.Region)
+		if version != "" {
+			head += "  " + th.Paint(pal.Text, version)
+		}
+		out = append(out, "", head)
+		switch {
+		case c.Error != "":
+			out = append(out, "  "+th.Token(render.Unknown, "unplanned: "+c.Error))
+			continue
+		case len(c.Changes) == 0:
+			out = append(out, "  "+th.Tokenf(render.Healthy, fmt.Sprintf("in sync (%d managed)", c.InSync)))
+			continue
+		}
+		tbl := th.NewTable(
+			ui.Column{Title: "", Min: 9},
+			ui.Column{Title: "KIND", Min: 13},
+			ui.Column{Title: "TARGET", Min: 6, Max: 32},
+			ui.Column{Title: "CURRENT", Min: 7},
+			ui.Column{Title: "DESIRED", Min: 7},
+			ui.Column{Title: "NOTE", Max: 60},
+		)
+		for _, ch := range c.Changes {
+			tbl.Row(
+				statusToken(th, ch.Status),
+				th.Paint(pal.Text, kindLabel(ch.Kind)),
+				th.Paint(pal.White, dash(ch.Target)),
+				th.Paint(pal.Dim, dash(ch.Current)),
+				th.Paint(pal.Teal, dash(ch.Desired)),
+				th.Paint(pal.Dim, changeNote(ch)),
+			)
+		}
+		for _, l := range tbl.Render() {
+			out = append(out, "  "+l)
+		}
+		if c.InSync > 0 {
+			out = append(out, "  "+th.Paint(pal.Dim, fmt.Sprintf("%d more in sync", c.InSync)))
+		}
+		for _, w := range c.Warnings {
+			out = append(out, "  "+th.Token(render.Unknown, w))
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package plancmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/services/desired"
+)
+
+func testPlan() *desired.Plan {
+	return &desired.Plan{Clusters: []desired.ClusterPlan{
+		{Cluster: "prod-east", Region: "us-east-1", KubernetesVersion: "1.32", DesiredVersion: "1.33", InSync: 3, Changes: []desired.Change{
+			{Kind: desired.ChangeKubernetes, Current: "1.32", Desired: "1.33", Status: desired.ChangePending},
+			{Kind: desired.ChangeAddon, Target: "coredns", Current: "v1.11.1", Desired: "v1.11.4", Policy: "default", Status: desired.ChangePending, Reason: "default for 1.33"},
+			{Kind: desired.ChangeAddon, Target: "vpc-cni", Current: "v1.20.0", Desired: "v1.19.0", Policy: "latest", Status: desired.ChangeManual, Reason: "EKS can't downgrade an add-on"},
+		}, Warnings: []string{"nodegroup gpu: latest release unknown"}},
+		{Cluster: "prod-west", Region: "us-west-2", KubernetesVersion: "1.33", DesiredVersion: "1.33", InSync: 5},
+		{Cluster: "staging", Region: "us-west-2", Error: "AccessDenied"},
+	}}
+}
+
+func TestPlanLines(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	joined := strings.Join(planLines(th, testPlan(), "fleet.yaml"), "\n")
+
+	if strings.Contains(joined, "\x1b") {
+		t.Fatalf("ColorNone output contains ANSI escapes:\n%s", joined)
+	}
+	for _, want := range []string{
+		"PLAN  fleet.yaml · 3 clusters",
+		"2 to change",
+		"1 manual",
+		"1 in sync",
+		"1 unplanned",
+		"prod-east  us-east-1  1.32 → 1.33",
+		"control plane",
+		"default: default for 1.33",
+		"MANUAL",
+		"3 more in sync",
+		"nodegroup gpu: latest release unknown",
+		"in sync (5 managed)",
+		"unplanned: AccessDenied",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestExitForPlan(t *testing.T) {
+	code := func(err error) int {
+		var ec cli.ExitCoder
+		if errors.As(err, &ec) {
+			return ec.ExitCode()
+		}
+		return 0
+	}
+	p := testPlan()
+	if got := code(exitForPlan(p)); got != 2 {
+		t.Errorf("drift exit = %d, want 2", got)
+	}
+	p.Clusters[0].Changes[1].Status = desired.ChangeBlocked
+	if got := code(exitForPlan(p)); got != 3 {
+		t.Errorf("blocked exit = %d, want 3", got)
+	}
+	if got := code(exitForPlan(&desired.Plan{Clusters: p.Clusters[1:2]})); got != 0 {
+		t.Errorf("in-sync exit = %d, want 0", got)
+	}
+}
@@ -0,0 +1,186 @@
+package plugincmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/awsconfig"
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/plugins"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// Statuses shown by `plugin list`.
+const (
+	statusActive          = "active"
+	statusBuiltin         = "ignored: a built-in command has this name"
+	statusOvershadowedFmt = "ignored: overshadowed by %s"
+)
+
+func runPlugin(ctx context.Context, cmd *cli.Command, p plugins.Plugin) error {
+	env, err := pluginEnv(cmd)
+	if err != nil {
+		return err
+	}
+	root := cmd.Root()
+	code, err := plugins.Run(ctx, p, cmd.Args().Slice(), env, os.Stdin, root.Writer, root.ErrWriter)
+	if err != nil {
+		return fmt.Errorf("running plugin %s: %w", p.Path, err)
+	}
+	if code != 0 {
+		// The plugin has reported its own failure; pass its code on.
+		return cli.Exit("", code)
+	}
+	return nil
+}
+
+// pluginEnv resolves what the plugin is told: the settings target (active
+// context and its cluster), the AWS profile and region in awsconfig.Load's
+// precedence, the kubeconfig health checks would use, and the global flags.
+func pluginEnv(cmd *cli.Command) (plugins.Env, error) {
+	target := runner.SettingsTarget(cmd)
+	profile, region := awsconfig.Resolved(cmd)
+	env := plugins.Env{
+		Context:  target.Context,
+		Cluster:  target.Cluster,
+		Region:   region,
+		Profile:  profile,
+		LogLevel: logLevel(cmd),
+		Cache:    cmd.Bool("cache"),
+		NoColor:  cmd.Bool("no-color"),
+	}
+	if bin, err := os.Executable(); err == nil {
+		env.Bin = bin
+	}
+	if sandbox := cmd.String("sandbox"); sandbox != "" {
+		// The plugin may change directory before calling back into refresh.
+		abs, err := filepath.Abs(sandbox)
+		if err != nil {
+			return env, err
+		}
+		env.Sandbox = abs
+	}
+	kubeconfig, err := resolveKubeconfig(cmd)
+	if err != nil {
+		return env, err
+	}
+	env.Kubeconfig = kubeconfig
+	return env, nil
+}
+
+// resolveKubeconfig mirrors the kubeconfig chain of the health checks: a
+// refresh.yaml kubeconfig default for this plugin, cluster or context, then
+// $KUBECONFIG, then ~/.kube/config if it exists.
+func resolveKubeconfig(cmd *cli.Command) (string, error) {
+	eff, err := runner.EffectiveSettings(cmd)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := eff.Flags()["kubeconfig"]; ok {
+		if values := cliconfig.FlagStrings(v.Value); len(values) > 0 && values[0] != "" {
+			return values[0], nil
+		}
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return env, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, ".kube", "config")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// logLevel is the effective --log-level, with --verbose meaning debug.
+func logLevel(cmd *cli.Command) string {
+	level := factory.ParseLogLevel(cmd.String("log-level"))
+	if cmd.Bool("verbose") {
+		level = slog.LevelDebug
+	}
+	return strings.ToLower(level.String())
+}
+
+// pluginRow is one line of `plugin list`.
+type pluginRow struct {
+	Name   string `json:"name" yaml:"name"`
+	Path   string `json:"path" yaml:"path"`
+	Status string `json:"status" yaml:"status"`
+	Active bool   `json:"active" yaml:"active"`
+}
+
+// listRows describes every refresh-<name> executable on pathList: the ones
+// that run, and the ones a built-in command or an earlier PATH entry
+// shadows.
+func listRows(root *cli.Command, pathList string) []pluginRow {
+	var rows []pluginRow
+	for _, p := range plugins.Discover(pathList) {
+		row := pluginRow{Name: p.Name, Path: p.Path, Status: statusActive, Active: true}
+		if builtin(root, p.Name) {
+			row.Status, row.Active = statusBuiltin, false
+		}
+		rows = append(rows, row)
+		for _, path := range p.Overshadowed {
+			rows = append(rows, pluginRow{Name: p.Name, Path: path, Status: fmt.Sprintf(statusOvershadowedFmt, p.Path)})
+		}
+	}
+	return rows
+}
+
+func runList(_ context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	if err := runner.ValidateFormat(format, runner.FormatsStandard); err != nil {
+		return err
+	}
+	rows := listRows(cmd.Root(), os.Getenv("PATH"))
+	if handled, err := runner.EncodeStdout(format, map[string]any{"plugins": rowsOrEmpty(rows)}); handled {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No plugins found. Put an executable named %s<name> on PATH to add `refresh <name>`.\n", plugins.Prefix)
+		return nil
+	}
+	columns := []ui.Column{
+		{Title: "NAME", Min: 8},
+		{Title: "PATH", Min: 12, Max: 72},
+		{Title: "STATUS", Min: 8, Max: 72},
+	}
+	if ui.PlainOutput() {
+		table := ui.NewPTable(columns, ui.CyanHeaders())
+		for _, r := range rows {
+			table.AddRow(r.Name, r.Path, r.Status)
+		}
+		table.Render()
+		return nil
+	}
+	th := render.Default(os.Stdout)
+	tbl := th.NewTable(columns...)
+	for _, r := range rows {
+		status := th.Paint(th.Pal.Green, r.Status)
+		if !r.Active {
+			status = th.Paint(th.Pal.Yellow, r.Status)
+		}
+		tbl.Row(th.Paint(th.Pal.White, r.Name), r.Path, status)
+	}
+	for _, line := range tbl.Render() {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func rowsOrEmpty(rows []pluginRow) []pluginRow {
+	if rows == nil {
+		return []pluginRow{}
+	}
+	return rows
+}
@@ -0,0 +1,84 @@
+// Package plugincmd wires external plugins into the command tree — every
+// refresh-<name> executable on PATH becomes `refresh <name>` — and the
+// `refresh plugin list` command that shows what was found.
+package plugincmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/plugins"
+)
+
+// Command returns the `refresh plugin` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "plugin",
+		Usage: "List external refresh-<name> plugins found on PATH (list)",
+		Description: `Any executable named refresh-<name> on PATH runs as ` + "`refresh <name>`" + `, with
+every argument after the name passed through untouched. Plugins are listed
+under PLUGINS in ` + "`refresh --help`" + `. Global flags go before the name:
+
+  refresh --region eu-west-1 audit --strict     # runs refresh-audit --strict
+
+The plugin gets what refresh resolved for the invocation in its environment:
+REFRESH_CONTEXT, REFRESH_CLUSTER, REFRESH_REGION, REFRESH_PROFILE,
+REFRESH_KUBECONFIG and REFRESH_LOG_LEVEL, plus REFRESH_BIN (this refresh
+binary), REFRESH_SANDBOX and REFRESH_CACHE so its own refresh calls run the
+same way, and AWS_REGION/AWS_PROFILE for the AWS CLI and SDKs. Its exit code
+is refresh's.
+
+A plugin can't replace a built-in command: one named like a command or alias
+is ignored. When two directories on PATH hold the same plugin, the first
+wins, as in a shell.`,
+		Commands: []*cli.Command{
+			listCommand(),
+		},
+	}
+}
+
+func listCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "Show the plugins found on PATH and any that are shadowed",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runList(ctx, cmd) },
+	}
+}
+
+// Install adds a command to root for every plugin on PATH that doesn't
+// collide with a built-in command. Call it once the built-in tree is
+// complete.
+func Install(root *cli.Command) {
+	for _, p := range plugins.Discover(os.Getenv("PATH")) {
+		if builtin(root, p.Name) {
+			continue
+		}
+		root.Commands = append(root.Commands, pluginCommand(p))
+	}
+}
+
+// builtin reports whether name is taken by one of root's own commands or
+// aliases, or by the help command the CLI framework adds.
+func builtin(root *cli.Command, name string) bool {
+	if name == "help" || name == "h" {
+		return true
+	}
+	c := root.Command(name)
+	return c != nil && c.Category != plugins.Category
+}
+
+func pluginCommand(p plugins.Plugin) *cli.Command {
+	return &cli.Command{
+		Name:     p.Name,
+		Usage:    "Plugin " + p.Path,
+		Category: plugins.Category,
+		// Everything after the name, --help included, is the plugin's.
+		SkipFlagParsing: true,
+		Action:          func(ctx context.Context, cmd *cli.Command) error { return runPlugin(ctx, cmd, p) },
+	}
+}
@@ -0,0 +1,111 @@
+package plugincmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/plugins"
+)
+
+func testRoot() *cli.Command {
+	return &cli.Command{
+		Name: "refresh",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "log-level", Value: "warn"},
+			&cli.BoolFlag{Name: "verbose"},
+		},
+		Commands: []*cli.Command{
+			{Name: "status"},
+			{Name: "nodegroup", Aliases: []string{"ng"}},
+			Command(),
+		},
+	}
+}
+
+func pluginDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, n := range names {
+		if err := os.WriteFile(filepath.Join(dir, plugins.Prefix+n), []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInstallSkipsBuiltins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell-script plugins")
+	}
+	dir := pluginDir(t, "audit", "status", "ng", "help")
+	t.Setenv("PATH", dir)
+	root := testRoot()
+	Install(root)
+
+	var added []string
+	for _, c := range root.Commands {
+		if c.Category == plugins.Category {
+			added = append(added, c.Name)
+		}
+	}
+	if len(added) != 1 || added[0] != "audit" {
+		t.Fatalf("plugin commands = %v, want only audit", added)
+	}
+	if !root.Command("audit").SkipFlagParsing {
+		t.Error("a plugin's arguments must reach it unparsed")
+	}
+
+	rows := listRows(root, dir)
+	status := map[string]string{}
+	for _, r := range rows {
+		status[r.Name] = r.Status
+	}
+	if status["audit"] != statusActive || status["status"] != statusBuiltin || status["ng"] != statusBuiltin || status["help"] != statusBuiltin {
+		t.Errorf("plugin list statuses = %v", status)
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	var env plugins.Env
+	root := &cli.Command{
+		Name: "refresh",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "log-level", Value: "warn"},
+			&cli.BoolFlag{Name: "verbose"},
+			&cli.StringFlag{Name: "region"},
+			&cli.StringFlag{Name: "profile"},
+			&cli.StringFlag{Name: "sandbox"},
+			&cli.BoolFlag{Name: "cache"},
+			&cli.BoolFlag{Name: "no-color"},
+		},
+		Commands: []*cli.Command{{
+			Name:            "audit",
+			SkipFlagParsing: true,
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				var err error
+				env, err = pluginEnv(cmd)
+				return err
+			},
+		}},
+	}
+	if err := root.Run(context.Background(), []string{"refresh", "--verbose", "--profile", "ops", "--sandbox", "fleet.yaml", "audit", "--region", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	wantSandbox, _ := filepath.Abs("fleet.yaml")
+	if env.Profile != "ops" || env.Region != "eu-west-1" || env.LogLevel != "debug" || env.Kubeconfig != kubeconfig || env.Sandbox != wantSandbox {
+		t.Errorf("env = %+v", env)
+	}
+}
@@ -0,0 +1,47 @@
+package rollcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/rollview"
+)
+
+func runReplay(ctx context.Context, cmd *cli.Command) error {
+	path := cmd.Args().First()
+	if path == "" {
+		return fmt.Errorf("usage: refresh roll replay <recording.ndjson>")
+	}
+	speed, err := parseSpeed(cmd.String("speed"))
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening roll recording: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	records, err := noderoll.ReadRecording(f)
+	if err != nil {
+		return err
+	}
+	return rollview.Replay(ctx, render.Default(os.Stdout), os.Stdout, records,
+		rollview.ReplayOptions{Speed: speed, Nodegroup: cmd.String("nodegroup")})
+}
+
+// parseSpeed reads a playback speed: "10x", "10", "0.5x"; "0" disables the
+// delays altogether.
+func parseSpeed(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x"), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid --speed %q: use a multiplier like 1x, 10x or 0.5x (0 for no delay)", s)
+	}
+	return v, nil
+}
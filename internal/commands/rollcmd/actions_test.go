@@ -0,0 +1,17 @@
+package rollcmd
+
+import "testing"
+
+func TestParseSpeed(t *testing.T) {
+	for in, want := range map[string]float64{"1x": 1, "10x": 10, "10": 10, "0.5X": 0.5, "0": 0} {
+		got, err := parseSpeed(in)
+		if err != nil || got != want {
+			t.Errorf("parseSpeed(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"fast", "-2x", ""} {
+		if _, err := parseSpeed(bad); err == nil {
+			t.Errorf("parseSpeed(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package rollcmd wires `refresh roll`: working with recorded nodegroup
+// rolls (`nodegroup update --record`) after the fact — replaying them through
+// the live roll panel for postmortems, demos and observer fixtures.
+package rollcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Command returns the `refresh roll` command group.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "roll",
+		Usage: "Work with recorded nodegroup rolls (replay)",
+		Description: `'refresh nodegroup update --record roll.ndjson' writes every node snapshot,
+lifecycle event and EKS status poll of a roll to an NDJSON file. These
+commands read it back, offline: no AWS, no cluster.
+
+  refresh roll replay roll.ndjson              # real time
+  refresh roll replay roll.ndjson --speed 10x  # ten times faster`,
+		Commands: []*cli.Command{
+			replayCommand(),
+		},
+	}
+}
+
+func replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "replay",
+		Usage:     "Replay a recorded roll through the live roll panel",
+		ArgsUsage: "<recording.ndjson>",
+		Description: `Re-render a roll recorded with 'nodegroup update --record' through the same
+per-node panel the roll was watched with, paced by the recorded timestamps,
+then print the EKS update status timeline. A multi-nodegroup recording is
+replayed one nodegroup at a time unless --nodegroup picks one.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "speed", Usage: "Playback speed: 1x is real time, 10x ten times faster, 0 for no delay", Value: "1x"},
+			&cli.StringFlag{Name: "nodegroup", Aliases: []string{"n"}, Usage: "Replay only this nodegroup of a multi-nodegroup recording"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runReplay(ctx, cmd) },
+	}
+}
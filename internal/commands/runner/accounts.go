@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	appconfig "github.com/dantech2000/refresh/internal/config"
+)
+
+// AccountFlags are the multi-account inventory flags shared by the fleet
+// commands (status, cluster list, cluster upgrade-check -A, nodegroup update
+// --all-clusters). Without them a fleet command spans only the loaded
+// credentials' account.
+func AccountFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "account-role",
+			Usage:   "IAM role ARN to assume per account (repeatable); region fan-out runs inside each account",
+			Sources: cli.EnvVars("REFRESH_ACCOUNT_ROLES"),
+		},
+		&cli.StringFlag{
+			Name:    "org-role",
+			Usage:   "Discover accounts via AWS Organizations ListAccounts and assume this role name (or ARN template with {account}) in each",
+			Sources: cli.EnvVars("REFRESH_ORG_ROLE"),
+		},
+	}
+}
+
+// InventoryFromFlags reads the AccountFlags into an accounts.Inventory.
+func InventoryFromFlags(cmd *cli.Command) accounts.Inventory {
+	var arns []string
+	for _, v := range cmd.StringSlice("account-role") {
+		// The env var form is comma-separated; the flag form is repeatable.
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				arns = append(arns, a)
+			}
+		}
+	}
+	return accounts.Inventory{RoleARNs: arns, OrgRole: strings.TrimSpace(cmd.String("org-role"))}
+}
+
+// FleetRegions picks a fleet command's region set: explicit --region wins,
+// then --all-regions (REFRESH_EKS_REGIONS, else the partition sweep), else the
+// config region.
+func FleetRegions(cmd *cli.Command, awsCfg aws.Config) []string {
+	if r := cmd.StringSlice("region"); len(r) > 0 {
+		return r
+	}
+	if cmd.Bool("all-regions") {
+		if env := appconfig.RegionsFromEnv(); len(env) > 0 {
+			return env
+		}
+		return appconfig.GetRegionsForPartition(awsCfg.Region)
+	}
+	if awsCfg.Region != "" {
+		return []string{awsCfg.Region}
+	}
+	return appconfig.GetRegionsForPartition(awsCfg.Region)
+}
+
+// ResolveAccounts expands the command's account inventory into per-account
+// configs. Accounts that can't be assumed come back in failed (one error per
+// account) so the caller can degrade them the way it degrades region errors;
+// err is fatal (the inventory itself couldn't be listed).
+func ResolveAccounts(ctx context.Context, cmd *cli.Command, base aws.Config) (accts []accounts.Account, failed []error, err error) {
+	inv := InventoryFromFlags(cmd)
+	return accounts.NewResolver(base).Resolve(ctx, base, inv, cmd.Int("max-concurrency"))
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/health"
+)
+
+// HealthFlags select and tune the pre-flight health checks. They layer over
+// the health section of refresh.yaml (including clusters.<glob>.health), so a
+// flag wins for the run it's given on.
+func HealthFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{Name: "checks", Usage: "Run only these health checks (" + strings.Join(health.CheckIDs(), ", ") + ")"},
+		&cli.StringSliceFlag{Name: "skip-checks", Usage: "Leave these health checks out of the run"},
+		&cli.StringSliceFlag{Name: "threshold", Usage: "Override a health threshold, name=value (repeatable), e.g. peakFailCPUPercent=98"},
+		&cli.StringSliceFlag{Name: "blocking", Usage: "Override whether a check's failure blocks, check=true|false (repeatable), e.g. capacity=false"},
+	}
+}
+
+// HealthOptions builds the health.Options for one cluster: built-in defaults,
+// then refresh.yaml's health settings resolved for that cluster, then the
+// HealthFlags. cluster may differ from the command's own target (fleet runs);
+// empty means the command's target.
+func HealthOptions(cmd *cli.Command, cluster string) (health.Options, error) {
+	opts := health.DefaultOptions()
+	var h cliconfig.HealthSettings
+	layers, err := cliconfig.LoadLayers()
+	if err != nil {
+		return opts, err
+	}
+	if len(layers) > 0 {
+		t := SettingsTarget(cmd)
+		if cluster != "" {
+			t.Cluster = cluster
+		}
+		h = cliconfig.Resolve(layers, t).Health()
+	}
+
+	if opts.Thresholds, err = opts.Thresholds.With(h.Thresholds); err != nil {
+		return opts, fmt.Errorf("%s health.thresholds: %w", cliconfig.SettingsFileName, err)
+	}
+	opts.Checks, opts.SkipChecks, opts.Blocking = h.Checks, h.SkipChecks, h.Blocking
+
+	if v := splitList(cmd.StringSlice("checks")); len(v) > 0 {
+		opts.Checks = v
+	}
+	if v := splitList(cmd.StringSlice("skip-checks")); len(v) > 0 {
+		opts.SkipChecks = v
+	}
+	overrides := map[string]float64{}
+	for _, kv := range splitList(cmd.StringSlice("threshold")) {
+		k, v, err := splitPair(kv, "--threshold")
+		if err != nil {
+			return opts, err
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return opts, fmt.Errorf("--threshold %s: %q is not a number", k, v)
+		}
+		overrides[k] = f
+	}
+	if opts.Thresholds, err = opts.Thresholds.With(overrides); err != nil {
+		return opts, err
+	}
+	for _, kv := range splitList(cmd.StringSlice("blocking")) {
+		k, v, err := splitPair(kv, "--blocking")
+		if err != nil {
+			return opts, err
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("--blocking %s: %q is not true or false", k, v)
+		}
+		if opts.Blocking == nil {
+			opts.Blocking = map[string]bool{}
+		}
+		opts.Blocking[k] = b
+	}
+	return opts, opts.Validate()
+}
+
+// splitList flattens repeatable and comma-separated values, dropping blanks.
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// splitPair splits "name=value" for the named flag.
+func splitPair(kv, flag string) (string, string, error) {
+	k, v, ok := strings.Cut(kv, "=")
+	k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+	if !ok || k == "" || v == "" {
+		return "", "", fmt.Errorf("%s %q: want name=value", flag, kv)
+	}
+	return k, v, nil
+}
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/health"
+)
+
+// healthOptionsFor parses args against a command carrying HealthFlags and
+// resolves the options for cluster.
+func healthOptionsFor(t *testing.T, cluster string, args ...string) (health.Options, error) {
+	t.Helper()
+	var opts health.Options
+	var oerr error
+	cmd := &cli.Command{
+		Name:  "update",
+		Flags: append([]cli.Flag{&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}}}, HealthFlags()...),
+		Action: func(_ context.Context, c *cli.Command) error {
+			opts, oerr = HealthOptions(c, cluster)
+			return nil
+		},
+	}
+	if err := cmd.Run(context.Background(), append([]string{"update"}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	return opts, oerr
+}
+
+func TestHealthOptions_ClusterScopeThenFlags(t *testing.T) {
+	withSettings(t, `
+health:
+  skipChecks: [balance]
+clusters:
+  gpu-*:
+    health:
+      thresholds: {peakFailCPUPercent: 98, minWarnCPUHeadroomPercent: 5}
+      blocking: {capacity: false}
+`)
+	opts, err := healthOptionsFor(t, "gpu-prod", "--threshold", "peakFailCPUPercent=99", "--blocking", "pdbs=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := opts.Thresholds
+	if th.PeakFailCPUPercent != 99 || th.MinWarnCPUHeadroomPercent != 5 {
+		t.Errorf("thresholds = %+v, want the flag over the gpu-* scope", th)
+	}
+	if opts.Blocking["capacity"] || !opts.Blocking["pdbs"] {
+		t.Errorf("blocking = %v", opts.Blocking)
+	}
+	if len(opts.SkipChecks) != 1 || opts.SkipChecks[0] != "balance" {
+		t.Errorf("skipChecks = %v", opts.SkipChecks)
+	}
+
+	// Another cluster keeps the defaults; --skip-checks replaces the file's list.
+	opts, err = healthOptionsFor(t, "web-prod", "--skip-checks", "quotas,pdbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Thresholds != health.DefaultThresholds() || len(opts.Blocking) != 0 {
+		t.Errorf("web-prod should not see the gpu-* scope: %+v", opts)
+	}
+	if strings.Join(opts.SkipChecks, ",") != "quotas,pdbs" {
+		t.Errorf("skipChecks = %v", opts.SkipChecks)
+	}
+}
+
+func TestHealthOptions_Errors(t *testing.T) {
+	withSettings(t, "health: {thresholds: {peakFail: 98}}\n")
+	if _, err := healthOptionsFor(t, "prod"); err == nil || !strings.Contains(err.Error(), "refresh.yaml health.thresholds") {
+		t.Errorf("file typo: err = %v", err)
+	}
+
+	withSettings(t, "")
+	for _, args := range [][]string{
+		{"--threshold", "peakFailCPUPercent"},
+		{"--threshold", "peakFailCPUPercent=high"},
+		{"--blocking", "capacity=maybe"},
+		{"--checks", "capacty"},
+	} {
+		if _, err := healthOptionsFor(t, "prod", args...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
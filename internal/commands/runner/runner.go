@@ -21,6 +21,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awscache"
 	"github.com/dantech2000/refresh/internal/awsconfig"
 	"github.com/dantech2000/refresh/internal/commands/clusterview"
 	"github.com/dantech2000/refresh/internal/commands/factory"
@@ -96,9 +97,14 @@ func SetupAWSWithTimeout(ctx context.Context, cmd *cli.Command, defaultTimeout t
 }
 
 // SetupAWSStrict is like SetupAWS but uses ValidateAWSCredentials and prints
-// the credential help message on failure (used by destructive commands).
+// the credential help message on failure (used by destructive commands). The
+// returned context bypasses the --cache response cache: destructive commands
+// gate on what they read.
 func SetupAWSStrict(ctx context.Context, cmd *cli.Command) (context.Context, context.CancelFunc, aws.Config, error) {
-	return setupAWS(ctx, cmd, 0, checkCredentialsStrict)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return setupAWS(awscache.Bypass(ctx), cmd, 0, checkCredentialsStrict)
 }
 
 // ParseFilters parses repeated key=value --filter flag values into a map.
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/selector"
+)
+
+// SelectorFlags are the tag-selector flags shared by the fleet commands
+// (status, cluster list, cluster upgrade-check -A, nodegroup update
+// --all-clusters).
+func SelectorFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "selector",
+			Aliases: []string{"l"},
+			Usage:   "Only clusters whose EKS tags match, kubectl-style: env=prod,team!=data, tier in (web,api), owner, !legacy",
+		},
+		&cli.StringFlag{
+			Name:  "group",
+			Usage: "Only clusters matching a saved context group (see 'refresh context group'); combines with --selector",
+		},
+	}
+}
+
+// ResolveSelector reads --selector and --group into one selector; empty
+// when neither is set.
+func ResolveSelector(cmd *cli.Command) (selector.Selector, error) {
+	sel, err := selector.Parse(cmd.String("selector"))
+	if err != nil {
+		return selector.Selector{}, err
+	}
+	name := strings.TrimSpace(cmd.String("group"))
+	if name == "" {
+		return sel, nil
+	}
+	f, err := cliconfig.Load()
+	if err != nil {
+		return selector.Selector{}, err
+	}
+	group, err := f.Group(name)
+	if err != nil {
+		return selector.Selector{}, err
+	}
+	return sel.And(group), nil
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+func resolveSelectorArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var got string
+	cmd := &cli.Command{
+		Name:  "app",
+		Flags: SelectorFlags(),
+		Action: func(_ context.Context, c *cli.Command) error {
+			sel, err := ResolveSelector(c)
+			got = sel.String()
+			return err
+		},
+	}
+	err := cmd.Run(context.Background(), append([]string{"app"}, args...))
+	return got, err
+}
+
+func TestResolveSelector(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	f := &cliconfig.File{}
+	if err := f.SetGroup("prod-web", "tier=web, env=prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cliconfig.Save(f); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := resolveSelectorArgs(t); err != nil || got != "" {
+		t.Errorf("no flags = %q, %v; want the empty selector", got, err)
+	}
+	if got, err := resolveSelectorArgs(t, "-l", "team!=data", "--group", "prod-web"); err != nil || got != "env=prod,team!=data,tier=web" {
+		t.Errorf("--selector + --group = %q, %v", got, err)
+	}
+	if _, err := resolveSelectorArgs(t, "--group", "nope"); err == nil || !strings.Contains(err.Error(), "saved: prod-web") {
+		t.Errorf("unknown group err = %v", err)
+	}
+	if _, err := resolveSelectorArgs(t, "-l", "env in prod"); err == nil {
+		t.Error("a malformed selector should fail")
+	}
+}
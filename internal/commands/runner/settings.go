@@ -0,0 +1,187 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// InstallSettings wraps the Before hook of every runnable command under root
+// so refresh.yaml defaults are applied after flag parsing and before the
+// action runs. Each leaf sees its own flags, so per-command sections work for
+// any command without the command opting in.
+func InstallSettings(root *cli.Command) {
+	_ = root.Walk(func(c *cli.Command) error {
+		if c.Action == nil || len(c.Commands) > 0 {
+			return nil
+		}
+		prev := c.Before
+		c.Before = func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if err := ApplySettings(cmd); err != nil {
+				return ctx, err
+			}
+			if prev != nil {
+				return prev(ctx, cmd)
+			}
+			return ctx, nil
+		}
+		return nil
+	})
+}
+
+// ApplySettings fills every flag the user didn't set — on the command line or
+// through its env var — from the effective refresh.yaml settings for cmd.
+// That keeps the precedence flags > env > refresh.yaml (context, cluster,
+// repo, user) > built-in defaults.
+func ApplySettings(cmd *cli.Command) error {
+	eff, err := EffectiveSettings(cmd)
+	if err != nil || len(eff) == 0 {
+		return err
+	}
+	flags := eff.Flags()
+	names := make([]string, 0, len(flags))
+	for n := range flags {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := flags[name]
+		f := lookupFlag(cmd, name)
+		if f == nil {
+			// A defaults key only applies where the flag exists; a key in
+			// this command's own section is a typo worth failing on.
+			if v.Command != "" {
+				return fmt.Errorf("%s: %q has no --%s flag", v.Source, v.Command, name)
+			}
+			continue
+		}
+		// An alias (-y for --yes) mustn't get round the gate flags refresh.yaml
+		// can't default.
+		for _, n := range f.Names() {
+			if err := cliconfig.CheckGateFlag(n); err != nil {
+				return fmt.Errorf("%s: %s: %w", v.Source, name, err)
+			}
+		}
+		if cmd.IsSet(name) {
+			continue
+		}
+		for _, s := range cliconfig.FlagStrings(v.Value) {
+			if err := cmd.Set(name, s); err != nil {
+				return fmt.Errorf("%s: --%s: %w", v.Source, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EffectiveSettings resolves refresh.yaml for cmd: its command path, the
+// cluster it targets (flag, first positional, or the active context), and the
+// active context.
+func EffectiveSettings(cmd *cli.Command) (cliconfig.Effective, error) {
+	layers, err := cliconfig.LoadLayers()
+	if err != nil || len(layers) == 0 {
+		return nil, err
+	}
+	return cliconfig.Resolve(layers, SettingsTarget(cmd)), nil
+}
+
+// SettingsTarget is the cliconfig.Target for cmd.
+func SettingsTarget(cmd *cli.Command) cliconfig.Target {
+	t := cliconfig.Target{Command: CommandPath(cmd)}
+	if hasFlag(cmd, "cluster") {
+		t.Cluster = PositionalSlot(cmd, "cluster")
+	}
+	if f, err := cliconfig.Load(); err == nil {
+		if name, active, ok := f.Active(); ok {
+			t.Context = name
+			if t.Cluster == "" {
+				t.Cluster = active.Cluster
+			}
+		}
+	}
+	return t
+}
+
+// SkipValues reads a repeatable skip flag and expands "@name" references to
+// the named skip lists from refresh.yaml.
+func SkipValues(cmd *cli.Command, name string) ([]string, error) {
+	values := cmd.StringSlice(name)
+	needsLists := false
+	for _, v := range values {
+		if strings.HasPrefix(strings.TrimSpace(v), "@") {
+			needsLists = true
+			break
+		}
+	}
+	if !needsLists {
+		return values, nil
+	}
+	eff, err := EffectiveSettings(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return eff.ExpandSkipLists(values)
+}
+
+// CommandPath is cmd's path below the root ("nodegroup update").
+func CommandPath(cmd *cli.Command) string {
+	p := cmd.Path()
+	if len(p) > 1 {
+		p = p[1:]
+	}
+	return strings.Join(p, " ")
+}
+
+// hasFlag reports whether cmd or an ancestor defines a flag called name.
+func hasFlag(cmd *cli.Command, name string) bool {
+	return lookupFlag(cmd, name) != nil
+}
+
+// lookupFlag returns the flag of cmd or an ancestor that answers to name,
+// or nil.
+func lookupFlag(cmd *cli.Command, name string) cli.Flag {
+	for _, c := range cmd.Lineage() {
+		for _, f := range c.Flags {
+			for _, n := range f.Names() {
+				if n == name {
+					return f
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ClusterFlag returns the value of flag name for one cluster of a fleet run,
+// where clusters other than the command's own target are visited. current is
+// the flag's parsed value. A value from the command line or env wins; a value
+// ApplySettings filled in for the command's target is re-resolved from
+// refresh.yaml for cluster.
+func ClusterFlag(cmd *cli.Command, cluster, name string, current []string) ([]string, error) {
+	layers, err := cliconfig.LoadLayers()
+	if err != nil || len(layers) == 0 {
+		return current, err
+	}
+	base, fromSettings := cliconfig.Resolve(layers, SettingsTarget(cmd)).Flags()[name]
+	if fromSettings && !slices.Equal(cliconfig.FlagStrings(base.Value), current) {
+		return current, nil // overridden on the command line
+	}
+	if !fromSettings && cmd.IsSet(name) {
+		return current, nil
+	}
+	t := SettingsTarget(cmd)
+	t.Cluster = cluster
+	if v, ok := cliconfig.Resolve(layers, t).Flags()[name]; ok {
+		return cliconfig.FlagStrings(v.Value), nil
+	}
+	if fromSettings {
+		return nil, nil // set for the command's target only
+	}
+	return current, nil
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// withSettings writes a user-level refresh.yaml into an isolated config dir.
+func withSettings(t *testing.T, body string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", dir)
+	t.Setenv("REFRESH_CONTEXT", "")
+	if err := os.WriteFile(filepath.Join(dir, cliconfig.SettingsFileName), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runWithSettings runs `app roll <args>` with settings installed and returns
+// the leaf command as its action saw it.
+func runWithSettings(t *testing.T, args ...string) (*cli.Command, error) {
+	t.Helper()
+	var captured *cli.Command
+	app := &cli.Command{
+		Name:  "app",
+		Flags: []cli.Flag{&cli.StringFlag{Name: "log-level", Value: "warn"}},
+		Commands: []*cli.Command{{
+			Name: "roll",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}},
+				&cli.DurationFlag{Name: "poll-interval", Value: 15 * time.Second, Sources: cli.EnvVars("TEST_POLL_INTERVAL")},
+				&cli.BoolFlag{Name: "require-healthy"},
+				&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}},
+				&cli.StringSliceFlag{Name: "skip"},
+			},
+			Action: func(_ context.Context, c *cli.Command) error {
+				captured = c
+				return nil
+			},
+		}},
+	}
+	InstallSettings(app)
+	err := app.Run(context.Background(), append([]string{"app", "roll"}, args...))
+	return captured, err
+}
+
+func TestApplySettings_FillsUnsetFlags(t *testing.T) {
+	withSettings(t, `
+defaults:
+  require-healthy: true
+  dry-run: true           # no such flag on roll: ignored
+commands:
+  roll:
+    poll-interval: 30s
+    skip: [coredns, "@helm"]
+skipLists:
+  helm: [external-dns]
+`)
+	cmd, err := runWithSettings(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Duration("poll-interval"); got != 30*time.Second {
+		t.Errorf("poll-interval = %v, want 30s from refresh.yaml", got)
+	}
+	if !cmd.Bool("require-healthy") {
+		t.Error("require-healthy should come from defaults")
+	}
+	skip, err := SkipValues(cmd, "skip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"coredns", "external-dns"}; !reflect.DeepEqual(skip, want) {
+		t.Errorf("skip = %v, want %v", skip, want)
+	}
+}
+
+func TestApplySettings_FlagsAndEnvWin(t *testing.T) {
+	withSettings(t, "commands:\n  roll:\n    poll-interval: 30s\n")
+
+	cmd, err := runWithSettings(t, "--poll-interval", "5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Duration("poll-interval"); got != 5*time.Second {
+		t.Errorf("flag: poll-interval = %v, want 5s", got)
+	}
+
+	t.Setenv("TEST_POLL_INTERVAL", "7s")
+	cmd, err = runWithSettings(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Duration("poll-interval"); got != 7*time.Second {
+		t.Errorf("env: poll-interval = %v, want 7s", got)
+	}
+}
+
+func TestApplySettings_PerClusterScope(t *testing.T) {
+	withSettings(t, "clusters:\n  prod-*:\n    commands:\n      roll: {poll-interval: 1m}\n")
+
+	cmd, err := runWithSettings(t, "-c", "prod-east")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Duration("poll-interval"); got != time.Minute {
+		t.Errorf("prod-east: poll-interval = %v, want 1m", got)
+	}
+	cmd, err = runWithSettings(t, "-c", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Duration("poll-interval"); got != 15*time.Second {
+		t.Errorf("dev: poll-interval = %v, want the 15s default", got)
+	}
+}
+
+func TestApplySettings_UnknownCommandFlagFails(t *testing.T) {
+	withSettings(t, "commands:\n  roll:\n    poll-intreval: 30s\n")
+	_, err := runWithSettings(t)
+	if err == nil || !strings.Contains(err.Error(), "--poll-intreval") {
+		t.Fatalf("err = %v, want a typo error naming the flag", err)
+	}
+}
+
+func TestApplySettings_GateFlagsNeverDefaulted(t *testing.T) {
+	withSettings(t, "")
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	for _, body := range []string{"defaults: {yes: true}\n", "commands:\n  roll: {\"y\": true}\n"} {
+		if err := os.WriteFile(filepath.Join(repo, cliconfig.SettingsFileName), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cmd, err := runWithSettings(t)
+		if err == nil || !strings.Contains(err.Error(), "--yes") {
+			t.Errorf("%q: err = %v, want the gate flag refused", body, err)
+		}
+		if cmd != nil && cmd.Bool("yes") {
+			t.Errorf("%q: a repo refresh.yaml turned on --yes", body)
+		}
+	}
+}
+
+func TestClusterFlag_ResolvesPerCluster(t *testing.T) {
+	withSettings(t, "clusters:\n  prod-*:\n    commands:\n      roll: {skip: [ng-prod]}\n  dev-*:\n    commands:\n      roll: {skip: [ng-dev]}\n")
+
+	cmd, err := runWithSettings(t, "-c", "prod-east")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := cmd.StringSlice("skip")
+	for cluster, want := range map[string][]string{"prod-west": {"ng-prod"}, "dev-1": {"ng-dev"}, "other": nil} {
+		got, err := ClusterFlag(cmd, cluster, "skip", current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: skip = %v, want %v", cluster, got, want)
+		}
+	}
+
+	cmd, err = runWithSettings(t, "-c", "prod-east", "--skip", "mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ClusterFlag(cmd, "dev-1", "skip", cmd.StringSlice("skip")); !reflect.DeepEqual(got, []string{"mine"}) {
+		t.Errorf("explicit flag: skip = %v, want [mine]", got)
+	}
+}
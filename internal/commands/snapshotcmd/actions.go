@@ -0,0 +1,59 @@
+package snapshotcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/services/snapshot"
+)
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	clusterName, listed, err := runner.ResolveClusterOrList(ctx, awsCfg, cmd)
+	if err != nil || listed {
+		return err
+	}
+
+	opts := snapshot.Options{To: cmd.String("to")}
+	if cmd.Bool("kubernetes") {
+		client, diag, err := health.BuildKubeClient(cmd.String("kubeconfig"))
+		if err == nil {
+			err = health.ProbeConnection(ctx, client)
+		}
+		if err != nil {
+			return fmt.Errorf("--kubernetes: Kubernetes API unavailable (%s): %w", diag, err)
+		}
+		// The kubeconfig names its own cluster; say which one is captured.
+		fmt.Fprintf(os.Stderr, "Capturing Kubernetes objects via %s\n", diag)
+		opts.Kubernetes = client
+	}
+
+	svc := snapshot.NewService(snapshot.NewClients(awsCfg), awsCfg.Region, factory.NewDefaultLogger(nil))
+	// Progress goes to stderr: stdout is the fixture.
+	fmt.Fprintf(os.Stderr, "Capturing cluster %s in %s\n", clusterName, awsCfg.Region)
+	snap, err := svc.Capture(ctx, clusterName, opts)
+	if err != nil {
+		return err
+	}
+	data, err := snap.Encode()
+	if err != nil {
+		return err
+	}
+	for _, w := range snap.Warnings {
+		fmt.Fprintln(os.Stderr, color.YellowString("warning: %s", w))
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
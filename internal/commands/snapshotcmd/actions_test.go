@@ -0,0 +1,176 @@
+package snapshotcmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/awsconfig"
+	"github.com/dantech2000/refresh/internal/sandbox"
+)
+
+const prodRole = "arn:aws:iam::111122223333:role/platform-admin"
+
+const prodFixture = `account: "111122223333"
+region: us-east-1
+addonVersions:
+  coredns:
+    - {version: v1.11.3-eksbuild.1, kubernetes: ["1.31"], default: ["1.31"]}
+    - {version: v1.11.4-eksbuild.2, kubernetes: ["1.31", "1.32"], default: ["1.32"]}
+amiReleases:
+  - {kubernetes: "1.31", release: 1.31.0-20260101}
+  - {kubernetes: "1.32", release: 1.32.0-20260601}
+clusters:
+  - name: prod
+    version: "1.31"
+    tags:
+      owner-role: ` + prodRole + `
+      aws:cloudformation:stack-id: arn:aws:cloudformation:us-east-1:111122223333:stack/prod-eks/0a1b2c3d
+      logs: arn:aws:s3:::acme-prod-logs
+    addons:
+      - {name: coredns, version: v1.11.3-eksbuild.1}
+    nodegroups:
+      - {name: workers, releaseVersion: 1.31.0-20260101, desired: 2}
+    insights:
+      - name: Cluster health
+        kubernetesVersion: "1.32"
+        status: WARNING
+        recommendation: Grant ` + prodRole + ` access to the new access entries.
+`
+
+// snapshotOf runs `refresh snapshot prod` against the fixture at path and
+// returns what it wrote to stdout.
+func snapshotOf(t *testing.T, path string) string {
+	t.Helper()
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	t.Setenv("REFRESH_CONTEXT", "")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	sim, err := sandbox.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	awsconfig.UseSandbox(sim)
+	t.Cleanup(func() { awsconfig.UseSandbox(nil) })
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	var errOut bytes.Buffer
+	app := &cli.Command{
+		Name:      "refresh",
+		Flags:     []cli.Flag{&cli.StringFlag{Name: "region"}, &cli.StringFlag{Name: "profile"}},
+		ErrWriter: &errOut,
+		Commands:  []*cli.Command{Command()},
+	}
+	runErr := app.Run(context.Background(), []string{"refresh", "--region", "us-east-1", "snapshot", "prod"})
+	_ = w.Close()
+	stdout, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("snapshot: %v\nstderr:\n%s", runErr, errOut.String())
+	}
+	return string(stdout)
+}
+
+func writeFixture(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// body drops the header comment, which records when the snapshot was taken.
+func body(snapshot string) string {
+	var lines []string
+	for _, l := range strings.Split(snapshot, "\n") {
+		if !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineDiff returns the lines only in a ("- ") and only in b ("+ ").
+func lineDiff(a, b string) []string {
+	count := map[string]int{}
+	for _, l := range strings.Split(a, "\n") {
+		count[l]++
+	}
+	for _, l := range strings.Split(b, "\n") {
+		count[l]--
+	}
+	var diff []string
+	for _, l := range strings.Split(a, "\n") {
+		if count[l] > 0 {
+			diff = append(diff, "- "+strings.TrimSpace(l))
+			count[l]--
+		}
+	}
+	for _, l := range strings.Split(b, "\n") {
+		if count[l] < 0 {
+			diff = append(diff, "+ "+strings.TrimSpace(l))
+			count[l]++
+		}
+	}
+	return diff
+}
+
+// TestSnapshotRedactedRoundTrip captures a cluster, replays the redacted
+// snapshot as a fixture and captures it again: nothing identifying survives,
+// and the second capture is the first.
+func TestSnapshotRedactedRoundTrip(t *testing.T) {
+	first := snapshotOf(t, writeFixture(t, prodFixture))
+	for _, leak := range []string{"111122223333", "platform-admin", "prod-eks", "acme-prod-logs"} {
+		if strings.Contains(first, leak) {
+			t.Errorf("snapshot leaks %q:\n%s", leak, first)
+		}
+	}
+	if !strings.Contains(first, "# Account IDs and ARNs are redacted.") {
+		t.Errorf("header should say what is redacted:\n%s", first)
+	}
+
+	// The role reads the same in the tag and in the insight.
+	var role string
+	for _, l := range strings.Split(first, "\n") {
+		if _, v, ok := strings.Cut(l, "owner-role: "); ok {
+			role = v
+		}
+	}
+	if !strings.HasPrefix(role, "arn:aws:iam::123456789012:redacted-") {
+		t.Fatalf("owner-role = %q", role)
+	}
+	if !strings.Contains(first, "Grant "+role+" access") {
+		t.Errorf("the insight should name the role as the tag does (%s):\n%s", role, first)
+	}
+
+	second := snapshotOf(t, writeFixture(t, first))
+	if diff := lineDiff(body(first), body(second)); len(diff) != 0 {
+		t.Errorf("re-capturing the snapshot changed it:\n%s", strings.Join(diff, "\n"))
+	}
+}
+
+// TestSnapshotDiff checks that two snapshots of a changed cluster differ only
+// where it changed: redaction is the same in both.
+func TestSnapshotDiff(t *testing.T) {
+	before := snapshotOf(t, writeFixture(t, prodFixture))
+	after := snapshotOf(t, writeFixture(t, strings.Replace(prodFixture,
+		"{name: coredns, version: v1.11.3-eksbuild.1}", "{name: coredns, version: v1.11.4-eksbuild.2}", 1)))
+
+	got := strings.Join(lineDiff(body(before), body(after)), "\n")
+	want := "- version: v1.11.3-eksbuild.1\n+ version: v1.11.4-eksbuild.2"
+	if got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+}
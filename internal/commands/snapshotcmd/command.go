@@ -0,0 +1,49 @@
+// Package snapshotcmd wires the top-level `refresh snapshot` command: capture
+// a live cluster as a redacted sandbox fixture for offline rehearsal.
+package snapshotcmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+
+	appconfig "github.com/dantech2000/refresh/internal/config"
+)
+
+// Command returns the `refresh snapshot` top-level command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "snapshot",
+		Usage:     "Capture a cluster as a sandbox fixture for offline rehearsal",
+		ArgsUsage: "[cluster]",
+		Description: `Read everything the upgrade planners and health checks read about one cluster
+and write it to stdout as a fixture for the global --sandbox flag: the cluster
+itself, its nodegroups with their launch templates and instances, its add-ons
+and the versions published for them, its upgrade insights, the support
+calendar, and the EKS-optimized AMI releases for its AMI types. The add-on and
+AMI catalogues cover every Kubernetes version up to --to (default: the next
+minor). With --kubernetes, the nodes, pods, PodDisruptionBudgets and
+Deployments are captured too, pruned to the fields the health checks use (no
+environment, commands, probes or config volumes).
+
+Account IDs and ARNs are redacted: every ARN is replaced whole by a consistent
+hash, and the caller's account and any ECR registry account become
+123456789012. Nothing is changed. Reads that only enrich the fixture
+(insights, calendar, AMI releases, Kubernetes objects) become warnings on
+stderr and in the fixture's header comment when they fail.
+
+A reviewer without the account's credentials can then rehearse the upgrade:
+
+  refresh snapshot -c prod-east --to 1.33 --kubernetes > prod.yaml
+  refresh --sandbox prod.yaml cluster upgrade -c prod-east --to 1.33 --dry-run
+  refresh --sandbox prod.yaml cluster upgrade-check -c prod-east`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "cluster", Aliases: []string{"c"}, Usage: "EKS cluster name or pattern"},
+			&cli.StringFlag{Name: "to", Usage: "Capture add-on and AMI catalogues through this Kubernetes version (default: the next minor)"},
+			&cli.BoolFlag{Name: "kubernetes", Aliases: []string{"k"}, Usage: "Also capture nodes, pods, PodDisruptionBudgets and Deployments"},
+			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig for --kubernetes (defaults to $KUBECONFIG, then ~/.kube/config)"},
+			&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Usage: "Operation timeout", Value: appconfig.DefaultTimeout, Sources: cli.EnvVars("REFRESH_TIMEOUT")},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error { return run(ctx, cmd) },
+	}
+}
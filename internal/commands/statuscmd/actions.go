@@ -6,17 +6,17 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	"github.com/dantech2000/refresh/internal/cliconfig"
 	"github.com/dantech2000/refresh/internal/commands/factory"
 	"github.com/dantech2000/refresh/internal/commands/runner"
 	"github.com/dantech2000/refresh/internal/commands/statusview"
-	appconfig "github.com/dantech2000/refresh/internal/config"
 	statussvc "github.com/dantech2000/refresh/internal/services/status"
 )
 
@@ -24,17 +24,37 @@ func runStatus(ctx context.Context, cmd *cli.Command) error {
 	if err := runner.ValidateFormat(cmd.String("format"), runner.FormatsStandard); err != nil {
 		return err
 	}
+	severities, err := amichangelog.LoadSeverities(cmd.String("cve-severity"))
+	if err != nil {
+		return err
+	}
+	sel, err := runner.ResolveSelector(cmd)
+	if err != nil {
+		return err
+	}
 	ctx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
 	if err != nil {
 		return err
 	}
 	defer cancel()
 
-	regions := resolveRegions(cmd, awsCfg)
+	accts, acctErrs, err := runner.ResolveAccounts(ctx, cmd, awsCfg)
+	if err != nil {
+		return err
+	}
+	regions := runner.FleetRegions(cmd, awsCfg)
 	maxConc := cmd.Int("max-concurrency")
 	opts := statussvc.ListOptions{
 		NamePattern:    strings.TrimSpace(cmd.Args().First()),
 		MaxConcurrency: maxConc,
+		Severities:     severities,
+		Selector:       sel,
+	}
+	if !cmd.Bool("no-security") {
+		opts.ReleaseNotes = amichangelog.Default()
+	}
+	if opts.SLA, err = slaResolver(); err != nil {
+		return err
 	}
 
 	start := time.Now()
@@ -43,8 +63,11 @@ func runStatus(ctx context.Context, cmd *cli.Command) error {
 		regionErrs []error
 	)
 	gather := func() error {
-		statuses, regionErrs = gatherFleet(ctx, awsCfg, regions, opts, maxConc)
-		// Only a total failure (no data from any region) is fatal.
+		statuses, regionErrs = gatherFleet(ctx, accts, regions, opts, maxConc)
+		// Accounts that couldn't be assumed degrade exactly like regions that
+		// couldn't be listed.
+		regionErrs = append(acctErrs, regionErrs...)
+		// Only a total failure (no data from any account/region) is fatal.
 		if len(statuses) == 0 && len(regionErrs) > 0 {
 			return regionErrs[0]
 		}
@@ -61,7 +84,11 @@ func runStatus(ctx context.Context, cmd *cli.Command) error {
 
 	sortStatuses(statuses, cmd.String("sort"), cmd.Bool("desc"))
 
-	if handled, err := runner.EncodeStdout(cmd.String("format"), statussvc.FleetStatus{Clusters: statuses}); handled {
+	fleet := statussvc.FleetStatus{Clusters: statuses}
+	for _, e := range regionErrs {
+		fleet.Errors = append(fleet.Errors, e.Error())
+	}
+	if handled, err := runner.EncodeStdout(cmd.String("format"), fleet); handled {
 		if err != nil {
 			return err
 		}
@@ -73,82 +100,41 @@ func runStatus(ctx context.Context, cmd *cli.Command) error {
 	return exitForStatuses(statuses)
 }
 
-// resolveRegions picks the region set: explicit --region wins, then
-// --all-regions (partition sweep / REFRESH_EKS_REGIONS), else the config region.
-func resolveRegions(cmd *cli.Command, awsCfg aws.Config) []string {
-	if r := cmd.StringSlice("region"); len(r) > 0 {
-		return r
-	}
-	if cmd.Bool("all-regions") {
-		if env := appconfig.RegionsFromEnv(); len(env) > 0 {
-			return env
-		}
-		return appconfig.GetRegionsForPartition(awsCfg.Region)
-	}
-	if awsCfg.Region != "" {
-		return []string{awsCfg.Region}
-	}
-	return appconfig.GetRegionsForPartition(awsCfg.Region)
-}
-
-// gatherFleet fans out across regions with bounded concurrency, returning the
-// merged cluster statuses and any per-region errors.
-func gatherFleet(ctx context.Context, baseCfg aws.Config, regions []string, opts statussvc.ListOptions, maxConc int) ([]statussvc.ClusterStatus, []error) {
-	if maxConc <= 0 {
-		maxConc = appconfig.DefaultMaxConcurrency
-	}
-	// Build the shared logger once through the factory so service logs honor the
-	// global --log-level/--verbose (quiet by default) instead of leaking at
-	// Info level into the TUI. (REF-129)
-	logger := factory.NewDefaultLogger(nil)
-	var (
-		mu   sync.Mutex
-		all  []statussvc.ClusterStatus
-		errs []error
-		wg   sync.WaitGroup
-		sem  = make(chan struct{}, maxConc)
-	)
-	for _, region := range regions {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(r string) {
-			defer wg.Done()
-			defer func() { <-sem }()
-
-			cfg := baseCfg.Copy()
-			cfg.Region = r
-			svc := statussvc.NewService(cfg, logger)
-			statuses, err := svc.ListClusterStatuses(ctx, opts)
-
-			mu.Lock()
-			defer mu.Unlock()
-			if err != nil {
-				errs = append(errs, fmt.Errorf("region %s: %w", r, err))
-				return
-			}
-			all = append(all, statuses...)
-		}(region)
-	}
-	wg.Wait()
-	return all, errs
+// gatherFleet gathers the fleet through the status service. The shared
+// logger is built once through the factory so service logs honor the global
+// --log-level/--verbose (quiet by default) instead of leaking at Info level
+// into the TUI. (REF-129)
+func gatherFleet(ctx context.Context, accts []accounts.Account, regions []string, opts statussvc.ListOptions, maxConc int) ([]statussvc.ClusterStatus, []error) {
+	return statussvc.GatherFleet(ctx, accts, regions, opts, maxConc, factory.NewDefaultLogger(nil))
 }
 
 // exitForStatuses maps the fleet posture to the documented exit-code contract:
-// 3 when any cluster is on extended/unsupported EKS, 2 when something is stale,
-// 0 otherwise.
+// 5 when a nodegroup is past its SLA, 3 when any cluster is on
+// extended/unsupported EKS, 4 when a nodegroup is approaching its SLA, 2 when
+// something is stale, 0 otherwise.
 func exitForStatuses(statuses []statussvc.ClusterStatus) error {
-	supportRisk, stale := false, false
+	breached, supportRisk, approaching, stale := false, false, false, false
 	for _, c := range statuses {
+		if c.SLABreached() {
+			breached = true
+		}
 		if c.SupportRisk() {
 			supportRisk = true
 		}
+		if c.SLAApproaching() {
+			approaching = true
+		}
 		if c.NeedsAttention() {
 			stale = true
 		}
 	}
 	switch {
+	case breached:
+		return cli.Exit("", 5)
 	case supportRisk:
 		return cli.Exit("", 3)
+	case approaching:
+		return cli.Exit("", 4)
 	case stale:
 		return cli.Exit("", 2)
 	default:
@@ -156,6 +142,53 @@ func exitForStatuses(statuses []statussvc.ClusterStatus) error {
 	}
 }
 
+// slaResolver resolves each cluster's sla policy from refresh.yaml: the
+// tags.<key=glob> scopes its EKS tags match, its clusters.<glob> scopes, and
+// the contexts.<name> scope of a saved context pointing at it. Nil when there
+// is no refresh.yaml.
+func slaResolver() (statussvc.SLAResolver, error) {
+	layers, err := cliconfig.LoadLayers()
+	if err != nil || len(layers) == 0 {
+		return nil, err
+	}
+	// Without a readable context file, the contexts.<name> scopes just don't
+	// apply.
+	file, _ := cliconfig.Load()
+	return func(cluster, region string, tags map[string]string) *statussvc.SLAPolicy {
+		t := cliconfig.Target{Command: "status", Cluster: cluster, Context: contextFor(file, cluster, region), Tags: tags}
+		sla, sources := cliconfig.Resolve(layers, t).SLA()
+		if !sla.Enforced() {
+			return nil
+		}
+		return &statussvc.SLAPolicy{
+			MaxAMIAgeDays:     sla.MaxAMIAgeDays,
+			MaxReleasesBehind: sla.MaxReleasesBehind,
+			WarnDays:          sla.WarnDays,
+			Source:            strings.Join(sources, ", "),
+		}
+	}, nil
+}
+
+// contextFor names the saved context pointing at cluster in region,
+// preferring the active one; empty when none does.
+func contextFor(file *cliconfig.File, cluster, region string) string {
+	if file == nil {
+		return ""
+	}
+	matches := func(c cliconfig.Context) bool {
+		return c.Cluster == cluster && (c.Region == "" || c.Region == region)
+	}
+	if name, active, ok := file.Active(); ok && matches(active) {
+		return name
+	}
+	for _, name := range file.Names() {
+		if matches(file.Contexts[name]) {
+			return name
+		}
+	}
+	return ""
+}
+
 func sortStatuses(statuses []statussvc.ClusterStatus, key string, desc bool) {
 	less := lessFunc(strings.ToLower(strings.TrimSpace(key)))
 	sort.SliceStable(statuses, func(i, j int) bool {
@@ -174,6 +207,13 @@ func lessFunc(key string) func(a, b statussvc.ClusterStatus) bool {
 		return a.Region < b.Region
 	}
 	switch key {
+	case "account":
+		return func(a, b statussvc.ClusterStatus) bool {
+			if a.Account != b.Account {
+				return a.Account < b.Account
+			}
+			return byName(a, b)
+		}
 	case "region":
 		return func(a, b statussvc.ClusterStatus) bool {
 			if a.Region != b.Region {
@@ -1,10 +1,13 @@
 package statuscmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/cliconfig"
 	statussvc "github.com/dantech2000/refresh/internal/services/status"
 )
 
@@ -45,6 +48,31 @@ func TestExitForStatuses(t *testing.T) {
 			}},
 			want: 3,
 		},
+		{
+			name: "SLA approaching → 4 (beats stale)",
+			statuses: []statussvc.ClusterStatus{{
+				Support:  statussvc.SupportPosture{Tier: statussvc.SupportStandard},
+				StaleAMI: statussvc.StaleAMISummary{Behind: 1},
+				SLA:      &statussvc.SLAStatus{State: statussvc.SLAApproaching},
+			}},
+			want: 4,
+		},
+		{
+			name: "extended support → 3 (beats SLA approaching)",
+			statuses: []statussvc.ClusterStatus{
+				{Support: statussvc.SupportPosture{Tier: statussvc.SupportExtended}},
+				{SLA: &statussvc.SLAStatus{State: statussvc.SLAApproaching}},
+			},
+			want: 3,
+		},
+		{
+			name: "SLA breached → 5 (beats everything)",
+			statuses: []statussvc.ClusterStatus{
+				{Support: statussvc.SupportPosture{Tier: statussvc.SupportUnsupported}},
+				{SLA: &statussvc.SLAStatus{State: statussvc.SLABreached}},
+			},
+			want: 5,
+		},
 		{
 			name: "unsupported → 3",
 			statuses: []statussvc.ClusterStatus{{
@@ -81,3 +109,52 @@ func TestSortStatuses_ByClusterName(t *testing.T) {
 		t.Errorf("name order = %s..%s, want a..c", statuses[0].Name, statuses[2].Name)
 	}
 }
+
+func TestSLAResolver_TagsClustersAndContexts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", dir)
+	t.Setenv("REFRESH_CONTEXT", "")
+	settings := `
+sla: {maxAmiAgeDays: 60}
+tags:
+  env=prod:
+    sla: {maxAmiAgeDays: 30, maxReleasesBehind: 3}
+contexts:
+  payments:
+    sla: {maxAmiAgeDays: 14}
+`
+	if err := os.WriteFile(filepath.Join(dir, cliconfig.SettingsFileName), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cliconfig.Save(&cliconfig.File{Contexts: map[string]cliconfig.Context{
+		"payments": {Cluster: "pay-prod", Region: "us-east-1"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	resolve, err := slaResolver()
+	if err != nil || resolve == nil {
+		t.Fatalf("slaResolver() = %v, %v", resolve, err)
+	}
+	prod := map[string]string{"env": "prod"}
+	cases := []struct {
+		cluster, region string
+		tags            map[string]string
+		wantAge         int
+	}{
+		{"dev", "us-east-1", nil, 60},
+		{"web-prod", "us-east-1", prod, 30},
+		{"pay-prod", "us-east-1", prod, 14},
+		// The context points at us-east-1; the same name elsewhere isn't it.
+		{"pay-prod", "eu-west-1", prod, 30},
+	}
+	for _, c := range cases {
+		p := resolve(c.cluster, c.region, c.tags)
+		if p == nil || p.MaxAMIAgeDays != c.wantAge {
+			t.Errorf("%s/%s: policy = %+v, want maxAmiAgeDays %d", c.cluster, c.region, p, c.wantAge)
+		}
+	}
+	if p := resolve("web-prod", "us-east-1", prod); p.MaxReleasesBehind != 3 || p.Source == "" {
+		t.Errorf("tag policy = %+v, want 3 releases and a source", p)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/runner"
 )
 
 // Command returns the `refresh status` top-level command.
@@ -18,11 +20,36 @@ func Command() *cli.Command {
 EKS support window (with extended-support cost callout), nodegroup AMI
 staleness, and addons behind latest.
 
-Exit codes (for CI/cron):
-  0  everything current and in standard support
+SECURITY counts the amazon-eks-ami releases after the oldest stale nodegroup's
+release that fix CVEs, with critical/high counts when --cve-severity maps the
+CVE IDs to severities. --no-security skips the release-notes lookup.
+
+SLA appears when refresh.yaml sets an sla policy (maxAmiAgeDays,
+maxReleasesBehind, warnDays), at the top level or scoped by clusters.<glob>,
+tags.<key=glob> (matched against the cluster's EKS tags) or contexts.<name>
+(for the saved context pointing at the cluster). Each stale nodegroup is
+measured against it, and a NODEGROUP SLA table lists when each one breaches.
+
+--selector (-l) keeps only clusters whose EKS tags match a kubectl-style
+selector (env=prod,team!=data, tier in (web,api), owner, !legacy); --group
+uses one saved with 'refresh context group add'. Both combine with the
+name pattern.
+
+  refresh status -A -l env=prod,team!=data
+  refresh status -A --group prod-web
+
+Multi-account: --account-role (repeatable role ARNs) or --org-role (a role
+name assumed in every ACTIVE AWS Organizations account) nests the region
+fan-out under each account and adds an ACCOUNT column. Accounts whose role
+can't be assumed are reported like unreachable regions.
+
+Exit codes (for CI/cron), the first that applies:
+  5  a nodegroup is past its SLA
+  3  a cluster is on extended support or unsupported
+  4  a nodegroup is within warnDays of breaching its SLA
   2  something stale (nodegroup AMI or addon behind latest)
-  3  a cluster is on extended support or unsupported`,
-		Flags: []cli.Flag{
+  0  everything current and in standard support`,
+		Flags: append([]cli.Flag{
 			// --timeout and --max-concurrency come from the global/persistent
 			// flags (see main.go); status reads them via cmd.Duration/cmd.Int and
 			// does not re-declare them, so help lists each once. (REF-134)
@@ -33,9 +60,11 @@ Exit codes (for CI/cron):
 			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Query all EKS-supported regions"},
 			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "Specific region(s) to query (repeatable)"},
 			&cli.StringFlag{Name: "format", Aliases: []string{"o"}, Usage: "Output format (table, json, yaml, plain)", Value: "table"},
-			&cli.StringFlag{Name: "sort", Usage: "Sort by field: cluster,region,version,support,stale", Value: "cluster"},
+			&cli.StringFlag{Name: "sort", Usage: "Sort by field: cluster,account,region,version,support,stale", Value: "cluster"},
 			&cli.BoolFlag{Name: "desc", Usage: "Sort descending"},
-		},
+			&cli.StringFlag{Name: "cve-severity", Usage: "YAML/JSON file mapping CVE IDs to critical, high, medium or low, used to grade the SECURITY column", Sources: cli.EnvVars("REFRESH_CVE_SEVERITY")},
+			&cli.BoolFlag{Name: "no-security", Usage: "Skip the amazon-eks-ami release-notes lookup behind the SECURITY column"},
+		}, append(runner.SelectorFlags(), runner.AccountFlags()...)...),
 		Action: func(ctx context.Context, cmd *cli.Command) error { return runStatus(ctx, cmd) },
 	}
 }
@@ -32,6 +32,17 @@ func distinctRegions(statuses []statussvc.ClusterStatus) int {
 	return len(seen)
 }
 
+// multiAccount reports whether any row carries an account label, i.e. the
+// sweep spanned an account inventory and the ACCOUNT column is worth its width.
+func multiAccount(statuses []statussvc.ClusterStatus) bool {
+	for _, c := range statuses {
+		if c.Account != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func nameOr(c statussvc.ClusterStatus) string {
 	if c.Name == "" {
 		return "unknown"
@@ -48,13 +59,30 @@ func fleetLines(th *render.Theme, statuses []statussvc.ClusterStatus, elapsed ti
 			th.Paint(pal.White, fmt.Sprintf("%d clusters", len(statuses))) +
 			th.Paint(pal.Dim, fmt.Sprintf(" · %d region(s)", distinctRegions(statuses))),
 		"",
-		chipsLine(th, statuses),
+		ChipsLine(th, statuses),
 		"",
 	}
+	out = append(out, FleetTable(th, statuses)...)
+	if sla := slaLines(th, statuses); len(sla) > 0 {
+		out = append(append(out, ""), sla...)
+	}
+	out = append(out, "", footerPretty(th, statuses, elapsed))
+	if h := hintLine(th, statuses); h != "" {
+		out = append(out, "", h)
+	}
+	return out
+}
 
-	tbl := th.NewTable(
-		ui.Column{Title: "", Min: 1},
-		ui.Column{Title: "CLUSTER", Min: 8},
+// FleetTable renders the fleet table: a header line, then one line per
+// cluster in the order given. `refresh ui` selects rows by that order.
+func FleetTable(th *render.Theme, statuses []statussvc.ClusterStatus) []string {
+	pal := th.Pal
+	withAccount, withSecurity, withSLA := multiAccount(statuses), securityKnown(statuses), slaKnown(statuses)
+	cols := []ui.Column{{Title: "", Min: 1}, {Title: "CLUSTER", Min: 8}}
+	if withAccount {
+		cols = append(cols, ui.Column{Title: "ACCOUNT", Min: 7, Max: 24})
+	}
+	cols = append(cols,
 		ui.Column{Title: "REGION", Min: 6},
 		ui.Column{Title: "VERSION", Min: 7},
 		ui.Column{Title: "SUPPORT", Min: 10, Max: 34},
@@ -62,14 +90,23 @@ func fleetLines(th *render.Theme, statuses []statussvc.ClusterStatus, elapsed ti
 		ui.Column{Title: "STALE AMI", Min: 6},
 		ui.Column{Title: "ADDONS", Min: 6, Max: 26},
 	)
+	if withSecurity {
+		cols = append(cols, ui.Column{Title: "SECURITY", Min: 8})
+	}
+	if withSLA {
+		cols = append(cols, ui.Column{Title: "SLA", Min: 12})
+	}
+	tbl := th.NewTable(cols...)
 	for _, c := range statuses {
 		version := c.Version
 		if version == "" {
 			version = "unknown"
 		}
-		tbl.Row(
-			th.Glyph(overall(c)),
-			th.Paint(pal.White, nameOr(c)),
+		cells := []string{th.Glyph(overall(c)), th.Paint(pal.White, nameOr(c))}
+		if withAccount {
+			cells = append(cells, th.Paint(pal.Teal, c.Account))
+		}
+		cells = append(cells,
 			th.Paint(pal.Dim, c.Region),
 			th.Paint(pal.White, version),
 			supportPretty(th, c.Support),
@@ -77,16 +114,20 @@ func fleetLines(th *render.Theme, statuses []statussvc.ClusterStatus, elapsed ti
 			stalePretty(th, c),
 			addonsPretty(th, c.AddonsBehind),
 		)
+		if withSecurity {
+			cells = append(cells, securityPretty(th, c))
+		}
+		if withSLA {
+			cells = append(cells, slaPretty(th, clusterSLAState(c), slaText(c)))
+		}
+		tbl.Row(cells...)
 	}
-	out = append(out, tbl.Render()...)
-	out = append(out, "", footerPretty(th, statuses, elapsed))
-	if h := hintLine(th, statuses); h != "" {
-		out = append(out, "", h)
-	}
-	return out
+	return tbl.Render()
 }
 
-func chipsLine(th *render.Theme, statuses []statussvc.ClusterStatus) string {
+// ChipsLine summarizes the fleet as current / need-attention / unsupported
+// token counts.
+func ChipsLine(th *render.Theme, statuses []statussvc.ClusterStatus) string {
 	var healthy, warn, fail int
 	for _, c := range statuses {
 		switch overall(c) {
@@ -170,6 +211,19 @@ func addonsPretty(th *render.Theme, a statussvc.AddonsBehindSummary) string {
 	return th.Token(render.Warn, fmt.Sprintf("%d (%s%s)", a.Behind, strings.Join(names, ","), suffix))
 }
 
+func securityPretty(th *render.Theme, c statussvc.ClusterStatus) string {
+	txt, ok := securityText(c)
+	switch {
+	case !ok:
+		return th.Paint(th.Pal.Dim, txt)
+	case c.Security.Critical > 0:
+		return th.Token(render.Fail, txt)
+	case c.Security.ReleasesBehind > 0:
+		return th.Token(render.Warn, txt)
+	}
+	return th.Paint(th.Pal.Green, txt)
+}
+
 func footerPretty(th *render.Theme, statuses []statussvc.ClusterStatus, elapsed time.Duration) string {
 	staleNG, addonsBehind, supportRisk := 0, 0, 0
 	for _, c := range statuses {
@@ -218,6 +272,8 @@ func hintLine(th *render.Theme, statuses []statussvc.ClusterStatus) string {
 	switch {
 	case st == render.Fail:
 		reason = "is on unsupported EKS"
+	case worst.SLABreached():
+		reason = "is past its AMI SLA"
 	case worst.HealthIssues > 0:
 		reason = fmt.Sprintf("has %d control-plane health issue(s)", worst.HealthIssues)
 	case worst.NeedsAttention():
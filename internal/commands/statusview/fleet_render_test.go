@@ -118,3 +118,97 @@ func mustContain(t *testing.T, haystack, needle string) {
 		t.Errorf("output missing %q in:\n%s", needle, haystack)
 	}
 }
+
+func TestFleetLines_AccountColumnOnlyWhenLabeled(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	if joined := strings.Join(fleetLines(th, sampleFleet(), 0), "\n"); strings.Contains(joined, "ACCOUNT") {
+		t.Fatalf("single-account fleet should not show ACCOUNT:\n%s", joined)
+	}
+
+	fleet := sampleFleet()
+	fleet[0].Account = "payments-prod"
+	joined := strings.Join(fleetLines(th, fleet, 0), "\n")
+	mustContain(t, joined, "ACCOUNT")
+	mustContain(t, joined, "payments-prod")
+}
+
+func TestFleetLines_SecurityColumnOnlyWhenKnown(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	if joined := strings.Join(fleetLines(th, sampleFleet(), 0), "\n"); strings.Contains(joined, "SECURITY") {
+		t.Fatalf("fleet without security summaries should not show SECURITY:\n%s", joined)
+	}
+
+	fleet := sampleFleet()
+	fleet[0].Security = &statussvc.SecuritySummary{}
+	fleet[2].Security = &statussvc.SecuritySummary{ReleasesBehind: 3, CVEs: 5, Critical: 1, High: 2}
+	joined := strings.Join(fleetLines(th, fleet, 0), "\n")
+	mustContain(t, joined, "SECURITY")
+	mustContain(t, joined, "3 (1C/2H)")
+}
+
+func TestSecurityText(t *testing.T) {
+	managed := statussvc.ClusterStatus{Compute: statussvc.ComputeManaged}
+	cases := []struct {
+		sec  *statussvc.SecuritySummary
+		want string
+	}{
+		{nil, "-"},
+		{&statussvc.SecuritySummary{}, "0"},
+		{&statussvc.SecuritySummary{ReleasesBehind: 2, CVEs: 4}, "2 (4 CVEs)"},
+		{&statussvc.SecuritySummary{ReleasesBehind: 2, CVEs: 4, High: 1}, "2 (0C/1H)"},
+	}
+	for _, tc := range cases {
+		c := managed
+		c.Security = tc.sec
+		if got, _ := securityText(c); got != tc.want {
+			t.Errorf("securityText(%+v) = %q, want %q", tc.sec, got, tc.want)
+		}
+	}
+	if got, _ := securityText(statussvc.ClusterStatus{Compute: statussvc.ComputeAutoMode}); got != "n/a" {
+		t.Errorf("Auto Mode securityText = %q, want n/a", got)
+	}
+}
+
+func TestFleetLines_SLAColumnAndNodegroupTable(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	if joined := strings.Join(fleetLines(th, sampleFleet(), 0), "\n"); strings.Contains(joined, "SLA") {
+		t.Fatalf("fleet without a policy should not show SLA:\n%s", joined)
+	}
+
+	fleet := sampleFleet()
+	fleet[2].SLA = &statussvc.SLAStatus{
+		State:        statussvc.SLABreached,
+		BreachInDays: iptr(-17),
+		Nodegroups: []statussvc.NodegroupSLA{
+			{Name: "ng-old", State: statussvc.SLABreached, AMIAgeDays: iptr(47), BreachInDays: iptr(-17), Reason: "AMI 47d old (max 30)"},
+			{Name: "ng-new", State: statussvc.SLAOK},
+		},
+	}
+	fleet[1].SLA = &statussvc.SLAStatus{State: statussvc.SLAOK, Nodegroups: []statussvc.NodegroupSLA{}}
+	joined := strings.Join(fleetLines(th, fleet, 0), "\n")
+	mustContain(t, joined, "NODEGROUP SLA")
+	mustContain(t, joined, "breached 17d ago")
+	mustContain(t, joined, "AMI 47d old (max 30)")
+	if strings.Contains(joined, "ng-new") {
+		t.Errorf("nodegroups on the latest AMI should be left out of the SLA table:\n%s", joined)
+	}
+}
+
+func TestBreachText(t *testing.T) {
+	cases := []struct {
+		state statussvc.SLAState
+		in    *int
+		want  string
+	}{
+		{statussvc.SLAApproaching, iptr(3), "breach in 3d"},
+		{statussvc.SLABreached, iptr(-2), "breached 2d ago"},
+		{statussvc.SLABreached, iptr(10), "breached"},
+		{statussvc.SLAApproaching, nil, "approaching"},
+		{statussvc.SLAUnknown, nil, "unknown"},
+	}
+	for _, c := range cases {
+		if got := breachText(c.state, c.in); got != c.want {
+			t.Errorf("breachText(%s, %v) = %q, want %q", c.state, c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package statusview
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/dantech2000/refresh/internal/render"
+	statussvc "github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// slaKnown reports whether any row was evaluated against an sla policy, so
+// the SLA column and the per-nodegroup table are left out otherwise.
+func slaKnown(statuses []statussvc.ClusterStatus) bool {
+	for _, c := range statuses {
+		if c.SLA != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// breachText renders a countdown to an AMI-age breach: "breach in 3d", or
+// "breached 7d ago" once it has passed. Without a countdown it's the state.
+func breachText(state statussvc.SLAState, in *int) string {
+	switch {
+	case in == nil:
+		return string(state)
+	case *in < 0:
+		return fmt.Sprintf("breached %dd ago", -*in)
+	case state == statussvc.SLABreached:
+		// Breached on releases behind while the age is still inside its limit.
+		return "breached"
+	default:
+		return fmt.Sprintf("breach in %dd", *in)
+	}
+}
+
+// slaText renders a cluster's worst nodegroup: "-" without a policy.
+func slaText(c statussvc.ClusterStatus) string {
+	if c.SLA == nil {
+		return "-"
+	}
+	return breachText(c.SLA.State, c.SLA.BreachInDays)
+}
+
+func slaStatus(state statussvc.SLAState) render.Status {
+	switch state {
+	case statussvc.SLABreached:
+		return render.Fail
+	case statussvc.SLAApproaching:
+		return render.Warn
+	default:
+		return render.Healthy
+	}
+}
+
+func slaCell(state statussvc.SLAState, txt string) string {
+	switch state {
+	case statussvc.SLABreached:
+		return color.RedString(txt)
+	case statussvc.SLAApproaching:
+		return color.YellowString(txt)
+	}
+	return txt
+}
+
+func slaPretty(th *render.Theme, state statussvc.SLAState, txt string) string {
+	switch state {
+	case statussvc.SLAOK:
+		return th.Paint(th.Pal.Green, txt)
+	case statussvc.SLAUnknown, "":
+		return th.Paint(th.Pal.Dim, txt)
+	}
+	return th.Token(slaStatus(state), txt)
+}
+
+func clusterSLAState(c statussvc.ClusterStatus) statussvc.SLAState {
+	if c.SLA == nil {
+		return ""
+	}
+	return c.SLA.State
+}
+
+// slaRow is one stale nodegroup in the per-nodegroup SLA table.
+type slaRow struct {
+	cluster string
+	ng      statussvc.NodegroupSLA
+}
+
+// slaRows lists the nodegroups the policy measured; nodegroups on the latest
+// AMI are compliant and left out.
+func slaRows(statuses []statussvc.ClusterStatus) []slaRow {
+	var rows []slaRow
+	for _, c := range statuses {
+		if c.SLA == nil {
+			continue
+		}
+		for _, ng := range c.SLA.Nodegroups {
+			if ng.AMIAgeDays == nil && ng.ReleasesBehind == nil && ng.State == statussvc.SLAOK {
+				continue
+			}
+			rows = append(rows, slaRow{cluster: nameOr(c), ng: ng})
+		}
+	}
+	return rows
+}
+
+func daysCell(d *int) string {
+	if d == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%dd", *d)
+}
+
+func countCell(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *n)
+}
+
+func slaColumns() []ui.Column {
+	return []ui.Column{
+		{Title: "CLUSTER", Min: 8},
+		{Title: "NODEGROUP", Min: 9},
+		{Title: "AMI AGE", Min: 7},
+		{Title: "RELEASES BEHIND", Min: 15},
+		{Title: "SLA", Min: 12},
+		{Title: "REASON", Min: 6, Max: 60},
+	}
+}
+
+// outputSLAPlain prints the per-nodegroup SLA table for `-o plain`.
+func outputSLAPlain(statuses []statussvc.ClusterStatus) {
+	rows := slaRows(statuses)
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Println()
+	table := ui.NewPTable(slaColumns(), ui.CyanHeaders())
+	for _, r := range rows {
+		table.AddRow(r.cluster, r.ng.Name, daysCell(r.ng.AMIAgeDays), countCell(r.ng.ReleasesBehind),
+			slaCell(r.ng.State, breachText(r.ng.State, r.ng.BreachInDays)), r.ng.Reason)
+	}
+	table.Render()
+}
+
+// slaLines renders the per-nodegroup SLA section of the dashboard.
+func slaLines(th *render.Theme, statuses []statussvc.ClusterStatus) []string {
+	rows := slaRows(statuses)
+	if len(rows) == 0 {
+		return nil
+	}
+	pal := th.Pal
+	tbl := th.NewTable(slaColumns()...)
+	for _, r := range rows {
+		tbl.Row(
+			th.Paint(pal.White, r.cluster),
+			th.Paint(pal.White, r.ng.Name),
+			th.Paint(pal.Dim, daysCell(r.ng.AMIAgeDays)),
+			th.Paint(pal.Dim, countCell(r.ng.ReleasesBehind)),
+			slaPretty(th, r.ng.State, breachText(r.ng.State, r.ng.BreachInDays)),
+			th.Paint(pal.Dim, r.ng.Reason),
+		)
+	}
+	return append([]string{th.Section("NODEGROUP SLA")}, tbl.Render()...)
+}
@@ -33,19 +33,32 @@ func OutputFleetTable(statuses []statussvc.ClusterStatus, elapsed time.Duration)
 // outputFleetPlain renders the uncolored, tab-separated fleet table for
 // `-o plain` (grep/awk-friendly), via the PTable plain path.
 func outputFleetPlain(statuses []statussvc.ClusterStatus, elapsed time.Duration) error {
-	columns := []ui.Column{
-		{Title: "CLUSTER", Min: 8},
+	withAccount, withSecurity, withSLA := multiAccount(statuses), securityKnown(statuses), slaKnown(statuses)
+	columns := []ui.Column{{Title: "CLUSTER", Min: 8}}
+	if withAccount {
+		columns = append(columns, ui.Column{Title: "ACCOUNT", Min: 7, Max: 24})
+	}
+	columns = append(columns, []ui.Column{
 		{Title: "REGION", Min: 9},
 		{Title: "VERSION", Min: 7},
 		{Title: "SUPPORT", Min: 24, Max: 40},
 		{Title: "COMPUTE", Min: 10, Max: 28},
 		{Title: "STALE AMI", Min: 9},
 		{Title: "ADDONS BEHIND", Min: 13, Max: 30},
+	}...)
+	if withSecurity {
+		columns = append(columns, ui.Column{Title: "SECURITY", Min: 8})
+	}
+	if withSLA {
+		columns = append(columns, ui.Column{Title: "SLA", Min: 12})
 	}
 	table := ui.NewPTable(columns, ui.CyanHeaders())
 	for _, c := range statuses {
-		table.AddRow(
-			c.Name,
+		row := []string{c.Name}
+		if withAccount {
+			row = append(row, c.Account)
+		}
+		row = append(row,
 			c.Region,
 			versionCell(c),
 			supportCell(c.Support),
@@ -53,8 +66,16 @@ func outputFleetPlain(statuses []statussvc.ClusterStatus, elapsed time.Duration)
 			staleAMICell(c),
 			addonsCell(c.AddonsBehind),
 		)
+		if withSecurity {
+			row = append(row, securityCell(c))
+		}
+		if withSLA {
+			row = append(row, slaCell(clusterSLAState(c), slaText(c)))
+		}
+		table.AddRow(row...)
 	}
 	table.Render()
+	outputSLAPlain(statuses)
 
 	fmt.Println()
 	fmt.Println(summaryFooter(statuses, elapsed))
@@ -128,6 +149,51 @@ func staleAMICell(c statussvc.ClusterStatus) string {
 	return color.YellowString(txt)
 }
 
+func securityCell(c statussvc.ClusterStatus) string {
+	txt, ok := securityText(c)
+	switch {
+	case !ok:
+		return txt
+	case c.Security.Critical > 0:
+		return color.RedString(txt)
+	case c.Security.ReleasesBehind > 0:
+		return color.YellowString(txt)
+	}
+	return txt
+}
+
+// securityText renders the security-relevant releases behind, with the
+// critical/high split when graded: "3 (1C/2H)", or "3 (5 CVEs)" ungraded. ok is
+// false for "n/a" and "-" (not looked up or unavailable).
+func securityText(c statussvc.ClusterStatus) (string, bool) {
+	switch {
+	case c.Compute != statussvc.ComputeManaged:
+		return "n/a", false
+	case c.Security == nil:
+		return "-", false
+	}
+	sec := c.Security
+	txt := fmt.Sprintf("%d", sec.ReleasesBehind)
+	switch {
+	case sec.Critical+sec.High > 0:
+		txt += fmt.Sprintf(" (%dC/%dH)", sec.Critical, sec.High)
+	case sec.CVEs > 0:
+		txt += fmt.Sprintf(" (%d CVEs)", sec.CVEs)
+	}
+	return txt, true
+}
+
+// securityKnown reports whether any row carries a security summary, so the
+// SECURITY column is left out when the release notes weren't looked up.
+func securityKnown(statuses []statussvc.ClusterStatus) bool {
+	for _, c := range statuses {
+		if c.Security != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func addonsCell(a statussvc.AddonsBehindSummary) string {
 	if a.Behind == 0 {
 		return "0"
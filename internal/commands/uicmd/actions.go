@@ -0,0 +1,220 @@
+package uicmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/commands/factory"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+	addonsvc "github.com/dantech2000/refresh/internal/services/addons"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	nodegroupsvc "github.com/dantech2000/refresh/internal/services/nodegroup"
+	statussvc "github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/tui"
+)
+
+// assumedProfile is the profile a dry-run or health check runs under when its
+// cluster's account was reached through an assumed role.
+const assumedProfile = "refresh-ui"
+
+func runUI(ctx context.Context, cmd *cli.Command) error {
+	// Fail before touching AWS: there is nothing to draw on.
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return tui.ErrNotTerminal
+	}
+	// --timeout bounds each fetch, not the session.
+	setupCtx, cancel, awsCfg, err := runner.SetupAWS(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	accts, acctErrs, err := runner.ResolveAccounts(setupCtx, cmd, awsCfg)
+	cancel()
+	if err != nil {
+		return err
+	}
+	regions := runner.FleetRegions(cmd, awsCfg)
+
+	src := &awsSource{
+		accts:      map[string]accounts.Account{},
+		all:        accts,
+		acctErrs:   acctErrs,
+		regions:    regions,
+		opts:       statussvc.ListOptions{NamePattern: strings.TrimSpace(cmd.Args().First()), MaxConcurrency: cmd.Int("max-concurrency")},
+		timeout:    cmd.Duration("timeout"),
+		kubeconfig: cmd.String("kubeconfig"),
+		profile:    cmd.String("profile"),
+		logger:     factory.NewDefaultLogger(nil),
+	}
+	for _, a := range accts {
+		src.accts[a.Label()] = a
+	}
+	app := tui.New(src, render.Default(os.Stdout), scopeLabel(accts, regions))
+	return tui.Run(ctx, app, os.Stdin, os.Stdout, src.exec)
+}
+
+// scopeLabel describes what the fleet screen covers.
+func scopeLabel(accts []accounts.Account, regions []string) string {
+	scope := strings.Join(regions, ", ")
+	if len(regions) > 3 {
+		scope = fmt.Sprintf("%d regions", len(regions))
+	}
+	if len(accts) > 1 {
+		scope = fmt.Sprintf("%d accounts · %s", len(accts), scope)
+	}
+	return scope
+}
+
+// awsSource reads the UI's data from AWS and the kubeconfig.
+type awsSource struct {
+	accts      map[string]accounts.Account // by Label, as on ClusterStatus.Account
+	all        []accounts.Account
+	acctErrs   []error
+	regions    []string
+	opts       statussvc.ListOptions
+	timeout    time.Duration
+	kubeconfig string
+	profile    string
+	logger     *slog.Logger
+}
+
+// bound applies --timeout to one fetch.
+func (s *awsSource) bound(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// config is the AWS config for c's account and region.
+func (s *awsSource) config(c tui.Cluster) aws.Config {
+	cfg := s.accts[c.Account].Config.Copy()
+	cfg.Region = c.Region
+	return cfg
+}
+
+func (s *awsSource) Fleet(ctx context.Context) ([]statussvc.ClusterStatus, []error) {
+	ctx, cancel := s.bound(ctx)
+	defer cancel()
+	statuses, errs := statussvc.GatherFleet(ctx, s.all, s.regions, s.opts, s.opts.MaxConcurrency, s.logger)
+	// Accounts that couldn't be assumed degrade like unreachable regions.
+	return statuses, append(append([]error(nil), s.acctErrs...), errs...)
+}
+
+func (s *awsSource) Nodegroups(ctx context.Context, c tui.Cluster) ([]nodegroupsvc.NodegroupSummary, error) {
+	ctx, cancel := s.bound(ctx)
+	defer cancel()
+	return factory.NewNodegroupService(s.config(c), false, s.logger).List(ctx, c.Name, nodegroupsvc.ListOptions{})
+}
+
+func (s *awsSource) Addons(ctx context.Context, c tui.Cluster) ([]addonsvc.AddonSummary, error) {
+	ctx, cancel := s.bound(ctx)
+	defer cancel()
+	return factory.NewAddonService(s.config(c), s.logger).List(ctx, c.Name, addonsvc.ListOptions{})
+}
+
+func (s *awsSource) Insights(ctx context.Context, c tui.Cluster) ([]clustersvc.InsightSummary, error) {
+	ctx, cancel := s.bound(ctx)
+	defer cancel()
+	return factory.NewClusterService(s.config(c), false, s.logger).ListInsights(ctx, c.Name, clustersvc.UpgradeCheckOptions{ShowPassing: true})
+}
+
+// RollObserver attaches to a roll already in flight: the observer has no
+// roll-start baseline, so the target AMI is inferred from the newest node.
+func (s *awsSource) RollObserver(ctx context.Context, _ tui.Cluster, nodegroup string) (noderoll.Observer, string, error) {
+	client, diag, err := health.BuildKubeClient(s.kubeconfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("building kubernetes client (%s): %w", diag, err)
+	}
+	ctx, cancel := s.bound(ctx)
+	defer cancel()
+	if err := health.ProbeConnection(ctx, client); err != nil {
+		return nil, "", fmt.Errorf("cluster API unreachable via %s: %w", diag, err)
+	}
+	obs := noderoll.NewKubeObserver(client, nodegroup, "")
+	if err := obs.InferTargetAMI(ctx); err != nil {
+		return nil, "", fmt.Errorf("listing nodes of %s: %w", nodegroup, err)
+	}
+	return obs, diag.String(), nil
+}
+
+// exec runs an action as a child refresh process in the normal terminal.
+// The child gets no stdin: the UI's key reader still owns it.
+func (s *awsSource) exec(ctx context.Context, act tui.Action) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the refresh binary: %w", err)
+	}
+	args, env, cleanup, err := s.invocation(ctx, act)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	child := exec.CommandContext(ctx, self, args...)
+	child.Stdout, child.Stderr, child.Env = os.Stdout, os.Stderr, env
+	return child.Run()
+}
+
+// invocation builds the child's arguments and environment: the cluster's
+// region, and either the --profile in effect or, for an assumed-role
+// account, its current credentials in a throwaway shared-config profile (a
+// profile, not AWS_* variables, so an active `refresh use` context can't
+// shadow them). cleanup removes that profile.
+func (s *awsSource) invocation(ctx context.Context, act tui.Action) (args, env []string, cleanup func(), err error) {
+	args = append(act.Args(), "--region", act.Cluster.Region)
+	if color.NoColor {
+		args = append(args, "--no-color")
+	}
+	env, cleanup = os.Environ(), func() {}
+
+	acct := s.accts[act.Cluster.Account]
+	if acct.RoleARN == "" {
+		if s.profile != "" {
+			args = append(args, "--profile", s.profile)
+		}
+		return args, env, cleanup, nil
+	}
+	creds, err := acct.Config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("credentials for account %s: %w", acct.Label(), err)
+	}
+	dir, err := os.MkdirTemp("", "refresh-ui-")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating credentials profile: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(assumedProfileConfig(creds)), 0o600); err != nil {
+		cleanup()
+		return nil, nil, nil, fmt.Errorf("writing credentials profile: %w", err)
+	}
+	env = append(env, "AWS_CONFIG_FILE="+path, "AWS_SHARED_CREDENTIALS_FILE="+filepath.Join(dir, "credentials"))
+	return append(args, "--profile", assumedProfile), env, cleanup, nil
+}
+
+// assumedProfileConfig renders a shared-config file holding creds under
+// assumedProfile.
+func assumedProfileConfig(creds aws.Credentials) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[profile %s]\n", assumedProfile)
+	fmt.Fprintf(&b, "aws_access_key_id = %s\n", creds.AccessKeyID)
+	fmt.Fprintf(&b, "aws_secret_access_key = %s\n", creds.SecretAccessKey)
+	if creds.SessionToken != "" {
+		fmt.Fprintf(&b, "aws_session_token = %s\n", creds.SessionToken)
+	}
+	return b.String()
+}
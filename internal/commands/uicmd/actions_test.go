@@ -0,0 +1,67 @@
+package uicmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/fatih/color"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	"github.com/dantech2000/refresh/internal/tui"
+)
+
+func TestInvocation(t *testing.T) {
+	defer func(v bool) { color.NoColor = v }(color.NoColor)
+	color.NoColor = false
+
+	assumed := accounts.Account{ID: "111122223333", Name: "payments", RoleARN: "arn:aws:iam::111122223333:role/refresh"}
+	assumed.Config.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKIAEXAMPLE", "secret", "token"))
+	src := &awsSource{
+		accts:   map[string]accounts.Account{"": {}, "payments": assumed},
+		profile: "ops",
+	}
+	ctx := context.Background()
+
+	act := tui.Action{Kind: tui.ActionDryRun, Cluster: tui.Cluster{Name: "prod", Region: "us-east-1"}, Nodegroup: "workers"}
+	args, _, cleanup, err := src.invocation(ctx, act)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+	if got := strings.Join(args, " "); got != "nodegroup update prod workers --dry-run --region us-east-1 --profile ops" {
+		t.Errorf("args = %s", got)
+	}
+
+	// An assumed-role account runs under a throwaway profile holding its
+	// credentials, not the base --profile.
+	act = tui.Action{Kind: tui.ActionHealth, Cluster: tui.Cluster{Name: "pay", Region: "eu-west-1", Account: "payments"}}
+	args, env, cleanup, err := src.invocation(ctx, act)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(args, " "); got != "nodegroup update pay --health-only --region eu-west-1 --profile refresh-ui" {
+		t.Errorf("args = %s", got)
+	}
+	var path string
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "AWS_CONFIG_FILE="); ok {
+			path = v
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading the profile: %v", err)
+	}
+	want := "[profile refresh-ui]\naws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = secret\naws_session_token = token\n"
+	if string(b) != want {
+		t.Errorf("profile =\n%s", b)
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("cleanup should remove the profile")
+	}
+}
@@ -0,0 +1,45 @@
+// Package uicmd wires `refresh ui`: the keyboard-driven full-screen fleet
+// browser (see internal/tui) backed by the same AWS services as `status`,
+// `nodegroup list`, `addon list` and `cluster upgrade-check`.
+package uicmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/dantech2000/refresh/internal/commands/runner"
+)
+
+// Command returns the `refresh ui` top-level command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "ui",
+		Usage:     "Browse the fleet in a keyboard-driven full-screen terminal UI",
+		ArgsUsage: "[name-pattern]",
+		Description: `Start at the 'refresh status' fleet table and drill into a cluster's
+nodegroups, addons and upgrade insights. From a selected row, start a dry-run
+or health check ('nodegroup update --dry-run' / '--health-only', run in the
+normal terminal, then back to the UI), or attach the live roll panel to a
+nodegroup that is rolling.
+
+Keys:
+  ↑/↓ j/k    move            enter   open / attach
+  ←/→ tab    switch tab      esc     back
+  d          dry-run         h       health check
+  a          attach to roll  r       reload
+  q          quit
+
+Attaching reads nodes through the current kubeconfig context, like the live
+panel of 'nodegroup update': switch it to the cluster first. Colors follow
+--no-color and NO_COLOR.`,
+		Flags: append([]cli.Flag{
+			// --region shadows the global override with a repeatable slice, like
+			// `refresh status`. (REF-47)
+			&cli.BoolFlag{Name: "all-regions", Aliases: []string{"A"}, Usage: "Query all EKS-supported regions"},
+			&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Usage: "Specific region(s) to query (repeatable)"},
+			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig used to attach to a roll (defaults to $KUBECONFIG, then ~/.kube/config)"},
+		}, runner.AccountFlags()...),
+		Action: func(ctx context.Context, cmd *cli.Command) error { return runUI(ctx, cmd) },
+	}
+}
@@ -0,0 +1,276 @@
+// Package customami rolls custom-AMI managed nodegroups (AmiType=CUSTOM).
+// EKS can't pick an AMI for them: it lives in the nodegroup's launch
+// template. A Roller resolves the target AMI from a configured Source,
+// publishes a launch template version that changes only the ImageId, and
+// starts UpdateNodegroupVersion onto that version, so the roll itself is the
+// same managed rolling update as any other nodegroup's.
+package customami
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// versionPlaceholder in an SSM parameter or name pattern is replaced with the
+// Kubernetes version the AMI is for, e.g. "/platform/ami/{version}/hardened".
+const versionPlaceholder = "{version}"
+
+// Source says where the target AMI of a custom-AMI nodegroup comes from.
+// Exactly one of SSMParameter, NamePattern and ImageID is set.
+type Source struct {
+	// SSMParameter names a parameter whose value is an AMI ID.
+	SSMParameter string
+	// NamePattern is an EC2 image name filter (* and ? wildcards); the newest
+	// available image owned by Owners wins.
+	NamePattern string
+	Owners      []string
+	// ImageID is an explicit AMI ID.
+	ImageID string
+}
+
+// ParseSource parses a --custom-ami value: "ssm:<parameter>",
+// "name:<pattern>" or an "ami-" ID. owners (repeated or comma-separated)
+// applies to name patterns and defaults to "self".
+func ParseSource(spec string, owners []string) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	owners = splitOwners(owners)
+	switch {
+	case strings.HasPrefix(spec, "ssm:") && len(spec) > len("ssm:"):
+		return Source{SSMParameter: strings.TrimPrefix(spec, "ssm:")}, nil
+	case strings.HasPrefix(spec, "name:") && len(spec) > len("name:"):
+		if len(owners) == 0 {
+			owners = []string{"self"}
+		}
+		return Source{NamePattern: strings.TrimPrefix(spec, "name:"), Owners: owners}, nil
+	case strings.HasPrefix(spec, "ami-"):
+		return Source{ImageID: spec}, nil
+	}
+	return Source{}, fmt.Errorf("custom AMI source %q: use ssm:<parameter>, name:<pattern> or an ami- ID", spec)
+}
+
+func splitOwners(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, o := range strings.Split(v, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+// String renders the source the way ParseSource reads it.
+func (s Source) String() string {
+	switch {
+	case s.SSMParameter != "":
+		return "ssm:" + s.SSMParameter
+	case s.NamePattern != "":
+		return fmt.Sprintf("name:%s (owners %s)", s.NamePattern, strings.Join(s.Owners, ","))
+	}
+	return s.ImageID
+}
+
+// EC2API is the subset of the EC2 client a Roller uses.
+type EC2API interface {
+	DescribeImages(ctx context.Context, params *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)
+	DescribeLaunchTemplateVersions(ctx context.Context, params *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
+	CreateLaunchTemplateVersion(ctx context.Context, params *ec2.CreateLaunchTemplateVersionInput, optFns ...func(*ec2.Options)) (*ec2.CreateLaunchTemplateVersionOutput, error)
+}
+
+// SSMAPI is the subset of the SSM client a Roller uses.
+type SSMAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// EKSAPI is the subset of the EKS client a Roller uses.
+type EKSAPI interface {
+	UpdateNodegroupVersion(ctx context.Context, params *eks.UpdateNodegroupVersionInput, optFns ...func(*eks.Options)) (*eks.UpdateNodegroupVersionOutput, error)
+}
+
+// Roller rolls custom-AMI nodegroups onto the AMI its Source names.
+type Roller struct {
+	Source Source
+
+	eks EKSAPI
+	ec2 EC2API
+	ssm SSMAPI
+
+	mu      sync.Mutex
+	targets map[string]string // by Kubernetes version
+}
+
+// NewRoller creates a Roller using the given clients.
+func NewRoller(src Source, eksClient EKSAPI, ec2Client EC2API, ssmClient SSMAPI) *Roller {
+	return &Roller{Source: src, eks: eksClient, ec2: ec2Client, ssm: ssmClient, targets: make(map[string]string)}
+}
+
+// NewRollerFromConfig creates a Roller with EC2 and SSM clients built from
+// awsCfg.
+func NewRollerFromConfig(src Source, awsCfg aws.Config, eksClient EKSAPI) *Roller {
+	return NewRoller(src, eksClient, ec2.NewFromConfig(awsCfg), ssm.NewFromConfig(awsCfg))
+}
+
+// TargetImage resolves the source for a Kubernetes version, memoized so
+// every nodegroup of one run gets the same AMI.
+func (r *Roller) TargetImage(ctx context.Context, k8sVersion string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.targets[k8sVersion]; ok {
+		return id, nil
+	}
+	id, err := r.resolve(ctx, k8sVersion)
+	if err != nil {
+		return "", fmt.Errorf("resolving custom AMI from %s: %w", r.Source, err)
+	}
+	r.targets[k8sVersion] = id
+	return id, nil
+}
+
+func (r *Roller) resolve(ctx context.Context, k8sVersion string) (string, error) {
+	s := r.Source
+	switch {
+	case s.ImageID != "":
+		return s.ImageID, nil
+	case s.SSMParameter != "":
+		name := strings.ReplaceAll(s.SSMParameter, versionPlaceholder, k8sVersion)
+		out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*ssm.GetParameterOutput, error) {
+			return r.ssm.GetParameter(rc, &ssm.GetParameterInput{Name: aws.String(name)})
+		})
+		if err != nil {
+			return "", awsinternal.FormatAWSError(err, "reading SSM parameter "+name)
+		}
+		if out.Parameter == nil || !strings.HasPrefix(aws.ToString(out.Parameter.Value), "ami-") {
+			return "", fmt.Errorf("SSM parameter %s does not hold an AMI ID", name)
+		}
+		return aws.ToString(out.Parameter.Value), nil
+	case s.NamePattern != "":
+		pattern := strings.ReplaceAll(s.NamePattern, versionPlaceholder, k8sVersion)
+		out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*ec2.DescribeImagesOutput, error) {
+			return r.ec2.DescribeImages(rc, &ec2.DescribeImagesInput{
+				Owners: s.Owners,
+				Filters: []ec2types.Filter{
+					{Name: aws.String("name"), Values: []string{pattern}},
+					{Name: aws.String("state"), Values: []string{"available"}},
+				},
+			})
+		})
+		if err != nil {
+			return "", awsinternal.FormatAWSError(err, "describing images named "+pattern)
+		}
+		return newestImage(out.Images, pattern, s.Owners)
+	}
+	return "", fmt.Errorf("no custom AMI source configured")
+}
+
+// newestImage picks the most recently created image. CreationDate is
+// ISO 8601, so it sorts as a string.
+func newestImage(images []ec2types.Image, pattern string, owners []string) (string, error) {
+	if len(images) == 0 {
+		return "", fmt.Errorf("no available image named %s owned by %s", pattern, strings.Join(owners, ","))
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		return aws.ToString(images[i].CreationDate) > aws.ToString(images[j].CreationDate)
+	})
+	return aws.ToString(images[0].ImageId), nil
+}
+
+// CurrentImage returns the AMI in the nodegroup's current launch template
+// version.
+func (r *Roller) CurrentImage(ctx context.Context, ng *ekstypes.Nodegroup) (string, error) {
+	lt, err := launchTemplate(ng)
+	if err != nil {
+		return "", err
+	}
+	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+		return r.ec2.DescribeLaunchTemplateVersions(rc, &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: lt.Id,
+			Versions:         []string{aws.ToString(lt.Version)},
+		})
+	})
+	if err != nil {
+		return "", awsinternal.FormatAWSError(err, fmt.Sprintf("describing launch template %s", aws.ToString(lt.Id)))
+	}
+	if len(out.LaunchTemplateVersions) == 0 || out.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return "", fmt.Errorf("launch template %s version %s not found", aws.ToString(lt.Id), aws.ToString(lt.Version))
+	}
+	return aws.ToString(out.LaunchTemplateVersions[0].LaunchTemplateData.ImageId), nil
+}
+
+// Rolled is a started custom-AMI roll.
+type Rolled struct {
+	Update *ekstypes.Update
+	// LaunchTemplate and Version identify the launch template version that
+	// was published for the roll.
+	LaunchTemplate string
+	Version        string
+}
+
+// Roll publishes a launch template version based on the nodegroup's current
+// one with only ImageId changed to imageID, then starts the nodegroup's
+// rolling update onto it.
+func (r *Roller) Roll(ctx context.Context, clusterName string, ng *ekstypes.Nodegroup, imageID string, force bool) (Rolled, error) {
+	lt, err := launchTemplate(ng)
+	if err != nil {
+		return Rolled{}, err
+	}
+	created, err := r.ec2.CreateLaunchTemplateVersion(ctx, &ec2.CreateLaunchTemplateVersionInput{
+		LaunchTemplateId:   lt.Id,
+		SourceVersion:      lt.Version,
+		VersionDescription: aws.String("refresh: ImageId " + imageID),
+		LaunchTemplateData: &ec2types.RequestLaunchTemplateData{ImageId: aws.String(imageID)},
+		ClientToken:        aws.String(common.IdempotencyToken()),
+	})
+	if err != nil {
+		return Rolled{}, awsinternal.FormatAWSError(err, fmt.Sprintf("creating a version of launch template %s", aws.ToString(lt.Id)))
+	}
+	if created.LaunchTemplateVersion == nil || created.LaunchTemplateVersion.VersionNumber == nil {
+		return Rolled{}, fmt.Errorf("launch template %s: no version number returned", aws.ToString(lt.Id))
+	}
+	version := strconv.FormatInt(*created.LaunchTemplateVersion.VersionNumber, 10)
+
+	// A custom AMI sets the node's Kubernetes version, so Version is left
+	// out: EKS rejects it alongside a custom-AMI launch template.
+	input := &eks.UpdateNodegroupVersionInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: ng.NodegroupName,
+		LaunchTemplate: &ekstypes.LaunchTemplateSpecification{
+			Id:      lt.Id,
+			Version: aws.String(version),
+		},
+		Force:              force,
+		ClientRequestToken: aws.String(common.IdempotencyToken()),
+	}
+	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.UpdateNodegroupVersionOutput, error) {
+		return r.eks.UpdateNodegroupVersion(rc, input)
+	})
+	if err != nil {
+		return Rolled{}, awsinternal.FormatAWSError(err, fmt.Sprintf("rolling nodegroup %s onto launch template version %s", aws.ToString(ng.NodegroupName), version))
+	}
+	return Rolled{Update: out.Update, LaunchTemplate: aws.ToString(lt.Id), Version: version}, nil
+}
+
+func launchTemplate(ng *ekstypes.Nodegroup) (*ekstypes.LaunchTemplateSpecification, error) {
+	if ng == nil || ng.LaunchTemplate == nil || ng.LaunchTemplate.Id == nil || ng.LaunchTemplate.Version == nil {
+		name := ""
+		if ng != nil {
+			name = aws.ToString(ng.NodegroupName)
+		}
+		return nil, fmt.Errorf("nodegroup %s has no launch template to publish a version of", name)
+	}
+	return ng.LaunchTemplate, nil
+}
@@ -0,0 +1,182 @@
+package customami
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+type fakeEC2 struct {
+	images      []ec2types.Image
+	imagesInput *ec2.DescribeImagesInput
+	ltImage     string
+	createInput *ec2.CreateLaunchTemplateVersionInput
+	createdNum  int64
+}
+
+func (f *fakeEC2) DescribeImages(_ context.Context, in *ec2.DescribeImagesInput, _ ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	f.imagesInput = in
+	return &ec2.DescribeImagesOutput{Images: f.images}, nil
+}
+
+func (f *fakeEC2) DescribeLaunchTemplateVersions(_ context.Context, in *ec2.DescribeLaunchTemplateVersionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	if f.ltImage == "" {
+		return &ec2.DescribeLaunchTemplateVersionsOutput{}, nil
+	}
+	return &ec2.DescribeLaunchTemplateVersionsOutput{LaunchTemplateVersions: []ec2types.LaunchTemplateVersion{{
+		LaunchTemplateId:   in.LaunchTemplateId,
+		LaunchTemplateData: &ec2types.ResponseLaunchTemplateData{ImageId: aws.String(f.ltImage)},
+	}}}, nil
+}
+
+func (f *fakeEC2) CreateLaunchTemplateVersion(_ context.Context, in *ec2.CreateLaunchTemplateVersionInput, _ ...func(*ec2.Options)) (*ec2.CreateLaunchTemplateVersionOutput, error) {
+	f.createInput = in
+	return &ec2.CreateLaunchTemplateVersionOutput{LaunchTemplateVersion: &ec2types.LaunchTemplateVersion{VersionNumber: aws.Int64(f.createdNum)}}, nil
+}
+
+type fakeSSM struct {
+	values map[string]string
+	calls  int
+}
+
+func (f *fakeSSM) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.calls++
+	v, ok := f.values[aws.ToString(in.Name)]
+	if !ok {
+		return nil, &ssmtypes.ParameterNotFound{Message: aws.String("not found")}
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Value: aws.String(v)}}, nil
+}
+
+type fakeEKS struct {
+	input *eks.UpdateNodegroupVersionInput
+}
+
+func (f *fakeEKS) UpdateNodegroupVersion(_ context.Context, in *eks.UpdateNodegroupVersionInput, _ ...func(*eks.Options)) (*eks.UpdateNodegroupVersionOutput, error) {
+	f.input = in
+	return &eks.UpdateNodegroupVersionOutput{Update: &ekstypes.Update{Id: aws.String("upd-1"), Status: ekstypes.UpdateStatusInProgress}}, nil
+}
+
+func customNodegroup() *ekstypes.Nodegroup {
+	return &ekstypes.Nodegroup{
+		NodegroupName:  aws.String("byo"),
+		AmiType:        ekstypes.AMITypesCustom,
+		Version:        aws.String("1.31"),
+		LaunchTemplate: &ekstypes.LaunchTemplateSpecification{Id: aws.String("lt-123"), Version: aws.String("7")},
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec    string
+		owners  []string
+		want    Source
+		wantErr bool
+	}{
+		{spec: "ssm:/platform/ami/{version}", want: Source{SSMParameter: "/platform/ami/{version}"}},
+		{spec: "name:golden-*", want: Source{NamePattern: "golden-*", Owners: []string{"self"}}},
+		{spec: "name:golden-*", owners: []string{"123456789012"}, want: Source{NamePattern: "golden-*", Owners: []string{"123456789012"}}},
+		{spec: " ami-0abc ", want: Source{ImageID: "ami-0abc"}},
+		{spec: "ssm:", wantErr: true},
+		{spec: "golden-*", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSource(tt.spec, tt.owners)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSource(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want.String() {
+			t.Errorf("ParseSource(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestTargetImage_SSMParameterPerVersion(t *testing.T) {
+	params := &fakeSSM{values: map[string]string{"/ami/1.31/golden": "ami-131", "/ami/1.32/golden": "ami-132", "/ami/bad": "not-an-ami"}}
+	r := NewRoller(Source{SSMParameter: "/ami/{version}/golden"}, &fakeEKS{}, &fakeEC2{}, params)
+
+	for version, want := range map[string]string{"1.31": "ami-131", "1.32": "ami-132"} {
+		if got, err := r.TargetImage(context.Background(), version); err != nil || got != want {
+			t.Errorf("TargetImage(%s) = %q, %v; want %q", version, got, err, want)
+		}
+	}
+	if _, err := r.TargetImage(context.Background(), "1.31"); err != nil || params.calls != 2 {
+		t.Errorf("expected the 1.31 target to be memoized, got %d SSM calls (err %v)", params.calls, err)
+	}
+
+	bad := NewRoller(Source{SSMParameter: "/ami/bad"}, &fakeEKS{}, &fakeEC2{}, params)
+	if _, err := bad.TargetImage(context.Background(), "1.31"); err == nil || !strings.Contains(err.Error(), "does not hold an AMI ID") {
+		t.Errorf("non-AMI parameter: err = %v", err)
+	}
+}
+
+func TestTargetImage_NamePatternPicksNewest(t *testing.T) {
+	images := &fakeEC2{images: []ec2types.Image{
+		{ImageId: aws.String("ami-old"), CreationDate: aws.String("2026-05-01T10:00:00.000Z")},
+		{ImageId: aws.String("ami-new"), CreationDate: aws.String("2026-06-01T10:00:00.000Z")},
+		{ImageId: aws.String("ami-mid"), CreationDate: aws.String("2026-05-15T10:00:00.000Z")},
+	}}
+	r := NewRoller(Source{NamePattern: "golden-{version}-*", Owners: []string{"self"}}, &fakeEKS{}, images, &fakeSSM{})
+
+	got, err := r.TargetImage(context.Background(), "1.31")
+	if err != nil || got != "ami-new" {
+		t.Fatalf("TargetImage() = %q, %v; want ami-new", got, err)
+	}
+	if name := images.imagesInput.Filters[0].Values[0]; name != "golden-1.31-*" {
+		t.Errorf("name filter = %q, want the version substituted", name)
+	}
+
+	empty := NewRoller(Source{NamePattern: "none-*", Owners: []string{"self"}}, &fakeEKS{}, &fakeEC2{}, &fakeSSM{})
+	if _, err := empty.TargetImage(context.Background(), "1.31"); err == nil || !strings.Contains(err.Error(), "no available image named none-*") {
+		t.Errorf("no images: err = %v", err)
+	}
+}
+
+func TestRollPublishesImageOnlyVersion(t *testing.T) {
+	ec2c := &fakeEC2{ltImage: "ami-old", createdNum: 8}
+	eksc := &fakeEKS{}
+	r := NewRoller(Source{ImageID: "ami-new"}, eksc, ec2c, &fakeSSM{})
+	ng := customNodegroup()
+
+	if current, err := r.CurrentImage(context.Background(), ng); err != nil || current != "ami-old" {
+		t.Fatalf("CurrentImage() = %q, %v", current, err)
+	}
+	rolled, err := r.Roll(context.Background(), "prod", ng, "ami-new", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolled.Version != "8" || rolled.LaunchTemplate != "lt-123" || aws.ToString(rolled.Update.Id) != "upd-1" {
+		t.Errorf("Roll() = %+v", rolled)
+	}
+
+	in := ec2c.createInput
+	if aws.ToString(in.SourceVersion) != "7" || aws.ToString(in.LaunchTemplateData.ImageId) != "ami-new" || in.LaunchTemplateData.UserData != nil {
+		t.Errorf("CreateLaunchTemplateVersion input = %+v, want source 7 with only ImageId set", in)
+	}
+	up := eksc.input
+	if up.Version != nil || up.ReleaseVersion != nil || !up.Force ||
+		aws.ToString(up.LaunchTemplate.Id) != "lt-123" || aws.ToString(up.LaunchTemplate.Version) != "8" {
+		t.Errorf("UpdateNodegroupVersion input = %+v", up)
+	}
+}
+
+func TestRollRequiresLaunchTemplate(t *testing.T) {
+	r := NewRoller(Source{ImageID: "ami-new"}, &fakeEKS{}, &fakeEC2{}, &fakeSSM{})
+	ng := customNodegroup()
+	ng.LaunchTemplate = nil
+	if _, err := r.Roll(context.Background(), "prod", ng, "ami-new", false); err == nil || !strings.Contains(err.Error(), "no launch template") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := r.CurrentImage(context.Background(), customNodegroup()); err == nil || !strings.Contains(err.Error(), "version 7 not found") {
+		t.Errorf("missing launch template version: err = %v", err)
+	}
+}
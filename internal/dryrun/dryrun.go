@@ -34,7 +34,28 @@ type NodegroupUpdate struct {
 	Action     refreshTypes.DryRunAction
 	CurrentAMI string
 	LatestAMI  string
-	Reason     string
+	// Pinned is the release version the roll is pinned to (--release-version
+	// or --match-cluster); LatestAMI is then that release's AMI.
+	Pinned string
+	// Custom marks a custom-AMI nodegroup rolled through a new launch
+	// template version; LatestAMI is then the --custom-ami target.
+	Custom bool
+	Reason string
+}
+
+// CustomAMIFunc resolves a custom-AMI nodegroup's current AMI and the AMI
+// --custom-ami would roll it to.
+type CustomAMIFunc func(ctx context.Context, ng *types.Nodegroup) (current, target string, err error)
+
+// Options tunes a dry run beyond the plain "roll to latest" preview.
+type Options struct {
+	Force, Quiet bool
+	// Pins maps a nodegroup name to the release it would roll to instead of
+	// the latest recommended AMI.
+	Pins map[string]awsClient.AMIRelease
+	// CustomAMI previews custom-AMI nodegroups; nil means they are skipped,
+	// as the real run does without --custom-ami.
+	CustomAMI CustomAMIFunc
 }
 
 // DryRunner handles dry-run operations for AMI updates.
@@ -48,6 +69,8 @@ type DryRunner struct {
 	force               bool
 	quiet               bool
 	latestByType        map[types.AMITypes]string
+	pins                map[string]awsClient.AMIRelease // by nodegroup name
+	customAMI           CustomAMIFunc
 	describeNodegroupFn func(context.Context, string) (*types.Nodegroup, error)
 	currentAmiFn        func(context.Context, *types.Nodegroup) string
 	latestAmiFn         func(context.Context, *types.Nodegroup) string
@@ -98,10 +121,18 @@ func NewDryRunner(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client,
 
 // PerformDryRun shows what would be updated without making changes.
 func PerformDryRun(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName string, selectedNodegroups []string, force bool, quiet bool) error {
-	runner, err := newDryRunner(ctx, awsCfg, eksClient, clusterName, force, quiet)
+	return PerformDryRunWith(ctx, awsCfg, eksClient, clusterName, selectedNodegroups, Options{Force: force, Quiet: quiet})
+}
+
+// PerformDryRunWith is PerformDryRun with pinned releases and custom-AMI
+// targets. Nodegroups without a pin target the latest as usual.
+func PerformDryRunWith(ctx context.Context, awsCfg aws.Config, eksClient *eks.Client, clusterName string, selectedNodegroups []string, opts Options) error {
+	runner, err := newDryRunner(ctx, awsCfg, eksClient, clusterName, opts.Force, opts.Quiet)
 	if err != nil {
 		return err
 	}
+	runner.pins = opts.Pins
+	runner.customAMI = opts.CustomAMI
 
 	result := runner.Analyze(ctx, selectedNodegroups)
 	runner.DisplayResults(result)
@@ -145,8 +176,15 @@ func (dr *DryRunner) analyzeNodegroup(ctx context.Context, ngName string) Nodegr
 		return update
 	}
 
+	if ng.AmiType == types.AMITypesCustom {
+		return dr.analyzeCustom(ctx, update, ng)
+	}
+
 	// Get AMI information
 	update.CurrentAMI = dr.currentAmi(ctx, ng)
+	if pin, ok := dr.pins[ngName]; ok {
+		return dr.analyzePinned(update, ng, pin)
+	}
 	update.LatestAMI = dr.latestAmi(ctx, ng)
 
 	// Determine action
@@ -173,6 +211,60 @@ func (dr *DryRunner) analyzeNodegroup(ctx context.Context, ngName string) Nodegr
 	return update
 }
 
+// analyzePinned decides the action for a nodegroup pinned to a release. The
+// nodegroup's reported release version is compared rather than AMI IDs, the
+// same way the real run decides to skip it.
+func (dr *DryRunner) analyzePinned(update NodegroupUpdate, ng *types.Nodegroup, pin awsClient.AMIRelease) NodegroupUpdate {
+	update.LatestAMI = pin.ImageID
+	update.Pinned = pin.ReleaseVersion
+	current := aws.ToString(ng.ReleaseVersion)
+	switch {
+	case dr.force:
+		update.Action = refreshTypes.ActionForceUpdate
+		update.Reason = fmt.Sprintf("force flag specified (pinned to release %s)", pin.ReleaseVersion)
+	case current == pin.ReleaseVersion:
+		update.Action = refreshTypes.ActionSkipLatest
+		update.Reason = fmt.Sprintf("already on pinned release %s", pin.ReleaseVersion)
+	case current == "":
+		update.Action = refreshTypes.ActionUpdate
+		update.Reason = fmt.Sprintf("pinned to release %s", pin.ReleaseVersion)
+	default:
+		update.Action = refreshTypes.ActionUpdate
+		update.Reason = fmt.Sprintf("pinned to release %s (running %s)", pin.ReleaseVersion, current)
+	}
+	return update
+}
+
+// analyzeCustom decides the action for a custom-AMI nodegroup: rolled onto
+// the --custom-ami target through a new launch template version, or skipped
+// when no source is configured or it already runs the target.
+func (dr *DryRunner) analyzeCustom(ctx context.Context, update NodegroupUpdate, ng *types.Nodegroup) NodegroupUpdate {
+	if dr.customAMI == nil {
+		update.Action = refreshTypes.ActionSkipUpdating
+		update.Reason = "custom AMI (AmiType=CUSTOM); set --custom-ami to roll it"
+		return update
+	}
+	current, target, err := dr.customAMI(ctx, ng)
+	if err != nil {
+		update.Action = refreshTypes.ActionSkipUpdating
+		update.Reason = err.Error()
+		return update
+	}
+	update.CurrentAMI, update.LatestAMI, update.Custom = current, target, true
+	switch {
+	case dr.force:
+		update.Action = refreshTypes.ActionForceUpdate
+		update.Reason = fmt.Sprintf("force flag specified (custom AMI %s)", target)
+	case current == target:
+		update.Action = refreshTypes.ActionSkipLatest
+		update.Reason = fmt.Sprintf("already on custom AMI %s", target)
+	default:
+		update.Action = refreshTypes.ActionUpdate
+		update.Reason = fmt.Sprintf("new launch template version with custom AMI %s", target)
+	}
+	return update
+}
+
 func (dr *DryRunner) describeNodegroup(ctx context.Context, ngName string) (*types.Nodegroup, error) {
 	if dr.describeNodegroupFn != nil {
 		return dr.describeNodegroupFn(ctx, ngName)
@@ -252,13 +344,17 @@ func (dr *DryRunner) DisplayResults(result *DryRunResult) {
 	// Summary
 	color.Cyan("Summary:")
 	ui.Outf("- Nodegroups that would be updated: %d\n", len(result.UpdatesNeeded))
-	ui.Outf("- Nodegroups that would be skipped (already updating): %d\n", len(result.UpdatesSkipped))
-	ui.Outf("- Nodegroups already on latest AMI: %d\n", len(result.AlreadyLatest))
+	ui.Outf("- Nodegroups that would be skipped: %d\n", len(result.UpdatesSkipped))
+	target := "latest AMI"
+	if len(dr.pins) > 0 || dr.customAMI != nil {
+		target = "target AMI"
+	}
+	ui.Outf("- Nodegroups already on %s: %d\n", target, len(result.AlreadyLatest))
 
 	// Detailed lists
 	dr.printNodegroupList("Would update:", result.UpdatesNeeded, color.GreenString)
-	dr.printNodegroupList("Would skip (already updating):", result.UpdatesSkipped, color.YellowString)
-	dr.printNodegroupList("Already on latest AMI:", result.AlreadyLatest, color.CyanString)
+	dr.printNodegroupList("Would skip:", result.UpdatesSkipped, color.YellowString)
+	dr.printNodegroupList("Already on "+target+":", result.AlreadyLatest, color.CyanString)
 
 	ui.Outln("\nTo execute these updates, run the same command without --dry-run")
 }
@@ -272,6 +368,18 @@ func (dr *DryRunner) printNodegroupList(header string, updates []NodegroupUpdate
 	ui.Outf("\n%s\n", colorFn(header))
 	for _, update := range updates {
 		ui.Outf("  - %s\n", update.Name)
+		if update.Pinned != "" {
+			if update.CurrentAMI != "" {
+				ui.Outf("    Current: %s\n", update.CurrentAMI)
+			}
+			ui.Outf("    Target:  %s (release %s, pinned)\n", update.LatestAMI, update.Pinned)
+			continue
+		}
+		if update.Custom {
+			ui.Outf("    Current: %s\n", update.CurrentAMI)
+			ui.Outf("    Target:  %s (custom AMI, new launch template version)\n", update.LatestAMI)
+			continue
+		}
 		if update.CurrentAMI != "" && update.LatestAMI != "" {
 			ui.Outf("    Current: %s\n", update.CurrentAMI)
 			ui.Outf("    Latest:  %s\n", update.LatestAMI)
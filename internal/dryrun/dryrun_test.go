@@ -13,6 +13,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	awsClient "github.com/dantech2000/refresh/internal/aws"
 	refreshTypes "github.com/dantech2000/refresh/internal/types"
 )
 
@@ -258,6 +260,88 @@ func TestAnalyzeNodegroupBranchesWithInjectedLookups(t *testing.T) {
 	}
 }
 
+func TestAnalyzePinnedComparesReleaseVersions(t *testing.T) {
+	pin := awsClient.AMIRelease{ReleaseVersion: "1.31.0-20260601", ImageID: "ami-pinned"}
+	dr := &DryRunner{
+		clusterName: "test-cluster",
+		quiet:       true,
+		pins:        map[string]awsClient.AMIRelease{"staged": pin, "current": pin},
+		describeNodegroupFn: func(_ context.Context, name string) (*types.Nodegroup, error) {
+			release := "1.31.0-20260515"
+			if name == "current" {
+				release = pin.ReleaseVersion
+			}
+			return &types.Nodegroup{NodegroupName: aws.String(name), Status: types.NodegroupStatusActive, ReleaseVersion: aws.String(release)}, nil
+		},
+		currentAmiFn: func(context.Context, *types.Nodegroup) string { return "ami-running" },
+		latestAmiFn: func(context.Context, *types.Nodegroup) string {
+			t.Error("a pinned nodegroup should not look up the latest AMI")
+			return ""
+		},
+	}
+
+	got := dr.analyzeNodegroup(context.Background(), "staged")
+	if got.Action != refreshTypes.ActionUpdate || got.LatestAMI != "ami-pinned" || got.Pinned != pin.ReleaseVersion ||
+		got.Reason != "pinned to release 1.31.0-20260601 (running 1.31.0-20260515)" {
+		t.Errorf("staged = %+v", got)
+	}
+	if got := dr.analyzeNodegroup(context.Background(), "current"); got.Action != refreshTypes.ActionSkipLatest {
+		t.Errorf("current = %+v, want skipped as already on the pinned release", got)
+	}
+
+	dr.quiet = false
+	out := captureStdout(func() {
+		dr.DisplayResults(&DryRunResult{UpdatesNeeded: []NodegroupUpdate{got}})
+	})
+	for _, want := range []string{"already on target AMI: 0", "Target:  ami-pinned (release 1.31.0-20260601, pinned)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("display missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnalyzeCustomUsesResolver(t *testing.T) {
+	custom := func(name string) (*types.Nodegroup, error) {
+		return &types.Nodegroup{NodegroupName: aws.String(name), Status: types.NodegroupStatusActive, AmiType: types.AMITypesCustom}, nil
+	}
+	dr := &DryRunner{
+		clusterName:         "test-cluster",
+		quiet:               true,
+		describeNodegroupFn: func(_ context.Context, name string) (*types.Nodegroup, error) { return custom(name) },
+	}
+	if got := dr.analyzeNodegroup(context.Background(), "byo"); got.Action != refreshTypes.ActionSkipUpdating || !strings.Contains(got.Reason, "--custom-ami") {
+		t.Errorf("without a resolver = %+v, want skipped with guidance", got)
+	}
+
+	dr.customAMI = func(_ context.Context, ng *types.Nodegroup) (string, string, error) {
+		switch aws.ToString(ng.NodegroupName) {
+		case "current":
+			return "ami-new", "ami-new", nil
+		case "broken":
+			return "", "", errors.New("no available image named golden-*")
+		}
+		return "ami-old", "ami-new", nil
+	}
+	got := dr.analyzeNodegroup(context.Background(), "byo")
+	if got.Action != refreshTypes.ActionUpdate || !got.Custom || got.CurrentAMI != "ami-old" || got.LatestAMI != "ami-new" {
+		t.Errorf("byo = %+v", got)
+	}
+	if got := dr.analyzeNodegroup(context.Background(), "current"); got.Action != refreshTypes.ActionSkipLatest {
+		t.Errorf("current = %+v, want skipped as already on the target", got)
+	}
+	if got := dr.analyzeNodegroup(context.Background(), "broken"); got.Action != refreshTypes.ActionSkipUpdating || !strings.Contains(got.Reason, "golden-*") {
+		t.Errorf("broken = %+v, want the resolve error surfaced", got)
+	}
+
+	dr.quiet = false
+	out := captureStdout(func() {
+		dr.DisplayResults(&DryRunResult{UpdatesNeeded: []NodegroupUpdate{got}})
+	})
+	if !strings.Contains(out, "Target:  ami-new (custom AMI, new launch template version)") {
+		t.Errorf("display missing the custom target:\n%s", out)
+	}
+}
+
 func TestNewDryRunnerAndPerformDryRunErrorPaths(t *testing.T) {
 	if _, err := NewDryRunner(context.Background(), aws.Config{}, nil, "cluster", false, true); err == nil {
 		t.Fatal("expected error for nil EKS client")
@@ -15,9 +15,10 @@ import (
 	"github.com/dantech2000/refresh/internal/services/common"
 )
 
-// CPU headroom thresholds for safe rolling updates: at least
+// Default CPU headroom thresholds for safe rolling updates: at least
 // minSafeCPUHeadroomPercent free is a pass, at least
 // minWarnCPUHeadroomPercent is a warning, anything less is a failure.
+// Overridable per cluster via Thresholds.
 const (
 	minSafeCPUHeadroomPercent = 30.0
 	minWarnCPUHeadroomPercent = 15.0
@@ -72,14 +73,16 @@ func (hc *HealthChecker) checkClusterCapacityWith(ctx context.Context, snap *cpu
 	result.Details = append(result.Details, fmt.Sprintf("Average CPU utilization: %.1f%% (peak node %.1f%%)", avgCPU, maxCPU))
 	result.Details = append(result.Details, "Memory utilization: Not available (requires Container Insights)")
 
+	th := hc.thresholds()
+
 	// Mean-based verdict from cluster-wide CPU headroom.
 	headroom := 100 - avgCPU
 	switch {
-	case headroom >= minSafeCPUHeadroomPercent:
+	case headroom >= th.MinSafeCPUHeadroomPercent:
 		result.Status = StatusPass
 		result.Score = 100
 		result.Message = fmt.Sprintf("Sufficient CPU capacity (avg %.1f%%, peak %.1f%%)", avgCPU, maxCPU)
-	case headroom >= minWarnCPUHeadroomPercent:
+	case headroom >= th.MinWarnCPUHeadroomPercent:
 		result.Status = StatusWarn
 		result.Score = 70
 		result.Message = fmt.Sprintf("Limited CPU capacity (avg %.1f%%, peak %.1f%%)", avgCPU, maxCPU)
@@ -95,18 +98,20 @@ func (hc *HealthChecker) checkClusterCapacityWith(ctx context.Context, snap *cpu
 	// rolling update even when the cluster mean looks healthy. Only ever
 	// degrade the mean-based verdict, never improve it.
 	switch {
-	case maxCPU >= peakFailCPUPercent && result.Status != StatusFail:
+	case maxCPU >= th.PeakFailCPUPercent && result.Status != StatusFail:
 		result.Status = StatusFail
 		result.Score = 30
 		result.Message = fmt.Sprintf("A node is near CPU saturation (peak %.1f%%, avg %.1f%%)", maxCPU, avgCPU)
-		result.Details = append(result.Details, fmt.Sprintf("At least one node at/above %.0f%% CPU — rolling it could overload its peers", peakFailCPUPercent))
-	case maxCPU >= peakWarnCPUPercent && result.Status == StatusPass:
+		result.Details = append(result.Details, fmt.Sprintf("At least one node at/above %s CPU — rolling it could overload its peers", fmtPct(th.PeakFailCPUPercent)))
+	case maxCPU >= th.PeakWarnCPUPercent && result.Status == StatusPass:
 		result.Status = StatusWarn
 		result.Score = 70
 		result.Message = fmt.Sprintf("Uneven CPU capacity (peak %.1f%%, avg %.1f%%)", maxCPU, avgCPU)
-		result.Details = append(result.Details, fmt.Sprintf("At least one node at/above %.0f%% CPU despite a low cluster average", peakWarnCPUPercent))
+		result.Details = append(result.Details, fmt.Sprintf("At least one node at/above %s CPU despite a low cluster average", fmtPct(th.PeakWarnCPUPercent)))
 	}
 
+	result.Details = append(result.Details, fmt.Sprintf("Thresholds: CPU headroom pass ≥%s, warn ≥%s; peak node warn ≥%s, fail ≥%s",
+		fmtPct(th.MinSafeCPUHeadroomPercent), fmtPct(th.MinWarnCPUHeadroomPercent), fmtPct(th.PeakWarnCPUPercent), fmtPct(th.PeakFailCPUPercent)))
 	return result
 }
 
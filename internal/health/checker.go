@@ -59,6 +59,8 @@ type HealthChecker struct {
 	asgClient   *autoscaling.Client
 	nodeMetrics NodeMetricsLister // optional; enables the live utilization check
 	sqClient    serviceQuotaAPI   // optional; enables the vCPU quota headroom check
+	opts        *Options          // optional; nil means DefaultOptions
+	drainPlans  []DrainPlan       // optional; enables the scheduling simulation
 }
 
 // NewChecker creates a new health checker instance
@@ -71,36 +73,102 @@ func NewChecker(eksClient *eks.Client, k8sClient kubernetes.Interface, cwClient
 	}
 }
 
-// RunAllChecks executes all health checks and returns a summary. The checks
-// are independent, so they run concurrently; capacity and balance share one
-// instance-discovery + CloudWatch fetch via a lazy snapshot.
+// SetOptions applies threshold overrides, check selection and blocking
+// overrides. Unknown check IDs or inconsistent thresholds are an error and
+// leave the checker unchanged.
+func (hc *HealthChecker) SetOptions(o Options) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	hc.opts = &o
+	return nil
+}
+
+// WithOptions returns a copy of the checker with o applied, leaving hc as it
+// was. Services that share one checker across clusters use it to apply each
+// cluster's own options without racing the other runs.
+func (hc *HealthChecker) WithOptions(o Options) (*HealthChecker, error) {
+	c := *hc
+	if err := c.SetOptions(o); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// options returns the configured Options, or the defaults.
+func (hc *HealthChecker) options() Options {
+	if hc.opts == nil {
+		return DefaultOptions()
+	}
+	return *hc.opts
+}
+
+// thresholds returns the limits the checks evaluate against.
+func (hc *HealthChecker) thresholds() Thresholds { return hc.options().Thresholds }
+
+// RunAllChecks executes the selected health checks (all by default) and
+// returns a summary. The checks are independent, so they run concurrently;
+// capacity and balance share one instance-discovery + CloudWatch fetch via a
+// lazy snapshot.
 func (hc *HealthChecker) RunAllChecks(ctx context.Context, clusterName string) HealthSummary {
 	snap := hc.newCPUSnapshot(clusterName)
-	checks := []func() HealthResult{
-		func() HealthResult { return hc.CheckNodeHealth(ctx, clusterName) },
-		func() HealthResult { return hc.checkClusterCapacityWith(ctx, snap) },
-		func() HealthResult { return hc.CheckNodeUtilization(ctx, clusterName) },
-		func() HealthResult { return hc.CheckControlPlaneMetrics(ctx, clusterName) },
-		func() HealthResult { return hc.CheckServiceQuotas(ctx, clusterName) },
-		func() HealthResult { return hc.CheckCriticalWorkloads(ctx) },
-		func() HealthResult { return hc.CheckPodDisruptionBudgets(ctx) },
-		func() HealthResult { return hc.checkResourceBalanceWith(ctx, snap) },
+	all := map[string]func() HealthResult{
+		CheckNodeHealthID:   func() HealthResult { return hc.CheckNodeHealth(ctx, clusterName) },
+		CheckCapacityID:     func() HealthResult { return hc.checkClusterCapacityWith(ctx, snap) },
+		CheckUtilizationID:  func() HealthResult { return hc.CheckNodeUtilization(ctx, clusterName) },
+		CheckControlPlaneID: func() HealthResult { return hc.CheckControlPlaneMetrics(ctx, clusterName) },
+		CheckQuotasID:       func() HealthResult { return hc.CheckServiceQuotas(ctx, clusterName) },
+		CheckWorkloadsID:    func() HealthResult { return hc.CheckCriticalWorkloads(ctx) },
+		CheckPDBsID:         func() HealthResult { return hc.CheckPodDisruptionBudgets(ctx) },
+		CheckBalanceID:      func() HealthResult { return hc.checkResourceBalanceWith(ctx, snap) },
+	}
+	// The scheduling simulation and drain blocker analysis need to know which
+	// nodes go away, so they only run for an operation that set drain plans (a
+	// roll or scale-down).
+	if len(hc.drainPlans) > 0 {
+		all[CheckSchedulingID] = func() HealthResult { return hc.CheckScheduling(ctx) }
+		all[CheckDrainID] = func() HealthResult { return hc.CheckDrainBlockers(ctx) }
+	}
+	opts := hc.options()
+	var ids []string
+	for _, id := range opts.selected() {
+		if all[id] != nil {
+			ids = append(ids, id)
+		}
 	}
 
-	results := make([]HealthResult, len(checks))
+	results := make([]HealthResult, len(ids))
 	var wg sync.WaitGroup
-	for i, check := range checks {
+	for i, id := range ids {
 		wg.Add(1)
 		go func(i int, check func() HealthResult) {
 			defer wg.Done()
 			results[i] = check()
-		}(i, check)
+		}(i, all[id])
 	}
 	wg.Wait()
 
+	for i, id := range ids {
+		applyBlockingOverride(&results[i], opts.Blocking, id)
+	}
 	return aggregateResults(results)
 }
 
+// applyBlockingOverride sets IsBlocking from a per-check override, noting the
+// change in the details so a non-blocking FAIL isn't a surprise.
+func applyBlockingOverride(r *HealthResult, overrides map[string]bool, id string) {
+	blocking, ok := overrides[id]
+	if !ok || blocking == r.IsBlocking {
+		return
+	}
+	r.IsBlocking = blocking
+	if blocking {
+		r.Details = append(r.Details, "Blocking: on (overridden)")
+	} else {
+		r.Details = append(r.Details, "Blocking: off (overridden)")
+	}
+}
+
 // aggregateResults folds the individual check results into a HealthSummary:
 // the OverallScore is the mean of the *measured* checks (skipped checks are
 // excluded so a missing prerequisite doesn't penalize the score), and the
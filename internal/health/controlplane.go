@@ -16,7 +16,8 @@ import (
 // https://docs.aws.amazon.com/eks/latest/best-practices/known_limits_and_service_quotas.html
 const etcdQuotaBytes = 8 * 1024 * 1024 * 1024
 
-// Control-plane gate thresholds (percent). etcd usage is the blocking signal;
+// Default control-plane gate thresholds (percent), overridable per cluster
+// via Thresholds. etcd usage is the blocking signal;
 // the API-server error rate is advisory (warn-only) so noisy request metrics
 // never wrongly block an upgrade.
 const (
@@ -67,12 +68,12 @@ func (hc *HealthChecker) CheckControlPlaneMetrics(ctx context.Context, clusterNa
 			Message: "control-plane metrics unavailable (no CloudWatch client)",
 		}
 	}
-	return checkControlPlaneMetrics(ctx, hc.cwClient, clusterName)
+	return checkControlPlaneMetrics(ctx, hc.cwClient, clusterName, hc.thresholds())
 }
 
 // checkControlPlaneMetrics is CheckControlPlaneMetrics against any
 // GetMetricData-capable client, so it is testable with a fake.
-func checkControlPlaneMetrics(ctx context.Context, api metricDataAPI, clusterName string) HealthResult {
+func checkControlPlaneMetrics(ctx context.Context, api metricDataAPI, clusterName string, th Thresholds) HealthResult {
 	m, err := fetchControlPlaneMetrics(ctx, api, clusterName)
 	if err != nil {
 		return HealthResult{
@@ -83,7 +84,7 @@ func checkControlPlaneMetrics(ctx context.Context, api metricDataAPI, clusterNam
 			Skipped: true,
 		}
 	}
-	return evaluateControlPlane(m)
+	return evaluateControlPlane(m, th)
 }
 
 // controlPlaneQuery pairs a CloudWatch query id with its AWS/EKS metric name and
@@ -172,7 +173,7 @@ func aggregateValues(id string, values []float64) float64 {
 // evaluateControlPlane turns the aggregated metrics into a gate verdict (pure,
 // table-testable). etcd usage drives Warn/Fail; the API-server error rate is
 // advisory; the scheduler backlog is informational.
-func evaluateControlPlane(m controlPlaneMetrics) HealthResult {
+func evaluateControlPlane(m controlPlaneMetrics, th Thresholds) HealthResult {
 	result := HealthResult{Name: "Control Plane", IsBlocking: true}
 
 	if !m.hasData {
@@ -192,11 +193,11 @@ func evaluateControlPlane(m controlPlaneMetrics) HealthResult {
 		result.Details = append(result.Details,
 			fmt.Sprintf("etcd database %.1f%% of the 8 GiB limit (%.2f GiB in use)", pct, m.etcdInUseBytes/(1024*1024*1024)))
 		switch {
-		case pct >= etcdFailPercent:
+		case pct >= th.EtcdFailPercent:
 			result.Status = StatusFail
 			result.Score = 30
 			result.Message = fmt.Sprintf("etcd database near the 8 GiB read-only limit (%.1f%%) — compact before upgrading", pct)
-		case pct >= etcdWarnPercent:
+		case pct >= th.EtcdWarnPercent:
 			result.Status = StatusWarn
 			result.Score = 70
 			result.Message = fmt.Sprintf("etcd database growing toward the 8 GiB limit (%.1f%%)", pct)
@@ -207,11 +208,11 @@ func evaluateControlPlane(m controlPlaneMetrics) HealthResult {
 
 	// API-server error rate — advisory (warn-only), and only above a volume
 	// floor so a handful of errors on an idle cluster doesn't trip it.
-	if m.reqTotal >= apiserverMinReqsForRate {
+	if m.reqTotal >= th.APIServerMinReqsForRate {
 		errPct := m.req5xx / m.reqTotal * 100
 		result.Details = append(result.Details,
 			fmt.Sprintf("API-server 5xx rate %.2f%% (%d of %d requests)", errPct, int(m.req5xx), int(m.reqTotal)))
-		if errPct >= apiserver5xxWarnPercent && result.Status == StatusPass {
+		if errPct >= th.APIServer5xxWarnPercent && result.Status == StatusPass {
 			result.Status = StatusWarn
 			result.Score = 70
 			result.Message = fmt.Sprintf("Elevated API-server error rate (%.2f%% 5xx)", errPct)
@@ -231,5 +232,7 @@ func evaluateControlPlane(m controlPlaneMetrics) HealthResult {
 		result.Details = append(result.Details, fmt.Sprintf("Scheduler pending pods: %d", int(m.pendingPods)))
 	}
 
+	result.Details = append(result.Details, fmt.Sprintf("Thresholds: etcd warn ≥%s, fail ≥%s; API-server 5xx warn ≥%s above %.0f requests",
+		fmtPct(th.EtcdWarnPercent), fmtPct(th.EtcdFailPercent), fmtPct(th.APIServer5xxWarnPercent), th.APIServerMinReqsForRate))
 	return result
 }
@@ -73,7 +73,7 @@ func TestFetchControlPlaneMetrics_ParsesAndScopes(t *testing.T) {
 // AWS/EKS metrics (e.g. <1.28) is Skipped, not failed.
 func TestCheckControlPlaneMetrics_NoData(t *testing.T) {
 	fake := &fakeMetricsAPI{out: &cloudwatch.GetMetricDataOutput{}}
-	r := checkControlPlaneMetrics(context.Background(), fake, "prod")
+	r := checkControlPlaneMetrics(context.Background(), fake, "prod", DefaultThresholds())
 	if !r.Skipped || r.Status == StatusFail {
 		t.Errorf("no-data check should be skipped/non-fail, got %+v", r)
 	}
@@ -83,7 +83,7 @@ func TestCheckControlPlaneMetrics_NoData(t *testing.T) {
 // upgrade because CloudWatch hiccuped).
 func TestCheckControlPlaneMetrics_FetchError(t *testing.T) {
 	fake := &fakeMetricsAPI{err: errors.New("throttled")}
-	r := checkControlPlaneMetrics(context.Background(), fake, "prod")
+	r := checkControlPlaneMetrics(context.Background(), fake, "prod", DefaultThresholds())
 	if !r.Skipped || r.Status == StatusFail {
 		t.Errorf("fetch error should be skipped/non-fail, got %+v", r)
 	}
@@ -135,7 +135,7 @@ func TestEvaluateControlPlane(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			r := evaluateControlPlane(tc.m)
+			r := evaluateControlPlane(tc.m, DefaultThresholds())
 			if r.Status != tc.wantStatus {
 				t.Errorf("status = %s, want %s (%+v)", r.Status, tc.wantStatus, r)
 			}
@@ -0,0 +1,316 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// safeToEvictAnnotation is the cluster-autoscaler opt-out from eviction.
+const safeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// Drain blocker severities.
+const (
+	// DrainSeverityBlock stalls the drain: the eviction API refuses the pod
+	// until something changes.
+	DrainSeverityBlock = "block"
+	// DrainSeverityWarn drains, but at a cost (downtime, lost data, a pod
+	// that isn't recreated).
+	DrainSeverityWarn = "warn"
+	// DrainSeverityInfo is worth knowing but costs the drain nothing, like a
+	// scratch emptyDir that's expected to go with the pod. It doesn't count
+	// toward warnings.
+	DrainSeverityInfo = "info"
+)
+
+// Drain blocker reasons.
+const (
+	DrainReasonPDB           = "pdb"
+	DrainReasonMultiplePDBs  = "multiple-pdbs"
+	DrainReasonUnmanaged     = "unmanaged"
+	DrainReasonSafeToEvict   = "safe-to-evict"
+	DrainReasonLocalStorage  = "local-storage"
+	DrainReasonSingleReplica = "single-replica"
+)
+
+// DrainBlocker is one pod on a nodegroup's nodes that would stall or hurt a
+// drain, and why.
+type DrainBlocker struct {
+	Severity  string `json:"severity" yaml:"severity"`
+	Reason    string `json:"reason" yaml:"reason"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Pod       string `json:"pod" yaml:"pod"`
+	Node      string `json:"node" yaml:"node"`
+	Workload  string `json:"workload" yaml:"workload"`
+	// PDBs are the budgets covering the pod, for the pdb reasons.
+	PDBs   []string `json:"pdbs,omitempty" yaml:"pdbs,omitempty"`
+	Detail string   `json:"detail" yaml:"detail"`
+}
+
+// DrainReport is the drain blocker analysis of one nodegroup.
+type DrainReport struct {
+	Nodegroup string         `json:"nodegroup" yaml:"nodegroup"`
+	Nodes     []string       `json:"nodes" yaml:"nodes"`
+	Pods      int            `json:"pods" yaml:"pods"`
+	Blockers  []DrainBlocker `json:"blockers" yaml:"blockers"`
+}
+
+// Blocking counts the findings that would stall the drain.
+func (r DrainReport) Blocking() int {
+	n := 0
+	for _, b := range r.Blockers {
+		if b.Severity == DrainSeverityBlock {
+			n++
+		}
+	}
+	return n
+}
+
+// Warnings counts the findings that drain at a cost.
+func (r DrainReport) Warnings() int {
+	n := 0
+	for _, b := range r.Blockers {
+		if b.Severity == DrainSeverityWarn {
+			n++
+		}
+	}
+	return n
+}
+
+// drainInputs is the cluster state the analysis reads.
+type drainInputs struct {
+	nodes       []corev1.Node
+	pods        []corev1.Pod
+	pdbs        []policyv1.PodDisruptionBudget
+	deployments []appsv1.Deployment
+}
+
+func (hc *HealthChecker) loadDrainInputs(ctx context.Context) (drainInputs, error) {
+	var in drainInputs
+	if hc.k8sClient == nil {
+		return in, fmt.Errorf("kubernetes client not available")
+	}
+	nodes, err := hc.k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return in, fmt.Errorf("listing nodes: %w", err)
+	}
+	pods, err := hc.k8sClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		return in, fmt.Errorf("listing pods: %w", err)
+	}
+	pdbs, err := hc.k8sClient.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return in, fmt.Errorf("listing PodDisruptionBudgets: %w", err)
+	}
+	deps, err := hc.k8sClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return in, fmt.Errorf("listing deployments: %w", err)
+	}
+	in.nodes, in.pods, in.pdbs, in.deployments = nodes.Items, pods.Items, pdbs.Items, deps.Items
+	return in, nil
+}
+
+// AnalyzeDrain maps every evictable pod on nodegroup's nodes to the PDBs that
+// cover it and reports what would stall or hurt draining those nodes: PDBs
+// allowing no disruptions, pods covered by more than one PDB, pods without a
+// controller, pods opted out of eviction or using local storage, and
+// single-replica Deployments.
+func (hc *HealthChecker) AnalyzeDrain(ctx context.Context, nodegroup string) (DrainReport, error) {
+	in, err := hc.loadDrainInputs(ctx)
+	if err != nil {
+		return DrainReport{Nodegroup: nodegroup}, err
+	}
+	return analyzeDrain(nodegroup, in), nil
+}
+
+// analyzeDrain is the pure core of AnalyzeDrain.
+func analyzeDrain(nodegroup string, in drainInputs) DrainReport {
+	r := DrainReport{Nodegroup: nodegroup, Nodes: []string{}, Blockers: []DrainBlocker{}}
+	onNodegroup := map[string]bool{}
+	for _, n := range in.nodes {
+		if n.Labels[nodeLabelNodegroup] == nodegroup {
+			onNodegroup[n.Name] = true
+			r.Nodes = append(r.Nodes, n.Name)
+		}
+	}
+	sort.Strings(r.Nodes)
+
+	type budget struct {
+		pdb *policyv1.PodDisruptionBudget
+		sel labels.Selector
+	}
+	budgets := map[string][]budget{}
+	for i := range in.pdbs {
+		pdb := &in.pdbs[i]
+		sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		budgets[pdb.Namespace] = append(budgets[pdb.Namespace], budget{pdb, sel})
+	}
+	replicas := map[string]int32{}
+	for _, d := range in.deployments {
+		n := int32(1)
+		if d.Spec.Replicas != nil {
+			n = *d.Spec.Replicas
+		}
+		replicas[d.Namespace+"/Deployment/"+d.Name] = n
+	}
+
+	for i := range in.pods {
+		p := &in.pods[i]
+		if !onNodegroup[p.Spec.NodeName] || !isDrainedPod(p) {
+			continue
+		}
+		r.Pods++
+		add := func(severity, reason, detail string, pdbs []string) {
+			r.Blockers = append(r.Blockers, DrainBlocker{
+				Severity: severity, Reason: reason,
+				Namespace: p.Namespace, Pod: p.Name, Node: p.Spec.NodeName,
+				Workload: workloadName(p), PDBs: pdbs, Detail: detail,
+			})
+		}
+
+		var covering []*policyv1.PodDisruptionBudget
+		for _, b := range budgets[p.Namespace] {
+			if b.sel.Matches(labels.Set(p.Labels)) {
+				covering = append(covering, b.pdb)
+			}
+		}
+		switch {
+		case len(covering) > 1:
+			names := make([]string, len(covering))
+			for j, pdb := range covering {
+				names[j] = pdb.Name
+			}
+			add(DrainSeverityBlock, DrainReasonMultiplePDBs,
+				fmt.Sprintf("covered by %d PDBs (%s); the eviction API refuses pods with more than one", len(covering), strings.Join(names, ", ")), names)
+		case len(covering) == 1 && covering[0].Status.DisruptionsAllowed <= 0:
+			pdb := covering[0]
+			add(DrainSeverityBlock, DrainReasonPDB,
+				fmt.Sprintf("PDB %s allows 0 disruptions (healthy %d/%d desired)", pdb.Name, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy), []string{pdb.Name})
+		}
+
+		if metav1.GetControllerOf(p) == nil {
+			add(DrainSeverityWarn, DrainReasonUnmanaged, "no controller: evicting deletes it and nothing recreates it", nil)
+		}
+		pinned := p.Annotations[safeToEvictAnnotation] == "false"
+		if pinned {
+			add(DrainSeverityWarn, DrainReasonSafeToEvict, safeToEvictAnnotation+"=false: autoscalers won't drain it, and an EKS roll evicts it regardless", nil)
+		}
+		// Most emptyDir and hostPath volumes are scratch or cache space;
+		// losing them matters when the pod says it mustn't be evicted.
+		if vols := localVolumes(p); len(vols) > 0 {
+			sev := DrainSeverityInfo
+			if pinned {
+				sev = DrainSeverityWarn
+			}
+			add(sev, DrainReasonLocalStorage, fmt.Sprintf("local storage (%s) is lost on eviction", strings.Join(vols, ", ")), nil)
+		}
+		if n, ok := replicas[workloadName(p)]; ok && n == 1 {
+			add(DrainSeverityWarn, DrainReasonSingleReplica, "single-replica Deployment: unavailable until rescheduled", nil)
+		}
+	}
+
+	sort.SliceStable(r.Blockers, func(i, j int) bool {
+		a, b := r.Blockers[i], r.Blockers[j]
+		if a.Severity != b.Severity {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Pod < b.Pod
+	})
+	return r
+}
+
+// severityRank orders findings most severe first.
+var severityRank = map[string]int{DrainSeverityBlock: 0, DrainSeverityWarn: 1, DrainSeverityInfo: 2}
+
+// localVolumes names the pod's emptyDir and hostPath volumes.
+func localVolumes(p *corev1.Pod) []string {
+	var out []string
+	for _, v := range p.Spec.Volumes {
+		switch {
+		case v.EmptyDir != nil:
+			out = append(out, "emptyDir "+v.Name)
+		case v.HostPath != nil:
+			out = append(out, "hostPath "+v.Name)
+		}
+	}
+	return out
+}
+
+// CheckDrainBlockers runs AnalyzeDrain for every nodegroup in the attached
+// drain plans. Pods a PDB would keep from evicting fail the check (EKS gives
+// up on a node after 15 minutes of failed evictions); the other findings warn.
+func (hc *HealthChecker) CheckDrainBlockers(ctx context.Context) HealthResult {
+	r := HealthResult{Name: "Drain Blockers", IsBlocking: true, Status: StatusPass, Score: 100}
+	if hc.k8sClient == nil {
+		r.Skipped = true
+		r.IsBlocking = false
+		r.Message = "Kubernetes client not available, skipping drain blocker analysis"
+		return r
+	}
+	in, err := hc.loadDrainInputs(ctx)
+	if err != nil {
+		r.Skipped = true
+		r.Message = fmt.Sprintf("Unable to analyze drain blockers: %v", err)
+		return r
+	}
+	seen := map[string]bool{}
+	var reports []DrainReport
+	for _, plan := range hc.drainPlans {
+		if seen[plan.Nodegroup] {
+			continue
+		}
+		seen[plan.Nodegroup] = true
+		reports = append(reports, analyzeDrain(plan.Nodegroup, in))
+	}
+	return drainBlockersResult(r, reports)
+}
+
+// drainBlockersResult folds the reports into r.
+func drainBlockersResult(r HealthResult, reports []DrainReport) HealthResult {
+	block, warn, pods := 0, 0, 0
+	var lines []string
+	for _, rep := range reports {
+		pods += rep.Pods
+		for _, b := range rep.Blockers {
+			switch b.Severity {
+			case DrainSeverityBlock:
+				block++
+			case DrainSeverityWarn:
+				warn++
+			default:
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s/%s (%s, %s): %s", b.Namespace, b.Pod, rep.Nodegroup, b.Severity, b.Detail))
+		}
+	}
+	if len(lines) > maxSchedulingDetails {
+		lines = append(lines[:maxSchedulingDetails], fmt.Sprintf("... and %d more (refresh nodegroup drain-check for the full list)", len(lines)-maxSchedulingDetails))
+	}
+	r.Details = lines
+	switch {
+	case block > 0:
+		r.Status = StatusFail
+		r.Score = 20
+		r.Message = fmt.Sprintf("%d pod(s) would stall the drain, %d warning(s)", block, warn)
+	case warn > 0:
+		r.Status = StatusWarn
+		r.Score = 70
+		r.Message = fmt.Sprintf("%d drain warning(s)", warn)
+	default:
+		r.Message = fmt.Sprintf("%d pod(s) across %d nodegroup(s) can be evicted", pods, len(reports))
+	}
+	return r
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func drainPDB(name, app string, allowed int32) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed, CurrentHealthy: 2, DesiredHealthy: 2},
+	}
+}
+
+func drainDeployment(name string, replicas int32) appsv1.Deployment {
+	return appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func TestAnalyzeDrain(t *testing.T) {
+	bare := deployPod("debug", 0, "a", "10m", "16Mi")
+	bare.OwnerReferences = nil
+	scratch := deployPod("cache", 0, "a", "10m", "16Mi")
+	scratch.Spec.Volumes = []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	pinned := deployPod("etl", 0, "a", "10m", "16Mi")
+	pinned.Annotations = map[string]string{safeToEvictAnnotation: "false"}
+	pinned.Spec.Volumes = []corev1.Volume{{Name: "spool", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/spool/etl"}}}}
+	ctrl := true
+	ds := deployPod("fluent-bit", 0, "a", "10m", "16Mi")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "fluent-bit", Controller: &ctrl}}
+
+	in := drainInputs{
+		nodes: []corev1.Node{simNodeFixture("a", "workers", "4", "8Gi"), simNodeFixture("b", "system", "4", "8Gi")},
+		pods: []corev1.Pod{
+			deployPod("web", 0, "a", "10m", "16Mi"),
+			deployPod("web", 1, "b", "10m", "16Mi"), // other nodegroup: ignored
+			deployPod("api", 0, "a", "10m", "16Mi"),
+			bare, scratch, pinned, ds,
+		},
+		pdbs: []policyv1.PodDisruptionBudget{
+			drainPDB("web-pdb", "web", 0),
+			drainPDB("api-pdb", "api", 1),
+			drainPDB("api-pdb-2", "api", 1),
+			drainPDB("cache-pdb", "cache", 1),
+		},
+		deployments: []appsv1.Deployment{drainDeployment("web", 2), drainDeployment("etl", 1)},
+	}
+	r := analyzeDrain("workers", in)
+
+	if r.Pods != 5 || strings.Join(r.Nodes, ",") != "a" {
+		t.Errorf("pods = %d, nodes = %v; want the 5 evictable pods on node a", r.Pods, r.Nodes)
+	}
+	got := map[string]string{}
+	for _, b := range r.Blockers {
+		got[b.Pod+"/"+b.Reason] = b.Severity
+	}
+	want := map[string]string{
+		"web-7d9f-a/" + DrainReasonPDB:            DrainSeverityBlock,
+		"api-7d9f-a/" + DrainReasonMultiplePDBs:   DrainSeverityBlock,
+		"debug-7d9f-a/" + DrainReasonUnmanaged:    DrainSeverityWarn,
+		"cache-7d9f-a/" + DrainReasonLocalStorage: DrainSeverityInfo, // scratch space
+		"etl-7d9f-a/" + DrainReasonSafeToEvict:    DrainSeverityWarn,
+		"etl-7d9f-a/" + DrainReasonLocalStorage:   DrainSeverityWarn, // and it asked not to be evicted
+		"etl-7d9f-a/" + DrainReasonSingleReplica:  DrainSeverityWarn,
+	}
+	if len(got) != len(want) {
+		t.Errorf("blockers = %v, want %v", got, want)
+	}
+	for k, sev := range want {
+		if got[k] != sev {
+			t.Errorf("%s: severity %q, want %q", k, got[k], sev)
+		}
+	}
+	if r.Blocking() != 2 || r.Blockers[0].Severity != DrainSeverityBlock || r.Blockers[1].Severity != DrainSeverityBlock {
+		t.Errorf("blocking findings should sort first: %+v", r.Blockers)
+	}
+	if r.Warnings() != 4 || r.Blockers[len(r.Blockers)-1].Severity != DrainSeverityInfo {
+		t.Errorf("want 4 warnings and the info note last: %+v", r.Blockers)
+	}
+	if r.Blockers[0].Workload != "default/Deployment/api" || !strings.Contains(r.Blockers[0].Detail, "api-pdb, api-pdb-2") {
+		t.Errorf("first blocker = %+v", r.Blockers[0])
+	}
+}
+
+func TestCheckDrainBlockers_FailsOnStalledPDB(t *testing.T) {
+	node := simNodeFixture("a", "workers", "4", "8Gi")
+	pod := deployPod("web", 0, "a", "10m", "16Mi")
+	pdb := drainPDB("web-pdb", "web", 0)
+	hc := NewChecker(nil, fake.NewClientset(&node, &pod, &pdb), nil, nil)
+	hc.SetDrainPlans([]DrainPlan{roll("workers", 1)})
+
+	r := hc.CheckDrainBlockers(context.Background())
+	if r.Status != StatusFail || !r.IsBlocking {
+		t.Fatalf("status = %s (%s), want a blocking FAIL", r.Status, r.Message)
+	}
+	if len(r.Details) != 1 || !strings.Contains(r.Details[0], "default/web-7d9f-a (workers, block): PDB web-pdb allows 0 disruptions") {
+		t.Errorf("details = %v", r.Details)
+	}
+}
+
+func TestDrainBlockersResult_InfoDoesNotWarn(t *testing.T) {
+	rep := DrainReport{Nodegroup: "workers", Pods: 3, Blockers: []DrainBlocker{
+		{Severity: DrainSeverityInfo, Reason: DrainReasonLocalStorage, Namespace: "default", Pod: "cache-0", Detail: "local storage (emptyDir tmp) is lost on eviction"},
+	}}
+	r := drainBlockersResult(HealthResult{Status: StatusPass}, []DrainReport{rep})
+	if r.Status != StatusPass || len(r.Details) != 0 {
+		t.Errorf("status = %s, details = %v; a scratch volume shouldn't warn", r.Status, r.Details)
+	}
+}
@@ -22,7 +22,7 @@ const kubeProbeTimeout = 5 * time.Second
 // KubeDiag describes how the Kubernetes client was (or would be) resolved, so
 // callers can emit an actionable message when the API can't be reached.
 type KubeDiag struct {
-	Source  string // "--kubeconfig", "KUBECONFIG", "default", "in-cluster", "none"
+	Source  string // "--kubeconfig", "KUBECONFIG", "default", "in-cluster", "sandbox", "none"
 	Path    string
 	Context string
 }
@@ -32,6 +32,8 @@ func (d KubeDiag) String() string {
 	switch {
 	case d.Source == "in-cluster":
 		return "in-cluster service account"
+	case d.Source == "sandbox":
+		return fmt.Sprintf("sandbox fixture %s (cluster %q)", d.Path, d.Context)
 	case d.Path != "":
 		ctx := d.Context
 		if ctx == "" {
@@ -43,12 +45,36 @@ func (d KubeDiag) String() string {
 	}
 }
 
+// kubeDisabled, when set, is why Kubernetes access is off for the process.
+var kubeDisabled string
+
+// DisableKubernetes turns off Kubernetes access for the process: clients fail
+// to resolve with reason, and callers degrade as if no kubeconfig existed.
+// Sandbox mode uses it so simulated clusters never reach a real API server.
+func DisableKubernetes(reason string) { kubeDisabled = reason }
+
+// kubeOverride, when set, is the client BuildKubeClient returns.
+var (
+	kubeOverride     kubernetes.Interface
+	kubeOverrideDiag KubeDiag
+)
+
+// UseKubeClient makes BuildKubeClient return client, described by diag,
+// whatever kubeconfig is asked for; nil restores normal resolution. Sandbox
+// mode serves a fixture's objects through it.
+func UseKubeClient(client kubernetes.Interface, diag KubeDiag) {
+	kubeOverride, kubeOverrideDiag = client, diag
+}
+
 // resolveRESTConfig resolves a *rest.Config and a diagnostic, preferring an
 // explicit kubeconfig path, then $KUBECONFIG, then ~/.kube/config, then
 // in-cluster config. An explicit --kubeconfig path that doesn't exist is a hard
 // error. Shared by BuildKubeClient and BuildMetricsClient so both clients
 // resolve identically.
 func resolveRESTConfig(kubeconfigPath string) (*rest.Config, KubeDiag, error) {
+	if kubeDisabled != "" {
+		return nil, KubeDiag{Source: "none"}, fmt.Errorf("kubernetes access disabled: %s", kubeDisabled)
+	}
 	source := ""
 	path := strings.TrimSpace(kubeconfigPath)
 	switch {
@@ -94,6 +120,9 @@ func resolveRESTConfig(kubeconfigPath string) (*rest.Config, KubeDiag, error) {
 // returns a KubeDiag describing what was tried (for diagnostics) alongside the
 // client. An explicit --kubeconfig path that doesn't exist is a hard error.
 func BuildKubeClient(kubeconfigPath string) (kubernetes.Interface, KubeDiag, error) {
+	if kubeOverride != nil {
+		return kubeOverride, kubeOverrideDiag, nil
+	}
 	cfg, diag, err := resolveRESTConfig(kubeconfigPath)
 	if err != nil {
 		return nil, diag, err
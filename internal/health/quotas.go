@@ -41,11 +41,11 @@ func (hc *HealthChecker) CheckServiceQuotas(ctx context.Context, _ string) Healt
 		return HealthResult{Name: "Service Quotas", Status: StatusPass, Skipped: true,
 			Message: "service-quota headroom unavailable (clients not configured)"}
 	}
-	return checkServiceQuotas(ctx, hc.sqClient, hc.cwClient)
+	return checkServiceQuotas(ctx, hc.sqClient, hc.cwClient, hc.thresholds())
 }
 
 // checkServiceQuotas is CheckServiceQuotas against injectable clients (testable).
-func checkServiceQuotas(ctx context.Context, sq serviceQuotaAPI, md metricDataAPI) HealthResult {
+func checkServiceQuotas(ctx context.Context, sq serviceQuotaAPI, md metricDataAPI, th Thresholds) HealthResult {
 	limit, err := onDemandVCPULimit(ctx, sq)
 	if err != nil {
 		return HealthResult{Name: "Service Quotas", Status: StatusPass, Skipped: true,
@@ -56,7 +56,7 @@ func checkServiceQuotas(ctx context.Context, sq serviceQuotaAPI, md metricDataAP
 		return HealthResult{Name: "Service Quotas", Status: StatusPass, Skipped: true,
 			Message: "service-quota usage unavailable (AWS/Usage metric not reported)"}
 	}
-	return evaluateQuota(usage, limit)
+	return evaluateQuota(usage, limit, th)
 }
 
 func onDemandVCPULimit(ctx context.Context, sq serviceQuotaAPI) (float64, error) {
@@ -109,7 +109,7 @@ func onDemandVCPUUsage(ctx context.Context, md metricDataAPI) (usage float64, ok
 }
 
 // evaluateQuota turns usage vs limit into an advisory verdict (pure, testable).
-func evaluateQuota(usage, limit float64) HealthResult {
+func evaluateQuota(usage, limit float64, th Thresholds) HealthResult {
 	r := HealthResult{Name: "Service Quotas", IsBlocking: false, Status: StatusPass, Score: 100}
 	if limit <= 0 {
 		r.Skipped = true
@@ -119,16 +119,17 @@ func evaluateQuota(usage, limit float64) HealthResult {
 	pct := usage / limit * 100
 	r.Details = append(r.Details, fmt.Sprintf("EC2 On-Demand Standard vCPUs: %.0f of %.0f used (%.1f%%, %.0f free)", usage, limit, pct, limit-usage))
 	switch {
-	case pct >= quotaHighPercent:
+	case pct >= th.QuotaHighPercent:
 		r.Status = StatusFail
 		r.Score = 40
 		r.Message = fmt.Sprintf("EC2 vCPU quota nearly exhausted (%.1f%%) — a scale-up or roll may fail to launch nodes", pct)
-	case pct >= quotaWarnPercent:
+	case pct >= th.QuotaWarnPercent:
 		r.Status = StatusWarn
 		r.Score = 70
 		r.Message = fmt.Sprintf("EC2 vCPU quota usage high (%.1f%%) — limited headroom for new nodes", pct)
 	default:
 		r.Message = fmt.Sprintf("EC2 vCPU quota headroom healthy (%.1f%% used)", pct)
 	}
+	r.Details = append(r.Details, fmt.Sprintf("Thresholds: warn ≥%s, fail ≥%s of the quota", fmtPct(th.QuotaWarnPercent), fmtPct(th.QuotaHighPercent)))
 	return r
 }
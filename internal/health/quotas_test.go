@@ -56,7 +56,7 @@ func TestEvaluateQuota(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			r := evaluateQuota(tc.usage, tc.limit)
+			r := evaluateQuota(tc.usage, tc.limit, DefaultThresholds())
 			if r.Status != tc.wantStatus || r.Skipped != tc.wantSkipped {
 				t.Errorf("got status=%s skipped=%v, want status=%s skipped=%v (%+v)", r.Status, r.Skipped, tc.wantStatus, tc.wantSkipped, r)
 			}
@@ -71,7 +71,7 @@ func TestCheckServiceQuotas_FetchAndEvaluate(t *testing.T) {
 	sq := &fakeServiceQuotas{value: aws.Float64(1000)}
 	md := &usageMetrics{value: 970, hasData: true} // 97% → near-exhausted → fail (advisory)
 
-	r := checkServiceQuotas(context.Background(), sq, md)
+	r := checkServiceQuotas(context.Background(), sq, md, DefaultThresholds())
 	if r.Skipped {
 		t.Fatalf("expected a measured result, got skipped: %+v", r)
 	}
@@ -85,11 +85,11 @@ func TestCheckServiceQuotas_FetchAndEvaluate(t *testing.T) {
 
 func TestCheckServiceQuotas_SkipsGracefully(t *testing.T) {
 	// Quota fetch error → skip.
-	if r := checkServiceQuotas(context.Background(), &fakeServiceQuotas{err: errors.New("AccessDenied")}, &usageMetrics{value: 1, hasData: true}); !r.Skipped {
+	if r := checkServiceQuotas(context.Background(), &fakeServiceQuotas{err: errors.New("AccessDenied")}, &usageMetrics{value: 1, hasData: true}, DefaultThresholds()); !r.Skipped {
 		t.Errorf("quota error should skip, got %+v", r)
 	}
 	// Usage metric absent (AWS/Usage not reported) → skip, not fail.
-	if r := checkServiceQuotas(context.Background(), &fakeServiceQuotas{value: aws.Float64(1000)}, &usageMetrics{hasData: false}); !r.Skipped || r.Status == StatusFail {
+	if r := checkServiceQuotas(context.Background(), &fakeServiceQuotas{value: aws.Float64(1000)}, &usageMetrics{hasData: false}, DefaultThresholds()); !r.Skipped || r.Status == StatusFail {
 		t.Errorf("missing usage should skip (not fail), got %+v", r)
 	}
 }
@@ -0,0 +1,757 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxSchedulingDetails caps the unschedulable-workload lines in the result.
+const maxSchedulingDetails = 10
+
+// DrainPlan describes the nodes a nodegroup operation takes away, so the
+// scheduling check can replay it against the live pods.
+type DrainPlan struct {
+	Nodegroup string
+	// BatchSize nodes drain at the same time (a roll's maxUnavailable).
+	BatchSize int
+	// Replace launches a fresh node of the same shape for every drained node
+	// before it drains (a roll). False removes the nodes for good (scale-down).
+	Replace bool
+	// Count limits how many of the nodegroup's nodes drain; 0 means all.
+	Count int
+}
+
+// RollDrainPlan is the plan for a rolling update of ng: EKS drains up to
+// maxUnavailable nodes at a time (1 unless the update config says otherwise)
+// and surges a replacement for each.
+func RollDrainPlan(ng *ekstypes.Nodegroup) DrainPlan {
+	p := DrainPlan{BatchSize: 1, Replace: true}
+	if ng == nil {
+		return p
+	}
+	if ng.NodegroupName != nil {
+		p.Nodegroup = *ng.NodegroupName
+	}
+	if uc := ng.UpdateConfig; uc != nil {
+		switch {
+		case uc.MaxUnavailable != nil && *uc.MaxUnavailable > 0:
+			p.BatchSize = int(*uc.MaxUnavailable)
+		case uc.MaxUnavailablePercentage != nil && ng.ScalingConfig != nil && ng.ScalingConfig.DesiredSize != nil:
+			n := (int(*uc.MaxUnavailablePercentage)*int(*ng.ScalingConfig.DesiredSize) + 99) / 100
+			p.BatchSize = max(n, 1)
+		}
+	}
+	return p
+}
+
+// ScaleDownDrainPlan is the plan for shrinking a nodegroup by remove nodes.
+// The ASG picks which instances go, so the simulation assumes the worst case:
+// the most-requested nodes.
+func ScaleDownDrainPlan(nodegroup string, remove int) DrainPlan {
+	return DrainPlan{Nodegroup: nodegroup, BatchSize: remove, Count: remove}
+}
+
+// SetDrainPlans attaches the nodegroup drains the next RunAllChecks should
+// simulate. With plans set, RunAllChecks includes the blocking "scheduling"
+// check; nil removes it again.
+func (hc *HealthChecker) SetDrainPlans(plans []DrainPlan) { hc.drainPlans = plans }
+
+// CheckScheduling simulates the attached drain plans against the live nodes
+// and pods: each batch's evictable pods are bin-packed by their requests onto
+// the remaining schedulable nodes (plus any replacement nodes), honoring node
+// selectors, required node and pod (anti-)affinity, taints/tolerations and
+// DoNotSchedule topology spread. Any pod that fits nowhere fails the check.
+func (hc *HealthChecker) CheckScheduling(ctx context.Context) HealthResult {
+	r := HealthResult{Name: "Pod Scheduling", IsBlocking: true, Status: StatusPass}
+	if hc.k8sClient == nil {
+		r.Skipped = true
+		r.IsBlocking = false
+		r.Message = "Kubernetes client not available, skipping scheduling simulation"
+		return r
+	}
+	if len(hc.drainPlans) == 0 {
+		r.Skipped = true
+		r.Message = "no nodegroup drain to simulate"
+		return r
+	}
+	nodes, err := hc.k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		r.Skipped = true
+		r.Message = fmt.Sprintf("Unable to list nodes for the scheduling simulation: %v", err)
+		return r
+	}
+	pods, err := hc.k8sClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		r.Skipped = true
+		r.Message = fmt.Sprintf("Unable to list pods for the scheduling simulation: %v", err)
+		return r
+	}
+	return simulateDrains(nodes.Items, pods.Items, hc.drainPlans)
+}
+
+// simNode is a node's remaining capacity and placed pods during a simulation.
+type simNode struct {
+	name        string
+	labels      map[string]string
+	taints      []corev1.Taint
+	schedulable bool
+	freeCPU     int64 // millicores
+	freeMem     int64 // bytes
+	freePods    int64
+	pods        []*corev1.Pod
+}
+
+func newSimNode(n *corev1.Node) *simNode {
+	alloc := n.Status.Allocatable
+	return &simNode{
+		name:        n.Name,
+		labels:      n.Labels,
+		taints:      n.Spec.Taints,
+		schedulable: !n.Spec.Unschedulable && nodeReady(n),
+		freeCPU:     alloc.Cpu().MilliValue(),
+		freeMem:     alloc.Memory().Value(),
+		freePods:    alloc.Pods().Value(),
+	}
+}
+
+func (n *simNode) place(p *corev1.Pod) {
+	cpu, mem := podRequests(p)
+	n.freeCPU -= cpu
+	n.freeMem -= mem
+	n.freePods--
+	n.pods = append(n.pods, p)
+}
+
+// requestedCPU sums the CPU requests placed on the node, used to order nodes
+// for a worst-case scale-down.
+func (n *simNode) requestedCPU() int64 {
+	var total int64
+	for _, p := range n.pods {
+		cpu, _ := podRequests(p)
+		total += cpu
+	}
+	return total
+}
+
+// simCluster is the simulated cluster state.
+type simCluster struct {
+	nodes []*simNode
+}
+
+func (c *simCluster) remove(name string) {
+	for i, n := range c.nodes {
+		if n.name == name {
+			c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+// unplaced records a pod that fit nowhere and why.
+type unplaced struct {
+	pod    *corev1.Pod
+	reason string
+}
+
+// simulateDrains replays plans against nodes and pods (pure, table-testable).
+// Plans advance in lockstep: step i drains batch i of every plan at once, as
+// concurrent nodegroup updates would.
+func simulateDrains(nodes []corev1.Node, pods []corev1.Pod, plans []DrainPlan) HealthResult {
+	r := HealthResult{Name: "Pod Scheduling", IsBlocking: true, Status: StatusPass, Score: 100}
+
+	c := &simCluster{}
+	byName := map[string]*simNode{}
+	template := map[string]*corev1.Node{}
+	for i := range nodes {
+		sn := newSimNode(&nodes[i])
+		c.nodes = append(c.nodes, sn)
+		byName[sn.name] = sn
+		if ng := nodes[i].Labels[nodeLabelNodegroup]; ng != "" && template[ng] == nil {
+			template[ng] = &nodes[i]
+		}
+	}
+	for i := range pods {
+		p := &pods[i]
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if n := byName[p.Spec.NodeName]; n != nil {
+			n.place(p)
+		}
+	}
+
+	// Batches per plan, in drain order.
+	var batches [][][]string
+	var described []string
+	steps, draining, surge := 0, 0, 0
+	for _, plan := range plans {
+		members := nodegroupNodes(c, plan.Nodegroup, plan.Replace)
+		if plan.Count > 0 && plan.Count < len(members) {
+			members = members[:plan.Count]
+		}
+		size := max(plan.BatchSize, 1)
+		var b [][]string
+		for i := 0; i < len(members); i += size {
+			b = append(b, members[i:min(i+size, len(members))])
+		}
+		batches = append(batches, b)
+		steps = max(steps, len(b))
+		draining += len(members)
+		if plan.Replace {
+			surge += len(members)
+			described = append(described, fmt.Sprintf("%s: roll %d node(s), %d at a time, each replaced", plan.Nodegroup, len(members), size))
+		} else {
+			described = append(described, fmt.Sprintf("%s: remove %d node(s)", plan.Nodegroup, len(members)))
+		}
+	}
+	if draining == 0 {
+		r.Skipped = true
+		r.Message = "no nodes found for the nodegroup(s) to simulate"
+		return r
+	}
+	r.Details = append(r.Details, described...)
+
+	var failed []unplaced
+	evicted, bare := 0, 0
+	for step := 0; step < steps; step++ {
+		var drain []string
+		for pi, b := range batches {
+			if step >= len(b) {
+				continue
+			}
+			drain = append(drain, b[step]...)
+			if plans[pi].Replace {
+				// EKS launches the replacements before it drains.
+				for _, name := range b[step] {
+					c.nodes = append(c.nodes, surgeNode(template[plans[pi].Nodegroup], name, pods))
+				}
+			}
+		}
+		var pending []*corev1.Pod
+		for _, name := range drain {
+			n := byName[name]
+			for _, p := range n.pods {
+				switch {
+				case !isDrainedPod(p):
+				case metav1.GetControllerOf(p) == nil:
+					bare++ // evicted, but nothing recreates it
+				default:
+					pending = append(pending, p)
+				}
+			}
+			c.remove(name)
+		}
+		evicted += len(pending)
+		sortByRequests(pending)
+		for _, p := range pending {
+			if n, reason := c.bestFit(p); n != nil {
+				n.place(p)
+			} else {
+				failed = append(failed, unplaced{pod: p, reason: reason})
+			}
+		}
+	}
+
+	r.Details = append(r.Details, fmt.Sprintf("Simulated %d node drain(s) in %d step(s) with %d replacement node(s); %d pod(s) to reschedule", draining, steps, surge, evicted))
+	if bare > 0 {
+		r.Details = append(r.Details, fmt.Sprintf("%d pod(s) without a controller would be deleted, not rescheduled", bare))
+	}
+	if len(failed) == 0 {
+		r.Message = fmt.Sprintf("All %d evicted pod(s) fit on the remaining capacity", evicted)
+		return r
+	}
+
+	r.Status = StatusFail
+	r.Score = 20
+	lines := summarizeUnplaced(failed)
+	r.Message = fmt.Sprintf("%d pod(s) from %d workload(s) would be unschedulable during the drain", len(failed), len(lines))
+	if len(lines) > maxSchedulingDetails {
+		lines = append(lines[:maxSchedulingDetails], fmt.Sprintf("… and %d more workload(s)", len(lines)-maxSchedulingDetails))
+	}
+	r.Details = append(r.Details, lines...)
+	return r
+}
+
+// nodegroupNodes lists the nodegroup's node names in drain order: by name for
+// a roll, most-requested first for a scale-down (worst case).
+func nodegroupNodes(c *simCluster, nodegroup string, roll bool) []string {
+	var members []*simNode
+	for _, n := range c.nodes {
+		if n.labels[nodeLabelNodegroup] == nodegroup {
+			members = append(members, n)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if !roll {
+			if a, b := members[i].requestedCPU(), members[j].requestedCPU(); a != b {
+				return a > b
+			}
+		}
+		return members[i].name < members[j].name
+	})
+	names := make([]string, len(members))
+	for i, n := range members {
+		names[i] = n.name
+	}
+	return names
+}
+
+// surgeNode is a fresh replacement for the drained node name, shaped like the
+// nodegroup's template node and already carrying its DaemonSet pods.
+func surgeNode(tmpl *corev1.Node, drained string, pods []corev1.Pod) *simNode {
+	name := "replacement-for-" + drained
+	if tmpl == nil {
+		return &simNode{name: name}
+	}
+	n := newSimNode(tmpl)
+	n.name = name
+	n.schedulable = true
+	n.labels = make(map[string]string, len(tmpl.Labels))
+	for k, v := range tmpl.Labels {
+		n.labels[k] = v
+	}
+	n.labels[corev1.LabelHostname] = name
+	n.taints = nil
+	for _, t := range tmpl.Spec.Taints {
+		if t.Key != corev1.TaintNodeUnschedulable {
+			n.taints = append(n.taints, t)
+		}
+	}
+	for i := range pods {
+		if pods[i].Spec.NodeName == tmpl.Name && !isDrainedPod(&pods[i]) {
+			n.place(&pods[i])
+		}
+	}
+	return n
+}
+
+// isDrainedPod reports whether a drain evicts p: DaemonSet and static/mirror
+// pods stay, terminal pods are already gone.
+func isDrainedPod(p *corev1.Pod) bool {
+	if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, mirror := p.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	if ref := metav1.GetControllerOf(p); ref != nil && ref.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
+
+// podRequests is the pod's effective CPU (millicores) and memory (bytes)
+// request: the larger of the containers' sum and any init container, plus
+// overhead.
+func podRequests(p *corev1.Pod) (cpu, mem int64) {
+	for _, c := range p.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		mem += c.Resources.Requests.Memory().Value()
+	}
+	for _, c := range p.Spec.InitContainers {
+		cpu = max(cpu, c.Resources.Requests.Cpu().MilliValue())
+		mem = max(mem, c.Resources.Requests.Memory().Value())
+	}
+	if p.Spec.Overhead != nil {
+		cpu += p.Spec.Overhead.Cpu().MilliValue()
+		mem += p.Spec.Overhead.Memory().Value()
+	}
+	return cpu, mem
+}
+
+// sortByRequests orders pods largest-first, so big pods claim space before
+// small ones fragment it.
+func sortByRequests(pods []*corev1.Pod) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		ci, mi := podRequests(pods[i])
+		cj, mj := podRequests(pods[j])
+		if ci != cj {
+			return ci > cj
+		}
+		return mi > mj
+	})
+}
+
+// bestFit returns the feasible node p leaves the least room on, or the
+// reason no node fits (the most common failing predicate, scheduler-style).
+func (c *simCluster) bestFit(p *corev1.Pod) (*simNode, string) {
+	cpu, mem := podRequests(p)
+	spread := c.spreadCounts(p)
+	var best *simNode
+	var bestScore int64
+	reasons := map[string]int{}
+	for _, n := range c.nodes {
+		if reason := c.fits(p, n, cpu, mem, spread); reason != "" {
+			reasons[reason]++
+			continue
+		}
+		score := (n.freeCPU - cpu) + (n.freeMem-mem)>>20 // millicores + MiB
+		if best == nil || score < bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil {
+		return best, ""
+	}
+	return nil, topReason(reasons, len(c.nodes))
+}
+
+// fits returns "" when p can land on n, else the failing predicate.
+func (c *simCluster) fits(p *corev1.Pod, n *simNode, cpu, mem int64, spread []spreadState) string {
+	switch {
+	case !n.schedulable:
+		return "node(s) were unschedulable"
+	case !matchesNodeSelector(p, n.labels):
+		return "node(s) didn't match node selector/affinity"
+	case !toleratesAll(p.Spec.Tolerations, n.taints):
+		return "node(s) had untolerated taint"
+	case n.freePods < 1:
+		return "Too many pods"
+	case n.freeCPU < cpu:
+		return "Insufficient cpu"
+	case n.freeMem < mem:
+		return "Insufficient memory"
+	case !c.podAffinityOK(p, n):
+		return "node(s) didn't satisfy pod affinity/anti-affinity"
+	}
+	for _, s := range spread {
+		if !s.allows(n) {
+			return "node(s) didn't satisfy topology spread constraints"
+		}
+	}
+	return ""
+}
+
+func topReason(reasons map[string]int, nodes int) string {
+	if nodes == 0 {
+		return "no nodes left"
+	}
+	type kv struct {
+		reason string
+		count  int
+	}
+	var all []kv
+	for r, n := range reasons {
+		all = append(all, kv{r, n})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].count != all[j].count {
+			return all[i].count > all[j].count
+		}
+		return all[i].reason < all[j].reason
+	})
+	parts := make([]string, len(all))
+	for i, r := range all {
+		parts[i] = fmt.Sprintf("%d %s", r.count, r.reason)
+	}
+	return fmt.Sprintf("0/%d nodes: %s", nodes, strings.Join(parts, ", "))
+}
+
+// matchesNodeSelector checks nodeSelector and required node affinity.
+func matchesNodeSelector(p *corev1.Pod, nodeLabels map[string]string) bool {
+	for k, v := range p.Spec.NodeSelector {
+		if nodeLabels[k] != v {
+			return false
+		}
+	}
+	aff := p.Spec.Affinity
+	if aff == nil || aff.NodeAffinity == nil || aff.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := aff.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for _, term := range terms {
+		if matchesNodeSelectorTerm(term, nodeLabels) {
+			return true
+		}
+	}
+	return len(terms) == 0
+}
+
+func matchesNodeSelectorTerm(term corev1.NodeSelectorTerm, nodeLabels map[string]string) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false // an empty term matches nothing
+	}
+	for _, req := range term.MatchExpressions {
+		if !matchesRequirement(req, nodeLabels) {
+			return false
+		}
+	}
+	for _, req := range term.MatchFields {
+		// metadata.name is the only supported field; the hostname label
+		// carries the same value for a simulated node.
+		if req.Key == "metadata.name" && !matchesRequirement(corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: req.Operator, Values: req.Values}, nodeLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesRequirement(req corev1.NodeSelectorRequirement, nodeLabels map[string]string) bool {
+	v, has := nodeLabels[req.Key]
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return has && contains(req.Values, v)
+	case corev1.NodeSelectorOpNotIn:
+		return !has || !contains(req.Values, v)
+	case corev1.NodeSelectorOpExists:
+		return has
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !has
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !has || len(req.Values) != 1 {
+			return false
+		}
+		got, err1 := strconv.ParseInt(v, 10, 64)
+		want, err2 := strconv.ParseInt(req.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if req.Operator == corev1.NodeSelectorOpGt {
+			return got > want
+		}
+		return got < want
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// toleratesAll reports whether every NoSchedule/NoExecute taint is tolerated.
+func toleratesAll(tols []corev1.Toleration, taints []corev1.Taint) bool {
+	for _, taint := range taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		ok := false
+		for _, t := range tols {
+			if tolerates(t, taint) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tolerates(t corev1.Toleration, taint corev1.Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+	switch t.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case corev1.TolerationOpEqual, "":
+		return t.Key != "" && t.Value == taint.Value
+	}
+	return false
+}
+
+// termMatches reports whether pod q is selected by an affinity term declared
+// on a pod in namespace ns.
+func termMatches(term corev1.PodAffinityTerm, ns string, q *corev1.Pod) bool {
+	if term.NamespaceSelector == nil {
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{ns}
+		}
+		if !contains(namespaces, q.Namespace) {
+			return false
+		}
+	}
+	sel, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil || term.LabelSelector == nil {
+		return false
+	}
+	return sel.Matches(labels.Set(q.Labels))
+}
+
+// podsInDomain reports whether fn holds for any pod placed on a node sharing
+// n's value for key. A node without the key is in no domain.
+func (c *simCluster) podsInDomain(key string, n *simNode, fn func(*corev1.Pod) bool) bool {
+	val, ok := n.labels[key]
+	if !ok {
+		return false
+	}
+	for _, m := range c.nodes {
+		if m.labels[key] != val {
+			continue
+		}
+		for _, q := range m.pods {
+			if fn(q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// podAffinityOK checks required pod affinity and anti-affinity for p on n,
+// including existing pods' anti-affinity against p.
+func (c *simCluster) podAffinityOK(p *corev1.Pod, n *simNode) bool {
+	if aff := p.Spec.Affinity; aff != nil {
+		if pa := aff.PodAntiAffinity; pa != nil {
+			for _, term := range pa.RequiredDuringSchedulingIgnoredDuringExecution {
+				if c.podsInDomain(term.TopologyKey, n, func(q *corev1.Pod) bool { return termMatches(term, p.Namespace, q) }) {
+					return false
+				}
+			}
+		}
+		if pa := aff.PodAffinity; pa != nil {
+			for _, term := range pa.RequiredDuringSchedulingIgnoredDuringExecution {
+				if _, ok := n.labels[term.TopologyKey]; !ok {
+					return false
+				}
+				found := c.podsInDomain(term.TopologyKey, n, func(q *corev1.Pod) bool { return termMatches(term, p.Namespace, q) })
+				// As in the scheduler, a pod whose affinity matches only itself
+				// may start the group anywhere.
+				if !found && !(termMatches(term, p.Namespace, p) && !c.anyPodMatches(term, p.Namespace)) {
+					return false
+				}
+			}
+		}
+	}
+	// Symmetry: an existing pod's anti-affinity also keeps p out.
+	for _, m := range c.nodes {
+		for _, q := range m.pods {
+			if q.Spec.Affinity == nil || q.Spec.Affinity.PodAntiAffinity == nil {
+				continue
+			}
+			for _, term := range q.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				if v, ok := m.labels[term.TopologyKey]; ok && n.labels[term.TopologyKey] == v && termMatches(term, q.Namespace, p) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (c *simCluster) anyPodMatches(term corev1.PodAffinityTerm, ns string) bool {
+	for _, m := range c.nodes {
+		for _, q := range m.pods {
+			if termMatches(term, ns, q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spreadState is one DoNotSchedule topology spread constraint evaluated for a
+// pod: matching pods per domain, and the least-loaded domain's count.
+type spreadState struct {
+	key     string
+	maxSkew int32
+	counts  map[string]int
+	min     int
+}
+
+func (s spreadState) allows(n *simNode) bool {
+	v, ok := n.labels[s.key]
+	if !ok {
+		return false
+	}
+	return int32(s.counts[v]+1-s.min) <= s.maxSkew
+}
+
+// spreadCounts evaluates p's hard topology spread constraints. As in the
+// scheduler, domains come from every node matching p's node selector and
+// affinity, cordoned ones included.
+func (c *simCluster) spreadCounts(p *corev1.Pod) []spreadState {
+	var out []spreadState
+	for _, tsc := range p.Spec.TopologySpreadConstraints {
+		if tsc.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(tsc.LabelSelector)
+		if err != nil {
+			continue
+		}
+		s := spreadState{key: tsc.TopologyKey, maxSkew: tsc.MaxSkew, counts: map[string]int{}}
+		for _, n := range c.nodes {
+			v, ok := n.labels[tsc.TopologyKey]
+			if !ok || !matchesNodeSelector(p, n.labels) {
+				continue
+			}
+			if _, seen := s.counts[v]; !seen {
+				s.counts[v] = 0
+			}
+			for _, q := range n.pods {
+				if q.Namespace == p.Namespace && sel.Matches(labels.Set(q.Labels)) {
+					s.counts[v]++
+				}
+			}
+		}
+		first := true
+		for _, cnt := range s.counts {
+			if first || cnt < s.min {
+				s.min, first = cnt, false
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// summarizeUnplaced groups unschedulable pods by workload, largest first.
+func summarizeUnplaced(failed []unplaced) []string {
+	type group struct {
+		name   string
+		count  int
+		reason string
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, f := range failed {
+		name := workloadName(f.pod)
+		g := groups[name]
+		if g == nil {
+			g = &group{name: name, reason: f.reason}
+			groups[name] = g
+			order = append(order, name)
+		}
+		g.count++
+	}
+	sort.SliceStable(order, func(i, j int) bool { return groups[order[i]].count > groups[order[j]].count })
+	lines := make([]string, 0, len(order))
+	for _, name := range order {
+		g := groups[name]
+		lines = append(lines, fmt.Sprintf("%s: %d pod(s) unschedulable — %s", g.name, g.count, g.reason))
+	}
+	return lines
+}
+
+// workloadName names the pod's owning workload ("ns/Deployment/web"), folding
+// ReplicaSet pods into their Deployment via the pod-template-hash suffix.
+func workloadName(p *corev1.Pod) string {
+	ref := metav1.GetControllerOf(p)
+	if ref == nil {
+		return p.Namespace + "/" + p.Name
+	}
+	kind, name := ref.Kind, ref.Name
+	if hash := p.Labels["pod-template-hash"]; kind == "ReplicaSet" && hash != "" && strings.HasSuffix(name, "-"+hash) {
+		kind, name = "Deployment", strings.TrimSuffix(name, "-"+hash)
+	}
+	return p.Namespace + "/" + kind + "/" + name
+}
@@ -0,0 +1,203 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func simNodeFixture(name, ng, cpu, mem string) corev1.Node {
+	labels := map[string]string{nodeLabelNodegroup: ng, corev1.LabelHostname: name, corev1.LabelTopologyZone: "us-east-1a"}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// deployPod is a ReplicaSet-owned pod of Deployment app with the given requests.
+func deployPod(app string, i int, node, cpu, mem string) corev1.Pod {
+	ctrl := true
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            app + "-7d9f-" + string(rune('a'+i)),
+			Labels:          map[string]string{"app": app, "pod-template-hash": "7d9f"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: app + "-7d9f", Controller: &ctrl}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{Name: "c", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			}}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func roll(ng string, batch int) DrainPlan {
+	return DrainPlan{Nodegroup: ng, BatchSize: batch, Replace: true}
+}
+
+func TestSimulateDrains_RollFitsOnReplacements(t *testing.T) {
+	// Both nodes are nearly full, but each is replaced before it drains.
+	nodes := []corev1.Node{
+		simNodeFixture("a", "workers", "2", "4Gi"),
+		simNodeFixture("b", "workers", "2", "4Gi"),
+	}
+	pods := []corev1.Pod{
+		deployPod("web", 0, "a", "1800m", "1Gi"),
+		deployPod("web", 1, "b", "1800m", "1Gi"),
+	}
+	r := simulateDrains(nodes, pods, []DrainPlan{roll("workers", 1)})
+	if r.Status != StatusPass || !r.IsBlocking {
+		t.Fatalf("status = %s (%s), want a blocking PASS", r.Status, r.Message)
+	}
+	if !strings.Contains(strings.Join(r.Details, "\n"), "2 replacement node(s); 2 pod(s) to reschedule") {
+		t.Errorf("details = %v", r.Details)
+	}
+}
+
+func TestSimulateDrains_ScaleDownReportsUnschedulableWorkloads(t *testing.T) {
+	nodes := []corev1.Node{
+		simNodeFixture("a", "workers", "2", "4Gi"),
+		simNodeFixture("b", "workers", "2", "4Gi"),
+	}
+	pods := []corev1.Pod{
+		deployPod("web", 0, "a", "1500m", "1Gi"),
+		deployPod("web", 1, "b", "1500m", "1Gi"),
+	}
+	r := simulateDrains(nodes, pods, []DrainPlan{ScaleDownDrainPlan("workers", 1)})
+	if r.Status != StatusFail {
+		t.Fatalf("status = %s, want FAIL", r.Status)
+	}
+	joined := strings.Join(r.Details, "\n")
+	if !strings.Contains(joined, "default/Deployment/web: 1 pod(s) unschedulable — 0/1 nodes: 1 Insufficient cpu") {
+		t.Errorf("details = %v", r.Details)
+	}
+}
+
+func TestSimulateDrains_PlacementConstraints(t *testing.T) {
+	gpu := map[string]string{"accelerator": "nvidia"}
+	taint := corev1.Taint{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	cases := []struct {
+		name   string
+		mutate func(p *corev1.Pod, target *corev1.Node)
+		want   string // "" means it fits
+	}{
+		{"fits", func(*corev1.Pod, *corev1.Node) {}, ""},
+		{"node selector", func(p *corev1.Pod, _ *corev1.Node) { p.Spec.NodeSelector = gpu }, "didn't match node selector"},
+		{"taint", func(_ *corev1.Pod, n *corev1.Node) { n.Spec.Taints = []corev1.Taint{taint} }, "untolerated taint"},
+		{"toleration", func(p *corev1.Pod, n *corev1.Node) {
+			n.Spec.Taints = []corev1.Taint{taint}
+			p.Spec.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}
+		}, ""},
+		{"required node affinity", func(p *corev1.Pod, _ *corev1.Node) {
+			p.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "accelerator", Operator: corev1.NodeSelectorOpIn, Values: []string{"nvidia"}}},
+				}}},
+			}}
+		}, "didn't match node selector"},
+		{"pod anti-affinity", func(p *corev1.Pod, _ *corev1.Node) {
+			p.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					TopologyKey:   corev1.LabelTopologyZone,
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				}},
+			}}
+		}, "pod affinity/anti-affinity"},
+		{"topology spread", func(p *corev1.Pod, _ *corev1.Node) {
+			p.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+				MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			}}
+		}, "topology spread"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Drain "old"; the only schedulable node left already runs two db pods.
+			target := simNodeFixture("target", "system", "8", "16Gi")
+			nodes := []corev1.Node{simNodeFixture("old", "workers", "2", "4Gi"), target, simNodeFixture("spare", "system", "8", "16Gi")}
+			nodes[2].Spec.Unschedulable = true
+			pods := []corev1.Pod{
+				deployPod("db", 0, "target", "100m", "128Mi"),
+				deployPod("db", 1, "target", "100m", "128Mi"),
+				deployPod("db", 2, "old", "100m", "128Mi"),
+			}
+			tc.mutate(&pods[2], &nodes[1])
+			// The spare node is cordoned but carries the hostname domain, so
+			// spread sees a 2-vs-0 skew.
+			r := simulateDrains(nodes, pods, []DrainPlan{ScaleDownDrainPlan("workers", 1)})
+			if tc.want == "" {
+				if r.Status != StatusPass {
+					t.Errorf("status = %s: %v", r.Status, r.Details)
+				}
+				return
+			}
+			if r.Status != StatusFail || !strings.Contains(strings.Join(r.Details, "\n"), tc.want) {
+				t.Errorf("status = %s, details = %v; want FAIL mentioning %q", r.Status, r.Details, tc.want)
+			}
+		})
+	}
+}
+
+func TestSimulateDrains_DaemonSetsAndUnknownNodegroup(t *testing.T) {
+	ctrl := true
+	ds := deployPod("fluent-bit", 0, "a", "100m", "64Mi")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "fluent-bit", Controller: &ctrl}}
+	nodes := []corev1.Node{simNodeFixture("a", "workers", "1", "1Gi")}
+
+	r := simulateDrains(nodes, []corev1.Pod{ds}, []DrainPlan{ScaleDownDrainPlan("workers", 1)})
+	if r.Status != StatusPass {
+		t.Errorf("DaemonSet pods aren't evicted: status = %s, %v", r.Status, r.Details)
+	}
+	if r := simulateDrains(nodes, nil, []DrainPlan{roll("gpu", 1)}); !r.Skipped {
+		t.Errorf("no nodes for the nodegroup should skip: %+v", r)
+	}
+}
+
+func TestRollDrainPlan_MaxUnavailable(t *testing.T) {
+	ng := &ekstypes.Nodegroup{NodegroupName: aws.String("workers")}
+	if p := RollDrainPlan(ng); p.BatchSize != 1 || !p.Replace || p.Nodegroup != "workers" {
+		t.Errorf("default plan = %+v", p)
+	}
+	ng.UpdateConfig = &ekstypes.NodegroupUpdateConfig{MaxUnavailablePercentage: aws.Int32(25)}
+	ng.ScalingConfig = &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(10)}
+	if p := RollDrainPlan(ng); p.BatchSize != 3 {
+		t.Errorf("25%% of 10 = batch %d, want 3 (rounded up)", p.BatchSize)
+	}
+}
+
+func TestRunAllChecks_SchedulingOnlyWithDrainPlans(t *testing.T) {
+	nodes := []corev1.Node{simNodeFixture("a", "workers", "2", "4Gi")}
+	pod := deployPod("web", 0, "a", "1", "1Gi")
+	hc := NewChecker(nil, fake.NewClientset(&nodes[0], &pod), nil, nil)
+	if err := hc.SetOptions(Options{Thresholds: DefaultThresholds(), Checks: []string{CheckSchedulingID, CheckPDBsID}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := hc.RunAllChecks(context.Background(), "prod"); len(s.Results) != 1 {
+		t.Fatalf("without plans: %d results, want just the PDB check", len(s.Results))
+	}
+
+	hc.SetDrainPlans([]DrainPlan{ScaleDownDrainPlan("workers", 1)})
+	s := hc.RunAllChecks(context.Background(), "prod")
+	if s.Decision != DecisionBlock || s.Results[len(s.Results)-1].Name != "Pod Scheduling" {
+		t.Errorf("decision = %s, results = %+v", s.Decision, s.Results)
+	}
+}
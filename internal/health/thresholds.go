@@ -0,0 +1,209 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Thresholds are the tunable limits the checks evaluate against. The zero
+// value is not useful; start from DefaultThresholds and apply overrides.
+type Thresholds struct {
+	MinSafeCPUHeadroomPercent float64
+	MinWarnCPUHeadroomPercent float64
+	PeakWarnCPUPercent        float64
+	PeakFailCPUPercent        float64
+	MinSafeMemHeadroomPercent float64
+	MinWarnMemHeadroomPercent float64
+	EtcdWarnPercent           float64
+	EtcdFailPercent           float64
+	APIServer5xxWarnPercent   float64
+	APIServerMinReqsForRate   float64
+	QuotaWarnPercent          float64
+	QuotaHighPercent          float64
+}
+
+// DefaultThresholds returns the built-in limits.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MinSafeCPUHeadroomPercent: minSafeCPUHeadroomPercent,
+		MinWarnCPUHeadroomPercent: minWarnCPUHeadroomPercent,
+		PeakWarnCPUPercent:        peakWarnCPUPercent,
+		PeakFailCPUPercent:        peakFailCPUPercent,
+		MinSafeMemHeadroomPercent: minSafeMemHeadroomPercent,
+		MinWarnMemHeadroomPercent: minWarnMemHeadroomPercent,
+		EtcdWarnPercent:           etcdWarnPercent,
+		EtcdFailPercent:           etcdFailPercent,
+		APIServer5xxWarnPercent:   apiserver5xxWarnPercent,
+		APIServerMinReqsForRate:   apiserverMinReqsForRate,
+		QuotaWarnPercent:          quotaWarnPercent,
+		QuotaHighPercent:          quotaHighPercent,
+	}
+}
+
+// fields maps the override keys (the names used in refresh.yaml and
+// --threshold) to the struct fields they set.
+func (t *Thresholds) fields() map[string]*float64 {
+	return map[string]*float64{
+		"minSafeCPUHeadroomPercent": &t.MinSafeCPUHeadroomPercent,
+		"minWarnCPUHeadroomPercent": &t.MinWarnCPUHeadroomPercent,
+		"peakWarnCPUPercent":        &t.PeakWarnCPUPercent,
+		"peakFailCPUPercent":        &t.PeakFailCPUPercent,
+		"minSafeMemHeadroomPercent": &t.MinSafeMemHeadroomPercent,
+		"minWarnMemHeadroomPercent": &t.MinWarnMemHeadroomPercent,
+		"etcdWarnPercent":           &t.EtcdWarnPercent,
+		"etcdFailPercent":           &t.EtcdFailPercent,
+		"apiserver5xxWarnPercent":   &t.APIServer5xxWarnPercent,
+		"apiserverMinReqsForRate":   &t.APIServerMinReqsForRate,
+		"quotaWarnPercent":          &t.QuotaWarnPercent,
+		"quotaHighPercent":          &t.QuotaHighPercent,
+	}
+}
+
+// ThresholdNames lists the keys accepted by Thresholds.With, sorted.
+func ThresholdNames() []string {
+	var t Thresholds
+	names := make([]string, 0, len(t.fields()))
+	for k := range t.fields() {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// With returns a copy of t with the named overrides applied. Unknown names and
+// inverted warn/fail pairs are errors, so a typo can't silently keep the
+// default.
+func (t Thresholds) With(overrides map[string]float64) (Thresholds, error) {
+	fields := t.fields()
+	for k, v := range overrides {
+		f, ok := fields[k]
+		if !ok {
+			return t, fmt.Errorf("unknown health threshold %q (valid: %s)", k, strings.Join(ThresholdNames(), ", "))
+		}
+		if v < 0 {
+			return t, fmt.Errorf("health threshold %s must not be negative", k)
+		}
+		*f = v
+	}
+	return t, t.validate()
+}
+
+// validate rejects pairs whose pass/warn/fail bands would overlap.
+func (t Thresholds) validate() error {
+	pairs := []struct {
+		lo, hi         float64
+		loName, hiName string
+	}{
+		{t.MinWarnCPUHeadroomPercent, t.MinSafeCPUHeadroomPercent, "minWarnCPUHeadroomPercent", "minSafeCPUHeadroomPercent"},
+		{t.PeakWarnCPUPercent, t.PeakFailCPUPercent, "peakWarnCPUPercent", "peakFailCPUPercent"},
+		{t.MinWarnMemHeadroomPercent, t.MinSafeMemHeadroomPercent, "minWarnMemHeadroomPercent", "minSafeMemHeadroomPercent"},
+		{t.EtcdWarnPercent, t.EtcdFailPercent, "etcdWarnPercent", "etcdFailPercent"},
+		{t.QuotaWarnPercent, t.QuotaHighPercent, "quotaWarnPercent", "quotaHighPercent"},
+	}
+	for _, p := range pairs {
+		if p.lo > p.hi {
+			return fmt.Errorf("health threshold %s (%s) must not exceed %s (%s)", p.loName, fmtPct(p.lo), p.hiName, fmtPct(p.hi))
+		}
+	}
+	return nil
+}
+
+// fmtPct formats a threshold without trailing zeros ("95%", "2.5%").
+func fmtPct(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+}
+
+// Check IDs accepted by --checks, --skip-checks and health.blocking.
+const (
+	CheckNodeHealthID   = "node-health"
+	CheckCapacityID     = "capacity"
+	CheckUtilizationID  = "utilization"
+	CheckControlPlaneID = "control-plane"
+	CheckQuotasID       = "quotas"
+	CheckWorkloadsID    = "workloads"
+	CheckPDBsID         = "pdbs"
+	CheckBalanceID      = "balance"
+	CheckSchedulingID   = "scheduling"
+	CheckDrainID        = "drain"
+)
+
+// CheckIDs lists every check RunAllChecks knows, in run order. The
+// scheduling and drain checks only run when drain plans are set.
+func CheckIDs() []string {
+	return []string{
+		CheckNodeHealthID, CheckCapacityID, CheckUtilizationID, CheckControlPlaneID,
+		CheckQuotasID, CheckWorkloadsID, CheckPDBsID, CheckBalanceID, CheckSchedulingID,
+		CheckDrainID,
+	}
+}
+
+// Options tune a HealthChecker: thresholds, which checks run, and per-check
+// overrides of whether a failure blocks.
+type Options struct {
+	Thresholds Thresholds
+	// Checks limits the run to these check IDs; empty means all.
+	Checks []string
+	// SkipChecks removes check IDs from the run.
+	SkipChecks []string
+	// Blocking overrides IsBlocking per check ID.
+	Blocking map[string]bool
+}
+
+// DefaultOptions runs every check with the built-in thresholds and blocking.
+func DefaultOptions() Options {
+	return Options{Thresholds: DefaultThresholds()}
+}
+
+// Validate rejects unknown check IDs and an empty selection.
+func (o Options) Validate() error {
+	known := map[string]bool{}
+	for _, id := range CheckIDs() {
+		known[id] = true
+	}
+	check := func(what, id string) error {
+		if !known[id] {
+			return fmt.Errorf("unknown health check %q in %s (valid: %s)", id, what, strings.Join(CheckIDs(), ", "))
+		}
+		return nil
+	}
+	for _, id := range o.Checks {
+		if err := check("--checks", id); err != nil {
+			return err
+		}
+	}
+	for _, id := range o.SkipChecks {
+		if err := check("--skip-checks", id); err != nil {
+			return err
+		}
+	}
+	for id := range o.Blocking {
+		if err := check("blocking", id); err != nil {
+			return err
+		}
+	}
+	if len(o.selected()) == 0 {
+		return fmt.Errorf("--checks/--skip-checks leave no health checks to run")
+	}
+	return o.Thresholds.validate()
+}
+
+// selected returns the check IDs to run, in run order.
+func (o Options) selected() []string {
+	only := map[string]bool{}
+	for _, id := range o.Checks {
+		only[id] = true
+	}
+	skip := map[string]bool{}
+	for _, id := range o.SkipChecks {
+		skip[id] = true
+	}
+	var ids []string
+	for _, id := range CheckIDs() {
+		if (len(only) == 0 || only[id]) && !skip[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestThresholdsWith(t *testing.T) {
+	th, err := DefaultThresholds().With(map[string]float64{"peakFailCPUPercent": 98, "minWarnCPUHeadroomPercent": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.PeakFailCPUPercent != 98 || th.MinWarnCPUHeadroomPercent != 5 || th.PeakWarnCPUPercent != peakWarnCPUPercent {
+		t.Errorf("overrides not applied: %+v", th)
+	}
+
+	if _, err := DefaultThresholds().With(map[string]float64{"peakFailCPU": 98}); err == nil || !strings.Contains(err.Error(), "peakFailCPUPercent") {
+		t.Errorf("unknown name: err = %v, want the valid names listed", err)
+	}
+	if _, err := DefaultThresholds().With(map[string]float64{"etcdWarnPercent": 99}); err == nil {
+		t.Error("warn above fail should be rejected")
+	}
+}
+
+func TestOptionsValidateAndSelect(t *testing.T) {
+	o := DefaultOptions()
+	o.Checks = []string{CheckCapacityID, CheckBalanceID, CheckNodeHealthID}
+	o.SkipChecks = []string{CheckBalanceID}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// Run order is fixed, not the order given.
+	if got := strings.Join(o.selected(), ","); got != "node-health,capacity" {
+		t.Errorf("selected = %s", got)
+	}
+
+	o.SkipChecks = []string{CheckCapacityID, CheckBalanceID, CheckNodeHealthID}
+	if err := o.Validate(); err == nil {
+		t.Error("an empty selection should be rejected")
+	}
+	o = DefaultOptions()
+	o.Blocking = map[string]bool{"Cluster Capacity": false}
+	if err := o.Validate(); err == nil {
+		t.Error("blocking overrides must use check IDs")
+	}
+}
+
+func TestCheckCapacity_GPUClusterWithRaisedThresholds(t *testing.T) {
+	// A GPU fleet legitimately runs hot: blocked on the defaults...
+	snap := seededSnapshot(map[string]float64{"i-1": 90, "i-2": 92})
+	hc := NewChecker(nil, nil, nil, nil)
+	if r := hc.checkClusterCapacityWith(context.Background(), snap); r.Status != StatusFail {
+		t.Fatalf("defaults: status = %s, want FAIL", r.Status)
+	}
+
+	// ...and a warning once its cluster scope lowers the bars.
+	th, err := DefaultThresholds().With(map[string]float64{"minWarnCPUHeadroomPercent": 5, "peakFailCPUPercent": 98})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hc.SetOptions(Options{Thresholds: th}); err != nil {
+		t.Fatal(err)
+	}
+	r := hc.checkClusterCapacityWith(context.Background(), snap)
+	if r.Status != StatusWarn {
+		t.Errorf("overridden: status = %s, want WARN (%s)", r.Status, r.Message)
+	}
+	last := r.Details[len(r.Details)-1]
+	if !strings.Contains(last, "warn ≥5%") || !strings.Contains(last, "fail ≥98%") {
+		t.Errorf("effective thresholds not echoed: %q", last)
+	}
+}
+
+func TestRunAllChecks_SelectionAndBlockingOverride(t *testing.T) {
+	hc := NewChecker(nil, nil, nil, nil)
+	if err := hc.SetOptions(Options{Thresholds: DefaultThresholds(), Checks: []string{"nope"}}); err == nil {
+		t.Fatal("unknown check ID should be rejected")
+	}
+	if err := hc.SetOptions(Options{
+		Thresholds: DefaultThresholds(),
+		Checks:     []string{CheckControlPlaneID, CheckQuotasID},
+		Blocking:   map[string]bool{CheckQuotasID: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	summary := hc.RunAllChecks(context.Background(), "gpu-prod")
+	if len(summary.Results) != 2 {
+		t.Fatalf("results = %d, want only the selected 2", len(summary.Results))
+	}
+	if cp := summary.Results[0]; cp.Name != "Control Plane" || cp.IsBlocking {
+		t.Errorf("control plane = %+v, want it left as is", cp)
+	}
+	q := summary.Results[1]
+	if q.Name != "Service Quotas" || !q.IsBlocking {
+		t.Errorf("quotas = %+v, want the blocking override applied", q)
+	}
+	if len(q.Details) == 0 || q.Details[len(q.Details)-1] != "Blocking: on (overridden)" {
+		t.Errorf("override not noted in details: %v", q.Details)
+	}
+}
@@ -8,8 +8,9 @@ import (
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
-// Memory headroom thresholds for absorbing a node drain. Memory is less
-// forgiving than CPU (no throttling — eviction/OOM), so the bars are tighter.
+// Default memory headroom thresholds for absorbing a node drain. Memory is
+// less forgiving than CPU (no throttling — eviction/OOM), so the bars are
+// tighter. Overridable per cluster via Thresholds.
 const (
 	minSafeMemHeadroomPercent = 20.0
 	minWarnMemHeadroomPercent = 10.0
@@ -51,7 +52,7 @@ func (hc *HealthChecker) CheckNodeUtilization(ctx context.Context, _ string) Hea
 			Message: fmt.Sprintf("live utilization unavailable: %v", err),
 		}
 	}
-	return evaluateNodeUtilization(cpuPct, memPct, nodes)
+	return evaluateNodeUtilization(cpuPct, memPct, nodes, hc.thresholds())
 }
 
 // nodeUtilization sums live usage (metrics-server) against node allocatable
@@ -98,22 +99,24 @@ func (hc *HealthChecker) nodeUtilization(ctx context.Context) (cpuPct, memPct fl
 
 // evaluateNodeUtilization turns CPU/memory utilization into an advisory verdict
 // (pure, table-testable). Worst of the two dimensions wins.
-func evaluateNodeUtilization(cpuPct, memPct float64, nodes int) HealthResult {
+func evaluateNodeUtilization(cpuPct, memPct float64, nodes int, th Thresholds) HealthResult {
 	r := HealthResult{Name: "Node Utilization", IsBlocking: false, Status: StatusPass, Score: 100}
 	r.Details = append(r.Details, fmt.Sprintf("Live utilization across %d node(s): CPU %.1f%%, memory %.1f%%", nodes, cpuPct, memPct))
 
 	cpuHead, memHead := 100-cpuPct, 100-memPct
 	switch {
-	case cpuHead < minWarnCPUHeadroomPercent || memHead < minWarnMemHeadroomPercent:
+	case cpuHead < th.MinWarnCPUHeadroomPercent || memHead < th.MinWarnMemHeadroomPercent:
 		r.Status = StatusFail
 		r.Score = 40
 		r.Message = fmt.Sprintf("Low headroom for a drain (CPU %.1f%%, memory %.1f%% in use)", cpuPct, memPct)
-	case cpuHead < minSafeCPUHeadroomPercent || memHead < minSafeMemHeadroomPercent:
+	case cpuHead < th.MinSafeCPUHeadroomPercent || memHead < th.MinSafeMemHeadroomPercent:
 		r.Status = StatusWarn
 		r.Score = 70
 		r.Message = fmt.Sprintf("Limited headroom for a drain (CPU %.1f%%, memory %.1f%% in use)", cpuPct, memPct)
 	default:
 		r.Message = fmt.Sprintf("Healthy headroom (CPU %.1f%%, memory %.1f%% in use)", cpuPct, memPct)
 	}
+	r.Details = append(r.Details, fmt.Sprintf("Thresholds: headroom pass ≥%s CPU / ≥%s memory, warn ≥%s CPU / ≥%s memory",
+		fmtPct(th.MinSafeCPUHeadroomPercent), fmtPct(th.MinSafeMemHeadroomPercent), fmtPct(th.MinWarnCPUHeadroomPercent), fmtPct(th.MinWarnMemHeadroomPercent)))
 	return r
 }
@@ -56,7 +56,7 @@ func TestEvaluateNodeUtilization(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			r := evaluateNodeUtilization(tc.cpu, tc.mem, 3)
+			r := evaluateNodeUtilization(tc.cpu, tc.mem, 3, DefaultThresholds())
 			if r.Status != tc.wantStatus {
 				t.Errorf("status = %s, want %s (%+v)", r.Status, tc.wantStatus, r)
 			}
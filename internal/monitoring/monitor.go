@@ -154,6 +154,9 @@ func checkAllUpdatesWithChannels(ctx context.Context, eksClient *eks.Client, mon
 			// in-flight update as FAILED.
 			update.LastCheckError = result.err.Error()
 			allComplete = false
+			if config.OnPoll != nil {
+				config.OnPoll(*update)
+			}
 			continue
 		}
 
@@ -161,6 +164,9 @@ func checkAllUpdatesWithChannels(ctx context.Context, eksClient *eks.Client, mon
 		update.LastChecked = now
 		update.ErrorMessage = result.errMsg
 		update.LastCheckError = ""
+		if config.OnPoll != nil {
+			config.OnPoll(*update)
+		}
 
 		if !isUpdateComplete(update.Status) {
 			allComplete = false
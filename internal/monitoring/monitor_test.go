@@ -293,3 +293,17 @@ func TestMonitorUpdatesCompletesAndTimesOut(t *testing.T) {
 		t.Fatal("expected timeout")
 	}
 }
+
+func TestCheckAllUpdatesReportsEachPoll(t *testing.T) {
+	var polled []refreshTypes.UpdateProgress
+	cfg := refreshTypes.MonitorConfig{Quiet: true, MaxRetries: 1, BackoffMultiple: 1,
+		OnPoll: func(u refreshTypes.UpdateProgress) { polled = append(polled, u) }}
+	monitor := testMonitorWithUpdates(ekstypes.UpdateStatusInProgress, ekstypes.UpdateStatusSuccessful)
+	if _, err := checkAllUpdatesWithChannels(context.Background(), fakeEKSDescribeUpdate(ekstypes.UpdateStatusFailed, "boom"), monitor, cfg); err != nil {
+		t.Fatal(err)
+	}
+	// The already-complete update isn't polled again.
+	if len(polled) != 1 || polled[0].Status != ekstypes.UpdateStatusFailed {
+		t.Fatalf("polled = %+v, want one Failed poll", polled)
+	}
+}
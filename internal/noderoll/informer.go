@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -22,11 +23,12 @@ type informerSet struct {
 	nodes  corelisters.NodeLister
 	pods   corelisters.PodLister
 	events corelisters.EventLister
+	pdbs   policylisters.PodDisruptionBudgetLister
 	stop   context.CancelFunc
 }
 
 // StartInformers switches the observer from per-Snapshot List calls to shared
-// informers: one watch stream per resource (nodes, pods, Warning events), with
+// informers: one watch stream per resource (nodes, pods, PDBs, Warning events), with
 // every Snapshot read served from the informer's local cache. Changes arrive
 // as they happen instead of on the next poll, and the repeated cluster-wide
 // pod List — the expensive call on large clusters — collapses into a single
@@ -55,10 +57,12 @@ func (o *KubeObserver) StartInformers(ctx context.Context) error {
 	nodes := nodeFactory.Core().V1().Nodes()
 	pods := podFactory.Core().V1().Pods()
 	events := eventFactory.Core().V1().Events()
+	pdbs := podFactory.Policy().V1().PodDisruptionBudgets()
 	synced := []cache.InformerSynced{
 		nodes.Informer().HasSynced,
 		pods.Informer().HasSynced,
 		events.Informer().HasSynced,
+		pdbs.Informer().HasSynced,
 	}
 
 	nodeFactory.Start(stopCtx.Done())
@@ -76,6 +80,7 @@ func (o *KubeObserver) StartInformers(ctx context.Context) error {
 		nodes:  nodes.Lister(),
 		pods:   pods.Lister(),
 		events: events.Lister(),
+		pdbs:   pdbs.Lister(),
 		stop:   cancel,
 	}
 	return nil
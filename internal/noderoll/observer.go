@@ -66,6 +66,9 @@ type Snapshot struct {
 	// nodes — the "why is a node stuck" signal (failed drain/eviction, sandbox
 	// failures) that the coarse lifecycle phases can't show.
 	Warnings []WarnEvent `json:"warnings,omitempty"`
+	// Stuck explains the pods still holding up draining nodes — which PDB,
+	// finalizer, hung termination or volume problem — ranked worst first.
+	Stuck []StuckPod `json:"stuck,omitempty"`
 }
 
 // WarnEvent is a Kubernetes Warning event scoped to a nodegroup node.
@@ -97,6 +100,11 @@ type KubeObserver struct {
 	// drainStart remembers the evictable-pod count when a node first appears
 	// Draining, so the panel can show evicted/total as pods leave.
 	drainStart map[string]int
+	// drainSince remembers when each node was first seen Draining, to age
+	// the pods stuck on it.
+	drainSince map[string]time.Time
+	// now is the clock (swapped in tests).
+	now func() time.Time
 	// inf, when set (StartInformers), serves reads from informer caches fed by
 	// watch streams instead of issuing List calls per snapshot.
 	inf *informerSet
@@ -105,7 +113,7 @@ type KubeObserver struct {
 // NewKubeObserver returns an Observer for nodegroup, treating targetAMI as the
 // "new" AMI the roll is moving toward.
 func NewKubeObserver(client kubernetes.Interface, nodegroup, targetAMI string) *KubeObserver {
-	return &KubeObserver{client: client, nodegroup: nodegroup, targetAMI: targetAMI}
+	return &KubeObserver{client: client, nodegroup: nodegroup, targetAMI: targetAMI, now: time.Now}
 }
 
 // CaptureBaseline records the nodegroup's current node set as "old" so that
@@ -123,6 +131,27 @@ func (o *KubeObserver) CaptureBaseline(ctx context.Context) error {
 	return nil
 }
 
+// InferTargetAMI sets the target AMI to the image of the nodegroup's newest
+// node — for attaching to a roll already in flight, where neither the target
+// AMI nor a roll-start baseline is known: a rolling nodegroup's newest nodes
+// are its replacements. A no-op when the nodegroup has no nodes.
+func (o *KubeObserver) InferTargetAMI(ctx context.Context) error {
+	nodes, err := o.listNodes(ctx)
+	if err != nil {
+		return err
+	}
+	var newest *corev1.Node
+	for _, n := range nodes {
+		if newest == nil || n.CreationTimestamp.After(newest.CreationTimestamp.Time) {
+			newest = n
+		}
+	}
+	if newest != nil {
+		o.targetAMI = newest.Labels[LabelImage]
+	}
+	return nil
+}
+
 // listNodes returns the nodegroup's nodes: from the informer cache when
 // watching, else via a label-scoped List call.
 func (o *KubeObserver) listNodes(ctx context.Context) ([]*corev1.Node, error) {
@@ -168,17 +197,26 @@ func (o *KubeObserver) Snapshot(ctx context.Context) (Snapshot, error) {
 			}
 		}
 	}
-	// Best-effort pod-eviction progress for draining nodes.
-	if snap.Draining > 0 {
-		o.fillPodEviction(ctx, &snap)
-	}
-
-	// Best-effort Warning events scoped to this nodegroup's nodes.
+	// Best-effort Warning events scoped to this nodegroup's nodes. A list
+	// failure leaves Warnings empty (the panel omits the section) rather than
+	// failing the snapshot.
 	nodeSet := make(map[string]bool, len(nodes))
 	for _, n := range nodes {
 		nodeSet[n.Name] = true
 	}
-	o.fillWarnings(ctx, &snap, nodeSet)
+	events, evErr := o.listWarningEvents(ctx)
+	if evErr == nil {
+		snap.Warnings = scopeWarnings(events, nodeSet, o.now(), warningWindow)
+	}
+
+	// Best-effort pod-eviction progress and stall diagnosis for draining
+	// nodes.
+	if snap.Draining > 0 {
+		if pods, err := o.listPods(ctx); err == nil {
+			o.fillPodEviction(&snap, pods)
+			o.fillStalls(ctx, &snap, pods, events)
+		}
+	}
 
 	// Stable order so renders/golden tests are deterministic.
 	sort.Slice(snap.Nodes, func(i, j int) bool { return snap.Nodes[i].Name < snap.Nodes[j].Name })
@@ -189,17 +227,6 @@ func (o *KubeObserver) Snapshot(ctx context.Context) (Snapshot, error) {
 // panel shows what's happening during the roll, not stale history.
 const warningWindow = 10 * time.Minute
 
-// fillWarnings lists Warning events and keeps those scoped to the nodegroup's
-// nodes. Best-effort: a list failure leaves Warnings empty (the panel omits the
-// section) rather than failing the snapshot.
-func (o *KubeObserver) fillWarnings(ctx context.Context, snap *Snapshot, nodeSet map[string]bool) {
-	events, err := o.listWarningEvents(ctx)
-	if err != nil {
-		return
-	}
-	snap.Warnings = scopeWarnings(events, nodeSet, time.Now(), warningWindow)
-}
-
 // listWarningEvents returns cluster Warning events: from the informer cache
 // when watching (its factory tweak pre-filters to type=Warning), else via a
 // bounded List call.
@@ -226,13 +253,8 @@ func (o *KubeObserver) listWarningEvents(ctx context.Context) ([]corev1.Event, e
 }
 
 // fillPodEviction counts the evictable pods on each draining node and records
-// the count at drain start, so the panel can show evicted/total. Best-effort:
-// a list failure leaves the pod fields zero (the panel just omits the bar).
-func (o *KubeObserver) fillPodEviction(ctx context.Context, snap *Snapshot) {
-	pods, err := o.listPods(ctx)
-	if err != nil {
-		return
-	}
+// the count and time at drain start, so the panel can show evicted/total.
+func (o *KubeObserver) fillPodEviction(snap *Snapshot, pods []*corev1.Pod) {
 	counts := make(map[string]int)
 	for _, p := range pods {
 		if isEvictablePod(p) {
@@ -241,6 +263,7 @@ func (o *KubeObserver) fillPodEviction(ctx context.Context, snap *Snapshot) {
 	}
 	if o.drainStart == nil {
 		o.drainStart = make(map[string]int)
+		o.drainSince = make(map[string]time.Time)
 	}
 	for i := range snap.Nodes {
 		n := &snap.Nodes[i]
@@ -250,6 +273,7 @@ func (o *KubeObserver) fillPodEviction(ctx context.Context, snap *Snapshot) {
 		cur := counts[n.Name]
 		if _, seen := o.drainStart[n.Name]; !seen {
 			o.drainStart[n.Name] = cur
+			o.drainSince[n.Name] = o.now()
 		}
 		n.Pods = cur
 		n.PodsTotal = o.drainStart[n.Name]
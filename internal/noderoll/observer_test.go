@@ -257,3 +257,25 @@ func nodeOf(s Snapshot, name string) NodeView {
 	}
 	return NodeView{}
 }
+
+// Attaching mid-roll: the newest node's image is taken as the target, so the
+// replacements read as new and the originals as old.
+func TestKubeObserver_InferTargetAMI(t *testing.T) {
+	old := mkNode("ip-1", oldAMI, true, true)
+	old.CreationTimestamp = metav1.NewTime(time.Now().Add(-48 * time.Hour))
+	repl := mkNode("ip-2", newAMI, true, false)
+	repl.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	obs := NewKubeObserver(fake.NewClientset(old, repl), ng, "")
+	if err := obs.InferTargetAMI(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := obs.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range snap.Nodes {
+		if n.OnTarget != (n.Name == "ip-2") {
+			t.Errorf("%s onTarget = %v", n.Name, n.OnTarget)
+		}
+	}
+}
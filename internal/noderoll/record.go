@@ -0,0 +1,256 @@
+package noderoll
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// RecordKind is the type of one line in a roll recording.
+type RecordKind string
+
+const (
+	RecordMeta     RecordKind = "meta"     // what was rolled: written first
+	RecordSnapshot RecordKind = "snapshot" // one observed Snapshot
+	RecordEvent    RecordKind = "event"    // one tracker lifecycle Event
+	RecordStatus   RecordKind = "status"   // one EKS DescribeUpdate poll
+)
+
+// RecordingMeta describes the roll a recording captured.
+type RecordingMeta struct {
+	Cluster    string   `json:"cluster"`
+	Nodegroups []string `json:"nodegroups"`
+}
+
+// StatusPoll is the outcome of one EKS DescribeUpdate poll.
+type StatusPoll struct {
+	UpdateID string `json:"updateId"`
+	Status   string `json:"status,omitempty"`
+	// Error is the update's own failure message; PollError a transient
+	// failure of the poll itself (the update may still be running).
+	Error     string `json:"error,omitempty"`
+	PollError string `json:"pollError,omitempty"`
+}
+
+// Record is one timestamped NDJSON line of a roll recording. Exactly one of
+// the payload fields is set, matching Kind.
+type Record struct {
+	Time      time.Time      `json:"ts"`
+	Kind      RecordKind     `json:"type"`
+	Nodegroup string         `json:"nodegroup,omitempty"`
+	Meta      *RecordingMeta `json:"meta,omitempty"`
+	Snapshot  *Snapshot      `json:"snapshot,omitempty"`
+	Event     *Event         `json:"event,omitempty"`
+	Status    *StatusPoll    `json:"status,omitempty"`
+}
+
+// Recorder writes a roll recording as NDJSON: every observed Snapshot, the
+// lifecycle Events derived from them, and each EKS status poll, one
+// timestamped line each. Safe for concurrent use; a nil *Recorder records
+// nothing, so callers needn't guard every call. Write errors are sticky and
+// reported by Close — recording never interrupts a roll.
+type Recorder struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	enc    *json.Encoder
+	closer io.Closer
+	now    func() time.Time
+	err    error
+}
+
+// NewRecorder records to w.
+func NewRecorder(w io.Writer) *Recorder {
+	bw := bufio.NewWriter(w)
+	return &Recorder{w: bw, enc: json.NewEncoder(bw), now: time.Now}
+}
+
+// CreateRecorder records to a new file at path (truncating an existing one).
+func CreateRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating roll recording: %w", err)
+	}
+	r := NewRecorder(f)
+	r.closer = f
+	return r, nil
+}
+
+// Meta records what is being rolled.
+func (r *Recorder) Meta(cluster string, nodegroups []string) {
+	r.write(Record{Kind: RecordMeta, Meta: &RecordingMeta{Cluster: cluster, Nodegroups: nodegroups}})
+}
+
+// Snapshot records one observed snapshot of nodegroup.
+func (r *Recorder) Snapshot(nodegroup string, s Snapshot) {
+	r.write(Record{Kind: RecordSnapshot, Nodegroup: nodegroup, Snapshot: &s})
+}
+
+// Event records one lifecycle event of nodegroup.
+func (r *Recorder) Event(nodegroup string, e Event) {
+	r.write(Record{Kind: RecordEvent, Nodegroup: nodegroup, Event: &e})
+}
+
+// Status records one EKS update status poll of nodegroup.
+func (r *Recorder) Status(nodegroup string, p StatusPoll) {
+	r.write(Record{Kind: RecordStatus, Nodegroup: nodegroup, Status: &p})
+}
+
+func (r *Recorder) write(rec Record) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	rec.Time = r.now().UTC()
+	if err := r.enc.Encode(rec); err != nil {
+		r.err = err
+		return
+	}
+	// Flush per line so a recording cut short (Ctrl+C, crash) is still
+	// readable up to the last record.
+	r.err = r.w.Flush()
+}
+
+// Close flushes and closes the recording, returning the first write error.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); r.err == nil {
+		r.err = err
+	}
+	if r.closer != nil {
+		if err := r.closer.Close(); r.err == nil {
+			r.err = err
+		}
+	}
+	return r.err
+}
+
+// ReadRecording parses an NDJSON roll recording. Blank lines are skipped; a
+// malformed line is an error naming its line number.
+func ReadRecording(r io.Reader) ([]Record, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var out []Record
+	for line := 1; sc.Scan(); line++ {
+		b := sc.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, fmt.Errorf("roll recording line %d: %w", line, err)
+		}
+		out = append(out, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading roll recording: %w", err)
+	}
+	return out, nil
+}
+
+// RecordingObserver wraps an Observer, recording each snapshot it yields and
+// the lifecycle events a Tracker derives from them.
+type RecordingObserver struct {
+	inner     Observer
+	rec       *Recorder
+	nodegroup string
+	tr        *Tracker
+}
+
+// NewRecordingObserver records inner's snapshots of nodegroup to rec.
+func NewRecordingObserver(inner Observer, rec *Recorder, nodegroup string) *RecordingObserver {
+	return &RecordingObserver{inner: inner, rec: rec, nodegroup: nodegroup, tr: NewTracker()}
+}
+
+// Snapshot reads the next snapshot from the wrapped observer and records it.
+func (o *RecordingObserver) Snapshot(ctx context.Context) (Snapshot, error) {
+	s, err := o.inner.Snapshot(ctx)
+	if err != nil {
+		return s, err
+	}
+	o.rec.Snapshot(o.nodegroup, s)
+	seen := len(o.tr.Events)
+	o.tr.Observe(s)
+	for _, e := range o.tr.Events[seen:] {
+		o.rec.Event(o.nodegroup, e)
+	}
+	return s, nil
+}
+
+// ReplayObserver replays the recorded snapshots of one nodegroup, pacing
+// them by their recorded timestamps divided by speed. Like ScriptedObserver
+// it repeats the final snapshot once the recording is exhausted.
+type ReplayObserver struct {
+	frames []Record
+	speed  float64
+	i      int
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewReplayObserver replays nodegroup's snapshots from records at speed
+// (1 = real time; <= 0 means no delay).
+func NewReplayObserver(records []Record, nodegroup string, speed float64) *ReplayObserver {
+	o := &ReplayObserver{speed: speed, sleep: sleepCtx}
+	for _, r := range records {
+		if r.Kind == RecordSnapshot && r.Snapshot != nil && r.Nodegroup == nodegroup {
+			o.frames = append(o.frames, r)
+		}
+	}
+	return o
+}
+
+// First returns the first recorded snapshot, if any.
+func (o *ReplayObserver) First() (Snapshot, bool) {
+	if len(o.frames) == 0 {
+		return Snapshot{}, false
+	}
+	return *o.frames[0].Snapshot, true
+}
+
+// Snapshot waits out the recorded gap to the next snapshot, then returns it.
+func (o *ReplayObserver) Snapshot(ctx context.Context) (Snapshot, error) {
+	if len(o.frames) == 0 {
+		return Snapshot{}, nil
+	}
+	if o.i >= len(o.frames) {
+		return *o.frames[len(o.frames)-1].Snapshot, nil
+	}
+	if o.i > 0 && o.speed > 0 {
+		gap := o.frames[o.i].Time.Sub(o.frames[o.i-1].Time)
+		if err := o.sleep(ctx, time.Duration(float64(gap)/o.speed)); err != nil {
+			return Snapshot{}, err
+		}
+	}
+	s := *o.frames[o.i].Snapshot
+	o.i++
+	return s, nil
+}
+
+// AtEnd reports whether every recorded snapshot has been returned.
+func (o *ReplayObserver) AtEnd() bool { return o.i >= len(o.frames) }
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package noderoll
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordDemo records the --simulate timeline through a RecordingObserver,
+// one snapshot every 30s on a fake clock.
+func recordDemo(t *testing.T) []Record {
+	t.Helper()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	clock := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	rec.now = func() time.Time { clock = clock.Add(30 * time.Second); return clock }
+	rec.Meta("prod", []string{ng})
+
+	timeline := DemoTimeline()
+	obs := NewRecordingObserver(NewScriptedObserver(timeline), rec, ng)
+	for range timeline {
+		if _, err := obs.Snapshot(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec.Status(ng, StatusPoll{UpdateID: "upd-1", Status: "Successful"})
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	records := recordDemo(t)
+	timeline := DemoTimeline()
+
+	counts := map[RecordKind]int{}
+	for _, r := range records {
+		counts[r.Kind]++
+	}
+	tr := NewTracker()
+	for _, s := range timeline {
+		tr.Observe(s)
+	}
+	if counts[RecordMeta] != 1 || counts[RecordSnapshot] != len(timeline) || counts[RecordEvent] != len(tr.Events) || counts[RecordStatus] != 1 {
+		t.Fatalf("record counts = %v, want 1 meta, %d snapshots, %d events, 1 status", counts, len(timeline), len(tr.Events))
+	}
+	if records[0].Kind != RecordMeta || records[0].Meta.Cluster != "prod" {
+		t.Errorf("first record = %+v, want the meta line", records[0])
+	}
+	// Snapshots survive the round trip intact, stuck pods included.
+	var stuck int
+	for _, r := range records {
+		if r.Kind == RecordSnapshot {
+			stuck += len(r.Snapshot.Stuck)
+		}
+	}
+	if stuck == 0 {
+		t.Error("the demo's PDB-stuck pod was lost in the recording")
+	}
+	if !records[1].Time.Equal(records[0].Time.Add(30 * time.Second)) {
+		t.Errorf("timestamps = %v, %v; want 30s apart", records[0].Time, records[1].Time)
+	}
+}
+
+func TestReadRecording_BadLine(t *testing.T) {
+	_, err := ReadRecording(strings.NewReader("{\"type\":\"meta\"}\n\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("err = %v, want one naming line 3", err)
+	}
+}
+
+func TestReplayObserver_PacesBySpeed(t *testing.T) {
+	records := recordDemo(t)
+	obs := NewReplayObserver(records, ng, 10)
+	var slept []time.Duration
+	obs.sleep = func(_ context.Context, d time.Duration) error { slept = append(slept, d); return nil }
+
+	first, ok := obs.First()
+	if !ok || first.Total != DemoTimeline()[0].Total {
+		t.Fatalf("First() = %+v, %v", first, ok)
+	}
+	n := 0
+	for !obs.AtEnd() {
+		if _, err := obs.Snapshot(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != len(DemoTimeline()) || len(slept) != n-1 {
+		t.Fatalf("replayed %d snapshots with %d waits", n, len(slept))
+	}
+	// Snapshots were recorded 30s apart, interleaved with event lines that
+	// each took a clock tick too; at 10x every wait is a tenth of the gap.
+	for _, d := range slept {
+		if d < 3*time.Second || d%(3*time.Second) != 0 {
+			t.Errorf("wait %v is not a multiple of 3s (30s at 10x)", d)
+		}
+	}
+	// Past the end the final snapshot repeats.
+	last, _ := obs.Snapshot(context.Background())
+	want := DemoTimeline()[len(DemoTimeline())-1]
+	if last.ReadyTarget != want.ReadyTarget || last.Total != want.Total {
+		t.Errorf("after end = %+v, want the final frame", last)
+	}
+}
@@ -65,9 +65,15 @@ func draining(name string, remaining, total int) NodeView {
 	return NodeView{Name: name, OnTarget: false, Ready: true, Phase: PhaseDraining, Pods: remaining, PodsTotal: total}
 }
 
+func withStuck(s Snapshot, stuck ...StuckPod) Snapshot {
+	s.Stuck = stuck
+	return s
+}
+
 // DemoTimeline builds a realistic surge roll of 3 old nodes → 3 new nodes
 // (max-surge 1): each new node joins and goes Ready, then an old node drains
-// its pods and terminates. Used by --simulate.
+// its pods and terminates — the second one briefly held up by a PDB. Used by
+// --simulate.
 func DemoTimeline() []Snapshot {
 	const a, b, c = "ip-10-0-1-12", "ip-10-0-3-21", "ip-10-0-2-08"
 	const d, e, f = "ip-10-0-1-44", "ip-10-0-2-77", "ip-10-0-3-05"
@@ -80,6 +86,11 @@ func DemoTimeline() []Snapshot {
 		snapOf(oldReady(b), oldReady(c), newReady(d)),
 		snapOf(oldReady(b), oldReady(c), newReady(d), newJoining(e)),
 		snapOf(draining(b, 3, 3), oldReady(c), newReady(d), newReady(e)),
+		withStuck(snapOf(draining(b, 1, 3), oldReady(c), newReady(d), newReady(e)), StuckPod{
+			Node: b, Namespace: "payments", Pod: "api-6f9c-x2", Cause: StallPDB, StuckSeconds: 840,
+			Detail: "PDB api allows 0 disruptions (healthy 2/2 desired)",
+			Action: "scale the workload up or relax PDB payments/api; eviction retries until it allows one",
+		}),
 		snapOf(draining(b, 0, 3), oldReady(c), newReady(d), newReady(e)),
 		snapOf(oldReady(c), newReady(d), newReady(e)),
 		snapOf(oldReady(c), newReady(d), newReady(e), newJoining(f)),
@@ -0,0 +1,229 @@
+package noderoll
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StallCause classifies why a pod is still on a draining node.
+type StallCause string
+
+const (
+	StallPDB         StallCause = "pdb"         // a PodDisruptionBudget refuses the eviction
+	StallFinalizer   StallCause = "finalizer"   // deleted, but a finalizer holds the object
+	StallTerminating StallCause = "terminating" // deleted and past its grace period
+	StallVolume      StallCause = "volume"      // volume unmount/detach is failing
+	StallPending     StallCause = "pending"     // not evicted yet, no blocker identified
+)
+
+// stallRank orders causes for the "stuck because" list: the ones that never
+// clear on their own first.
+var stallRank = map[StallCause]int{
+	StallPDB: 0, StallFinalizer: 1, StallTerminating: 2, StallVolume: 3, StallPending: 4,
+}
+
+// pendingStallAfter is how long a node must have been draining before its
+// remaining pods with no identified blocker are listed: a healthy drain
+// evicts everything well within it.
+const pendingStallAfter = 5 * time.Minute
+
+// StuckPod is one pod holding up a node drain, why, and what to do about it.
+type StuckPod struct {
+	Node      string     `json:"node"`
+	Namespace string     `json:"namespace"`
+	Pod       string     `json:"pod"`
+	Cause     StallCause `json:"cause"`
+	Detail    string     `json:"detail"`
+	Action    string     `json:"action"`
+	// StuckSeconds is how long the pod has held the drain: since the node was
+	// first seen draining, or since its grace period ran out when terminating.
+	StuckSeconds int64 `json:"stuckSeconds"`
+}
+
+// Key identifies the pod ("namespace/name").
+func (s StuckPod) Key() string { return s.Namespace + "/" + s.Pod }
+
+// Deletable reports whether deleting the pod is a sensible manual remedy:
+// it bypasses a PDB or finishes a hung termination. A finalizer needs the
+// owning controller (or a patch), not another delete.
+func (s StuckPod) Deletable() bool {
+	return s.Cause == StallPDB || s.Cause == StallTerminating
+}
+
+// DeleteStuckPod deletes a stuck pod: a normal delete for a PDB-blocked pod
+// (its controller recreates it elsewhere), a zero-grace force delete for one
+// that is hung terminating.
+func DeleteStuckPod(ctx context.Context, client kubernetes.Interface, s StuckPod) error {
+	opts := metav1.DeleteOptions{}
+	if s.Cause == StallTerminating {
+		zero := int64(0)
+		opts.GracePeriodSeconds = &zero
+	}
+	if err := client.CoreV1().Pods(s.Namespace).Delete(ctx, s.Pod, opts); err != nil {
+		return fmt.Errorf("deleting pod %s: %w", s.Key(), err)
+	}
+	return nil
+}
+
+// listPDBs returns every PodDisruptionBudget: from the informer cache when
+// watching, else via a List call.
+func (o *KubeObserver) listPDBs(ctx context.Context) ([]*policyv1.PodDisruptionBudget, error) {
+	if o.inf != nil {
+		return o.inf.pdbs.List(labels.Everything())
+	}
+	list, err := o.client.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pdbs := make([]*policyv1.PodDisruptionBudget, len(list.Items))
+	for i := range list.Items {
+		pdbs[i] = &list.Items[i]
+	}
+	return pdbs, nil
+}
+
+// fillStalls diagnoses the pods left on draining nodes. Best-effort: without
+// PDBs the pdb cause is only found via events.
+func (o *KubeObserver) fillStalls(ctx context.Context, snap *Snapshot, pods []*corev1.Pod, events []corev1.Event) {
+	draining := map[string]time.Time{}
+	for _, n := range snap.Nodes {
+		if n.Phase == PhaseDraining {
+			draining[n.Name] = o.drainSince[n.Name]
+		}
+	}
+	pdbs, _ := o.listPDBs(ctx)
+	snap.Stuck = diagnoseStalls(draining, pods, pdbs, events, o.now())
+}
+
+// diagnoseStalls explains every evictable pod still on a draining node
+// (draining maps node → when it was first seen draining), ranked by cause and
+// then by how long it has been stuck. Pure for testability.
+func diagnoseStalls(draining map[string]time.Time, pods []*corev1.Pod, pdbs []*policyv1.PodDisruptionBudget, events []corev1.Event, now time.Time) []StuckPod {
+	podEvents := map[string][]corev1.Event{}
+	for _, e := range events {
+		if e.InvolvedObject.Kind == "Pod" {
+			k := e.InvolvedObject.Namespace + "/" + e.InvolvedObject.Name
+			podEvents[k] = append(podEvents[k], e)
+		}
+	}
+
+	var out []StuckPod
+	for _, p := range pods {
+		since, ok := draining[p.Spec.NodeName]
+		if !ok || !isEvictablePod(p) {
+			continue
+		}
+		s := StuckPod{Node: p.Spec.NodeName, Namespace: p.Namespace, Pod: p.Name}
+		if !since.IsZero() {
+			s.StuckSeconds = int64(now.Sub(since).Seconds())
+		}
+		evCause, evDetail := classifyPodEvents(podEvents[s.Key()])
+
+		switch {
+		case p.DeletionTimestamp != nil && len(p.Finalizers) > 0:
+			s.Cause = StallFinalizer
+			s.Detail = fmt.Sprintf("deleted %s ago, held by finalizer %s", ShortAge(now.Sub(p.DeletionTimestamp.Time)), strings.Join(p.Finalizers, ", "))
+			s.Action = fmt.Sprintf("fix the controller owning the finalizer, or clear it: kubectl patch pod -n %s %s --type=merge -p '{\"metadata\":{\"finalizers\":null}}'", p.Namespace, p.Name)
+		case p.DeletionTimestamp != nil && evCause == StallVolume:
+			s.Cause, s.Detail = StallVolume, evDetail
+			s.Action = fmt.Sprintf("check the CSI driver / attach-detach controller: kubectl describe pod -n %s %s", p.Namespace, p.Name)
+		case p.DeletionTimestamp != nil:
+			over := now.Sub(p.DeletionTimestamp.Time)
+			if over <= 0 {
+				continue // still inside its grace period: terminating normally
+			}
+			s.Cause = StallTerminating
+			s.StuckSeconds = int64(over.Seconds())
+			s.Detail = fmt.Sprintf("terminating %s past its grace period", ShortAge(over))
+			s.Action = fmt.Sprintf("force delete: kubectl delete pod -n %s %s --grace-period=0 --force", p.Namespace, p.Name)
+		default:
+			if pdb := blockingPDB(p, pdbs); pdb != nil {
+				s.Cause = StallPDB
+				s.Detail = fmt.Sprintf("PDB %s allows 0 disruptions (healthy %d/%d desired)", pdb.Name, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+				s.Action = fmt.Sprintf("scale the workload up or relax PDB %s/%s; eviction retries until it allows one", pdb.Namespace, pdb.Name)
+			} else if evCause != "" {
+				s.Cause, s.Detail = evCause, evDetail
+				if evCause == StallPDB {
+					s.Action = "scale the workload up or relax its PDB; eviction retries until it allows one"
+				} else {
+					s.Action = fmt.Sprintf("check the CSI driver / attach-detach controller: kubectl describe pod -n %s %s", p.Namespace, p.Name)
+				}
+			} else {
+				if since.IsZero() || now.Sub(since) < pendingStallAfter {
+					continue
+				}
+				s.Cause = StallPending
+				s.Detail = "not evicted yet; no PDB, finalizer or volume problem found"
+				s.Action = fmt.Sprintf("look for eviction errors: kubectl describe pod -n %s %s", p.Namespace, p.Name)
+			}
+		}
+		out = append(out, s)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if stallRank[a.Cause] != stallRank[b.Cause] {
+			return stallRank[a.Cause] < stallRank[b.Cause]
+		}
+		if a.StuckSeconds != b.StuckSeconds {
+			return a.StuckSeconds > b.StuckSeconds
+		}
+		return a.Key() < b.Key()
+	})
+	return out
+}
+
+// blockingPDB returns the PDB in p's namespace whose selector matches it and
+// that currently allows no disruptions, if any.
+func blockingPDB(p *corev1.Pod, pdbs []*policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	for _, pdb := range pdbs {
+		if pdb.Namespace != p.Namespace || pdb.Status.DisruptionsAllowed > 0 {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !sel.Matches(labels.Set(p.Labels)) {
+			continue
+		}
+		return pdb
+	}
+	return nil
+}
+
+// classifyPodEvents maps a pod's Warning events onto an eviction-failure
+// cause: a disruption-budget refusal or a volume unmount/detach failure. The
+// most recent matching event wins.
+func classifyPodEvents(events []corev1.Event) (StallCause, string) {
+	sort.SliceStable(events, func(i, j int) bool { return warningTime(&events[i]).After(warningTime(&events[j])) })
+	for _, e := range events {
+		msg := strings.ToLower(e.Message)
+		switch {
+		case strings.Contains(msg, "disruption budget"):
+			return StallPDB, "eviction refused: " + e.Message
+		case e.Reason == "FailedUnmount" || e.Reason == "FailedDetachVolume" || e.Reason == "FailedAttachVolume" ||
+			strings.Contains(msg, "multi-attach") || strings.Contains(msg, "unmount"):
+			return StallVolume, e.Reason + ": " + e.Message
+		}
+	}
+	return "", ""
+}
+
+// ShortAge renders a duration as "45s", "12m", "2h5m".
+func ShortAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,115 @@
+package noderoll
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func stallPDB(name, app string, allowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed, CurrentHealthy: 1, DesiredHealthy: 1},
+	}
+}
+
+func TestDiagnoseStalls(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	draining := map[string]time.Time{"ip-1": now.Add(-20 * time.Minute)}
+
+	guarded := pod("web-1", "ip-1", corev1.PodRunning, false, false)
+	guarded.Labels = map[string]string{"app": "web"}
+	finalized := pod("job-1", "ip-1", corev1.PodRunning, false, false)
+	finalized.DeletionTimestamp = &metav1.Time{Time: now.Add(-3 * time.Minute)}
+	finalized.Finalizers = []string{"example.com/cleanup"}
+	hung := pod("db-0", "ip-1", corev1.PodRunning, false, false)
+	hung.DeletionTimestamp = &metav1.Time{Time: now.Add(-8 * time.Minute)}
+	graceful := pod("api-1", "ip-1", corev1.PodRunning, false, false)
+	graceful.DeletionTimestamp = &metav1.Time{Time: now.Add(20 * time.Second)} // still in grace
+	mounted := pod("cache-1", "ip-1", corev1.PodRunning, false, false)
+	waiting := pod("misc-1", "ip-1", corev1.PodRunning, false, false)
+	elsewhere := pod("web-2", "ip-9", corev1.PodRunning, false, false) // node not draining
+	elsewhere.Labels = map[string]string{"app": "web"}
+
+	events := []corev1.Event{{
+		Type: "Warning", Reason: "FailedUnmount", Message: "UnmountVolume.TearDown failed for volume \"data\"",
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "cache-1"},
+		LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+	}}
+
+	got := diagnoseStalls(draining,
+		[]*corev1.Pod{waiting, mounted, graceful, hung, finalized, guarded, elsewhere},
+		[]*policyv1.PodDisruptionBudget{stallPDB("web", "web", 0)}, events, now)
+
+	var order []string
+	for _, s := range got {
+		order = append(order, s.Pod+":"+string(s.Cause))
+	}
+	want := "web-1:pdb,job-1:finalizer,db-0:terminating,cache-1:volume,misc-1:pending"
+	if strings.Join(order, ",") != want {
+		t.Fatalf("ranked = %v, want %s", order, want)
+	}
+	if got[0].StuckSeconds != 1200 || !strings.Contains(got[0].Detail, "PDB web allows 0 disruptions") {
+		t.Errorf("pdb stall = %+v", got[0])
+	}
+	if got[2].StuckSeconds != 480 || !strings.Contains(got[2].Action, "--grace-period=0 --force") {
+		t.Errorf("terminating stall = %+v", got[2])
+	}
+	if !got[0].Deletable() || got[1].Deletable() || !got[2].Deletable() {
+		t.Error("only pdb and terminating stalls should offer a delete")
+	}
+
+	// A fresh drain doesn't list pods with no identified blocker.
+	draining["ip-1"] = now.Add(-time.Minute)
+	for _, s := range diagnoseStalls(draining, []*corev1.Pod{waiting}, nil, nil, now) {
+		t.Errorf("unexpected stall on a fresh drain: %+v", s)
+	}
+}
+
+func TestClassifyPodEvents_PDBRefusal(t *testing.T) {
+	cause, detail := classifyPodEvents([]corev1.Event{{
+		Reason:  "EvictionFailed",
+		Message: "Cannot evict pod as it would violate the pod's disruption budget.",
+	}})
+	if cause != StallPDB || !strings.HasPrefix(detail, "eviction refused: ") {
+		t.Errorf("cause = %q, detail = %q", cause, detail)
+	}
+}
+
+// The real observer path: a cordoned node whose pod a PDB guards surfaces in
+// Snapshot.Stuck, and DeleteStuckPod removes it.
+func TestKubeObserver_StuckPods(t *testing.T) {
+	ctx := context.Background()
+	guarded := pod("web-1", "ip-1", corev1.PodRunning, false, false)
+	guarded.Labels = map[string]string{"app": "web"}
+	client := fake.NewClientset(mkNode("ip-1", oldAMI, true, true), guarded, stallPDB("web", "web", 0))
+
+	obs := NewKubeObserver(client, ng, newAMI)
+	start := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	obs.now = func() time.Time { return start }
+	if _, err := obs.Snapshot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	obs.now = func() time.Time { return start.Add(12 * time.Minute) }
+	snap, err := obs.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Stuck) != 1 || snap.Stuck[0].Cause != StallPDB || snap.Stuck[0].StuckSeconds != 720 {
+		t.Fatalf("stuck = %+v, want the PDB-guarded pod stuck 12m", snap.Stuck)
+	}
+
+	if err := DeleteStuckPod(ctx, client, snap.Stuck[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(ctx, "web-1", metav1.GetOptions{}); err == nil {
+		t.Error("pod should be deleted")
+	}
+}
@@ -0,0 +1,206 @@
+// Package plugins finds and runs external refresh commands, kubectl-style:
+// an executable named refresh-<name> on PATH becomes `refresh <name>`. A
+// plugin is told what refresh resolved for the invocation (context, cluster,
+// region, profile, kubeconfig, log level) through REFRESH_* environment
+// variables, so a wrapper script doesn't have to re-implement the chain.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prefix is the executable name prefix that marks a plugin.
+const Prefix = "refresh-"
+
+// Category is the help category plugin commands are listed under.
+const Category = "PLUGINS"
+
+// interruptGrace is how long a cancelled plugin gets to exit after it is
+// interrupted before it is killed.
+const interruptGrace = 10 * time.Second
+
+// validName is what a plugin name must look like to become a command.
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// Plugin is one discovered plugin.
+type Plugin struct {
+	// Name is the command it provides: refresh-<name>.
+	Name string `json:"name" yaml:"name"`
+	// Path is the executable that runs, the first on PATH.
+	Path string `json:"path" yaml:"path"`
+	// Overshadowed are executables of the same name later on PATH, which
+	// never run.
+	Overshadowed []string `json:"overshadowed,omitempty" yaml:"overshadowed,omitempty"`
+}
+
+// Discover lists the plugins on pathList (a PATH value), by name. Like a
+// shell, the first executable of a name wins; directories that can't be
+// read are skipped. So are empty and relative entries, which name the current
+// directory: like exec.LookPath's ErrDot, a checked-out repo's refresh-*
+// files must not become commands just by cd-ing into it.
+func Discover(pathList string) []Plugin {
+	byName := map[string]*Plugin{}
+	seenDirs := map[string]bool{}
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		if seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			if p, found := byName[name]; found {
+				p.Overshadowed = append(p.Overshadowed, path)
+				continue
+			}
+			byName[name] = &Plugin{Name: name, Path: path}
+		}
+	}
+	out := make([]Plugin, 0, len(byName))
+	for _, p := range byName {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// pluginName returns the command an executable's file name provides.
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !strings.EqualFold(ext, ".exe") && !strings.EqualFold(ext, ".bat") && !strings.EqualFold(ext, ".cmd") {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, validName.MatchString(name)
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
+
+// Env is what a plugin is told about the invocation. Empty fields were not
+// resolved.
+type Env struct {
+	// Bin is the refresh executable, for plugins that call back into it.
+	Bin        string
+	Context    string
+	Cluster    string
+	Region     string
+	Profile    string
+	Kubeconfig string
+	LogLevel   string
+	// Sandbox and Cache carry the global --sandbox and --cache flags, so a
+	// plugin's own refresh calls run the same way.
+	Sandbox string
+	Cache   bool
+	NoColor bool
+}
+
+// Environ returns base with e's variables set. Unresolved REFRESH_*
+// variables are removed rather than inherited, so a plugin never sees values
+// left over from an outer invocation. AWS_REGION and AWS_PROFILE are set too
+// when resolved, for the AWS CLI and SDKs a plugin calls.
+func (e Env) Environ(base []string) []string {
+	vars := [][2]string{
+		{"REFRESH_BIN", e.Bin},
+		{"REFRESH_CONTEXT", e.Context},
+		{"REFRESH_CLUSTER", e.Cluster},
+		{"REFRESH_REGION", e.Region},
+		{"REFRESH_PROFILE", e.Profile},
+		{"REFRESH_KUBECONFIG", e.Kubeconfig},
+		{"REFRESH_LOG_LEVEL", e.LogLevel},
+		{"REFRESH_SANDBOX", e.Sandbox},
+		{"REFRESH_CACHE", boolEnv(e.Cache)},
+	}
+	if e.Region != "" {
+		vars = append(vars, [2]string{"AWS_REGION", e.Region})
+	}
+	if e.Profile != "" {
+		vars = append(vars, [2]string{"AWS_PROFILE", e.Profile})
+	}
+	if e.NoColor {
+		vars = append(vars, [2]string{"NO_COLOR", "1"})
+	}
+
+	set := map[string]bool{}
+	for _, v := range vars {
+		set[v[0]] = true
+	}
+	out := make([]string, 0, len(base)+len(vars))
+	for _, kv := range base {
+		if k, _, _ := strings.Cut(kv, "="); !set[k] {
+			out = append(out, kv)
+		}
+	}
+	for _, v := range vars {
+		if v[1] != "" {
+			out = append(out, v[0]+"="+v[1])
+		}
+	}
+	return out
+}
+
+func boolEnv(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+// Run runs p with args and env, on the given standard streams, and returns
+// its exit code. Cancelling ctx interrupts the plugin, then kills it if it
+// hasn't exited after a grace period. err is set only when the plugin
+// couldn't be started or waited for.
+func Run(ctx context.Context, p Plugin, args []string, env Env, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Env = env.Environ(os.Environ())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = interruptGrace
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if code := exitErr.ExitCode(); code > 0 {
+			return code, nil
+		}
+		// Killed by a signal.
+		return 1, nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeExec(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell-script plugins")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	audit := writeExec(t, first, "refresh-audit", "true")
+	later := writeExec(t, second, "refresh-audit", "true")
+	writeExec(t, second, "refresh-drift-report", "true")
+	writeExec(t, first, "kubectl-foo", "true")
+	writeExec(t, first, "refresh-", "true")
+	if err := os.WriteFile(filepath.Join(first, "refresh-notes"), []byte("not a program"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(first, "refresh-dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Discover(strings.Join([]string{first, filepath.Join(first, "missing"), second, first}, string(os.PathListSeparator)))
+	var names []string
+	for _, p := range got {
+		names = append(names, p.Name)
+	}
+	if !slices.Equal(names, []string{"audit", "drift-report"}) {
+		t.Fatalf("plugins = %v", names)
+	}
+	if got[0].Path != audit || !slices.Equal(got[0].Overshadowed, []string{later}) {
+		t.Errorf("audit = %+v: the first on PATH should win and hide the later one", got[0])
+	}
+}
+
+// TestDiscover_SkipsCurrentDir checks that an empty or relative PATH entry
+// doesn't turn the working directory's refresh-* files into commands.
+func TestDiscover_SkipsCurrentDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell-script plugins")
+	}
+	repo, bin := t.TempDir(), t.TempDir()
+	writeExec(t, repo, "refresh-deploy", "true")
+	if err := os.Mkdir(filepath.Join(repo, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeExec(t, filepath.Join(repo, "bin"), "refresh-build", "true")
+	writeExec(t, bin, "refresh-audit", "true")
+	t.Chdir(repo)
+
+	sep := string(os.PathListSeparator)
+	got := Discover(sep + bin + sep + "." + sep + "bin")
+	if len(got) != 1 || got[0].Name != "audit" {
+		t.Errorf("plugins = %+v, want only audit from %s", got, bin)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	base := []string{"PATH=/bin", "REFRESH_CLUSTER=outer", "REFRESH_SANDBOX=/tmp/outer.yaml", "AWS_REGION=us-east-1"}
+	got := Env{Cluster: "prod", Region: "eu-west-1", LogLevel: "warn", Cache: true}.Environ(base)
+	want := []string{"PATH=/bin", "REFRESH_CLUSTER=prod", "REFRESH_REGION=eu-west-1", "REFRESH_LOG_LEVEL=warn", "REFRESH_CACHE=true", "AWS_REGION=eu-west-1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Environ =\n  %v\nwant\n  %v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell-script plugins")
+	}
+	path := writeExec(t, t.TempDir(), "refresh-echo", `echo "$REFRESH_CLUSTER $*"; read line; echo "$line" >&2; exit 3`)
+	var stdout, stderr bytes.Buffer
+	code, err := Run(context.Background(), Plugin{Name: "echo", Path: path}, []string{"--strict", "x"},
+		Env{Cluster: "prod"}, strings.NewReader("from stdin\n"), &stdout, &stderr)
+	if err != nil || code != 3 {
+		t.Fatalf("Run = %d, %v; want the plugin's exit code 3", code, err)
+	}
+	if stdout.String() != "prod --strict x\n" || stderr.String() != "from stdin\n" {
+		t.Errorf("stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	if _, err := Run(context.Background(), Plugin{Name: "gone", Path: filepath.Join(t.TempDir(), "refresh-gone")}, nil, Env{}, nil, &stdout, &stderr); err == nil {
+		t.Error("a plugin that can't be started should be an error")
+	}
+}
@@ -21,6 +21,7 @@ type LiveRegion struct {
 	tty      bool // repaint in place vs. append
 	prev     int  // lines painted last frame (TTY only)
 	appended bool // whether we've appended at least one snapshot (non-TTY)
+	detached bool // Detach showed the cursor; the next Draw hides it again
 }
 
 // NewLiveRegion returns a LiveRegion for w. It repaints in place only when w is
@@ -34,6 +35,10 @@ func (t *Theme) NewLiveRegion(w io.Writer) *LiveRegion {
 func (lr *LiveRegion) Draw(frame []string) {
 	body := strings.Join(frame, "\n")
 	if lr.tty {
+		if lr.detached {
+			_, _ = fmt.Fprint(lr.w, "\x1b[?25l")
+			lr.detached = false
+		}
 		if lr.prev > 0 {
 			_, _ = fmt.Fprintf(lr.w, "\x1b[%dA\x1b[0J", lr.prev) // up prev lines, clear to end
 		}
@@ -48,6 +53,17 @@ func (lr *LiveRegion) Draw(frame []string) {
 	lr.appended = true
 }
 
+// Detach leaves the last frame on screen as scrollback, so the caller can
+// write below it (a prompt) without the next Draw overwriting that output.
+// The cursor is shown until the next Draw.
+func (lr *LiveRegion) Detach() {
+	if lr.tty {
+		_, _ = fmt.Fprint(lr.w, "\x1b[?25h")
+		lr.detached = true
+	}
+	lr.prev = 0
+}
+
 // Run draws frames every interval until frame reports done==true or ctx is
 // cancelled. The cursor is hidden during a TTY run and always restored.
 func (lr *LiveRegion) Run(ctx context.Context, interval time.Duration, frame func() (lines []string, done bool)) error {
@@ -165,3 +165,39 @@ func TestLiveRedrawTTY(t *testing.T) {
 		t.Fatalf("redraw missing cursor-up/clear: %q", buf.String())
 	}
 }
+
+func TestLiveDetachKeepsFrame(t *testing.T) {
+	var buf bytes.Buffer
+	lr := &LiveRegion{w: &buf, tty: true}
+	lr.Draw([]string{"x", "y"})
+	lr.Detach()
+	buf.WriteString("prompt? y\n")
+	lr.Draw([]string{"z"})
+	// After Detach the next frame starts below the prompt instead of
+	// rewinding over it, and re-hides the cursor Detach showed.
+	if strings.Contains(buf.String(), "\x1b[2A") {
+		t.Fatalf("redraw after Detach rewound over the prompt: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "\x1b[?25h") || !strings.HasSuffix(buf.String(), "\x1b[?25lz\n") {
+		t.Fatalf("cursor not shown for the prompt and hidden again: %q", buf.String())
+	}
+}
+
+func TestScreenClipsFrame(t *testing.T) {
+	th := New(ColorTrue, true)
+	frame := []string{th.Paint(Mocha.Green, "0123456789"), "short", "dropped"}
+	got := ClipFrame(frame, 6, 2)
+	if len(got) != 2 || ui.VisibleWidth(got[0]) != 6 || got[1] != "short" {
+		t.Fatalf("ClipFrame = %q", got)
+	}
+
+	var buf bytes.Buffer
+	s := NewScreen(&buf)
+	s.Enter()
+	s.Draw([]string{"a", "b"}, 80, 24)
+	s.Leave()
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1b[?1049h") || !strings.Contains(out, "a\x1b[0m\x1b[K\r\nb") || !strings.HasSuffix(out, "\x1b[?1049l") {
+		t.Errorf("screen output = %q", out)
+	}
+}
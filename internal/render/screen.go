@@ -0,0 +1,77 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// Screen paints whole frames on the terminal's alternate screen — the one
+// surface in refresh that is a TUI (`refresh ui`). Like LiveRegion, the frame
+// is a pure []string and Screen only paints it: each frame is clipped to the
+// terminal and drawn from the top-left, so scrollback is untouched and
+// restored on Leave.
+//
+// Lines end in "\r\n" because the caller runs the terminal in raw mode, where
+// a bare newline doesn't return the carriage.
+type Screen struct {
+	w      io.Writer
+	active bool
+}
+
+// NewScreen returns a Screen for w, which must be a terminal.
+func NewScreen(w io.Writer) *Screen { return &Screen{w: w} }
+
+// Enter switches to the alternate screen and hides the cursor.
+func (s *Screen) Enter() {
+	if s.active {
+		return
+	}
+	_, _ = fmt.Fprint(s.w, "\x1b[?1049h\x1b[?25l\x1b[2J")
+	s.active = true
+}
+
+// Leave shows the cursor and returns to the main screen as it was before
+// Enter.
+func (s *Screen) Leave() {
+	if !s.active {
+		return
+	}
+	_, _ = fmt.Fprint(s.w, "\x1b[?25h\x1b[?1049l")
+	s.active = false
+}
+
+// Draw paints frame clipped to width × height, clearing whatever the previous
+// frame left beyond it.
+func (s *Screen) Draw(frame []string, width, height int) {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range ClipFrame(frame, width, height) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[0m\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	_, _ = fmt.Fprint(s.w, b.String())
+}
+
+// ClipFrame keeps the first height lines of frame, each truncated to width
+// visible columns (ANSI-aware). Non-positive dimensions leave that axis
+// unclipped.
+func ClipFrame(frame []string, width, height int) []string {
+	if height > 0 && len(frame) > height {
+		frame = frame[:height]
+	}
+	out := make([]string, len(frame))
+	for i, line := range frame {
+		if width > 0 && ui.VisibleWidth(line) > width {
+			line = ui.TruncateANSI(line, width)
+		}
+		out[i] = line
+	}
+	return out
+}
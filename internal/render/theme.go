@@ -1,7 +1,8 @@
 // Package render is the human-facing visual system for refresh: a small design
 // language (palette, status tokens, primitives) plus an in-place live-region
-// printer. It is line-oriented CLI output — NOT a TUI (no alternate screen, no
-// input loop). Color is always additive: every status also carries a glyph and
+// printer. It is line-oriented CLI output; the one exception is Screen, the
+// alternate-screen painter behind the full-screen `refresh ui` (whose input
+// loop lives in internal/tui). Color is always additive: every status also carries a glyph and
 // a label, so output stays legible with --no-color, when piped, or on a
 // non-UTF-8 terminal.
 //
@@ -42,12 +42,12 @@ func captureStdout(t *testing.T, fn func()) string {
 func TestLiveRollForUpdate_DegradesAndBounds(t *testing.T) {
 	_ = captureStdout(t, func() {
 		// nil client → immediate, no panic.
-		LiveRollForUpdate(context.Background(), nil, "ng", time.Second, time.Second)
+		LiveRollForUpdate(context.Background(), nil, "ng", time.Second, time.Second, LiveRollOptions{})
 
 		// A fake cluster whose old node never gets replaced → bounded by timeout.
 		client := fake.NewClientset(kn("ip-1", true, false))
 		start := time.Now()
-		LiveRollForUpdate(context.Background(), client, "spot-burst", 40*time.Millisecond, 10*time.Millisecond)
+		LiveRollForUpdate(context.Background(), client, "spot-burst", 40*time.Millisecond, 10*time.Millisecond, LiveRollOptions{})
 		if elapsed := time.Since(start); elapsed > 5*time.Second {
 			t.Fatalf("LiveRollForUpdate did not respect timeout bound: %v", elapsed)
 		}
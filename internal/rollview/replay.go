@@ -0,0 +1,179 @@
+package rollview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+// replayRepaint is the panel's tick during a replay: the observer itself
+// waits out the recorded gaps, so the tick only needs to be short.
+const replayRepaint = 10 * time.Millisecond
+
+// ReplayOptions tune Replay.
+type ReplayOptions struct {
+	// Speed divides the recorded gaps between snapshots (10 = ten times
+	// faster); <= 0 replays without delays.
+	Speed float64
+	// Nodegroup limits the replay to one nodegroup of a multi-nodegroup
+	// recording; empty replays each in turn.
+	Nodegroup string
+}
+
+// Replay re-renders a recorded roll (see noderoll.Recorder) through the same
+// live panel the roll was watched with, then prints the EKS update status
+// timeline for each nodegroup.
+func Replay(ctx context.Context, th *render.Theme, w io.Writer, records []noderoll.Record, opts ReplayOptions) error {
+	groups := recordedNodegroups(records)
+	if opts.Nodegroup != "" {
+		found := false
+		for _, ng := range groups {
+			found = found || ng == opts.Nodegroup
+		}
+		if !found {
+			return fmt.Errorf("recording has no nodegroup %q (it has: %s)", opts.Nodegroup, strings.Join(groups, ", "))
+		}
+		groups = []string{opts.Nodegroup}
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("recording has no snapshots or status polls to replay")
+	}
+
+	for i, ng := range groups {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintln(w, replayHeader(th, records, ng, opts.Speed))
+		obs := noderoll.NewReplayObserver(records, ng, opts.Speed)
+		// The first snapshot sizes the roll, as on a live roll.
+		if first, ok := obs.First(); ok {
+			m := rollMeta{Nodegroup: ng, OldAMI: "current AMI", NewAMI: "recommended AMI", Desired: first.Total}
+			_, _ = fmt.Fprintln(w)
+			done := func(noderoll.Snapshot) bool { return obs.AtEnd() }
+			if err := runRoll(ctx, th, w, obs, m, replayRepaint, done, nil); err != nil {
+				return err
+			}
+		}
+		for _, l := range statusTimelineLines(th, records, ng) {
+			_, _ = fmt.Fprintln(w, l)
+		}
+	}
+	return nil
+}
+
+// recordedNodegroups lists the nodegroups in a recording: the meta line's
+// order first, then any others in first-seen order.
+func recordedNodegroups(records []noderoll.Record) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(ng string) {
+		if ng != "" && !seen[ng] {
+			seen[ng] = true
+			out = append(out, ng)
+		}
+	}
+	for _, r := range records {
+		if r.Kind == noderoll.RecordMeta && r.Meta != nil {
+			for _, ng := range r.Meta.Nodegroups {
+				add(ng)
+			}
+		}
+	}
+	for _, r := range records {
+		if r.Kind == noderoll.RecordSnapshot || r.Kind == noderoll.RecordStatus {
+			add(r.Nodegroup)
+		}
+	}
+	return out
+}
+
+// replayHeader names what is being replayed: cluster, nodegroup, when it was
+// recorded, how long it ran and at what speed.
+func replayHeader(th *render.Theme, records []noderoll.Record, ng string, speed float64) string {
+	pal := th.Pal
+	cluster := ""
+	var start, end time.Time
+	snaps := 0
+	for _, r := range records {
+		if r.Kind == noderoll.RecordMeta && r.Meta != nil && cluster == "" {
+			cluster = r.Meta.Cluster
+		}
+		if r.Nodegroup != ng {
+			continue
+		}
+		if r.Kind == noderoll.RecordSnapshot {
+			snaps++
+		}
+		if start.IsZero() {
+			start = r.Time
+		}
+		end = r.Time
+	}
+	name := ng
+	if cluster != "" {
+		name = cluster + "/" + ng
+	}
+	pace := "no delay"
+	if speed > 0 {
+		pace = strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", speed), "0"), ".") + "x"
+	}
+	return th.Bold(pal.Mauve, "REPLAY") + "  " + th.Paint(pal.White, name) +
+		th.Paint(pal.Dim, fmt.Sprintf(" · recorded %s · %s · %d snapshot(s) · %s",
+			start.UTC().Format("2006-01-02 15:04:05 UTC"), end.Sub(start).Round(time.Second), snaps, pace))
+}
+
+// statusTimelineLines renders each EKS update status change for nodegroup,
+// offset from the start of the recording (pure, golden-testable). Repeated
+// polls with an unchanged status collapse into the first.
+func statusTimelineLines(th *render.Theme, records []noderoll.Record, ng string) []string {
+	pal := th.Pal
+	var start time.Time
+	if len(records) > 0 {
+		start = records[0].Time
+	}
+	var out []string
+	last := map[string]string{}
+	for _, r := range records {
+		if r.Kind != noderoll.RecordStatus || r.Nodegroup != ng || r.Status == nil {
+			continue
+		}
+		p := *r.Status
+		state := p.Status
+		if p.PollError != "" {
+			state = "poll error"
+		}
+		if last[p.UpdateID] == state {
+			continue
+		}
+		last[p.UpdateID] = state
+		if out == nil {
+			out = []string{"", th.Paint(pal.Dim, "eks update status")}
+		}
+		line := "    " + th.Paint(pal.Dim, fmt.Sprintf("+%-8s", r.Time.Sub(start).Round(time.Second))) + " " +
+			th.Token(pollStatus(p), state) + th.Paint(pal.Dim, " "+p.UpdateID)
+		if detail := p.Error + p.PollError; detail != "" {
+			line += th.Paint(pal.Dim, "  "+oneLineWarn(detail))
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// pollStatus maps an EKS update status onto a render status.
+func pollStatus(p noderoll.StatusPoll) render.Status {
+	switch {
+	case p.PollError != "":
+		return render.Unknown
+	case p.Status == "Successful":
+		return render.Healthy
+	case p.Status == "Failed" || p.Status == "Cancelled":
+		return render.Fail
+	default:
+		return render.Progress
+	}
+}
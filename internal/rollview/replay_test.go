@@ -0,0 +1,94 @@
+package rollview
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+// recordedDemo records the --simulate timeline plus a couple of EKS status
+// polls, the way `nodegroup update --record` would.
+func recordedDemo(t *testing.T) []noderoll.Record {
+	t.Helper()
+	var buf bytes.Buffer
+	rec := noderoll.NewRecorder(&buf)
+	rec.Meta("prod", []string{"spot-burst"})
+	timeline := noderoll.DemoTimeline()
+	obs := noderoll.NewRecordingObserver(noderoll.NewScriptedObserver(timeline), rec, "spot-burst")
+	for range timeline {
+		if _, err := obs.Snapshot(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec.Status("spot-burst", noderoll.StatusPoll{UpdateID: "upd-1", Status: "InProgress"})
+	rec.Status("spot-burst", noderoll.StatusPoll{UpdateID: "upd-1", Status: "Successful"})
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := noderoll.ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestReplay_RendersRecordedRoll(t *testing.T) {
+	var out bytes.Buffer
+	th := render.New(render.ColorNone, true)
+	if err := Replay(context.Background(), th, &out, recordedDemo(t), ReplayOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"REPLAY  prod/spot-burst",
+		"snapshot(s) · no delay",
+		"rolling spot-burst",
+		"3/3 replaced",
+		"stuck because", // the demo's PDB-held pod
+		"drained & terminated",
+		"eks update status",
+		"Successful",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("replay output missing %q", want)
+		}
+	}
+	if strings.Count(got, "InProgress") != 1 {
+		t.Error("status timeline should list each status once")
+	}
+}
+
+func TestReplay_UnknownNodegroup(t *testing.T) {
+	err := Replay(context.Background(), render.New(render.ColorNone, true), &bytes.Buffer{}, recordedDemo(t), ReplayOptions{Nodegroup: "workers"})
+	if err == nil || !strings.Contains(err.Error(), "it has: spot-burst") {
+		t.Errorf("err = %v, want the recorded nodegroups listed", err)
+	}
+}
+
+func TestStatusTimelineLines_CollapsesRepeats(t *testing.T) {
+	t0 := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	poll := func(at time.Duration, p noderoll.StatusPoll) noderoll.Record {
+		return noderoll.Record{Time: t0.Add(at), Kind: noderoll.RecordStatus, Nodegroup: "workers", Status: &p}
+	}
+	records := []noderoll.Record{
+		{Time: t0, Kind: noderoll.RecordMeta, Meta: &noderoll.RecordingMeta{Cluster: "prod"}},
+		poll(30*time.Second, noderoll.StatusPoll{UpdateID: "u1", Status: "InProgress"}),
+		poll(60*time.Second, noderoll.StatusPoll{UpdateID: "u1", Status: "InProgress"}),
+		poll(90*time.Second, noderoll.StatusPoll{UpdateID: "u1", PollError: "throttled"}),
+		poll(14*time.Minute, noderoll.StatusPoll{UpdateID: "u1", Status: "Failed", Error: "NodeCreationFailure"}),
+	}
+	got := strings.Join(statusTimelineLines(render.New(render.ColorNone, true), records, "workers"), "\n")
+	for _, want := range []string{"+30s", "+1m30s", "poll error u1  throttled", "+14m0s", "Failed u1  NodeCreationFailure"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("timeline missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "+1m0s") {
+		t.Errorf("an unchanged status should collapse:\n%s", got)
+	}
+}
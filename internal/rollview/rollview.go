@@ -1,6 +1,6 @@
 // Package rollview renders the live per-node roll panel — nodes
-// draining/joining/terminating, pod eviction, node pressure, and a Warning-event
-// feed — driven from live Kubernetes state via internal/noderoll. It is shared
+// draining/joining/terminating, pod eviction, node pressure, why a drain is
+// stuck, and a Warning-event feed — driven from live Kubernetes state via internal/noderoll. It is shared
 // by `nodegroup update` and the cluster-upgrade orchestrator's nodegroup phase
 // so both surface the same view; rendering lives here (the view layer), never in
 // a service.
@@ -72,7 +72,8 @@ func eventToken(kind noderoll.EventKind) (render.Status, string) {
 
 // rollPanelLines builds the live node-roll panel as a slice of lines (pure, so
 // it is golden-testable): a header with overall progress, a per-node table
-// (AMI + lifecycle state + pod-eviction bar), and a recent-events feed.
+// (AMI + lifecycle state + pod-eviction bar), a recent-events feed, and the
+// ranked "stuck because" list when pods are holding up a drain.
 func rollPanelLines(th *render.Theme, snap noderoll.Snapshot, events []noderoll.Event, m rollMeta) []string {
 	pal := th.Pal
 
@@ -126,6 +127,8 @@ func rollPanelLines(th *render.Theme, snap noderoll.Snapshot, events []noderoll.
 		out = append(out, "    "+th.Glyph(st)+" "+th.Paint(pal.White, e.Node)+th.Paint(pal.Dim, "  "+text))
 	}
 
+	out = append(out, stuckLines(th, snap.Stuck)...)
+
 	// Warning events explain *why* a node is stuck (failed drain/eviction,
 	// sandbox failures) — surfaced beneath the lifecycle feed.
 	if len(snap.Warnings) > 0 {
@@ -193,21 +196,53 @@ func nodeStateCell(th *render.Theme, n noderoll.NodeView) string {
 	return s
 }
 
+// Panel renders the live roll panel one frame at a time, for a host that owns
+// the redraw loop: runRoll below, and the `refresh ui` attach screen.
+type Panel struct {
+	obs noderoll.Observer
+	tr  *noderoll.Tracker
+	m   rollMeta
+}
+
+// NewPanel renders nodegroup's roll from obs, desired being the node count
+// the roll converges on.
+func NewPanel(obs noderoll.Observer, nodegroup string, desired int) *Panel {
+	return newPanel(obs, rollMeta{Nodegroup: nodegroup, OldAMI: "current AMI", NewAMI: "target AMI", Desired: desired})
+}
+
+func newPanel(obs noderoll.Observer, m rollMeta) *Panel {
+	return &Panel{obs: obs, tr: noderoll.NewTracker(), m: m}
+}
+
+// Frame reads the next snapshot and renders it, advancing the spinner.
+func (p *Panel) Frame(ctx context.Context, th *render.Theme) ([]string, noderoll.Snapshot, error) {
+	snap, err := p.obs.Snapshot(ctx)
+	if err != nil {
+		return nil, snap, err
+	}
+	p.tr.Observe(snap)
+	p.m.Frame++
+	return rollPanelLines(th, snap, p.tr.Recent(6), p.m), snap, nil
+}
+
 // runRoll drives the live panel from obs until done(snapshot) is true or ctx is
 // cancelled, repainting in place on a TTY and appending snapshots when piped.
-func runRoll(ctx context.Context, th *render.Theme, w io.Writer, obs noderoll.Observer, m rollMeta, interval time.Duration, done func(noderoll.Snapshot) bool) error {
-	tr := noderoll.NewTracker()
+// With a prompter, a long-stuck pod from the previous frame is offered for
+// deletion before the next one is read.
+func runRoll(ctx context.Context, th *render.Theme, w io.Writer, obs noderoll.Observer, m rollMeta, interval time.Duration, done func(noderoll.Snapshot) bool, prompter *stuckPrompter) error {
+	p := newPanel(obs, m)
 	lr := th.NewLiveRegion(w)
-	frame := 0
+	var stuck []noderoll.StuckPod
 	return lr.Run(ctx, interval, func() ([]string, bool) {
-		snap, err := obs.Snapshot(ctx)
+		if prompter != nil {
+			prompter.offer(ctx, stuck, lr.Detach)
+		}
+		lines, snap, err := p.Frame(ctx, th)
 		if err != nil {
 			return []string{th.Token(render.Fail, "observer error: "+err.Error())}, true
 		}
-		tr.Observe(snap)
-		frame++
-		m.Frame = frame
-		return rollPanelLines(th, snap, tr.Recent(6), m), done(snap)
+		stuck = snap.Stuck
+		return lines, done(snap)
 	})
 }
 
@@ -230,7 +265,7 @@ func SimulatedRoll(ctx context.Context, nodegroup string) error {
 	obs := noderoll.NewScriptedObserver(noderoll.DemoTimeline())
 
 	fmt.Println()
-	if err := runRoll(ctx, th, os.Stdout, obs, m, 160*time.Millisecond, rollComplete(m.Desired)); err != nil {
+	if err := runRoll(ctx, th, os.Stdout, obs, m, 160*time.Millisecond, rollComplete(m.Desired), nil); err != nil {
 		return err
 	}
 	fmt.Println()
@@ -238,13 +273,24 @@ func SimulatedRoll(ctx context.Context, nodegroup string) error {
 	return nil
 }
 
+// LiveRollOptions tune LiveRollForUpdate.
+type LiveRollOptions struct {
+	// PromptStuck offers to delete a pod that has held a drain for a while
+	// (PDB-blocked or hung terminating), once per pod. Only set it when stdin
+	// is a terminal.
+	PromptStuck bool
+	// Record, when set, receives every snapshot and lifecycle event the panel
+	// observes (see noderoll.Recorder).
+	Record *noderoll.Recorder
+}
+
 // LiveRollForUpdate renders the live per-node roll panel for a real update by
 // observing live Kubernetes state until every roll-start node is replaced
 // (rollComplete) or the timeout fires. Purely visual and best-effort: it never
 // returns an error to the caller, so it cannot affect the update or its exit
 // code — EKS DescribeUpdate remains authoritative. Old-vs-new is determined by a
 // roll-start baseline (no need to know the target AMI ID up front).
-func LiveRollForUpdate(ctx context.Context, kube kubernetes.Interface, nodegroup string, timeout, pollInterval time.Duration) {
+func LiveRollForUpdate(ctx context.Context, kube kubernetes.Interface, nodegroup string, timeout, pollInterval time.Duration, opts LiveRollOptions) {
 	if kube == nil {
 		return
 	}
@@ -281,5 +327,13 @@ func LiveRollForUpdate(ctx context.Context, kube kubernetes.Interface, nodegroup
 	th := render.Default(os.Stdout)
 	m := rollMeta{Nodegroup: nodegroup, OldAMI: "current AMI", NewAMI: "recommended AMI", Desired: desired}
 	fmt.Println()
-	_ = runRoll(rollCtx, th, os.Stdout, obs, m, poll, rollComplete(desired))
+	var prompter *stuckPrompter
+	if opts.PromptStuck {
+		prompter = newStuckPrompter(kube, os.Stdin, os.Stdout)
+	}
+	var o noderoll.Observer = obs
+	if opts.Record != nil {
+		o = noderoll.NewRecordingObserver(obs, opts.Record, nodegroup)
+	}
+	_ = runRoll(rollCtx, th, os.Stdout, o, m, poll, rollComplete(desired), prompter)
 }
@@ -0,0 +1,241 @@
+package rollview
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+// maxStuckLines caps the "stuck because" list in the panel.
+const maxStuckLines = 5
+
+// stuckPromptAfter is how long a pod must have held a drain before the
+// interactive prompt offers to delete it.
+const stuckPromptAfter = 5 * time.Minute
+
+// stuckPromptTimeout is how long the prompt waits for an answer before taking
+// the default (leave the pod alone) and going back to the live panel, so an
+// unattended run never stalls on it.
+const stuckPromptTimeout = time.Minute
+
+// stuckLines renders the ranked "stuck because" list: cause, pod, node and
+// age, with the diagnosis and suggested action beneath.
+func stuckLines(th *render.Theme, stuck []noderoll.StuckPod) []string {
+	if len(stuck) == 0 {
+		return nil
+	}
+	pal := th.Pal
+	out := []string{"", th.Paint(pal.Dim, "stuck because")}
+	for i, s := range stuck {
+		if i == maxStuckLines {
+			out = append(out, th.Paint(pal.Dim, fmt.Sprintf("    … and %d more", len(stuck)-maxStuckLines)))
+			break
+		}
+		st := render.Fail
+		if s.Cause == noderoll.StallPending {
+			st = render.Warn
+		}
+		out = append(out,
+			"    "+th.Token(st, string(s.Cause))+" "+th.Paint(pal.White, s.Key())+
+				th.Paint(pal.Dim, fmt.Sprintf(" on %s · %s", s.Node, noderoll.ShortAge(time.Duration(s.StuckSeconds)*time.Second))),
+			"        "+th.Paint(pal.Text, oneLineWarn(s.Detail)),
+			"        "+th.Paint(pal.Dim, "→ "+s.Action),
+		)
+	}
+	return out
+}
+
+// stuckPrompter offers, once per pod, to delete a pod that has held a drain
+// for stuckPromptAfter when deleting is a sensible remedy.
+type stuckPrompter struct {
+	client  kubernetes.Interface
+	in      io.Reader
+	out     io.Writer
+	after   time.Duration
+	wait    time.Duration
+	offered map[string]bool
+
+	// lines carries answers from the one goroutine reading in, started on
+	// the first prompt; it's closed when in ends. A read can't be
+	// interrupted, so the goroutine outlives an unanswered prompt and its
+	// line, if one comes, is discarded before the next prompt.
+	lines chan string
+}
+
+func newStuckPrompter(client kubernetes.Interface, in io.Reader, out io.Writer) *stuckPrompter {
+	return &stuckPrompter{client: client, in: in, out: out, after: stuckPromptAfter, wait: stuckPromptTimeout, offered: map[string]bool{}}
+}
+
+// readLines starts the reader goroutine.
+func (p *stuckPrompter) readLines() {
+	p.lines = make(chan string)
+	go func() {
+		defer close(p.lines)
+		r := bufio.NewReader(p.in)
+		for {
+			line, err := r.ReadString('\n')
+			if line != "" {
+				p.lines <- line
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// discard drops what was typed after an earlier prompt gave up, so it
+// isn't taken as the answer to the next one.
+func (p *stuckPrompter) discard() {
+	if p.lines == nil {
+		return
+	}
+	for {
+		select {
+		case _, open := <-p.lines:
+			if !open {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// answer waits for the next line, up to p.wait. ok is false when none came:
+// the wait ran out, ctx ended, or in was closed.
+func (p *stuckPrompter) answer(ctx context.Context) (line string, ok bool) {
+	if p.lines == nil {
+		p.readLines()
+	}
+	timer := time.NewTimer(p.wait)
+	defer timer.Stop()
+	select {
+	case line, ok = <-p.lines:
+		return line, ok
+	case <-timer.C:
+		return "", false
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+// candidate returns the worst-ranked pod worth offering, if any.
+func (p *stuckPrompter) candidate(stuck []noderoll.StuckPod) (noderoll.StuckPod, bool) {
+	for _, s := range stuck {
+		if s.Deletable() && !p.offered[s.Key()] && time.Duration(s.StuckSeconds)*time.Second >= p.after {
+			return s, true
+		}
+	}
+	return noderoll.StuckPod{}, false
+}
+
+// offer asks about the next candidate, deleting the pod on "y". detach is
+// called first so the prompt lands below the live panel instead of being
+// painted over. It reports whether it prompted.
+func (p *stuckPrompter) offer(ctx context.Context, stuck []noderoll.StuckPod, detach func()) bool {
+	s, ok := p.candidate(stuck)
+	if !ok {
+		return false
+	}
+	p.offered[s.Key()] = true
+	detach()
+	verb := "Delete"
+	if s.Cause == noderoll.StallTerminating {
+		verb = "Force-delete"
+	}
+	p.discard()
+	_, _ = fmt.Fprintf(p.out, "\n%s has held the drain of %s for %s (%s).\n%s it? (y/N): ",
+		s.Key(), s.Node, noderoll.ShortAge(time.Duration(s.StuckSeconds)*time.Second), s.Cause, verb)
+	answer, ok := p.answer(ctx)
+	if !ok {
+		_, _ = fmt.Fprintln(p.out, "no answer; leaving it")
+		return true
+	}
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		return true
+	}
+	if err := noderoll.DeleteStuckPod(ctx, p.client, s); err != nil {
+		_, _ = fmt.Fprintf(p.out, "  %v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(p.out, "  deleted %s\n", s.Key())
+	}
+	return true
+}
+
+// StallLog collects the pods that held up draining nodes during a roll, for
+// the run summary. Safe for concurrent use.
+type StallLog struct {
+	mu    sync.Mutex
+	order []string
+	last  map[string]noderoll.StuckPod
+}
+
+// Add records a snapshot's diagnosis; a pod seen again keeps its latest.
+func (l *StallLog) Add(stuck []noderoll.StuckPod) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.last == nil {
+		l.last = map[string]noderoll.StuckPod{}
+	}
+	for _, s := range stuck {
+		k := s.Node + "/" + s.Key()
+		if _, seen := l.last[k]; !seen {
+			l.order = append(l.order, k)
+		}
+		l.last[k] = s
+	}
+}
+
+// Stalls returns every pod recorded, in first-seen order.
+func (l *StallLog) Stalls() []noderoll.StuckPod {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]noderoll.StuckPod, 0, len(l.order))
+	for _, k := range l.order {
+		out = append(out, l.last[k])
+	}
+	return out
+}
+
+// WatchRoll observes nodegroup headlessly (no panel) until ctx is done,
+// recording drain stalls into log and every snapshot into rec (either may be
+// nil). It backs the run summary and --record of a roll that has no live
+// panel. Best-effort: an unreadable cluster records nothing.
+func WatchRoll(ctx context.Context, kube kubernetes.Interface, nodegroup string, interval time.Duration, log *StallLog, rec *noderoll.Recorder) {
+	if kube == nil {
+		return
+	}
+	ko := noderoll.NewKubeObserver(kube, nodegroup, "")
+	// Without a baseline every node reads as on-target; recording needs
+	// old-vs-new to replay faithfully.
+	_ = ko.CaptureBaseline(ctx)
+	var obs noderoll.Observer = ko
+	if rec != nil {
+		obs = noderoll.NewRecordingObserver(ko, rec, nodegroup)
+	}
+	if interval <= 0 {
+		interval = liveRollPoll
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if snap, err := obs.Snapshot(ctx); err == nil && log != nil {
+			log.Add(snap.Stuck)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
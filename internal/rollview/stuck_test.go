@@ -0,0 +1,143 @@
+package rollview
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+func stuckFixture() []noderoll.StuckPod {
+	return []noderoll.StuckPod{
+		{Node: "ip-1", Namespace: "payments", Pod: "api-1", Cause: noderoll.StallPDB, StuckSeconds: 840,
+			Detail: "PDB api allows 0 disruptions (healthy 2/2 desired)", Action: "scale the workload up or relax PDB payments/api"},
+		{Node: "ip-1", Namespace: "batch", Pod: "job-1", Cause: noderoll.StallFinalizer, StuckSeconds: 600,
+			Detail: "deleted 10m ago, held by finalizer example.com/cleanup", Action: "fix the controller owning the finalizer"},
+	}
+}
+
+func TestRollPanelLines_StuckBecause(t *testing.T) {
+	th := render.New(render.ColorNone, true)
+	snap := noderoll.Snapshot{
+		Total: 1, Draining: 1,
+		Nodes: []noderoll.NodeView{{Name: "ip-1", Ready: true, Phase: noderoll.PhaseDraining, Pods: 2, PodsTotal: 6}},
+		Stuck: stuckFixture(),
+	}
+	m := rollMeta{Nodegroup: "spot-burst", OldAMI: "ami-old", NewAMI: "ami-new", Desired: 1}
+	joined := strings.Join(rollPanelLines(th, snap, nil, m), "\n")
+	for _, want := range []string{
+		"stuck because",
+		"✗ pdb payments/api-1 on ip-1 · 14m",
+		"PDB api allows 0 disruptions",
+		"→ scale the workload up",
+		"✗ finalizer batch/job-1 on ip-1 · 10m",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("panel missing %q in:\n%s", want, joined)
+		}
+	}
+	if strings.Index(joined, "payments/api-1") > strings.Index(joined, "batch/job-1") {
+		t.Error("stuck pods should keep the observer's ranking")
+	}
+}
+
+func TestStuckPrompter(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-1"}})
+	var out bytes.Buffer
+	p := newStuckPrompter(client, strings.NewReader("y\n"), &out)
+	detached := 0
+
+	stuck := stuckFixture()
+	stuck[0].StuckSeconds = 60 // not stuck long enough yet
+	if p.offer(ctx, stuck, func() { detached++ }) {
+		t.Fatal("a pod stuck for 1m should not be offered")
+	}
+
+	stuck = stuckFixture()
+	if !p.offer(ctx, stuck, func() { detached++ }) || detached != 1 {
+		t.Fatalf("the PDB-blocked pod should be offered (detached %d)", detached)
+	}
+	if !strings.Contains(out.String(), "payments/api-1 has held the drain of ip-1 for 14m (pdb).\nDelete it? (y/N): ") ||
+		!strings.Contains(out.String(), "deleted payments/api-1") {
+		t.Errorf("prompt output = %q", out.String())
+	}
+	if _, err := client.CoreV1().Pods("payments").Get(ctx, "api-1", metav1.GetOptions{}); err == nil {
+		t.Error("answering y should delete the pod")
+	}
+
+	// Offered once; the finalizer stall is never offered (delete won't help).
+	if p.offer(ctx, stuck, func() { detached++ }) {
+		t.Error("a pod should only be offered once")
+	}
+}
+
+// TestStuckPrompter_Unanswered checks that a prompt nobody answers — stdin
+// an open pipe, as in an unattended run — takes the default after its wait,
+// or as soon as ctx ends, and leaves the pod alone.
+func TestStuckPrompter_Unanswered(t *testing.T) {
+	client := fake.NewClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-2"}},
+	)
+	in, w := io.Pipe()
+	defer w.Close()
+	var out bytes.Buffer
+	p := newStuckPrompter(client, in, &out)
+	p.wait = 20 * time.Millisecond
+
+	done := make(chan bool)
+	go func() { done <- p.offer(context.Background(), stuckFixture(), func() {}) }()
+	select {
+	case offered := <-done:
+		if !offered || !strings.Contains(out.String(), "no answer") {
+			t.Errorf("offered %v, output %q", offered, out.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("an unanswered prompt blocked the roll")
+	}
+	if _, err := client.CoreV1().Pods("payments").Get(context.Background(), "api-1", metav1.GetOptions{}); err != nil {
+		t.Error("an unanswered prompt must not delete the pod")
+	}
+
+	// A late "y" belongs to the prompt that gave up, not the next one.
+	go func() { _, _ = io.WriteString(w, "y\n") }()
+	time.Sleep(20 * time.Millisecond)
+	p.wait = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	stuck := stuckFixture()
+	stuck[0].Pod = "api-2"
+	go func() { done <- p.offer(ctx, stuck, func() {}) }()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a prompt should give up when the run's context ends")
+	}
+	if strings.Contains(out.String(), "deleted") {
+		t.Errorf("a stale answer deleted a pod: %q", out.String())
+	}
+}
+
+func TestStallLog_KeepsLatestPerPod(t *testing.T) {
+	var l StallLog
+	first := stuckFixture()
+	l.Add(first[:1])
+	later := stuckFixture()
+	later[0].StuckSeconds = 900
+	l.Add(later)
+	got := l.Stalls()
+	if len(got) != 2 || got[0].Pod != "api-1" || got[0].StuckSeconds != 900 {
+		t.Errorf("stalls = %+v", got)
+	}
+}
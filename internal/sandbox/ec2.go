@@ -0,0 +1,323 @@
+package sandbox
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	asgtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+)
+
+func vpcID(c *Cluster) string { return "vpc-" + hexID(17, c.Region, c.Name, "vpc") }
+
+func subnetID(c *Cluster, zone string) string { return "subnet-" + hexID(17, c.Region, c.Name, zone) }
+
+func asgName(c *Cluster, ng *Nodegroup) string {
+	return fmt.Sprintf("eks-%s-%s", ng.Name, hexID(8, c.Region, c.Name, ng.Name))
+}
+
+// serveEC2 answers the EC2, Auto Scaling and CloudWatch operations refresh
+// calls.
+func (s *Sim) serveEC2(region string, params any, _ *Failure) (any, bool, error) {
+	switch in := params.(type) {
+	case *ec2.DescribeInstancesInput:
+		return s.describeInstances(region, in), true, nil
+	case *ec2.DescribeImagesInput:
+		return s.describeImages(in), true, nil
+	case *ec2.DescribeVpcsInput:
+		out := &ec2.DescribeVpcsOutput{}
+		for _, c := range s.f.Clusters {
+			if c.Region == region && (len(in.VpcIds) == 0 || contains(in.VpcIds, vpcID(&c))) {
+				out.Vpcs = append(out.Vpcs, ec2types.Vpc{VpcId: aws.String(vpcID(&c)), CidrBlock: aws.String("10.0.0.0/16")})
+			}
+		}
+		return out, true, nil
+	case *ec2.DescribeSubnetsInput:
+		out := &ec2.DescribeSubnetsOutput{}
+		for _, c := range s.f.Clusters {
+			if c.Region != region {
+				continue
+			}
+			for i, z := range zones(region) {
+				id := subnetID(&c, z)
+				if len(in.SubnetIds) > 0 && !contains(in.SubnetIds, id) {
+					continue
+				}
+				out.Subnets = append(out.Subnets, ec2types.Subnet{
+					SubnetId:                aws.String(id),
+					VpcId:                   aws.String(vpcID(&c)),
+					AvailabilityZone:        aws.String(z),
+					CidrBlock:               aws.String(fmt.Sprintf("10.0.%d.0/19", i*32)),
+					AvailableIpAddressCount: aws.Int32(8000),
+				})
+			}
+		}
+		return out, true, nil
+	case *ec2.DescribeInstanceTypeOfferingsInput:
+		// Every instance type is offered in every simulated zone.
+		var types []string
+		for _, f := range in.Filters {
+			if aws.ToString(f.Name) == "instance-type" {
+				types = append(types, f.Values...)
+			}
+		}
+		out := &ec2.DescribeInstanceTypeOfferingsOutput{}
+		for _, t := range types {
+			for _, z := range zones(region) {
+				out.InstanceTypeOfferings = append(out.InstanceTypeOfferings, ec2types.InstanceTypeOffering{
+					InstanceType: ec2types.InstanceType(t),
+					Location:     aws.String(z),
+					LocationType: ec2types.LocationTypeAvailabilityZone,
+				})
+			}
+		}
+		return out, true, nil
+	case *ec2.DescribeLaunchTemplateVersionsInput:
+		return s.describeLaunchTemplateVersions(region, in), true, nil
+	case *autoscaling.DescribeAutoScalingGroupsInput:
+		return s.describeAutoScalingGroups(region, in), true, nil
+	case *cloudwatch.GetMetricDataInput:
+		return s.getMetricData(in), true, nil
+	}
+	return nil, false, nil
+}
+
+// nodegroupInstances visits the instances of every nodegroup in the region.
+func (s *Sim) nodegroupInstances(region string, visit func(c *Cluster, ng *Nodegroup, inst Instance)) {
+	for i := range s.f.Clusters {
+		c := &s.f.Clusters[i]
+		if c.Region != region {
+			continue
+		}
+		for j := range c.Nodegroups {
+			for _, inst := range c.Nodegroups[j].Instances {
+				visit(c, &c.Nodegroups[j], inst)
+			}
+		}
+	}
+}
+
+func (s *Sim) describeInstances(region string, in *ec2.DescribeInstancesInput) *ec2.DescribeInstancesOutput {
+	out := &ec2.DescribeInstancesOutput{}
+	// Simulated instances carry no tags, so tag filters match nothing.
+	for _, f := range in.Filters {
+		if name := aws.ToString(f.Name); name == "tag-key" || strings.HasPrefix(name, "tag:") {
+			return out
+		}
+	}
+	s.nodegroupInstances(region, func(c *Cluster, ng *Nodegroup, inst Instance) {
+		if len(in.InstanceIds) > 0 && !contains(in.InstanceIds, inst.ID) {
+			return
+		}
+		lifecycle := ec2types.InstanceLifecycleType("")
+		if ng.CapacityType == ekstypes.CapacityTypesSpot {
+			lifecycle = ec2types.InstanceLifecycleTypeSpot
+		}
+		out.Reservations = append(out.Reservations, ec2types.Reservation{Instances: []ec2types.Instance{{
+			InstanceId:        aws.String(inst.ID),
+			ImageId:           aws.String(inst.ImageID),
+			InstanceType:      ec2types.InstanceType(ng.InstanceType),
+			InstanceLifecycle: lifecycle,
+			LaunchTime:        aws.Time(inst.LaunchTime),
+			State:             &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+			Placement:         &ec2types.Placement{AvailabilityZone: aws.String(inst.Zone)},
+			SubnetId:          aws.String(subnetID(c, inst.Zone)),
+			VpcId:             aws.String(vpcID(c)),
+		}}})
+	})
+	return out
+}
+
+// describeImages serves the fixture's AMI releases by ID. Images of
+// unpublished releases exist too, without a creation date.
+func (s *Sim) describeImages(in *ec2.DescribeImagesInput) *ec2.DescribeImagesOutput {
+	out := &ec2.DescribeImagesOutput{}
+	for _, id := range in.ImageIds {
+		img := ec2types.Image{ImageId: aws.String(id), State: ec2types.ImageStateAvailable}
+		for _, r := range s.f.AMIReleases {
+			if r.ImageID != id {
+				continue
+			}
+			img.Name = aws.String(fmt.Sprintf("amazon-eks-node-%s-%s", strings.ToLower(string(r.AMIType)), r.Release))
+			if built, ok := releaseDate(r.Release); ok {
+				img.CreationDate = aws.String(built.Format("2006-01-02T15:04:05.000Z"))
+			}
+		}
+		out.Images = append(out.Images, img)
+	}
+	return out
+}
+
+// describeLaunchTemplateVersions serves the launch template version each
+// nodegroup runs; other versions don't exist.
+func (s *Sim) describeLaunchTemplateVersions(region string, in *ec2.DescribeLaunchTemplateVersionsInput) *ec2.DescribeLaunchTemplateVersionsOutput {
+	out := &ec2.DescribeLaunchTemplateVersionsOutput{}
+	for _, c := range s.f.Clusters {
+		if c.Region != region {
+			continue
+		}
+		for _, ng := range c.Nodegroups {
+			lt := ng.LaunchTemplate
+			if lt == nil || (aws.ToString(in.LaunchTemplateId) != lt.ID && aws.ToString(in.LaunchTemplateName) != lt.Name) {
+				continue
+			}
+			if len(in.Versions) > 0 && !contains(in.Versions, lt.Version) {
+				continue
+			}
+			number, _ := strconv.ParseInt(lt.Version, 10, 64)
+			v := ec2types.LaunchTemplateVersion{
+				LaunchTemplateId:   aws.String(lt.ID),
+				VersionNumber:      aws.Int64(number),
+				LaunchTemplateData: &ec2types.ResponseLaunchTemplateData{},
+			}
+			if lt.Name != "" {
+				v.LaunchTemplateName = aws.String(lt.Name)
+			}
+			if lt.ImageID != "" {
+				v.LaunchTemplateData.ImageId = aws.String(lt.ImageID)
+			}
+			out.LaunchTemplateVersions = append(out.LaunchTemplateVersions, v)
+		}
+	}
+	return out
+}
+
+func (s *Sim) describeAutoScalingGroups(region string, in *autoscaling.DescribeAutoScalingGroupsInput) *autoscaling.DescribeAutoScalingGroupsOutput {
+	out := &autoscaling.DescribeAutoScalingGroupsOutput{}
+	for i := range s.f.Clusters {
+		c := &s.f.Clusters[i]
+		if c.Region != region {
+			continue
+		}
+		for j := range c.Nodegroups {
+			ng := &c.Nodegroups[j]
+			name := asgName(c, ng)
+			if len(in.AutoScalingGroupNames) > 0 && !contains(in.AutoScalingGroupNames, name) {
+				continue
+			}
+			group := asgtypes.AutoScalingGroup{
+				AutoScalingGroupName: aws.String(name),
+				DesiredCapacity:      aws.Int32(ng.Desired),
+				MinSize:              aws.Int32(ng.Min),
+				MaxSize:              aws.Int32(ng.Max),
+				AvailabilityZones:    zones(region),
+			}
+			for _, inst := range ng.Instances {
+				group.Instances = append(group.Instances, asgtypes.Instance{
+					InstanceId:       aws.String(inst.ID),
+					InstanceType:     aws.String(ng.InstanceType),
+					AvailabilityZone: aws.String(inst.Zone),
+					LifecycleState:   asgtypes.LifecycleStateInService,
+					HealthStatus:     aws.String("Healthy"),
+				})
+			}
+			out.AutoScalingGroups = append(out.AutoScalingGroups, group)
+		}
+	}
+	return out
+}
+
+// getMetricData reports the fixture's value for each queried metric, once
+// per query; metrics the fixture leaves out have no data.
+func (s *Sim) getMetricData(in *cloudwatch.GetMetricDataInput) *cloudwatch.GetMetricDataOutput {
+	out := &cloudwatch.GetMetricDataOutput{}
+	end := aws.ToTime(in.EndTime)
+	if end.IsZero() {
+		end = s.now()
+	}
+	for _, q := range in.MetricDataQueries {
+		r := cwtypes.MetricDataResult{Id: q.Id, StatusCode: cwtypes.StatusCodeComplete}
+		if q.MetricStat != nil && q.MetricStat.Metric != nil {
+			if v, ok := s.f.Metrics[aws.ToString(q.MetricStat.Metric.MetricName)]; ok {
+				r.Values = []float64{v}
+				r.Timestamps = []time.Time{end}
+			}
+		}
+		out.MetricDataResults = append(out.MetricDataResults, r)
+	}
+	return out
+}
+
+// serveSSM answers the EKS-optimized AMI parameter reads.
+func (s *Sim) serveSSM(_ string, params any, _ *Failure) (any, bool, error) {
+	switch in := params.(type) {
+	case *ssm.GetParameterInput:
+		name := aws.ToString(in.Name)
+		for _, p := range s.parameters() {
+			if aws.ToString(p.Name) == name {
+				return &ssm.GetParameterOutput{Parameter: &p}, true, nil
+			}
+		}
+		return nil, true, &ssmtypes.ParameterNotFound{Message: aws.String("Parameter " + name + " not found.")}
+	case *ssm.GetParametersByPathInput:
+		path := strings.TrimSuffix(aws.ToString(in.Path), "/") + "/"
+		out := &ssm.GetParametersByPathOutput{}
+		for _, p := range s.parameters() {
+			rest, ok := strings.CutPrefix(aws.ToString(p.Name), path)
+			if ok && (aws.ToBool(in.Recursive) || !strings.Contains(rest, "/")) {
+				out.Parameters = append(out.Parameters, p)
+			}
+		}
+		return out, true, nil
+	}
+	return nil, false, nil
+}
+
+// parameters are the SSM parameters EKS publishes for the fixture's AMI
+// releases: a directory per release plus "recommended" for the newest.
+func (s *Sim) parameters() []ssmtypes.Parameter {
+	var out []ssmtypes.Parameter
+	add := func(dir string, r AMIRelease) {
+		out = append(out,
+			ssmtypes.Parameter{Name: aws.String(dir + "/image_id"), Value: aws.String(r.ImageID), Type: ssmtypes.ParameterTypeString},
+			ssmtypes.Parameter{Name: aws.String(dir + "/release_version"), Value: aws.String(r.Release), Type: ssmtypes.ParameterTypeString},
+		)
+	}
+	latest := map[string]AMIRelease{}
+	for _, r := range s.f.AMIReleases {
+		base := strings.TrimSuffix(awsinternal.SSMImageParameter(r.Kubernetes, r.AMIType), "/recommended/image_id")
+		if base == "" {
+			continue
+		}
+		add(fmt.Sprintf("%s/amazon-eks-node-%s-%s", base, r.Kubernetes, awsinternal.ReleaseStamp(r.Release)), r)
+		if l, ok := latest[base]; !ok || awsinternal.ReleaseStamp(r.Release) > awsinternal.ReleaseStamp(l.Release) {
+			latest[base] = r
+		}
+	}
+	bases := make([]string, 0, len(latest))
+	for base := range latest {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	for _, base := range bases {
+		add(base+"/recommended", latest[base])
+	}
+	return out
+}
+
+// serveOther answers STS, for credential checks.
+func (s *Sim) serveOther(_ string, params any, _ *Failure) (any, bool, error) {
+	switch params.(type) {
+	case *sts.GetCallerIdentityInput:
+		return &sts.GetCallerIdentityOutput{
+			Account: aws.String(s.f.Account),
+			Arn:     aws.String(fmt.Sprintf("arn:aws:sts::%s:assumed-role/sandbox/refresh", s.f.Account)),
+			UserId:  aws.String("AROASANDBOX:refresh"),
+		}, true, nil
+	}
+	return nil, false, nil
+}
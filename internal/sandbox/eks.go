@@ -0,0 +1,627 @@
+package sandbox
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/services/addons"
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+// serveEKS answers the EKS operations refresh calls.
+func (s *Sim) serveEKS(region string, params any, failure *Failure) (any, bool, error) {
+	var out any
+	var err error
+	switch in := params.(type) {
+	case *eks.ListClustersInput:
+		list := &eks.ListClustersOutput{}
+		for _, c := range s.f.Clusters {
+			if c.Region == region {
+				list.Clusters = append(list.Clusters, c.Name)
+			}
+		}
+		sort.Strings(list.Clusters)
+		out = list
+	case *eks.DescribeClusterInput:
+		out, err = s.describeCluster(region, aws.ToString(in.Name))
+	case *eks.DescribeClusterVersionsInput:
+		out = s.describeClusterVersions(in)
+	case *eks.ListNodegroupsInput:
+		out, err = s.listNodegroups(region, aws.ToString(in.ClusterName))
+	case *eks.DescribeNodegroupInput:
+		out, err = s.describeNodegroup(region, aws.ToString(in.ClusterName), aws.ToString(in.NodegroupName))
+	case *eks.ListAddonsInput:
+		out, err = s.listAddons(region, aws.ToString(in.ClusterName))
+	case *eks.DescribeAddonInput:
+		out, err = s.describeAddon(region, aws.ToString(in.ClusterName), aws.ToString(in.AddonName))
+	case *eks.DescribeAddonVersionsInput:
+		out = s.describeAddonVersions(aws.ToString(in.AddonName), aws.ToString(in.KubernetesVersion))
+	case *eks.ListInsightsInput:
+		out, err = s.listInsights(region, in)
+	case *eks.DescribeInsightInput:
+		out, err = s.describeInsight(region, aws.ToString(in.ClusterName), aws.ToString(in.Id))
+	case *eks.DescribeUpdateInput:
+		out, err = s.describeUpdate(region, in)
+	case *eks.UpdateClusterVersionInput:
+		out, err = s.updateClusterVersion(region, in, failure)
+	case *eks.UpdateNodegroupVersionInput:
+		out, err = s.updateNodegroupVersion(region, in, failure)
+	case *eks.UpdateNodegroupConfigInput:
+		out, err = s.updateNodegroupConfig(region, in, failure)
+	case *eks.UpdateAddonInput:
+		out, err = s.updateAddon(region, in, failure)
+	default:
+		return nil, false, nil
+	}
+	return out, true, err
+}
+
+func (s *Sim) cluster(region, name string) (*Cluster, error) {
+	for i := range s.f.Clusters {
+		if c := &s.f.Clusters[i]; c.Region == region && c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, &ekstypes.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("No cluster found for name: %s.", name))}
+}
+
+func (s *Sim) nodegroup(region, cluster, name string) (*Cluster, *Nodegroup, error) {
+	c, err := s.cluster(region, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range c.Nodegroups {
+		if ng := &c.Nodegroups[i]; ng.Name == name {
+			return c, ng, nil
+		}
+	}
+	return nil, nil, &ekstypes.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("No node group found for name: %s.", name))}
+}
+
+func (s *Sim) addon(region, cluster, name string) (*Cluster, *Addon, error) {
+	c, err := s.cluster(region, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range c.Addons {
+		if a := &c.Addons[i]; a.Name == name {
+			return c, a, nil
+		}
+	}
+	return nil, nil, &ekstypes.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("No addon: %s found in cluster: %s", name, cluster))}
+}
+
+func (s *Sim) arn(region, resource string) string {
+	return fmt.Sprintf("arn:aws:eks:%s:%s:%s", region, s.f.Account, resource)
+}
+
+func (s *Sim) describeCluster(region, name string) (*eks.DescribeClusterOutput, error) {
+	c, err := s.cluster(region, name)
+	if err != nil {
+		return nil, err
+	}
+	id := hexID(32, region, c.Name)
+	subnets := make([]string, 0, 3)
+	for _, z := range zones(region) {
+		subnets = append(subnets, subnetID(c, z))
+	}
+	return &eks.DescribeClusterOutput{Cluster: &ekstypes.Cluster{
+		Name:            aws.String(c.Name),
+		Arn:             aws.String(s.arn(region, "cluster/"+c.Name)),
+		Version:         aws.String(c.Version),
+		PlatformVersion: aws.String(c.PlatformVersion),
+		Status:          c.status,
+		CreatedAt:       aws.Time(c.CreatedAt),
+		Endpoint:        aws.String(fmt.Sprintf("https://%s.gr7.%s.eks.amazonaws.com", strings.ToUpper(id), region)),
+		RoleArn:         aws.String(fmt.Sprintf("arn:aws:iam::%s:role/%s-cluster", s.f.Account, c.Name)),
+		Tags:            c.Tags,
+		ResourcesVpcConfig: &ekstypes.VpcConfigResponse{
+			VpcId:                  aws.String(vpcID(c)),
+			SubnetIds:              subnets,
+			ClusterSecurityGroupId: aws.String("sg-" + hexID(17, region, c.Name, "sg")),
+			EndpointPublicAccess:   true,
+		},
+		KubernetesNetworkConfig: &ekstypes.KubernetesNetworkConfigResponse{
+			ServiceIpv4Cidr: aws.String("10.100.0.0/16"),
+			IpFamily:        ekstypes.IpFamilyIpv4,
+		},
+		UpgradePolicy: &ekstypes.UpgradePolicyResponse{SupportType: c.SupportType},
+		Health:        &ekstypes.ClusterHealth{},
+	}}, nil
+}
+
+func (s *Sim) describeClusterVersions(in *eks.DescribeClusterVersionsInput) *eks.DescribeClusterVersionsOutput {
+	versions := s.f.KubernetesVersions
+	if len(versions) == 0 {
+		for _, e := range status.BuiltInCalendar().Versions {
+			v := KubernetesVersion{Version: e.Version, EndOfStandardSupport: e.StandardUntil}
+			if e.ExtendedUntil != nil {
+				v.EndOfExtendedSupport = *e.ExtendedUntil
+			}
+			versions = append(versions, v)
+		}
+	}
+	now := s.now()
+	out := &eks.DescribeClusterVersionsOutput{}
+	for _, v := range versions {
+		if len(in.ClusterVersions) > 0 && !contains(in.ClusterVersions, v.Version) {
+			continue
+		}
+		state := ekstypes.ClusterVersionStatusStandardSupport
+		switch {
+		case now.After(v.EndOfExtendedSupport):
+			state = ekstypes.ClusterVersionStatusUnsupported
+		case now.After(v.EndOfStandardSupport):
+			state = ekstypes.ClusterVersionStatusExtendedSupport
+		}
+		if state == ekstypes.ClusterVersionStatusUnsupported && !aws.ToBool(in.IncludeAll) && len(in.ClusterVersions) == 0 {
+			continue
+		}
+		out.ClusterVersions = append(out.ClusterVersions, ekstypes.ClusterVersionInformation{
+			ClusterVersion:           aws.String(v.Version),
+			EndOfStandardSupportDate: aws.Time(v.EndOfStandardSupport),
+			EndOfExtendedSupportDate: aws.Time(v.EndOfExtendedSupport),
+			Status:                   state,
+		})
+	}
+	return out
+}
+
+func (s *Sim) listNodegroups(region, cluster string) (*eks.ListNodegroupsOutput, error) {
+	c, err := s.cluster(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	out := &eks.ListNodegroupsOutput{}
+	for _, ng := range c.Nodegroups {
+		out.Nodegroups = append(out.Nodegroups, ng.Name)
+	}
+	return out, nil
+}
+
+func (s *Sim) describeNodegroup(region, cluster, name string) (*eks.DescribeNodegroupOutput, error) {
+	c, ng, err := s.nodegroup(region, cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	subnets := make([]string, 0, 3)
+	for _, z := range zones(region) {
+		subnets = append(subnets, subnetID(c, z))
+	}
+	out := &ekstypes.Nodegroup{
+		NodegroupName: aws.String(ng.Name),
+		NodegroupArn:  aws.String(s.arn(region, fmt.Sprintf("nodegroup/%s/%s/%s", c.Name, ng.Name, hexID(8, ng.Name)))),
+		ClusterName:   aws.String(c.Name),
+		Version:       aws.String(ng.Version),
+		AmiType:       ng.AMIType,
+		CapacityType:  ng.CapacityType,
+		InstanceTypes: []string{ng.InstanceType},
+		Status:        ng.status,
+		Labels:        ng.Labels,
+		Subnets:       subnets,
+		CreatedAt:     aws.Time(c.CreatedAt),
+		ModifiedAt:    aws.Time(c.CreatedAt),
+		NodeRole:      aws.String(fmt.Sprintf("arn:aws:iam::%s:role/%s-nodes", s.f.Account, c.Name)),
+		ScalingConfig: &ekstypes.NodegroupScalingConfig{
+			DesiredSize: aws.Int32(ng.Desired),
+			MinSize:     aws.Int32(ng.Min),
+			MaxSize:     aws.Int32(ng.Max),
+		},
+		UpdateConfig: &ekstypes.NodegroupUpdateConfig{MaxUnavailable: aws.Int32(1)},
+		Resources: &ekstypes.NodegroupResources{
+			AutoScalingGroups: []ekstypes.AutoScalingGroup{{Name: aws.String(asgName(c, ng))}},
+		},
+		Health: &ekstypes.NodegroupHealth{},
+	}
+	if ng.ReleaseVersion != "" {
+		out.ReleaseVersion = aws.String(ng.ReleaseVersion)
+	}
+	if lt := ng.LaunchTemplate; lt != nil {
+		out.LaunchTemplate = &ekstypes.LaunchTemplateSpecification{Id: aws.String(lt.ID), Version: aws.String(lt.Version)}
+		if lt.Name != "" {
+			out.LaunchTemplate.Name = aws.String(lt.Name)
+		}
+	}
+	return &eks.DescribeNodegroupOutput{Nodegroup: out}, nil
+}
+
+func (s *Sim) listAddons(region, cluster string) (*eks.ListAddonsOutput, error) {
+	c, err := s.cluster(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	out := &eks.ListAddonsOutput{}
+	for _, a := range c.Addons {
+		out.Addons = append(out.Addons, a.Name)
+	}
+	return out, nil
+}
+
+func (s *Sim) describeAddon(region, cluster, name string) (*eks.DescribeAddonOutput, error) {
+	c, a, err := s.addon(region, cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	return &eks.DescribeAddonOutput{Addon: &ekstypes.Addon{
+		AddonName:    aws.String(a.Name),
+		AddonVersion: aws.String(a.Version),
+		AddonArn:     aws.String(s.arn(region, fmt.Sprintf("addon/%s/%s/%s", c.Name, a.Name, hexID(8, a.Name)))),
+		ClusterName:  aws.String(c.Name),
+		Status:       a.Status,
+		CreatedAt:    aws.Time(c.CreatedAt),
+		ModifiedAt:   aws.Time(c.CreatedAt),
+		Health:       &ekstypes.AddonHealth{},
+	}}, nil
+}
+
+// describeAddonVersions serves the catalogue, newest version first like EKS,
+// filtered to an add-on and a Kubernetes version when given.
+func (s *Sim) describeAddonVersions(name, k8s string) *eks.DescribeAddonVersionsOutput {
+	names := make([]string, 0, len(s.f.AddonVersions))
+	for n := range s.f.AddonVersions {
+		if name == "" || n == name {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	out := &eks.DescribeAddonVersionsOutput{}
+	for _, n := range names {
+		info := ekstypes.AddonInfo{AddonName: aws.String(n), Owner: aws.String("aws")}
+		for _, v := range s.f.AddonVersions[n] {
+			if k8s != "" && !contains(v.Kubernetes, k8s) {
+				continue
+			}
+			vi := ekstypes.AddonVersionInfo{AddonVersion: aws.String(v.Version), Architecture: []string{"amd64", "arm64"}}
+			for _, k := range v.Kubernetes {
+				vi.Compatibilities = append(vi.Compatibilities, ekstypes.Compatibility{
+					ClusterVersion:   aws.String(k),
+					DefaultVersion:   contains(v.Default, k),
+					PlatformVersions: []string{"*"},
+				})
+			}
+			info.AddonVersions = append(info.AddonVersions, vi)
+		}
+		sort.SliceStable(info.AddonVersions, func(i, j int) bool {
+			return addons.CompareVersions(aws.ToString(info.AddonVersions[i].AddonVersion), aws.ToString(info.AddonVersions[j].AddonVersion)) > 0
+		})
+		if len(info.AddonVersions) > 0 {
+			out.Addons = append(out.Addons, info)
+		}
+	}
+	return out
+}
+
+func insightID(c *Cluster, in Insight) string {
+	id := hexID(32, c.Region, c.Name, in.Name, in.KubernetesVersion)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[:8], id[8:12], id[12:16], id[16:20], id[20:])
+}
+
+func (s *Sim) insight(c *Cluster, in Insight) ekstypes.Insight {
+	now := s.now()
+	return ekstypes.Insight{
+		Id:                 aws.String(insightID(c, in)),
+		Name:               aws.String(in.Name),
+		Category:           ekstypes.CategoryUpgradeReadiness,
+		KubernetesVersion:  aws.String(in.KubernetesVersion),
+		InsightStatus:      &ekstypes.InsightStatus{Status: in.Status, Reason: aws.String(in.Reason)},
+		Description:        aws.String(in.Description),
+		Recommendation:     aws.String(in.Recommendation),
+		LastRefreshTime:    aws.Time(now),
+		LastTransitionTime: aws.Time(now),
+	}
+}
+
+func (s *Sim) listInsights(region string, in *eks.ListInsightsInput) (*eks.ListInsightsOutput, error) {
+	c, err := s.cluster(region, aws.ToString(in.ClusterName))
+	if err != nil {
+		return nil, err
+	}
+	out := &eks.ListInsightsOutput{}
+	for _, ins := range c.Insights {
+		if f := in.Filter; f != nil {
+			if len(f.KubernetesVersions) > 0 && !contains(f.KubernetesVersions, ins.KubernetesVersion) {
+				continue
+			}
+			if len(f.Categories) > 0 && !containsValue(f.Categories, ekstypes.CategoryUpgradeReadiness) {
+				continue
+			}
+			if len(f.Statuses) > 0 && !containsValue(f.Statuses, ins.Status) {
+				continue
+			}
+		}
+		full := s.insight(c, ins)
+		out.Insights = append(out.Insights, ekstypes.InsightSummary{
+			Id:                 full.Id,
+			Name:               full.Name,
+			Category:           full.Category,
+			KubernetesVersion:  full.KubernetesVersion,
+			InsightStatus:      full.InsightStatus,
+			Description:        full.Description,
+			LastRefreshTime:    full.LastRefreshTime,
+			LastTransitionTime: full.LastTransitionTime,
+		})
+	}
+	return out, nil
+}
+
+func (s *Sim) describeInsight(region, cluster, id string) (*eks.DescribeInsightOutput, error) {
+	c, err := s.cluster(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range c.Insights {
+		if insightID(c, ins) == id {
+			full := s.insight(c, ins)
+			return &eks.DescribeInsightOutput{Insight: &full}, nil
+		}
+	}
+	return nil, &ekstypes.ResourceNotFoundException{Message: aws.String("No insight found for id: " + id)}
+}
+
+func (s *Sim) describeUpdate(region string, in *eks.DescribeUpdateInput) (*eks.DescribeUpdateOutput, error) {
+	u, ok := s.updates[aws.ToString(in.UpdateId)]
+	if !ok || u.region != region || u.cluster != aws.ToString(in.Name) ||
+		u.nodegroup != aws.ToString(in.NodegroupName) || u.addon != aws.ToString(in.AddonName) {
+		return nil, &ekstypes.ResourceNotFoundException{Message: aws.String("No update found for ID: " + aws.ToString(in.UpdateId))}
+	}
+	return &eks.DescribeUpdateOutput{Update: u.api()}, nil
+}
+
+// api renders the update as EKS reports it.
+func (u *update) api() *ekstypes.Update {
+	out := &ekstypes.Update{
+		Id:        aws.String(u.id),
+		Type:      u.kind,
+		Status:    u.status,
+		Params:    u.params,
+		CreatedAt: aws.Time(u.started),
+	}
+	if u.status == ekstypes.UpdateStatusFailed || u.status == ekstypes.UpdateStatusCancelled {
+		msg := u.failure.Message
+		if msg == "" {
+			msg = "sandbox: injected update failure"
+		}
+		code := ekstypes.ErrorCodeUnknown
+		if u.nodegroup != "" {
+			code = ekstypes.ErrorCodeNodeCreationFailure
+		}
+		out.Errors = []ekstypes.ErrorDetail{{ErrorCode: code, ErrorMessage: aws.String(msg)}}
+	}
+	return out
+}
+
+func (s *Sim) updateClusterVersion(region string, in *eks.UpdateClusterVersionInput, failure *Failure) (*eks.UpdateClusterVersionOutput, error) {
+	if u, ok := s.replay(in.ClientRequestToken); ok {
+		return &eks.UpdateClusterVersionOutput{Update: u.api()}, nil
+	}
+	c, err := s.cluster(region, aws.ToString(in.Name))
+	if err != nil {
+		return nil, err
+	}
+	if s.inFlight(region, c.Name, "", "") {
+		return nil, &ekstypes.ResourceInUseException{Message: aws.String("Update already in progress")}
+	}
+	target := aws.ToString(in.Version)
+	if minor(target) != minor(c.Version)+1 {
+		return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+			fmt.Sprintf("Unsupported Kubernetes minor version update from %s to %s", c.Version, target))}
+	}
+	c.status = ekstypes.ClusterStatusUpdating
+	u := s.startUpdate(in.ClientRequestToken, &update{
+		region:   region,
+		cluster:  c.Name,
+		kind:     ekstypes.UpdateTypeVersionUpdate,
+		params:   []ekstypes.UpdateParam{{Type: ekstypes.UpdateParamTypeVersion, Value: aws.String(target)}},
+		duration: timing(s.f.Timings.ControlPlane, DefaultControlPlaneDuration),
+		land: func(time.Time) {
+			c.Version, c.PlatformVersion, c.status = target, "eks.1", ekstypes.ClusterStatusActive
+		},
+		undo: func() { c.status = ekstypes.ClusterStatusActive },
+	}, failure)
+	return &eks.UpdateClusterVersionOutput{Update: u.api()}, nil
+}
+
+func (s *Sim) updateNodegroupVersion(region string, in *eks.UpdateNodegroupVersionInput, failure *Failure) (*eks.UpdateNodegroupVersionOutput, error) {
+	if u, ok := s.replay(in.ClientRequestToken); ok {
+		return &eks.UpdateNodegroupVersionOutput{Update: u.api()}, nil
+	}
+	c, ng, err := s.nodegroup(region, aws.ToString(in.ClusterName), aws.ToString(in.NodegroupName))
+	if err != nil {
+		return nil, err
+	}
+	if s.inFlight(region, c.Name, "", "") || s.inFlight(region, c.Name, ng.Name, "") {
+		return nil, &ekstypes.ResourceInUseException{Message: aws.String("Update already in progress")}
+	}
+	version := aws.ToString(in.Version)
+	if version == "" {
+		version = c.Version
+	}
+	switch {
+	case minor(version) > minor(c.Version):
+		return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+			fmt.Sprintf("Requested Kubernetes version %s is newer than the cluster's %s", version, c.Version))}
+	case minor(version) < minor(ng.Version):
+		return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+			fmt.Sprintf("Requested Kubernetes version %s is older than the node group's %s", version, ng.Version))}
+	}
+	release := aws.ToString(in.ReleaseVersion)
+	if release != "" {
+		if _, ok := s.f.release(version, release, ng.AMIType); !ok {
+			return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+				fmt.Sprintf("Requested release version %s is not valid for kubernetes version %s.", release, version))}
+		}
+	} else if r, ok := s.f.latestRelease(version, ng.AMIType); ok {
+		release = r.Release
+	} else if version == ng.Version {
+		release = ng.ReleaseVersion
+	}
+	image := s.f.imageFor(version, release, ng.AMIType)
+
+	params := []ekstypes.UpdateParam{{Type: ekstypes.UpdateParamTypeVersion, Value: aws.String(version)}}
+	if release != "" {
+		params = append(params, ekstypes.UpdateParam{Type: ekstypes.UpdateParamTypeReleaseVersion, Value: aws.String(release)})
+	}
+	ng.status = ekstypes.NodegroupStatusUpdating
+	u := &update{
+		region:    region,
+		cluster:   c.Name,
+		nodegroup: ng.Name,
+		kind:      ekstypes.UpdateTypeVersionUpdate,
+		params:    params,
+		duration:  timing(s.f.Timings.Nodegroup, DefaultNodegroupDuration),
+		land: func(time.Time) {
+			ng.Version, ng.ReleaseVersion, ng.status = version, release, ekstypes.NodegroupStatusActive
+		},
+		undo: func() { ng.status = ekstypes.NodegroupStatusActive },
+	}
+	// Instances are replaced one by one across the update's duration.
+	u.roll = func(now time.Time, progress float64) {
+		replace := int(math.Ceil(progress * float64(len(ng.Instances))))
+		for i := 0; i < replace && i < len(ng.Instances); i++ {
+			if ng.Instances[i].ImageID == image {
+				continue
+			}
+			ng.Instances[i] = Instance{
+				ID:         "i-" + hexID(17, u.id, ng.Name, strconv.Itoa(i)),
+				ImageID:    image,
+				Zone:       ng.Instances[i].Zone,
+				LaunchTime: now,
+			}
+		}
+	}
+	u = s.startUpdate(in.ClientRequestToken, u, failure)
+	return &eks.UpdateNodegroupVersionOutput{Update: u.api()}, nil
+}
+
+func (s *Sim) updateNodegroupConfig(region string, in *eks.UpdateNodegroupConfigInput, failure *Failure) (*eks.UpdateNodegroupConfigOutput, error) {
+	if u, ok := s.replay(in.ClientRequestToken); ok {
+		return &eks.UpdateNodegroupConfigOutput{Update: u.api()}, nil
+	}
+	c, ng, err := s.nodegroup(region, aws.ToString(in.ClusterName), aws.ToString(in.NodegroupName))
+	if err != nil {
+		return nil, err
+	}
+	if s.inFlight(region, c.Name, ng.Name, "") {
+		return nil, &ekstypes.ResourceInUseException{Message: aws.String("Update already in progress")}
+	}
+	desired, lo, hi := ng.Desired, ng.Min, ng.Max
+	var params []ekstypes.UpdateParam
+	if sc := in.ScalingConfig; sc != nil {
+		if sc.DesiredSize != nil {
+			desired = *sc.DesiredSize
+			params = append(params, ekstypes.UpdateParam{Type: ekstypes.UpdateParamTypeDesiredSize, Value: aws.String(strconv.Itoa(int(desired)))})
+		}
+		if sc.MinSize != nil {
+			lo = *sc.MinSize
+			params = append(params, ekstypes.UpdateParam{Type: ekstypes.UpdateParamTypeMinSize, Value: aws.String(strconv.Itoa(int(lo)))})
+		}
+		if sc.MaxSize != nil {
+			hi = *sc.MaxSize
+			params = append(params, ekstypes.UpdateParam{Type: ekstypes.UpdateParamTypeMaxSize, Value: aws.String(strconv.Itoa(int(hi)))})
+		}
+	}
+	if lo > desired || desired > hi {
+		return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+			fmt.Sprintf("Desired size %d must be within min %d and max %d", desired, lo, hi))}
+	}
+	ng.status = ekstypes.NodegroupStatusUpdating
+	u := s.startUpdate(in.ClientRequestToken, &update{
+		region:    region,
+		cluster:   c.Name,
+		nodegroup: ng.Name,
+		kind:      ekstypes.UpdateTypeConfigUpdate,
+		params:    params,
+		duration:  timing(s.f.Timings.Addon, DefaultAddonDuration),
+		land: func(now time.Time) {
+			ng.Desired, ng.Min, ng.Max, ng.status = desired, lo, hi, ekstypes.NodegroupStatusActive
+			s.resize(c, ng, now)
+		},
+		undo: func() { ng.status = ekstypes.NodegroupStatusActive },
+	}, failure)
+	return &eks.UpdateNodegroupConfigOutput{Update: u.api()}, nil
+}
+
+// resize launches or terminates instances to the nodegroup's desired size.
+func (s *Sim) resize(c *Cluster, ng *Nodegroup, now time.Time) {
+	image := s.f.nodegroupImage(ng)
+	for i := len(ng.Instances); i < int(ng.Desired); i++ {
+		ng.Instances = append(ng.Instances, Instance{
+			ID:         "i-" + hexID(17, c.Region, c.Name, ng.Name, now.String(), strconv.Itoa(i)),
+			ImageID:    image,
+			Zone:       zones(c.Region)[i%3],
+			LaunchTime: now,
+		})
+	}
+	if int(ng.Desired) < len(ng.Instances) {
+		ng.Instances = ng.Instances[:ng.Desired]
+	}
+}
+
+func (s *Sim) updateAddon(region string, in *eks.UpdateAddonInput, failure *Failure) (*eks.UpdateAddonOutput, error) {
+	if u, ok := s.replay(in.ClientRequestToken); ok {
+		return &eks.UpdateAddonOutput{Update: u.api()}, nil
+	}
+	c, a, err := s.addon(region, aws.ToString(in.ClusterName), aws.ToString(in.AddonName))
+	if err != nil {
+		return nil, err
+	}
+	if s.inFlight(region, c.Name, "", a.Name) {
+		return nil, &ekstypes.ResourceInUseException{Message: aws.String("Update already in progress")}
+	}
+	version := aws.ToString(in.AddonVersion)
+	if version == "" {
+		version = a.Version
+	}
+	supported := false
+	for _, v := range s.f.AddonVersions[a.Name] {
+		if v.Version == version && contains(v.Kubernetes, c.Version) {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil, &ekstypes.InvalidParameterException{Message: aws.String(
+			fmt.Sprintf("Addon version specified (%s) is not supported for %s on Kubernetes %s", version, a.Name, c.Version))}
+	}
+	a.Status = ekstypes.AddonStatusUpdating
+	u := s.startUpdate(in.ClientRequestToken, &update{
+		region:   region,
+		cluster:  c.Name,
+		addon:    a.Name,
+		kind:     ekstypes.UpdateTypeAddonUpdate,
+		params:   []ekstypes.UpdateParam{{Type: ekstypes.UpdateParamTypeAddonVersion, Value: aws.String(version)}},
+		duration: timing(s.f.Timings.Addon, DefaultAddonDuration),
+		land:     func(time.Time) { a.Version, a.Status = version, ekstypes.AddonStatusActive },
+		undo:     func() { a.Status = ekstypes.AddonStatusDegraded },
+	}, failure)
+	return &eks.UpdateAddonOutput{Update: u.api()}, nil
+}
+
+// minor returns the minor of a "1.N" version, or -1.
+func minor(v string) int {
+	_, m, ok := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.Atoi(m)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func containsValue[T comparable](list []T, v T) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,453 @@
+package sandbox
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"gopkg.in/yaml.v3"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+)
+
+// Defaults for what a fixture leaves out.
+const (
+	DefaultRegion  = "us-east-1"
+	DefaultAccount = "123456789012"
+
+	defaultAMIType      = ekstypes.AMITypesAl2023X8664Standard
+	defaultInstanceType = "m6i.large"
+	defaultDesired      = 2
+)
+
+// Default update durations: long enough to watch an update progress, short
+// enough to practice a multi-hop upgrade in minutes.
+var (
+	DefaultControlPlaneDuration = 20 * time.Second
+	DefaultAddonDuration        = 5 * time.Second
+	DefaultNodegroupDuration    = 30 * time.Second
+)
+
+// Fixture is the simulated world a sandbox starts from:
+//
+//	region: us-east-1
+//	timings: {controlPlane: 20s, addon: 5s, nodegroup: 30s}
+//	addonVersions:
+//	  coredns:
+//	    - {version: v1.11.4-eksbuild.2, kubernetes: ["1.31", "1.32"], default: ["1.32"]}
+//	amiReleases:
+//	  - {kubernetes: "1.32", release: 1.32.0-20260601}
+//	failures:
+//	  - {operation: UpdateNodegroupVersion, target: gpu, updateStatus: Failed}
+//	clusters:
+//	  - name: prod-east
+//	    version: "1.31"
+//	    tags: {env: prod}
+//	    addons: [{name: coredns, version: v1.11.1-eksbuild.1}]
+//	    nodegroups: [{name: workers, releaseVersion: 1.31.0-20260101, desired: 3}]
+//	    insights: [{name: Deprecated APIs, kubernetesVersion: "1.32", status: WARNING}]
+type Fixture struct {
+	// Account is what STS GetCallerIdentity reports.
+	Account string `yaml:"account,omitempty"`
+	// Region is the default region, and that of clusters that don't name one.
+	Region  string  `yaml:"region,omitempty"`
+	Timings Timings `yaml:"timings,omitempty"`
+	// KubernetesVersions are what DescribeClusterVersions serves, in place of
+	// the built-in support calendar.
+	KubernetesVersions []KubernetesVersion `yaml:"kubernetesVersions,omitempty"`
+	// AddonVersions is the add-on catalogue DescribeAddonVersions serves.
+	AddonVersions map[string][]AddonVersion `yaml:"addonVersions,omitempty"`
+	// AMIReleases are the EKS-optimized AMI releases published in SSM and EC2.
+	AMIReleases []AMIRelease `yaml:"amiReleases,omitempty"`
+	// Metrics maps a CloudWatch metric name to the value GetMetricData
+	// reports for it; other metrics have no data.
+	Metrics  map[string]float64 `yaml:"metrics,omitempty"`
+	Failures []Failure          `yaml:"failures,omitempty"`
+	Clusters []Cluster          `yaml:"clusters"`
+}
+
+// Timings are how long simulated updates stay in progress. Zero completes an
+// update on the next call.
+type Timings struct {
+	ControlPlane *time.Duration `yaml:"controlPlane,omitempty"`
+	Addon        *time.Duration `yaml:"addon,omitempty"`
+	Nodegroup    *time.Duration `yaml:"nodegroup,omitempty"`
+}
+
+// KubernetesVersion is one EKS Kubernetes version and its support window.
+type KubernetesVersion struct {
+	Version              string    `yaml:"version"`
+	EndOfStandardSupport time.Time `yaml:"endOfStandardSupport"`
+	EndOfExtendedSupport time.Time `yaml:"endOfExtendedSupport"`
+}
+
+// AddonVersion is one published version of an add-on: the Kubernetes minors
+// it supports, and those it is the default for.
+type AddonVersion struct {
+	Version    string   `yaml:"version"`
+	Kubernetes []string `yaml:"kubernetes"`
+	Default    []string `yaml:"default,omitempty"`
+}
+
+// AMIRelease is one EKS-optimized AMI release.
+type AMIRelease struct {
+	Kubernetes string            `yaml:"kubernetes"`
+	Release    string            `yaml:"release"`
+	AMIType    ekstypes.AMITypes `yaml:"amiType,omitempty"`
+	// ImageID defaults to one derived from the release.
+	ImageID string `yaml:"imageId,omitempty"`
+}
+
+// Failure injects an error into matching calls, or a failed outcome into the
+// updates they start.
+type Failure struct {
+	// Operation is the API operation name, e.g. UpdateNodegroupVersion.
+	Operation string `yaml:"operation"`
+	// Target limits the failure to calls naming this cluster, nodegroup or
+	// add-on. Empty matches every call.
+	Target string `yaml:"target,omitempty"`
+	// Code fails the call itself with this API error code
+	// (ThrottlingException, AccessDeniedException, ...).
+	Code string `yaml:"code,omitempty"`
+	// UpdateStatus, instead, lets the call start its update and ends the
+	// update with this status (Failed or Cancelled), its change unapplied.
+	UpdateStatus ekstypes.UpdateStatus `yaml:"updateStatus,omitempty"`
+	Message      string                `yaml:"message,omitempty"`
+	// Times is how many matching calls fail; 0 fails every one.
+	Times int `yaml:"times,omitempty"`
+}
+
+// Cluster is one simulated EKS cluster.
+type Cluster struct {
+	Name            string               `yaml:"name"`
+	Region          string               `yaml:"region,omitempty"`
+	Version         string               `yaml:"version"`
+	PlatformVersion string               `yaml:"platformVersion,omitempty"`
+	SupportType     ekstypes.SupportType `yaml:"supportType,omitempty"`
+	Tags            map[string]string    `yaml:"tags,omitempty"`
+	CreatedAt       time.Time            `yaml:"createdAt,omitempty"`
+	Addons          []Addon              `yaml:"addons,omitempty"`
+	Nodegroups      []Nodegroup          `yaml:"nodegroups,omitempty"`
+	Insights        []Insight            `yaml:"insights,omitempty"`
+	// Kubernetes holds the cluster's API objects, for the checks that read
+	// them.
+	Kubernetes *KubeObjects `yaml:"kubernetes,omitempty"`
+
+	status ekstypes.ClusterStatus
+}
+
+// Addon is an installed add-on.
+type Addon struct {
+	Name    string               `yaml:"name"`
+	Version string               `yaml:"version"`
+	Status  ekstypes.AddonStatus `yaml:"status,omitempty"`
+}
+
+// Nodegroup is a managed nodegroup and the EC2 instances behind it.
+type Nodegroup struct {
+	Name           string                 `yaml:"name"`
+	Version        string                 `yaml:"version,omitempty"`
+	ReleaseVersion string                 `yaml:"releaseVersion,omitempty"`
+	AMIType        ekstypes.AMITypes      `yaml:"amiType,omitempty"`
+	InstanceType   string                 `yaml:"instanceType,omitempty"`
+	CapacityType   ekstypes.CapacityTypes `yaml:"capacityType,omitempty"`
+	Desired        int32                  `yaml:"desired,omitempty"`
+	Min            int32                  `yaml:"min,omitempty"`
+	Max            int32                  `yaml:"max,omitempty"`
+	Labels         map[string]string      `yaml:"labels,omitempty"`
+	LaunchTemplate *LaunchTemplate        `yaml:"launchTemplate,omitempty"`
+	// Instances default to desired instances on the nodegroup's AMI,
+	// launched when its release was built.
+	Instances []Instance `yaml:"instances,omitempty"`
+
+	status ekstypes.NodegroupStatus
+}
+
+// LaunchTemplate is the launch template version a nodegroup runs and the
+// image it names; custom-AMI nodegroups run that image.
+type LaunchTemplate struct {
+	ID      string `yaml:"id"`
+	Name    string `yaml:"name,omitempty"`
+	Version string `yaml:"version"`
+	ImageID string `yaml:"imageId,omitempty"`
+}
+
+// Instance is one EC2 instance of a nodegroup.
+type Instance struct {
+	ID         string    `yaml:"id"`
+	ImageID    string    `yaml:"imageId"`
+	Zone       string    `yaml:"zone,omitempty"`
+	LaunchTime time.Time `yaml:"launchTime,omitempty"`
+}
+
+// KubeObjects are Kubernetes API objects as the API serves them (the YAML
+// of kubectl get -o yaml), one list per kind refresh reads.
+type KubeObjects struct {
+	Nodes                []KubeObject `yaml:"nodes,omitempty"`
+	Pods                 []KubeObject `yaml:"pods,omitempty"`
+	PodDisruptionBudgets []KubeObject `yaml:"podDisruptionBudgets,omitempty"`
+	Deployments          []KubeObject `yaml:"deployments,omitempty"`
+}
+
+// KubeObject is one Kubernetes API object.
+type KubeObject map[string]any
+
+// Insight is an EKS upgrade-readiness insight.
+type Insight struct {
+	Name              string                      `yaml:"name"`
+	KubernetesVersion string                      `yaml:"kubernetesVersion"`
+	Status            ekstypes.InsightStatusValue `yaml:"status,omitempty"`
+	Reason            string                      `yaml:"reason,omitempty"`
+	Description       string                      `yaml:"description,omitempty"`
+	Recommendation    string                      `yaml:"recommendation,omitempty"`
+}
+
+// LoadFixture reads and validates a fixture file.
+func LoadFixture(file string) (*Fixture, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if err := f.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &f, nil
+}
+
+// normalize validates the fixture and fills in its defaults.
+func (f *Fixture) normalize() error {
+	if f.Account == "" {
+		f.Account = DefaultAccount
+	}
+	if f.Region == "" {
+		f.Region = DefaultRegion
+	}
+	for i := range f.AMIReleases {
+		r := &f.AMIReleases[i]
+		if r.Kubernetes == "" || r.Release == "" {
+			return fmt.Errorf("amiReleases[%d]: kubernetes and release are required", i)
+		}
+		if r.AMIType == "" {
+			r.AMIType = defaultAMIType
+		}
+		if r.ImageID == "" {
+			r.ImageID = "ami-" + hexID(17, string(r.AMIType), r.Release)
+		}
+	}
+	for name, versions := range f.AddonVersions {
+		for i, v := range versions {
+			if v.Version == "" || len(v.Kubernetes) == 0 {
+				return fmt.Errorf("addonVersions.%s[%d]: version and kubernetes are required", name, i)
+			}
+		}
+	}
+	for i, fl := range f.Failures {
+		if fl.Operation == "" {
+			return fmt.Errorf("failures[%d]: operation is required", i)
+		}
+		if (fl.Code == "") == (fl.UpdateStatus == "") {
+			return fmt.Errorf("failures[%d] (%s): set exactly one of code and updateStatus", i, fl.Operation)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range f.Clusters {
+		c := &f.Clusters[i]
+		label := fmt.Sprintf("clusters[%d]", i)
+		if c.Name == "" || c.Version == "" {
+			return fmt.Errorf("%s: name and version are required", label)
+		}
+		if c.Region == "" {
+			c.Region = f.Region
+		}
+		key := c.Region + "/" + c.Name
+		if seen[key] {
+			return fmt.Errorf("%s: cluster %s is defined twice in %s", label, c.Name, c.Region)
+		}
+		seen[key] = true
+		if c.PlatformVersion == "" {
+			c.PlatformVersion = "eks.1"
+		}
+		if c.SupportType == "" {
+			c.SupportType = ekstypes.SupportTypeStandard
+		}
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		}
+		c.status = ekstypes.ClusterStatusActive
+		for j := range c.Addons {
+			a := &c.Addons[j]
+			if a.Name == "" || a.Version == "" {
+				return fmt.Errorf("%s addons[%d]: name and version are required", label, j)
+			}
+			if a.Status == "" {
+				a.Status = ekstypes.AddonStatusActive
+			}
+		}
+		for j := range c.Insights {
+			if c.Insights[j].Status == "" {
+				c.Insights[j].Status = ekstypes.InsightStatusValuePassing
+			}
+		}
+		if c.Kubernetes != nil {
+			if _, err := c.Kubernetes.decode(); err != nil {
+				return fmt.Errorf("%s: %w", label, err)
+			}
+		}
+		for j := range c.Nodegroups {
+			if err := f.normalizeNodegroup(c, &c.Nodegroups[j]); err != nil {
+				return fmt.Errorf("%s nodegroups[%d]: %w", label, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fixture) normalizeNodegroup(c *Cluster, ng *Nodegroup) error {
+	if ng.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if ng.Version == "" {
+		ng.Version = c.Version
+	}
+	if ng.AMIType == "" {
+		ng.AMIType = defaultAMIType
+	}
+	if ng.ReleaseVersion == "" && ng.AMIType != ekstypes.AMITypesCustom {
+		if r, ok := f.latestRelease(ng.Version, ng.AMIType); ok {
+			ng.ReleaseVersion = r.Release
+		}
+	}
+	if ng.InstanceType == "" {
+		ng.InstanceType = defaultInstanceType
+	}
+	if ng.CapacityType == "" {
+		ng.CapacityType = ekstypes.CapacityTypesOnDemand
+	}
+	if ng.Desired == 0 {
+		ng.Desired = int32(len(ng.Instances))
+	}
+	if ng.Desired == 0 {
+		ng.Desired = defaultDesired
+	}
+	if ng.Min == 0 || ng.Min > ng.Desired {
+		ng.Min = min(1, ng.Desired)
+	}
+	if ng.Max < ng.Desired {
+		ng.Max = 2 * ng.Desired
+	}
+	ng.status = ekstypes.NodegroupStatusActive
+
+	if lt := ng.LaunchTemplate; lt != nil && (lt.ID == "" || lt.Version == "") {
+		return fmt.Errorf("launchTemplate: id and version are required")
+	}
+	if len(ng.Instances) > 0 {
+		for i := range ng.Instances {
+			if ng.Instances[i].ID == "" || ng.Instances[i].ImageID == "" {
+				return fmt.Errorf("instances[%d]: id and imageId are required", i)
+			}
+			if ng.Instances[i].Zone == "" {
+				ng.Instances[i].Zone = zones(c.Region)[i%3]
+			}
+		}
+		return nil
+	}
+
+	image := f.nodegroupImage(ng)
+	launched := c.CreatedAt
+	if r, ok := f.release(ng.Version, ng.ReleaseVersion, ng.AMIType); ok {
+		if built, ok := releaseDate(r.Release); ok {
+			launched = built
+		}
+	}
+	for i := range int(ng.Desired) {
+		ng.Instances = append(ng.Instances, Instance{
+			ID:         "i-" + hexID(17, c.Region, c.Name, ng.Name, fmt.Sprint(i)),
+			ImageID:    image,
+			Zone:       zones(c.Region)[i%3],
+			LaunchTime: launched,
+		})
+	}
+	return nil
+}
+
+// nodegroupImage is the AMI a nodegroup launches: its launch template's for
+// custom AMIs, else that of its release.
+func (f *Fixture) nodegroupImage(ng *Nodegroup) string {
+	if lt := ng.LaunchTemplate; lt != nil && lt.ImageID != "" && ng.AMIType == ekstypes.AMITypesCustom {
+		return lt.ImageID
+	}
+	return f.imageFor(ng.Version, ng.ReleaseVersion, ng.AMIType)
+}
+
+// release returns the published release for a version and AMI type.
+func (f *Fixture) release(k8s, release string, amiType ekstypes.AMITypes) (AMIRelease, bool) {
+	for _, r := range f.AMIReleases {
+		if r.Kubernetes == k8s && r.Release == release && r.AMIType == amiType {
+			return r, true
+		}
+	}
+	return AMIRelease{}, false
+}
+
+// latestRelease returns the newest release published for a version and AMI
+// type.
+func (f *Fixture) latestRelease(k8s string, amiType ekstypes.AMITypes) (AMIRelease, bool) {
+	var latest AMIRelease
+	found := false
+	for _, r := range f.AMIReleases {
+		if r.Kubernetes != k8s || r.AMIType != amiType {
+			continue
+		}
+		if !found || awsinternal.ReleaseStamp(r.Release) > awsinternal.ReleaseStamp(latest.Release) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// imageFor returns the AMI of a release, or a stable made-up one for
+// releases the fixture doesn't publish.
+func (f *Fixture) imageFor(k8s, release string, amiType ekstypes.AMITypes) string {
+	if r, ok := f.release(k8s, release, amiType); ok {
+		return r.ImageID
+	}
+	return "ami-" + hexID(17, string(amiType), k8s, release)
+}
+
+func releaseDate(release string) (time.Time, bool) {
+	t, err := time.Parse("20060102", awsinternal.ReleaseStamp(release))
+	return t, err == nil
+}
+
+// zones are the three availability zones simulated in every region.
+func zones(region string) []string {
+	return []string{region + "a", region + "b", region + "c"}
+}
+
+// hexID derives a stable n-digit hex identifier from parts, so resource IDs
+// are the same on every run.
+func hexID(n int, parts ...string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	s := fmt.Sprintf("%016x", h.Sum64())
+	for len(s) < n {
+		s += fmt.Sprintf("%016x", fnvString(s))
+	}
+	return s[:n]
+}
+
+func fnvString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
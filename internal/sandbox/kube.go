@@ -0,0 +1,69 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// KubeClient returns a Kubernetes client serving the objects of the first
+// cluster that has any — a kubeconfig points at one cluster too — and which
+// cluster that is. ok is false when no cluster has objects.
+func (s *Sim) KubeClient() (client kubernetes.Interface, cluster string, ok bool, err error) {
+	for _, c := range s.f.Clusters {
+		if c.Kubernetes == nil {
+			continue
+		}
+		objects, err := c.Kubernetes.decode()
+		if err != nil {
+			return nil, "", false, fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+		return fake.NewClientset(objects...), c.Name, true, nil
+	}
+	return nil, "", false, nil
+}
+
+// decode converts the objects to their API types, adding the namespaces
+// they live in.
+func (k *KubeObjects) decode() ([]runtime.Object, error) {
+	var out []runtime.Object
+	namespaces := map[string]bool{}
+	add := func(kind string, raw []KubeObject, newObject func() runtime.Object) error {
+		for i, r := range raw {
+			obj := newObject()
+			data, err := json.Marshal(r)
+			if err == nil {
+				err = json.Unmarshal(data, obj)
+			}
+			if err != nil {
+				return fmt.Errorf("kubernetes.%s[%d]: %w", kind, i, err)
+			}
+			if ns := obj.(metav1.Object).GetNamespace(); ns != "" && !namespaces[ns] {
+				namespaces[ns] = true
+				out = append(out, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+			}
+			out = append(out, obj)
+		}
+		return nil
+	}
+	if err := add("nodes", k.Nodes, func() runtime.Object { return &corev1.Node{} }); err != nil {
+		return nil, err
+	}
+	if err := add("pods", k.Pods, func() runtime.Object { return &corev1.Pod{} }); err != nil {
+		return nil, err
+	}
+	if err := add("podDisruptionBudgets", k.PodDisruptionBudgets, func() runtime.Object { return &policyv1.PodDisruptionBudget{} }); err != nil {
+		return nil, err
+	}
+	if err := add("deployments", k.Deployments, func() runtime.Object { return &appsv1.Deployment{} }); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,262 @@
+// Package sandbox is an in-memory simulator of the AWS APIs refresh calls:
+// EKS clusters, nodegroups, add-ons, updates and insights, SSM AMI
+// parameters, EC2 instances and images, Auto Scaling groups and CloudWatch
+// metrics. It is loaded from a YAML fixture and plugs into the SDK clients
+// through aws.Config, so every command runs unchanged against it.
+//
+// The simulation is stateful: an update stays InProgress for the fixture's
+// timings, then lands (the control plane changes version, a nodegroup's
+// instances are replaced onto the new AMI). Failures can be injected per
+// operation and target, either as API errors or as updates that end Failed.
+//
+// Nothing is written back: each process starts from the fixture.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Sim is a running simulation. It is safe for concurrent use.
+type Sim struct {
+	mu      sync.Mutex
+	f       *Fixture
+	now     func() time.Time
+	seq     int
+	updates map[string]*update
+	// tokens maps idempotency tokens to the update they started.
+	tokens map[string]string
+}
+
+// update is one EKS update, in flight or done.
+type update struct {
+	id        string
+	region    string
+	cluster   string
+	nodegroup string
+	addon     string
+	kind      ekstypes.UpdateType
+	params    []ekstypes.UpdateParam
+	started   time.Time
+	duration  time.Duration
+	status    ekstypes.UpdateStatus
+	failure   *Failure
+	// land applies the update's change once it succeeds; roll, when set,
+	// advances it while in progress.
+	land func(now time.Time)
+	roll func(now time.Time, progress float64)
+	// undo restores resource statuses when the update fails.
+	undo func()
+}
+
+// New starts a simulation of the fixture.
+func New(f *Fixture) *Sim {
+	return &Sim{f: f, now: time.Now, updates: map[string]*update{}, tokens: map[string]string{}}
+}
+
+// Load reads a fixture file and starts a simulation of it.
+func Load(file string) (*Sim, error) {
+	f, err := LoadFixture(file)
+	if err != nil {
+		return nil, err
+	}
+	return New(f), nil
+}
+
+// Regions lists the regions the fixture has clusters in, default first.
+func (s *Sim) Regions() []string {
+	out := []string{s.f.Region}
+	seen := map[string]bool{s.f.Region: true}
+	for _, c := range s.f.Clusters {
+		if !seen[c.Region] {
+			seen[c.Region] = true
+			out = append(out, c.Region)
+		}
+	}
+	return out
+}
+
+// Config returns an aws.Config whose clients are served by the simulation.
+// An empty region is the fixture's default.
+func (s *Sim) Config(region string) aws.Config {
+	if region == "" {
+		region = s.f.Region
+	}
+	return aws.Config{
+		Region:      region,
+		Credentials: credentials.NewStaticCredentialsProvider("SANDBOX", "sandbox", ""),
+		APIOptions:  []func(*middleware.Stack) error{s.attach},
+	}
+}
+
+// attach short-circuits a client's middleware stack at the end of the
+// initialize step, after input validation and idempotency tokens: the
+// simulation answers the typed input, and nothing is serialized, signed or
+// sent.
+func (s *Sim) attach(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RefreshSandbox",
+		func(ctx context.Context, in middleware.InitializeInput, _ middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, err := s.serve(ctx, awsmiddleware.GetRegion(ctx), awsmiddleware.GetOperationName(ctx), in.Parameters)
+			return middleware.InitializeOutput{Result: out}, middleware.Metadata{}, err
+		}), middleware.After)
+}
+
+// serve answers one call.
+func (s *Sim) serve(ctx context.Context, region, op string, params any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle()
+
+	failure := s.inject(op, params)
+	if failure != nil && failure.Code != "" {
+		msg := failure.Message
+		if msg == "" {
+			msg = fmt.Sprintf("sandbox: injected %s failure", op)
+		}
+		return nil, &smithy.GenericAPIError{Code: failure.Code, Message: msg}
+	}
+	for _, h := range []func(string, any, *Failure) (any, bool, error){s.serveEKS, s.serveEC2, s.serveSSM, s.serveOther} {
+		if out, ok, err := h(region, params, failure); ok {
+			return out, err
+		}
+	}
+	return nil, &smithy.GenericAPIError{
+		Code:    "UnsupportedOperation",
+		Message: fmt.Sprintf("sandbox: %s is not simulated", op),
+	}
+}
+
+// inject returns the failure the call hits, if any, consuming one of its
+// times.
+func (s *Sim) inject(op string, params any) *Failure {
+	names := subjects(params)
+	for i := range s.f.Failures {
+		fl := s.f.Failures[i]
+		if fl.Operation != op || (fl.Target != "" && !contains(names, fl.Target)) {
+			continue
+		}
+		if fl.Times > 0 {
+			s.f.Failures[i].Times--
+			if s.f.Failures[i].Times == 0 {
+				s.f.Failures = append(s.f.Failures[:i], s.f.Failures[i+1:]...)
+			}
+		}
+		return &fl
+	}
+	return nil
+}
+
+// subjects are the resource names a call's input carries.
+func subjects(params any) []string {
+	v := reflect.Indirect(reflect.ValueOf(params))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var out []string
+	for _, field := range []string{"Name", "ClusterName", "NodegroupName", "AddonName"} {
+		if fv := v.FieldByName(field); fv.IsValid() {
+			if p, ok := fv.Interface().(*string); ok && p != nil {
+				out = append(out, *p)
+			}
+		}
+	}
+	return out
+}
+
+// replay returns the update an earlier call with the same idempotency token
+// started, so a retried request doesn't start a second one.
+func (s *Sim) replay(token *string) (*update, bool) {
+	id, ok := s.tokens[aws.ToString(token)]
+	if !ok {
+		return nil, false
+	}
+	return s.updates[id], true
+}
+
+// startUpdate records a new update.
+func (s *Sim) startUpdate(token *string, u *update, failure *Failure) *update {
+	s.seq++
+	u.id = fmt.Sprintf("%s-%04d", hexID(8, u.region, u.cluster), s.seq)
+	u.started = s.now()
+	u.status = ekstypes.UpdateStatusInProgress
+	if failure != nil && failure.UpdateStatus != "" {
+		u.failure = failure
+	}
+	s.updates[u.id] = u
+	if t := aws.ToString(token); t != "" {
+		s.tokens[t] = u.id
+	}
+	s.settle()
+	return u
+}
+
+// settle advances every in-flight update to the current time.
+func (s *Sim) settle() {
+	now := s.now()
+	for _, u := range s.updates {
+		if u.status != ekstypes.UpdateStatusInProgress {
+			continue
+		}
+		elapsed := now.Sub(u.started)
+		if elapsed < u.duration {
+			if u.roll != nil && u.failure == nil {
+				u.roll(now, float64(elapsed)/float64(u.duration))
+			}
+			continue
+		}
+		if u.failure != nil {
+			u.status = u.failure.UpdateStatus
+			if u.undo != nil {
+				u.undo()
+			}
+			continue
+		}
+		if u.roll != nil {
+			u.roll(now, 1)
+		}
+		if u.land != nil {
+			u.land(now)
+		}
+		u.status = ekstypes.UpdateStatusSuccessful
+	}
+}
+
+// inFlight reports whether an update of the resource is in progress.
+func (s *Sim) inFlight(region, cluster, nodegroup, addon string) bool {
+	for _, u := range s.updates {
+		if u.status == ekstypes.UpdateStatusInProgress && u.region == region && u.cluster == cluster &&
+			u.nodegroup == nodegroup && u.addon == addon {
+			return true
+		}
+	}
+	return false
+}
+
+func timing(d *time.Duration, def time.Duration) time.Duration {
+	if d == nil {
+		return def
+	}
+	return *d
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+)
+
+// clock is a settable time source for the simulation.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newSim(t *testing.T) (*Sim, *clock) {
+	t.Helper()
+	s, err := Load(filepath.Join("testdata", "fleet.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &clock{t: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	s.now = c.now
+	return s, c
+}
+
+func TestLoadFixture_Defaults(t *testing.T) {
+	s, _ := newSim(t)
+	if got := strings.Join(s.Regions(), ","); got != "us-east-1,us-west-2" {
+		t.Errorf("Regions = %s", got)
+	}
+	c := s.f.Clusters[0]
+	if c.PlatformVersion != "eks.1" || c.SupportType != ekstypes.SupportTypeStandard {
+		t.Errorf("cluster defaults = %s %s", c.PlatformVersion, c.SupportType)
+	}
+	gpu := c.Nodegroups[1]
+	if gpu.Version != "1.31" || gpu.ReleaseVersion != "1.31.0-20260601" || len(gpu.Instances) != 1 {
+		t.Errorf("gpu nodegroup = %s %s %d instances", gpu.Version, gpu.ReleaseVersion, len(gpu.Instances))
+	}
+	if staging := s.f.Clusters[1]; staging.Nodegroups[0].ReleaseVersion != "1.31.0-20260601" {
+		t.Errorf("release should default to the latest, got %s", staging.Nodegroups[0].ReleaseVersion)
+	}
+}
+
+func TestLoadFixture_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing version": "clusters: [{name: a}]",
+		"duplicate":       "clusters: [{name: a, version: '1.31'}, {name: a, version: '1.31'}]",
+		"both outcomes":   "failures: [{operation: UpdateAddon, code: X, updateStatus: Failed}]\nclusters: []",
+		"neither outcome": "failures: [{operation: UpdateAddon}]\nclusters: []",
+		"bad release":     "amiReleases: [{kubernetes: '1.31'}]\nclusters: []",
+		"bad template":    "clusters: [{name: a, version: '1.31', nodegroups: [{name: n, launchTemplate: {id: lt-1}}]}]",
+		"bad instance":    "clusters: [{name: a, version: '1.31', nodegroups: [{name: n, instances: [{id: i-1}]}]}]",
+		"bad pod":         "clusters: [{name: a, version: '1.31', kubernetes: {pods: [{spec: {nodeName: 7}}]}}]",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "f.yaml")
+			if err := os.WriteFile(file, []byte(body), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadFixture(file); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestControlPlaneUpdateProgresses(t *testing.T) {
+	s, clk := newSim(t)
+	ctx := context.Background()
+	api := eks.NewFromConfig(s.Config(""))
+
+	up, err := api.UpdateClusterVersion(ctx, &eks.UpdateClusterVersionInput{Name: aws.String("prod-east"), Version: aws.String("1.32")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Update.Status != ekstypes.UpdateStatusInProgress {
+		t.Fatalf("status = %s", up.Update.Status)
+	}
+	desc, _ := api.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String("prod-east")})
+	if desc.Cluster.Status != ekstypes.ClusterStatusUpdating || aws.ToString(desc.Cluster.Version) != "1.31" {
+		t.Errorf("mid-update cluster = %s %s", desc.Cluster.Status, aws.ToString(desc.Cluster.Version))
+	}
+	if _, err := api.UpdateClusterVersion(ctx, &eks.UpdateClusterVersionInput{Name: aws.String("prod-east"), Version: aws.String("1.32")}); err == nil {
+		t.Error("a second update while one is in flight should fail")
+	}
+
+	clk.advance(3 * time.Second)
+	got, err := api.DescribeUpdate(ctx, &eks.DescribeUpdateInput{Name: aws.String("prod-east"), UpdateId: up.Update.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Update.Status != ekstypes.UpdateStatusSuccessful {
+		t.Errorf("status after the timing = %s", got.Update.Status)
+	}
+	desc, _ = api.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String("prod-east")})
+	if desc.Cluster.Status != ekstypes.ClusterStatusActive || aws.ToString(desc.Cluster.Version) != "1.32" {
+		t.Errorf("landed cluster = %s %s", desc.Cluster.Status, aws.ToString(desc.Cluster.Version))
+	}
+
+	_, err = api.UpdateClusterVersion(ctx, &eks.UpdateClusterVersionInput{Name: aws.String("prod-east"), Version: aws.String("1.34")})
+	var invalid *ekstypes.InvalidParameterException
+	if !errors.As(err, &invalid) {
+		t.Errorf("skipping a minor: err = %v, want InvalidParameterException", err)
+	}
+}
+
+func TestNodegroupRollReplacesInstances(t *testing.T) {
+	s, clk := newSim(t)
+	ctx := context.Background()
+	cfg := s.Config("")
+	api, ec2api := eks.NewFromConfig(cfg), ec2.NewFromConfig(cfg)
+	images := func() map[string]int {
+		out, err := ec2api.DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := map[string]int{}
+		for _, r := range out.Reservations {
+			n[aws.ToString(r.Instances[0].ImageId)]++
+		}
+		return n
+	}
+	oldImage := s.f.imageFor("1.31", "1.31.0-20260101", defaultAMIType)
+	newImage := s.f.imageFor("1.31", "1.31.0-20260601", defaultAMIType)
+	if images()[oldImage] != 3 {
+		t.Fatalf("initial images = %v", images())
+	}
+
+	up, err := api.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{
+		ClusterName:        aws.String("prod-east"),
+		NodegroupName:      aws.String("workers"),
+		ClientRequestToken: aws.String("tok"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := api.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{
+		ClusterName:        aws.String("prod-east"),
+		NodegroupName:      aws.String("workers"),
+		ClientRequestToken: aws.String("tok"),
+	})
+	if aws.ToString(again.Update.Id) != aws.ToString(up.Update.Id) {
+		t.Error("the same idempotency token should return the same update")
+	}
+
+	clk.advance(1500 * time.Millisecond)
+	mid := images()
+	if mid[newImage] == 0 || mid[oldImage] == 0 {
+		t.Errorf("halfway through the roll images = %v, want a mix", mid)
+	}
+	clk.advance(2 * time.Second)
+	if done := images(); done[newImage] != 3 {
+		t.Errorf("after the roll images = %v", done)
+	}
+	ng, _ := api.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: aws.String("prod-east"), NodegroupName: aws.String("workers")})
+	if aws.ToString(ng.Nodegroup.ReleaseVersion) != "1.31.0-20260601" || ng.Nodegroup.Status != ekstypes.NodegroupStatusActive {
+		t.Errorf("nodegroup = %s %s", aws.ToString(ng.Nodegroup.ReleaseVersion), ng.Nodegroup.Status)
+	}
+
+	_, err = api.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{
+		ClusterName:   aws.String("prod-east"),
+		NodegroupName: aws.String("workers"),
+		Version:       aws.String("1.32"),
+	})
+	if err == nil {
+		t.Error("a nodegroup newer than its control plane should be rejected")
+	}
+}
+
+func TestInjectedFailures(t *testing.T) {
+	s, clk := newSim(t)
+	s.f.Failures = append(s.f.Failures, Failure{Operation: "DescribeCluster", Target: "prod-east", Code: "ThrottlingException", Times: 1})
+	ctx := context.Background()
+	api := eks.NewFromConfig(s.Config(""), func(o *eks.Options) { o.RetryMaxAttempts = 1 })
+
+	_, err := api.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String("prod-east")})
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ThrottlingException" {
+		t.Fatalf("err = %v, want ThrottlingException", err)
+	}
+	if _, err := api.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String("prod-east")}); err != nil {
+		t.Errorf("a times: 1 failure should clear after one call: %v", err)
+	}
+
+	up, err := api.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{ClusterName: aws.String("prod-east"), NodegroupName: aws.String("gpu")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.advance(time.Minute)
+	got, _ := api.DescribeUpdate(ctx, &eks.DescribeUpdateInput{Name: aws.String("prod-east"), NodegroupName: aws.String("gpu"), UpdateId: up.Update.Id})
+	if got.Update.Status != ekstypes.UpdateStatusFailed || len(got.Update.Errors) != 1 ||
+		!strings.Contains(aws.ToString(got.Update.Errors[0].ErrorMessage), "failed to join") {
+		t.Errorf("update = %s %+v", got.Update.Status, got.Update.Errors)
+	}
+	ng, _ := api.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: aws.String("prod-east"), NodegroupName: aws.String("gpu")})
+	if ng.Nodegroup.Status != ekstypes.NodegroupStatusActive || aws.ToString(ng.Nodegroup.Version) != "1.31" {
+		t.Errorf("failed update should leave the nodegroup as it was: %s %s", ng.Nodegroup.Status, aws.ToString(ng.Nodegroup.Version))
+	}
+
+	// The failure fired once; a retry goes through.
+	up, _ = api.UpdateNodegroupVersion(ctx, &eks.UpdateNodegroupVersionInput{ClusterName: aws.String("prod-east"), NodegroupName: aws.String("gpu")})
+	clk.advance(time.Minute)
+	got, _ = api.DescribeUpdate(ctx, &eks.DescribeUpdateInput{Name: aws.String("prod-east"), NodegroupName: aws.String("gpu"), UpdateId: up.Update.Id})
+	if got.Update.Status != ekstypes.UpdateStatusSuccessful {
+		t.Errorf("retried update = %s", got.Update.Status)
+	}
+}
+
+func TestSSMAndCatalogue(t *testing.T) {
+	s, _ := newSim(t)
+	ctx := context.Background()
+	cfg := s.Config("")
+	ssmapi := ssm.NewFromConfig(cfg)
+
+	releases, err := awsinternal.ReleasesForType(ctx, ssmapi, "1.31", defaultAMIType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[1].ReleaseVersion != "1.31.0-20260601" {
+		t.Errorf("releases = %+v", releases)
+	}
+	if got := awsinternal.LatestReleaseVersionForType(ctx, ssmapi, "1.32", defaultAMIType); got != "1.32.0-20260601" {
+		t.Errorf("latest 1.32 release = %q", got)
+	}
+
+	out, err := eks.NewFromConfig(cfg).DescribeAddonVersions(ctx, &eks.DescribeAddonVersionsInput{
+		AddonName:         aws.String("coredns"),
+		KubernetesVersion: aws.String("1.31"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := out.Addons[0].AddonVersions; len(v) != 2 || aws.ToString(v[0].AddonVersion) != "v1.11.4-eksbuild.2" {
+		t.Errorf("coredns versions for 1.31 should be newest first: %+v", v)
+	}
+
+	id, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil || aws.ToString(id.Account) != DefaultAccount {
+		t.Errorf("caller identity = %v, %v", id, err)
+	}
+
+	west := eks.NewFromConfig(s.Config("us-west-2"))
+	list, _ := west.ListClusters(ctx, &eks.ListClustersInput{})
+	if strings.Join(list.Clusters, ",") != "staging-west" {
+		t.Errorf("us-west-2 clusters = %v", list.Clusters)
+	}
+}
+
+const capturedFixture = `
+clusters:
+  - name: prod
+    version: "1.31"
+    nodegroups:
+      - name: custom
+        amiType: CUSTOM
+        launchTemplate: {id: lt-0abc, name: golden, version: "4", imageId: ami-0golden}
+        instances:
+          - {id: i-0aaa, imageId: ami-0golden, zone: us-east-1b}
+          - {id: i-0bbb, imageId: ami-0older}
+    kubernetes:
+      nodes:
+        - metadata: {name: ip-10-0-0-1, labels: {eks.amazonaws.com/nodegroup: custom}}
+      pods:
+        - metadata: {name: web-1, namespace: shop}
+          spec: {nodeName: ip-10-0-0-1}
+`
+
+func TestCapturedNodegroupsAndKubernetes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "f.yaml")
+	if err := os.WriteFile(file, []byte(capturedFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	cfg := s.Config("")
+
+	ng, err := eks.NewFromConfig(cfg).DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: aws.String("prod"), NodegroupName: aws.String("custom")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lt := ng.Nodegroup.LaunchTemplate
+	if lt == nil || aws.ToString(lt.Id) != "lt-0abc" || aws.ToString(lt.Version) != "4" {
+		t.Fatalf("launch template = %+v", lt)
+	}
+	if got := aws.ToInt32(ng.Nodegroup.ScalingConfig.DesiredSize); got != 2 {
+		t.Errorf("desired should default to the instance count, got %d", got)
+	}
+
+	ec2api := ec2.NewFromConfig(cfg)
+	versions, err := ec2api.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{LaunchTemplateName: aws.String("golden"), Versions: []string{"4"}})
+	if err != nil || len(versions.LaunchTemplateVersions) != 1 || aws.ToString(versions.LaunchTemplateVersions[0].LaunchTemplateData.ImageId) != "ami-0golden" {
+		t.Errorf("launch template versions = %+v, %v", versions, err)
+	}
+	instances, err := ec2api.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-0bbb"}})
+	if err != nil || len(instances.Reservations) != 1 || aws.ToString(instances.Reservations[0].Instances[0].ImageId) != "ami-0older" {
+		t.Errorf("instances = %+v, %v", instances, err)
+	}
+
+	client, cluster, ok, err := s.KubeClient()
+	if err != nil || !ok || cluster != "prod" {
+		t.Fatalf("KubeClient = %v %q %v", ok, cluster, err)
+	}
+	pods, err := client.CoreV1().Pods("shop").List(ctx, metav1.ListOptions{})
+	if err != nil || len(pods.Items) != 1 || pods.Items[0].Spec.NodeName != "ip-10-0-0-1" {
+		t.Errorf("pods = %+v, %v", pods, err)
+	}
+	if _, err := client.CoreV1().Namespaces().Get(ctx, "shop", metav1.GetOptions{}); err != nil {
+		t.Errorf("the pod's namespace should exist: %v", err)
+	}
+
+	if _, _, ok, _ := New(&Fixture{}).KubeClient(); ok {
+		t.Error("a fixture without objects has no Kubernetes client")
+	}
+}
@@ -0,0 +1,249 @@
+// Package selector parses kubectl-style label selectors and matches them
+// against EKS cluster tags, so fleet commands can pick clusters by their
+// env/team/tier tags instead of their names:
+//
+//	env=prod,team!=data
+//	tier in (web,api),!experimental
+//
+// Requirements are comma-separated and all must hold. Unlike Kubernetes label
+// selectors, keys and values may use any character EKS tags allow except the
+// selector's own punctuation, so "aws:cloudformation:stack-name=core" works.
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operator is how a requirement compares a tag.
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is one comma-separated term of a selector.
+type Requirement struct {
+	Key    string
+	Op     Operator
+	Values []string
+}
+
+// Matches reports whether tags satisfy the requirement. As in Kubernetes, !=
+// and notin also match clusters without the tag at all.
+func (r Requirement) Matches(tags map[string]string) bool {
+	v, ok := tags[r.Key]
+	switch r.Op {
+	case Equals, In:
+		return ok && contains(r.Values, v)
+	case NotEquals, NotIn:
+		return !ok || !contains(r.Values, v)
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Op {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case In, NotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Op, strings.Join(r.Values, ","))
+	}
+	return r.Key + string(r.Op) + r.Values[0]
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector is a conjunction of requirements. The zero Selector matches every
+// cluster.
+type Selector struct {
+	reqs []Requirement
+}
+
+// Empty reports whether the selector selects everything.
+func (s Selector) Empty() bool { return len(s.reqs) == 0 }
+
+// Requirements returns the selector's terms, sorted by key.
+func (s Selector) Requirements() []Requirement { return s.reqs }
+
+// Matches reports whether tags satisfy every requirement.
+func (s Selector) Matches(tags map[string]string) bool {
+	for _, r := range s.reqs {
+		if !r.Matches(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// Positive returns the terms a tag index can answer: =, in and bare-key
+// existence. The rest (!=, notin, !key) also match clusters without the tag,
+// so only a cluster's own tags can settle them.
+func (s Selector) Positive() []Requirement {
+	var reqs []Requirement
+	for _, r := range s.reqs {
+		switch r.Op {
+		case Equals, In, Exists:
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// And returns a selector requiring both s and o.
+func (s Selector) And(o Selector) Selector {
+	reqs := append(append([]Requirement{}, s.reqs...), o.reqs...)
+	sortRequirements(reqs)
+	return Selector{reqs: reqs}
+}
+
+// String renders the selector in a canonical form Parse reads back.
+func (s Selector) String() string {
+	parts := make([]string, len(s.reqs))
+	for i, r := range s.reqs {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Parse reads a selector: comma-separated requirements of the forms
+//
+//	key=value  key==value  key!=value
+//	key in (v1,v2)  key notin (v1,v2)
+//	key  !key
+//
+// An empty string parses to the empty selector.
+func Parse(s string) (Selector, error) {
+	var reqs []Requirement
+	for _, term := range splitTerms(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return Selector{}, fmt.Errorf("selector %q: empty requirement", s)
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return Selector{}, fmt.Errorf("selector %q: %w", s, err)
+		}
+		reqs = append(reqs, r)
+	}
+	sortRequirements(reqs)
+	return Selector{reqs: reqs}, nil
+}
+
+// splitTerms splits on the commas outside parentheses.
+func splitTerms(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		key := strings.TrimSpace(term[1:])
+		if err := checkToken("key", key); err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Op: DoesNotExist}, nil
+	}
+	for _, op := range []struct {
+		sep string
+		op  Operator
+	}{{"!=", NotEquals}, {"==", Equals}, {"=", Equals}} {
+		if key, value, ok := strings.Cut(term, op.sep); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if err := checkToken("key", key); err != nil {
+				return Requirement{}, err
+			}
+			if err := checkValue(value); err != nil {
+				return Requirement{}, err
+			}
+			return Requirement{Key: key, Op: op.op, Values: []string{value}}, nil
+		}
+	}
+	if open := strings.Index(term, "("); open >= 0 {
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 || (fields[1] != string(In) && fields[1] != string(NotIn)) {
+			return Requirement{}, fmt.Errorf("%q: want 'key in (a,b)' or 'key notin (a,b)'", term)
+		}
+		if !strings.HasSuffix(term, ")") {
+			return Requirement{}, fmt.Errorf("%q: missing ')'", term)
+		}
+		if err := checkToken("key", fields[0]); err != nil {
+			return Requirement{}, err
+		}
+		var values []string
+		for _, v := range strings.Split(term[open+1:len(term)-1], ",") {
+			v = strings.TrimSpace(v)
+			if err := checkValue(v); err != nil {
+				return Requirement{}, err
+			}
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		return Requirement{Key: fields[0], Op: Operator(fields[1]), Values: values}, nil
+	}
+	if err := checkToken("key", term); err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: term, Op: Exists}, nil
+}
+
+// reserved are the characters a key or value can't contain.
+const reserved = "=!,() \t"
+
+func checkToken(what, tok string) error {
+	if tok == "" {
+		return fmt.Errorf("empty %s", what)
+	}
+	if strings.ContainsAny(tok, reserved) {
+		return fmt.Errorf("%s %q: contains one of = ! , ( ) or a space", what, tok)
+	}
+	return nil
+}
+
+// checkValue allows an empty value (a tag set to ""), unlike a key.
+func checkValue(v string) error {
+	if strings.ContainsAny(v, reserved) {
+		return fmt.Errorf("value %q: contains one of = ! , ( ) or a space", v)
+	}
+	return nil
+}
+
+func sortRequirements(reqs []Requirement) {
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[i].Key < reqs[j].Key })
+}
@@ -0,0 +1,80 @@
+package selector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAndMatch(t *testing.T) {
+	tags := map[string]string{"env": "prod", "team": "web", "tier": "api", "aws:cloudformation:stack-name": "core"}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=dev", false},
+		{"env!=dev", true},
+		{"owner!=me", true}, // absent tags satisfy !=
+		{"env=prod,team!=web", false},
+		{"tier in (web, api)", true},
+		{"tier notin (web,api)", false},
+		{"owner notin (a)", true},
+		{"team", true},
+		{"owner", false},
+		{"!owner", true},
+		{"!env", false},
+		{"aws:cloudformation:stack-name=core", true},
+		{"env=prod, tier in (api,web), !legacy", true},
+	}
+	for _, c := range cases {
+		sel, err := Parse(c.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.expr, err)
+			continue
+		}
+		if got := sel.Matches(tags); got != c.want {
+			t.Errorf("%q matches = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for expr, want := range map[string]string{
+		"env=prod,":        "empty requirement",
+		"=prod":            "empty key",
+		"env=a b":          "value",
+		"tier in (a,b":     "missing ')'",
+		"tier among (a,b)": "want 'key in (a,b)'",
+		"!env=prod":        "key",
+	} {
+		if _, err := Parse(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) err = %v, want it to mention %q", expr, err, want)
+		}
+	}
+}
+
+func TestStringRoundTripAndAnd(t *testing.T) {
+	sel, err := Parse("tier in (web,api), env=prod,!legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "env=prod,!legacy,tier in (api,web)"
+	if sel.String() != want {
+		t.Fatalf("String() = %q, want %q", sel.String(), want)
+	}
+	again, err := Parse(sel.String())
+	if err != nil || again.String() != want {
+		t.Errorf("round trip = %q, %v", again.String(), err)
+	}
+
+	team, _ := Parse("team=web")
+	both := sel.And(team)
+	if len(both.Requirements()) != 4 || both.Matches(map[string]string{"env": "prod", "tier": "web"}) {
+		t.Errorf("And = %s", both)
+	}
+	if !(Selector{}).Empty() || sel.Empty() {
+		t.Error("Empty() wrong")
+	}
+}
@@ -0,0 +1,168 @@
+package addons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+)
+
+// GetCompatibility fetches every published version of an addon with the
+// Kubernetes versions each supports, in one unfiltered DescribeAddonVersions
+// sweep, where GetAvailableVersions answers one Kubernetes version at a time.
+func (s *ServiceImpl) GetCompatibility(ctx context.Context, addonName string) (*CompatibilityMatrix, error) {
+	infos, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("describing versions for addon %s", addonName),
+		func(rc context.Context, token *string) (*eks.DescribeAddonVersionsOutput, error) {
+			return s.eksClient.DescribeAddonVersions(rc, &eks.DescribeAddonVersionsInput{AddonName: aws.String(addonName), NextToken: token})
+		},
+		func(out *eks.DescribeAddonVersionsOutput) ([]ekstypes.AddonInfo, *string) {
+			return out.Addons, out.NextToken
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing addon versions: %w", err)
+	}
+	cm := &CompatibilityMatrix{
+		AddonName:       addonName,
+		Versions:        map[string][]string{},
+		DefaultVersions: map[string]string{},
+	}
+	for _, info := range infos {
+		for _, v := range info.AddonVersions {
+			version := aws.ToString(v.AddonVersion)
+			if version == "" {
+				continue
+			}
+			for _, c := range v.Compatibilities {
+				k8s := aws.ToString(c.ClusterVersion)
+				if k8s == "" {
+					continue
+				}
+				cm.Versions[version] = append(cm.Versions[version], k8s)
+				if c.DefaultVersion {
+					cm.DefaultVersions[k8s] = version
+				}
+			}
+		}
+	}
+	if len(cm.Versions) == 0 {
+		return nil, fmt.Errorf("no versions found for addon %s", addonName)
+	}
+	return cm, nil
+}
+
+// Supports reports whether addonVersion is published for k8sVersion.
+func (m *CompatibilityMatrix) Supports(addonVersion, k8sVersion string) bool {
+	for _, v := range m.Versions[addonVersion] {
+		if v == k8sVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// Latest returns the newest addon version for k8sVersion, or "" for a gap.
+func (m *CompatibilityMatrix) Latest(k8sVersion string) string {
+	latest := ""
+	for version := range m.Versions {
+		if m.Supports(version, k8sVersion) && (latest == "" || compareAddonVersions(version, latest) > 0) {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// NewestKubernetes returns the newest Kubernetes minor any version of any of
+// the addons supports.
+func NewestKubernetes(compat []*CompatibilityMatrix) string {
+	newest, newestMinor := "", -1
+	for _, m := range compat {
+		for _, k8s := range m.Versions {
+			for _, v := range k8s {
+				if n, ok := kubernetesMinor(v); ok && n > newestMinor {
+					newest, newestMinor = v, n
+				}
+			}
+		}
+	}
+	return newest
+}
+
+// KubernetesRange lists the "1.N" minors from..to inclusive.
+func KubernetesRange(from, to string) ([]string, error) {
+	lo, ok := kubernetesMinor(from)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a Kubernetes minor version like 1.30", from)
+	}
+	hi, ok := kubernetesMinor(to)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a Kubernetes minor version like 1.34", to)
+	}
+	if lo > hi {
+		return nil, fmt.Errorf("from %s is newer than to %s", from, to)
+	}
+	out := make([]string, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		out = append(out, "1."+strconv.Itoa(n))
+	}
+	return out, nil
+}
+
+// ShiftMinor returns the Kubernetes minor delta minors from v ("1.34", -4 →
+// "1.30"), or "" when v isn't a minor version.
+func ShiftMinor(v string, delta int) string {
+	n, ok := kubernetesMinor(v)
+	if !ok || n+delta < 0 {
+		return ""
+	}
+	return "1." + strconv.Itoa(n+delta)
+}
+
+// kubernetesMinor extracts N from "1.N" (or "v1.N.x").
+func kubernetesMinor(v string) (int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// BuildAddonMatrix lays the compatibility data out per Kubernetes version.
+// installed maps addon name to its installed version; for installed addons it
+// walks the versions in order, carrying the running version forward and
+// marking each minor where it stops being compatible and has to move.
+func BuildAddonMatrix(compat []*CompatibilityMatrix, installed map[string]string, k8sVersions []string) []MatrixRow {
+	rows := make([]MatrixRow, 0, len(compat))
+	for _, cm := range compat {
+		row := MatrixRow{Addon: cm.AddonName, Installed: installed[cm.AddonName]}
+		running := row.Installed
+		for _, k8s := range k8sVersions {
+			cell := MatrixCell{KubernetesVersion: k8s, Latest: cm.Latest(k8s), Default: cm.DefaultVersions[k8s]}
+			cell.Gap = cell.Latest == ""
+			if running != "" {
+				if !cm.Supports(running, k8s) {
+					cell.MustChange = true
+					if !cell.Gap {
+						running = cell.Latest
+					}
+				}
+				cell.Running = running
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Addon < rows[j].Addon })
+	return rows
+}
@@ -0,0 +1,152 @@
+package addons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+const (
+	// compatCacheFileName holds cached addon compatibility beside the
+	// context file.
+	compatCacheFileName = "addon-versions.json"
+	// CompatCacheTTL is how long cached compatibility is served before it is
+	// fetched again. New addon builds land a few times a month, so a day is
+	// plenty fresh for planning.
+	CompatCacheTTL = 24 * time.Hour
+)
+
+// compatEntry is one cached addon in one region.
+type compatEntry struct {
+	FetchedAt time.Time            `json:"fetchedAt"`
+	Matrix    *CompatibilityMatrix `json:"matrix"`
+}
+
+// CompatCache is the on-disk addon compatibility cache. Entries are keyed by
+// region and addon, since marketplace addons differ between regions.
+type CompatCache struct {
+	path    string
+	now     func() time.Time
+	Entries map[string]compatEntry `json:"entries"`
+	dirty   bool
+}
+
+// CompatCachePath returns the cache location: addon-versions.json beside the
+// context file.
+func CompatCachePath() (string, error) {
+	dir, err := cliconfig.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, compatCacheFileName), nil
+}
+
+// LoadCompatCache reads the cache at path. A missing or unreadable file is an
+// empty cache: it only ever saves API calls.
+func LoadCompatCache(path string) *CompatCache {
+	c := &CompatCache{path: path, now: time.Now, Entries: map[string]compatEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if json.Unmarshal(data, c) != nil || c.Entries == nil {
+		c.Entries = map[string]compatEntry{}
+	}
+	return c
+}
+
+func compatKey(region, addon string) string { return region + "/" + addon }
+
+// Get returns the cached matrix for addon in region when it's younger than
+// CompatCacheTTL.
+func (c *CompatCache) Get(region, addon string) (*CompatibilityMatrix, bool) {
+	e, ok := c.Entries[compatKey(region, addon)]
+	if !ok || e.Matrix == nil || c.now().Sub(e.FetchedAt) > CompatCacheTTL {
+		return nil, false
+	}
+	return e.Matrix, true
+}
+
+// Put caches a freshly fetched matrix.
+func (c *CompatCache) Put(region string, m *CompatibilityMatrix) {
+	c.Entries[compatKey(region, m.AddonName)] = compatEntry{FetchedAt: c.now(), Matrix: m}
+	c.dirty = true
+}
+
+// Save writes the cache if anything changed, atomically (temp + rename).
+func (c *CompatCache) Save() error {
+	if !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".addon-versions-*.json")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, c.path); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// CachedCompatibility returns the compatibility of each named addon, from
+// cache when fresh (and refresh is false), else fetched in parallel and
+// cached. Addons that can't be fetched come back nil with their error.
+func (s *ServiceImpl) CachedCompatibility(ctx context.Context, cache *CompatCache, region string, names []string, refresh bool) ([]*CompatibilityMatrix, []error) {
+	out := make([]*CompatibilityMatrix, len(names))
+	errs := make([]error, len(names))
+	var missing []int
+	for i, name := range names {
+		if m, ok := cache.Get(region, name); ok && !refresh {
+			out[i] = m
+			continue
+		}
+		missing = append(missing, i)
+	}
+	type fetched struct {
+		m   *CompatibilityMatrix
+		err error
+	}
+	results := common.ForEachParallel(ctx, missing, common.DefaultItemConcurrency, func(fctx context.Context, i int) fetched {
+		m, err := s.GetCompatibility(fctx, names[i])
+		return fetched{m, err}
+	})
+	for k, i := range missing {
+		r := results[k]
+		switch {
+		case r.err != nil:
+			errs[i] = r.err
+		case r.m == nil:
+			// ForEachParallel leaves items unstarted once ctx is cancelled.
+			errs[i] = fmt.Errorf("addon %s: not fetched: %v", names[i], ctx.Err())
+		default:
+			out[i] = r.m
+			cache.Put(region, r.m)
+		}
+	}
+	return out, errs
+}
@@ -0,0 +1,166 @@
+package addons
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/mocks"
+)
+
+func compatVersion(v string, defaults []string, k8s ...string) ekstypes.AddonVersionInfo {
+	info := ekstypes.AddonVersionInfo{AddonVersion: aws.String(v)}
+	for _, k := range k8s {
+		c := ekstypes.Compatibility{ClusterVersion: aws.String(k)}
+		for _, d := range defaults {
+			c.DefaultVersion = c.DefaultVersion || d == k
+		}
+		info.Compatibilities = append(info.Compatibilities, c)
+	}
+	return info
+}
+
+func matrixEKS() *mocks.EKSAPI {
+	return &mocks.EKSAPI{
+		DescribeAddonVersionsFn: func(_ context.Context, in *eks.DescribeAddonVersionsInput, _ ...func(*eks.Options)) (*eks.DescribeAddonVersionsOutput, error) {
+			return &eks.DescribeAddonVersionsOutput{Addons: []ekstypes.AddonInfo{{
+				AddonName: in.AddonName,
+				AddonVersions: []ekstypes.AddonVersionInfo{
+					compatVersion("v1.10.0", []string{"1.30"}, "1.30", "1.31"),
+					compatVersion("v1.11.0", []string{"1.31", "1.32"}, "1.31", "1.32"),
+					compatVersion("v1.12.0", nil, "1.32", "1.33"),
+				},
+			}}}, nil
+		},
+	}
+}
+
+func TestGetCompatibility(t *testing.T) {
+	s := NewService(matrixEKS(), nil)
+	cm, err := s.GetCompatibility(context.Background(), "coredns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.Latest("1.32"); got != "v1.12.0" {
+		t.Errorf("Latest(1.32) = %q, want v1.12.0", got)
+	}
+	if got := cm.Latest("1.34"); got != "" {
+		t.Errorf("Latest(1.34) = %q, want a gap", got)
+	}
+	if got := cm.DefaultVersions["1.31"]; got != "v1.11.0" {
+		t.Errorf("default for 1.31 = %q, want v1.11.0", got)
+	}
+	if !cm.Supports("v1.10.0", "1.31") || cm.Supports("v1.10.0", "1.32") {
+		t.Errorf("Supports is wrong for v1.10.0: %v", cm.Versions["v1.10.0"])
+	}
+	if got := NewestKubernetes([]*CompatibilityMatrix{cm}); got != "1.33" {
+		t.Errorf("NewestKubernetes = %q, want 1.33", got)
+	}
+}
+
+func TestKubernetesRange(t *testing.T) {
+	got, err := KubernetesRange("1.30", "v1.33")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1.30", "1.31", "1.32", "1.33"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KubernetesRange = %v, want %v", got, want)
+	}
+	for _, bad := range [][2]string{{"1.33", "1.30"}, {"latest", "1.30"}, {"1.30", "2.0"}} {
+		if _, err := KubernetesRange(bad[0], bad[1]); err == nil {
+			t.Errorf("KubernetesRange(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+	if got := ShiftMinor("1.34.2", -4); got != "1.30" {
+		t.Errorf("ShiftMinor = %q, want 1.30", got)
+	}
+}
+
+func TestBuildAddonMatrix(t *testing.T) {
+	cm, err := NewService(matrixEKS(), nil).GetCompatibility(context.Background(), "coredns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k8s, _ := KubernetesRange("1.30", "1.34")
+	rows := BuildAddonMatrix([]*CompatibilityMatrix{cm}, map[string]string{"coredns": "v1.10.0"}, k8s)
+	if len(rows) != 1 || rows[0].Installed != "v1.10.0" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	cells := rows[0].Cells
+	type want struct {
+		running    string
+		mustChange bool
+		gap        bool
+	}
+	for i, w := range []want{
+		{"v1.10.0", false, false},
+		{"v1.10.0", false, false},
+		{"v1.12.0", true, false}, // v1.10.0 isn't published for 1.32
+		{"v1.12.0", false, false},
+		{"v1.12.0", true, true}, // nothing for 1.34
+	} {
+		c := cells[i]
+		if c.Running != w.running || c.MustChange != w.mustChange || c.Gap != w.gap {
+			t.Errorf("%s: got running=%q mustChange=%v gap=%v, want %+v", c.KubernetesVersion, c.Running, c.MustChange, c.Gap, w)
+		}
+	}
+
+	// Not installed: no running version, nothing must change.
+	rows = BuildAddonMatrix([]*CompatibilityMatrix{cm}, nil, k8s)
+	for _, c := range rows[0].Cells {
+		if c.Running != "" || c.MustChange {
+			t.Errorf("uninstalled %s: %+v", c.KubernetesVersion, c)
+		}
+	}
+}
+
+func TestCompatCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), compatCacheFileName)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	eksAPI := matrixEKS()
+	s := NewService(eksAPI, nil)
+
+	cache := LoadCompatCache(path)
+	cache.now = func() time.Time { return now }
+	got, errs := s.CachedCompatibility(context.Background(), cache, "us-west-2", []string{"coredns", "vpc-cni"}, false)
+	if got[0] == nil || got[1] == nil || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("first fetch: %v %v", got, errs)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if n := eksAPI.Calls.DescribeAddonVersions; n != 2 {
+		t.Fatalf("DescribeAddonVersions calls = %d, want 2", n)
+	}
+
+	// Reloaded and fresh: served from disk.
+	cache = LoadCompatCache(path)
+	cache.now = func() time.Time { return now.Add(time.Hour) }
+	if _, ok := cache.Get("us-west-2", "coredns"); !ok {
+		t.Fatal("fresh entry not served from disk")
+	}
+	if _, ok := cache.Get("eu-west-1", "coredns"); ok {
+		t.Fatal("entry served for another region")
+	}
+	s.CachedCompatibility(context.Background(), cache, "us-west-2", []string{"coredns"}, false)
+	if n := eksAPI.Calls.DescribeAddonVersions; n != 2 {
+		t.Fatalf("cached fetch called the API: %d calls", n)
+	}
+	// --refresh fetches regardless.
+	s.CachedCompatibility(context.Background(), cache, "us-west-2", []string{"coredns"}, true)
+	if n := eksAPI.Calls.DescribeAddonVersions; n != 3 {
+		t.Fatalf("refresh calls = %d, want 3", n)
+	}
+
+	// Stale after the TTL.
+	cache.now = func() time.Time { return now.Add(CompatCacheTTL + 2*time.Hour) }
+	if _, ok := cache.Get("us-west-2", "vpc-cni"); ok {
+		t.Fatal("stale entry served")
+	}
+}
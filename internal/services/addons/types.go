@@ -93,3 +93,35 @@ type CompatibilityMatrix struct {
 	Versions        map[string][]string `json:"versions"`        // addon version -> k8s versions
 	DefaultVersions map[string]string   `json:"defaultVersions"` // k8s version -> default addon version
 }
+
+// MatrixCell is one addon at one Kubernetes minor in an AddonMatrix.
+type MatrixCell struct {
+	KubernetesVersion string `json:"kubernetesVersion" yaml:"kubernetesVersion"`
+	Latest            string `json:"latest,omitempty" yaml:"latest,omitempty"`
+	Default           string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Gap means no published version supports this Kubernetes version.
+	Gap bool `json:"gap,omitempty" yaml:"gap,omitempty"`
+	// MustChange means the version carried in from the previous minor (the
+	// installed one, at first) doesn't support this one, so the addon has to
+	// move here. Running is the version after that move: the latest
+	// compatible, as the upgrade orchestrator picks.
+	MustChange bool   `json:"mustChange,omitempty" yaml:"mustChange,omitempty"`
+	Running    string `json:"running,omitempty" yaml:"running,omitempty"`
+}
+
+// MatrixRow is one addon across the matrix's Kubernetes versions.
+type MatrixRow struct {
+	Addon     string       `json:"addon" yaml:"addon"`
+	Installed string       `json:"installed,omitempty" yaml:"installed,omitempty"`
+	Cells     []MatrixCell `json:"cells" yaml:"cells"`
+	Error     string       `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// AddonMatrix is the `addon matrix` result: latest and default compatible
+// versions per addon per Kubernetes minor.
+type AddonMatrix struct {
+	Cluster            string      `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	ClusterVersion     string      `json:"clusterVersion,omitempty" yaml:"clusterVersion,omitempty"`
+	KubernetesVersions []string    `json:"kubernetesVersions" yaml:"kubernetesVersions"`
+	Addons             []MatrixRow `json:"addons" yaml:"addons"`
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/selector"
 	"github.com/dantech2000/refresh/internal/services/common"
 )
 
@@ -206,9 +208,24 @@ func filterSummaries(summaries []ClusterSummary, filters map[string]string) []Cl
 	return out
 }
 
+// selectTagged narrows names to the clusters the tag index says can match
+// sel, so the rest are never described. Each kept cluster's tags are still
+// checked once it's described; without the index (no =, in or existence
+// terms, or no tag:GetResources permission) every name is kept.
+func (s *ServiceImpl) selectTagged(ctx context.Context, names []string, sel selector.Selector) []string {
+	if sel.Empty() {
+		return names
+	}
+	if kept, ok := awsinternal.TaggedClusters(ctx, s.taggingClient, names, sel); ok {
+		return kept
+	}
+	return names
+}
+
 // getClusterSummary creates a summary for a single cluster. On describe
 // failure it returns a minimal "UNKNOWN" summary so callers can still render a
-// complete list -- never returns an error.
+// complete list -- never returns an error. It returns nil for a cluster whose
+// tags don't match options.Selector.
 func (s *ServiceImpl) getClusterSummary(ctx context.Context, clusterName string, options ListOptions) *ClusterSummary {
 	output, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
 		return s.eksClient.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(clusterName)})
@@ -226,6 +243,9 @@ func (s *ServiceImpl) getClusterSummary(ctx context.Context, clusterName string,
 	}
 
 	cluster := output.Cluster
+	if !options.Selector.Matches(cluster.Tags) {
+		return nil
+	}
 	summary := &ClusterSummary{
 		Name:      aws.ToString(cluster.Name),
 		Status:    string(cluster.Status),
@@ -253,9 +273,30 @@ func (s *ServiceImpl) getClusterSummary(ctx context.Context, clusterName string,
 	}
 
 	if options.ShowHealth && s.healthChecker != nil {
-		healthSummary := s.healthChecker.RunAllChecks(ctx, clusterName)
-		summary.Health = &healthSummary
+		h, err := s.clusterHealth(ctx, clusterName, options.HealthOptions)
+		if err != nil {
+			s.logger.Warn("skipping health for summary", "cluster", clusterName, "error", err)
+		}
+		summary.Health = h
 	}
 
 	return summary
 }
+
+// clusterHealth runs the health checks against clusterName with the options
+// resolve returns for it (the defaults when resolve is nil). The options go on
+// a copy of the shared checker, so concurrent clusters don't see each other's.
+func (s *ServiceImpl) clusterHealth(ctx context.Context, clusterName string, resolve func(string) (health.Options, error)) (*health.HealthSummary, error) {
+	hc := s.healthChecker
+	if resolve != nil {
+		opts, err := resolve(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		if hc, err = hc.WithOptions(opts); err != nil {
+			return nil, err
+		}
+	}
+	summary := hc.RunAllChecks(ctx, clusterName)
+	return &summary, nil
+}
@@ -84,6 +84,9 @@ type UpgradeCheckOptions struct {
 	Category    string   // default UPGRADE_READINESS
 	Statuses    []string // optional status filter (PASSING/WARNING/ERROR/UNKNOWN)
 	ShowPassing bool     // include PASSING insights (hidden by default)
+	// KubernetesVersions narrows insights to those about upgrading to these
+	// versions (e.g. every hop on the way to an --to target).
+	KubernetesVersions []string
 }
 
 // NodegroupSkew is a managed nodegroup's Kubernetes version relative to the
@@ -130,7 +133,10 @@ func (s *ServiceImpl) ListInsights(ctx context.Context, clusterName string, opts
 	if category == "" {
 		category = string(ekstypes.CategoryUpgradeReadiness)
 	}
-	filter := &ekstypes.InsightsFilter{Categories: []ekstypes.Category{ekstypes.Category(category)}}
+	filter := &ekstypes.InsightsFilter{
+		Categories:         []ekstypes.Category{ekstypes.Category(category)},
+		KubernetesVersions: opts.KubernetesVersions,
+	}
 	for _, st := range opts.Statuses {
 		filter.Statuses = append(filter.Statuses, ekstypes.InsightStatusValue(strings.ToUpper(strings.TrimSpace(st))))
 	}
@@ -310,9 +316,34 @@ func (s *ServiceImpl) UpgradeCheck(ctx context.Context, clusterName string, opts
 // computeSkew builds the local version-skew report and ordered findings.
 func (s *ServiceImpl) computeSkew(ctx context.Context, clusterName, cpVersion string) (SkewReport, error) {
 	report := SkewReport{ControlPlaneVersion: cpVersion}
-	cpMinor, cpOK := minorVersion(cpVersion)
 
-	// Nodegroups.
+	nodegroups, err := s.nodegroupSkews(ctx, clusterName, cpVersion)
+	if err != nil {
+		return report, err
+	}
+	report.Nodegroups = nodegroups
+
+	installed, err := s.installedAddons(ctx, clusterName)
+	if err != nil {
+		return report, err
+	}
+	for _, skew := range installed {
+		latest, lerr := s.latestAddonVersion(ctx, skew.Name, cpVersion)
+		skew.Latest = latest
+		if lerr == nil && latest != "" && skew.Installed != "" && addons.CompareVersions(skew.Installed, latest) < 0 {
+			skew.Behind = true
+		}
+		report.Addons = append(report.Addons, skew)
+	}
+
+	report.Findings = skewFindings(report)
+	return report, nil
+}
+
+// nodegroupSkews measures each managed nodegroup against the control-plane
+// version. Nodegroups that can't be described are skipped.
+func (s *ServiceImpl) nodegroupSkews(ctx context.Context, clusterName, cpVersion string) ([]NodegroupSkew, error) {
+	cpMinor, cpOK := minorVersion(cpVersion)
 	ngNames, err := awsinternal.ListAllPages(ctx, "listing nodegroups",
 		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
 			return s.eksClient.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName), NextToken: token})
@@ -320,8 +351,9 @@ func (s *ServiceImpl) computeSkew(ctx context.Context, clusterName, cpVersion st
 		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
 	)
 	if err != nil {
-		return report, err
+		return nil, err
 	}
+	var skews []NodegroupSkew
 	for _, name := range ngNames {
 		ngDesc, derr := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
 			return s.eksClient.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)})
@@ -340,10 +372,14 @@ func (s *ServiceImpl) computeSkew(ctx context.Context, clusterName, cpVersion st
 				skew.Blocking = skew.MinorsBehind >= kubeletSkewLimit
 			}
 		}
-		report.Nodegroups = append(report.Nodegroups, skew)
+		skews = append(skews, skew)
 	}
+	return skews, nil
+}
 
-	// Addons.
+// installedAddons returns each installed addon with its installed version
+// (Latest and Behind unset). Addons that can't be described are skipped.
+func (s *ServiceImpl) installedAddons(ctx context.Context, clusterName string) ([]AddonSkew, error) {
 	addonNames, err := awsinternal.ListAllPages(ctx, "listing addons",
 		func(rc context.Context, token *string) (*eks.ListAddonsOutput, error) {
 			return s.eksClient.ListAddons(rc, &eks.ListAddonsInput{ClusterName: aws.String(clusterName), NextToken: token})
@@ -351,8 +387,9 @@ func (s *ServiceImpl) computeSkew(ctx context.Context, clusterName, cpVersion st
 		func(out *eks.ListAddonsOutput) ([]string, *string) { return out.Addons, out.NextToken },
 	)
 	if err != nil {
-		return report, err
+		return nil, err
 	}
+	var installed []AddonSkew
 	for _, name := range addonNames {
 		adDesc, derr := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeAddonOutput, error) {
 			return s.eksClient.DescribeAddon(rc, &eks.DescribeAddonInput{ClusterName: aws.String(clusterName), AddonName: aws.String(name)})
@@ -360,17 +397,9 @@ func (s *ServiceImpl) computeSkew(ctx context.Context, clusterName, cpVersion st
 		if derr != nil || adDesc == nil || adDesc.Addon == nil {
 			continue
 		}
-		installed := aws.ToString(adDesc.Addon.AddonVersion)
-		latest, lerr := s.latestAddonVersion(ctx, name, cpVersion)
-		skew := AddonSkew{Name: name, Installed: installed, Latest: latest}
-		if lerr == nil && latest != "" && installed != "" && addons.CompareVersions(installed, latest) < 0 {
-			skew.Behind = true
-		}
-		report.Addons = append(report.Addons, skew)
+		installed = append(installed, AddonSkew{Name: name, Installed: aws.ToString(adDesc.Addon.AddonVersion)})
 	}
-
-	report.Findings = skewFindings(report)
-	return report, nil
+	return installed, nil
 }
 
 // latestAddonVersion returns the newest addon version compatible with the given
@@ -21,6 +21,7 @@ type fakeEKSClient struct {
 	clustersPages   [][]string
 	nodegroupsPages map[string][][]string
 	addonsPages     map[string][][]string
+	tags            map[string]map[string]string
 }
 
 func (f *fakeEKSClient) ListClusters(ctx context.Context, in *eks.ListClustersInput, _ ...func(*eks.Options)) (*eks.ListClustersOutput, error) {
@@ -50,7 +51,7 @@ func (f *fakeEKSClient) DescribeCluster(ctx context.Context, in *eks.DescribeClu
 		Version:         aws.String("1.27"),
 		PlatformVersion: aws.String("eks.1"),
 		Endpoint:        aws.String("https://example"),
-		Tags:            map[string]string{},
+		Tags:            f.tags[name],
 		ResourcesVpcConfig: &eksTypes.VpcConfigResponse{
 			EndpointPublicAccess:  true,
 			EndpointPrivateAccess: false,
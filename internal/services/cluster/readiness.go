@@ -0,0 +1,314 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/selector"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// ReadinessState is a cluster's verdict in a fleet upgrade-readiness check.
+type ReadinessState string
+
+const (
+	// ReadinessBlocked means something must be fixed before the upgrade: an
+	// ERROR insight, a nodegroup beyond the kubelet skew limit of the target,
+	// or an addon with no version for the target.
+	ReadinessBlocked ReadinessState = "blocked"
+	// ReadinessWarning means WARNING insights, or a check that couldn't run.
+	ReadinessWarning ReadinessState = "warning"
+	ReadinessReady   ReadinessState = "ready"
+	// ReadinessCurrent means the cluster is already at (or past) the target.
+	ReadinessCurrent ReadinessState = "current"
+	// ReadinessError means the cluster couldn't be described at all.
+	ReadinessError ReadinessState = "error"
+)
+
+// stateRank orders states worst-first for sorting.
+var stateRank = map[ReadinessState]int{
+	ReadinessBlocked: 0,
+	ReadinessError:   1,
+	ReadinessWarning: 2,
+	ReadinessReady:   3,
+	ReadinessCurrent: 4,
+}
+
+// ClusterReadiness is one row of the fleet upgrade-readiness matrix.
+type ClusterReadiness struct {
+	Cluster string `json:"cluster" yaml:"cluster"`
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+	Region  string `json:"region,omitempty" yaml:"region,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Target  string `json:"target,omitempty" yaml:"target,omitempty"`
+	// Hops is how many minor upgrades reaching Target takes.
+	Hops  int            `json:"hops" yaml:"hops"`
+	State ReadinessState `json:"state" yaml:"state"`
+	// Blocking are the ERROR insights for any hop up to Target.
+	Blocking []InsightSummary `json:"blocking,omitempty" yaml:"blocking,omitempty"`
+	// Warnings are the WARNING insights for any hop up to Target.
+	Warnings []InsightSummary `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	// SkewViolations are nodegroups that would trail a Target control plane
+	// by more than the kubelet skew limit, so they must be rolled on the way.
+	// Their MinorsBehind is measured against Target.
+	SkewViolations []NodegroupSkew `json:"skewViolations,omitempty" yaml:"skewViolations,omitempty"`
+	// IncompatibleAddons have no published version for Target.
+	IncompatibleAddons []AddonSkew `json:"incompatibleAddons,omitempty" yaml:"incompatibleAddons,omitempty"`
+	// Notes are checks that couldn't run (e.g. insights access denied).
+	Notes []string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Error string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Ref identifies the cluster across accounts and regions, e.g.
+// "prod/us-east-1/web".
+func (c ClusterReadiness) Ref() string {
+	var parts []string
+	for _, p := range []string{c.Account, c.Region, c.Cluster} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// FleetReadinessOptions scopes a fleet upgrade-readiness check.
+type FleetReadinessOptions struct {
+	// Target is the Kubernetes version to check against ("1.33"). Empty
+	// checks each cluster against its own next minor version.
+	Target string
+	// NamePattern keeps clusters whose name contains it.
+	NamePattern string
+	Selector    selector.Selector
+}
+
+// RegionReadiness checks the matching clusters of the service's own region,
+// for callers that fan out over accounts and regions themselves.
+func (s *ServiceImpl) RegionReadiness(ctx context.Context, opts FleetReadinessOptions) ([]ClusterReadiness, error) {
+	return s.regionReadiness(ctx, s.awsConfig.Region, opts)
+}
+
+// regionReadiness checks the matching clusters of one region.
+func (s *ServiceImpl) regionReadiness(ctx context.Context, region string, opts FleetReadinessOptions) ([]ClusterReadiness, error) {
+	names, err := awsinternal.ListAllPages(ctx, "listing clusters",
+		func(rc context.Context, token *string) (*eks.ListClustersOutput, error) {
+			return s.eksClient.ListClusters(rc, &eks.ListClustersInput{NextToken: token})
+		},
+		func(out *eks.ListClustersOutput) ([]string, *string) { return out.Clusters, out.NextToken },
+	)
+	if err != nil {
+		return nil, err
+	}
+	var selected []string
+	for _, name := range names {
+		if !s.shouldSkipCluster(name, map[string]string{"name": opts.NamePattern}) {
+			selected = append(selected, name)
+		}
+	}
+	selected = s.selectTagged(ctx, selected, opts.Selector)
+
+	checked := common.ForEachParallel(ctx, selected, common.DefaultItemConcurrency,
+		func(fctx context.Context, name string) *ClusterReadiness {
+			return s.clusterReadiness(fctx, name, opts.Target, opts.Selector)
+		})
+	var rows []ClusterReadiness
+	for _, r := range checked {
+		if r != nil {
+			r.Region = region
+			rows = append(rows, *r)
+		}
+	}
+	return rows, nil
+}
+
+// clusterReadiness checks one cluster against target. It returns nil for a
+// cluster whose tags don't match sel; a cluster that can't be described is
+// kept as an error row, as in the cluster list.
+func (s *ServiceImpl) clusterReadiness(ctx context.Context, clusterName, target string, sel selector.Selector) *ClusterReadiness {
+	row := &ClusterReadiness{Cluster: clusterName, Target: target}
+	desc, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
+		return s.eksClient.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(clusterName)})
+	})
+	if err != nil {
+		row.State = ReadinessError
+		row.Error = awsinternal.FormatAWSError(err, "describing cluster").Error()
+		return row
+	}
+	if desc == nil || desc.Cluster == nil {
+		row.State = ReadinessError
+		row.Error = "cluster not found"
+		return row
+	}
+	if !sel.Empty() && !sel.Matches(desc.Cluster.Tags) {
+		return nil
+	}
+	row.Version = aws.ToString(desc.Cluster.Version)
+
+	cpMinor, ok := minorVersion(row.Version)
+	if !ok {
+		row.State = ReadinessError
+		row.Error = fmt.Sprintf("unrecognized Kubernetes version %q", row.Version)
+		return row
+	}
+	if row.Target == "" {
+		row.Target = fmt.Sprintf("1.%d", cpMinor+1)
+	}
+	targetMinor, ok := minorVersion(row.Target)
+	if !ok {
+		row.State = ReadinessError
+		row.Error = fmt.Sprintf("unrecognized target version %q", row.Target)
+		return row
+	}
+	if targetMinor <= cpMinor {
+		row.State = ReadinessCurrent
+		return row
+	}
+	row.Hops = targetMinor - cpMinor
+
+	// Insights are published per destination version, so ask for every hop:
+	// a 1.31 cluster going to 1.33 must clear the 1.32 checks first.
+	var hops []string
+	for m := cpMinor + 1; m <= targetMinor; m++ {
+		hops = append(hops, fmt.Sprintf("1.%d", m))
+	}
+	insights, err := s.ListInsights(ctx, clusterName, UpgradeCheckOptions{KubernetesVersions: hops})
+	if err != nil {
+		row.Notes = append(row.Notes, fmt.Sprintf("insights unavailable: %v", err))
+	}
+	for _, in := range insights {
+		switch strings.ToUpper(in.Status) {
+		case InsightStatusError:
+			row.Blocking = append(row.Blocking, in)
+		case InsightStatusWarning:
+			row.Warnings = append(row.Warnings, in)
+		}
+	}
+
+	nodegroups, err := s.nodegroupSkews(ctx, clusterName, row.Version)
+	if err != nil {
+		row.Notes = append(row.Notes, fmt.Sprintf("nodegroup skew unavailable: %v", err))
+	}
+	for _, ng := range nodegroups {
+		if ngMinor, ok := minorVersion(ng.Version); ok && targetMinor-ngMinor > kubeletSkewLimit {
+			ng.MinorsBehind, ng.Blocking = targetMinor-ngMinor, true
+			row.SkewViolations = append(row.SkewViolations, ng)
+		}
+	}
+
+	installed, err := s.installedAddons(ctx, clusterName)
+	if err != nil {
+		row.Notes = append(row.Notes, fmt.Sprintf("addons unavailable: %v", err))
+	}
+	for _, a := range installed {
+		latest, lerr := s.latestAddonVersion(ctx, a.Name, row.Target)
+		switch {
+		case lerr != nil:
+			row.Notes = append(row.Notes, fmt.Sprintf("addon %s: %v", a.Name, lerr))
+		case latest == "":
+			row.IncompatibleAddons = append(row.IncompatibleAddons, a)
+		}
+	}
+
+	switch {
+	case len(row.Blocking) > 0 || len(row.SkewViolations) > 0 || len(row.IncompatibleAddons) > 0:
+		row.State = ReadinessBlocked
+	case len(row.Warnings) > 0 || len(row.Notes) > 0:
+		row.State = ReadinessWarning
+	default:
+		row.State = ReadinessReady
+	}
+	return row
+}
+
+// InsightGroup is one insight as it shows up across the fleet.
+type InsightGroup struct {
+	Name     string   `json:"name" yaml:"name"`
+	Status   string   `json:"status" yaml:"status"`
+	Clusters []string `json:"clusters" yaml:"clusters"`
+}
+
+// ReadinessCounts tallies clusters per state.
+type ReadinessCounts struct {
+	Blocked int `json:"blocked" yaml:"blocked"`
+	Warning int `json:"warning" yaml:"warning"`
+	Ready   int `json:"ready" yaml:"ready"`
+	Current int `json:"current" yaml:"current"`
+	Error   int `json:"error" yaml:"error"`
+}
+
+// FleetReadinessReport is the `cluster upgrade-check -A` result: a row per
+// cluster plus each blocking or warning insight grouped across clusters.
+type FleetReadinessReport struct {
+	Target   string             `json:"target,omitempty" yaml:"target,omitempty"`
+	Counts   ReadinessCounts    `json:"counts" yaml:"counts"`
+	Clusters []ClusterReadiness `json:"clusters" yaml:"clusters"`
+	Insights []InsightGroup     `json:"insights,omitempty" yaml:"insights,omitempty"`
+	Warnings []string           `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// NewFleetReadinessReport sorts rows worst-first and groups identical
+// insights (same name and status) across clusters, most widespread first
+// within ERROR then WARNING.
+func NewFleetReadinessReport(target string, rows []ClusterReadiness, warnings []string) *FleetReadinessReport {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if stateRank[rows[i].State] != stateRank[rows[j].State] {
+			return stateRank[rows[i].State] < stateRank[rows[j].State]
+		}
+		return rows[i].Ref() < rows[j].Ref()
+	})
+
+	report := &FleetReadinessReport{Target: target, Clusters: rows, Warnings: warnings}
+	groups := map[string]*InsightGroup{}
+	var order []*InsightGroup
+	add := func(ref string, in InsightSummary) {
+		key := strings.ToUpper(in.Status) + "\x00" + in.Name
+		g, ok := groups[key]
+		if !ok {
+			g = &InsightGroup{Name: in.Name, Status: strings.ToUpper(in.Status)}
+			groups[key] = g
+			order = append(order, g)
+		}
+		// The same insight can repeat per hop; list each cluster once.
+		if n := len(g.Clusters); n == 0 || g.Clusters[n-1] != ref {
+			g.Clusters = append(g.Clusters, ref)
+		}
+	}
+	for _, r := range rows {
+		switch r.State {
+		case ReadinessBlocked:
+			report.Counts.Blocked++
+		case ReadinessWarning:
+			report.Counts.Warning++
+		case ReadinessReady:
+			report.Counts.Ready++
+		case ReadinessCurrent:
+			report.Counts.Current++
+		case ReadinessError:
+			report.Counts.Error++
+		}
+		for _, in := range r.Blocking {
+			add(r.Ref(), in)
+		}
+		for _, in := range r.Warnings {
+			add(r.Ref(), in)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].Status != order[j].Status {
+			return order[i].Status == InsightStatusError
+		}
+		if len(order[i].Clusters) != len(order[j].Clusters) {
+			return len(order[i].Clusters) > len(order[j].Clusters)
+		}
+		return order[i].Name < order[j].Name
+	})
+	for _, g := range order {
+		report.Insights = append(report.Insights, *g)
+	}
+	return report
+}
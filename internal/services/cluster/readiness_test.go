@@ -0,0 +1,150 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/mocks"
+	"github.com/dantech2000/refresh/internal/selector"
+)
+
+// readinessMock serves clusters at the given versions and tags, each with one
+// nodegroup at ngVersion and a vpc-cni addon published only up to 1.32.
+func readinessMock(versions map[string]string, tags map[string]map[string]string, ngVersion string, insights []ekstypes.InsightSummary, gotVersions *[]string) *mocks.EKSAPI {
+	return &mocks.EKSAPI{
+		ListClustersFn: func(_ context.Context, _ *eks.ListClustersInput, _ ...func(*eks.Options)) (*eks.ListClustersOutput, error) {
+			var names []string
+			for n := range versions {
+				names = append(names, n)
+			}
+			return &eks.ListClustersOutput{Clusters: names}, nil
+		},
+		DescribeClusterFn: func(_ context.Context, in *eks.DescribeClusterInput, _ ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+			name := aws.ToString(in.Name)
+			return &eks.DescribeClusterOutput{Cluster: &ekstypes.Cluster{Name: in.Name, Version: aws.String(versions[name]), Tags: tags[name]}}, nil
+		},
+		ListInsightsFn: func(_ context.Context, in *eks.ListInsightsInput, _ ...func(*eks.Options)) (*eks.ListInsightsOutput, error) {
+			if gotVersions != nil {
+				*gotVersions = in.Filter.KubernetesVersions
+			}
+			return &eks.ListInsightsOutput{Insights: insights}, nil
+		},
+		ListNodegroupsFn: func(_ context.Context, _ *eks.ListNodegroupsInput, _ ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+			return &eks.ListNodegroupsOutput{Nodegroups: []string{"workers"}}, nil
+		},
+		DescribeNodegroupFn: func(_ context.Context, _ *eks.DescribeNodegroupInput, _ ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+			return &eks.DescribeNodegroupOutput{Nodegroup: &ekstypes.Nodegroup{Version: aws.String(ngVersion)}}, nil
+		},
+		ListAddonsFn: func(_ context.Context, _ *eks.ListAddonsInput, _ ...func(*eks.Options)) (*eks.ListAddonsOutput, error) {
+			return &eks.ListAddonsOutput{Addons: []string{"vpc-cni"}}, nil
+		},
+		DescribeAddonFn: func(_ context.Context, _ *eks.DescribeAddonInput, _ ...func(*eks.Options)) (*eks.DescribeAddonOutput, error) {
+			return &eks.DescribeAddonOutput{Addon: &ekstypes.Addon{AddonName: aws.String("vpc-cni"), AddonVersion: aws.String("v1.18.0")}}, nil
+		},
+		DescribeAddonVersionsFn: func(_ context.Context, in *eks.DescribeAddonVersionsInput, _ ...func(*eks.Options)) (*eks.DescribeAddonVersionsOutput, error) {
+			if m, _ := minorVersion(aws.ToString(in.KubernetesVersion)); m > 32 {
+				return &eks.DescribeAddonVersionsOutput{}, nil
+			}
+			return &eks.DescribeAddonVersionsOutput{Addons: []ekstypes.AddonInfo{{
+				AddonVersions: []ekstypes.AddonVersionInfo{{AddonVersion: aws.String("v1.19.0")}},
+			}}}, nil
+		},
+	}
+}
+
+func insight(name string, status ekstypes.InsightStatusValue) ekstypes.InsightSummary {
+	return ekstypes.InsightSummary{
+		Id:            aws.String(name),
+		Name:          aws.String(name),
+		Category:      ekstypes.CategoryUpgradeReadiness,
+		InsightStatus: &ekstypes.InsightStatus{Status: status},
+	}
+}
+
+func TestClusterReadiness(t *testing.T) {
+	ctx := context.Background()
+
+	// 1.31 → 1.33: insights for both hops, a nodegroup four minors behind
+	// the target and an addon with nothing published for 1.33.
+	var hops []string
+	svc := &ServiceImpl{eksClient: readinessMock(map[string]string{"web": "1.31"}, nil, "1.29",
+		[]ekstypes.InsightSummary{insight("Deprecated APIs", ekstypes.InsightStatusValueError), insight("Kubelet skew", ekstypes.InsightStatusValueWarning)}, &hops)}
+	row := svc.clusterReadiness(ctx, "web", "1.33", selector.Selector{})
+	if row.State != ReadinessBlocked || row.Hops != 2 {
+		t.Fatalf("state = %s, hops = %d", row.State, row.Hops)
+	}
+	if !reflect.DeepEqual(hops, []string{"1.32", "1.33"}) {
+		t.Errorf("insight versions = %v, want both hops", hops)
+	}
+	if len(row.Blocking) != 1 || len(row.Warnings) != 1 {
+		t.Errorf("insights = %+v / %+v", row.Blocking, row.Warnings)
+	}
+	if len(row.SkewViolations) != 1 || row.SkewViolations[0].MinorsBehind != 4 {
+		t.Errorf("skew = %+v", row.SkewViolations)
+	}
+	if len(row.IncompatibleAddons) != 1 || row.IncompatibleAddons[0].Installed != "v1.18.0" {
+		t.Errorf("addons = %+v", row.IncompatibleAddons)
+	}
+
+	// Default target is the next minor: nothing blocks 1.31 → 1.32.
+	svc = &ServiceImpl{eksClient: readinessMock(map[string]string{"web": "1.31"}, nil, "1.30", nil, nil)}
+	if row = svc.clusterReadiness(ctx, "web", "", selector.Selector{}); row.State != ReadinessReady || row.Target != "1.32" {
+		t.Errorf("next-minor row = %+v", row)
+	}
+	if row = svc.clusterReadiness(ctx, "web", "1.31", selector.Selector{}); row.State != ReadinessCurrent {
+		t.Errorf("at-target state = %s, want current", row.State)
+	}
+}
+
+func TestRegionReadiness_FiltersBySelectorAndName(t *testing.T) {
+	svc := &ServiceImpl{eksClient: readinessMock(
+		map[string]string{"web-prod": "1.31", "web-dev": "1.31", "batch-prod": "1.31"},
+		map[string]map[string]string{"web-prod": {"env": "prod"}, "web-dev": {"env": "dev"}, "batch-prod": {"env": "prod"}},
+		"1.31", nil, nil)}
+	sel, err := selector.Parse("env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := svc.regionReadiness(context.Background(), "us-east-1", FleetReadinessOptions{Target: "1.32", NamePattern: "web", Selector: sel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Cluster != "web-prod" || rows[0].Region != "us-east-1" {
+		t.Errorf("rows = %+v", rows)
+	}
+}
+
+func TestNewFleetReadinessReport_GroupsInsights(t *testing.T) {
+	deprecated := InsightSummary{Name: "Deprecated APIs", Status: InsightStatusError}
+	addonCompat := InsightSummary{Name: "Addon compatibility", Status: InsightStatusWarning}
+	rows := []ClusterReadiness{
+		{Cluster: "c", Region: "us-east-1", State: ReadinessReady},
+		{Cluster: "a", Region: "us-east-1", State: ReadinessBlocked, Blocking: []InsightSummary{deprecated, deprecated}},
+		{Cluster: "b", Region: "eu-west-1", State: ReadinessBlocked, Blocking: []InsightSummary{deprecated}, Warnings: []InsightSummary{addonCompat}},
+		{Cluster: "d", Region: "us-east-1", State: ReadinessError, Error: "AccessDenied"},
+	}
+	r := NewFleetReadinessReport("1.33", rows, nil)
+
+	var order []string
+	for _, c := range r.Clusters {
+		order = append(order, c.Ref())
+	}
+	if want := []string{"eu-west-1/b", "us-east-1/a", "us-east-1/d", "us-east-1/c"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	want := []InsightGroup{
+		{Name: "Deprecated APIs", Status: InsightStatusError, Clusters: []string{"eu-west-1/b", "us-east-1/a"}},
+		{Name: "Addon compatibility", Status: InsightStatusWarning, Clusters: []string{"eu-west-1/b"}},
+	}
+	if !reflect.DeepEqual(r.Insights, want) {
+		t.Errorf("groups = %+v", r.Insights)
+	}
+	if r.Counts != (ReadinessCounts{Blocked: 2, Ready: 1, Error: 1}) {
+		t.Errorf("counts = %+v", r.Counts)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
 	appconfig "github.com/dantech2000/refresh/internal/config"
@@ -41,6 +42,7 @@ type ServiceImpl struct {
 	ec2Client     *ec2.Client
 	iamClient     *iam.Client
 	stsClient     *sts.Client
+	taggingClient awsinternal.TaggingAPI // narrows a selector's clusters; nil describes each one
 	healthChecker *health.HealthChecker
 	cache         *Cache
 	logger        *slog.Logger
@@ -68,6 +70,7 @@ func NewService(awsConfig aws.Config, healthChecker *health.HealthChecker, logge
 		ec2Client:     ec2.NewFromConfig(awsConfig),
 		iamClient:     iam.NewFromConfig(awsConfig),
 		stsClient:     sts.NewFromConfig(awsConfig),
+		taggingClient: resourcegroupstaggingapi.NewFromConfig(awsConfig),
 		healthChecker: healthChecker,
 		cache:         NewCache(defaultCacheTTLDescribe),
 		logger:        logger,
@@ -90,9 +93,10 @@ func buildListCacheKey(options ListOptions) string {
 		filterParts[i] = k + "=" + options.Filters[k]
 	}
 
-	return fmt.Sprintf("list-regions=%s|filters=%s|showHealth=%t|allRegions=%t",
+	return fmt.Sprintf("list-regions=%s|filters=%s|selector=%s|showHealth=%t|allRegions=%t",
 		strings.Join(regions, ","),
 		strings.Join(filterParts, ";"),
+		options.Selector,
 		options.ShowHealth,
 		options.AllRegions,
 	)
@@ -226,8 +230,11 @@ func (s *ServiceImpl) Describe(ctx context.Context, name string, options Describ
 
 	// Add health information if requested
 	if options.ShowHealth && s.healthChecker != nil {
-		healthSummary := s.healthChecker.RunAllChecks(ctx, name)
-		details.Health = &healthSummary
+		h, err := s.clusterHealth(ctx, name, options.HealthOptions)
+		if err != nil {
+			return nil, err
+		}
+		details.Health = h
 	}
 
 	// Cache the result
@@ -265,6 +272,7 @@ func (s *ServiceImpl) List(ctx context.Context, options ListOptions) ([]ClusterS
 			selected = append(selected, clusterName)
 		}
 	}
+	selected = s.selectTagged(ctx, selected, options.Selector)
 
 	// Each summary costs a DescribeCluster + nodegroup describes; fan out with
 	// bounded concurrency instead of paying the per-cluster latency serially.
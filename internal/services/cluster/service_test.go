@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/dantech2000/refresh/internal/mocks"
+	"github.com/dantech2000/refresh/internal/selector"
 )
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -171,6 +172,34 @@ func TestList_FilterByNameExcludesNonMatching(t *testing.T) {
 	}
 }
 
+func TestList_SelectorMatchesClusterTags(t *testing.T) {
+	fake := &fakeEKSClient{
+		clustersPages:   [][]string{{"prod-web", "prod-data", "dev"}},
+		nodegroupsPages: map[string][][]string{"prod-web": {{}}, "prod-data": {{}}, "dev": {{}}},
+		tags: map[string]map[string]string{
+			"prod-web":  {"env": "prod", "team": "web"},
+			"prod-data": {"env": "prod", "team": "data"},
+			"dev":       {"env": "dev"},
+		},
+	}
+	svc := newTestServiceWithFake(t, fake)
+
+	sel, err := selector.Parse("env=prod,team!=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries, err := svc.List(context.Background(), ListOptions{Selector: sel})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Name != "prod-web" {
+		t.Fatalf("selector kept %v, want [prod-web]", names(summaries))
+	}
+	if buildListCacheKey(ListOptions{Selector: sel}) == buildListCacheKey(ListOptions{}) {
+		t.Error("selector should be part of the cache key")
+	}
+}
+
 func TestList_NodeCountUsesManagedNodegroupTotals(t *testing.T) {
 	fake := &fakeEKSClient{
 		clustersPages: [][]string{{"staging-blue"}},
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/selector"
 	"github.com/dantech2000/refresh/internal/services/status"
 )
 
@@ -51,6 +52,7 @@ type ClusterSummary struct {
 	Status    string                `json:"status"`
 	Version   string                `json:"version"`
 	Region    string                `json:"region"`
+	Account   string                `json:"account,omitempty"`
 	Health    *health.HealthSummary `json:"health,omitempty"`
 	NodeCount NodeCountInfo         `json:"nodeCount"`
 	CreatedAt time.Time             `json:"createdAt"`
@@ -125,6 +127,9 @@ type DescribeOptions struct {
 	ShowSecurity  bool `json:"showSecurity"`
 	IncludeAddons bool `json:"includeAddons"`
 	Detailed      bool `json:"detailed"`
+	// HealthOptions resolves the health thresholds and check selection for
+	// the cluster; nil runs the checks with health.DefaultOptions.
+	HealthOptions func(cluster string) (health.Options, error) `json:"-"`
 }
 
 // ListOptions controls cluster listing behavior
@@ -134,4 +139,10 @@ type ListOptions struct {
 	Filters        map[string]string `json:"filters"`
 	AllRegions     bool              `json:"allRegions"`
 	MaxConcurrency int               `json:"maxConcurrency"`
+	// Selector keeps only clusters whose EKS tags match; they're read from
+	// the DescribeCluster each summary already makes.
+	Selector selector.Selector `json:"-"`
+	// HealthOptions resolves each cluster's health thresholds and check
+	// selection for ShowHealth; nil runs the checks with the defaults.
+	HealthOptions func(cluster string) (health.Options, error) `json:"-"`
 }
@@ -0,0 +1,239 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// LimiterConfig tunes a RateLimiter. Each service and region gets its own
+// token bucket: AWS throttles per account, service and region, so EKS
+// throttling in us-east-1 shouldn't slow EC2 or eu-west-1.
+type LimiterConfig struct {
+	// Rate is the steady-state requests per second a bucket allows, and the
+	// ceiling it recovers to.
+	Rate float64
+	// Burst is how many requests may go at once after a quiet spell.
+	Burst float64
+	// MinRate is the floor repeated throttling can push a bucket down to.
+	MinRate float64
+	// Decrease multiplies the rate when a call is throttled.
+	Decrease float64
+	// Increase is added to the rate, in requests per second, for each call
+	// that isn't throttled.
+	Increase float64
+	// Cooldown is how long after a decrease further throttles are only
+	// counted: the requests already in flight when the first one was
+	// throttled shouldn't each halve the rate again.
+	Cooldown time.Duration
+}
+
+// DefaultLimiterConfig sits under the documented EKS and EC2 describe limits
+// so a fleet-wide fan-out rarely throttles at all.
+var DefaultLimiterConfig = LimiterConfig{
+	Rate:     10,
+	Burst:    20,
+	MinRate:  0.5,
+	Decrease: 0.5,
+	Increase: 0.2,
+	Cooldown: time.Second,
+}
+
+// RateLimiter is an adaptive (AIMD) token-bucket limiter over AWS calls,
+// keyed by service and region. It is safe for concurrent use.
+type RateLimiter struct {
+	cfg     LimiterConfig
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	now     func() time.Time
+}
+
+type bucketKey struct{ service, region string }
+
+type bucket struct {
+	rate         float64
+	tokens       float64
+	last         time.Time
+	lastDecrease time.Time
+	calls        int
+	throttles    int
+	lowest       float64
+	waited       time.Duration
+}
+
+// NewRateLimiter returns a limiter; zero fields of cfg take the defaults.
+func NewRateLimiter(cfg LimiterConfig) *RateLimiter {
+	d := DefaultLimiterConfig
+	if cfg.Rate <= 0 {
+		cfg.Rate = d.Rate
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = d.Burst
+	}
+	if cfg.MinRate <= 0 || cfg.MinRate > cfg.Rate {
+		cfg.MinRate = min(d.MinRate, cfg.Rate)
+	}
+	if cfg.Decrease <= 0 || cfg.Decrease >= 1 {
+		cfg.Decrease = d.Decrease
+	}
+	if cfg.Increase <= 0 {
+		cfg.Increase = d.Increase
+	}
+	return &RateLimiter{cfg: cfg, buckets: map[bucketKey]*bucket{}, now: time.Now}
+}
+
+// SharedRateLimiter is the process-wide limiter every AWS client built by
+// awsconfig.Load goes through.
+var SharedRateLimiter = NewRateLimiter(DefaultLimiterConfig)
+
+// bucketFor returns the bucket for service and region; the caller holds mu.
+func (l *RateLimiter) bucketFor(service, region string) *bucket {
+	k := bucketKey{service, region}
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{rate: l.cfg.Rate, tokens: l.cfg.Burst, last: l.now(), lowest: l.cfg.Rate}
+		l.buckets[k] = b
+	}
+	return b
+}
+
+// Wait blocks until a request to service in region may go, or ctx is done.
+// Requests are admitted in arrival order: each reserves its token up front,
+// so a caller arriving while the bucket is empty queues behind those already
+// waiting.
+func (l *RateLimiter) Wait(ctx context.Context, service, region string) error {
+	l.mu.Lock()
+	b := l.bucketFor(service, region)
+	now := l.now()
+	b.tokens = min(l.cfg.Burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+		b.waited += wait
+	}
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reservation back so those queued behind don't wait for it.
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Observe records the outcome of a request: a throttle cuts the bucket's
+// rate, anything else lets it recover towards the configured rate.
+func (l *RateLimiter) Observe(service, region string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucketFor(service, region)
+	b.calls++
+	if !IsThrottling(err) {
+		b.rate = min(l.cfg.Rate, b.rate+l.cfg.Increase)
+		return
+	}
+	b.throttles++
+	now := l.now()
+	if now.Sub(b.lastDecrease) < l.cfg.Cooldown {
+		return
+	}
+	b.lastDecrease = now
+	b.rate = max(l.cfg.MinRate, b.rate*l.cfg.Decrease)
+	// Spend the saved-up burst too, or the next calls would go at once.
+	b.tokens = min(b.tokens, 0)
+	b.lowest = min(b.lowest, b.rate)
+}
+
+// LimiterStats is one bucket's activity.
+type LimiterStats struct {
+	Service   string
+	Region    string
+	Calls     int
+	Throttles int
+	// Rate is the bucket's current rate in requests per second; LowestRate
+	// the lowest throttling pushed it to.
+	Rate       float64
+	LowestRate float64
+	// Waited is the total time calls were held back.
+	Waited time.Duration
+}
+
+// Stats returns every bucket's activity, by service then region.
+func (l *RateLimiter) Stats() []LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]LimiterStats, 0, len(l.buckets))
+	for k, b := range l.buckets {
+		out = append(out, LimiterStats{
+			Service: k.service, Region: k.region,
+			Calls: b.calls, Throttles: b.throttles,
+			Rate: b.rate, LowestRate: b.lowest, Waited: b.waited,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Service != out[j].Service {
+			return out[i].Service < out[j].Service
+		}
+		return out[i].Region < out[j].Region
+	})
+	return out
+}
+
+// WriteStats writes one line per bucket that saw calls, for --verbose.
+func (l *RateLimiter) WriteStats(w io.Writer) {
+	stats := l.Stats()
+	if len(stats) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "AWS rate limiting (limit %.4g/s per service and region):\n", l.cfg.Rate)
+	for _, s := range stats {
+		fmt.Fprintf(w, "  %-14s %-15s %5d calls  %3d throttled  rate %.3g/s (lowest %.3g/s)  waited %s\n",
+			s.Service, s.Region, s.Calls, s.Throttles, s.Rate, s.LowestRate, s.Waited.Round(time.Millisecond))
+	}
+}
+
+// AddToStack installs the limiter just before a client signs its request:
+// below the SDK's retry loop so every attempt, retried or not, takes a token
+// and reports its outcome, below any response cache so answers from disk
+// don't, and before signing so a long wait can't outlive the signature. It
+// has the shape of an aws.Config APIOptions entry.
+func (l *RateLimiter) AddToStack(stack *middleware.Stack) error {
+	mw := middleware.FinalizeMiddlewareFunc("RefreshRateLimit",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			service, region := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetRegion(ctx)
+			if err := l.Wait(ctx, service, region); err != nil {
+				return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+			}
+			out, md, err := next.HandleFinalize(ctx, in)
+			l.Observe(service, region, err)
+			return out, md, err
+		})
+	if _, ok := stack.Finalize.Get("Signing"); ok {
+		return stack.Finalize.Insert(mw, "Signing", middleware.Before)
+	}
+	return stack.Finalize.Add(mw, middleware.After)
+}
+
+// IsThrottling reports whether err is an AWS throttling error.
+func IsThrottling(err error) bool {
+	var ae smithy.APIError
+	return err != nil && errors.As(err, &ae) && throttlingErrorCodes[ae.ErrorCode()]
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/smithy-go"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
+)
+
+var throttled = &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	clock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(LimiterConfig{Rate: 8, Burst: 4, MinRate: 1, Decrease: 0.5, Increase: 1, Cooldown: time.Second})
+	l.now = func() time.Time { return clock }
+	rate := func() float64 { return l.Stats()[0].Rate }
+
+	l.Observe("EKS", "us-east-1", throttled)
+	if rate() != 4 {
+		t.Fatalf("rate after a throttle = %v, want 4", rate())
+	}
+	// In-flight requests throttled in the same breath count, but don't cut again.
+	l.Observe("EKS", "us-east-1", throttled)
+	if rate() != 4 {
+		t.Errorf("rate within the cooldown = %v, want 4", rate())
+	}
+	for range 4 {
+		clock = clock.Add(2 * time.Second)
+		l.Observe("EKS", "us-east-1", throttled)
+	}
+	if rate() != 1 {
+		t.Errorf("rate should floor at MinRate, got %v", rate())
+	}
+	l.Observe("EKS", "us-east-1", &smithy.GenericAPIError{Code: "ResourceNotFoundException"})
+	for range 20 {
+		l.Observe("EKS", "us-east-1", nil)
+	}
+	s := l.Stats()[0]
+	if s.Rate != 8 || s.LowestRate != 1 || s.Calls != 27 || s.Throttles != 6 {
+		t.Errorf("stats after recovery = %+v", s)
+	}
+
+	l.Observe("EC2", "us-east-1", nil)
+	l.Observe("EKS", "eu-west-1", nil)
+	var keys []string
+	for _, s := range l.Stats() {
+		keys = append(keys, s.Service+"/"+s.Region)
+	}
+	if got := strings.Join(keys, ","); got != "EC2/us-east-1,EKS/eu-west-1,EKS/us-east-1" {
+		t.Errorf("buckets = %s", got)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(LimiterConfig{Rate: 50, Burst: 2})
+	ctx := context.Background()
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(ctx, "EKS", "us-east-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst goes at once; the third call waits for a token (20ms at 50/s).
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("third call went after %s, want about 20ms", elapsed)
+	}
+	if waited := l.Stats()[0].Waited; waited <= 0 {
+		t.Errorf("waited = %s", waited)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cancelled, "EKS", "us-east-1"); err == nil {
+		t.Error("a call queued on an empty bucket should give up with its context")
+	}
+}
+
+// throttlingTransport answers every request as EKS does when throttling.
+type throttlingTransport struct{ calls int }
+
+func (t *throttlingTransport) Do(*http.Request) (*http.Response, error) {
+	t.calls++
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"X-Amzn-Errortype": []string{"ThrottlingException"}, "Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"message":"Rate exceeded"}`)),
+	}, nil
+}
+
+func TestRateLimiterOnClientStack(t *testing.T) {
+	l := NewRateLimiter(LimiterConfig{Rate: 1000, Burst: 1000})
+	transport := &throttlingTransport{}
+	client := eks.NewFromConfig(aws.Config{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		HTTPClient:       transport,
+		RetryMaxAttempts: 2,
+		APIOptions:       []func(*smithymiddleware.Stack) error{l.AddToStack},
+	})
+	if _, err := client.ListClusters(context.Background(), &eks.ListClustersInput{}); !IsThrottling(err) {
+		t.Fatalf("err = %v, want a throttle", err)
+	}
+	stats := l.Stats()
+	if len(stats) != 1 || stats[0].Service != "EKS" || stats[0].Region != "us-east-1" {
+		t.Fatalf("stats = %+v", stats)
+	}
+	// Each SDK attempt goes through the limiter.
+	if stats[0].Calls != transport.calls || stats[0].Throttles != 2 || stats[0].Rate >= 1000 {
+		t.Errorf("stats = %+v after %d attempts", stats[0], transport.calls)
+	}
+
+	var buf bytes.Buffer
+	l.WriteStats(&buf)
+	if !strings.Contains(buf.String(), "EKS") || !strings.Contains(buf.String(), "2 throttled") {
+		t.Errorf("WriteStats =\n%s", buf.String())
+	}
+}
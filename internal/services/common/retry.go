@@ -25,22 +25,33 @@ var DefaultRetryConfig = RetryConfig{
 	BackoffMultiplier: 2.0,
 }
 
+// throttlingErrorCodes are the typed AWS API error codes for request-rate
+// throttling; they also drive the adaptive RateLimiter.
+var throttlingErrorCodes = map[string]bool{
+	"ThrottlingException":       true,
+	"Throttling":                true,
+	"TooManyRequestsException":  true,
+	"RequestLimitExceeded":      true,
+	"RequestThrottled":          true,
+	"RequestThrottledException": true,
+	"SlowDown":                  true,
+	"PriorRequestNotComplete":   true,
+}
+
 // retryableErrorCodes are typed AWS API error codes that indicate a transient
 // condition worth retrying (throttling and server-side hiccups).
-var retryableErrorCodes = map[string]bool{
-	"ThrottlingException":         true,
-	"Throttling":                  true,
-	"TooManyRequestsException":    true,
-	"RequestLimitExceeded":        true,
-	"RequestThrottled":            true,
-	"RequestThrottledException":   true,
-	"SlowDown":                    true,
-	"PriorRequestNotComplete":     true,
-	"ServiceUnavailableException": true,
-	"InternalServerException":     true,
-	"InternalFailure":             true,
-	"ServerException":             true,
-}
+var retryableErrorCodes = func() map[string]bool {
+	codes := map[string]bool{
+		"ServiceUnavailableException": true,
+		"InternalServerException":     true,
+		"InternalFailure":             true,
+		"ServerException":             true,
+	}
+	for c := range throttlingErrorCodes {
+		codes[c] = true
+	}
+	return codes
+}()
 
 // shouldRetry classifies transient errors. Typed AWS API errors are judged by
 // their error code (and server fault classification); substring matching is
@@ -0,0 +1,393 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/services/common"
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+// DefaultBatchDuration is the assumed wall time for EKS to replace one batch
+// of nodes during a managed nodegroup roll (launch, join, drain, terminate).
+const DefaultBatchDuration = 10 * time.Minute
+
+// EKSAPI is the slice of EKS the estimators read.
+type EKSAPI interface {
+	ListClusters(ctx context.Context, in *eks.ListClustersInput, optFns ...func(*eks.Options)) (*eks.ListClustersOutput, error)
+	DescribeCluster(ctx context.Context, in *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+	DescribeClusterVersions(ctx context.Context, in *eks.DescribeClusterVersionsInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterVersionsOutput, error)
+	ListNodegroups(ctx context.Context, in *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error)
+	DescribeNodegroup(ctx context.Context, in *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error)
+}
+
+// SupportCost is the extended-support projection for one cluster.
+type SupportCost struct {
+	Tier          status.SupportTier `json:"tier" yaml:"tier"`
+	StandardUntil *time.Time         `json:"standardUntil,omitempty" yaml:"standardUntil,omitempty"`
+	ExtendedUntil *time.Time         `json:"extendedUntil,omitempty" yaml:"extendedUntil,omitempty"`
+	// PremiumUSDPerHour is the premium the cluster pays right now (0 unless
+	// it is already in extended support).
+	PremiumUSDPerHour float64 `json:"premiumUsdPerHour" yaml:"premiumUsdPerHour"`
+	// ProjectedPremiumUSD is the premium accrued from now until the end of
+	// extended support if the cluster is never upgraded.
+	ProjectedPremiumUSD float64 `json:"projectedPremiumUsd" yaml:"projectedPremiumUsd"`
+	// NextVersion is the minor an upgrade now would land on.
+	NextVersion string `json:"nextVersion,omitempty" yaml:"nextVersion,omitempty"`
+	// NextVersionPremiumUSD is what NextVersion itself would accrue over the
+	// same window (non-zero when it also leaves standard support before the
+	// current version's deadline).
+	NextVersionPremiumUSD float64 `json:"nextVersionPremiumUsd" yaml:"nextVersionPremiumUsd"`
+	// SavingsUSD is the saving of upgrading now versus at the deadline.
+	SavingsUSD float64 `json:"savingsUsd" yaml:"savingsUsd"`
+	// Fallback is true when the support dates came from the compiled-in calendar.
+	Fallback bool `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+}
+
+// SurgeCost is the temporary EC2 cost of rolling one nodegroup.
+type SurgeCost struct {
+	Nodegroup      string `json:"nodegroup" yaml:"nodegroup"`
+	InstanceType   string `json:"instanceType" yaml:"instanceType"`
+	CapacityType   string `json:"capacityType,omitempty" yaml:"capacityType,omitempty"`
+	Nodes          int    `json:"nodes" yaml:"nodes"`
+	MaxUnavailable int    `json:"maxUnavailable" yaml:"maxUnavailable"`
+	// SurgeNodes is how many extra nodes EKS launches for the roll: the larger
+	// of twice the nodegroup's AZ count and maxUnavailable.
+	SurgeNodes      int     `json:"surgeNodes" yaml:"surgeNodes"`
+	Batches         int     `json:"batches" yaml:"batches"`
+	DurationMinutes int     `json:"durationMinutes" yaml:"durationMinutes"`
+	USDPerHour      float64 `json:"usdPerHour" yaml:"usdPerHour"`
+	EstimatedUSD    float64 `json:"estimatedUsd" yaml:"estimatedUsd"`
+	// PriceKnown is false when the instance type isn't in the price table, in
+	// which case EstimatedUSD is 0 rather than a guess.
+	PriceKnown bool `json:"priceKnown" yaml:"priceKnown"`
+}
+
+// ClusterCost is the cost picture for one cluster.
+type ClusterCost struct {
+	Name          string      `json:"name" yaml:"name"`
+	Region        string      `json:"region" yaml:"region"`
+	Version       string      `json:"version" yaml:"version"`
+	Support       SupportCost `json:"support" yaml:"support"`
+	Surge         []SurgeCost `json:"surge,omitempty" yaml:"surge,omitempty"`
+	SurgeTotalUSD float64     `json:"surgeTotalUsd" yaml:"surgeTotalUsd"`
+	// Errors holds non-fatal failures so a partial row still renders.
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Report is the fleet cost payload serialized for json/yaml output.
+type Report struct {
+	PriceSource         string        `json:"priceSource" yaml:"priceSource"`
+	BatchMinutes        int           `json:"batchMinutes" yaml:"batchMinutes"`
+	Clusters            []ClusterCost `json:"clusters" yaml:"clusters"`
+	ProjectedPremiumUSD float64       `json:"projectedPremiumUsd" yaml:"projectedPremiumUsd"`
+	SavingsUSD          float64       `json:"savingsUsd" yaml:"savingsUsd"`
+	SurgeUSD            float64       `json:"surgeUsd" yaml:"surgeUsd"`
+}
+
+// NewReport totals per-cluster costs into a Report.
+func NewReport(clusters []ClusterCost, priceSource string, batch time.Duration) Report {
+	r := Report{PriceSource: priceSource, BatchMinutes: int(batch.Minutes()), Clusters: clusters}
+	for _, c := range clusters {
+		r.ProjectedPremiumUSD += c.Support.ProjectedPremiumUSD
+		r.SavingsUSD += c.Support.SavingsUSD
+		r.SurgeUSD += c.SurgeTotalUSD
+	}
+	return r
+}
+
+// Service estimates costs for the clusters in one region.
+type Service struct {
+	api     EKSAPI
+	region  string
+	prices  PriceTable
+	support *status.SupportResolver
+
+	// BatchDuration is the assumed time to replace one batch of nodes.
+	BatchDuration time.Duration
+
+	// now is injectable for tests; nil means time.Now.
+	now func() time.Time
+}
+
+// NewService builds a region-scoped cost estimator over an EKS client.
+func NewService(api EKSAPI, region string, prices PriceTable) *Service {
+	return &Service{
+		api:           api,
+		region:        region,
+		prices:        prices,
+		support:       status.NewSupportResolver(api),
+		BatchDuration: DefaultBatchDuration,
+	}
+}
+
+func (s *Service) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// EstimateOptions narrows an estimate.
+type EstimateOptions struct {
+	// Nodegroups limits the surge estimate to these nodegroups; empty means all.
+	Nodegroups []string
+	// SkipSurge omits the nodegroup surge estimate entirely.
+	SkipSurge bool
+}
+
+// ListClusterCosts estimates every cluster in the region whose name contains
+// namePattern. Per-cluster failures are recorded on the row.
+func (s *Service) ListClusterCosts(ctx context.Context, namePattern string, maxConcurrency int, opts EstimateOptions) ([]ClusterCost, error) {
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing clusters in %s", s.region),
+		func(rc context.Context, token *string) (*eks.ListClustersOutput, error) {
+			return s.api.ListClusters(rc, &eks.ListClustersInput{NextToken: token})
+		},
+		func(out *eks.ListClustersOutput) ([]string, *string) { return out.Clusters, out.NextToken },
+	)
+	if err != nil {
+		return nil, err
+	}
+	if p := strings.ToLower(strings.TrimSpace(namePattern)); p != "" {
+		filtered := names[:0]
+		for _, n := range names {
+			if strings.Contains(strings.ToLower(n), p) {
+				filtered = append(filtered, n)
+			}
+		}
+		names = filtered
+	}
+	return common.ForEachParallel(ctx, names, maxConcurrency,
+		func(fctx context.Context, name string) ClusterCost {
+			return s.EstimateCluster(fctx, name, opts)
+		}), nil
+}
+
+// EstimateCluster builds one cluster's support projection and roll surge
+// estimate. Each part is best-effort.
+func (s *Service) EstimateCluster(ctx context.Context, name string, opts EstimateOptions) ClusterCost {
+	cc := ClusterCost{Name: name, Region: s.region}
+	desc, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
+		return s.api.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(name)})
+	})
+	if err != nil || desc == nil || desc.Cluster == nil {
+		cc.Errors = append(cc.Errors, fmt.Sprintf("describe cluster: %v", err))
+		cc.Support.Tier = status.SupportUnknown
+		return cc
+	}
+	cc.Version = aws.ToString(desc.Cluster.Version)
+	cc.Support = s.supportCost(ctx, cc.Version)
+
+	if opts.SkipSurge {
+		return cc
+	}
+	surge, failed, err := s.surgeCosts(ctx, name, opts.Nodegroups)
+	if err != nil {
+		cc.Errors = append(cc.Errors, fmt.Sprintf("list nodegroups: %v", err))
+	}
+	cc.Errors = append(cc.Errors, failed...)
+	cc.Surge = surge
+	for _, sc := range surge {
+		cc.SurgeTotalUSD += sc.EstimatedUSD
+	}
+	return cc
+}
+
+// supportCost resolves the current and next minor's support windows and
+// projects the premium for both.
+func (s *Service) supportCost(ctx context.Context, version string) SupportCost {
+	cur := s.support.Resolve(ctx, version)
+	next := nextMinor(version)
+	var nextPosture status.SupportPosture
+	if next != "" {
+		nextPosture = s.support.Resolve(ctx, next)
+	}
+	return projectSupport(cur, next, nextPosture, s.clock(), s.prices.EKS.ExtendedPremiumPerHour)
+}
+
+// projectSupport computes the premium accrued from now to the current
+// version's end-of-extended date, and how much of it an upgrade to the next
+// minor now would avoid. A next version with unknown dates is assumed to stay
+// in standard support for the whole window.
+func projectSupport(cur status.SupportPosture, next string, nextPosture status.SupportPosture, now time.Time, premium float64) SupportCost {
+	sc := SupportCost{
+		Tier:          cur.Tier,
+		StandardUntil: cur.StandardUntil,
+		ExtendedUntil: cur.ExtendedUntil,
+		Fallback:      cur.Fallback,
+		NextVersion:   next,
+	}
+	if cur.Tier == status.SupportExtended {
+		sc.PremiumUSDPerHour = premium
+	}
+	if cur.ExtendedUntil == nil {
+		return sc
+	}
+	deadline := *cur.ExtendedUntil
+	sc.ProjectedPremiumUSD = roundCents(premium * extendedHours(cur.StandardUntil, deadline, now))
+	if nextPosture.ExtendedUntil != nil {
+		end := deadline
+		if nextPosture.ExtendedUntil.Before(end) {
+			end = *nextPosture.ExtendedUntil
+		}
+		sc.NextVersionPremiumUSD = roundCents(premium * extendedHours(nextPosture.StandardUntil, end, now))
+	}
+	sc.SavingsUSD = roundCents(math.Max(0, sc.ProjectedPremiumUSD-sc.NextVersionPremiumUSD))
+	return sc
+}
+
+// extendedHours is the number of hours in [now, end] that fall after the end
+// of standard support. A nil standardEnd counts the whole window.
+func extendedHours(standardEnd *time.Time, end, now time.Time) float64 {
+	start := now
+	if standardEnd != nil && standardEnd.After(start) {
+		start = *standardEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// surgeCosts describes the cluster's nodegroups and estimates each roll. A
+// nodegroup that can't be described is left out of the estimate and named in
+// failed, so the total isn't silently short.
+func (s *Service) surgeCosts(ctx context.Context, clusterName string, only []string) (surge []SurgeCost, failed []string, err error) {
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing nodegroups for cluster %s", clusterName),
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return s.api.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(only) > 0 {
+		names = keep(names, only)
+	}
+	type described struct {
+		ng  *ekstypes.Nodegroup
+		err error
+	}
+	results := common.ForEachParallel(ctx, names, common.DefaultItemConcurrency,
+		func(fctx context.Context, ng string) described {
+			out, derr := common.WithRetry(fctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
+				return s.api.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{
+					ClusterName:   aws.String(clusterName),
+					NodegroupName: aws.String(ng),
+				})
+			})
+			if derr == nil && (out == nil || out.Nodegroup == nil) {
+				derr = errors.New("empty response")
+			}
+			if derr != nil {
+				return described{err: derr}
+			}
+			return described{ng: out.Nodegroup}
+		})
+	surge = make([]SurgeCost, 0, len(results))
+	for i, r := range results {
+		if r.err != nil {
+			failed = append(failed, fmt.Sprintf("describe nodegroup %s: %v", names[i], r.err))
+			continue
+		}
+		surge = append(surge, estimateSurge(r.ng, s.prices, s.BatchDuration))
+	}
+	return surge, failed, nil
+}
+
+// estimateSurge models a managed nodegroup roll: EKS raises the ASG by the
+// larger of twice the AZ count and maxUnavailable, keeps those surge nodes up
+// while it replaces maxUnavailable nodes per batch, then scales back down.
+// Subnet count stands in for AZ count.
+func estimateSurge(ng *ekstypes.Nodegroup, prices PriceTable, batch time.Duration) SurgeCost {
+	if batch <= 0 {
+		batch = DefaultBatchDuration
+	}
+	sc := SurgeCost{
+		Nodegroup:    aws.ToString(ng.NodegroupName),
+		InstanceType: "unknown",
+		CapacityType: string(ng.CapacityType),
+	}
+	if len(ng.InstanceTypes) > 0 {
+		sc.InstanceType = ng.InstanceTypes[0]
+	}
+	if ng.ScalingConfig != nil {
+		sc.Nodes = int(aws.ToInt32(ng.ScalingConfig.DesiredSize))
+	}
+	sc.MaxUnavailable = maxUnavailable(ng.UpdateConfig, sc.Nodes)
+	if sc.Nodes == 0 {
+		return sc
+	}
+	azs := len(ng.Subnets)
+	if azs == 0 {
+		azs = 1
+	}
+	sc.SurgeNodes = max(2*azs, sc.MaxUnavailable)
+	sc.Batches = (sc.Nodes + sc.MaxUnavailable - 1) / sc.MaxUnavailable
+	duration := time.Duration(sc.Batches) * batch
+	sc.DurationMinutes = int(duration.Minutes())
+	if price, ok := prices.InstancePrice(sc.InstanceType); ok {
+		sc.PriceKnown = true
+		sc.USDPerHour = price
+		sc.EstimatedUSD = roundCents(float64(sc.SurgeNodes) * price * duration.Hours())
+	}
+	return sc
+}
+
+// maxUnavailable resolves the nodegroup's update config to a node count
+// (EKS defaults to 1).
+func maxUnavailable(uc *ekstypes.NodegroupUpdateConfig, nodes int) int {
+	if uc == nil {
+		return 1
+	}
+	if n := int(aws.ToInt32(uc.MaxUnavailable)); n > 0 {
+		return n
+	}
+	if pct := int(aws.ToInt32(uc.MaxUnavailablePercentage)); pct > 0 {
+		return max(1, (nodes*pct+99)/100)
+	}
+	return 1
+}
+
+// nextMinor returns the next Kubernetes minor ("1.31" → "1.32"), or "" when
+// the version can't be parsed.
+func nextMinor(version string) string {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return ""
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("1.%d", minor+1)
+}
+
+func keep(names, only []string) []string {
+	want := make(map[string]struct{}, len(only))
+	for _, n := range only {
+		want[n] = struct{}{}
+	}
+	out := names[:0]
+	for _, n := range names {
+		if _, ok := want[n]; ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
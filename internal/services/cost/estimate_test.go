@@ -0,0 +1,257 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/mocks"
+	"github.com/dantech2000/refresh/internal/services/status"
+)
+
+func date(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestEstimateSurge(t *testing.T) {
+	prices := PriceTable{EC2: map[string]float64{"m6i.large": 0.10}}
+	tests := []struct {
+		name        string
+		ng          ekstypes.Nodegroup
+		wantSurge   int
+		wantBatches int
+		wantUSD     float64
+		wantKnown   bool
+	}{
+		{
+			name: "defaults: 3 AZs, maxUnavailable 1",
+			ng: ekstypes.Nodegroup{
+				InstanceTypes: []string{"m6i.large"},
+				Subnets:       []string{"a", "b", "c"},
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(6)},
+			},
+			// surge = max(2*3, 1) = 6 nodes for 6 batches × 10m = 1h.
+			wantSurge: 6, wantBatches: 6, wantUSD: 0.60, wantKnown: true,
+		},
+		{
+			name: "maxUnavailable above 2×AZ drives surge",
+			ng: ekstypes.Nodegroup{
+				InstanceTypes: []string{"m6i.large"},
+				Subnets:       []string{"a"},
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(10)},
+				UpdateConfig:  &ekstypes.NodegroupUpdateConfig{MaxUnavailable: aws.Int32(5)},
+			},
+			// surge = 5 for 2 batches × 10m.
+			wantSurge: 5, wantBatches: 2, wantUSD: 0.17, wantKnown: true,
+		},
+		{
+			name: "percentage rounds up",
+			ng: ekstypes.Nodegroup{
+				InstanceTypes: []string{"m6i.large"},
+				Subnets:       []string{"a", "b"},
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(9)},
+				UpdateConfig:  &ekstypes.NodegroupUpdateConfig{MaxUnavailablePercentage: aws.Int32(25)},
+			},
+			// maxUnavailable = ceil(9*0.25) = 3 → 3 batches; surge = max(4, 3) = 4.
+			wantSurge: 4, wantBatches: 3, wantUSD: 0.20, wantKnown: true,
+		},
+		{
+			name: "unknown instance type is flagged, not guessed",
+			ng: ekstypes.Nodegroup{
+				InstanceTypes: []string{"z1d.metal"},
+				Subnets:       []string{"a"},
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(2)},
+			},
+			wantSurge: 2, wantBatches: 2, wantUSD: 0, wantKnown: false,
+		},
+		{
+			name: "empty nodegroup costs nothing",
+			ng: ekstypes.Nodegroup{
+				InstanceTypes: []string{"m6i.large"},
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(0)},
+			},
+			wantSurge: 0, wantBatches: 0, wantUSD: 0, wantKnown: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateSurge(&tt.ng, prices, DefaultBatchDuration)
+			if got.SurgeNodes != tt.wantSurge || got.Batches != tt.wantBatches {
+				t.Errorf("surge/batches = %d/%d, want %d/%d", got.SurgeNodes, got.Batches, tt.wantSurge, tt.wantBatches)
+			}
+			if got.EstimatedUSD != tt.wantUSD {
+				t.Errorf("EstimatedUSD = %v, want %v", got.EstimatedUSD, tt.wantUSD)
+			}
+			if got.PriceKnown != tt.wantKnown {
+				t.Errorf("PriceKnown = %v, want %v", got.PriceKnown, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestProjectSupport(t *testing.T) {
+	now := date(2026, 1, 1)
+	premium := 0.50
+
+	t.Run("in extended: premium runs to end of extended", func(t *testing.T) {
+		cur := status.SupportPosture{
+			Tier:          status.SupportExtended,
+			StandardUntil: timePtr(date(2025, 11, 1)),
+			ExtendedUntil: timePtr(date(2026, 1, 11)), // 10 days left
+		}
+		next := status.SupportPosture{
+			Tier:          status.SupportStandard,
+			StandardUntil: timePtr(date(2026, 6, 1)),
+			ExtendedUntil: timePtr(date(2027, 6, 1)),
+		}
+		got := projectSupport(cur, "1.31", next, now, premium)
+		if got.PremiumUSDPerHour != premium {
+			t.Errorf("PremiumUSDPerHour = %v", got.PremiumUSDPerHour)
+		}
+		if got.ProjectedPremiumUSD != 120 { // 240h × $0.50
+			t.Errorf("ProjectedPremiumUSD = %v, want 120", got.ProjectedPremiumUSD)
+		}
+		if got.NextVersionPremiumUSD != 0 || got.SavingsUSD != 120 {
+			t.Errorf("next/savings = %v/%v, want 0/120", got.NextVersionPremiumUSD, got.SavingsUSD)
+		}
+	})
+
+	t.Run("standard: premium only counts after standard ends", func(t *testing.T) {
+		cur := status.SupportPosture{
+			Tier:          status.SupportStandard,
+			StandardUntil: timePtr(date(2026, 1, 2)),
+			ExtendedUntil: timePtr(date(2026, 1, 4)), // 48h of extended
+		}
+		got := projectSupport(cur, "1.32", status.SupportPosture{}, now, premium)
+		if got.PremiumUSDPerHour != 0 {
+			t.Errorf("standard tier should pay no premium now, got %v", got.PremiumUSDPerHour)
+		}
+		if got.ProjectedPremiumUSD != 24 {
+			t.Errorf("ProjectedPremiumUSD = %v, want 24", got.ProjectedPremiumUSD)
+		}
+	})
+
+	t.Run("next minor also lapses: savings net of its premium", func(t *testing.T) {
+		cur := status.SupportPosture{
+			Tier:          status.SupportExtended,
+			StandardUntil: timePtr(date(2025, 6, 1)),
+			ExtendedUntil: timePtr(date(2026, 1, 11)),
+		}
+		next := status.SupportPosture{
+			Tier:          status.SupportStandard,
+			StandardUntil: timePtr(date(2026, 1, 6)), // 5 days of premium inside the window
+			ExtendedUntil: timePtr(date(2027, 1, 6)),
+		}
+		got := projectSupport(cur, "1.31", next, now, premium)
+		if got.NextVersionPremiumUSD != 60 || got.SavingsUSD != 60 {
+			t.Errorf("next/savings = %v/%v, want 60/60", got.NextVersionPremiumUSD, got.SavingsUSD)
+		}
+	})
+
+	t.Run("unknown dates project nothing", func(t *testing.T) {
+		got := projectSupport(status.SupportPosture{Tier: status.SupportUnknown}, "", status.SupportPosture{}, now, premium)
+		if got.ProjectedPremiumUSD != 0 || got.SavingsUSD != 0 {
+			t.Errorf("got %+v, want zero projection", got)
+		}
+	})
+}
+
+func TestNextMinor(t *testing.T) {
+	for in, want := range map[string]string{"1.31": "1.32", "1.9": "1.10", "1.30.2": "1.31", "": "", "2.0": "", "x": ""} {
+		if got := nextMinor(in); got != want {
+			t.Errorf("nextMinor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEstimateCluster(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+	api := mocks.NewEKSAPI().
+		WithCluster("prod", "1.31").
+		WithNodegroup("ng-a", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		WithNodegroup("ng-b", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		Build()
+	// Give ng-a a priced instance type; ng-b stays describable but unpriced.
+	describe := api.DescribeNodegroupFn
+	api.DescribeNodegroupFn = func(ctx context.Context, in *eks.DescribeNodegroupInput, opts ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+		out, err := describe(ctx, in, opts...)
+		if err == nil && aws.ToString(in.NodegroupName) == "ng-a" {
+			out.Nodegroup.InstanceTypes = []string{"m6i.large"}
+			out.Nodegroup.Subnets = []string{"a", "b", "c"}
+			out.Nodegroup.ScalingConfig = &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(6)}
+		}
+		return out, err
+	}
+	api.DescribeClusterVersionsFn = func(_ context.Context, in *eks.DescribeClusterVersionsInput, _ ...func(*eks.Options)) (*eks.DescribeClusterVersionsOutput, error) {
+		out := &eks.DescribeClusterVersionsOutput{}
+		for _, v := range in.ClusterVersions {
+			info := ekstypes.ClusterVersionInformation{ClusterVersion: aws.String(v)}
+			if v == "1.31" {
+				info.EndOfStandardSupportDate = timePtr(date(2025, 11, 26))
+				info.EndOfExtendedSupportDate = timePtr(date(2026, 1, 11))
+			}
+			out.ClusterVersions = append(out.ClusterVersions, info)
+		}
+		return out, nil
+	}
+
+	svc := NewService(api, "us-east-1", PriceTable{
+		EKS: EKSPrices{ExtendedPremiumPerHour: 0.5},
+		EC2: map[string]float64{"m6i.large": 0.10},
+	})
+	svc.now = func() time.Time { return date(2026, 1, 1) }
+
+	got := svc.EstimateCluster(context.Background(), "prod", EstimateOptions{})
+	if len(got.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", got.Errors)
+	}
+	if got.Version != "1.31" || got.Support.NextVersion != "1.32" {
+		t.Errorf("version/next = %s/%s", got.Version, got.Support.NextVersion)
+	}
+	if got.Support.ProjectedPremiumUSD != 120 {
+		t.Errorf("ProjectedPremiumUSD = %v, want 120", got.Support.ProjectedPremiumUSD)
+	}
+	if len(got.Surge) != 2 {
+		t.Fatalf("want 2 surge rows, got %d", len(got.Surge))
+	}
+	if got.SurgeTotalUSD != 0.60 {
+		t.Errorf("SurgeTotalUSD = %v, want 0.60", got.SurgeTotalUSD)
+	}
+
+	only := svc.EstimateCluster(context.Background(), "prod", EstimateOptions{Nodegroups: []string{"ng-b"}})
+	if len(only.Surge) != 1 || only.Surge[0].Nodegroup != "ng-b" {
+		t.Errorf("Nodegroups filter not applied: %+v", only.Surge)
+	}
+
+	// A nodegroup that can't be described is reported, not dropped quietly.
+	priced := api.DescribeNodegroupFn
+	api.DescribeNodegroupFn = func(ctx context.Context, in *eks.DescribeNodegroupInput, opts ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+		if aws.ToString(in.NodegroupName) == "ng-b" {
+			return nil, errors.New("AccessDeniedException: not authorized")
+		}
+		return priced(ctx, in, opts...)
+	}
+	partial := svc.EstimateCluster(context.Background(), "prod", EstimateOptions{})
+	if len(partial.Surge) != 1 || partial.Surge[0].Nodegroup != "ng-a" {
+		t.Errorf("surge rows = %+v, want only ng-a", partial.Surge)
+	}
+	if len(partial.Errors) != 1 || !strings.Contains(partial.Errors[0], "describe nodegroup ng-b") {
+		t.Errorf("errors = %v, want the failed ng-b describe", partial.Errors)
+	}
+}
+
+func TestNewReportTotals(t *testing.T) {
+	r := NewReport([]ClusterCost{
+		{Support: SupportCost{ProjectedPremiumUSD: 10, SavingsUSD: 4}, SurgeTotalUSD: 1.5},
+		{Support: SupportCost{ProjectedPremiumUSD: 5, SavingsUSD: 5}, SurgeTotalUSD: 0.5},
+	}, "built-in", DefaultBatchDuration)
+	if r.ProjectedPremiumUSD != 15 || r.SavingsUSD != 9 || r.SurgeUSD != 2 || r.BatchMinutes != 10 {
+		t.Errorf("totals wrong: %+v", r)
+	}
+}
@@ -0,0 +1,159 @@
+// Package cost estimates what EKS patch posture costs: the extended-support
+// premium a lagging cluster accrues until its end-of-extended date (and what
+// upgrading now saves), and the temporary EC2 surge a nodegroup roll launches.
+//
+// Prices come from a local price table — compiled-in list prices overlaid by
+// an optional YAML file — so estimates work offline and can carry negotiated
+// rates. Nothing here calls a pricing API.
+package cost
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// priceFileName is the default override file under the refresh config dir.
+const priceFileName = "prices.yaml"
+
+var readFile = os.ReadFile
+
+// PriceTable is the set of hourly prices the estimators draw from. All values
+// are USD per hour.
+type PriceTable struct {
+	// EKS holds control-plane pricing.
+	EKS EKSPrices `json:"eks" yaml:"eks"`
+	// EC2 maps an instance type (e.g. "m6i.large") to its hourly price.
+	EC2 map[string]float64 `json:"ec2" yaml:"ec2"`
+}
+
+// EKSPrices is the per-cluster control-plane pricing.
+type EKSPrices struct {
+	// StandardPerHour is the standard-support control-plane price.
+	StandardPerHour float64 `json:"standardPerHour" yaml:"standardPerHour"`
+	// ExtendedPremiumPerHour is what extended support costs on top of standard.
+	ExtendedPremiumPerHour float64 `json:"extendedPremiumPerHour" yaml:"extendedPremiumPerHour"`
+}
+
+// DefaultPrices returns the compiled-in table: public us-east-1 on-demand list
+// prices for the instance families EKS nodegroups commonly run. It is a
+// starting point, not a quote — override it with a prices.yaml for other
+// regions, Savings Plans, or negotiated rates.
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		EKS: EKSPrices{
+			StandardPerHour:        0.10,
+			ExtendedPremiumPerHour: 0.50,
+		},
+		EC2: map[string]float64{
+			"t3.medium":   0.0416,
+			"t3.large":    0.0832,
+			"t3.xlarge":   0.1664,
+			"t3.2xlarge":  0.3328,
+			"m5.large":    0.096,
+			"m5.xlarge":   0.192,
+			"m5.2xlarge":  0.384,
+			"m5.4xlarge":  0.768,
+			"m6i.large":   0.096,
+			"m6i.xlarge":  0.192,
+			"m6i.2xlarge": 0.384,
+			"m6i.4xlarge": 0.768,
+			"m7i.large":   0.1008,
+			"m7i.xlarge":  0.2016,
+			"m7i.2xlarge": 0.4032,
+			"m6g.large":   0.077,
+			"m6g.xlarge":  0.154,
+			"m7g.large":   0.0816,
+			"m7g.xlarge":  0.1632,
+			"c5.large":    0.085,
+			"c5.xlarge":   0.17,
+			"c5.2xlarge":  0.34,
+			"c6i.large":   0.085,
+			"c6i.xlarge":  0.17,
+			"c6i.2xlarge": 0.34,
+			"c6g.large":   0.068,
+			"c6g.xlarge":  0.136,
+			"c7g.large":   0.0725,
+			"c7g.xlarge":  0.145,
+			"r5.large":    0.126,
+			"r5.xlarge":   0.252,
+			"r5.2xlarge":  0.504,
+			"r6i.large":   0.126,
+			"r6i.xlarge":  0.252,
+			"r6i.2xlarge": 0.504,
+			"r6g.large":   0.1008,
+			"r6g.xlarge":  0.2016,
+			"g5.xlarge":   1.006,
+			"g5.2xlarge":  1.212,
+		},
+	}
+}
+
+// InstancePrice returns the hourly price of an instance type, if known.
+func (p PriceTable) InstancePrice(instanceType string) (float64, bool) {
+	v, ok := p.EC2[strings.ToLower(strings.TrimSpace(instanceType))]
+	return v, ok
+}
+
+// DefaultPricePath returns where LoadPrices looks when no explicit file is
+// given: prices.yaml beside the context file.
+func DefaultPricePath() (string, error) {
+	dir, err := cliconfig.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, priceFileName), nil
+}
+
+// LoadPrices returns the compiled-in table overlaid with the YAML file at path
+// (or DefaultPricePath when path is empty). Only the keys present in the file
+// override; everything else keeps its default. source describes where the
+// prices came from ("built-in" or the file path) for display.
+//
+// A missing default file is not an error; a missing explicit path is.
+func LoadPrices(path string) (table PriceTable, source string, err error) {
+	table = DefaultPrices()
+	explicit := strings.TrimSpace(path) != ""
+	if !explicit {
+		if path, err = DefaultPricePath(); err != nil {
+			return table, "built-in", nil
+		}
+	}
+	b, err := readFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return table, "built-in", nil
+	}
+	if err != nil {
+		return table, "", fmt.Errorf("reading price table %s: %w", path, err)
+	}
+	var override PriceTable
+	if err := yaml.Unmarshal(b, &override); err != nil {
+		return table, "", fmt.Errorf("parsing price table %s: %w", path, err)
+	}
+	table.merge(override)
+	return table, path, nil
+}
+
+// merge overlays the non-zero fields of o onto p.
+func (p *PriceTable) merge(o PriceTable) {
+	if o.EKS.StandardPerHour > 0 {
+		p.EKS.StandardPerHour = o.EKS.StandardPerHour
+	}
+	if o.EKS.ExtendedPremiumPerHour > 0 {
+		p.EKS.ExtendedPremiumPerHour = o.EKS.ExtendedPremiumPerHour
+	}
+	if p.EC2 == nil {
+		p.EC2 = map[string]float64{}
+	}
+	for k, v := range o.EC2 {
+		if v > 0 {
+			p.EC2[strings.ToLower(strings.TrimSpace(k))] = v
+		}
+	}
+}
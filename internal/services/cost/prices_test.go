@@ -0,0 +1,73 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPrices_DefaultWhenNoFile(t *testing.T) {
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
+
+	table, source, err := LoadPrices("")
+	if err != nil {
+		t.Fatalf("LoadPrices: %v", err)
+	}
+	if source != "built-in" {
+		t.Errorf("source = %q, want built-in", source)
+	}
+	if table.EKS.ExtendedPremiumPerHour != 0.50 {
+		t.Errorf("premium = %v, want 0.50", table.EKS.ExtendedPremiumPerHour)
+	}
+	if _, ok := table.InstancePrice("m6i.large"); !ok {
+		t.Error("expected a built-in price for m6i.large")
+	}
+}
+
+func TestLoadPrices_DefaultFileOverlays(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("REFRESH_CONFIG_HOME", dir)
+	body := "eks:\n  extendedPremiumPerHour: 0.42\nec2:\n  M6I.Large: 0.05\n  x9.huge: 9.99\n"
+	if err := os.WriteFile(filepath.Join(dir, "prices.yaml"), []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	table, source, err := LoadPrices("")
+	if err != nil {
+		t.Fatalf("LoadPrices: %v", err)
+	}
+	if source != filepath.Join(dir, "prices.yaml") {
+		t.Errorf("source = %q", source)
+	}
+	if table.EKS.ExtendedPremiumPerHour != 0.42 {
+		t.Errorf("premium = %v, want override 0.42", table.EKS.ExtendedPremiumPerHour)
+	}
+	if table.EKS.StandardPerHour != 0.10 {
+		t.Errorf("standard = %v, want default kept", table.EKS.StandardPerHour)
+	}
+	if p, _ := table.InstancePrice("m6i.large"); p != 0.05 {
+		t.Errorf("m6i.large = %v, want case-insensitive override 0.05", p)
+	}
+	if p, ok := table.InstancePrice("x9.huge"); !ok || p != 9.99 {
+		t.Errorf("x9.huge = %v,%v, want added type", p, ok)
+	}
+	if _, ok := table.InstancePrice("c6i.large"); !ok {
+		t.Error("untouched defaults should survive the overlay")
+	}
+}
+
+func TestLoadPrices_ExplicitMissingIsError(t *testing.T) {
+	if _, _, err := LoadPrices(filepath.Join(t.TempDir(), "nope.yaml")); err == nil {
+		t.Fatal("expected an error for a missing explicit price file")
+	}
+}
+
+func TestLoadPrices_BadYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte("eks: [not a map"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadPrices(path); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
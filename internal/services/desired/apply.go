@@ -0,0 +1,199 @@
+package desired
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dantech2000/refresh/internal/services/addons"
+	"github.com/dantech2000/refresh/internal/services/upgrade"
+)
+
+// ApplyOptions tunes Apply. The fields mirror upgrade.ExecuteOptions and are
+// passed through to the orchestrator.
+type ApplyOptions struct {
+	Yes               bool
+	Confirm           upgrade.ConfirmFunc
+	Progress          upgrade.ProgressFunc
+	Force             bool
+	NodegroupGate     upgrade.NodegroupGate
+	NodegroupObserver upgrade.RollObserver
+}
+
+// Apply reconciles a planned cluster with its spec in three steps, each
+// confirmed unless opts.Yes:
+//
+//  1. Kubernetes version drift (control plane or nodegroups) runs the upgrade
+//     orchestrator to the spec version, with its readiness, addon and
+//     nodegroup gates. Add-ons with a default or pinned policy are held out
+//     of its per-hop "latest compatible" updates, and nodegroups land on a
+//     pinned AMI release.
+//  2. The cluster is planned again from live state, and add-ons move to the
+//     version their policy names.
+//  3. Nodegroups whose AMI release breaks the policy roll onto the desired
+//     release within their Kubernetes version.
+//
+// Manual changes are left alone. A plan with an error or a blocked change
+// isn't applied. The report says what ran, where it stopped and what remains;
+// rerunning resumes from live state.
+func (s *Service) Apply(ctx context.Context, plan *ClusterPlan, opts ApplyOptions) (*upgrade.Report, error) {
+	report := &upgrade.Report{}
+	if plan.Error != "" {
+		return report, fmt.Errorf("cluster %s wasn't planned: %s", plan.Cluster, plan.Error)
+	}
+	if plan.Blocked() {
+		return report, fmt.Errorf("cluster %s has blocked changes; refusing to apply:\n  %s", plan.Cluster, strings.Join(blockers(plan), "\n  "))
+	}
+
+	if len(plan.pending(ChangeKubernetes, ChangeNodegroupVersion)) > 0 {
+		upgradeReport, err := s.applyVersion(ctx, plan, opts)
+		report.Completed = append(report.Completed, upgradeReport.Completed...)
+		report.FailedAt = upgradeReport.FailedAt
+		report.Remaining = upgradeReport.Remaining
+		if err != nil {
+			report.Remaining = append(report.Remaining, "reconcile add-ons and nodegroup AMIs to spec")
+			return report, err
+		}
+		// Add-on and AMI drift is re-derived below from where the upgrade
+		// left the cluster.
+		replanned := s.PlanCluster(ctx, plan.Cluster, plan.spec)
+		replanned.Region = plan.Region
+		if replanned.Error != "" {
+			return report, fmt.Errorf("re-planning %s after the upgrade: %s", plan.Cluster, replanned.Error)
+		}
+		if replanned.Blocked() {
+			return report, fmt.Errorf("cluster %s has blocked changes after the upgrade:\n  %s", plan.Cluster, strings.Join(blockers(&replanned), "\n  "))
+		}
+		plan = &replanned
+	}
+
+	addonChanges := plan.pending(ChangeAddon)
+	amiChanges := plan.pending(ChangeNodegroupAMI)
+	phases := []struct {
+		label   string
+		changes []Change
+		run     func() error
+	}{
+		{
+			label:   fmt.Sprintf("add-ons to spec on %s (%d update(s), dependency order)", plan.Cluster, len(addonChanges)),
+			changes: addonChanges,
+			run: func() error {
+				targets := make([]upgrade.AddonTarget, 0, len(addonChanges))
+				for _, c := range addonChanges {
+					targets = append(targets, upgrade.AddonTarget{Name: c.Target, Version: c.Desired})
+				}
+				return s.Upgrader.SetAddonVersions(ctx, plan.Cluster, targets, opts.Progress)
+			},
+		},
+		{
+			label:   fmt.Sprintf("nodegroup AMI refresh on %s (%d nodegroup(s))", plan.Cluster, len(amiChanges)),
+			changes: amiChanges,
+			run: func() error {
+				targets := make([]upgrade.NodegroupTarget, 0, len(amiChanges))
+				for _, c := range amiChanges {
+					targets = append(targets, upgrade.NodegroupTarget{Name: c.Target, Version: c.Version, ReleaseVersion: c.Desired})
+				}
+				return s.Upgrader.RefreshNodegroups(ctx, plan.Cluster, targets, upgrade.NodegroupRollOptions{
+					Force:    opts.Force,
+					Gate:     opts.NodegroupGate,
+					Observer: opts.NodegroupObserver,
+				}, opts.Progress)
+			},
+		},
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(string, ...any) {}
+	}
+	for i, ph := range phases {
+		if len(ph.changes) == 0 {
+			continue
+		}
+		var rest []string
+		for _, later := range phases[i+1:] {
+			if len(later.changes) > 0 {
+				rest = append(rest, later.label)
+			}
+		}
+		if !opts.Yes {
+			if opts.Confirm == nil {
+				return report, fmt.Errorf("confirmation required for %q but no prompt available (use --yes for non-interactive runs)", ph.label)
+			}
+			if !opts.Confirm(ph.label) {
+				report.Remaining = append([]string{ph.label}, rest...)
+				return report, upgrade.ErrAborted
+			}
+		}
+		progress("▸ %s", ph.label)
+		if err := ph.run(); err != nil {
+			report.FailedAt = ph.label
+			report.Remaining = rest
+			return report, fmt.Errorf("%s failed: %w", ph.label, err)
+		}
+		report.Completed = append(report.Completed, ph.label)
+	}
+	return report, nil
+}
+
+// applyVersion runs the upgrade orchestrator to the spec's Kubernetes version.
+func (s *Service) applyVersion(ctx context.Context, plan *ClusterPlan, opts ApplyOptions) (*upgrade.Report, error) {
+	held, err := s.heldAddons(ctx, plan)
+	if err != nil {
+		return &upgrade.Report{}, err
+	}
+	skipAddons := append(append([]string(nil), plan.spec.Skip.Addons...), held...)
+	release := ""
+	if plan.spec.Nodegroups.Pinned() {
+		release = plan.spec.Nodegroups.AMI
+	}
+	up, err := s.Upgrader.BuildPlan(ctx, plan.Cluster, plan.DesiredVersion, upgrade.PlanOptions{
+		SkipAddons:              skipAddons,
+		SkipNodegroups:          plan.spec.Skip.Nodegroups,
+		NodegroupReleaseVersion: release,
+	})
+	if err != nil {
+		return &upgrade.Report{}, err
+	}
+	return s.Upgrader.Execute(ctx, up, upgrade.ExecuteOptions{
+		Yes:                     opts.Yes,
+		Confirm:                 opts.Confirm,
+		Progress:                opts.Progress,
+		SkipAddons:              skipAddons,
+		SkipNodegroups:          plan.spec.Skip.Nodegroups,
+		Force:                   opts.Force,
+		NodegroupGate:           opts.NodegroupGate,
+		NodegroupObserver:       opts.NodegroupObserver,
+		NodegroupReleaseVersion: release,
+	})
+}
+
+// heldAddons lists the installed add-ons whose policy isn't latest: the
+// upgrade leaves them be, and step 2 lands them on their version once the
+// control plane is at the spec version.
+func (s *Service) heldAddons(ctx context.Context, plan *ClusterPlan) ([]string, error) {
+	if len(plan.spec.Addons) == 0 {
+		return nil, nil
+	}
+	installed, err := addons.NewService(s.eksClient, s.logger).List(ctx, plan.Cluster, addons.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var held []string
+	for _, a := range installed {
+		if p := plan.spec.AddonPolicy(a.Name); p != "" && p != PolicyLatest {
+			held = append(held, a.Name)
+		}
+	}
+	return held, nil
+}
+
+// blockers describes a plan's blocked changes.
+func blockers(p *ClusterPlan) []string {
+	var out []string
+	for _, c := range p.Changes {
+		if c.Status == ChangeBlocked {
+			out = append(out, fmt.Sprintf("%s %s: %s", c.Kind, c.Target, c.Reason))
+		}
+	}
+	return out
+}
@@ -0,0 +1,486 @@
+package desired
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/selector"
+	"github.com/dantech2000/refresh/internal/services/addons"
+	"github.com/dantech2000/refresh/internal/services/common"
+	"github.com/dantech2000/refresh/internal/services/upgrade"
+)
+
+// EKSAPI is the EKS surface plan and apply use: the upgrade orchestrator's,
+// plus ListClusters to expand name globs.
+type EKSAPI interface {
+	upgrade.EKSAPI
+	ListClusters(ctx context.Context, params *eks.ListClustersInput, optFns ...func(*eks.Options)) (*eks.ListClustersOutput, error)
+}
+
+// AMIReleases answers the nodegroup AMI policy's questions about published
+// EKS-optimized AMI releases.
+type AMIReleases interface {
+	// Latest returns the newest release for amiType on k8sVersion, or ""
+	// when it can't be resolved.
+	Latest(ctx context.Context, k8sVersion string, amiType ekstypes.AMITypes) string
+	// Check errors unless release is published for amiType on k8sVersion.
+	Check(ctx context.Context, k8sVersion, release string, amiType ekstypes.AMITypes) error
+}
+
+// ChangeKind is what a change converges.
+type ChangeKind string
+
+const (
+	ChangeKubernetes       ChangeKind = "kubernetes"
+	ChangeAddon            ChangeKind = "addon"
+	ChangeNodegroupVersion ChangeKind = "nodegroup-version"
+	ChangeNodegroupAMI     ChangeKind = "nodegroup-ami"
+)
+
+// ChangeStatus says whether apply will make a change.
+type ChangeStatus string
+
+const (
+	// ChangePending is drift apply reconciles.
+	ChangePending ChangeStatus = "pending"
+	// ChangeBlocked is drift apply can't reconcile until the reason is
+	// resolved; a cluster with a blocked change isn't applied.
+	ChangeBlocked ChangeStatus = "blocked"
+	// ChangeManual is drift outside what apply does (an add-on downgrade, an
+	// add-on that isn't installed, a custom-AMI nodegroup); it is reported
+	// and left alone.
+	ChangeManual ChangeStatus = "manual"
+)
+
+// Change is one difference between live state and the spec.
+type Change struct {
+	Kind    ChangeKind   `json:"kind" yaml:"kind"`
+	Target  string       `json:"target,omitempty" yaml:"target,omitempty"`
+	Current string       `json:"current,omitempty" yaml:"current,omitempty"`
+	Desired string       `json:"desired,omitempty" yaml:"desired,omitempty"`
+	Policy  string       `json:"policy,omitempty" yaml:"policy,omitempty"`
+	Status  ChangeStatus `json:"status" yaml:"status"`
+	Reason  string       `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Version is the Kubernetes version a nodegroup-ami change stays on.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ClusterPlan is one cluster's drift from its spec entry.
+type ClusterPlan struct {
+	Cluster           string   `json:"cluster" yaml:"cluster"`
+	Region            string   `json:"region,omitempty" yaml:"region,omitempty"`
+	KubernetesVersion string   `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+	DesiredVersion    string   `json:"desiredVersion,omitempty" yaml:"desiredVersion,omitempty"`
+	Changes           []Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+	// InSync counts what the spec manages that already matches it.
+	InSync   int      `json:"inSync" yaml:"inSync"`
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
+
+	spec ClusterSpec
+}
+
+// Count returns how many changes have status.
+func (p *ClusterPlan) Count(status ChangeStatus) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Blocked reports whether any change is blocked.
+func (p *ClusterPlan) Blocked() bool { return p.Count(ChangeBlocked) > 0 }
+
+// pending returns the pending changes of the given kinds.
+func (p *ClusterPlan) pending(kinds ...ChangeKind) []Change {
+	var out []Change
+	for _, c := range p.Changes {
+		if c.Status != ChangePending {
+			continue
+		}
+		for _, k := range kinds {
+			if c.Kind == k {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// Plan is the drift of every cluster a spec matches.
+type Plan struct {
+	Clusters []ClusterPlan `json:"clusters" yaml:"clusters"`
+	Warnings []string      `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Totals sums the changes across clusters by status, and counts clusters that
+// couldn't be planned.
+func (p *Plan) Totals() (pending, blocked, manual, errored int) {
+	for i := range p.Clusters {
+		c := &p.Clusters[i]
+		pending += c.Count(ChangePending)
+		blocked += c.Count(ChangeBlocked)
+		manual += c.Count(ChangeManual)
+		if c.Error != "" {
+			errored++
+		}
+	}
+	return pending, blocked, manual, errored
+}
+
+// Service plans and applies specs against the clusters of one region.
+type Service struct {
+	eksClient EKSAPI
+	amis      AMIReleases
+	logger    *slog.Logger
+	now       func() time.Time
+
+	// Upgrader runs apply's changes; NewService builds it on the same client.
+	Upgrader *upgrade.Service
+
+	mu     sync.Mutex
+	compat map[string]*addons.CompatibilityMatrix
+}
+
+// NewService creates a desired-state service for one region.
+func NewService(eksClient EKSAPI, amis AMIReleases, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		eksClient: eksClient,
+		amis:      amis,
+		logger:    logger,
+		now:       time.Now,
+		Upgrader:  upgrade.NewService(eksClient, logger),
+		compat:    map[string]*addons.CompatibilityMatrix{},
+	}
+}
+
+// Match returns the clusters in the region entry c selects: a plain name as
+// is, a glob against ListClusters, both narrowed by the tag selector.
+func (s *Service) Match(ctx context.Context, c ClusterSpec) ([]string, error) {
+	var names []string
+	if strings.ContainsAny(c.Name, "*?[") {
+		all, err := awsinternal.ListAllPages(ctx, "listing clusters",
+			func(rc context.Context, token *string) (*eks.ListClustersOutput, error) {
+				return s.eksClient.ListClusters(rc, &eks.ListClustersInput{NextToken: token})
+			},
+			func(out *eks.ListClustersOutput) ([]string, *string) { return out.Clusters, out.NextToken },
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range all {
+			if ok, _ := path.Match(c.Name, n); ok {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+	} else {
+		names = []string{c.Name}
+	}
+	if c.Selector == "" {
+		return names, nil
+	}
+	sel, err := selector.Parse(c.Selector)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, n := range names {
+		cl, err := s.describeCluster(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if sel.Matches(cl.Tags) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) describeCluster(ctx context.Context, name string) (*ekstypes.Cluster, error) {
+	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
+		return s.eksClient.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(name)})
+	})
+	if err != nil {
+		return nil, awsinternal.FormatAWSError(err, fmt.Sprintf("describing cluster %s", name))
+	}
+	if out.Cluster == nil {
+		return nil, fmt.Errorf("cluster %s not found", name)
+	}
+	return out.Cluster, nil
+}
+
+// compatibility returns an add-on's version catalogue, fetched once per
+// service: every cluster in the region shares it.
+func (s *Service) compatibility(ctx context.Context, name string) (*addons.CompatibilityMatrix, error) {
+	s.mu.Lock()
+	cm, ok := s.compat[name]
+	s.mu.Unlock()
+	if ok {
+		return cm, nil
+	}
+	cm, err := addons.NewService(s.eksClient, s.logger).GetCompatibility(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.compat[name] = cm
+	s.mu.Unlock()
+	return cm, nil
+}
+
+// PlanCluster diffs one cluster's live state against spec. Failures to read
+// the cluster land in the plan's Error rather than failing the whole plan.
+func (s *Service) PlanCluster(ctx context.Context, clusterName string, spec ClusterSpec) ClusterPlan {
+	p := ClusterPlan{Cluster: clusterName, spec: spec}
+	cluster, err := s.describeCluster(ctx, clusterName)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	p.KubernetesVersion = aws.ToString(cluster.Version)
+	p.DesiredVersion = spec.KubernetesVersion
+	if p.DesiredVersion == "" {
+		p.DesiredVersion = p.KubernetesVersion
+	}
+
+	switch {
+	case upgrade.CompareMinor(p.KubernetesVersion, p.DesiredVersion) < 0:
+		p.Changes = append(p.Changes, Change{
+			Kind: ChangeKubernetes, Current: p.KubernetesVersion, Desired: p.DesiredVersion, Status: ChangePending,
+			Reason: "control plane upgrade, one minor per hop",
+		})
+	case upgrade.CompareMinor(p.KubernetesVersion, p.DesiredVersion) > 0:
+		p.Changes = append(p.Changes, Change{
+			Kind: ChangeKubernetes, Current: p.KubernetesVersion, Desired: p.DesiredVersion, Status: ChangeManual,
+			Reason: "EKS can't downgrade a control plane",
+		})
+	case spec.KubernetesVersion != "":
+		p.InSync++
+	}
+
+	if err := s.planAddons(ctx, &p); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if err := s.planNodegroups(ctx, &p); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	return p
+}
+
+// planAddons diffs installed add-ons against their policies, against the
+// add-on catalogue for the desired Kubernetes version.
+func (s *Service) planAddons(ctx context.Context, p *ClusterPlan) error {
+	if len(p.spec.Addons) == 0 {
+		return nil
+	}
+	installed, err := addons.NewService(s.eksClient, s.logger).List(ctx, p.Cluster, addons.ListOptions{})
+	if err != nil {
+		return err
+	}
+	running := make(map[string]string, len(installed))
+	for _, a := range installed {
+		running[a.Name] = a.Version
+	}
+	for _, a := range addons.SortByDependency(installed) {
+		policy := p.spec.AddonPolicy(a.Name)
+		if policy == "" || upgrade.MatchesAny(a.Name, p.spec.Skip.Addons) {
+			continue
+		}
+		change := Change{Kind: ChangeAddon, Target: a.Name, Current: a.Version, Policy: policy}
+		cm, err := s.compatibility(ctx, a.Name)
+		if err != nil {
+			change.Status, change.Reason = ChangeBlocked, err.Error()
+			p.Changes = append(p.Changes, change)
+			continue
+		}
+		desired, reason := addonTarget(cm, policy, p.DesiredVersion)
+		change.Desired = desired
+		switch cmp := addons.CompareVersions(a.Version, desired); {
+		case desired == "":
+			change.Status, change.Reason = ChangeBlocked, reason
+		case cmp == 0:
+			p.InSync++
+			continue
+		case cmp > 0:
+			change.Status, change.Reason = ChangeManual, "EKS can't downgrade an add-on"
+		default:
+			change.Status, change.Reason = ChangePending, reason
+		}
+		p.Changes = append(p.Changes, change)
+	}
+	// Add-ons the spec names but the cluster doesn't run.
+	var missing []string
+	for name := range p.spec.Addons {
+		if _, ok := running[name]; !ok && name != AnyAddon && !upgrade.MatchesAny(name, p.spec.Skip.Addons) {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		p.Changes = append(p.Changes, Change{
+			Kind: ChangeAddon, Target: name, Policy: p.spec.Addons[name], Status: ChangeManual,
+			Reason: "not installed; apply updates add-ons but doesn't install them",
+		})
+	}
+	return nil
+}
+
+// addonTarget resolves a policy to a version for k8sVersion, or "" with the
+// reason there is none.
+func addonTarget(cm *addons.CompatibilityMatrix, policy, k8sVersion string) (string, string) {
+	switch policy {
+	case PolicyLatest:
+		if v := cm.Latest(k8sVersion); v != "" {
+			return v, "latest for " + k8sVersion
+		}
+		return "", fmt.Sprintf("no version of %s supports Kubernetes %s", cm.AddonName, k8sVersion)
+	case PolicyDefault:
+		if v := cm.DefaultVersions[k8sVersion]; v != "" {
+			return v, "default for " + k8sVersion
+		}
+		return "", fmt.Sprintf("EKS publishes no default %s version for Kubernetes %s", cm.AddonName, k8sVersion)
+	}
+	if _, ok := cm.Versions[policy]; !ok {
+		return "", fmt.Sprintf("pinned version %s is not published", policy)
+	}
+	if !cm.Supports(policy, k8sVersion) {
+		return "", fmt.Sprintf("pinned version %s doesn't support Kubernetes %s", policy, k8sVersion)
+	}
+	return policy, "pinned"
+}
+
+// planNodegroups diffs each nodegroup's Kubernetes version and AMI release.
+// A nodegroup behind the desired version rolls with the upgrade (which lands
+// the latest release, or the pinned one), so it only gets an AMI change when
+// its version already matches.
+func (s *Service) planNodegroups(ctx context.Context, p *ClusterPlan) error {
+	versionManaged := p.spec.KubernetesVersion != ""
+	if !versionManaged && !p.spec.Nodegroups.Set() {
+		return nil
+	}
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing nodegroups for cluster %s", p.Cluster),
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return s.eksClient.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(p.Cluster), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	policy := p.spec.Nodegroups
+	for _, name := range names {
+		if upgrade.MatchesAny(name, p.spec.Skip.Nodegroups) {
+			continue
+		}
+		out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
+			return s.eksClient.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{ClusterName: aws.String(p.Cluster), NodegroupName: aws.String(name)})
+		})
+		if err != nil {
+			return awsinternal.FormatAWSError(err, fmt.Sprintf("describing nodegroup %s", name))
+		}
+		ng := out.Nodegroup
+		if ng == nil {
+			continue
+		}
+		version, release := aws.ToString(ng.Version), aws.ToString(ng.ReleaseVersion)
+		custom := ng.AmiType == ekstypes.AMITypesCustom
+
+		if versionManaged && upgrade.CompareMinor(version, p.DesiredVersion) < 0 {
+			change := Change{Kind: ChangeNodegroupVersion, Target: name, Current: version, Desired: p.DesiredVersion, Status: ChangePending}
+			if custom {
+				change.Status, change.Reason = ChangeManual, "custom AMI: roll it with `cluster upgrade --custom-ami`"
+			} else if policy.Pinned() {
+				change.Reason = "rolls to release " + policy.AMI
+			}
+			p.Changes = append(p.Changes, change)
+			continue
+		}
+		if versionManaged {
+			p.InSync++
+		}
+		if !policy.Set() || custom {
+			continue
+		}
+		if change, drift := s.amiChange(ctx, name, version, release, ng.AmiType, policy); drift {
+			p.Changes = append(p.Changes, change)
+		} else if change.Reason != "" {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("nodegroup %s: %s", name, change.Reason))
+		} else {
+			p.InSync++
+		}
+	}
+	return nil
+}
+
+// amiChange measures one nodegroup against the AMI policy. No drift with a
+// reason means the policy couldn't be evaluated.
+func (s *Service) amiChange(ctx context.Context, name, version, release string, amiType ekstypes.AMITypes, policy NodegroupPolicy) (Change, bool) {
+	change := Change{Kind: ChangeNodegroupAMI, Target: name, Current: release, Policy: policy.String(), Status: ChangePending, Version: version}
+	if policy.Pinned() {
+		if release == policy.AMI {
+			return change, false
+		}
+		change.Desired = policy.AMI
+		if err := s.amis.Check(ctx, version, policy.AMI, amiType); err != nil {
+			change.Status, change.Reason = ChangeBlocked, err.Error()
+		}
+		return change, true
+	}
+	latest := s.amis.Latest(ctx, version, amiType)
+	if latest == "" {
+		change.Reason = fmt.Sprintf("latest %s release for %s unknown; AMI policy not checked", amiType, version)
+		return change, false
+	}
+	if release == latest {
+		return change, false
+	}
+	change.Desired = latest
+	if policy.MaxAgeDays > 0 {
+		age, ok := releaseAgeDays(release, s.now())
+		if !ok {
+			change.Reason = fmt.Sprintf("can't date release %q; max age not checked", release)
+			return change, false
+		}
+		if age <= policy.MaxAgeDays {
+			return change, false
+		}
+		change.Reason = fmt.Sprintf("%d days old", age)
+	}
+	return change, true
+}
+
+// releaseAgeDays is how many days ago an AMI release was built, from the date
+// stamp in its version.
+func releaseAgeDays(release string, now time.Time) (int, bool) {
+	stamp, ok := amichangelog.ReleaseDate(release)
+	if !ok {
+		return 0, false
+	}
+	built, err := time.Parse("20060102", stamp)
+	if err != nil {
+		return 0, false
+	}
+	return int(now.Sub(built).Hours() / 24), true
+}
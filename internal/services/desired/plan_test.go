@@ -0,0 +1,333 @@
+package desired
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/mocks"
+)
+
+// fakeReleases serves the newest AMI release per Kubernetes version and
+// accepts only releases in published.
+type fakeReleases struct {
+	latest    map[string]string
+	published []string
+}
+
+func (f fakeReleases) Latest(_ context.Context, k8s string, _ ekstypes.AMITypes) string {
+	return f.latest[k8s]
+}
+
+func (f fakeReleases) Check(_ context.Context, k8s, release string, _ ekstypes.AMITypes) error {
+	for _, p := range f.published {
+		if p == release {
+			return nil
+		}
+	}
+	return fmt.Errorf("release %s is not published for %s", release, k8s)
+}
+
+type testNodegroup struct {
+	name, version, release string
+	amiType                ekstypes.AMITypes
+}
+
+// catalogue wires DescribeAddonVersions to a per-add-on list of versions,
+// each compatible with the listed minors; the first minor's default is the
+// version marked with a trailing "*".
+func catalogue(m *mocks.EKSAPI, versions map[string]map[string][]string) {
+	m.DescribeAddonVersionsFn = func(_ context.Context, in *eks.DescribeAddonVersionsInput, _ ...func(*eks.Options)) (*eks.DescribeAddonVersionsOutput, error) {
+		name := aws.ToString(in.AddonName)
+		var infos []ekstypes.AddonVersionInfo
+		for v, k8s := range versions[name] {
+			def := strings.HasSuffix(v, "*")
+			info := ekstypes.AddonVersionInfo{AddonVersion: aws.String(strings.TrimSuffix(v, "*"))}
+			for _, k := range k8s {
+				info.Compatibilities = append(info.Compatibilities, ekstypes.Compatibility{ClusterVersion: aws.String(k), DefaultVersion: def})
+			}
+			infos = append(infos, info)
+		}
+		return &eks.DescribeAddonVersionsOutput{Addons: []ekstypes.AddonInfo{{AddonName: aws.String(name), AddonVersions: infos}}}, nil
+	}
+}
+
+// nodegroups wires ListNodegroups and DescribeNodegroup with release versions.
+func nodegroups(m *mocks.EKSAPI, ngs ...testNodegroup) {
+	m.ListNodegroupsFn = func(_ context.Context, _ *eks.ListNodegroupsInput, _ ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+		out := &eks.ListNodegroupsOutput{}
+		for _, ng := range ngs {
+			out.Nodegroups = append(out.Nodegroups, ng.name)
+		}
+		return out, nil
+	}
+	m.DescribeNodegroupFn = func(_ context.Context, in *eks.DescribeNodegroupInput, _ ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+		for _, ng := range ngs {
+			if ng.name == aws.ToString(in.NodegroupName) {
+				return &eks.DescribeNodegroupOutput{Nodegroup: &ekstypes.Nodegroup{
+					NodegroupName:  aws.String(ng.name),
+					Version:        aws.String(ng.version),
+					ReleaseVersion: aws.String(ng.release),
+					AmiType:        ng.amiType,
+					Status:         ekstypes.NodegroupStatusActive,
+				}}, nil
+			}
+		}
+		return nil, &ekstypes.ResourceNotFoundException{Message: aws.String("nodegroup not found")}
+	}
+}
+
+func newTestService(m *mocks.EKSAPI, amis AMIReleases) *Service {
+	s := NewService(m, amis, slog.New(slog.DiscardHandler))
+	s.Upgrader.PollInterval = time.Millisecond
+	s.now = func() time.Time { return time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) }
+	return s
+}
+
+// driftMock is a 1.33 cluster whose add-ons and nodegroups exercise every
+// status.
+func driftMock() *mocks.EKSAPI {
+	m := mocks.NewEKSAPI().
+		WithCluster("prod-east", "1.33").
+		WithAddon("vpc-cni", "v1.20.0-eksbuild.1", ekstypes.AddonStatusActive).
+		WithAddon("coredns", "v1.11.1-eksbuild.1", ekstypes.AddonStatusActive).
+		WithAddon("kube-proxy", "v1.33.0-eksbuild.1", ekstypes.AddonStatusActive).
+		WithAddon("aws-load-balancer-controller", "v2.8.0", ekstypes.AddonStatusActive).
+		WithDescribeUpdate(ekstypes.UpdateStatusSuccessful).
+		Build()
+	catalogue(m, map[string]map[string][]string{
+		"vpc-cni":    {"v1.19.0-eksbuild.1": {"1.32", "1.33"}},
+		"coredns":    {"v1.11.1-eksbuild.1": {"1.32"}, "v1.11.4-eksbuild.2*": {"1.33"}, "v1.12.0-eksbuild.1": {"1.33"}},
+		"kube-proxy": {"v1.33.0-eksbuild.1": {"1.33"}, "v1.33.3-eksbuild.1": {"1.33"}},
+	})
+	nodegroups(m,
+		testNodegroup{"workers-a", "1.33", "1.33.0-20260801", ekstypes.AMITypesAl2023X8664Standard},
+		testNodegroup{"workers-b", "1.33", "1.33.0-20260920", ekstypes.AMITypesAl2023X8664Standard},
+		testNodegroup{"gpu-a", "1.33", "1.33.0-20250101", ekstypes.AMITypesAl2023X8664Nvidia},
+		testNodegroup{"custom", "1.33", "", ekstypes.AMITypesCustom},
+	)
+	return m
+}
+
+func driftSpec() ClusterSpec {
+	s := &Spec{Clusters: []ClusterSpec{{
+		Name:              "prod-east",
+		KubernetesVersion: "1.33",
+		Addons: map[string]string{
+			AnyAddon:         PolicyLatest,
+			"coredns":        PolicyDefault,
+			"kube-proxy":     "v1.33.0-eksbuild.1",
+			"metrics-server": PolicyLatest,
+		},
+		Nodegroups: NodegroupPolicy{AMI: PolicyLatest, MaxAgeDays: 30},
+		Skip:       SkipLists{Addons: []string{"aws-load-balancer"}, Nodegroups: []string{"gpu-"}},
+	}}}
+	return s.Resolve(0)
+}
+
+func byTarget(p ClusterPlan) map[string]Change {
+	out := map[string]Change{}
+	for _, c := range p.Changes {
+		out[string(c.Kind)+"/"+c.Target] = c
+	}
+	return out
+}
+
+func TestPlanCluster_Drift(t *testing.T) {
+	svc := newTestService(driftMock(), fakeReleases{latest: map[string]string{"1.33": "1.33.0-20260925"}})
+	p := svc.PlanCluster(context.Background(), "prod-east", driftSpec())
+	if p.Error != "" {
+		t.Fatalf("plan error: %s", p.Error)
+	}
+	changes := byTarget(p)
+
+	tests := []struct {
+		key            string
+		status         ChangeStatus
+		desired        string
+		reasonContains string
+	}{
+		{"addon/coredns", ChangePending, "v1.11.4-eksbuild.2", "default for 1.33"},
+		{"addon/vpc-cni", ChangeManual, "v1.19.0-eksbuild.1", "downgrade"},
+		{"addon/metrics-server", ChangeManual, "", "not installed"},
+		{"nodegroup-ami/workers-a", ChangePending, "1.33.0-20260925", "61 days old"},
+		{"nodegroup-ami/custom", "", "", ""},
+	}
+	for _, tt := range tests {
+		c, ok := changes[tt.key]
+		if tt.status == "" {
+			if ok {
+				t.Errorf("%s: unexpected change %+v", tt.key, c)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s: no change planned; got %v", tt.key, p.Changes)
+			continue
+		}
+		if c.Status != tt.status || c.Desired != tt.desired || !strings.Contains(c.Reason, tt.reasonContains) {
+			t.Errorf("%s = %+v, want %s to %q (%s)", tt.key, c, tt.status, tt.desired, tt.reasonContains)
+		}
+	}
+	for key := range changes {
+		if strings.Contains(key, "aws-load-balancer") || strings.Contains(key, "gpu-") {
+			t.Errorf("%s is on a skip list but was planned", key)
+		}
+	}
+	// Control plane, kube-proxy and workers-b (10 days old, within max age)
+	// already match; every nodegroup at 1.33 counts for the version too.
+	if p.InSync != 6 {
+		t.Errorf("InSync = %d, want 6", p.InSync)
+	}
+}
+
+func TestPlanCluster_VersionDriftAndBlockedPin(t *testing.T) {
+	m := mocks.NewEKSAPI().
+		WithCluster("prod-east", "1.32").
+		WithAddon("kube-proxy", "v1.32.0-eksbuild.1", ekstypes.AddonStatusActive).
+		Build()
+	catalogue(m, map[string]map[string][]string{
+		"kube-proxy": {"v1.32.0-eksbuild.1": {"1.32"}, "v1.33.0-eksbuild.1": {"1.33"}},
+	})
+	nodegroups(m, testNodegroup{"workers", "1.32", "1.32.0-20260801", ekstypes.AMITypesAl2023X8664Standard})
+	svc := newTestService(m, fakeReleases{})
+
+	spec := ClusterSpec{
+		Name:              "prod-east",
+		KubernetesVersion: "1.33",
+		Addons:            map[string]string{"kube-proxy": "v1.32.0-eksbuild.1"},
+		Nodegroups:        NodegroupPolicy{AMI: "1.33.0-20260601"},
+	}
+	p := svc.PlanCluster(context.Background(), "prod-east", spec)
+	changes := byTarget(p)
+
+	if c := changes["kubernetes/"]; c.Status != ChangePending || c.Current != "1.32" || c.Desired != "1.33" {
+		t.Errorf("kubernetes change = %+v, want pending 1.32 → 1.33", c)
+	}
+	if c := changes["nodegroup-version/workers"]; c.Status != ChangePending || !strings.Contains(c.Reason, "1.33.0-20260601") {
+		t.Errorf("nodegroup change = %+v, want pending onto the pinned release", c)
+	}
+	if c := changes["addon/kube-proxy"]; c.Status != ChangeBlocked || !strings.Contains(c.Reason, "doesn't support Kubernetes 1.33") {
+		t.Errorf("kube-proxy change = %+v, want blocked: the pin doesn't support 1.33", c)
+	}
+	if !p.Blocked() {
+		t.Error("Blocked() = false, want true")
+	}
+
+	_, err := svc.Apply(context.Background(), &p, ApplyOptions{Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Fatalf("Apply of a blocked plan = %v, want a refusal", err)
+	}
+	if m.Calls.UpdateClusterVersion != 0 {
+		t.Error("a blocked plan must not touch the cluster")
+	}
+}
+
+func TestPlanCluster_DowngradeIsManualAndUnpublishedPinBlocked(t *testing.T) {
+	m := mocks.NewEKSAPI().WithCluster("prod-east", "1.33").Build()
+	nodegroups(m, testNodegroup{"workers", "1.33", "1.33.0-20260801", ekstypes.AMITypesAl2023X8664Standard})
+	svc := newTestService(m, fakeReleases{published: []string{"1.33.0-20260901"}})
+
+	p := svc.PlanCluster(context.Background(), "prod-east", ClusterSpec{
+		Name: "prod-east", KubernetesVersion: "1.32", Nodegroups: NodegroupPolicy{AMI: "1.33.0-20260999"},
+	})
+	changes := byTarget(p)
+	if c := changes["kubernetes/"]; c.Status != ChangeManual {
+		t.Errorf("kubernetes change = %+v, want manual (no control plane downgrades)", c)
+	}
+	if c := changes["nodegroup-ami/workers"]; c.Status != ChangeBlocked {
+		t.Errorf("AMI change = %+v, want blocked on an unpublished release", c)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := mocks.NewEKSAPI().Build()
+	m.ListClustersFn = func(_ context.Context, _ *eks.ListClustersInput, _ ...func(*eks.Options)) (*eks.ListClustersOutput, error) {
+		return &eks.ListClustersOutput{Clusters: []string{"prod-west", "dev-east", "prod-east"}}, nil
+	}
+	m.DescribeClusterFn = func(_ context.Context, in *eks.DescribeClusterInput, _ ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+		tags := map[string]string{"env": "prod"}
+		if aws.ToString(in.Name) == "prod-west" {
+			tags["team"] = "data"
+		}
+		return &eks.DescribeClusterOutput{Cluster: &ekstypes.Cluster{Name: in.Name, Tags: tags}}, nil
+	}
+	svc := newTestService(m, fakeReleases{})
+
+	got, err := svc.Match(context.Background(), ClusterSpec{Name: "prod-*"})
+	if err != nil || !reflect.DeepEqual(got, []string{"prod-east", "prod-west"}) {
+		t.Errorf("Match(prod-*) = %v, %v", got, err)
+	}
+	got, err = svc.Match(context.Background(), ClusterSpec{Name: "prod-*", Selector: "team!=data"})
+	if err != nil || !reflect.DeepEqual(got, []string{"prod-east"}) {
+		t.Errorf("Match(prod-*, team!=data) = %v, %v", got, err)
+	}
+	got, _ = svc.Match(context.Background(), ClusterSpec{Name: "staging"})
+	if !reflect.DeepEqual(got, []string{"staging"}) {
+		t.Errorf("Match(staging) = %v, want the name as is", got)
+	}
+}
+
+func TestApply_AddonsAndAMIs(t *testing.T) {
+	m := driftMock()
+	var mu sync.Mutex
+	var addonUpdates, rolls []string
+	m.UpdateAddonFn = func(_ context.Context, in *eks.UpdateAddonInput, _ ...func(*eks.Options)) (*eks.UpdateAddonOutput, error) {
+		mu.Lock()
+		addonUpdates = append(addonUpdates, aws.ToString(in.AddonName)+"@"+aws.ToString(in.AddonVersion))
+		mu.Unlock()
+		return &eks.UpdateAddonOutput{Update: &ekstypes.Update{Id: aws.String("ua"), Status: ekstypes.UpdateStatusInProgress}}, nil
+	}
+	m.UpdateNodegroupVersionFn = func(_ context.Context, in *eks.UpdateNodegroupVersionInput, _ ...func(*eks.Options)) (*eks.UpdateNodegroupVersionOutput, error) {
+		mu.Lock()
+		rolls = append(rolls, aws.ToString(in.NodegroupName)+"@"+aws.ToString(in.ReleaseVersion))
+		mu.Unlock()
+		return &eks.UpdateNodegroupVersionOutput{Update: &ekstypes.Update{Id: aws.String("un"), Status: ekstypes.UpdateStatusInProgress}}, nil
+	}
+	svc := newTestService(m, fakeReleases{latest: map[string]string{"1.33": "1.33.0-20260925"}})
+	p := svc.PlanCluster(context.Background(), "prod-east", driftSpec())
+
+	var confirmed []string
+	report, err := svc.Apply(context.Background(), &p, ApplyOptions{
+		Confirm:       func(label string) bool { confirmed = append(confirmed, label); return true },
+		NodegroupGate: func(context.Context, string) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := []string{"coredns@v1.11.4-eksbuild.2"}; !reflect.DeepEqual(addonUpdates, want) {
+		t.Errorf("add-on updates = %v, want %v (manual changes left alone)", addonUpdates, want)
+	}
+	if want := []string{"workers-a@1.33.0-20260925"}; !reflect.DeepEqual(rolls, want) {
+		t.Errorf("nodegroup rolls = %v, want %v", rolls, want)
+	}
+	if len(confirmed) != 2 || len(report.Completed) != 2 {
+		t.Errorf("confirmed %v, completed %v; want both phases", confirmed, report.Completed)
+	}
+	if m.Calls.UpdateClusterVersion != 0 {
+		t.Error("no version drift, but the control plane was upgraded")
+	}
+}
+
+func TestApply_DeclinedPhaseStops(t *testing.T) {
+	m := driftMock()
+	svc := newTestService(m, fakeReleases{latest: map[string]string{"1.33": "1.33.0-20260925"}})
+	p := svc.PlanCluster(context.Background(), "prod-east", driftSpec())
+
+	report, err := svc.Apply(context.Background(), &p, ApplyOptions{Confirm: func(string) bool { return false }})
+	if err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Fatalf("Apply declined = %v, want aborted", err)
+	}
+	if len(report.Remaining) != 2 || m.Calls.UpdateAddon != 0 {
+		t.Errorf("remaining %v, %d add-on updates; want both phases left and nothing touched", report.Remaining, m.Calls.UpdateAddon)
+	}
+}
@@ -0,0 +1,31 @@
+package desired
+
+import (
+	"context"
+
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+)
+
+// SSMReleases resolves AMI releases from the public EKS-optimized AMI SSM
+// parameters, as nodegroup update and cluster upgrade do.
+type SSMReleases struct {
+	Client *ssm.Client
+}
+
+// Latest implements AMIReleases.
+func (r SSMReleases) Latest(ctx context.Context, k8sVersion string, amiType ekstypes.AMITypes) string {
+	return awsinternal.LatestReleaseVersionForType(ctx, r.Client, k8sVersion, amiType)
+}
+
+// Check implements AMIReleases.
+func (r SSMReleases) Check(ctx context.Context, k8sVersion, release string, amiType ekstypes.AMITypes) error {
+	releases, err := awsinternal.ReleasesForType(ctx, r.Client, k8sVersion, amiType)
+	if err != nil {
+		return err
+	}
+	_, err = awsinternal.FindRelease(releases, release, amiType, k8sVersion)
+	return err
+}
@@ -0,0 +1,222 @@
+// Package desired implements declarative cluster state: a spec file naming
+// the Kubernetes version, add-on version policies and nodegroup AMI policy a
+// cluster (or a fleet of them) should run, a plan that diffs live state
+// against it, and an apply that reconciles the drift through the upgrade
+// orchestrator's addon and nodegroup machinery, gates included.
+//
+// Like the orchestrator, nothing is recorded between runs: every plan is
+// derived from live state, so an apply interrupted halfway is finished by
+// running it again.
+package desired
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dantech2000/refresh/internal/selector"
+)
+
+// DefaultSpecFile is the spec plan and apply read when not told otherwise.
+const DefaultSpecFile = "refresh-spec.yaml"
+
+// Addon version policies. Any other value pins an exact version.
+const (
+	PolicyLatest  = "latest"
+	PolicyDefault = "default"
+)
+
+// AnyAddon is the addons key whose policy covers installed add-ons the spec
+// doesn't name.
+const AnyAddon = "*"
+
+// Spec is one desired-state file:
+//
+//	defaults:
+//	  kubernetesVersion: "1.33"
+//	  addons: {"*": latest}
+//	  nodegroups: {ami: latest, maxAgeDays: 30}
+//	clusters:
+//	  - name: prod-*
+//	    region: us-east-1
+//	    selector: env=prod
+//	    addons:
+//	      coredns: default
+//	      kube-proxy: v1.33.0-eksbuild.1
+//	    skip:
+//	      addons: [aws-load-balancer-controller]
+//	      nodegroups: [gpu-]
+//
+// Every cluster entry is layered over defaults.
+type Spec struct {
+	Defaults ClusterSpec   `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	Clusters []ClusterSpec `json:"clusters" yaml:"clusters"`
+}
+
+// ClusterSpec is the desired state for the clusters one entry matches.
+type ClusterSpec struct {
+	// Name is a cluster name or a glob over names in the region.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Region defaults to the region of the run.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Selector narrows Name's matches by EKS tag (env=prod,team!=data).
+	Selector string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// KubernetesVersion is the minor the control plane and nodegroups run.
+	// Empty leaves the version alone.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+	// Addons maps add-on name (or "*") to latest, default or an exact version.
+	Addons     map[string]string `json:"addons,omitempty" yaml:"addons,omitempty"`
+	Nodegroups NodegroupPolicy   `json:"nodegroups,omitempty" yaml:"nodegroups,omitempty"`
+	Skip       SkipLists         `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+// NodegroupPolicy is the AMI release nodegroups should run: the latest
+// (ami: latest), a pinned release (ami: 1.33.0-20260601), or the latest once
+// the running release is older than MaxAgeDays.
+type NodegroupPolicy struct {
+	AMI        string `json:"ami,omitempty" yaml:"ami,omitempty"`
+	MaxAgeDays int    `json:"maxAgeDays,omitempty" yaml:"maxAgeDays,omitempty"`
+}
+
+// SkipLists name what the spec never touches: add-ons managed elsewhere (Helm,
+// GitOps) and nodegroup name patterns.
+type SkipLists struct {
+	Addons     []string `json:"addons,omitempty" yaml:"addons,omitempty"`
+	Nodegroups []string `json:"nodegroups,omitempty" yaml:"nodegroups,omitempty"`
+}
+
+// Pinned reports whether the policy pins an AMI release.
+func (p NodegroupPolicy) Pinned() bool {
+	return p.AMI != "" && p.AMI != PolicyLatest
+}
+
+// Set reports whether the policy says anything about AMIs.
+func (p NodegroupPolicy) Set() bool { return p.AMI != "" || p.MaxAgeDays > 0 }
+
+// String describes the policy for plan output.
+func (p NodegroupPolicy) String() string {
+	switch {
+	case p.Pinned():
+		return "release " + p.AMI
+	case p.MaxAgeDays > 0:
+		return fmt.Sprintf("max age %dd", p.MaxAgeDays)
+	}
+	return PolicyLatest
+}
+
+// AddonPolicy returns the policy for an installed add-on: its own entry, else
+// the "*" entry, else "" (unmanaged).
+func (c ClusterSpec) AddonPolicy(name string) string {
+	if p, ok := c.Addons[name]; ok {
+		return p
+	}
+	return c.Addons[AnyAddon]
+}
+
+// LoadSpec reads and validates a spec file.
+func LoadSpec(file string) (*Spec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &spec, nil
+}
+
+// Validate checks every entry as it resolves over the defaults.
+func (s *Spec) Validate() error {
+	if len(s.Clusters) == 0 {
+		return fmt.Errorf("no clusters: list at least one under clusters")
+	}
+	for i, entry := range s.Clusters {
+		c := s.Resolve(i)
+		label := fmt.Sprintf("clusters[%d]", i)
+		if entry.Name != "" {
+			label += " (" + entry.Name + ")"
+		}
+		if c.Name == "" {
+			return fmt.Errorf("%s: name is required (a cluster name or a glob)", label)
+		}
+		if _, err := path.Match(c.Name, ""); err != nil {
+			return fmt.Errorf("%s: name %q: %w", label, c.Name, err)
+		}
+		if c.Selector != "" {
+			if _, err := selector.Parse(c.Selector); err != nil {
+				return fmt.Errorf("%s: selector: %w", label, err)
+			}
+		}
+		if v := c.KubernetesVersion; v != "" && !minorVersion(v) {
+			return fmt.Errorf("%s: kubernetesVersion %q: want a minor version like 1.33", label, v)
+		}
+		for name, policy := range c.Addons {
+			if strings.TrimSpace(policy) == "" {
+				return fmt.Errorf("%s: addons.%s: want latest, default or a version", label, name)
+			}
+		}
+		if c.Nodegroups.MaxAgeDays < 0 {
+			return fmt.Errorf("%s: nodegroups.maxAgeDays must be positive", label)
+		}
+		if c.Nodegroups.Pinned() && c.Nodegroups.MaxAgeDays > 0 {
+			return fmt.Errorf("%s: nodegroups: a pinned ami release and maxAgeDays are mutually exclusive", label)
+		}
+	}
+	return nil
+}
+
+// Resolve returns entry i layered over the defaults: scalars override, add-on
+// policies merge by name and skip lists add up.
+func (s *Spec) Resolve(i int) ClusterSpec {
+	d, e := s.Defaults, s.Clusters[i]
+	out := d
+	if e.Name != "" {
+		out.Name = e.Name
+	}
+	if e.Region != "" {
+		out.Region = e.Region
+	}
+	if e.Selector != "" {
+		out.Selector = e.Selector
+	}
+	if e.KubernetesVersion != "" {
+		out.KubernetesVersion = e.KubernetesVersion
+	}
+	out.Addons = make(map[string]string, len(d.Addons)+len(e.Addons))
+	for k, v := range d.Addons {
+		out.Addons[k] = v
+	}
+	for k, v := range e.Addons {
+		out.Addons[k] = v
+	}
+	if e.Nodegroups.Set() {
+		out.Nodegroups = e.Nodegroups
+	}
+	out.Skip = SkipLists{
+		Addons:     append(append([]string(nil), d.Skip.Addons...), e.Skip.Addons...),
+		Nodegroups: append(append([]string(nil), d.Skip.Nodegroups...), e.Skip.Nodegroups...),
+	}
+	out.KubernetesVersion = strings.TrimPrefix(strings.TrimSpace(out.KubernetesVersion), "v")
+	return out
+}
+
+// minorVersion accepts "1.N".
+func minorVersion(v string) bool {
+	major, minor, ok := strings.Cut(v, ".")
+	if !ok || major != "1" || minor == "" {
+		return false
+	}
+	for _, c := range minor {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package desired
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolve_LayersEntryOverDefaults(t *testing.T) {
+	s := &Spec{
+		Defaults: ClusterSpec{
+			KubernetesVersion: "1.32",
+			Addons:            map[string]string{AnyAddon: PolicyLatest, "coredns": PolicyLatest},
+			Nodegroups:        NodegroupPolicy{AMI: PolicyLatest, MaxAgeDays: 30},
+			Skip:              SkipLists{Addons: []string{"aws-load-balancer-controller"}},
+		},
+		Clusters: []ClusterSpec{
+			{Name: "prod-*", KubernetesVersion: "v1.33", Addons: map[string]string{"coredns": PolicyDefault}, Skip: SkipLists{Addons: []string{"karpenter"}, Nodegroups: []string{"gpu-"}}},
+			{Name: "dev", Nodegroups: NodegroupPolicy{AMI: "1.32.0-20260601"}},
+		},
+	}
+
+	prod := s.Resolve(0)
+	if prod.KubernetesVersion != "1.33" {
+		t.Errorf("KubernetesVersion = %q, want 1.33 (entry wins, v trimmed)", prod.KubernetesVersion)
+	}
+	if want := map[string]string{AnyAddon: PolicyLatest, "coredns": PolicyDefault}; !reflect.DeepEqual(prod.Addons, want) {
+		t.Errorf("Addons = %v, want %v", prod.Addons, want)
+	}
+	if prod.Nodegroups.MaxAgeDays != 30 {
+		t.Errorf("Nodegroups = %+v, want the defaults", prod.Nodegroups)
+	}
+	if want := []string{"aws-load-balancer-controller", "karpenter"}; !reflect.DeepEqual(prod.Skip.Addons, want) {
+		t.Errorf("Skip.Addons = %v, want %v", prod.Skip.Addons, want)
+	}
+
+	dev := s.Resolve(1)
+	if dev.KubernetesVersion != "1.32" || !dev.Nodegroups.Pinned() || dev.Nodegroups.MaxAgeDays != 0 {
+		t.Errorf("dev = %+v, want defaults' version and its own pinned AMI", dev)
+	}
+	if len(s.Defaults.Skip.Addons) != 1 {
+		t.Errorf("Resolve mutated the defaults' skip list: %v", s.Defaults.Skip.Addons)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec Spec
+		want string
+	}{
+		{"no clusters", Spec{}, "no clusters"},
+		{"missing name", Spec{Clusters: []ClusterSpec{{Region: "us-east-1"}}}, "name is required"},
+		{"bad glob", Spec{Clusters: []ClusterSpec{{Name: "prod-["}}}, "name"},
+		{"bad selector", Spec{Clusters: []ClusterSpec{{Name: "a", Selector: "=x"}}}, "selector"},
+		{"bad version", Spec{Clusters: []ClusterSpec{{Name: "a", KubernetesVersion: "1.33.2"}}}, "kubernetesVersion"},
+		{"empty addon policy", Spec{Clusters: []ClusterSpec{{Name: "a", Addons: map[string]string{"coredns": " "}}}}, "addons.coredns"},
+		{"pin and max age", Spec{
+			Defaults: ClusterSpec{Nodegroups: NodegroupPolicy{MaxAgeDays: 30}},
+			Clusters: []ClusterSpec{{Name: "a", Nodegroups: NodegroupPolicy{AMI: "1.33.0-20260601", MaxAgeDays: 10}}},
+		}, "mutually exclusive"},
+		{"valid", Spec{Clusters: []ClusterSpec{{Name: "prod-*", Selector: "env=prod", KubernetesVersion: "1.33"}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Validate() = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("Validate() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultSpecFile)
+	data := `defaults:
+  kubernetesVersion: "1.33"
+  addons: {"*": latest}
+clusters:
+  - name: prod-east
+    addons:
+      kube-proxy: v1.33.0-eksbuild.1
+    nodegroups: {ami: 1.33.0-20260601}
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadSpec(file)
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	c := spec.Resolve(0)
+	if c.Name != "prod-east" || c.AddonPolicy("kube-proxy") != "v1.33.0-eksbuild.1" || c.AddonPolicy("coredns") != PolicyLatest {
+		t.Errorf("resolved entry = %+v", c)
+	}
+	if got := c.Nodegroups.String(); got != "release 1.33.0-20260601" {
+		t.Errorf("Nodegroups.String() = %q", got)
+	}
+
+	if err := os.WriteFile(file, []byte("clusters: [{region: us-east-1}]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSpec(file); err == nil || !strings.Contains(err.Error(), file) {
+		t.Errorf("LoadSpec of an invalid spec = %v, want an error naming the file", err)
+	}
+}
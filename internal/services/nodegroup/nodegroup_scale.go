@@ -27,8 +27,28 @@ func (s *ServiceImpl) Scale(ctx context.Context, clusterName, nodegroupName stri
 		return nil
 	}
 
+	var checker *health.HealthChecker
 	if options.HealthCheck && s.healthChecker != nil {
-		summary := s.healthChecker.RunAllChecks(ctx, clusterName)
+		checker = s.healthChecker
+		if options.HealthOptions != nil {
+			opts, err := options.HealthOptions(clusterName)
+			if err != nil {
+				return err
+			}
+			if checker, err = checker.WithOptions(opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	if checker != nil {
+		// A scale-down drains nodes: simulate whether their pods fit on what's
+		// left, as a blocking check in the same summary.
+		if plan, ok := s.scaleDownPlan(ctx, clusterName, nodegroupName, desired); ok {
+			checker.SetDrainPlans([]health.DrainPlan{plan})
+		}
+		summary := checker.RunAllChecks(ctx, clusterName)
+		checker.SetDrainPlans(nil)
 		if summary.Decision == health.DecisionBlock {
 			return fmt.Errorf("pre-scaling health check blocked operation: %v", summary.Errors)
 		}
@@ -90,8 +110,8 @@ func (s *ServiceImpl) Scale(ctx context.Context, clusterName, nodegroupName stri
 		}
 	}
 
-	if options.HealthCheck && s.healthChecker != nil {
-		summary := s.healthChecker.RunAllChecks(ctx, clusterName)
+	if checker != nil {
+		summary := checker.RunAllChecks(ctx, clusterName)
 		if summary.Decision == health.DecisionBlock {
 			return fmt.Errorf("post-scaling health check blocked operation: %v", summary.Errors)
 		}
@@ -102,6 +122,28 @@ func (s *ServiceImpl) Scale(ctx context.Context, clusterName, nodegroupName stri
 	return nil
 }
 
+// scaleDownPlan returns the drain plan when desired shrinks the nodegroup.
+// ok is false for a scale-up, an unchanged size, or a failed describe (the
+// simulation is then left out rather than guessed).
+func (s *ServiceImpl) scaleDownPlan(ctx context.Context, clusterName, nodegroupName string, desired *int32) (health.DrainPlan, bool) {
+	if desired == nil {
+		return health.DrainPlan{}, false
+	}
+	out, err := s.eksClient.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(nodegroupName),
+	})
+	if err != nil || out.Nodegroup == nil || out.Nodegroup.ScalingConfig == nil || out.Nodegroup.ScalingConfig.DesiredSize == nil {
+		s.logger.Warn("scheduling simulation skipped: could not read the current size", "nodegroup", nodegroupName, "error", err)
+		return health.DrainPlan{}, false
+	}
+	current := *out.Nodegroup.ScalingConfig.DesiredSize
+	if *desired >= current {
+		return health.DrainPlan{}, false
+	}
+	return health.ScaleDownDrainPlan(nodegroupName, int(current-*desired)), true
+}
+
 func (s *ServiceImpl) waitForScaleCompletion(ctx context.Context, clusterName, nodegroupName string, desired *int32, timeout time.Duration) error {
 	waitCtx := ctx
 	if timeout > 0 {
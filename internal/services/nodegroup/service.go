@@ -217,6 +217,9 @@ func (s *ServiceImpl) List(ctx context.Context, clusterName string, options List
 				CurrentAMI:   currentAmiId,
 				AMIStatus:    amiStatus,
 			}
+			if ng.AmiType != ekstypes.AMITypesCustom {
+				summary.ReleaseVersion = aws.ToString(ng.ReleaseVersion)
+			}
 			if !matchesFilters(summary, options.Filters) {
 				return nil
 			}
@@ -5,11 +5,18 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dantech2000/refresh/internal/health"
 	"github.com/dantech2000/refresh/internal/mocks"
 	"github.com/dantech2000/refresh/internal/types"
 )
@@ -358,3 +365,77 @@ func TestScale_PropagatesUpdateError(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestScale_SchedulingSimulationBlocksScaleDown(t *testing.T) {
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"eks.amazonaws.com/nodegroup": "workers"}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi"), corev1.ResourcePods: resource.MustParse("110")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	ctrl := true
+	pod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &ctrl}}},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{Name: "c", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")},
+			}}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	kube := fakek8s.NewClientset(node("a"), node("b"), pod("db-0", "a"), pod("db-1", "b"))
+	hc := health.NewChecker(nil, kube, nil, nil)
+	if err := hc.SetOptions(health.Options{Thresholds: health.DefaultThresholds(), Checks: []string{health.CheckSchedulingID}}); err != nil {
+		t.Fatal(err)
+	}
+	mock := &mocks.EKSAPI{
+		DescribeNodegroupFn: func(_ context.Context, _ *eks.DescribeNodegroupInput, _ ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+			return &eks.DescribeNodegroupOutput{Nodegroup: &ekstypes.Nodegroup{ScalingConfig: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(2)}}}, nil
+		},
+		UpdateNodegroupConfigFn: func(_ context.Context, _ *eks.UpdateNodegroupConfigInput, _ ...func(*eks.Options)) (*eks.UpdateNodegroupConfigOutput, error) {
+			return &eks.UpdateNodegroupConfigOutput{}, nil
+		},
+	}
+	svc := newTestService(mock)
+	svc.healthChecker = hc
+
+	err := svc.Scale(context.Background(), "my-cluster", "workers", aws.Int32(1), nil, nil, ScaleOptions{HealthCheck: true})
+	if err == nil || !strings.Contains(err.Error(), "unschedulable") {
+		t.Fatalf("err = %v, want the scheduling simulation to block", err)
+	}
+	if mock.Calls.UpdateNodegroupConfig != 0 {
+		t.Error("a blocked scale-down must not call UpdateNodegroupConfig")
+	}
+
+	// Scaling up drains nothing, so the simulation stays out of the run.
+	if err := svc.Scale(context.Background(), "my-cluster", "workers", aws.Int32(3), nil, nil, ScaleOptions{HealthCheck: true}); err != nil {
+		t.Fatalf("scale-up: %v", err)
+	}
+
+	// The cluster's own health options (refresh.yaml, --blocking) apply:
+	// with scheduling made non-blocking the scale-down goes ahead.
+	calls := mock.Calls.UpdateNodegroupConfig
+	lenient := ScaleOptions{HealthCheck: true, HealthOptions: func(cluster string) (health.Options, error) {
+		if cluster != "my-cluster" {
+			t.Errorf("health options resolved for %q", cluster)
+		}
+		return health.Options{
+			Thresholds: health.DefaultThresholds(),
+			Checks:     []string{health.CheckSchedulingID},
+			Blocking:   map[string]bool{health.CheckSchedulingID: false},
+		}, nil
+	}}
+	if err := svc.Scale(context.Background(), "my-cluster", "workers", aws.Int32(1), nil, nil, lenient); err != nil {
+		t.Fatalf("non-blocking scheduling: %v", err)
+	}
+	if mock.Calls.UpdateNodegroupConfig != calls+1 {
+		t.Error("a warned scale-down should call UpdateNodegroupConfig")
+	}
+	// Those options were the run's, not the shared checker's.
+	if err := svc.Scale(context.Background(), "my-cluster", "workers", aws.Int32(1), nil, nil, ScaleOptions{HealthCheck: true}); err == nil {
+		t.Error("the shared checker kept another run's options")
+	}
+}
@@ -25,6 +25,9 @@ type ScaleOptions struct {
 	Wait        bool          `json:"wait"`
 	Timeout     time.Duration `json:"timeout"`
 	DryRun      bool          `json:"dryRun"`
+	// HealthOptions resolves the cluster's health thresholds and check
+	// selection for HealthCheck; nil runs the checks with the defaults.
+	HealthOptions func(cluster string) (health.Options, error) `json:"-"`
 }
 
 // ScalingConfig models the EKS managed nodegroup scaling configuration
@@ -67,6 +70,9 @@ type NodegroupSummary struct {
 	// AMI information - core functionality of refresh tool
 	CurrentAMI string          `json:"currentAmi"`
 	AMIStatus  types.AMIStatus `json:"amiStatus"`
+	// ReleaseVersion is the EKS AMI release the nodegroup runs (empty for
+	// custom AMIs).
+	ReleaseVersion string `json:"releaseVersion,omitempty"`
 }
 
 // NodegroupDetails extends summary with health and optional instance/workload details
@@ -0,0 +1,224 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/dantech2000/refresh/internal/sandbox"
+)
+
+// safeToEvictAnnotation is the only annotation the health checks read.
+const safeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// captureKubernetes lists the objects the health checks read, pruned to the
+// fields they use: no environment, commands, probes, secrets or config
+// volumes, addresses or UIDs leave the cluster.
+func captureKubernetes(ctx context.Context, client kubernetes.Interface) (*sandbox.KubeObjects, error) {
+	out := &sandbox.KubeObjects{}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, n := range nodes.Items {
+		if err := appendObject(&out.Nodes, pruneNode(n)); err != nil {
+			return nil, err
+		}
+	}
+
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	for _, p := range pods.Items {
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if err := appendObject(&out.Pods, prunePod(p)); err != nil {
+			return nil, err
+		}
+	}
+
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing PodDisruptionBudgets: %w", err)
+	}
+	for _, p := range pdbs.Items {
+		if err := appendObject(&out.PodDisruptionBudgets, prunePDB(p)); err != nil {
+			return nil, err
+		}
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		if err := appendObject(&out.Deployments, pruneDeployment(d)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func pruneMeta(m metav1.ObjectMeta) metav1.ObjectMeta {
+	out := metav1.ObjectMeta{Name: m.Name, Namespace: m.Namespace, Labels: m.Labels}
+	if v, ok := m.Annotations[safeToEvictAnnotation]; ok {
+		out.Annotations = map[string]string{safeToEvictAnnotation: v}
+	}
+	for _, ref := range m.OwnerReferences {
+		out.OwnerReferences = append(out.OwnerReferences, metav1.OwnerReference{
+			APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name, Controller: ref.Controller,
+		})
+	}
+	return out
+}
+
+func pruneNode(n corev1.Node) *corev1.Node {
+	out := &corev1.Node{
+		ObjectMeta: pruneMeta(n.ObjectMeta),
+		Spec:       corev1.NodeSpec{Unschedulable: n.Spec.Unschedulable, Taints: n.Spec.Taints},
+		Status: corev1.NodeStatus{
+			Capacity:    n.Status.Capacity,
+			Allocatable: n.Status.Allocatable,
+			NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          n.Status.NodeInfo.KubeletVersion,
+				OSImage:                 n.Status.NodeInfo.OSImage,
+				ContainerRuntimeVersion: n.Status.NodeInfo.ContainerRuntimeVersion,
+				Architecture:            n.Status.NodeInfo.Architecture,
+			},
+		},
+	}
+	for _, c := range n.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, corev1.NodeCondition{Type: c.Type, Status: c.Status, Reason: c.Reason})
+	}
+	return out
+}
+
+func prunePod(p corev1.Pod) *corev1.Pod {
+	out := &corev1.Pod{
+		ObjectMeta: pruneMeta(p.ObjectMeta),
+		Spec:       prunePodSpec(p.Spec),
+		Status:     corev1.PodStatus{Phase: p.Status.Phase},
+	}
+	for _, c := range p.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, corev1.PodCondition{Type: c.Type, Status: c.Status, Reason: c.Reason})
+	}
+	return out
+}
+
+// prunePodSpec keeps placement, resources and the volumes that block or
+// lose data on eviction.
+func prunePodSpec(s corev1.PodSpec) corev1.PodSpec {
+	out := corev1.PodSpec{
+		NodeName:          s.NodeName,
+		NodeSelector:      s.NodeSelector,
+		Affinity:          s.Affinity,
+		Tolerations:       s.Tolerations,
+		PriorityClassName: s.PriorityClassName,
+		Containers:        pruneContainers(s.Containers),
+		InitContainers:    pruneContainers(s.InitContainers),
+	}
+	for _, v := range s.Volumes {
+		switch {
+		case v.EmptyDir != nil:
+			out.Volumes = append(out.Volumes, corev1.Volume{Name: v.Name, VolumeSource: corev1.VolumeSource{EmptyDir: v.EmptyDir}})
+		case v.HostPath != nil:
+			out.Volumes = append(out.Volumes, corev1.Volume{Name: v.Name, VolumeSource: corev1.VolumeSource{HostPath: v.HostPath}})
+		}
+	}
+	return out
+}
+
+func pruneContainers(cs []corev1.Container) []corev1.Container {
+	var out []corev1.Container
+	for _, c := range cs {
+		out = append(out, corev1.Container{Name: c.Name, Image: c.Image, Resources: c.Resources})
+	}
+	return out
+}
+
+func prunePDB(p policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: pruneMeta(p.ObjectMeta),
+		Spec:       p.Spec,
+		Status: policyv1.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: p.Status.DisruptionsAllowed,
+			CurrentHealthy:     p.Status.CurrentHealthy,
+			DesiredHealthy:     p.Status.DesiredHealthy,
+			ExpectedPods:       p.Status.ExpectedPods,
+		},
+	}
+}
+
+func pruneDeployment(d appsv1.Deployment) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: pruneMeta(d.ObjectMeta),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: d.Spec.Replicas,
+			Selector: d.Spec.Selector,
+			Strategy: d.Spec.Strategy,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: pruneMeta(d.Spec.Template.ObjectMeta),
+				Spec:       prunePodSpec(d.Spec.Template.Spec),
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          d.Status.Replicas,
+			ReadyReplicas:     d.Status.ReadyReplicas,
+			AvailableReplicas: d.Status.AvailableReplicas,
+			UpdatedReplicas:   d.Status.UpdatedReplicas,
+		},
+	}
+}
+
+// appendObject converts obj to its JSON form, dropping the nulls and empty
+// objects the API types serialise, and appends it.
+func appendObject(dst *[]sandbox.KubeObject, obj any) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	compact(m)
+	*dst = append(*dst, sandbox.KubeObject(m))
+	return nil
+}
+
+// compact removes null and empty values from m, recursively. An empty
+// emptyDir is kept: its presence is what marks the volume's type.
+func compact(m map[string]any) {
+	for k, v := range m {
+		if empty(v) && k != "emptyDir" {
+			delete(m, k)
+		}
+	}
+}
+
+func empty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		compact(t)
+		return len(t) == 0
+	case []any:
+		for _, e := range t {
+			if mm, ok := e.(map[string]any); ok {
+				compact(mm)
+			}
+		}
+		return len(t) == 0
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dantech2000/refresh/internal/sandbox"
+)
+
+var (
+	// arn matches an ARN: partition, service, region, account (empty for
+	// S3 and other global resources) and resource. A trailing '.', ',' or ':'
+	// is left to the surrounding text.
+	arn = regexp.MustCompile(`arn:(aws[a-z-]*):([a-z0-9-]*):([a-z0-9-]*):(\d{12})?:[A-Za-z0-9_+=,.@/:*-]*[A-Za-z0-9_+=@/*-]`)
+	// redactedResource is the resource of an ARN Redact already replaced.
+	redactedResource = regexp.MustCompile(`:redacted-[0-9a-f]{12}$`)
+	// ecrRegistry matches the account of an ECR registry host.
+	ecrRegistry = regexp.MustCompile(`\b\d{12}(\.dkr\.ecr\.)`)
+)
+
+// Redact removes account IDs and ARNs from data. Every ARN is replaced whole,
+// keeping its partition, service and region: the account becomes
+// sandbox.DefaultAccount and the resource "redacted-" and a hash of the
+// original ARN, so one role or stack reads the same everywhere it appears and
+// in every snapshot, and two snapshots diff cleanly. Every other occurrence
+// of account, and the account of any ECR registry host, becomes
+// sandbox.DefaultAccount too. Redacting twice changes nothing.
+func Redact(data []byte, account string) []byte {
+	data = arn.ReplaceAllFunc(data, redactARN)
+	if account != "" && account != sandbox.DefaultAccount {
+		data = bytes.ReplaceAll(data, []byte(account), []byte(sandbox.DefaultAccount))
+	}
+	return ecrRegistry.ReplaceAll(data, []byte(sandbox.DefaultAccount+"${1}"))
+}
+
+// redactARN replaces one ARN matched by arn.
+func redactARN(match []byte) []byte {
+	if redactedResource.Match(match) {
+		return match
+	}
+	m := arn.FindSubmatch(match)
+	acct := ""
+	if len(m[4]) > 0 {
+		acct = sandbox.DefaultAccount
+	}
+	sum := sha256.Sum256(match)
+	return fmt.Appendf(nil, "arn:%s:%s:%s:%s:redacted-%s", m[1], m[2], m[3], acct, hex.EncodeToString(sum[:6]))
+}
+
+// Encode renders the snapshot as redacted fixture YAML, headed by a comment
+// recording where it came from.
+func (s *Snapshot) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Snapshot of EKS cluster %s (%s) taken %s by refresh snapshot.\n", s.Cluster, s.Region, s.Taken.Format("2006-01-02T15:04:05Z"))
+	fmt.Fprintf(&buf, "# Account IDs and ARNs are redacted. Rehearse offline with:\n")
+	fmt.Fprintf(&buf, "#   refresh --sandbox <this file> cluster upgrade -c %s --to %s --dry-run\n", s.Cluster, s.To)
+	for _, w := range s.Warnings {
+		fmt.Fprintf(&buf, "# warning: %s\n", strings.ReplaceAll(w, "\n", " "))
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s.Fixture); err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	return Redact(buf.Bytes(), s.Account), nil
+}
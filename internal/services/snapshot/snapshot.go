@@ -0,0 +1,532 @@
+// Package snapshot captures a live cluster as a sandbox fixture: the EKS,
+// EC2 and SSM state the upgrade planners and health checks read, and
+// optionally the Kubernetes objects behind them. Replaying the fixture with
+// --sandbox rehearses a plan offline, without the account's credentials.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	asgtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"k8s.io/client-go/kubernetes"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/sandbox"
+	"github.com/dantech2000/refresh/internal/services/common"
+)
+
+// releasesPerVersion is how many of the newest AMI releases are kept per
+// Kubernetes version and AMI type, besides those nodegroups run.
+const releasesPerVersion = 5
+
+// EKSAPI abstracts the EKS reads a snapshot makes.
+type EKSAPI interface {
+	DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+	DescribeClusterVersions(ctx context.Context, params *eks.DescribeClusterVersionsInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterVersionsOutput, error)
+	ListNodegroups(ctx context.Context, params *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error)
+	DescribeNodegroup(ctx context.Context, params *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error)
+	ListAddons(ctx context.Context, params *eks.ListAddonsInput, optFns ...func(*eks.Options)) (*eks.ListAddonsOutput, error)
+	DescribeAddon(ctx context.Context, params *eks.DescribeAddonInput, optFns ...func(*eks.Options)) (*eks.DescribeAddonOutput, error)
+	DescribeAddonVersions(ctx context.Context, params *eks.DescribeAddonVersionsInput, optFns ...func(*eks.Options)) (*eks.DescribeAddonVersionsOutput, error)
+	ListInsights(ctx context.Context, params *eks.ListInsightsInput, optFns ...func(*eks.Options)) (*eks.ListInsightsOutput, error)
+	DescribeInsight(ctx context.Context, params *eks.DescribeInsightInput, optFns ...func(*eks.Options)) (*eks.DescribeInsightOutput, error)
+}
+
+// EC2API abstracts the EC2 reads a snapshot makes.
+type EC2API interface {
+	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	DescribeLaunchTemplateVersions(ctx context.Context, params *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
+}
+
+// ASGAPI abstracts the Auto Scaling read a snapshot makes.
+type ASGAPI interface {
+	DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+}
+
+// STSAPI abstracts the caller-identity read that finds the account to redact.
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// AMIReleases lists the published EKS-optimized AMI releases.
+type AMIReleases interface {
+	Releases(ctx context.Context, k8sVersion string, amiType ekstypes.AMITypes) ([]awsinternal.AMIRelease, error)
+}
+
+// SSMReleases implements AMIReleases from the SSM parameters EKS publishes.
+type SSMReleases struct {
+	Client *ssm.Client
+}
+
+// Releases implements AMIReleases.
+func (r SSMReleases) Releases(ctx context.Context, k8sVersion string, amiType ekstypes.AMITypes) ([]awsinternal.AMIRelease, error) {
+	return awsinternal.ReleasesForType(ctx, r.Client, k8sVersion, amiType)
+}
+
+// Clients are the AWS APIs a snapshot reads.
+type Clients struct {
+	EKS  EKSAPI
+	EC2  EC2API
+	ASG  ASGAPI
+	STS  STSAPI
+	AMIs AMIReleases
+}
+
+// NewClients builds the clients from an AWS config.
+func NewClients(cfg aws.Config) Clients {
+	return Clients{
+		EKS:  eks.NewFromConfig(cfg),
+		EC2:  ec2.NewFromConfig(cfg),
+		ASG:  autoscaling.NewFromConfig(cfg),
+		STS:  sts.NewFromConfig(cfg),
+		AMIs: SSMReleases{Client: ssm.NewFromConfig(cfg)},
+	}
+}
+
+// Service captures snapshots.
+type Service struct {
+	clients Clients
+	region  string
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewService returns a Service reading region through clients.
+func NewService(clients Clients, region string, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{clients: clients, region: region, logger: logger, now: time.Now}
+}
+
+// Options tune a capture.
+type Options struct {
+	// To extends the add-on and AMI catalogues through this Kubernetes
+	// version, so plans up to it can be rehearsed. Default: the next minor.
+	To string
+	// Kubernetes, when set, captures the cluster's nodes, pods,
+	// PodDisruptionBudgets and Deployments through it.
+	Kubernetes kubernetes.Interface
+}
+
+// Snapshot is a captured cluster.
+type Snapshot struct {
+	Cluster string
+	Region  string
+	Taken   time.Time
+	// To is the newest version the fixture's catalogues cover.
+	To      string
+	Fixture *sandbox.Fixture
+	// Account is the captured account's ID, redacted from the output.
+	Account string
+	// Warnings are the reads that failed; the fixture lacks what they cover.
+	Warnings []string
+}
+
+// Capture snapshots the cluster. Reads that only enrich the fixture (insights,
+// the support calendar, AMI releases, Kubernetes objects) become warnings
+// when they fail; the cluster, its nodegroups and add-ons must be readable.
+func (s *Service) Capture(ctx context.Context, clusterName string, opts Options) (*Snapshot, error) {
+	snap := &Snapshot{Cluster: clusterName, Region: s.region, Taken: s.now().UTC()}
+	warn := func(format string, args ...any) {
+		snap.Warnings = append(snap.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
+		return s.clients.EKS.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(clusterName)})
+	})
+	if err != nil {
+		return nil, awsinternal.FormatAWSError(err, fmt.Sprintf("describing cluster %s", clusterName))
+	}
+	cl := out.Cluster
+	c := sandbox.Cluster{
+		Name:            clusterName,
+		Region:          s.region,
+		Version:         aws.ToString(cl.Version),
+		PlatformVersion: aws.ToString(cl.PlatformVersion),
+		Tags:            cl.Tags,
+		CreatedAt:       aws.ToTime(cl.CreatedAt).UTC(),
+	}
+	if cl.UpgradePolicy != nil {
+		c.SupportType = cl.UpgradePolicy.SupportType
+	}
+	versions, err := versionsThrough(c.Version, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	snap.To = versions[len(versions)-1]
+
+	f := &sandbox.Fixture{Region: s.region}
+	if c.Nodegroups, err = s.nodegroups(ctx, clusterName); err != nil {
+		return nil, err
+	}
+	if c.Addons, f.AddonVersions, err = s.addons(ctx, clusterName, versions); err != nil {
+		return nil, err
+	}
+	if c.Insights, err = s.insights(ctx, clusterName); err != nil {
+		warn("insights not captured: %v", err)
+	}
+	if f.KubernetesVersions, err = s.kubernetesVersions(ctx); err != nil {
+		warn("support calendar not captured (the sandbox falls back to the built-in one): %v", err)
+	}
+	f.AMIReleases = s.amiReleases(ctx, c.Nodegroups, versions, warn)
+	if opts.Kubernetes != nil {
+		if c.Kubernetes, err = captureKubernetes(ctx, opts.Kubernetes); err != nil {
+			warn("Kubernetes objects not captured: %v", err)
+		}
+	}
+	f.Clusters = []sandbox.Cluster{c}
+	snap.Fixture = f
+
+	if id, err := s.clients.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err == nil {
+		snap.Account = aws.ToString(id.Account)
+	} else {
+		warn("caller identity unknown; only account IDs inside ARNs and ECR registries are redacted: %v", err)
+	}
+	return snap, nil
+}
+
+// versionsThrough lists the Kubernetes minors from current through to,
+// which defaults to the next minor.
+func versionsThrough(current, to string) ([]string, error) {
+	major, minor, ok := parseMinor(current)
+	if !ok {
+		return nil, fmt.Errorf("cluster version %q is not a Kubernetes minor version", current)
+	}
+	last := minor + 1
+	if to != "" {
+		toMajor, toMinor, ok := parseMinor(to)
+		if !ok || toMajor != major {
+			return nil, fmt.Errorf("--to %q is not a Kubernetes %d.x minor version", to, major)
+		}
+		if toMinor < minor {
+			return nil, fmt.Errorf("--to %s is older than the cluster's %s", to, current)
+		}
+		last = toMinor
+	}
+	var out []string
+	for m := minor; m <= last; m++ {
+		out = append(out, fmt.Sprintf("%d.%d", major, m))
+	}
+	return out, nil
+}
+
+func parseMinor(v string) (major, minor int, ok bool) {
+	a, b, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	if !found {
+		return 0, 0, false
+	}
+	major, err1 := strconv.Atoi(a)
+	minor, err2 := strconv.Atoi(b)
+	return major, minor, err1 == nil && err2 == nil
+}
+
+func (s *Service) nodegroups(ctx context.Context, clusterName string) ([]sandbox.Nodegroup, error) {
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing nodegroups for cluster %s", clusterName),
+		func(rc context.Context, token *string) (*eks.ListNodegroupsOutput, error) {
+			return s.clients.EKS.ListNodegroups(rc, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName), NextToken: token})
+		},
+		func(out *eks.ListNodegroupsOutput) ([]string, *string) { return out.Nodegroups, out.NextToken },
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]sandbox.Nodegroup, 0, len(names))
+	for _, name := range names {
+		desc, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeNodegroupOutput, error) {
+			return s.clients.EKS.DescribeNodegroup(rc, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)})
+		})
+		if err != nil {
+			return nil, awsinternal.FormatAWSError(err, fmt.Sprintf("describing nodegroup %s", name))
+		}
+		ng := desc.Nodegroup
+		captured := sandbox.Nodegroup{
+			Name:           name,
+			Version:        aws.ToString(ng.Version),
+			ReleaseVersion: aws.ToString(ng.ReleaseVersion),
+			AMIType:        ng.AmiType,
+			CapacityType:   ng.CapacityType,
+			Labels:         ng.Labels,
+		}
+		if len(ng.InstanceTypes) > 0 {
+			captured.InstanceType = ng.InstanceTypes[0]
+		}
+		if sc := ng.ScalingConfig; sc != nil {
+			captured.Desired, captured.Min, captured.Max = aws.ToInt32(sc.DesiredSize), aws.ToInt32(sc.MinSize), aws.ToInt32(sc.MaxSize)
+		}
+		if lt := ng.LaunchTemplate; lt != nil && lt.Id != nil && lt.Version != nil {
+			captured.LaunchTemplate, err = s.launchTemplate(ctx, lt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if captured.Instances, err = s.instances(ctx, ng); err != nil {
+			return nil, err
+		}
+		out = append(out, captured)
+	}
+	return out, nil
+}
+
+func (s *Service) launchTemplate(ctx context.Context, lt *ekstypes.LaunchTemplateSpecification) (*sandbox.LaunchTemplate, error) {
+	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+		return s.clients.EC2.DescribeLaunchTemplateVersions(rc, &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: lt.Id,
+			Versions:         []string{aws.ToString(lt.Version)},
+		})
+	})
+	if err != nil {
+		return nil, awsinternal.FormatAWSError(err, "describing launch template "+aws.ToString(lt.Id))
+	}
+	captured := &sandbox.LaunchTemplate{ID: aws.ToString(lt.Id), Name: aws.ToString(lt.Name), Version: aws.ToString(lt.Version)}
+	if len(out.LaunchTemplateVersions) > 0 {
+		v := out.LaunchTemplateVersions[0]
+		if captured.Name == "" {
+			captured.Name = aws.ToString(v.LaunchTemplateName)
+		}
+		if v.LaunchTemplateData != nil {
+			captured.ImageID = aws.ToString(v.LaunchTemplateData.ImageId)
+		}
+	}
+	return captured, nil
+}
+
+// instances captures the EC2 instances in the nodegroup's Auto Scaling
+// groups, sorted by ID.
+func (s *Service) instances(ctx context.Context, ng *ekstypes.Nodegroup) ([]sandbox.Instance, error) {
+	var groups []string
+	if ng.Resources != nil {
+		for _, g := range ng.Resources.AutoScalingGroups {
+			if g.Name != nil {
+				groups = append(groups, *g.Name)
+			}
+		}
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	asgOut, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+		return s.clients.ASG.DescribeAutoScalingGroups(rc, &autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: groups})
+	})
+	if err != nil {
+		return nil, awsinternal.FormatAWSError(err, "describing Auto Scaling groups of nodegroup "+aws.ToString(ng.NodegroupName))
+	}
+	var ids []string
+	for _, g := range asgOut.AutoScalingGroups {
+		for _, inst := range g.Instances {
+			if inst.InstanceId != nil && inst.LifecycleState != asgtypes.LifecycleStateTerminated {
+				ids = append(ids, *inst.InstanceId)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	ec2Out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*ec2.DescribeInstancesOutput, error) {
+		return s.clients.EC2.DescribeInstances(rc, &ec2.DescribeInstancesInput{InstanceIds: ids})
+	})
+	if err != nil {
+		return nil, awsinternal.FormatAWSError(err, "describing instances of nodegroup "+aws.ToString(ng.NodegroupName))
+	}
+	var out []sandbox.Instance
+	for _, r := range ec2Out.Reservations {
+		for _, inst := range r.Instances {
+			if inst.State != nil && inst.State.Name == ec2types.InstanceStateNameTerminated {
+				continue
+			}
+			captured := sandbox.Instance{ID: aws.ToString(inst.InstanceId), ImageID: aws.ToString(inst.ImageId), LaunchTime: aws.ToTime(inst.LaunchTime).UTC()}
+			if inst.Placement != nil {
+				captured.Zone = aws.ToString(inst.Placement.AvailabilityZone)
+			}
+			out = append(out, captured)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// addons captures the installed add-ons and, for each, the catalogue of
+// versions compatible with any of versions.
+func (s *Service) addons(ctx context.Context, clusterName string, versions []string) ([]sandbox.Addon, map[string][]sandbox.AddonVersion, error) {
+	names, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing addons for cluster %s", clusterName),
+		func(rc context.Context, token *string) (*eks.ListAddonsOutput, error) {
+			return s.clients.EKS.ListAddons(rc, &eks.ListAddonsInput{ClusterName: aws.String(clusterName), NextToken: token})
+		},
+		func(out *eks.ListAddonsOutput) ([]string, *string) { return out.Addons, out.NextToken },
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	installed := make([]sandbox.Addon, 0, len(names))
+	catalogue := map[string][]sandbox.AddonVersion{}
+	for _, name := range names {
+		desc, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeAddonOutput, error) {
+			return s.clients.EKS.DescribeAddon(rc, &eks.DescribeAddonInput{ClusterName: aws.String(clusterName), AddonName: aws.String(name)})
+		})
+		if err != nil {
+			return nil, nil, awsinternal.FormatAWSError(err, fmt.Sprintf("describing addon %s", name))
+		}
+		installed = append(installed, sandbox.Addon{Name: name, Version: aws.ToString(desc.Addon.AddonVersion), Status: desc.Addon.Status})
+
+		infos, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing versions of addon %s", name),
+			func(rc context.Context, token *string) (*eks.DescribeAddonVersionsOutput, error) {
+				return s.clients.EKS.DescribeAddonVersions(rc, &eks.DescribeAddonVersionsInput{AddonName: aws.String(name), NextToken: token})
+			},
+			func(out *eks.DescribeAddonVersionsOutput) ([]ekstypes.AddonInfo, *string) {
+				return out.Addons, out.NextToken
+			},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, info := range infos {
+			for _, v := range info.AddonVersions {
+				av := sandbox.AddonVersion{Version: aws.ToString(v.AddonVersion)}
+				for _, compat := range v.Compatibilities {
+					k := aws.ToString(compat.ClusterVersion)
+					if !contains(versions, k) {
+						continue
+					}
+					av.Kubernetes = append(av.Kubernetes, k)
+					if compat.DefaultVersion {
+						av.Default = append(av.Default, k)
+					}
+				}
+				if len(av.Kubernetes) > 0 {
+					catalogue[name] = append(catalogue[name], av)
+				}
+			}
+		}
+	}
+	return installed, catalogue, nil
+}
+
+// insights captures the cluster's upgrade-readiness insights.
+func (s *Service) insights(ctx context.Context, clusterName string) ([]sandbox.Insight, error) {
+	summaries, err := awsinternal.ListAllPages(ctx, fmt.Sprintf("listing insights for cluster %s", clusterName),
+		func(rc context.Context, token *string) (*eks.ListInsightsOutput, error) {
+			return s.clients.EKS.ListInsights(rc, &eks.ListInsightsInput{
+				ClusterName: aws.String(clusterName),
+				Filter:      &ekstypes.InsightsFilter{Categories: []ekstypes.Category{ekstypes.CategoryUpgradeReadiness}},
+				NextToken:   token,
+			})
+		},
+		func(out *eks.ListInsightsOutput) ([]ekstypes.InsightSummary, *string) {
+			return out.Insights, out.NextToken
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]sandbox.Insight, 0, len(summaries))
+	for _, sum := range summaries {
+		in := sandbox.Insight{
+			Name:              aws.ToString(sum.Name),
+			KubernetesVersion: aws.ToString(sum.KubernetesVersion),
+			Description:       aws.ToString(sum.Description),
+		}
+		if st := sum.InsightStatus; st != nil {
+			in.Status, in.Reason = st.Status, aws.ToString(st.Reason)
+		}
+		// The recommendation is only on the full insight.
+		if desc, err := s.clients.EKS.DescribeInsight(ctx, &eks.DescribeInsightInput{ClusterName: aws.String(clusterName), Id: sum.Id}); err == nil && desc.Insight != nil {
+			in.Recommendation = aws.ToString(desc.Insight.Recommendation)
+		}
+		out = append(out, in)
+	}
+	return out, nil
+}
+
+// kubernetesVersions captures the support calendar.
+func (s *Service) kubernetesVersions(ctx context.Context) ([]sandbox.KubernetesVersion, error) {
+	infos, err := awsinternal.ListAllPages(ctx, "describing cluster versions",
+		func(rc context.Context, token *string) (*eks.DescribeClusterVersionsOutput, error) {
+			return s.clients.EKS.DescribeClusterVersions(rc, &eks.DescribeClusterVersionsInput{IncludeAll: aws.Bool(true), NextToken: token})
+		},
+		func(out *eks.DescribeClusterVersionsOutput) ([]ekstypes.ClusterVersionInformation, *string) {
+			return out.ClusterVersions, out.NextToken
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var out []sandbox.KubernetesVersion
+	for _, v := range infos {
+		if v.EndOfStandardSupportDate == nil || v.EndOfExtendedSupportDate == nil {
+			continue
+		}
+		out = append(out, sandbox.KubernetesVersion{
+			Version:              aws.ToString(v.ClusterVersion),
+			EndOfStandardSupport: v.EndOfStandardSupportDate.UTC(),
+			EndOfExtendedSupport: v.EndOfExtendedSupportDate.UTC(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		_, a, _ := parseMinor(out[i].Version)
+		_, b, _ := parseMinor(out[j].Version)
+		return a < b
+	})
+	return out, nil
+}
+
+// amiReleases captures, for each AMI type the nodegroups run and each
+// version, the newest releases plus those nodegroups run.
+func (s *Service) amiReleases(ctx context.Context, nodegroups []sandbox.Nodegroup, versions []string, warn func(string, ...any)) []sandbox.AMIRelease {
+	running := map[string]bool{}
+	var types []ekstypes.AMITypes
+	for _, ng := range nodegroups {
+		running[ng.ReleaseVersion] = true
+		if ng.AMIType != "" && ng.AMIType != ekstypes.AMITypesCustom && !containsType(types, ng.AMIType) {
+			types = append(types, ng.AMIType)
+		}
+	}
+	var out []sandbox.AMIRelease
+	for _, amiType := range types {
+		for _, k8s := range versions {
+			releases, err := s.clients.AMIs.Releases(ctx, k8s, amiType)
+			if err != nil {
+				warn("%s AMI releases for %s not captured: %v", amiType, k8s, err)
+				continue
+			}
+			// Releases are oldest first.
+			for i, r := range releases {
+				if i >= len(releases)-releasesPerVersion || running[r.ReleaseVersion] {
+					out = append(out, sandbox.AMIRelease{Kubernetes: k8s, Release: r.ReleaseVersion, AMIType: amiType, ImageID: r.ImageID})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(list []ekstypes.AMITypes, t ekstypes.AMITypes) bool {
+	for _, v := range list {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
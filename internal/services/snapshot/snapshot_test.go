@@ -0,0 +1,209 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dantech2000/refresh/internal/sandbox"
+)
+
+func TestVersionsThrough(t *testing.T) {
+	tests := []struct {
+		current, to string
+		want        string
+		wantErr     bool
+	}{
+		{current: "1.31", want: "1.31,1.32"},
+		{current: "1.31", to: "1.33", want: "1.31,1.32,1.33"},
+		{current: "1.31", to: "1.31", want: "1.31"},
+		{current: "1.31", to: "1.30", wantErr: true},
+		{current: "1.31", to: "2.0", wantErr: true},
+		{current: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := versionsThrough(tt.current, tt.to)
+		if (err != nil) != tt.wantErr || strings.Join(got, ",") != tt.want {
+			t.Errorf("versionsThrough(%q, %q) = %v, %v", tt.current, tt.to, got, err)
+		}
+	}
+}
+
+// TestCaptureRoundTrip snapshots a simulated cluster and loads the result
+// back as a fixture.
+func TestCaptureRoundTrip(t *testing.T) {
+	sim, err := sandbox.Load(filepath.Join("..", "..", "sandbox", "testdata", "fleet.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kube := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-1", Labels: map[string]string{"eks.amazonaws.com/nodegroup": "workers"}}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				NodeName:   "ip-10-0-0-1",
+				Containers: []corev1.Container{{Name: "web", Image: "210987654321.dkr.ecr.us-east-1.amazonaws.com/web:1"}},
+			},
+		},
+	)
+	svc := NewService(NewClients(sim.Config("us-east-1")), "us-east-1", nil)
+	svc.now = func() time.Time { return time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) }
+
+	snap, err := svc.Capture(context.Background(), "prod-east", Options{Kubernetes: kube})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Warnings) != 0 || snap.To != "1.32" || snap.Account != sandbox.DefaultAccount {
+		t.Errorf("snapshot = to %s, account %s, warnings %v", snap.To, snap.Account, snap.Warnings)
+	}
+	data, err := snap.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "210987654321") {
+		t.Error("the ECR registry account should be redacted")
+	}
+	if !strings.HasPrefix(string(data), "# Snapshot of EKS cluster prod-east (us-east-1) taken 2026-10-01T00:00:00Z") {
+		t.Errorf("missing header:\n%s", data)
+	}
+
+	file := filepath.Join(t.TempDir(), "prod.yaml")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := sandbox.LoadFixture(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Clusters) != 1 {
+		t.Fatalf("clusters = %d", len(f.Clusters))
+	}
+	c := f.Clusters[0]
+	if c.Version != "1.31" || len(c.Addons) != 3 || len(c.Insights) != 1 || len(c.Nodegroups) != 2 {
+		t.Errorf("cluster = %s, %d addons, %d insights, %d nodegroups", c.Version, len(c.Addons), len(c.Insights), len(c.Nodegroups))
+	}
+	workers := c.Nodegroups[0]
+	if workers.ReleaseVersion != "1.31.0-20260101" || len(workers.Instances) != 3 || workers.Desired != 3 {
+		t.Errorf("workers = %s, %d instances, desired %d", workers.ReleaseVersion, len(workers.Instances), workers.Desired)
+	}
+	for _, v := range f.AddonVersions["kube-proxy"] {
+		if strings.Join(v.Kubernetes, ",") == "" {
+			t.Errorf("kube-proxy %s has no captured Kubernetes versions", v.Version)
+		}
+	}
+	var running bool
+	for _, r := range f.AMIReleases {
+		running = running || r.Release == workers.ReleaseVersion
+	}
+	if !running {
+		t.Error("the release workers run should be captured")
+	}
+	if c.Kubernetes == nil || len(c.Kubernetes.Nodes) != 1 || len(c.Kubernetes.Pods) != 1 {
+		t.Fatalf("kubernetes objects = %+v", c.Kubernetes)
+	}
+
+	// The captured state replays: the sandbox serves it back.
+	client, _, ok, err := sandbox.New(f).KubeClient()
+	if err != nil || !ok {
+		t.Fatalf("KubeClient = %v, %v", ok, err)
+	}
+	pods, err := client.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(pods.Items) != 1 || pods.Items[0].Spec.NodeName != "ip-10-0-0-1" {
+		t.Errorf("replayed pods = %+v, %v", pods, err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	in := strings.Join([]string{
+		"role: arn:aws:iam::111122223333:role/eks",
+		"other: arn:aws-us-gov:eks:us-gov-west-1:444455556666:cluster/prod",
+		"image: 777788889999.dkr.ecr.eu-west-1.amazonaws.com/app:1",
+		"note: owned by 111122223333",
+		"s3: arn:aws:s3:::bucket-111122223333x",
+		"again: see arn:aws:iam::111122223333:role/eks.",
+	}, "\n")
+	role := "arn:aws:iam::123456789012:redacted-" + arnHash("arn:aws:iam::111122223333:role/eks")
+	want := strings.Join([]string{
+		"role: " + role,
+		"other: arn:aws-us-gov:eks:us-gov-west-1:123456789012:redacted-" + arnHash("arn:aws-us-gov:eks:us-gov-west-1:444455556666:cluster/prod"),
+		"image: 123456789012.dkr.ecr.eu-west-1.amazonaws.com/app:1",
+		"note: owned by 123456789012",
+		"s3: arn:aws:s3:::redacted-" + arnHash("arn:aws:s3:::bucket-111122223333x"),
+		"again: see " + role + ".",
+	}, "\n")
+	got := string(Redact([]byte(in), "111122223333"))
+	if got != want {
+		t.Errorf("Redact =\n%s\nwant\n%s", got, want)
+	}
+	for _, leak := range []string{"role/eks", "cluster/prod", "bucket"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("Redact leaks %q", leak)
+		}
+	}
+	if again := string(Redact([]byte(got), "111122223333")); again != got {
+		t.Errorf("redacting twice =\n%s\nwant\n%s", again, got)
+	}
+}
+
+func arnHash(arn string) string {
+	sum := sha256.Sum256([]byte(arn))
+	return hex.EncodeToString(sum[:6])
+}
+
+func TestPrunePod(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "api-1",
+			Namespace: "shop",
+			UID:       "3f1c",
+			Annotations: map[string]string{
+				"cluster-autoscaler.kubernetes.io/safe-to-evict":   "false",
+				"kubectl.kubernetes.io/last-applied-configuration": "{...}",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "ip-10-0-0-1",
+			Containers: []corev1.Container{{
+				Name:    "api",
+				Image:   "api:2",
+				Command: []string{"/api", "--token=secret"},
+				Env:     []corev1.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "db"}}},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.7"},
+	}
+	var out []sandbox.KubeObject
+	if err := appendObject(&out, prunePod(pod)); err != nil {
+		t.Fatal(err)
+	}
+	got := fmtObject(out[0])
+	for _, leak := range []string{"hunter2", "secret", "last-applied", "10.0.0.7", "3f1c", "creationTimestamp"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("pruned pod leaks %q: %s", leak, got)
+		}
+	}
+	for _, keep := range []string{"safe-to-evict", `"emptyDir":{}`, "ip-10-0-0-1", "api:2", "Running"} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("pruned pod lost %q: %s", keep, got)
+		}
+	}
+}
+
+func fmtObject(o sandbox.KubeObject) string {
+	data, _ := json.Marshal(o)
+	return string(data)
+}
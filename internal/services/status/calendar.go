@@ -0,0 +1,309 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"gopkg.in/yaml.v3"
+
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/cliconfig"
+)
+
+// calendarFileName is the cached support calendar under the refresh config dir.
+const calendarFileName = "calendar.yaml"
+
+// CalendarEntry is one Kubernetes minor's published EKS support window.
+type CalendarEntry struct {
+	Version       string     `json:"version" yaml:"version"`
+	StandardUntil time.Time  `json:"standardUntil" yaml:"standardUntil"`
+	ExtendedUntil *time.Time `json:"extendedUntil,omitempty" yaml:"extendedUntil,omitempty"`
+	// FetchedAt is when DescribeClusterVersions last reported this window.
+	// Zero for built-in rows.
+	FetchedAt time.Time `json:"fetchedAt,omitzero" yaml:"fetchedAt,omitempty"`
+}
+
+// Posture classifies the entry's window relative to now.
+func (e CalendarEntry) Posture(now time.Time) SupportPosture {
+	var ext time.Time
+	if e.ExtendedUntil != nil {
+		ext = *e.ExtendedUntil
+	}
+	return classifySupport(e.StandardUntil, ext, now, false)
+}
+
+// Calendar is the persisted support calendar: every window the EKS API has
+// reported, so a later run without DescribeClusterVersions access (or on a
+// version newer than the compiled-in table) still resolves a tier.
+type Calendar struct {
+	UpdatedAt time.Time       `json:"updatedAt" yaml:"updatedAt"`
+	Versions  []CalendarEntry `json:"versions" yaml:"versions"`
+}
+
+// Lookup returns the entry for a version.
+func (c *Calendar) Lookup(version string) (CalendarEntry, bool) {
+	for _, e := range c.Versions {
+		if e.Version == version {
+			return e, true
+		}
+	}
+	return CalendarEntry{}, false
+}
+
+// Merge upserts entries, keeping Versions sorted by minor, and reports
+// whether anything changed.
+func (c *Calendar) Merge(entries ...CalendarEntry) bool {
+	changed := false
+	for _, e := range entries {
+		replaced := false
+		for i := range c.Versions {
+			if c.Versions[i].Version != e.Version {
+				continue
+			}
+			if c.Versions[i] != e {
+				c.Versions[i] = e
+				changed = true
+			}
+			replaced = true
+			break
+		}
+		if !replaced {
+			c.Versions = append(c.Versions, e)
+			changed = true
+		}
+	}
+	sort.SliceStable(c.Versions, func(i, j int) bool {
+		return versionLess(c.Versions[i].Version, c.Versions[j].Version)
+	})
+	return changed
+}
+
+// BuiltInCalendar returns the compiled-in calendar — the last resort when
+// neither the API nor the cached calendar knows a version.
+func BuiltInCalendar() Calendar {
+	var cal Calendar
+	for v, w := range fallbackCalendar {
+		ext := w.extendedEnd
+		cal.Merge(CalendarEntry{Version: v, StandardUntil: w.standardEnd, ExtendedUntil: &ext})
+	}
+	return cal
+}
+
+// CalendarPath returns the cached calendar location: calendar.yaml beside the
+// context file.
+func CalendarPath() (string, error) {
+	dir, err := cliconfig.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, calendarFileName), nil
+}
+
+// LoadCalendar reads the calendar at path. A missing file is an empty
+// calendar, not an error.
+func LoadCalendar(path string) (Calendar, error) {
+	var cal Calendar
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cal, nil
+	}
+	if err != nil {
+		return cal, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(b, &cal); err != nil {
+		return cal, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cal, nil
+}
+
+// SaveCalendar writes the calendar atomically (temp + rename) so a crash or a
+// concurrent refresh process can't leave a torn file.
+func SaveCalendar(path string, cal Calendar) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(cal)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".calendar-*.yaml")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// CalendarCache is a process-shared, write-through view of the on-disk
+// calendar. Resolution reads it when the API is unavailable and records every
+// window the API does return.
+type CalendarCache struct {
+	path string
+
+	mu     sync.Mutex
+	loaded bool
+	cal    Calendar
+}
+
+var (
+	calendarCachesMu sync.Mutex
+	calendarCaches   = map[string]*CalendarCache{}
+)
+
+// DefaultCalendarCache returns the shared cache for CalendarPath, or nil when
+// the config dir can't be resolved (resolution then skips the cache tier).
+// One instance per path keeps concurrent region sweeps from racing each
+// other's writes.
+func DefaultCalendarCache() *CalendarCache {
+	path, err := CalendarPath()
+	if err != nil {
+		return nil
+	}
+	calendarCachesMu.Lock()
+	defer calendarCachesMu.Unlock()
+	if c, ok := calendarCaches[path]; ok {
+		return c
+	}
+	c := &CalendarCache{path: path}
+	calendarCaches[path] = c
+	return c
+}
+
+// Path is the calendar file this cache reads and writes.
+func (c *CalendarCache) Path() string {
+	if c == nil {
+		return ""
+	}
+	return c.path
+}
+
+func (c *CalendarCache) loadLocked() {
+	if c.loaded {
+		return
+	}
+	// An unreadable cache behaves like an empty one; the next successful API
+	// call rewrites it.
+	c.cal, _ = LoadCalendar(c.path)
+	c.loaded = true
+}
+
+// Lookup returns the cached window for a version.
+func (c *CalendarCache) Lookup(version string) (CalendarEntry, bool) {
+	if c == nil {
+		return CalendarEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	return c.cal.Lookup(version)
+}
+
+// Record merges API-reported windows into the cache and persists it. Write
+// failures are returned but callers on the read path ignore them — caching
+// is an optimization, never a reason to fail a status run.
+func (c *CalendarCache) Record(now time.Time, entries ...CalendarEntry) error {
+	if c == nil || len(entries) == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	for i := range entries {
+		entries[i].FetchedAt = now
+	}
+	c.cal.Merge(entries...)
+	c.cal.UpdatedAt = now
+	return SaveCalendar(c.path, c.cal)
+}
+
+// Snapshot returns a copy of the cached calendar.
+func (c *CalendarCache) Snapshot() Calendar {
+	if c == nil {
+		return Calendar{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	out := Calendar{UpdatedAt: c.cal.UpdatedAt, Versions: append([]CalendarEntry(nil), c.cal.Versions...)}
+	return out
+}
+
+// UpdateCalendar fetches every EKS version window (standard and extended)
+// from DescribeClusterVersions and records it in the cache — the explicit
+// `refresh calendar update` path.
+func UpdateCalendar(ctx context.Context, api supportVersionsAPI, cache *CalendarCache, now time.Time) (Calendar, error) {
+	infos, err := awsinternal.ListAllPages(ctx, "describing EKS cluster versions",
+		func(rc context.Context, token *string) (*eks.DescribeClusterVersionsOutput, error) {
+			return api.DescribeClusterVersions(rc, &eks.DescribeClusterVersionsInput{
+				IncludeAll: aws.Bool(true),
+				NextToken:  token,
+			})
+		},
+		func(out *eks.DescribeClusterVersionsOutput) ([]CalendarEntry, *string) {
+			var entries []CalendarEntry
+			for _, cv := range out.ClusterVersions {
+				if e, ok := entryFromAPI(cv.ClusterVersion, cv.EndOfStandardSupportDate, cv.EndOfExtendedSupportDate); ok {
+					entries = append(entries, e)
+				}
+			}
+			return entries, out.NextToken
+		},
+	)
+	if err != nil {
+		return Calendar{}, err
+	}
+	if len(infos) == 0 {
+		return Calendar{}, errors.New("DescribeClusterVersions returned no support windows")
+	}
+	if err := cache.Record(now, infos...); err != nil {
+		return Calendar{}, fmt.Errorf("writing calendar %s: %w", cache.path, err)
+	}
+	return cache.Snapshot(), nil
+}
+
+func entryFromAPI(version *string, std, ext *time.Time) (CalendarEntry, bool) {
+	if version == nil || std == nil {
+		return CalendarEntry{}, false
+	}
+	e := CalendarEntry{Version: aws.ToString(version), StandardUntil: *std}
+	if ext != nil {
+		x := *ext
+		e.ExtendedUntil = &x
+	}
+	return e, true
+}
+
+// versionLess orders "1.9" before "1.10".
+func versionLess(a, b string) bool {
+	var am, an, bm, bn int
+	_, errA := fmt.Sscanf(a, "%d.%d", &am, &an)
+	_, errB := fmt.Sscanf(b, "%d.%d", &bm, &bn)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	if am != bm {
+		return am < bm
+	}
+	return an < bn
+}
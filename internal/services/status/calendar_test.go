@@ -0,0 +1,161 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+func newTestCalendarCache(t *testing.T) *CalendarCache {
+	t.Helper()
+	return &CalendarCache{path: filepath.Join(t.TempDir(), "calendar.yaml")}
+}
+
+func TestCalendar_MergeSortsAndReportsChanges(t *testing.T) {
+	var cal Calendar
+	if !cal.Merge(
+		CalendarEntry{Version: "1.10", StandardUntil: date(2020, 1, 1)},
+		CalendarEntry{Version: "1.9", StandardUntil: date(2019, 1, 1)},
+	) {
+		t.Fatal("first merge should report a change")
+	}
+	if cal.Versions[0].Version != "1.9" || cal.Versions[1].Version != "1.10" {
+		t.Errorf("versions not sorted numerically: %+v", cal.Versions)
+	}
+	if cal.Merge(CalendarEntry{Version: "1.9", StandardUntil: date(2019, 1, 1)}) {
+		t.Error("identical merge should report no change")
+	}
+	if !cal.Merge(CalendarEntry{Version: "1.9", StandardUntil: date(2019, 2, 1)}) {
+		t.Error("changed window should report a change")
+	}
+	if e, _ := cal.Lookup("1.9"); !e.StandardUntil.Equal(date(2019, 2, 1)) {
+		t.Errorf("lookup after update = %v", e.StandardUntil)
+	}
+}
+
+func TestCalendar_SaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "calendar.yaml")
+	ext := date(2028, 3, 23)
+	in := Calendar{UpdatedAt: date(2026, 6, 1), Versions: []CalendarEntry{
+		{Version: "1.34", StandardUntil: date(2027, 3, 23), ExtendedUntil: &ext, FetchedAt: date(2026, 6, 1)},
+	}}
+	if err := SaveCalendar(path, in); err != nil {
+		t.Fatalf("SaveCalendar: %v", err)
+	}
+	out, err := LoadCalendar(path)
+	if err != nil {
+		t.Fatalf("LoadCalendar: %v", err)
+	}
+	e, ok := out.Lookup("1.34")
+	if !ok || !e.StandardUntil.Equal(date(2027, 3, 23)) || e.ExtendedUntil == nil || !e.ExtendedUntil.Equal(ext) {
+		t.Errorf("round trip lost data: %+v", out)
+	}
+
+	missing, err := LoadCalendar(filepath.Join(t.TempDir(), "none.yaml"))
+	if err != nil || len(missing.Versions) != 0 {
+		t.Errorf("missing file should be an empty calendar, got %+v, %v", missing, err)
+	}
+}
+
+// TestResolveSupportPosture_SourceOrder walks the API → cache → built-in
+// ladder and checks each tier is labeled with its source.
+func TestResolveSupportPosture_SourceOrder(t *testing.T) {
+	now := date(2026, 6, 11)
+	cache := newTestCalendarCache(t)
+	api := &fakeClusterAPI{versions: map[string]ekstypes.ClusterVersionInformation{
+		"1.35": {
+			ClusterVersion:           aws.String("1.35"),
+			EndOfStandardSupportDate: timePtr(date(2027, 12, 1)),
+			EndOfExtendedSupportDate: timePtr(date(2028, 12, 1)),
+		},
+	}}
+
+	// 1. Live API answer, recorded to the cache.
+	p := resolveSupportPosture(context.Background(), api, cache, "1.35", now.Add(-72*time.Hour))
+	if p.Source != SourceAPI || p.Fallback || p.Tier != SupportStandard {
+		t.Fatalf("API posture = %+v", p)
+	}
+	reloaded, err := LoadCalendar(cache.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Lookup("1.35"); !ok {
+		t.Fatal("API answer was not written to the calendar file")
+	}
+
+	// 2. API denied → the cached window, with its age.
+	denied := &fakeClusterAPI{versionsErr: errors.New("AccessDeniedException")}
+	p = resolveSupportPosture(context.Background(), denied, cache, "1.35", now)
+	if p.Source != SourceCache || !p.Fallback || p.Tier != SupportStandard {
+		t.Fatalf("cache posture = %+v", p)
+	}
+	if got := p.SourceLabel(now); got != "cache, 3d old" {
+		t.Errorf("SourceLabel = %q, want \"cache, 3d old\"", got)
+	}
+
+	// 3. Not cached → compiled-in calendar.
+	p = resolveSupportPosture(context.Background(), denied, cache, "1.31", now)
+	if p.Source != SourceBuiltIn || !p.Fallback || p.Tier != SupportExtended {
+		t.Fatalf("built-in posture = %+v", p)
+	}
+
+	// 4. Nowhere → unknown.
+	if p := resolveSupportPosture(context.Background(), denied, cache, "1.99", now); p.Tier != SupportUnknown {
+		t.Errorf("unknown version tier = %s", p.Tier)
+	}
+}
+
+// listingVersionsAPI returns every known version for an unfiltered call, as
+// `refresh calendar update` issues it.
+type listingVersionsAPI struct {
+	out *eks.DescribeClusterVersionsOutput
+	err error
+}
+
+func (l *listingVersionsAPI) DescribeClusterVersions(_ context.Context, in *eks.DescribeClusterVersionsInput, _ ...func(*eks.Options)) (*eks.DescribeClusterVersionsOutput, error) {
+	if !aws.ToBool(in.IncludeAll) {
+		return &eks.DescribeClusterVersionsOutput{}, nil
+	}
+	return l.out, l.err
+}
+
+func TestUpdateCalendar(t *testing.T) {
+	now := date(2026, 6, 11)
+	cache := newTestCalendarCache(t)
+	api := &listingVersionsAPI{out: &eks.DescribeClusterVersionsOutput{ClusterVersions: []ekstypes.ClusterVersionInformation{
+		{ClusterVersion: aws.String("1.34"), EndOfStandardSupportDate: timePtr(date(2027, 7, 1)), EndOfExtendedSupportDate: timePtr(date(2028, 7, 1))},
+		{ClusterVersion: aws.String("1.33"), EndOfStandardSupportDate: timePtr(date(2026, 7, 23))},
+		{ClusterVersion: aws.String("1.36")}, // no dates yet — skipped
+	}}}
+
+	cal, err := UpdateCalendar(context.Background(), api, cache, now)
+	if err != nil {
+		t.Fatalf("UpdateCalendar: %v", err)
+	}
+	if len(cal.Versions) != 2 || cal.Versions[0].Version != "1.33" {
+		t.Fatalf("calendar = %+v", cal.Versions)
+	}
+	if !cal.UpdatedAt.Equal(now) || !cal.Versions[1].FetchedAt.Equal(now) {
+		t.Errorf("timestamps not stamped: %+v", cal)
+	}
+
+	if _, err := UpdateCalendar(context.Background(), &listingVersionsAPI{out: &eks.DescribeClusterVersionsOutput{}}, cache, now); err == nil {
+		t.Error("an empty API answer should be an error, not a wiped calendar")
+	}
+}
+
+func TestBuiltInCalendarCoversFallbackTable(t *testing.T) {
+	cal := BuiltInCalendar()
+	if len(cal.Versions) != len(fallbackCalendar) {
+		t.Fatalf("built-in calendar has %d rows, want %d", len(cal.Versions), len(fallbackCalendar))
+	}
+	if cal.Versions[0].Version != "1.28" {
+		t.Errorf("built-in calendar not sorted: first = %s", cal.Versions[0].Version)
+	}
+}
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+	appconfig "github.com/dantech2000/refresh/internal/config"
+)
+
+// GatherFleet fans out across every account × region with bounded
+// concurrency, returning the merged cluster statuses and any per-region
+// errors. Rows are labeled with their account in multi-account sweeps.
+func GatherFleet(ctx context.Context, accts []accounts.Account, regions []string, opts ListOptions, maxConc int, logger *slog.Logger) ([]ClusterStatus, []error) {
+	return FanOut(ctx, accts, regions, maxConc, func(ctx context.Context, a accounts.Account, cfg aws.Config) ([]ClusterStatus, error) {
+		statuses, err := NewService(cfg, logger).ListClusterStatuses(ctx, opts)
+		for i := range statuses {
+			statuses[i].Account = a.Label()
+		}
+		return statuses, err
+	})
+}
+
+// FanOut runs gather once per account × region with bounded concurrency,
+// handing it the account's config set to the region, and merges the rows.
+// A failed region comes back as an error naming it; the rest still count.
+func FanOut[T any](ctx context.Context, accts []accounts.Account, regions []string, maxConc int, gather func(ctx context.Context, a accounts.Account, cfg aws.Config) ([]T, error)) ([]T, []error) {
+	if maxConc <= 0 {
+		maxConc = appconfig.DefaultMaxConcurrency
+	}
+	var (
+		mu   sync.Mutex
+		all  []T
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, maxConc)
+	)
+	for _, acct := range accts {
+		for _, region := range regions {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(a accounts.Account, r string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				cfg := a.Config.Copy()
+				cfg.Region = r
+				rows, err := gather(ctx, a, cfg)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if a.ID != "" {
+						errs = append(errs, fmt.Errorf("account %s region %s: %w", a.Label(), r, err))
+					} else {
+						errs = append(errs, fmt.Errorf("region %s: %w", r, err))
+					}
+					return
+				}
+				all = append(all, rows...)
+			}(acct, region)
+		}
+	}
+	wg.Wait()
+	return all, errs
+}
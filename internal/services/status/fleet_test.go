@@ -0,0 +1,31 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/dantech2000/refresh/internal/accounts"
+)
+
+func TestFanOut(t *testing.T) {
+	accts := []accounts.Account{{}, {ID: "222222222222", Name: "prod"}}
+	rows, errs := FanOut(context.Background(), accts, []string{"us-east-1", "eu-west-1"}, 2,
+		func(_ context.Context, a accounts.Account, cfg aws.Config) ([]string, error) {
+			if a.ID != "" && cfg.Region == "eu-west-1" {
+				return nil, errors.New("denied")
+			}
+			return []string{a.ID + "/" + cfg.Region}, nil
+		})
+	slices.Sort(rows)
+	if want := []string{"/eu-west-1", "/us-east-1", "222222222222/us-east-1"}; !slices.Equal(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "account prod region eu-west-1: denied") {
+		t.Errorf("errs = %v, want the failed account and region named", errs)
+	}
+}
@@ -14,7 +14,11 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/selector"
 	"github.com/dantech2000/refresh/internal/services/addons"
 	"github.com/dantech2000/refresh/internal/services/common"
 	"github.com/dantech2000/refresh/internal/services/nodegroup"
@@ -54,7 +58,13 @@ type Service struct {
 	nodegroups NodegroupLister
 	addons     AddonAnalyzer
 	ec2        EC2API // optional; nil disables AMI-age and Karpenter probes
-	logger     *slog.Logger
+	// tagging narrows a selector's clusters before any is described;
+	// optional, nil describes every cluster.
+	tagging awsinternal.TaggingAPI
+	logger  *slog.Logger
+
+	// calendar caches API support windows on disk; nil skips the cache tier.
+	calendar *CalendarCache
 
 	// now is injectable for tests; nil means time.Now.
 	now func() time.Time
@@ -67,6 +77,17 @@ type Service struct {
 type ListOptions struct {
 	NamePattern    string
 	MaxConcurrency int
+	// ReleaseNotes, when set, adds the Security summary from the
+	// amazon-eks-ami release notes; Severities grades their CVEs.
+	ReleaseNotes amichangelog.Source
+	Severities   amichangelog.Severities
+	// SLA, when set, resolves each cluster's patch policy; clusters it
+	// returns a policy for get an SLA evaluation.
+	SLA SLAResolver
+	// Selector keeps only the clusters whose EKS tags match. Tags come from
+	// the DescribeCluster call each row already makes, so clusters it drops
+	// cost nothing further.
+	Selector selector.Selector
 }
 
 // NewService builds a region-scoped status service from an AWS config, wiring
@@ -86,6 +107,8 @@ func NewService(awsCfg aws.Config, logger *slog.Logger) *Service {
 		nodegroups: nodegroup.NewService(awsCfg, nil, logger),
 		addons:     addons.NewService(eksClient, logger),
 		ec2:        ec2.NewFromConfig(awsCfg),
+		tagging:    resourcegroupstaggingapi.NewFromConfig(awsCfg),
+		calendar:   DefaultCalendarCache(),
 		logger:     logger,
 	}
 }
@@ -98,8 +121,10 @@ func (s *Service) clock() time.Time {
 }
 
 // ListClusterStatuses returns the patch posture of every cluster in the
-// service's region (optionally filtered by NamePattern). Per-cluster failures
-// are recorded on the row rather than failing the whole sweep.
+// service's region (optionally filtered by NamePattern and Selector).
+// Per-cluster failures are recorded on the row rather than failing the whole
+// sweep; a cluster that can't be described is kept even under a selector,
+// since its tags are unknown.
 func (s *Service) ListClusterStatuses(ctx context.Context, opts ListOptions) ([]ClusterStatus, error) {
 	names, err := s.listClusterNames(ctx)
 	if err != nil {
@@ -114,15 +139,33 @@ func (s *Service) ListClusterStatuses(ctx context.Context, opts ListOptions) ([]
 		}
 		names = filtered
 	}
+	if !opts.Selector.Empty() {
+		// Each remaining cluster's tags are still checked when it's
+		// described, so the index only has to rule clusters out.
+		if kept, ok := awsinternal.TaggedClusters(ctx, s.tagging, names, opts.Selector); ok {
+			names = kept
+		}
+	}
 
 	conc := opts.MaxConcurrency
 	if conc <= 0 {
 		conc = common.DefaultItemConcurrency
 	}
-	results := common.ForEachParallel(ctx, names, conc,
-		func(fctx context.Context, name string) ClusterStatus {
-			return s.assembleCluster(fctx, name)
+	type row struct {
+		status   ClusterStatus
+		selected bool
+	}
+	rows := common.ForEachParallel(ctx, names, conc,
+		func(fctx context.Context, name string) row {
+			cs, selected := s.assembleCluster(fctx, name, opts)
+			return row{status: cs, selected: selected}
 		})
+	results := make([]ClusterStatus, 0, len(rows))
+	for _, r := range rows {
+		if r.selected {
+			results = append(results, r.status)
+		}
+	}
 	return results, nil
 }
 
@@ -147,8 +190,10 @@ func (s *Service) listClusterNames(ctx context.Context) ([]string, error) {
 
 // assembleCluster builds one cluster's status row. Each data source is
 // best-effort: a failure appends to Errors and leaves that field zero-valued.
-func (s *Service) assembleCluster(ctx context.Context, name string) ClusterStatus {
-	cs := ClusterStatus{Name: name, Region: s.region}
+// selected is false when the cluster's tags don't match opts.Selector, in
+// which case nothing past DescribeCluster is fetched.
+func (s *Service) assembleCluster(ctx context.Context, name string, opts ListOptions) (cs ClusterStatus, selected bool) {
+	cs = ClusterStatus{Name: name, Region: s.region}
 
 	desc, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.DescribeClusterOutput, error) {
 		return s.clusterAPI.DescribeCluster(rc, &eks.DescribeClusterInput{Name: aws.String(name)})
@@ -157,9 +202,12 @@ func (s *Service) assembleCluster(ctx context.Context, name string) ClusterStatu
 		cs.Errors = append(cs.Errors, fmt.Sprintf("describe cluster: %v", err))
 		cs.Support = SupportPosture{Tier: SupportUnknown}
 		cs.Compute = ComputeNone
-		return cs
+		return cs, true
 	}
 	cluster := desc.Cluster
+	if !opts.Selector.Matches(cluster.Tags) {
+		return cs, false
+	}
 	cs.Version = aws.ToString(cluster.Version)
 	cs.Support = s.resolveSupport(ctx, cs.Version)
 	if cluster.Health != nil {
@@ -171,7 +219,16 @@ func (s *Service) assembleCluster(ctx context.Context, name string) ClusterStatu
 		cs.Errors = append(cs.Errors, fmt.Sprintf("list nodegroups: %v", ngErr))
 	} else {
 		cs.NodegroupCount = len(ngs)
-		cs.StaleAMI = s.staleAMISummary(ctx, ngs)
+		var created map[string]time.Time
+		cs.StaleAMI, created = s.staleAMISummary(ctx, ngs)
+		if opts.ReleaseNotes != nil {
+			cs.Security = securitySummary(ctx, ngs, opts)
+		}
+		if opts.SLA != nil {
+			if policy := opts.SLA(name, s.region, cluster.Tags); policy != nil {
+				cs.SLA = evaluateSLA(ctx, ngs, created, *policy, opts, s.clock())
+			}
+		}
 	}
 
 	cs.Compute = s.detectCompute(ctx, cluster, cs.NodegroupCount)
@@ -182,12 +239,13 @@ func (s *Service) assembleCluster(ctx context.Context, name string) ClusterStatu
 	}
 	cs.AddonsBehind = behind
 
-	return cs
+	return cs, true
 }
 
 // staleAMISummary counts outdated nodegroup AMIs and, best-effort, the age of
-// the oldest stale AMI.
-func (s *Service) staleAMISummary(ctx context.Context, ngs []nodegroup.NodegroupSummary) StaleAMISummary {
+// the oldest stale AMI. It also returns the stale AMIs' creation times for the
+// SLA evaluation.
+func (s *Service) staleAMISummary(ctx context.Context, ngs []nodegroup.NodegroupSummary) (StaleAMISummary, map[string]time.Time) {
 	summary := StaleAMISummary{Total: len(ngs)}
 	var staleIDs []string
 	for _, ng := range ngs {
@@ -198,17 +256,54 @@ func (s *Service) staleAMISummary(ctx context.Context, ngs []nodegroup.Nodegroup
 			}
 		}
 	}
+	var created map[string]time.Time
 	if summary.Behind > 0 && len(staleIDs) > 0 {
-		if days := s.amiOldestDays(ctx, staleIDs); days != nil {
-			summary.OldestDays = days
+		created = s.amiCreated(ctx, staleIDs)
+		var oldest time.Time
+		for _, t := range created {
+			if oldest.IsZero() || t.Before(oldest) {
+				oldest = t
+			}
+		}
+		if !oldest.IsZero() {
+			summary.OldestDays = daysBetween(oldest, s.clock())
+		}
+	}
+	return summary, created
+}
+
+// securitySummary counts the security-relevant releases between the oldest
+// stale nodegroup's AMI release and the newest published one. Nil when the
+// release notes can't be read.
+func securitySummary(ctx context.Context, ngs []nodegroup.NodegroupSummary, opts ListOptions) *SecuritySummary {
+	oldest, oldestDate := "", ""
+	for _, ng := range ngs {
+		if ng.AMIStatus != types.AMIOutdated {
+			continue
 		}
+		if d, ok := amichangelog.ReleaseDate(ng.ReleaseVersion); ok && (oldestDate == "" || d < oldestDate) {
+			oldest, oldestDate = ng.ReleaseVersion, d
+		}
+	}
+	if oldest == "" {
+		return &SecuritySummary{}
+	}
+	cl := amichangelog.Build(ctx, opts.ReleaseNotes, oldest, "", opts.Severities)
+	if cl.Security == nil {
+		return nil
+	}
+	return &SecuritySummary{
+		ReleasesBehind: cl.Security.ReleasesBehind,
+		CVEs:           len(cl.Security.CVEs),
+		Critical:       cl.Security.Critical,
+		High:           cl.Security.High,
 	}
-	return summary
 }
 
-// amiOldestDays resolves the age in days of the oldest AMI among the given IDs
-// via DescribeImages. Returns nil when EC2 is unavailable or the call fails.
-func (s *Service) amiOldestDays(ctx context.Context, amiIDs []string) *int {
+// amiCreated resolves the creation time of the given AMIs via DescribeImages.
+// Returns nil when EC2 is unavailable or the call fails; images without a
+// parseable creation date are left out.
+func (s *Service) amiCreated(ctx context.Context, amiIDs []string) map[string]time.Time {
 	if s.ec2 == nil {
 		return nil
 	}
@@ -216,24 +311,15 @@ func (s *Service) amiOldestDays(ctx context.Context, amiIDs []string) *int {
 	if err != nil || out == nil || len(out.Images) == 0 {
 		return nil
 	}
-	var oldest time.Time
+	created := make(map[string]time.Time, len(out.Images))
 	for _, img := range out.Images {
-		created := aws.ToString(img.CreationDate)
-		if created == "" {
-			continue
-		}
-		t, perr := time.Parse(time.RFC3339, created)
+		t, perr := time.Parse(time.RFC3339, aws.ToString(img.CreationDate))
 		if perr != nil {
 			continue
 		}
-		if oldest.IsZero() || t.Before(oldest) {
-			oldest = t
-		}
-	}
-	if oldest.IsZero() {
-		return nil
+		created[aws.ToString(img.ImageId)] = t
 	}
-	return daysBetween(oldest, s.clock())
+	return created
 }
 
 // addonsBehind counts cluster addons whose installed version trails the latest
@@ -2,12 +2,19 @@ package status
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	rgttypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/smithy-go"
 
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	"github.com/dantech2000/refresh/internal/selector"
 	"github.com/dantech2000/refresh/internal/services/addons"
 	"github.com/dantech2000/refresh/internal/services/nodegroup"
 	"github.com/dantech2000/refresh/internal/types"
@@ -211,3 +218,165 @@ func TestListClusterStatuses_NameFilter(t *testing.T) {
 		t.Fatalf("name filter returned %d, want 2", len(statuses))
 	}
 }
+
+func TestListClusterStatuses_Selector(t *testing.T) {
+	api := &fakeClusterAPI{
+		clusters: []string{"web", "data", "lab", "gone"},
+		describe: map[string]*ekstypes.Cluster{
+			"web":  {Name: aws.String("web"), Version: aws.String("1.32"), Tags: map[string]string{"env": "prod", "tier": "web"}},
+			"data": {Name: aws.String("data"), Version: aws.String("1.32"), Tags: map[string]string{"env": "prod", "team": "data"}},
+			"lab":  {Name: aws.String("lab"), Version: aws.String("1.32")},
+		},
+		versions: map[string]ekstypes.ClusterVersionInformation{
+			"1.32": {ClusterVersion: aws.String("1.32"), EndOfStandardSupportDate: timePtr(date(2027, 3, 23))},
+		},
+	}
+	ng := &fakeNodegroups{byCluster: map[string][]nodegroup.NodegroupSummary{
+		"data": {{Name: "ng", AMIStatus: types.AMIOutdated}},
+	}}
+	svc := newTestService(api, ng, &fakeAddons{})
+	sel, err := selector.Parse("env=prod,team!=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := svc.ListClusterStatuses(context.Background(), ListOptions{Selector: sel})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, c := range statuses {
+		got[c.Name] = true
+	}
+	// "gone" can't be described, so its tags are unknown: it stays as an
+	// error row rather than vanishing.
+	if len(statuses) != 2 || !got["web"] || !got["gone"] {
+		t.Fatalf("selector kept %v, want web and the undescribable gone", got)
+	}
+}
+
+// fakeTagging answers GetResources with a fixed set of cluster ARNs, or err.
+type fakeTagging struct {
+	arns []string
+	err  error
+}
+
+func (f fakeTagging) GetResources(context.Context, *resourcegroupstaggingapi.GetResourcesInput, ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := &resourcegroupstaggingapi.GetResourcesOutput{}
+	for _, arn := range f.arns {
+		out.ResourceTagMappingList = append(out.ResourceTagMappingList, rgttypes.ResourceTagMapping{ResourceARN: aws.String(arn)})
+	}
+	return out, nil
+}
+
+func TestListClusterStatuses_SelectorTagIndex(t *testing.T) {
+	api := &fakeClusterAPI{
+		clusters: []string{"web", "data", "gone"},
+		describe: map[string]*ekstypes.Cluster{
+			"web":  {Name: aws.String("web"), Version: aws.String("1.32"), Tags: map[string]string{"env": "prod"}},
+			"data": {Name: aws.String("data"), Version: aws.String("1.32"), Tags: map[string]string{"env": "prod", "team": "data"}},
+		},
+		versions: map[string]ekstypes.ClusterVersionInformation{
+			"1.32": {ClusterVersion: aws.String("1.32"), EndOfStandardSupportDate: timePtr(date(2027, 3, 23))},
+		},
+	}
+	sel, err := selector.Parse("env=prod,team!=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(statuses []ClusterStatus) []string {
+		var out []string
+		for _, c := range statuses {
+			out = append(out, c.Name)
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	// The index rules out "gone" without describing it; team!=data is
+	// still checked on the clusters it keeps.
+	svc := newTestService(api, &fakeNodegroups{}, &fakeAddons{})
+	svc.tagging = fakeTagging{arns: []string{
+		"arn:aws:eks:us-east-1:111122223333:cluster/web",
+		"arn:aws:eks:us-east-1:111122223333:cluster/data",
+	}}
+	statuses, err := svc.ListClusterStatuses(context.Background(), ListOptions{Selector: sel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(statuses); !slices.Equal(got, []string{"web"}) {
+		t.Errorf("with the tag index: %v, want [web]", got)
+	}
+
+	// Without tag:GetResources every cluster is described, as before.
+	svc.tagging = fakeTagging{err: &smithy.GenericAPIError{Code: "AccessDeniedException"}}
+	statuses, err = svc.ListClusterStatuses(context.Background(), ListOptions{Selector: sel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(statuses); !slices.Equal(got, []string{"gone", "web"}) {
+		t.Errorf("on AccessDenied: %v, want [gone web]", got)
+	}
+}
+
+// fakeReleaseNotes implements amichangelog.Source.
+type fakeReleaseNotes struct {
+	releases []amichangelog.Release
+	err      error
+}
+
+func (f fakeReleaseNotes) Releases(context.Context) (amichangelog.Feed, error) {
+	return amichangelog.Feed{Releases: f.releases}, f.err
+}
+
+func TestListClusterStatuses_Security(t *testing.T) {
+	api := &fakeClusterAPI{
+		clusters: []string{"prod", "fresh"},
+		describe: map[string]*ekstypes.Cluster{
+			"prod":  {Name: aws.String("prod"), Version: aws.String("1.32")},
+			"fresh": {Name: aws.String("fresh"), Version: aws.String("1.32")},
+		},
+	}
+	ng := &fakeNodegroups{byCluster: map[string][]nodegroup.NodegroupSummary{
+		"prod": {
+			{Name: "ng-a", AMIStatus: types.AMIOutdated, ReleaseVersion: "1.32.0-20260401"},
+			{Name: "ng-b", AMIStatus: types.AMIOutdated, ReleaseVersion: "1.32.0-20260201"},
+		},
+		"fresh": {{Name: "ng-a", AMIStatus: types.AMILatest, ReleaseVersion: "1.32.0-20260601"}},
+	}}
+	notes := fakeReleaseNotes{releases: []amichangelog.Release{
+		{Tag: "v20260601", Body: "- Fixes CVE-2026-1001\n"},
+		{Tag: "v20260501", Body: "- containerd 1.7.27\n"},
+		{Tag: "v20260301", Body: "- Fixes CVE-2026-1002, CVE-2026-1003\n"},
+		{Tag: "v20260101", Body: "- Fixes CVE-2025-9001\n"},
+	}}
+	opts := ListOptions{ReleaseNotes: notes, Severities: amichangelog.Severities{"CVE-2026-1002": "critical"}}
+
+	svc := newTestService(api, ng, &fakeAddons{})
+	statuses, err := svc.ListClusterStatuses(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := map[string]ClusterStatus{}
+	for _, c := range statuses {
+		byName[c.Name] = c
+	}
+	// From the oldest stale release (20260201): two releases fix CVEs.
+	want := SecuritySummary{ReleasesBehind: 2, CVEs: 3, Critical: 1}
+	if got := byName["prod"].Security; got == nil || *got != want {
+		t.Errorf("prod security = %+v, want %+v", got, want)
+	}
+	if got := byName["fresh"].Security; got == nil || *got != (SecuritySummary{}) {
+		t.Errorf("fresh security = %+v, want zero", got)
+	}
+
+	opts.ReleaseNotes = fakeReleaseNotes{err: errors.New("offline")}
+	statuses, _ = svc.ListClusterStatuses(context.Background(), opts)
+	for _, c := range statuses {
+		if c.Name == "prod" && c.Security != nil {
+			t.Errorf("unavailable release notes: security = %+v, want nil", c.Security)
+		}
+	}
+}
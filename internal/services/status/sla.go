@@ -0,0 +1,148 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	"github.com/dantech2000/refresh/internal/services/nodegroup"
+	"github.com/dantech2000/refresh/internal/types"
+)
+
+// DefaultSLAWarnDays is how long before an AMI-age breach a nodegroup counts
+// as approaching it when the policy doesn't say.
+const DefaultSLAWarnDays = 7
+
+// SLAPolicy is the AMI patch policy a cluster's nodegroups are held to. A zero
+// limit isn't enforced.
+type SLAPolicy struct {
+	MaxAMIAgeDays     int `json:"maxAmiAgeDays,omitempty" yaml:"maxAmiAgeDays,omitempty"`
+	MaxReleasesBehind int `json:"maxReleasesBehind,omitempty" yaml:"maxReleasesBehind,omitempty"`
+	WarnDays          int `json:"warnDays" yaml:"warnDays"`
+	// Source names the refresh.yaml scopes the limits came from.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// SLAResolver returns the policy for one cluster, or nil when none applies.
+// tags are the cluster's EKS tags.
+type SLAResolver func(cluster, region string, tags map[string]string) *SLAPolicy
+
+// SLAState is where a nodegroup, or the worst of a cluster's, stands against
+// its policy.
+type SLAState string
+
+const (
+	SLAOK          SLAState = "ok"
+	SLAApproaching SLAState = "approaching"
+	SLABreached    SLAState = "breached"
+	// SLAUnknown is a stale nodegroup whose age and releases behind couldn't
+	// be resolved (e.g. a custom AMI without DescribeImages access).
+	SLAUnknown SLAState = "unknown"
+)
+
+// slaRank orders states from compliant to breached.
+var slaRank = map[SLAState]int{SLAOK: 0, SLAUnknown: 1, SLAApproaching: 2, SLABreached: 3}
+
+// NodegroupSLA is one nodegroup measured against the policy.
+type NodegroupSLA struct {
+	Name  string   `json:"name" yaml:"name"`
+	State SLAState `json:"state" yaml:"state"`
+	// AMIAgeDays is the age of a stale nodegroup's AMI. Nil for a nodegroup
+	// on the latest AMI, which is compliant however old that AMI is.
+	AMIAgeDays     *int `json:"amiAgeDays,omitempty" yaml:"amiAgeDays,omitempty"`
+	ReleasesBehind *int `json:"releasesBehind,omitempty" yaml:"releasesBehind,omitempty"`
+	// BreachInDays counts down to the AMI-age breach; negative is days since
+	// it. Nil when no age limit applies or the age is unknown.
+	BreachInDays *int `json:"breachInDays,omitempty" yaml:"breachInDays,omitempty"`
+	// Reason says which limit drives State.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// SLAStatus is a cluster's nodegroups measured against its policy.
+type SLAStatus struct {
+	Policy SLAPolicy `json:"policy" yaml:"policy"`
+	// State is the worst nodegroup's.
+	State SLAState `json:"state" yaml:"state"`
+	// BreachInDays is the soonest nodegroup breach (or the oldest one).
+	BreachInDays *int           `json:"breachInDays,omitempty" yaml:"breachInDays,omitempty"`
+	Nodegroups   []NodegroupSLA `json:"nodegroups" yaml:"nodegroups"`
+}
+
+// evaluateSLA measures each nodegroup against policy. created maps AMI IDs to
+// their creation time; releases behind come from the release notes when
+// opts has them.
+func evaluateSLA(ctx context.Context, ngs []nodegroup.NodegroupSummary, created map[string]time.Time, policy SLAPolicy, opts ListOptions, now time.Time) *SLAStatus {
+	if policy.WarnDays <= 0 {
+		policy.WarnDays = DefaultSLAWarnDays
+	}
+	out := &SLAStatus{Policy: policy, State: SLAOK, Nodegroups: []NodegroupSLA{}}
+	for _, ng := range ngs {
+		n := NodegroupSLA{Name: ng.Name, State: SLAOK}
+		if ng.AMIStatus == types.AMIOutdated {
+			if t, ok := created[ng.CurrentAMI]; ok {
+				n.AMIAgeDays = daysBetween(t, now)
+			}
+			if opts.ReleaseNotes != nil && policy.MaxReleasesBehind > 0 {
+				if cl := amichangelog.Build(ctx, opts.ReleaseNotes, ng.ReleaseVersion, "", nil); !cl.Degraded {
+					behind := cl.Behind
+					n.ReleasesBehind = &behind
+				}
+			}
+			n.judge(policy)
+		}
+		if slaRank[n.State] > slaRank[out.State] {
+			out.State = n.State
+		}
+		if n.BreachInDays != nil && (out.BreachInDays == nil || *n.BreachInDays < *out.BreachInDays) {
+			d := *n.BreachInDays
+			out.BreachInDays = &d
+		}
+		out.Nodegroups = append(out.Nodegroups, n)
+	}
+	return out
+}
+
+// judge sets the state and reason of a stale nodegroup from whichever limit
+// is closest to, or furthest past, breaching.
+func (n *NodegroupSLA) judge(p SLAPolicy) {
+	var reasons []string
+	state := SLAOK
+	raise := func(s SLAState, reason string) {
+		if slaRank[s] > slaRank[state] {
+			state, reasons = s, nil
+		}
+		if s == state && reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	measured := false
+	if p.MaxAMIAgeDays > 0 && n.AMIAgeDays != nil {
+		measured = true
+		in := p.MaxAMIAgeDays - *n.AMIAgeDays
+		n.BreachInDays = &in
+		reason := fmt.Sprintf("AMI %dd old (max %d)", *n.AMIAgeDays, p.MaxAMIAgeDays)
+		switch {
+		case in < 0:
+			raise(SLABreached, reason)
+		case in <= p.WarnDays:
+			raise(SLAApproaching, reason)
+		}
+	}
+	if p.MaxReleasesBehind > 0 && n.ReleasesBehind != nil {
+		measured = true
+		reason := fmt.Sprintf("%d releases behind (max %d)", *n.ReleasesBehind, p.MaxReleasesBehind)
+		switch {
+		case *n.ReleasesBehind > p.MaxReleasesBehind:
+			raise(SLABreached, reason)
+		case *n.ReleasesBehind == p.MaxReleasesBehind:
+			// The next release breaches it.
+			raise(SLAApproaching, reason)
+		}
+	}
+	if !measured {
+		raise(SLAUnknown, "AMI age and releases behind unavailable")
+	}
+	n.State, n.Reason = state, strings.Join(reasons, "; ")
+}
@@ -0,0 +1,132 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+	"github.com/dantech2000/refresh/internal/services/nodegroup"
+	"github.com/dantech2000/refresh/internal/types"
+)
+
+// fakeEC2 implements EC2API with fixed image creation dates.
+type fakeEC2 struct {
+	created map[string]string
+}
+
+func (f *fakeEC2) DescribeImages(_ context.Context, in *ec2.DescribeImagesInput, _ ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	out := &ec2.DescribeImagesOutput{}
+	for _, id := range in.ImageIds {
+		if c, ok := f.created[id]; ok {
+			out.Images = append(out.Images, ec2types.Image{ImageId: aws.String(id), CreationDate: aws.String(c)})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) DescribeInstances(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{}, nil
+}
+
+func TestListClusterStatuses_SLA(t *testing.T) {
+	api := &fakeClusterAPI{
+		clusters: []string{"prod", "dev", "lab"},
+		describe: map[string]*ekstypes.Cluster{
+			"prod": {Name: aws.String("prod"), Version: aws.String("1.32"), Tags: map[string]string{"env": "prod"}},
+			"dev":  {Name: aws.String("dev"), Version: aws.String("1.32"), Tags: map[string]string{"env": "dev"}},
+			"lab":  {Name: aws.String("lab"), Version: aws.String("1.32")},
+		},
+	}
+	// The clock is 2026-06-11.
+	ng := &fakeNodegroups{byCluster: map[string][]nodegroup.NodegroupSummary{
+		"prod": {
+			{Name: "ng-old", AMIStatus: types.AMIOutdated, CurrentAMI: "ami-may", ReleaseVersion: "1.32.0-20260501"},
+			{Name: "ng-new", AMIStatus: types.AMILatest, CurrentAMI: "ami-jun", ReleaseVersion: "1.32.0-20260601"},
+		},
+		"dev": {
+			{Name: "ng-a", AMIStatus: types.AMIOutdated, CurrentAMI: "ami-may", ReleaseVersion: "1.32.0-20260501"},
+			{Name: "ng-custom", AMIStatus: types.AMIOutdated, CurrentAMI: "ami-custom"},
+		},
+		"lab": {{Name: "ng-a", AMIStatus: types.AMIOutdated, CurrentAMI: "ami-may"}},
+	}}
+	svc := newTestService(api, ng, &fakeAddons{})
+	svc.ec2 = &fakeEC2{created: map[string]string{
+		"ami-may": "2026-05-05T00:00:00.000Z", // 37 days old
+		"ami-jun": "2026-06-01T00:00:00.000Z",
+	}}
+	opts := ListOptions{
+		ReleaseNotes: fakeReleaseNotes{releases: []amichangelog.Release{
+			{Tag: "v20260601"}, {Tag: "v20260520"}, {Tag: "v20260510"}, {Tag: "v20260501"},
+		}},
+		SLA: func(cluster, region string, tags map[string]string) *SLAPolicy {
+			switch tags["env"] {
+			case "prod":
+				return &SLAPolicy{MaxAMIAgeDays: 30}
+			case "dev":
+				return &SLAPolicy{MaxAMIAgeDays: 40, MaxReleasesBehind: 5}
+			}
+			return nil
+		},
+	}
+	statuses, err := svc.ListClusterStatuses(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]ClusterStatus{}
+	for _, c := range statuses {
+		byName[c.Name] = c
+	}
+
+	prod := byName["prod"].SLA
+	if prod == nil || prod.State != SLABreached || !byName["prod"].SLABreached() {
+		t.Fatalf("prod SLA = %+v, want breached", prod)
+	}
+	if prod.BreachInDays == nil || *prod.BreachInDays != -7 || prod.Policy.WarnDays != DefaultSLAWarnDays {
+		t.Errorf("prod breachInDays = %v, policy = %+v", prod.BreachInDays, prod.Policy)
+	}
+	if n := prod.Nodegroups[1]; n.Name != "ng-new" || n.State != SLAOK || n.AMIAgeDays != nil {
+		t.Errorf("latest nodegroup = %+v, want ok without an age", n)
+	}
+
+	dev := byName["dev"].SLA
+	if dev == nil || dev.State != SLAApproaching || !byName["dev"].SLAApproaching() {
+		t.Fatalf("dev SLA = %+v, want approaching", dev)
+	}
+	a := dev.Nodegroups[0]
+	if a.BreachInDays == nil || *a.BreachInDays != 3 || a.ReleasesBehind == nil || *a.ReleasesBehind != 3 {
+		t.Errorf("dev ng-a = %+v, want breach in 3d and 3 releases behind", a)
+	}
+	if c := dev.Nodegroups[1]; c.State != SLAUnknown {
+		t.Errorf("custom AMI without an age = %+v, want unknown", c)
+	}
+
+	if byName["lab"].SLA != nil {
+		t.Errorf("lab SLA = %+v, want nil without a policy", byName["lab"].SLA)
+	}
+}
+
+func TestNodegroupSLA_Judge(t *testing.T) {
+	p := SLAPolicy{MaxAMIAgeDays: 30, MaxReleasesBehind: 2, WarnDays: 5}
+	cases := []struct {
+		age, behind int
+		want        SLAState
+	}{
+		{age: 10, behind: 1, want: SLAOK},
+		{age: 26, behind: 0, want: SLAApproaching},
+		{age: 10, behind: 2, want: SLAApproaching},
+		{age: 31, behind: 0, want: SLABreached},
+		{age: 10, behind: 3, want: SLABreached},
+	}
+	for _, c := range cases {
+		n := NodegroupSLA{AMIAgeDays: &c.age, ReleasesBehind: &c.behind}
+		n.judge(p)
+		if n.State != c.want {
+			t.Errorf("age %d, behind %d: state = %s (%s), want %s", c.age, c.behind, n.State, n.Reason, c.want)
+		}
+	}
+}
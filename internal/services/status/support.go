@@ -16,8 +16,9 @@ const extendedSupportPremiumUSDPerHour = 0.50
 
 // fallbackCalendar maps a Kubernetes minor version to its published EKS support
 // window, used when DescribeClusterVersions is unavailable (missing permission,
-// older API). Dates are AWS's published end-of-support calendar; rows derived
-// from this table are flagged Fallback.
+// older API) and the cached calendar doesn't know the version either. Dates
+// are AWS's published end-of-support calendar; rows derived from this table
+// are flagged Fallback with SourceBuiltIn.
 var fallbackCalendar = map[string]struct {
 	standardEnd time.Time
 	extendedEnd time.Time
@@ -42,24 +43,42 @@ type supportVersionsAPI interface {
 }
 
 // resolveSupportPosture is the shared support-resolution core: prefer
-// DescribeClusterVersions, fall back to the compiled-in calendar, then classify
-// relative to now. Pure given (api, version, now) — used by both the fleet
-// Service and the exported SupportResolver.
-func resolveSupportPosture(ctx context.Context, api supportVersionsAPI, version string, now time.Time) SupportPosture {
+// DescribeClusterVersions (recording the answer in the calendar cache), then
+// the cached calendar, then the compiled-in one, and classify relative to now.
+// Used by both the fleet Service and the exported SupportResolver. A nil cache
+// skips the cache tier.
+func resolveSupportPosture(ctx context.Context, api supportVersionsAPI, cache *CalendarCache, version string, now time.Time) SupportPosture {
 	if version == "" {
 		return SupportPosture{Tier: SupportUnknown}
 	}
-	std, ext, ok := supportDatesFromAPI(ctx, api, version)
-	fallback := false
-	if !ok {
-		cal, found := fallbackCalendar[version]
-		if !found {
-			return SupportPosture{Tier: SupportUnknown}
+	if std, ext, ok := supportDatesFromAPI(ctx, api, version); ok {
+		entry := CalendarEntry{Version: version, StandardUntil: std}
+		if !ext.IsZero() {
+			entry.ExtendedUntil = &ext
 		}
-		std, ext = cal.standardEnd, cal.extendedEnd
-		fallback = true
+		// Best-effort: a read-only config dir must not fail a status run.
+		_ = cache.Record(now, entry)
+		posture := classifySupport(std, ext, now, false)
+		posture.Source = SourceAPI
+		return posture
 	}
-	return classifySupport(std, ext, now, fallback)
+	if e, ok := cache.Lookup(version); ok {
+		posture := e.Posture(now)
+		posture.Fallback = true
+		posture.Source = SourceCache
+		if !e.FetchedAt.IsZero() {
+			fetched := e.FetchedAt
+			posture.CachedAt = &fetched
+		}
+		return posture
+	}
+	cal, found := fallbackCalendar[version]
+	if !found {
+		return SupportPosture{Tier: SupportUnknown}
+	}
+	posture := classifySupport(cal.standardEnd, cal.extendedEnd, now, true)
+	posture.Source = SourceBuiltIn
+	return posture
 }
 
 // resolveSupport returns the support posture for a Kubernetes version, caching
@@ -79,7 +98,7 @@ func (s *Service) resolveSupport(ctx context.Context, version string) SupportPos
 	}
 	s.supportMu.Unlock()
 
-	posture := resolveSupportPosture(ctx, s.clusterAPI, version, s.clock())
+	posture := resolveSupportPosture(ctx, s.clusterAPI, s.calendar, version, s.clock())
 
 	s.supportMu.Lock()
 	s.supportCache[version] = posture
@@ -91,25 +110,26 @@ func (s *Service) resolveSupport(ctx context.Context, version string) SupportPos
 // `refresh status` — for reuse by `cluster upgrade-check` and `cluster
 // describe`. Stateless apart from the EKS client; safe to construct per command.
 type SupportResolver struct {
-	api supportVersionsAPI
-	now func() time.Time
+	api      supportVersionsAPI
+	calendar *CalendarCache
+	now      func() time.Time
 }
 
 // NewSupportResolver builds a resolver over an EKS client (anything exposing
-// DescribeClusterVersions).
+// DescribeClusterVersions), backed by the shared on-disk calendar cache.
 func NewSupportResolver(api supportVersionsAPI) *SupportResolver {
-	return &SupportResolver{api: api, now: time.Now}
+	return &SupportResolver{api: api, calendar: DefaultCalendarCache(), now: time.Now}
 }
 
 // Resolve returns the support posture for a Kubernetes version (e.g. "1.32"),
-// falling back to the compiled-in calendar when DescribeClusterVersions is
-// unavailable.
+// falling back to the cached and then the compiled-in calendar when
+// DescribeClusterVersions is unavailable.
 func (r *SupportResolver) Resolve(ctx context.Context, version string) SupportPosture {
 	now := time.Now()
 	if r.now != nil {
 		now = r.now()
 	}
-	return resolveSupportPosture(ctx, r.api, version, now)
+	return resolveSupportPosture(ctx, r.api, r.calendar, version, now)
 }
 
 // supportDatesFromAPI fetches the standard/extended end dates for a version via
@@ -102,6 +102,8 @@ func TestResolveSupport_FromAPI(t *testing.T) {
 // cluster upgrade-check / describe) resolves the same posture as the fleet
 // Service, from the API and from the fallback calendar. (REF-145)
 func TestSupportResolver_Reuse(t *testing.T) {
+	// Keep the resolver's calendar cache out of the real config dir.
+	t.Setenv("REFRESH_CONFIG_HOME", t.TempDir())
 	api := &fakeClusterAPI{
 		versions: map[string]ekstypes.ClusterVersionInformation{
 			"1.32": {
@@ -4,7 +4,12 @@
 // is single-region; multi-region fan-out lives in the command layer.
 package status
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/amichangelog"
+)
 
 // ComputeType describes how a cluster provisions its worker nodes. It exists so
 // `refresh status` never renders a nodegroup-less cluster as an empty row.
@@ -32,6 +37,18 @@ const (
 	SupportUnknown     SupportTier = "unknown"
 )
 
+// SupportSource records where a support window came from.
+type SupportSource string
+
+const (
+	// SourceAPI is a live DescribeClusterVersions answer.
+	SourceAPI SupportSource = "api"
+	// SourceCache is the on-disk calendar written by earlier API answers.
+	SourceCache SupportSource = "cache"
+	// SourceBuiltIn is the compiled-in calendar, the last resort.
+	SourceBuiltIn SupportSource = "built-in"
+)
+
 // SupportPosture is the resolved support window for a cluster's version.
 type SupportPosture struct {
 	Tier          SupportTier `json:"tier" yaml:"tier"`
@@ -43,9 +60,22 @@ type SupportPosture struct {
 	// ExtraCostUSDPerHour is the per-cluster premium of the current tier over
 	// standard support (0 unless extended).
 	ExtraCostUSDPerHour float64 `json:"extraCostUsdPerHour,omitempty" yaml:"extraCostUsdPerHour,omitempty"`
-	// Fallback is true when the posture came from the compiled-in calendar
-	// because DescribeClusterVersions was unavailable.
-	Fallback bool `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+	// Fallback is true when DescribeClusterVersions was unavailable and the
+	// window came from the cached or compiled-in calendar; Source says which.
+	Fallback bool          `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+	Source   SupportSource `json:"source,omitempty" yaml:"source,omitempty"`
+	// CachedAt is when the cached window was fetched (SourceCache only), so
+	// callers can show how old it is.
+	CachedAt *time.Time `json:"cachedAt,omitempty" yaml:"cachedAt,omitempty"`
+}
+
+// SourceLabel describes the posture's source for display: "api", "cache, 3d
+// old", or "built-in".
+func (p SupportPosture) SourceLabel(now time.Time) string {
+	if p.Source == SourceCache && p.CachedAt != nil {
+		return fmt.Sprintf("cache, %s old", amichangelog.HumanAge(now.Sub(*p.CachedAt)))
+	}
+	return string(p.Source)
 }
 
 // StaleAMISummary summarizes nodegroup AMI posture for a cluster.
@@ -56,6 +86,17 @@ type StaleAMISummary struct {
 	OldestDays *int `json:"oldestDays,omitempty" yaml:"oldestDays,omitempty"`
 }
 
+// SecuritySummary is the security content a cluster's stale nodegroups are
+// missing: the amazon-eks-ami releases after the oldest stale nodegroup's
+// release that fix CVEs.
+type SecuritySummary struct {
+	// ReleasesBehind counts the security-relevant releases behind.
+	ReleasesBehind int `json:"releasesBehind" yaml:"releasesBehind"`
+	CVEs           int `json:"cves" yaml:"cves"`
+	Critical       int `json:"critical,omitempty" yaml:"critical,omitempty"`
+	High           int `json:"high,omitempty" yaml:"high,omitempty"`
+}
+
 // AddonsBehindSummary summarizes addon version posture for a cluster.
 type AddonsBehindSummary struct {
 	Total  int      `json:"total" yaml:"total"`
@@ -65,14 +106,22 @@ type AddonsBehindSummary struct {
 
 // ClusterStatus is the fleet-status row for a single cluster.
 type ClusterStatus struct {
-	Name           string              `json:"name" yaml:"name"`
-	Region         string              `json:"region" yaml:"region"`
+	Name   string `json:"name" yaml:"name"`
+	Region string `json:"region" yaml:"region"`
+	// Account labels the cluster's AWS account in multi-account sweeps (the
+	// Organizations name, else the ID); empty for single-account runs.
+	Account        string              `json:"account,omitempty" yaml:"account,omitempty"`
 	Version        string              `json:"version" yaml:"version"`
 	Support        SupportPosture      `json:"support" yaml:"support"`
 	Compute        ComputeType         `json:"compute" yaml:"compute"`
 	NodegroupCount int                 `json:"nodegroupCount" yaml:"nodegroupCount"`
 	StaleAMI       StaleAMISummary     `json:"staleAmi" yaml:"staleAmi"`
 	AddonsBehind   AddonsBehindSummary `json:"addonsBehind" yaml:"addonsBehind"`
+	// Security is nil when it wasn't looked up or the release notes were
+	// unavailable.
+	Security *SecuritySummary `json:"security,omitempty" yaml:"security,omitempty"`
+	// SLA is nil when no refresh.yaml sla policy applies to the cluster.
+	SLA *SLAStatus `json:"sla,omitempty" yaml:"sla,omitempty"`
 	// HealthIssues is the count of AWS-reported control-plane health issues
 	// (DescribeCluster Health.Issues) — degraded resources, IAM failures, etc.
 	HealthIssues int `json:"healthIssues,omitempty" yaml:"healthIssues,omitempty"`
@@ -93,8 +142,23 @@ func (c ClusterStatus) SupportRisk() bool {
 	return c.Support.Tier == SupportExtended || c.Support.Tier == SupportUnsupported
 }
 
+// SLABreached reports whether a nodegroup is past its SLA (drives the
+// exit-code "policy breached" signal).
+func (c ClusterStatus) SLABreached() bool {
+	return c.SLA != nil && c.SLA.State == SLABreached
+}
+
+// SLAApproaching reports whether a nodegroup is within the policy's warning
+// window of breaching (drives the exit-code "policy approaching" signal).
+func (c ClusterStatus) SLAApproaching() bool {
+	return c.SLA != nil && c.SLA.State == SLAApproaching
+}
+
 // FleetStatus is the aggregate posture across clusters and regions — the
 // payload serialized for json/yaml output.
 type FleetStatus struct {
 	Clusters []ClusterStatus `json:"clusters" yaml:"clusters"`
+	// Errors lists accounts or regions that could not be swept; their
+	// clusters are missing from Clusters rather than failing the run.
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
@@ -35,7 +35,7 @@ func (s *Service) UpgradeAddons(ctx context.Context, clusterName, targetVersion
 	addonList = addons.SortByDependency(addonList)
 
 	for _, a := range addonList {
-		if matchesAny(a.Name, skip) {
+		if MatchesAny(a.Name, skip) {
 			progress("addon %s: skipped (managed out-of-band)", a.Name)
 			continue
 		}
@@ -46,46 +46,57 @@ func (s *Service) UpgradeAddons(ctx context.Context, clusterName, targetVersion
 		}
 		chosen := versions[0].Version
 
-		// Resume support: a re-run after Ctrl+C may find an addon still
-		// CREATING/UPDATING from the previous run. The control-plane and
-		// nodegroup phases attach to such in-flight updates; the addon phase
-		// must too, or svc.Update's pre-update health gate hard-fails on the
-		// UPDATING status. Wait for it to settle, then re-read the installed
-		// version and let the normal skip/converge logic below decide.
-		current, status, err := svc.AddonStatus(ctx, clusterName, a.Name)
-		if err != nil {
-			return fmt.Errorf("addon %s: reading status: %w", a.Name, err)
-		}
-		if status == ekstypes.AddonStatusCreating || status == ekstypes.AddonStatusUpdating {
-			progress("addon %s is %s (in-flight update from a previous run); attaching and waiting for it to settle", a.Name, status)
-			if err := svc.WaitUntilActive(ctx, clusterName, a.Name, addonWaitTimeout, s.PollInterval); err != nil {
-				return fmt.Errorf("addon %s: waiting for in-flight update to finish: %w", a.Name, err)
-			}
-			if current, _, err = svc.AddonStatus(ctx, clusterName, a.Name); err != nil {
-				return fmt.Errorf("addon %s: reading status after attach: %w", a.Name, err)
-			}
-		}
-
-		if addons.CompareVersions(current, chosen) >= 0 {
-			progress("addon %s already at %s (latest compatible with %s), skipping", a.Name, current, targetVersion)
-			continue
+		if err := s.updateAddon(ctx, svc, clusterName, a.Name, chosen, fmt.Sprintf("latest compatible with %s", targetVersion), progress); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		progress("addon %s: %s → %s", a.Name, current, chosen)
-		result, err := svc.Update(ctx, clusterName, a.Name, addons.UpdateOptions{
-			Version:      chosen,
-			HealthCheck:  true,
-			Wait:         true,
-			WaitTimeout:  addonWaitTimeout,
-			PollInterval: s.PollInterval,
-		})
-		if err != nil {
-			return fmt.Errorf("addon %s update to %s failed: %w", a.Name, chosen, err)
+// updateAddon converges one addon on version: it attaches to an update still
+// in flight from a previous run, skips an addon already at (or past) version,
+// and otherwise updates it behind the addon service's health gate, waiting
+// for ACTIVE. why describes the version in progress lines.
+func (s *Service) updateAddon(ctx context.Context, svc *addons.ServiceImpl, clusterName, name, version, why string, progress ProgressFunc) error {
+	// Resume support: a re-run after Ctrl+C may find an addon still
+	// CREATING/UPDATING from the previous run. The control-plane and
+	// nodegroup phases attach to such in-flight updates; the addon phase
+	// must too, or svc.Update's pre-update health gate hard-fails on the
+	// UPDATING status. Wait for it to settle, then re-read the installed
+	// version and let the normal skip/converge logic below decide.
+	current, status, err := svc.AddonStatus(ctx, clusterName, name)
+	if err != nil {
+		return fmt.Errorf("addon %s: reading status: %w", name, err)
+	}
+	if status == ekstypes.AddonStatusCreating || status == ekstypes.AddonStatusUpdating {
+		progress("addon %s is %s (in-flight update from a previous run); attaching and waiting for it to settle", name, status)
+		if err := svc.WaitUntilActive(ctx, clusterName, name, addonWaitTimeout, s.PollInterval); err != nil {
+			return fmt.Errorf("addon %s: waiting for in-flight update to finish: %w", name, err)
 		}
-		if result.HealthIssues != "" {
-			return fmt.Errorf("addon %s updated to %s but failed its health gate: %s", a.Name, chosen, result.HealthIssues)
+		if current, _, err = svc.AddonStatus(ctx, clusterName, name); err != nil {
+			return fmt.Errorf("addon %s: reading status after attach: %w", name, err)
 		}
-		progress("addon %s is ACTIVE at %s", a.Name, chosen)
 	}
+
+	if addons.CompareVersions(current, version) >= 0 {
+		progress("addon %s already at %s (%s), skipping", name, current, why)
+		return nil
+	}
+
+	progress("addon %s: %s → %s", name, current, version)
+	result, err := svc.Update(ctx, clusterName, name, addons.UpdateOptions{
+		Version:      version,
+		HealthCheck:  true,
+		Wait:         true,
+		WaitTimeout:  addonWaitTimeout,
+		PollInterval: s.PollInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("addon %s update to %s failed: %w", name, version, err)
+	}
+	if result.HealthIssues != "" {
+		return fmt.Errorf("addon %s updated to %s but failed its health gate: %s", name, version, result.HealthIssues)
+	}
+	progress("addon %s is ACTIVE at %s", name, version)
 	return nil
 }
@@ -33,6 +33,9 @@ type ExecuteOptions struct {
 	// NodegroupObserver, when set, renders a live per-node roll view during each
 	// nodegroup roll. Supplied by the command (view) layer; nil → text progress.
 	NodegroupObserver RollObserver
+	// NodegroupReleaseVersion pins the final hop's nodegroup rolls to an AMI
+	// release; intermediate hops roll to the latest release of their version.
+	NodegroupReleaseVersion string
 }
 
 // Report describes how far an execution got: what ran, where it stopped, and
@@ -136,15 +139,22 @@ func (s *Service) phases(plan *Plan, opts ExecuteOptions) []phase {
 				return s.UpgradeAddons(ctx, plan.ClusterName, hop.To, opts.SkipAddons, opts.Progress)
 			},
 		})
+		release, target := "", hop.To
+		if hop.To == plan.TargetVersion && opts.NodegroupReleaseVersion != "" {
+			release = opts.NodegroupReleaseVersion
+			target = fmt.Sprintf("%s (release %s)", hop.To, release)
+		}
 		out = append(out, phase{
-			label: fmt.Sprintf("nodegroup rolls to %s (%d nodegroup(s))", hop.To, len(ngSteps)),
+			label: fmt.Sprintf("nodegroup rolls to %s (%d nodegroup(s))", target, len(ngSteps)),
 			steps: ngSteps,
 			run: func(ctx context.Context) error {
 				return s.UpgradeNodegroups(ctx, plan.ClusterName, hop.To, NodegroupRollOptions{
-					SkipPatterns: opts.SkipNodegroups,
-					Force:        opts.Force,
-					Gate:         opts.NodegroupGate,
-					Observer:     opts.NodegroupObserver,
+					SkipPatterns:   opts.SkipNodegroups,
+					Force:          opts.Force,
+					Gate:           opts.NodegroupGate,
+					Observer:       opts.NodegroupObserver,
+					ReleaseVersion: release,
+					CustomAMI:      hop.To == plan.TargetVersion,
 				}, opts.Progress)
 			},
 		})
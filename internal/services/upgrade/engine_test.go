@@ -236,6 +236,43 @@ func TestExecute_DoubleRunIsNoOp(t *testing.T) {
 	}
 }
 
+// A pinned nodegroup release applies to the final hop only: intermediate
+// hops roll to the latest release of their version.
+func TestExecute_NodegroupReleasePinsFinalHop(t *testing.T) {
+	w := newWorld()
+	m := newWorldMock(w)
+	roll := m.UpdateNodegroupVersionFn
+	var releases []string
+	m.UpdateNodegroupVersionFn = func(ctx context.Context, in *eks.UpdateNodegroupVersionInput, opts ...func(*eks.Options)) (*eks.UpdateNodegroupVersionOutput, error) {
+		releases = append(releases, aws.ToString(in.Version)+"="+aws.ToString(in.ReleaseVersion))
+		return roll(ctx, in, opts...)
+	}
+	svc := newTestService(m)
+	ctx := context.Background()
+
+	plan, err := svc.BuildPlan(ctx, "prod-east", "1.33", PlanOptions{NodegroupReleaseVersion: "1.33.0-20260601"})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	var described []string
+	for _, hop := range plan.Hops {
+		for _, st := range hop.Steps {
+			if st.Type == StepNodegroup {
+				described = append(described, st.Description)
+			}
+		}
+	}
+	if strings.Join(described, "; ") != "nodegroup workers-a → 1.32; nodegroup workers-a → 1.33 (release 1.33.0-20260601)" {
+		t.Errorf("nodegroup steps = %q", described)
+	}
+	if _, err := svc.Execute(ctx, plan, ExecuteOptions{Yes: true, NodegroupReleaseVersion: "1.33.0-20260601"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if strings.Join(releases, " ") != "1.32= 1.33=1.33.0-20260601" {
+		t.Errorf("rolls = %q, want the release on the 1.33 hop only", releases)
+	}
+}
+
 // Acceptance (REF-102): SIGINT mid-phase stops cleanly and the error tells
 // the user what continues server-side and how to resume.
 func TestExecute_InterruptLeavesResumeGuidance(t *testing.T) {
@@ -36,6 +36,13 @@ type NodegroupRollOptions struct {
 	Gate NodegroupGate
 	// Observer, when set, renders a live per-node roll view during each roll.
 	Observer RollObserver
+	// ReleaseVersion pins the rolls to a specific AMI release (e.g.
+	// "1.31.0-20260601") instead of the latest for targetVersion. The caller
+	// validates it against the published releases.
+	ReleaseVersion string
+	// CustomAMI rolls custom-AMI nodegroups with the service's
+	// CustomAMIRoller (set on the final hop only).
+	CustomAMI bool
 }
 
 // UpgradeNodegroups rolls every managed nodegroup to targetVersion, serially
@@ -43,8 +50,9 @@ type NodegroupRollOptions struct {
 // same UpdateNodegroupVersion machinery as the AMI refresh — a version roll
 // IS an AMI refresh with Version set.
 //
-// Already-current nodegroups are skipped (idempotent rerun); custom-AMI
-// nodegroups are surfaced as manual actions, never mutated. A gate failure
+// Already-current nodegroups are skipped (idempotent rerun). Custom-AMI
+// nodegroups roll onto the CustomAMIRoller's image when opts.CustomAMI is set,
+// and are otherwise surfaced as manual actions, never mutated. A gate failure
 // halts the remaining nodegroups so the operator can intervene.
 func (s *Service) UpgradeNodegroups(ctx context.Context, clusterName, targetVersion string, opts NodegroupRollOptions, progress ProgressFunc) error {
 	progress = ensureProgress(progress)
@@ -60,15 +68,25 @@ func (s *Service) UpgradeNodegroups(ctx context.Context, clusterName, targetVers
 	}
 
 	for _, ng := range nodegroups {
+		if ng.CustomAMI && s.CustomAMI != nil && !MatchesAny(ng.Name, opts.SkipPatterns) {
+			if !opts.CustomAMI {
+				progress("nodegroup %s: custom AMI, rolled once on the final hop", ng.Name)
+				continue
+			}
+			if err := s.upgradeCustomNodegroup(ctx, clusterName, targetVersion, ng, gate, opts, progress); err != nil {
+				return err
+			}
+			continue
+		}
 		switch {
 		case versionAtLeast(ng.Version, targetVersion):
 			progress("nodegroup %s already at %s, skipping", ng.Name, ng.Version)
 			continue
-		case matchesAny(ng.Name, opts.SkipPatterns):
+		case MatchesAny(ng.Name, opts.SkipPatterns):
 			progress("nodegroup %s: skipped via --skip-nodegroup", ng.Name)
 			continue
 		case ng.CustomAMI:
-			progress("nodegroup %s: MANUAL — custom AMI; build and roll a %s-compatible AMI yourself", ng.Name, targetVersion)
+			progress("nodegroup %s: MANUAL — custom AMI; roll it with --custom-ami, or build and roll a %s-compatible AMI yourself", ng.Name, targetVersion)
 			continue
 		}
 
@@ -76,7 +94,7 @@ func (s *Service) UpgradeNodegroups(ctx context.Context, clusterName, targetVers
 			return fmt.Errorf("pre-flight gate failed for nodegroup %s (remaining nodegroups not attempted): %w", ng.Name, err)
 		}
 
-		if err := s.rollNodegroup(ctx, clusterName, ng.Name, targetVersion, opts.Force, opts.Observer, progress); err != nil {
+		if err := s.rollNodegroup(ctx, clusterName, ng.Name, targetVersion, opts, progress); err != nil {
 			return err
 		}
 	}
@@ -84,33 +102,72 @@ func (s *Service) UpgradeNodegroups(ctx context.Context, clusterName, targetVers
 }
 
 // rollNodegroup starts and watches a single nodegroup version roll.
-func (s *Service) rollNodegroup(ctx context.Context, clusterName, nodegroupName, targetVersion string, force bool, observer RollObserver, progress ProgressFunc) error {
+func (s *Service) rollNodegroup(ctx context.Context, clusterName, nodegroupName, targetVersion string, opts NodegroupRollOptions, progress ProgressFunc) error {
 	input := &eks.UpdateNodegroupVersionInput{
 		ClusterName:   aws.String(clusterName),
 		NodegroupName: aws.String(nodegroupName),
 		Version:       aws.String(targetVersion),
-		Force:         force,
+		Force:         opts.Force,
 		// Pin the idempotency token so WithRetry re-issues the SAME request
 		// instead of submitting a fresh update per attempt.
 		ClientRequestToken: aws.String(common.IdempotencyToken()),
 	}
+	target := targetVersion // for messages
+	if opts.ReleaseVersion != "" {
+		input.ReleaseVersion = aws.String(opts.ReleaseVersion)
+		target = fmt.Sprintf("%s (release %s)", targetVersion, opts.ReleaseVersion)
+	}
 	out, err := common.WithRetry(ctx, common.DefaultRetryConfig, func(rc context.Context) (*eks.UpdateNodegroupVersionOutput, error) {
 		return s.eksClient.UpdateNodegroupVersion(rc, input)
 	})
 	if err != nil {
-		return awsinternal.FormatAWSError(err, fmt.Sprintf("rolling nodegroup %s to %s", nodegroupName, targetVersion))
+		return awsinternal.FormatAWSError(err, fmt.Sprintf("rolling nodegroup %s to %s", nodegroupName, target))
 	}
 
 	updateID := ""
 	if out.Update != nil {
 		updateID = aws.ToString(out.Update.Id)
 	}
-	progress("nodegroup %s roll to %s started (update %s)", nodegroupName, targetVersion, updateID)
+	progress("nodegroup %s roll to %s started (update %s)", nodegroupName, target, updateID)
+	return s.watchRoll(ctx, clusterName, nodegroupName, updateID, target, opts, progress)
+}
+
+// upgradeCustomNodegroup rolls a custom-AMI nodegroup onto the roller's
+// image for targetVersion, behind the same gate as any other roll. A
+// nodegroup already on that image is skipped unless opts.Force.
+func (s *Service) upgradeCustomNodegroup(ctx context.Context, clusterName, targetVersion string, ng nodegroupState, gate NodegroupGate, opts NodegroupRollOptions, progress ProgressFunc) error {
+	image, err := s.CustomAMI.TargetImage(ctx, targetVersion)
+	if err != nil {
+		return fmt.Errorf("nodegroup %s: %w", ng.Name, err)
+	}
+	target := "custom AMI " + image
+	if !opts.Force {
+		if current, err := s.CustomAMI.CurrentImage(ctx, ng.Nodegroup); err == nil && current == image {
+			progress("nodegroup %s already on %s, skipping", ng.Name, target)
+			return nil
+		}
+	}
+	if err := gate(ctx, ng.Name); err != nil {
+		return fmt.Errorf("pre-flight gate failed for nodegroup %s (remaining nodegroups not attempted): %w", ng.Name, err)
+	}
+	rolled, err := s.CustomAMI.Roll(ctx, clusterName, ng.Nodegroup, image, opts.Force)
+	if err != nil {
+		return err
+	}
+	updateID := ""
+	if rolled.Update != nil {
+		updateID = aws.ToString(rolled.Update.Id)
+	}
+	progress("nodegroup %s roll to %s started (launch template %s version %s, update %s)", ng.Name, target, rolled.LaunchTemplate, rolled.Version, updateID)
+	return s.watchRoll(ctx, clusterName, ng.Name, updateID, target, opts, progress)
+}
 
+// watchRoll follows a started roll to completion.
+func (s *Service) watchRoll(ctx context.Context, clusterName, nodegroupName, updateID, target string, opts NodegroupRollOptions, progress ProgressFunc) error {
 	// Live per-node panel (view layer, best-effort) while the roll proceeds; the
 	// DescribeUpdate wait below stays authoritative for the result.
-	if observer != nil {
-		observer(ctx, nodegroupName)
+	if opts.Observer != nil {
+		opts.Observer(ctx, nodegroupName)
 	}
 
 	if updateID != "" {
@@ -118,11 +175,11 @@ func (s *Service) rollNodegroup(ctx context.Context, clusterName, nodegroupName,
 			Name:          aws.String(clusterName),
 			NodegroupName: aws.String(nodegroupName),
 			UpdateId:      aws.String(updateID),
-		}, fmt.Sprintf("nodegroup %s roll to %s", nodegroupName, targetVersion), progress); err != nil {
+		}, fmt.Sprintf("nodegroup %s roll to %s", nodegroupName, target), progress); err != nil {
 			return err
 		}
 	}
-	progress("nodegroup %s is at %s", nodegroupName, targetVersion)
+	progress("nodegroup %s is at %s", nodegroupName, target)
 	return nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 
+	"github.com/dantech2000/refresh/internal/customami"
 	"github.com/dantech2000/refresh/internal/mocks"
 )
 
@@ -218,6 +219,30 @@ func TestUpgradeNodegroups_ForcePassthrough(t *testing.T) {
 	}
 }
 
+// A pinned release passes through alongside the version.
+func TestUpgradeNodegroups_ReleaseVersionPassthrough(t *testing.T) {
+	m := mocks.NewEKSAPI().
+		WithCluster("prod-east", "1.32").
+		WithNodegroup("workers-a", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		WithDescribeUpdate(ekstypes.UpdateStatusSuccessful).
+		Build()
+	rolls := captureNodegroupRolls(m)
+
+	var lines []string
+	progress := func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+	svc := newTestService(m)
+	if err := svc.UpgradeNodegroups(context.Background(), "prod-east", "1.32",
+		NodegroupRollOptions{ReleaseVersion: "1.32.0-20260601"}, progress); err != nil {
+		t.Fatalf("UpgradeNodegroups: %v", err)
+	}
+	if len(*rolls) != 1 || aws.ToString((*rolls)[0].ReleaseVersion) != "1.32.0-20260601" || aws.ToString((*rolls)[0].Version) != "1.32" {
+		t.Fatalf("rolls = %+v, want version 1.32 at release 1.32.0-20260601", *rolls)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "workers-a is at 1.32 (release 1.32.0-20260601)") {
+		t.Errorf("progress = %q", lines)
+	}
+}
+
 // REF-126: the injected RollObserver fires once per rolled nodegroup, in order,
 // and not for skipped/already-current/custom-AMI ones.
 func TestUpgradeNodegroups_InvokesObserverPerRoll(t *testing.T) {
@@ -252,3 +277,89 @@ func TestUpgradeNodegroups_InvokesObserverPerRoll(t *testing.T) {
 func sprintf(format string, args ...any) string { return fmt.Sprintf(format, args...) }
 
 func sprintfErr(format string, args ...any) error { return fmt.Errorf(format, args...) }
+
+// fakeRoller is a CustomAMIRoller over in-memory launch templates.
+type fakeRoller struct {
+	mu       sync.Mutex
+	target   string
+	current  map[string]string // image by nodegroup
+	err      error
+	rolled   []string
+	versions []string
+}
+
+func (f *fakeRoller) TargetImage(_ context.Context, k8sVersion string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions = append(f.versions, k8sVersion)
+	return f.target, f.err
+}
+
+func (f *fakeRoller) CurrentImage(_ context.Context, ng *ekstypes.Nodegroup) (string, error) {
+	return f.current[aws.ToString(ng.NodegroupName)], nil
+}
+
+func (f *fakeRoller) Roll(_ context.Context, _ string, ng *ekstypes.Nodegroup, imageID string, _ bool) (customami.Rolled, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(ng.NodegroupName)
+	f.rolled = append(f.rolled, name+"="+imageID)
+	return customami.Rolled{
+		Update:         &ekstypes.Update{Id: aws.String("u-" + name), Status: ekstypes.UpdateStatusInProgress},
+		LaunchTemplate: "lt-1",
+		Version:        "2",
+	}, nil
+}
+
+func TestUpgradeNodegroups_CustomAMIRolledOnFinalHop(t *testing.T) {
+	m := mocks.NewEKSAPI().
+		WithCluster("prod-east", "1.32").
+		WithNodegroup("byo-ami", "1.31", ekstypes.AMITypesCustom).
+		WithNodegroup("byo-current", "1.31", ekstypes.AMITypesCustom).
+		WithNodegroup("workers-a", "1.31", ekstypes.AMITypesAl2023X8664Standard).
+		WithDescribeUpdate(ekstypes.UpdateStatusSuccessful).
+		Build()
+	rolls := captureNodegroupRolls(m)
+	roller := &fakeRoller{target: "ami-golden", current: map[string]string{"byo-ami": "ami-old", "byo-current": "ami-golden"}}
+
+	var gated, lines []string
+	gate := func(_ context.Context, ng string) error {
+		gated = append(gated, ng)
+		return nil
+	}
+	progress := func(format string, args ...any) { lines = append(lines, sprintf(format, args...)) }
+
+	svc := newTestService(m)
+	svc.CustomAMI = roller
+
+	// An intermediate hop leaves custom-AMI nodegroups alone.
+	if err := svc.UpgradeNodegroups(context.Background(), "prod-east", "1.32", NodegroupRollOptions{Gate: gate}, progress); err != nil {
+		t.Fatalf("UpgradeNodegroups: %v", err)
+	}
+	if len(roller.rolled) != 0 {
+		t.Fatalf("intermediate hop rolled %v", roller.rolled)
+	}
+
+	*rolls, gated = nil, nil
+	if err := svc.UpgradeNodegroups(context.Background(), "prod-east", "1.32", NodegroupRollOptions{Gate: gate, CustomAMI: true}, progress); err != nil {
+		t.Fatalf("UpgradeNodegroups: %v", err)
+	}
+	if strings.Join(roller.rolled, ",") != "byo-ami=ami-golden" {
+		t.Errorf("custom rolls = %v, want only byo-ami onto ami-golden", roller.rolled)
+	}
+	if strings.Join(gated, ",") != "byo-ami,workers-a" {
+		t.Errorf("gated = %v, want the custom roll gated like any other", gated)
+	}
+	for _, r := range *rolls {
+		if ng := aws.ToString(r.NodegroupName); ng != "workers-a" {
+			t.Errorf("UpdateNodegroupVersion called directly for %s", ng)
+		}
+	}
+	if roller.versions[0] != "1.32" {
+		t.Errorf("target resolved for %v, want the hop version", roller.versions)
+	}
+	joined := strings.Join(lines, "\n")
+	if !strings.Contains(joined, "byo-current already on custom AMI ami-golden") {
+		t.Errorf("progress should report byo-current as current:\n%s", joined)
+	}
+}
@@ -21,6 +21,9 @@ type PlanOptions struct {
 	SkipAddons []string
 	// SkipNodegroups are substring patterns for nodegroups to leave alone.
 	SkipNodegroups []string
+	// NodegroupReleaseVersion is the AMI release the final hop's nodegroup
+	// rolls are pinned to; it only changes the step descriptions.
+	NodegroupReleaseVersion string
 }
 
 // BuildPlan derives the full ordered upgrade plan for clusterName to reach
@@ -82,14 +85,22 @@ func (s *Service) BuildPlan(ctx context.Context, clusterName, targetVersion stri
 		hop.Steps = append(hop.Steps, s.readinessStep(ctx, clusterName, hopTo, nodegroups, simNodegroups, plan))
 		hop.Steps = append(hop.Steps, controlPlaneStep(currentVersion, aws.ToString(cluster.Version), hopTo, cluster.Status))
 		hop.Steps = append(hop.Steps, s.addonSteps(ctx, addonsSvc, addonList, hopTo, opts.SkipAddons)...)
-		hop.Steps = append(hop.Steps, nodegroupSteps(nodegroups, hopTo, opts.SkipNodegroups)...)
+		release := ""
+		if hopTo == targetVersion {
+			release = opts.NodegroupReleaseVersion
+		}
+		ngSteps := nodegroupSteps(nodegroups, hopTo, release, opts.SkipNodegroups)
+		if s.CustomAMI != nil {
+			ngSteps = s.withCustomAMISteps(ctx, ngSteps, nodegroups, hopTo, hopTo == targetVersion, opts.SkipNodegroups)
+		}
+		hop.Steps = append(hop.Steps, ngSteps...)
 
 		plan.Hops = append(plan.Hops, hop)
 
 		// Advance the simulation: after this hop, rollable nodegroups sit at
 		// the hop target.
 		for _, ng := range nodegroups {
-			if !ng.CustomAMI && !matchesAny(ng.Name, opts.SkipNodegroups) && !versionAtLeast(simNodegroups[ng.Name], hopTo) {
+			if !ng.CustomAMI && !MatchesAny(ng.Name, opts.SkipNodegroups) && !versionAtLeast(simNodegroups[ng.Name], hopTo) {
 				simNodegroups[ng.Name] = hopTo
 			}
 		}
@@ -258,7 +269,7 @@ func (s *Service) addonSteps(ctx context.Context, svc *addons.ServiceImpl, addon
 			Description: fmt.Sprintf("addon %s → latest compatible with %s", a.Name, hopTo),
 			Status:      StatusPending,
 		}
-		if matchesAny(a.Name, skip) {
+		if MatchesAny(a.Name, skip) {
 			step.Status = StatusManual
 			step.Reason = "skipped via --skip (managed out-of-band)"
 			steps = append(steps, step)
@@ -285,8 +296,9 @@ func (s *Service) addonSteps(ctx context.Context, svc *addons.ServiceImpl, addon
 
 // nodegroupSteps derives one step per nodegroup for the hop. Custom-AMI
 // nodegroups surface as manual actions (the operator owns their AMI
-// lifecycle); skipped patterns likewise are never mutated.
-func nodegroupSteps(nodegroups []nodegroupState, hopTo string, skipPatterns []string) []Step {
+// lifecycle); skipped patterns likewise are never mutated. A non-empty release
+// is the AMI release the hop's rolls are pinned to.
+func nodegroupSteps(nodegroups []nodegroupState, hopTo, release string, skipPatterns []string) []Step {
 	steps := make([]Step, 0, len(nodegroups))
 	for _, ng := range nodegroups {
 		step := Step{
@@ -296,18 +308,64 @@ func nodegroupSteps(nodegroups []nodegroupState, hopTo string, skipPatterns []st
 			Version:     hopTo,
 			Status:      StatusPending,
 		}
+		if release != "" {
+			step.Description = fmt.Sprintf("nodegroup %s → %s (release %s)", ng.Name, hopTo, release)
+		}
 		switch {
 		case versionAtLeast(ng.Version, hopTo):
 			step.Status = StatusCompleted
 			step.Reason = fmt.Sprintf("already at %s", ng.Version)
-		case matchesAny(ng.Name, skipPatterns):
+		case MatchesAny(ng.Name, skipPatterns):
 			step.Status = StatusManual
 			step.Reason = "skipped via --skip-nodegroup"
 		case ng.CustomAMI:
 			step.Status = StatusManual
-			step.Reason = fmt.Sprintf("custom AMI nodegroup: build and roll a %s-compatible AMI yourself", hopTo)
+			step.Reason = fmt.Sprintf("custom AMI nodegroup: roll it with --custom-ami, or build and roll a %s-compatible AMI yourself", hopTo)
 		}
 		steps = append(steps, step)
 	}
 	return steps
 }
+
+// withCustomAMISteps replaces a hop's manual custom-AMI steps when a
+// CustomAMIRoller is set. The final hop rolls each nodegroup onto the
+// roller's image for the target version, completed when its launch template
+// already runs it; earlier hops leave them out, since the image is built for
+// the target rather than per hop. A nodegroup whose image can't be resolved
+// blocks the plan.
+func (s *Service) withCustomAMISteps(ctx context.Context, steps []Step, nodegroups []nodegroupState, hopTo string, final bool, skipPatterns []string) []Step {
+	byName := make(map[string]nodegroupState, len(nodegroups))
+	for _, ng := range nodegroups {
+		byName[ng.Name] = ng
+	}
+	out := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		ng := byName[step.Target]
+		if !ng.CustomAMI || MatchesAny(ng.Name, skipPatterns) {
+			out = append(out, step)
+			continue
+		}
+		if !final {
+			continue
+		}
+		step.Status, step.Reason = StatusPending, ""
+		target, err := s.CustomAMI.TargetImage(ctx, hopTo)
+		if err != nil {
+			step.Status, step.Reason = StatusBlocked, err.Error()
+			out = append(out, step)
+			continue
+		}
+		step.Description = fmt.Sprintf("nodegroup %s → custom AMI %s (%s)", ng.Name, target, hopTo)
+		current, err := s.CustomAMI.CurrentImage(ctx, ng.Nodegroup)
+		switch {
+		case err != nil:
+			step.Status, step.Reason = StatusBlocked, err.Error()
+		case current == target:
+			step.Status, step.Reason = StatusCompleted, fmt.Sprintf("already on %s", target)
+		default:
+			step.Reason = fmt.Sprintf("new launch template version replacing %s", current)
+		}
+		out = append(out, step)
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package upgrade
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"strings"
 	"testing"
@@ -305,3 +306,40 @@ func findStep(t *testing.T, steps []Step, typ StepType, target string) Step {
 	t.Fatalf("no %s step for %q in %+v", typ, target, steps)
 	return Step{}
 }
+
+// With a CustomAMIRoller, a custom-AMI nodegroup rolls once, on the final hop,
+// and an image that can't be resolved blocks the plan.
+func TestBuildPlan_CustomAMIOnFinalHopOnly(t *testing.T) {
+	m := mocks.NewEKSAPI().
+		WithCluster("prod-east", "1.31").
+		WithNodegroup("byo-ami", "1.31", ekstypes.AMITypesCustom).
+		Build()
+	svc := newTestService(m)
+	svc.CustomAMI = &fakeRoller{target: "ami-golden", current: map[string]string{"byo-ami": "ami-old"}}
+
+	plan, err := svc.BuildPlan(context.Background(), "prod-east", "1.33", PlanOptions{})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	var custom []Step
+	for _, hop := range plan.Hops {
+		for _, st := range hop.Steps {
+			if st.Type == StepNodegroup {
+				custom = append(custom, st)
+			}
+		}
+	}
+	if len(custom) != 1 || custom[0].Version != "1.33" || custom[0].Status != StatusPending ||
+		!strings.Contains(custom[0].Description, "custom AMI ami-golden") {
+		t.Fatalf("custom steps = %+v, want one pending roll on the 1.33 hop", custom)
+	}
+
+	svc.CustomAMI = &fakeRoller{err: errors.New("no available image named golden-1.33-*")}
+	plan, err = svc.BuildPlan(context.Background(), "prod-east", "1.32", PlanOptions{})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	if !plan.Blocked() || !strings.Contains(strings.Join(plan.Blockers(), "\n"), "golden-1.33-*") {
+		t.Errorf("blockers = %v, want the resolve error", plan.Blockers())
+	}
+}
@@ -0,0 +1,69 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dantech2000/refresh/internal/services/addons"
+)
+
+// AddonTarget is one addon and the exact version it should run.
+type AddonTarget struct {
+	Name    string
+	Version string
+}
+
+// SetAddonVersions moves each addon onto its given version, serially in
+// dependency order, through the same attach/health-gate/wait path as the
+// upgrade's addon phase. It serves declarative reconciles, where the version
+// comes from a policy (latest, default or a pin) rather than "latest
+// compatible with the hop".
+func (s *Service) SetAddonVersions(ctx context.Context, clusterName string, targets []AddonTarget, progress ProgressFunc) error {
+	progress = ensureProgress(progress)
+	svc := s.addonsService()
+
+	// SortByDependency orders summaries; the versions ride along by name.
+	summaries := make([]addons.AddonSummary, 0, len(targets))
+	versions := make(map[string]string, len(targets))
+	for _, t := range targets {
+		summaries = append(summaries, addons.AddonSummary{Name: t.Name})
+		versions[t.Name] = t.Version
+	}
+	for _, a := range addons.SortByDependency(summaries) {
+		if err := s.updateAddon(ctx, svc, clusterName, a.Name, versions[a.Name], "desired version", progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NodegroupTarget is one nodegroup AMI refresh: the Kubernetes version it
+// stays on and the AMI release to land (empty for the latest).
+type NodegroupTarget struct {
+	Name           string
+	Version        string
+	ReleaseVersion string
+}
+
+// RefreshNodegroups rolls each nodegroup onto a new AMI release within its
+// Kubernetes version, serially, behind the same pre-flight gate and watch as
+// the upgrade's nodegroup phase. A gate failure halts the remaining
+// nodegroups. opts.ReleaseVersion is ignored; each target carries its own.
+func (s *Service) RefreshNodegroups(ctx context.Context, clusterName string, targets []NodegroupTarget, opts NodegroupRollOptions, progress ProgressFunc) error {
+	progress = ensureProgress(progress)
+	gate := opts.Gate
+	if gate == nil {
+		gate = s.defaultNodegroupGate(clusterName)
+	}
+	for _, t := range targets {
+		if err := gate(ctx, t.Name); err != nil {
+			return fmt.Errorf("pre-flight gate failed for nodegroup %s (remaining nodegroups not attempted): %w", t.Name, err)
+		}
+		rollOpts := opts
+		rollOpts.ReleaseVersion = t.ReleaseVersion
+		if err := s.rollNodegroup(ctx, clusterName, t.Name, t.Version, rollOpts, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 
 	awsinternal "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/customami"
 	"github.com/dantech2000/refresh/internal/services/addons"
 	"github.com/dantech2000/refresh/internal/services/common"
 )
@@ -36,6 +37,15 @@ type EKSAPI interface {
 	UpdateNodegroupVersion(ctx context.Context, params *eks.UpdateNodegroupVersionInput, optFns ...func(*eks.Options)) (*eks.UpdateNodegroupVersionOutput, error)
 }
 
+// CustomAMIRoller rolls custom-AMI nodegroups (AmiType=CUSTOM) onto an image
+// the operator chose, through a new launch template version.
+// customami.Roller implements it.
+type CustomAMIRoller interface {
+	TargetImage(ctx context.Context, k8sVersion string) (string, error)
+	CurrentImage(ctx context.Context, ng *ekstypes.Nodegroup) (string, error)
+	Roll(ctx context.Context, clusterName string, ng *ekstypes.Nodegroup, imageID string, force bool) (customami.Rolled, error)
+}
+
 // ProgressFunc receives human-readable progress lines during execution.
 type ProgressFunc func(format string, args ...any)
 
@@ -47,6 +57,10 @@ type Service struct {
 	// PollInterval is how often in-flight updates are re-checked.
 	// Tests shrink it; defaults to defaultPollInterval.
 	PollInterval time.Duration
+
+	// CustomAMI, when set, rolls custom-AMI nodegroups on the final hop
+	// instead of leaving them to the operator.
+	CustomAMI CustomAMIRoller
 }
 
 // NewService creates the upgrade orchestrator service.
@@ -86,6 +100,8 @@ type nodegroupState struct {
 	AmiType   ekstypes.AMITypes
 	Status    ekstypes.NodegroupStatus
 	CustomAMI bool
+	// Nodegroup is the described nodegroup, for custom-AMI launch templates.
+	Nodegroup *ekstypes.Nodegroup
 }
 
 // listNodegroupStates describes every nodegroup in the cluster.
@@ -124,13 +140,15 @@ func (s *Service) listNodegroupStates(ctx context.Context, clusterName string) (
 			AmiType:   ng.AmiType,
 			Status:    ng.Status,
 			CustomAMI: ng.AmiType == ekstypes.AMITypesCustom,
+			Nodegroup: ng,
 		})
 	}
 	return states, nil
 }
 
-// matchesAny reports whether name matches any of the substring patterns.
-func matchesAny(name string, patterns []string) bool {
+// MatchesAny reports whether name matches any of the substring patterns, the
+// --skip semantics shared by upgrade and the desired-state planner.
+func MatchesAny(name string, patterns []string) bool {
 	for _, p := range patterns {
 		if p != "" && strings.Contains(name, p) {
 			return true
@@ -152,3 +152,17 @@ func versionAtLeast(a, b string) bool {
 	}
 	return am >= bm
 }
+
+// CompareMinor compares two Kubernetes versions by minor, returning -1, 0 or
+// 1. Versions that don't parse compare equal.
+func CompareMinor(a, b string) int {
+	am, err1 := minorVersion(a)
+	bm, err2 := minorVersion(b)
+	switch {
+	case err1 != nil || err2 != nil || am == bm:
+		return 0
+	case am < bm:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,317 @@
+// Package tui is `refresh ui`: a keyboard-driven, full-screen browser of the
+// fleet — status → cluster → nodegroups/addons/insights — that can start a
+// dry-run or health check from a selected row and attach to a roll in
+// progress.
+//
+// Every screen renders to a pure []string frame through the shared render
+// theme, reusing the status table and the live roll panel, so it looks like
+// the rest of the CLI and degrades the same way under --no-color. Only Run
+// touches the terminal; the App model is driven by keys and ticks and is
+// tested with a fake Source.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+	addonsvc "github.com/dantech2000/refresh/internal/services/addons"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	nodegroupsvc "github.com/dantech2000/refresh/internal/services/nodegroup"
+	statussvc "github.com/dantech2000/refresh/internal/services/status"
+)
+
+// Cluster identifies a cluster in the fleet.
+type Cluster struct {
+	Name    string
+	Region  string
+	Account string // account label in multi-account sweeps; empty otherwise
+}
+
+// String renders the cluster for breadcrumbs and messages.
+func (c Cluster) String() string {
+	if c.Account != "" {
+		return c.Account + "/" + c.Name
+	}
+	return c.Name
+}
+
+// Source is where the UI reads the fleet from. Each call is made when its
+// screen is opened or reloaded, never on a timer, except RollObserver's
+// observer which the roll screen polls.
+type Source interface {
+	Fleet(ctx context.Context) ([]statussvc.ClusterStatus, []error)
+	Nodegroups(ctx context.Context, c Cluster) ([]nodegroupsvc.NodegroupSummary, error)
+	Addons(ctx context.Context, c Cluster) ([]addonsvc.AddonSummary, error)
+	Insights(ctx context.Context, c Cluster) ([]clustersvc.InsightSummary, error)
+	// RollObserver observes nodegroup's nodes through the Kubernetes API;
+	// where describes the kubeconfig context it reads.
+	RollObserver(ctx context.Context, c Cluster, nodegroup string) (obs noderoll.Observer, where string, err error)
+}
+
+// ActionKind is a command the UI can run against a selected row.
+type ActionKind int
+
+const (
+	ActionDryRun ActionKind = iota // nodegroup update --dry-run
+	ActionHealth                   // nodegroup update --health-only
+)
+
+// Action is a refresh command to run outside the UI, against a cluster or one
+// of its nodegroups.
+type Action struct {
+	Kind      ActionKind
+	Cluster   Cluster
+	Nodegroup string // empty: every nodegroup in the cluster
+}
+
+// Args returns the refresh arguments that run the action (without the
+// region/profile the caller adds for the cluster).
+func (a Action) Args() []string {
+	args := []string{"nodegroup", "update", a.Cluster.Name}
+	if a.Nodegroup != "" {
+		args = append(args, a.Nodegroup)
+	}
+	if a.Kind == ActionHealth {
+		return append(args, "--health-only")
+	}
+	return append(args, "--dry-run")
+}
+
+// Label names the action for the status line ("dry-run of prod/workers").
+func (a Action) Label() string {
+	kind := "dry-run"
+	if a.Kind == ActionHealth {
+		kind = "health check"
+	}
+	target := a.Cluster.String()
+	if a.Nodegroup != "" {
+		target += "/" + a.Nodegroup
+	}
+	return kind + " of " + target
+}
+
+// view is one screen on the App's stack.
+type view interface {
+	title() string // breadcrumb segment
+	stale() bool   // needs load before it has anything to show
+	load(ctx context.Context, a *App)
+	reload() // marks the data stale (the r key)
+	body(a *App, height int) []string
+	help() string
+	handle(ctx context.Context, a *App, k Key)
+}
+
+// ticker is a view that refreshes on its own (the roll screen).
+type ticker interface {
+	tick(ctx context.Context, a *App)
+}
+
+// App is the UI model: a stack of screens, a one-line status message and the
+// action waiting to run. It is not safe for concurrent use; Run drives it
+// from one goroutine.
+type App struct {
+	src     Source
+	th      *render.Theme
+	scope   string
+	stack   []view
+	flash   string
+	pending *Action
+	quit    bool
+	now     func() time.Time
+}
+
+// New returns an App opened on the fleet screen. scope describes what the
+// fleet covers ("3 regions", "us-east-1") for its header.
+func New(src Source, th *render.Theme, scope string) *App {
+	return &App{src: src, th: th, scope: scope, stack: []view{&fleetView{}}, now: time.Now}
+}
+
+// Done reports whether the user quit.
+func (a *App) Done() bool { return a.quit }
+
+// TakeAction returns the action the user asked to run, if any, clearing it.
+func (a *App) TakeAction() (Action, bool) {
+	if a.pending == nil {
+		return Action{}, false
+	}
+	act := *a.pending
+	a.pending = nil
+	return act, true
+}
+
+// Settle loads the current screen if it has nothing to show yet, reporting
+// whether it did. Run draws a loading frame first, then settles.
+func (a *App) Settle(ctx context.Context) bool {
+	v := a.top()
+	if !v.stale() {
+		return false
+	}
+	v.load(ctx, a)
+	return true
+}
+
+// Tick lets a self-refreshing screen poll.
+func (a *App) Tick(ctx context.Context) {
+	if t, ok := a.top().(ticker); ok {
+		t.tick(ctx, a)
+	}
+}
+
+// Handle applies one key press: the global keys first, then the screen's.
+func (a *App) Handle(ctx context.Context, k Key) {
+	a.flash = ""
+	switch {
+	case k.Code == KeyCtrlC || (k.Code == KeyRune && k.Rune == 'q'):
+		a.quit = true
+	case k.Code == KeyEsc || k.Code == KeyBackspace:
+		if len(a.stack) > 1 {
+			a.stack = a.stack[:len(a.stack)-1]
+		}
+	case k.Code == KeyRune && k.Rune == 'r':
+		a.top().reload()
+	default:
+		a.top().handle(ctx, a, k)
+	}
+}
+
+// Frame renders the whole screen for a terminal height rows tall: the
+// breadcrumb, the current screen's body, then the status and key-help lines.
+// Lines may be wider than the terminal; render.Screen clips them.
+func (a *App) Frame(height int) []string {
+	pal := a.th.Pal
+	crumbs := make([]string, len(a.stack))
+	for i, v := range a.stack {
+		crumbs[i] = v.title()
+		if i == len(a.stack)-1 {
+			crumbs[i] = a.th.Bold(pal.White, crumbs[i])
+		}
+	}
+	sep := a.th.Paint(pal.Dim, " › ")
+	if !a.th.Unicode {
+		sep = a.th.Paint(pal.Dim, " > ")
+	}
+	out := []string{a.th.Bold(pal.Mauve, "refresh ui") + a.th.Paint(pal.Dim, "  ") + strings.Join(crumbs, sep), ""}
+
+	bodyHeight := height - len(out) - 2
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+	body := a.top().body(a, bodyHeight)
+	if len(body) > bodyHeight {
+		body = body[:bodyHeight]
+	}
+	out = append(out, body...)
+	for i := len(body); i < bodyHeight; i++ {
+		out = append(out, "")
+	}
+
+	keys := a.top().help()
+	if len(a.stack) > 1 {
+		keys += " · esc back"
+	}
+	return append(out, a.flash, a.th.Paint(pal.Dim, keys+" · r reload · q quit"))
+}
+
+func (a *App) top() view { return a.stack[len(a.stack)-1] }
+
+func (a *App) push(v view) { a.stack = append(a.stack, v) }
+
+// run queues act for Run to execute outside the UI.
+func (a *App) run(act Action) { a.pending = &act }
+
+// notify sets the status line to an informational message.
+func (a *App) notify(msg string) { a.flash = a.th.Paint(a.th.Pal.Subtext, msg) }
+
+// fail sets the status line to an error.
+func (a *App) fail(err error) {
+	a.flash = a.th.Token(render.Fail, "error") + " " + a.th.Paint(a.th.Pal.Text, oneLine(err.Error()))
+}
+
+// loading renders the placeholder a screen shows until its data arrives.
+func (a *App) loading(what string) []string {
+	return []string{a.th.Paint(a.th.Pal.Dim, fmt.Sprintf("Loading %s…", what))}
+}
+
+// oneLine flattens a multi-line message for the status line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// list is a scrolling cursor over a table's rows.
+type list struct {
+	cursor, top int
+}
+
+// move applies a navigation key over n rows, page rows per page, reporting
+// whether the key was a navigation key.
+func (l *list) move(k Key, n, page int) bool {
+	switch {
+	case k.Code == KeyUp || (k.Code == KeyRune && k.Rune == 'k'):
+		l.cursor--
+	case k.Code == KeyDown || (k.Code == KeyRune && k.Rune == 'j'):
+		l.cursor++
+	case k.Code == KeyPgUp:
+		l.cursor -= page
+	case k.Code == KeyPgDn:
+		l.cursor += page
+	case k.Code == KeyHome || (k.Code == KeyRune && k.Rune == 'g'):
+		l.cursor = 0
+	case k.Code == KeyEnd || (k.Code == KeyRune && k.Rune == 'G'):
+		l.cursor = n - 1
+	default:
+		return false
+	}
+	l.clamp(n)
+	return true
+}
+
+func (l *list) clamp(n int) {
+	if l.cursor >= n {
+		l.cursor = n - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+}
+
+// render lays table (header + one line per row) into height lines, with a
+// selection gutter on the cursor row and the window scrolled to keep it
+// visible. The header stays put.
+func (l *list) render(th *render.Theme, table []string, height int) []string {
+	if len(table) == 0 {
+		return nil
+	}
+	rows := table[1:]
+	l.clamp(len(rows))
+	visible := height - 1
+	if visible < 1 {
+		visible = 1
+	}
+	if l.cursor < l.top {
+		l.top = l.cursor
+	}
+	if l.cursor >= l.top+visible {
+		l.top = l.cursor - visible + 1
+	}
+	if maxTop := len(rows) - visible; l.top > maxTop {
+		l.top = max(maxTop, 0)
+	}
+
+	mark := ">"
+	if th.Unicode {
+		mark = "▸"
+	}
+	out := []string{"  " + table[0]}
+	for i := l.top; i < len(rows) && i < l.top+visible; i++ {
+		gutter := "  "
+		if i == l.cursor {
+			gutter = th.Bold(th.Pal.Mauve, mark) + " "
+		}
+		out = append(out, gutter+rows[i])
+	}
+	return out
+}
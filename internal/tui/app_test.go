@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/noderoll"
+	"github.com/dantech2000/refresh/internal/render"
+	addonsvc "github.com/dantech2000/refresh/internal/services/addons"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	nodegroupsvc "github.com/dantech2000/refresh/internal/services/nodegroup"
+	statussvc "github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/types"
+)
+
+type fakeSource struct {
+	calls      []string
+	nodegroups []nodegroupsvc.NodegroupSummary
+	addonsErr  error
+}
+
+func (f *fakeSource) Fleet(context.Context) ([]statussvc.ClusterStatus, []error) {
+	f.calls = append(f.calls, "fleet")
+	return []statussvc.ClusterStatus{
+		{Name: "staging", Region: "us-west-2", Version: "1.31", NodegroupCount: 1},
+		{Name: "prod", Region: "us-east-1", Version: "1.32", NodegroupCount: 2},
+	}, []error{errors.New("region eu-west-1: AccessDenied")}
+}
+
+func (f *fakeSource) Nodegroups(_ context.Context, c Cluster) ([]nodegroupsvc.NodegroupSummary, error) {
+	f.calls = append(f.calls, "nodegroups "+c.Name)
+	return f.nodegroups, nil
+}
+
+func (f *fakeSource) Addons(_ context.Context, c Cluster) ([]addonsvc.AddonSummary, error) {
+	f.calls = append(f.calls, "addons "+c.Name)
+	return nil, f.addonsErr
+}
+
+func (f *fakeSource) Insights(_ context.Context, c Cluster) ([]clustersvc.InsightSummary, error) {
+	f.calls = append(f.calls, "insights "+c.Name)
+	return []clustersvc.InsightSummary{{Name: "Deprecated APIs", Category: "UPGRADE_READINESS", Status: "WARNING"}}, nil
+}
+
+func (f *fakeSource) RollObserver(_ context.Context, c Cluster, ng string) (noderoll.Observer, string, error) {
+	f.calls = append(f.calls, "roll "+c.Name+"/"+ng)
+	return noderoll.NewScriptedObserver(noderoll.DemoTimeline()), `kubeconfig ~/.kube/config (context "prod")`, nil
+}
+
+func newTestApp(src Source) *App {
+	return New(src, render.New(render.ColorNone, true), "2 regions")
+}
+
+func press(a *App, keys ...Key) {
+	for _, k := range keys {
+		a.Handle(context.Background(), k)
+		a.Settle(context.Background())
+	}
+}
+
+func r(c rune) Key { return Key{Code: KeyRune, Rune: c} }
+
+func TestDecodeKeys(t *testing.T) {
+	got := decodeKeys([]byte("j\x1b[A\x1b[B\x1bOC\r\t\x1b[5~\x1b[Z\x1b[2~\x7fé\x03\x1b"))
+	want := []Key{
+		r('j'), {Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyEnter}, {Code: KeyTab},
+		{Code: KeyPgUp}, {Code: KeyBackTab}, {Code: KeyBackspace}, r('é'), {Code: KeyCtrlC}, {Code: KeyEsc},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeKeys =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestFleetScreen(t *testing.T) {
+	src := &fakeSource{}
+	a := newTestApp(src)
+	if frame := strings.Join(a.Frame(20), "\n"); !strings.Contains(frame, "Loading fleet status") {
+		t.Fatalf("first frame should be a loading placeholder:\n%s", frame)
+	}
+	a.Settle(context.Background())
+
+	frame := a.Frame(20)
+	if len(frame) != 20 {
+		t.Fatalf("frame is %d lines, want the full height", len(frame))
+	}
+	text := strings.Join(frame, "\n")
+	for _, want := range []string{"refresh ui  fleet", "FLEET  2 regions · 2 cluster(s)", "▸ ●  prod", "1 region(s) failed", "enter open"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("fleet frame missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "prod") > strings.Index(text, "staging") {
+		t.Error("clusters should be sorted by name")
+	}
+
+	press(a, Key{Code: KeyDown})
+	if !strings.Contains(strings.Join(a.Frame(20), "\n"), "▸ ●  staging") {
+		t.Error("down should move the cursor to staging")
+	}
+	press(a, r('d'))
+	act, ok := a.TakeAction()
+	if !ok || act.Label() != "dry-run of staging" ||
+		strings.Join(act.Args(), " ") != "nodegroup update staging --dry-run" {
+		t.Errorf("action = %+v (%v)", act, ok)
+	}
+	if _, again := a.TakeAction(); again {
+		t.Error("TakeAction should clear the action")
+	}
+}
+
+func TestClusterScreenTabsAndAttach(t *testing.T) {
+	src := &fakeSource{
+		nodegroups: []nodegroupsvc.NodegroupSummary{
+			{Name: "workers", Status: "ACTIVE", InstanceType: "m6i.large", DesiredSize: 3, AMIStatus: types.AMIOutdated},
+			{Name: "batch", Status: "UPDATING", InstanceType: "c6i.xlarge", DesiredSize: 4, AMIStatus: types.AMIUpdating},
+		},
+		addonsErr: errors.New("AccessDenied: eks:ListAddons"),
+	}
+	a := newTestApp(src)
+	a.Settle(context.Background())
+	press(a, Key{Code: KeyEnter})
+
+	text := strings.Join(a.Frame(24), "\n")
+	for _, want := range []string{"fleet › prod", "CLUSTER  prod · 1.32 · us-east-1", "[1 nodegroups]", "▸ workers", "▲ Outdated", "esc back"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("cluster frame missing %q:\n%s", want, text)
+		}
+	}
+
+	// Addons load lazily and surface their error in place; insights too.
+	press(a, Key{Code: KeyTab})
+	if text := strings.Join(a.Frame(24), "\n"); !strings.Contains(text, "AccessDenied: eks:ListAddons") {
+		t.Errorf("addons tab should show its error:\n%s", text)
+	}
+	press(a, r('3'))
+	if text := strings.Join(a.Frame(24), "\n"); !strings.Contains(text, "Deprecated APIs") {
+		t.Errorf("insights tab:\n%s", text)
+	}
+	press(a, Key{Code: KeyTab}) // wraps back to nodegroups, already loaded
+	want := []string{"fleet", "nodegroups prod", "addons prod", "insights prod"}
+	if !reflect.DeepEqual(src.calls, want) {
+		t.Errorf("calls = %v, want %v", src.calls, want)
+	}
+
+	// A nodegroup that isn't rolling has nothing to attach to.
+	press(a, Key{Code: KeyEnter})
+	if len(a.stack) != 2 || !strings.Contains(a.flash, "workers is ACTIVE, not rolling") {
+		t.Errorf("attach to an idle nodegroup: stack %d, flash %q", len(a.stack), a.flash)
+	}
+	press(a, r('h'))
+	if act, _ := a.TakeAction(); strings.Join(act.Args(), " ") != "nodegroup update prod workers --health-only" {
+		t.Errorf("health action args = %v", act.Args())
+	}
+
+	press(a, Key{Code: KeyDown}, r('a'))
+	text = strings.Join(a.Frame(30), "\n")
+	for _, want := range []string{"prod › batch roll", `via kubeconfig ~/.kube/config (context "prod")`, "target AMI"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("roll frame missing %q:\n%s", want, text)
+		}
+	}
+
+	// The roll screen polls on ticks, no faster than rollPoll.
+	v := a.top().(*rollView)
+	next := v.next
+	a.Tick(context.Background())
+	if v.next != next {
+		t.Error("a tick inside the poll interval should not poll")
+	}
+	a.now = func() time.Time { return next }
+	a.Tick(context.Background())
+	if !v.next.After(next) {
+		t.Error("a tick past the poll interval should poll")
+	}
+
+	press(a, Key{Code: KeyEsc}, Key{Code: KeyEsc}, Key{Code: KeyEsc})
+	if len(a.stack) != 1 || a.Done() {
+		t.Errorf("esc should pop back to the fleet and stop there (stack %d)", len(a.stack))
+	}
+	press(a, r('q'))
+	if !a.Done() {
+		t.Error("q should quit")
+	}
+}
+
+func TestListScrollsToCursor(t *testing.T) {
+	th := render.New(render.ColorNone, false)
+	table := []string{"NAME"}
+	for _, n := range []string{"a", "b", "c", "d", "e", "f"} {
+		table = append(table, n)
+	}
+	var l list
+	l.move(Key{Code: KeyEnd}, 6, 10)
+	got := l.render(th, table, 4)
+	want := []string{"  NAME", "  d", "  e", "> f"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+	l.move(r('g'), 6, 10)
+	if got := l.render(th, table, 4); got[1] != "> a" {
+		t.Errorf("home should scroll back to the top: %q", got)
+	}
+}
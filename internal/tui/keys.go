@@ -0,0 +1,93 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode is a decoded key press; KeyRune carries a printable character.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEsc
+	KeyTab
+	KeyBackTab
+	KeyBackspace
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyCtrlC
+)
+
+// Key is one key press read from a raw-mode terminal.
+type Key struct {
+	Code KeyCode
+	Rune rune // set when Code is KeyRune
+}
+
+// escapeKeys maps the CSI/SS3 sequences terminals send (after the ESC) onto
+// keys: both the normal and the application-cursor forms.
+var escapeKeys = map[string]KeyCode{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[5~": KeyPgUp, "[6~": KeyPgDn, "[Z": KeyBackTab,
+}
+
+// decodeKeys splits one read from the terminal into key presses. A lone ESC
+// is Esc; escape sequences and control bytes it doesn't bind are dropped.
+func decodeKeys(b []byte) []Key {
+	var out []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			code, n, ok := decodeEscape(b[1:])
+			if ok {
+				out = append(out, Key{Code: code})
+			}
+			b = b[1+n:]
+			continue
+		case c == '\r' || c == '\n':
+			out = append(out, Key{Code: KeyEnter})
+		case c == '\t':
+			out = append(out, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			out = append(out, Key{Code: KeyBackspace})
+		case c == 0x03:
+			out = append(out, Key{Code: KeyCtrlC})
+		case c < 0x20:
+			// other control bytes: ignored
+		default:
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				out = append(out, Key{Code: KeyRune, Rune: r})
+			}
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return out
+}
+
+// decodeEscape matches the bytes after an ESC against escapeKeys, returning
+// the key and how many bytes it consumed (0 for a bare Esc). ok is false for
+// a complete sequence that isn't bound, which is swallowed.
+func decodeEscape(b []byte) (code KeyCode, n int, ok bool) {
+	if len(b) < 2 || (b[0] != '[' && b[0] != 'O') {
+		return KeyEsc, 0, true
+	}
+	// A CSI sequence ends at its first byte in 0x40–0x7e.
+	for i := 1; i < len(b) && i < 8; i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			code, ok = escapeKeys[string(b[:i+1])]
+			return code, i + 1, ok
+		}
+	}
+	return KeyEsc, 0, true
+}
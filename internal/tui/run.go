@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/dantech2000/refresh/internal/render"
+)
+
+// tickEvery paces self-refreshing screens and picks up terminal resizes.
+const tickEvery = time.Second
+
+// ErrNotTerminal is returned by Run when stdin or stdout isn't a terminal.
+var ErrNotTerminal = errors.New("refresh ui needs an interactive terminal (stdin and stdout must be a TTY)")
+
+// Exec runs an action outside the UI, with the terminal restored to normal
+// mode and the action's output going straight to it.
+type Exec func(ctx context.Context, act Action) error
+
+// Run drives app on the terminal until the user quits or ctx is done: raw
+// mode on the alternate screen, one frame per key press or tick. Actions are
+// run by exec with the UI suspended, then the UI resumes where it was.
+func Run(ctx context.Context, app *App, in, out *os.File, exec Exec) error {
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return ErrNotTerminal
+	}
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return fmt.Errorf("entering raw mode: %w", err)
+	}
+	scr := render.NewScreen(out)
+	scr.Enter()
+	defer func() {
+		scr.Leave()
+		_ = term.Restore(inFd, state)
+	}()
+
+	keys := readKeys(in)
+	tick := time.NewTicker(tickEvery)
+	defer tick.Stop()
+
+	draw := func() {
+		w, h, err := term.GetSize(outFd)
+		if err != nil || w <= 0 || h <= 0 { // some ptys report 0×0
+			w, h = 80, 24
+		}
+		scr.Draw(app.Frame(h), w, h)
+	}
+	for !app.Done() {
+		draw()
+		if app.Settle(ctx) {
+			continue
+		}
+		if act, ok := app.TakeAction(); ok {
+			scr.Leave()
+			_ = term.Restore(inFd, state)
+			runAction(ctx, app, out, exec, act, keys)
+			if state, err = term.MakeRaw(inFd); err != nil {
+				return fmt.Errorf("entering raw mode: %w", err)
+			}
+			scr.Enter()
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case b, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range decodeKeys(b) {
+				app.Handle(ctx, k)
+			}
+		case <-tick.C:
+			app.Tick(ctx)
+		}
+	}
+	return nil
+}
+
+// runAction runs act in the normal terminal, reports how it went on the
+// status line, and waits for Enter so its output can be read before the UI
+// paints over it.
+func runAction(ctx context.Context, app *App, out *os.File, exec Exec, act Action, keys <-chan []byte) {
+	_, _ = fmt.Fprintf(out, "$ refresh %s\n\n", strings.Join(act.Args(), " "))
+	if err := exec(ctx, act); err != nil {
+		app.fail(fmt.Errorf("%s: %w", act.Label(), err))
+	} else {
+		app.notify(act.Label() + " finished")
+	}
+	// Keys typed while the action ran were meant for it, not the UI.
+	for drained := false; !drained; {
+		select {
+		case <-keys:
+		default:
+			drained = true
+		}
+	}
+	_, _ = fmt.Fprint(out, "\nPress Enter to return to refresh ui ")
+	select {
+	case <-ctx.Done():
+	case <-keys:
+	}
+}
+
+// readKeys forwards raw reads from in until it fails (closing the channel).
+func readKeys(in *os.File) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				ch <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,397 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/refresh/internal/commands/statusview"
+	"github.com/dantech2000/refresh/internal/render"
+	"github.com/dantech2000/refresh/internal/rollview"
+	addonsvc "github.com/dantech2000/refresh/internal/services/addons"
+	clustersvc "github.com/dantech2000/refresh/internal/services/cluster"
+	nodegroupsvc "github.com/dantech2000/refresh/internal/services/nodegroup"
+	statussvc "github.com/dantech2000/refresh/internal/services/status"
+	"github.com/dantech2000/refresh/internal/types"
+	"github.com/dantech2000/refresh/internal/ui"
+)
+
+// rollPoll is how often the roll screen re-reads the nodegroup's nodes.
+const rollPoll = 2 * time.Second
+
+// fleetView is the root screen: the `refresh status` table, one row per
+// cluster.
+type fleetView struct {
+	loaded   bool
+	statuses []statussvc.ClusterStatus
+	list     list
+}
+
+func (v *fleetView) title() string { return "fleet" }
+func (v *fleetView) stale() bool   { return !v.loaded }
+func (v *fleetView) reload()       { v.loaded = false }
+
+func (v *fleetView) load(ctx context.Context, a *App) {
+	statuses, errs := a.src.Fleet(ctx)
+	sort.SliceStable(statuses, func(i, j int) bool {
+		x, y := statuses[i], statuses[j]
+		if x.Account != y.Account {
+			return x.Account < y.Account
+		}
+		if x.Name != y.Name {
+			return x.Name < y.Name
+		}
+		return x.Region < y.Region
+	})
+	v.statuses, v.loaded = statuses, true
+	if len(errs) > 0 {
+		a.fail(fmt.Errorf("%d region(s) failed, first: %w", len(errs), errs[0]))
+	}
+}
+
+func (v *fleetView) body(a *App, height int) []string {
+	if !v.loaded {
+		return a.loading("fleet status")
+	}
+	pal := a.th.Pal
+	head := a.th.Bold(pal.Mauve, "FLEET") + "  " + a.th.Paint(pal.White, a.scope) +
+		a.th.Paint(pal.Dim, fmt.Sprintf(" · %d cluster(s)", len(v.statuses)))
+	if len(v.statuses) == 0 {
+		return []string{head, "", a.th.Paint(pal.Dim, "No EKS clusters found.")}
+	}
+	out := []string{head, statusview.ChipsLine(a.th, v.statuses), ""}
+	return append(out, v.list.render(a.th, statusview.FleetTable(a.th, v.statuses), height-len(out))...)
+}
+
+func (v *fleetView) help() string {
+	return "↑↓ move · enter open · d dry-run · h health check"
+}
+
+func (v *fleetView) handle(_ context.Context, a *App, k Key) {
+	if v.list.move(k, len(v.statuses), 10) || len(v.statuses) == 0 {
+		return
+	}
+	s := v.statuses[v.list.cursor]
+	c := Cluster{Name: s.Name, Region: s.Region, Account: s.Account}
+	switch {
+	case k.Code == KeyEnter:
+		a.push(&clusterView{c: c, status: s})
+	case k.Code == KeyRune && k.Rune == 'd':
+		a.run(Action{Kind: ActionDryRun, Cluster: c})
+	case k.Code == KeyRune && k.Rune == 'h':
+		a.run(Action{Kind: ActionHealth, Cluster: c})
+	}
+}
+
+// clusterTab is one tab of the cluster screen.
+type clusterTab int
+
+const (
+	tabNodegroups clusterTab = iota
+	tabAddons
+	tabInsights
+	tabCount
+)
+
+var tabNames = [tabCount]string{"nodegroups", "addons", "insights"}
+
+// clusterView is one cluster: its nodegroups, addons and upgrade insights on
+// tabs, each fetched the first time it is shown.
+type clusterView struct {
+	c      Cluster
+	status statussvc.ClusterStatus
+	tab    clusterTab
+	loaded [tabCount]bool
+	errs   [tabCount]error
+	lists  [tabCount]list
+
+	nodegroups []nodegroupsvc.NodegroupSummary
+	addons     []addonsvc.AddonSummary
+	insights   []clustersvc.InsightSummary
+}
+
+func (v *clusterView) title() string { return v.c.String() }
+func (v *clusterView) stale() bool   { return !v.loaded[v.tab] }
+func (v *clusterView) reload()       { v.loaded[v.tab] = false }
+
+func (v *clusterView) load(ctx context.Context, a *App) {
+	var err error
+	switch v.tab {
+	case tabNodegroups:
+		v.nodegroups, err = a.src.Nodegroups(ctx, v.c)
+	case tabAddons:
+		v.addons, err = a.src.Addons(ctx, v.c)
+	case tabInsights:
+		v.insights, err = a.src.Insights(ctx, v.c)
+	}
+	v.errs[v.tab], v.loaded[v.tab] = err, true
+}
+
+// rows is how many rows the current tab lists.
+func (v *clusterView) rows() int {
+	switch v.tab {
+	case tabNodegroups:
+		return len(v.nodegroups)
+	case tabAddons:
+		return len(v.addons)
+	default:
+		return len(v.insights)
+	}
+}
+
+func (v *clusterView) body(a *App, height int) []string {
+	th, pal := a.th, a.th.Pal
+	detail := " · " + v.c.Region
+	if v.status.Version != "" {
+		detail = " · " + v.status.Version + detail
+	}
+	tabs := make([]string, tabCount)
+	for i, name := range tabNames {
+		label := fmt.Sprintf("%d %s", i+1, name)
+		if clusterTab(i) == v.tab {
+			tabs[i] = th.Bold(pal.Mauve, "["+label+"]")
+		} else {
+			tabs[i] = th.Paint(pal.Dim, " "+label+" ")
+		}
+	}
+	out := []string{
+		th.Bold(pal.Mauve, "CLUSTER") + "  " + th.Paint(pal.White, v.c.Name) + th.Paint(pal.Dim, detail),
+		strings.Join(tabs, " "),
+		"",
+	}
+	switch {
+	case !v.loaded[v.tab]:
+		return append(out, a.loading(tabNames[v.tab])...)
+	case v.errs[v.tab] != nil:
+		return append(out, th.Token(render.Fail, "error")+" "+th.Paint(pal.Text, oneLine(v.errs[v.tab].Error())))
+	case v.rows() == 0:
+		return append(out, th.Paint(pal.Dim, "No "+tabNames[v.tab]+"."))
+	}
+	var table []string
+	switch v.tab {
+	case tabNodegroups:
+		table = nodegroupTable(th, v.nodegroups)
+	case tabAddons:
+		table = addonTable(th, v.addons)
+	case tabInsights:
+		table = insightTable(th, v.insights)
+	}
+	return append(out, v.lists[v.tab].render(th, table, height-len(out))...)
+}
+
+func (v *clusterView) help() string {
+	if v.tab == tabNodegroups {
+		return "←→ tab · ↑↓ move · enter/a attach to roll · d dry-run · h health check"
+	}
+	return "←→ tab · ↑↓ move · d dry-run · h health check (whole cluster)"
+}
+
+func (v *clusterView) handle(_ context.Context, a *App, k Key) {
+	switch {
+	case k.Code == KeyRight || k.Code == KeyTab:
+		v.tab = (v.tab + 1) % tabCount
+		return
+	case k.Code == KeyLeft || k.Code == KeyBackTab:
+		v.tab = (v.tab + tabCount - 1) % tabCount
+		return
+	case k.Code == KeyRune && k.Rune >= '1' && k.Rune < '1'+rune(tabCount):
+		v.tab = clusterTab(k.Rune - '1')
+		return
+	}
+	if v.list().move(k, v.rows(), 10) {
+		return
+	}
+
+	act := Action{Cluster: v.c}
+	var ng *nodegroupsvc.NodegroupSummary
+	if v.tab == tabNodegroups && v.loaded[v.tab] && len(v.nodegroups) > 0 {
+		ng = &v.nodegroups[v.list().cursor]
+		act.Nodegroup = ng.Name
+	}
+	switch {
+	case k.Code == KeyRune && k.Rune == 'd':
+		a.run(act)
+	case k.Code == KeyRune && k.Rune == 'h':
+		act.Kind = ActionHealth
+		a.run(act)
+	case ng != nil && (k.Code == KeyEnter || (k.Code == KeyRune && k.Rune == 'a')):
+		if ng.Status != "UPDATING" {
+			a.notify(fmt.Sprintf("%s is %s, not rolling: nothing to attach to (d previews a roll)", ng.Name, ng.Status))
+			return
+		}
+		a.push(&rollView{c: v.c, ng: *ng})
+	}
+}
+
+func (v *clusterView) list() *list { return &v.lists[v.tab] }
+
+// nodegroupTable mirrors `nodegroup list`: tokenized STATUS and AMI cells.
+func nodegroupTable(th *render.Theme, items []nodegroupsvc.NodegroupSummary) []string {
+	pal := th.Pal
+	tbl := th.NewTable(
+		ui.Column{Title: "NAME", Min: 4, Max: 60},
+		ui.Column{Title: "STATUS", Min: 10},
+		ui.Column{Title: "INSTANCE", Min: 10},
+		ui.Column{Title: "AMI", Min: 9},
+		ui.Column{Title: "NODES", Min: 7, Align: ui.AlignRight},
+	)
+	for _, ng := range items {
+		nodes := fmt.Sprintf("%d", ng.DesiredSize)
+		if ng.ReadyKnown {
+			nodes = fmt.Sprintf("%d/%d", ng.ReadyNodes, ng.DesiredSize)
+		}
+		tbl.Row(
+			th.Paint(pal.White, ng.Name),
+			th.Token(render.StatusFromString(ng.Status), ng.Status),
+			th.Paint(pal.Text, ng.InstanceType),
+			amiToken(th, ng.AMIStatus),
+			th.Paint(pal.Text, nodes),
+		)
+	}
+	return tbl.Render()
+}
+
+// amiToken renders a nodegroup's AMI freshness as a status token.
+func amiToken(th *render.Theme, s types.AMIStatus) string {
+	switch s {
+	case types.AMILatest:
+		return th.Token(render.Healthy, s.String())
+	case types.AMIOutdated:
+		return th.Token(render.Warn, s.String())
+	case types.AMIUpdating:
+		return th.Token(render.Progress, s.String())
+	default:
+		return th.Token(render.Unknown, s.String())
+	}
+}
+
+func addonTable(th *render.Theme, items []addonsvc.AddonSummary) []string {
+	pal := th.Pal
+	tbl := th.NewTable(
+		ui.Column{Title: "NAME", Min: 4, Max: 50},
+		ui.Column{Title: "VERSION", Min: 7},
+		ui.Column{Title: "STATUS", Min: 10},
+		ui.Column{Title: "HEALTH", Min: 8},
+	)
+	for _, ad := range items {
+		health := ad.Health
+		if health == "" {
+			health = "-"
+		}
+		tbl.Row(
+			th.Paint(pal.White, ad.Name),
+			th.Paint(pal.Text, ad.Version),
+			th.Token(render.StatusFromString(ad.Status), ad.Status),
+			th.Paint(pal.Text, health),
+		)
+	}
+	return tbl.Render()
+}
+
+func insightTable(th *render.Theme, items []clustersvc.InsightSummary) []string {
+	pal := th.Pal
+	tbl := th.NewTable(
+		ui.Column{Title: "STATUS", Min: 10},
+		ui.Column{Title: "INSIGHT", Min: 7, Max: 60},
+		ui.Column{Title: "CATEGORY", Min: 8},
+		ui.Column{Title: "REASON", Min: 6, Max: 80},
+	)
+	for _, in := range items {
+		tbl.Row(
+			th.Token(insightStatus(in.Status), in.Status),
+			th.Paint(pal.White, in.Name),
+			th.Paint(pal.Text, in.Category),
+			th.Paint(pal.Dim, oneLine(in.StatusReason)),
+		)
+	}
+	return tbl.Render()
+}
+
+// insightStatus maps an EKS insight status onto a render status.
+func insightStatus(s string) render.Status {
+	switch s {
+	case clustersvc.InsightStatusPassing:
+		return render.Healthy
+	case clustersvc.InsightStatusWarning:
+		return render.Warn
+	case clustersvc.InsightStatusError:
+		return render.Fail
+	default:
+		return render.Unknown
+	}
+}
+
+// rollView attaches the live roll panel to a nodegroup that is rolling,
+// re-reading its nodes every rollPoll.
+type rollView struct {
+	c     Cluster
+	ng    nodegroupsvc.NodegroupSummary
+	panel *rollview.Panel
+	where string
+	err   error // building the observer failed
+	lines []string
+	poll  error // the last poll failed; lines are from the one before
+	next  time.Time
+}
+
+func (v *rollView) title() string { return v.ng.Name + " roll" }
+func (v *rollView) stale() bool   { return v.panel == nil && v.err == nil }
+
+func (v *rollView) reload() {
+	if v.panel == nil {
+		v.err = nil // retry building the observer
+	}
+	v.next = time.Time{}
+}
+
+func (v *rollView) load(ctx context.Context, a *App) {
+	obs, where, err := a.src.RollObserver(ctx, v.c, v.ng.Name)
+	if err != nil {
+		v.err = err
+		return
+	}
+	v.panel, v.where = rollview.NewPanel(obs, v.ng.Name, int(v.ng.DesiredSize)), where
+	v.refresh(ctx, a)
+}
+
+func (v *rollView) tick(ctx context.Context, a *App) {
+	if v.panel != nil && !a.now().Before(v.next) {
+		v.refresh(ctx, a)
+	}
+}
+
+func (v *rollView) refresh(ctx context.Context, a *App) {
+	lines, _, err := v.panel.Frame(ctx, a.th)
+	if err == nil {
+		v.lines = lines
+	}
+	v.poll, v.next = err, a.now().Add(rollPoll)
+}
+
+func (v *rollView) body(a *App, _ int) []string {
+	th, pal := a.th, a.th.Pal
+	switch {
+	case v.err != nil:
+		return []string{
+			th.Token(render.Fail, "error") + " " + th.Paint(pal.Text, oneLine(v.err.Error())),
+			"",
+			th.Paint(pal.Dim, "The roll is read from the Kubernetes API through the current kubeconfig context; r retries."),
+		}
+	case v.panel == nil:
+		return a.loading("nodes of " + v.ng.Name)
+	}
+	out := []string{th.Paint(pal.Dim, "via "+v.where), ""}
+	out = append(out, v.lines...)
+	if v.poll != nil {
+		out = append(out, "", th.Token(render.Warn, "poll failed")+" "+th.Paint(pal.Dim, oneLine(v.poll.Error())))
+	}
+	return out
+}
+
+func (v *rollView) help() string {
+	return fmt.Sprintf("live · refreshes every %s", rollPoll)
+}
+
+func (v *rollView) handle(context.Context, *App, Key) {}
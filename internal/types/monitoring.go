@@ -61,4 +61,7 @@ type MonitorConfig struct {
 	Quiet           bool
 	NoWait          bool
 	Timeout         time.Duration
+	// OnPoll, when set, is called with an update's state after each status
+	// poll (successful or not) — e.g. to record the roll.
+	OnPoll func(UpdateProgress)
 }
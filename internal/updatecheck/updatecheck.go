@@ -25,6 +25,10 @@ import (
 // DefaultBaseURL is the GitHub Releases "latest" endpoint for this project.
 const DefaultBaseURL = "https://api.github.com/repos/dantech2000/refresh/releases/latest"
 
+// BaseURLEnv names a mirror of DefaultBaseURL, for networks that can't reach
+// api.github.com.
+const BaseURLEnv = "REFRESH_UPDATE_CHECK_URL"
+
 // checkInterval is the minimum time between network fetches. Within this window
 // the cached tag is reused.
 const checkInterval = 24 * time.Hour
@@ -58,10 +62,15 @@ func WithCachePath(p string) Option { return func(c *Checker) { c.cachePath = p
 // WithNow overrides the clock.
 func WithNow(now func() time.Time) Option { return func(c *Checker) { c.now = now } }
 
-// New builds a Checker with production defaults, applying any options.
+// New builds a Checker with production defaults, applying any options. The
+// endpoint is the mirror in REFRESH_UPDATE_CHECK_URL when set.
 func New(opts ...Option) *Checker {
+	baseURL := DefaultBaseURL
+	if u := strings.TrimSpace(os.Getenv(BaseURLEnv)); u != "" {
+		baseURL = u
+	}
 	c := &Checker{
-		baseURL:    DefaultBaseURL,
+		baseURL:    baseURL,
 		httpClient: &http.Client{Timeout: fetchTimeout},
 		cachePath:  defaultCachePath(),
 		now:        time.Now,
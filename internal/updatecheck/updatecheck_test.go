@@ -147,3 +147,17 @@ func TestLatestTagFailSilentOnHTTPError(t *testing.T) {
 	// The caller (maybePrintUpdateHint) treats any error as "no hint", so the
 	// returned error never reaches the user — this just documents the contract.
 }
+
+func TestLatestTagUsesMirrorFromEnv(t *testing.T) {
+	var hits int32
+	srv := newTestServer(t, "v9.9.9", &hits)
+	t.Setenv(BaseURLEnv, srv.URL)
+	c := New(
+		WithHTTPClient(srv.Client()),
+		WithCachePath(filepath.Join(t.TempDir(), "cache.json")),
+	)
+	tag, err := c.LatestTag(context.Background())
+	if err != nil || tag != "v9.9.9" || atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("LatestTag() = %q, %v (hits %d), want the mirror's tag", tag, err, hits)
+	}
+}
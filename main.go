@@ -11,19 +11,37 @@ import (
 	"regexp"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v3"
 
+	"github.com/dantech2000/refresh/internal/awscache"
+	"github.com/dantech2000/refresh/internal/awsconfig"
+	"github.com/dantech2000/refresh/internal/cliconfig"
 	"github.com/dantech2000/refresh/internal/commands"
 	addoncmd "github.com/dantech2000/refresh/internal/commands/addon"
+	cachecmd "github.com/dantech2000/refresh/internal/commands/cachecmd"
+	calendarcmd "github.com/dantech2000/refresh/internal/commands/calendarcmd"
+	changelogcmd "github.com/dantech2000/refresh/internal/commands/changelogcmd"
 	clustercmd "github.com/dantech2000/refresh/internal/commands/cluster"
+	configcmd "github.com/dantech2000/refresh/internal/commands/configcmd"
+	costcmd "github.com/dantech2000/refresh/internal/commands/costcmd"
 	ctxcmd "github.com/dantech2000/refresh/internal/commands/ctxcmd"
 	"github.com/dantech2000/refresh/internal/commands/factory"
 	nodegroupcmd "github.com/dantech2000/refresh/internal/commands/nodegroup"
+	plancmd "github.com/dantech2000/refresh/internal/commands/plancmd"
+	plugincmd "github.com/dantech2000/refresh/internal/commands/plugincmd"
+	rollcmd "github.com/dantech2000/refresh/internal/commands/rollcmd"
+	"github.com/dantech2000/refresh/internal/commands/runner"
+	snapshotcmd "github.com/dantech2000/refresh/internal/commands/snapshotcmd"
 	statuscmd "github.com/dantech2000/refresh/internal/commands/statuscmd"
+	uicmd "github.com/dantech2000/refresh/internal/commands/uicmd"
 	appconfig "github.com/dantech2000/refresh/internal/config"
+	"github.com/dantech2000/refresh/internal/health"
+	"github.com/dantech2000/refresh/internal/sandbox"
+	"github.com/dantech2000/refresh/internal/services/common"
 )
 
 var (
@@ -67,8 +85,17 @@ func colorizeHelp(text string) string {
 	return strings.Join(lines, "\n")
 }
 
+// cacheWait bounds how long a --cache run waits on exit for background
+// revalidations to save their answers.
+const cacheWait = 5 * time.Second
+
 func newApp() *cli.Command {
-	return &cli.Command{
+	var (
+		cache *awscache.Cache
+		// sandboxDir holds a sandbox run's caches; it is removed on exit.
+		sandboxDir string
+	)
+	app := &cli.Command{
 		Name:                  "refresh",
 		Usage:                 "Manage and monitor AWS EKS clusters and nodegroups",
 		Version:               commands.VersionInfo.Version,
@@ -118,7 +145,21 @@ func newApp() *cli.Command {
 			},
 			&cli.BoolFlag{
 				Name:  "verbose",
-				Usage: "Shortcut for --log-level debug",
+				Usage: "Shortcut for --log-level debug; also reports AWS throttling on exit",
+			},
+			// Practice mode: every AWS call is answered by an in-memory
+			// simulation of the fixture, and Kubernetes access is off.
+			&cli.StringFlag{
+				Name:      "sandbox",
+				Usage:     "Run against a simulated fleet loaded from a YAML fixture instead of AWS",
+				TakesFile: true,
+				Sources:   cli.EnvVars("REFRESH_SANDBOX"),
+			},
+			// Opt-in: cached reads can be up to a minute behind the console.
+			&cli.BoolFlag{
+				Name:    "cache",
+				Usage:   "Cache AWS reads on disk and serve slow-changing ones stale while revalidating (see refresh cache)",
+				Sources: cli.EnvVars("REFRESH_CACHE"),
 			},
 		},
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
@@ -133,19 +174,65 @@ func newApp() *cli.Command {
 				color.NoColor = true
 				pterm.DisableColor()
 			}
+			if file := cmd.String("sandbox"); file != "" {
+				dir, err := startSandbox(cmd.Root().ErrWriter, file)
+				sandboxDir = dir
+				if err != nil {
+					return ctx, err
+				}
+			} else if cmd.Bool("cache") {
+				dir, err := awscache.DefaultDir()
+				if err != nil {
+					return ctx, fmt.Errorf("locating the response cache: %w", err)
+				}
+				cache = awscache.New(dir)
+				awsconfig.UseCache(cache)
+			}
 			return ctx, nil
 		},
+		// --verbose ends with how hard AWS pushed back: throttles and the
+		// rate the shared limiter settled at, per service and region, and
+		// how much the response cache answered. A sandbox run's throwaway
+		// caches go with it.
+		After: func(_ context.Context, cmd *cli.Command) error {
+			if sandboxDir != "" {
+				cliconfig.UseCacheDir("")
+				_ = os.RemoveAll(sandboxDir)
+			}
+			if cache != nil {
+				cache.Wait(cacheWait)
+			}
+			if cmd.Bool("verbose") {
+				common.SharedRateLimiter.WriteStats(cmd.Root().ErrWriter)
+				if cache != nil {
+					cache.WriteStats(cmd.Root().ErrWriter)
+				}
+			}
+			return nil
+		},
 		Commands: []*cli.Command{
 			// Fleet front door
 			statuscmd.Command(),
+			costcmd.Command(),
+			calendarcmd.Command(),
+			changelogcmd.Command(),
+			uicmd.Command(),
 			// Resource-first groups
 			clustercmd.Command(),
 			nodegroupcmd.Command(),
 			addoncmd.Command(),
+			rollcmd.Command(),
+			// Declarative desired state
+			plancmd.PlanCommand(),
+			plancmd.ApplyCommand(),
+			snapshotcmd.Command(),
 			// Context (kubectx-style)
 			ctxcmd.UseCommand(),
 			ctxcmd.CurrentCommand(),
 			ctxcmd.ContextCommand(),
+			configcmd.Command(),
+			cachecmd.Command(),
+			plugincmd.Command(),
 			// Misc
 			commands.VersionCommand(),
 			commands.ManPageCommand(),
@@ -154,6 +241,47 @@ func newApp() *cli.Command {
 			commands.GenDocsCommand(),
 		},
 	}
+	// refresh.yaml defaults fill unset flags on every command, below flags and
+	// env vars in precedence.
+	runner.InstallSettings(app)
+	// refresh-<name> executables on PATH, after the built-ins so none can
+	// replace one. They take no flags, so refresh.yaml defaults don't apply.
+	plugincmd.Install(app)
+	return app
+}
+
+// startSandbox loads the fixture and points every AWS client at it, and the
+// Kubernetes client at the fixture's objects if it has any. The fixture's
+// regions become the multi-region sweep unless REFRESH_EKS_REGIONS
+// already names some. The caches refresh fills on its own (support calendar,
+// add-on compatibility, AMI release notes) move to the returned throwaway
+// directory, so made-up fixture data never reaches a real run.
+func startSandbox(errOut io.Writer, file string) (string, error) {
+	sim, err := sandbox.Load(file)
+	if err != nil {
+		return "", fmt.Errorf("loading sandbox fixture: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "refresh-sandbox-")
+	if err != nil {
+		return "", fmt.Errorf("creating the sandbox cache dir: %w", err)
+	}
+	cliconfig.UseCacheDir(dir)
+	awsconfig.UseSandbox(sim)
+	health.DisableKubernetes("sandbox mode")
+	client, cluster, ok, err := sim.KubeClient()
+	if err != nil {
+		return dir, fmt.Errorf("loading sandbox fixture: %w", err)
+	}
+	if ok {
+		health.UseKubeClient(client, health.KubeDiag{Source: "sandbox", Path: file, Context: cluster})
+	}
+	if os.Getenv(appconfig.EnvEKSRegions) == "" {
+		if err := os.Setenv(appconfig.EnvEKSRegions, strings.Join(sim.Regions(), ",")); err != nil {
+			return dir, err
+		}
+	}
+	_, _ = color.New(color.FgYellow).Fprintf(errOut, "SANDBOX: simulated AWS from %s; nothing real is changed\n", file)
+	return dir, nil
 }
 
 func run(ctx context.Context, args []string, out, errOut io.Writer) error {
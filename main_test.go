@@ -5,13 +5,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/fatih/color"
 	docs "github.com/urfave/cli-docs/v3"
+	"github.com/urfave/cli/v3"
 
 	awsClient "github.com/dantech2000/refresh/internal/aws"
+	"github.com/dantech2000/refresh/internal/awsconfig"
+	"github.com/dantech2000/refresh/internal/cliconfig"
+	appconfig "github.com/dantech2000/refresh/internal/config"
+	"github.com/dantech2000/refresh/internal/health"
 	"github.com/dantech2000/refresh/internal/types"
 )
 
@@ -236,8 +242,8 @@ func TestNewAppAndRun(t *testing.T) {
 		t.Fatalf("app name = %q", app.Name)
 	}
 	// Global flags: --timeout, --max-concurrency, --no-color, --profile,
-	// --region, --log-level, --verbose.
-	if len(app.Commands) == 0 || len(app.Flags) != 7 {
+	// --region, --log-level, --verbose, --sandbox, --cache.
+	if len(app.Commands) == 0 || len(app.Flags) != 9 {
 		t.Fatalf("unexpected app shape: commands=%d flags=%d", len(app.Commands), len(app.Flags))
 	}
 	if !app.EnableShellCompletion {
@@ -294,6 +300,52 @@ func TestGlobalProfileRegionFlagsPropagate(t *testing.T) {
 	}
 }
 
+// --sandbox runs real command paths against the simulated fleet, with no
+// credentials and no kubeconfig.
+func TestSandboxRunsCommands(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("REFRESH_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv(appconfig.EnvEKSRegions, "")
+	t.Cleanup(func() {
+		awsconfig.UseSandbox(nil)
+		health.DisableKubernetes("")
+		cliconfig.UseCacheDir("")
+	})
+
+	var out, errOut bytes.Buffer
+	argv := []string{"refresh", "--sandbox", "internal/sandbox/testdata/fleet.yaml", "cluster", "list", "-A", "-o", "json"}
+	if err := run(context.Background(), argv, &out, &errOut); err != nil {
+		t.Fatalf("run %v: %v", argv[1:], err)
+	}
+	if !strings.Contains(errOut.String(), "SANDBOX") {
+		t.Errorf("stderr = %q, want the sandbox banner", errOut.String())
+	}
+	if got := os.Getenv(appconfig.EnvEKSRegions); got != "us-east-1,us-west-2" {
+		t.Errorf("%s = %q, want the fixture's regions", appconfig.EnvEKSRegions, got)
+	}
+
+	// status records the support calendar it resolved; the fixture's must
+	// not land in the real config dir. Its exit code reflects the posture,
+	// so keep the CLI from exiting the test binary on it.
+	app := newApp()
+	app.Writer, app.ErrWriter = &out, &errOut
+	app.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+	_ = app.Run(context.Background(), []string{"refresh", "--sandbox", "internal/sandbox/testdata/fleet.yaml", "status", "-A", "-o", "json"})
+	if _, err := os.Stat(filepath.Join(home, ".config", "refresh")); !os.IsNotExist(err) {
+		t.Errorf("a sandbox run wrote the real config dir (stat: %v)", err)
+	}
+	if dir, _ := cliconfig.CacheDir(); dir != filepath.Join(home, ".config", "refresh") {
+		t.Errorf("CacheDir = %q after the run, want the real config dir back", dir)
+	}
+
+	if err := run(context.Background(), []string{"refresh", "--sandbox", "missing.yaml", "version"}, &out, &errOut); err == nil {
+		t.Error("a missing fixture should fail")
+	}
+}
+
 func containsString(haystack []string, needle string) bool {
 	for _, h := range haystack {
 		if h == needle {